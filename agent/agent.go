@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
@@ -11,11 +12,13 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"text/template"
 	"time"
 	"unicode"
 
 	"github.com/nachoal/simple-agent-go/internal/runlog"
 	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/llm/cost"
 	"github.com/nachoal/simple-agent-go/tools"
 	"github.com/nachoal/simple-agent-go/tools/registry"
 )
@@ -36,6 +39,19 @@ type agent struct {
 	toolRegistry    *registry.Registry
 	mu              sync.RWMutex
 	progressHandler func(ProgressEvent)
+
+	// running holds a token while a Query or QueryStream is in progress, so
+	// concurrent calls take turns instead of interleaving memory writes
+	running chan struct{}
+
+	// lastLLMCall is when the most recent LLM request was (or is scheduled
+	// to be) sent; guarded by mu. now and sleep are swapped out in tests.
+	lastLLMCall time.Time
+	now         func() time.Time
+	sleep       func(ctx context.Context, d time.Duration) error
+
+	// modelSelected is set once ModelSelector has chosen config.Model
+	modelSelected bool
 }
 
 // New creates a new agent
@@ -48,7 +64,7 @@ func New(client llm.Client, opts ...Option) Agent {
 	}
 
 	a := &agent{
-		client: client,
+		client: withFallbacks(client, config),
 		config: config,
 		memory: &Memory{
 			Messages: make([]llm.Message, 0),
@@ -56,20 +72,16 @@ func New(client llm.Client, opts ...Option) Agent {
 		},
 		toolRegistry:    registry.Default(),
 		progressHandler: config.progressHandler,
+		running:         make(chan struct{}, 1),
+		now:             time.Now,
+		sleep:           sleepContext,
 	}
 
 	// Initialize with system prompt
 	if config.SystemPrompt != "" {
-		// Get tool information to enhance the system prompt
-		toolInfo := a.getToolListForPrompt()
-		enhancedPrompt := config.SystemPrompt
-		if toolInfo != "" {
-			enhancedPrompt = config.SystemPrompt + "\n\n" + toolInfo
-		}
-
 		a.memory.Messages = append(a.memory.Messages, llm.Message{
 			Role:    llm.RoleSystem,
-			Content: llm.StringPtr(enhancedPrompt),
+			Content: llm.StringPtr(a.buildSystemPrompt(config.SystemPrompt)),
 		})
 	}
 
@@ -83,8 +95,99 @@ func (a *agent) withRequestTimeout(ctx context.Context) (context.Context, contex
 	return context.WithTimeout(ctx, a.config.Timeout)
 }
 
-// Query sends a query and returns the response
-func (a *agent) Query(ctx context.Context, query string) (*Response, error) {
+// withRetryBudget attaches a fresh retry budget to ctx when one is configured,
+// so every LLM call in a single query draws from the same pool.
+func (a *agent) withRetryBudget(ctx context.Context) context.Context {
+	if a.config.RetryBudget <= 0 {
+		return ctx
+	}
+	return llm.WithRetryBudget(ctx, llm.NewRetryBudget(a.config.RetryBudget))
+}
+
+// resetPacing forgets the previous LLM call at the start of a query unless
+// pacing is configured to span queries.
+func (a *agent) resetPacing() {
+	if a.config.MinIntervalAcrossQueries {
+		return
+	}
+	a.mu.Lock()
+	a.lastLLMCall = time.Time{}
+	a.mu.Unlock()
+}
+
+// waitMinInterval blocks until at least MinInterval has passed since the
+// previous LLM call. The slot is reserved under the lock so concurrent
+// callers are spaced out too.
+func (a *agent) waitMinInterval(ctx context.Context) error {
+	if a.config.MinInterval <= 0 {
+		return nil
+	}
+
+	a.mu.Lock()
+	now := a.now()
+	next := now
+	if !a.lastLLMCall.IsZero() {
+		if earliest := a.lastLLMCall.Add(a.config.MinInterval); earliest.After(now) {
+			next = earliest
+		}
+	}
+	a.lastLLMCall = next
+	a.mu.Unlock()
+
+	if wait := next.Sub(now); wait > 0 {
+		return a.sleep(ctx, wait)
+	}
+	return nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// beginQuery waits until no other Query or QueryStream is running on this
+// agent, or returns ErrBusy straight away when FailWhenBusy is set. Every
+// successful call must be paired with endQuery.
+func (a *agent) beginQuery(ctx context.Context) error {
+	select {
+	case a.running <- struct{}{}:
+		return nil
+	default:
+	}
+	if a.config.FailWhenBusy {
+		return ErrBusy
+	}
+	select {
+	case a.running <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (a *agent) endQuery() {
+	<-a.running
+}
+
+// Query sends a query and returns the response. When the query runs past
+// Config.Deadline it returns what it gathered so far, marked Truncated,
+// along with an error wrapping context.DeadlineExceeded.
+func (a *agent) Query(ctx context.Context, query string) (resp *Response, err error) {
+	if err := a.beginQuery(ctx); err != nil {
+		return nil, err
+	}
+	defer a.endQuery()
+	ctx, cancelDeadline := a.withDeadline(ctx)
+	defer cancelDeadline()
+	ctx = a.withRetryBudget(ctx)
+	a.resetPacing()
+	a.selectModel(ctx)
 	// Add user message to memory
 	a.addMessage(llm.Message{
 		Role:    llm.RoleUser,
@@ -97,25 +200,43 @@ func (a *agent) Query(ctx context.Context, query string) (*Response, error) {
 		streamChan = ch // nil if UI isn't streaming
 	}
 	// Get available tools if configured
-	var availableTools []map[string]interface{}
-	if len(a.config.Tools) > 0 {
-		for _, toolName := range a.config.Tools {
-			if schema, err := a.toolRegistry.GetSchema(toolName); err == nil {
-				availableTools = append(availableTools, schema)
-			}
-		}
-	} else {
-		// If no specific tools configured, use all available tools
-		availableTools = a.toolRegistry.GetAllSchemas()
-	}
+	availableTools := a.availableToolSchemas()
 
 	// Main agent loop
 	var totalUsage llm.Usage
+	var spent queryCost
 	var allToolResults []tools.ToolResult
+	toolFailures := 0
 	toolChoice := "auto"
 	totalToolCalls := 0
+	lastContent := ""
+	iterations := 0
+	loopGuard := newToolLoopGuard(a.config.MaxRepeatedToolCalls)
+
+	defer func() {
+		deadlineErr := a.deadlineError(ctx)
+		if err == nil || deadlineErr == nil {
+			return
+		}
+		resp = &Response{
+			AgentName:    a.config.Name,
+			Content:      lastContent,
+			ToolCalls:    allToolResults,
+			Usage:        &totalUsage,
+			Truncated:    true,
+			Iterations:   iterations,
+			ToolFailures: toolFailures,
+		}
+		spent.apply(resp)
+		err = deadlineErr
+	}()
 
 	for iteration := 0; iteration < a.config.MaxIterations; iteration++ {
+		if err := queryCancelled(ctx, iteration+1); err != nil {
+			return nil, err
+		}
+		iterations = iteration + 1
+
 		// Emit progress event for iteration
 		a.emitProgress(ProgressEvent{
 			Type:      ProgressEventIteration,
@@ -129,14 +250,15 @@ func (a *agent) Query(ctx context.Context, query string) (*Response, error) {
 
 		// Create chat request
 		request := &llm.ChatRequest{
-			Model:       a.config.Model,
-			Messages:    a.getMessages(),
-			Temperature: a.config.Temperature,
-			MaxTokens:   a.config.MaxTokens,
-			TopP:        a.config.TopP,
-			ExtraBody:   a.config.ExtraBody,
-			Tools:       availableTools,
-			ToolChoice:  toolChoice,
+			Model:          a.config.Model,
+			Messages:       a.getMessages(),
+			Temperature:    a.config.Temperature,
+			MaxTokens:      a.config.MaxTokens,
+			TopP:           a.config.TopP,
+			ExtraBody:      a.config.ExtraBody,
+			Tools:          availableTools,
+			ToolChoice:     toolChoice,
+			ResponseFormat: jsonResponseFormat(ctx),
 		}
 		logAgentEvent(ctx, "llm_request", map[string]interface{}{
 			"mode":          "query",
@@ -156,10 +278,22 @@ func (a *agent) Query(ctx context.Context, query string) (*Response, error) {
 		}
 
 		// Send request to LLM
+		if err := a.checkCostBudget(request); err != nil {
+			return nil, err
+		}
+		if err := a.waitMinInterval(ctx); err != nil {
+			if cancelErr := queryCancelled(ctx, iteration+1); cancelErr != nil {
+				return nil, cancelErr
+			}
+			return nil, err
+		}
 		requestCtx, cancel := a.withRequestTimeout(ctx)
 		response, err := a.client.Chat(requestCtx, request)
 		cancel()
 		if err != nil {
+			if cancelErr := queryCancelled(ctx, iteration+1); cancelErr != nil {
+				return nil, cancelErr
+			}
 			logAgentEvent(ctx, "llm_error", map[string]interface{}{
 				"mode":      "query",
 				"iteration": iteration + 1,
@@ -177,6 +311,8 @@ func (a *agent) Query(ctx context.Context, query string) (*Response, error) {
 		})
 
 		// Update usage
+		a.recordCost(request, response.Model, response.Usage)
+		spent.add(a.prices(), billedModel(request, response.Model), response.Usage)
 		if response.Usage != nil {
 			totalUsage.PromptTokens += response.Usage.PromptTokens
 			totalUsage.CompletionTokens += response.Usage.CompletionTokens
@@ -220,15 +356,50 @@ func (a *agent) Query(ctx context.Context, query string) (*Response, error) {
 			message.Content = llm.StringPtr("")
 		}
 
+		// Drop the response if the run was cancelled while waiting, so memory
+		// never holds tool calls without matching tool results.
+		if len(message.ToolCalls) > 0 {
+			if err := queryCancelled(ctx, iteration+1); err != nil {
+				return nil, err
+			}
+		}
+
 		// Add assistant message to memory
 		a.addMessage(message)
+		if message.Content != nil && strings.TrimSpace(*message.Content) != "" {
+			lastContent = *message.Content
+		}
 
 		// Check if we need to execute tools
 		if len(message.ToolCalls) > 0 {
+			if loopGuard.tripped {
+				a.addToolLoopReplies(message.ToolCalls, loopGuard)
+				logAgentEvent(ctx, "agent_error", map[string]interface{}{
+					"mode":  "query",
+					"error": ErrToolLoop.Error(),
+				})
+				return nil, ErrToolLoop
+			}
+			if loopGuard.observe(message.ToolCalls) {
+				a.addToolLoopReplies(message.ToolCalls, loopGuard)
+				logAgentEvent(ctx, "tool_loop", map[string]interface{}{
+					"mode":      "query",
+					"iteration": iteration + 1,
+					"repeats":   loopGuard.repeats,
+				})
+				toolChoice = "none"
+				continue
+			}
 			if a.config.MaxToolCalls > 0 && totalToolCalls+len(message.ToolCalls) > a.config.MaxToolCalls {
 				return nil, fmt.Errorf("max tool calls (%d) reached without completion", a.config.MaxToolCalls)
 			}
 			totalToolCalls += len(message.ToolCalls)
+			if streamChan != nil {
+				select {
+				case streamChan <- toolProposalEvent(ctx, "query", message.ToolCalls):
+				case <-ctx.Done():
+				}
+			}
 			// Emit progress event for tool calls
 			a.emitProgress(ProgressEvent{
 				Type:      ProgressEventToolCallsStart,
@@ -258,7 +429,9 @@ func (a *agent) Query(ctx context.Context, query string) (*Response, error) {
 			}
 
 			// Execute tool calls with events if channel provided
-			results := a.executeToolsWithEvents(ctx, toolCalls, streamChan)
+			results := a.executePermittedTools(ctx, toolCalls, func(calls []tools.ToolCall) []tools.ToolResult {
+				return a.executeToolsWithEvents(ctx, calls, streamChan)
+			})
 			allToolResults = append(allToolResults, results...)
 
 			// Add tool results to memory
@@ -279,6 +452,7 @@ func (a *agent) Query(ctx context.Context, query string) (*Response, error) {
 					toolFields["error"] = result.Error.Error()
 				}
 				logAgentEvent(ctx, "tool_result", toolFields)
+				loopGuard.record(result.ID, content)
 
 				a.addMessage(llm.Message{
 					Role:       llm.RoleTool,
@@ -287,6 +461,16 @@ func (a *agent) Query(ctx context.Context, query string) (*Response, error) {
 				})
 			}
 
+			batch := summarizeToolBatch(results)
+			toolFailures += batch.Failed
+			event := toolBatchEvent(ctx, "query", batch)
+			if streamChan != nil {
+				select {
+				case streamChan <- event:
+				case <-ctx.Done():
+				}
+			}
+
 			// Continue to next iteration for LLM to process tool results
 			// Reset tool choice for next iteration
 			toolChoice = "auto"
@@ -314,23 +498,78 @@ func (a *agent) Query(ctx context.Context, query string) (*Response, error) {
 		if message.Content != nil {
 			content = *message.Content
 		}
-		return &Response{
+		resp := &Response{
+			AgentName:    a.config.Name,
 			Content:      content,
 			ToolCalls:    allToolResults,
 			Usage:        &totalUsage,
 			FinishReason: choice.FinishReason,
-		}, nil
+			Iterations:   iteration + 1,
+			ToolFailures: toolFailures,
+		}
+		spent.apply(resp)
+		return resp, nil
 	}
 
-	logAgentEvent(ctx, "agent_error", map[string]interface{}{
-		"mode":  "query",
-		"error": fmt.Sprintf("max iterations (%d) reached without completion", a.config.MaxIterations),
+	// Out of iterations: hand back whatever the model last said rather than
+	// discarding it, and only fail when there is nothing to show.
+	if lastContent == "" {
+		logAgentEvent(ctx, "agent_error", map[string]interface{}{
+			"mode":  "query",
+			"error": fmt.Sprintf("max iterations (%d) reached without completion", a.config.MaxIterations),
+		})
+		return nil, fmt.Errorf("max iterations (%d) reached without completion", a.config.MaxIterations)
+	}
+	logAgentEvent(ctx, "run_complete", map[string]interface{}{
+		"mode":       "query",
+		"status":     "max_iterations",
+		"iterations": a.config.MaxIterations,
 	})
-	return nil, fmt.Errorf("max iterations (%d) reached without completion", a.config.MaxIterations)
+	resp = &Response{
+		AgentName:    a.config.Name,
+		Content:      lastContent,
+		ToolCalls:    allToolResults,
+		Usage:        &totalUsage,
+		Truncated:    true,
+		Iterations:   a.config.MaxIterations,
+		ToolFailures: toolFailures,
+	}
+	spent.apply(resp)
+	return resp, nil
 }
 
-// QueryStream sends a query and streams the response
+// queryCancelled returns ctx.Err() and logs the cancellation once the
+// caller's context is done, or nil while the run may continue.
+func queryCancelled(ctx context.Context, iteration int) error {
+	select {
+	case <-ctx.Done():
+		status := "cancelled"
+		if errors.Is(context.Cause(ctx), errQueryDeadline) {
+			status = "deadline"
+		}
+		logAgentEvent(ctx, "run_complete", map[string]interface{}{
+			"mode":      "query",
+			"status":    status,
+			"iteration": iteration,
+			"error":     ctx.Err().Error(),
+		})
+		return ctx.Err()
+	default:
+		return nil
+	}
+}
+
+// QueryStream sends a query and streams the response. The agent stays busy
+// until the returned channel is closed. A query that runs past
+// Config.Deadline ends with an error event wrapping context.DeadlineExceeded.
 func (a *agent) QueryStream(ctx context.Context, query string) (<-chan StreamEvent, error) {
+	if err := a.beginQuery(ctx); err != nil {
+		return nil, err
+	}
+	ctx, cancelDeadline := a.withDeadline(ctx)
+	ctx = a.withRetryBudget(ctx)
+	a.resetPacing()
+	a.selectModel(ctx)
 	originalMemory := a.GetMemory()
 	// Add user message to memory
 	a.addMessage(llm.Message{
@@ -342,20 +581,13 @@ func (a *agent) QueryStream(ctx context.Context, query string) (<-chan StreamEve
 	events := make(chan StreamEvent, 100)
 
 	// Get available tools
-	var availableTools []map[string]interface{}
-	if len(a.config.Tools) > 0 {
-		for _, toolName := range a.config.Tools {
-			if schema, err := a.toolRegistry.GetSchema(toolName); err == nil {
-				availableTools = append(availableTools, schema)
-			}
-		}
-	} else {
-		availableTools = a.toolRegistry.GetAllSchemas()
-	}
+	availableTools := a.availableToolSchemas()
 
 	// Start streaming goroutine
 	go func() {
 		defer close(events)
+		defer a.endQuery()
+		defer cancelDeadline()
 		completed := false
 		committedTurnState := false
 		defer func() {
@@ -363,21 +595,55 @@ func (a *agent) QueryStream(ctx context.Context, query string) (<-chan StreamEve
 			// next user message does not inherit an interrupted prompt unless the
 			// assistant/tool state had already been committed to memory.
 			if !completed && ctx.Err() != nil && !committedTurnState {
+				status := "cancelled"
+				if errors.Is(context.Cause(ctx), errQueryDeadline) {
+					status = "deadline"
+				}
 				logAgentEvent(ctx, "run_complete", map[string]interface{}{
 					"mode":   "stream",
-					"status": "cancelled",
+					"status": status,
 					"error":  ctx.Err().Error(),
 				})
 				a.SetMemory(originalMemory)
 			}
+			if err := a.deadlineError(ctx); err != nil && !completed {
+				events <- StreamEvent{
+					Type:  EventTypeError,
+					Error: err,
+				}
+			}
 		}()
 		totalToolCalls := 0
+		var totalUsage llm.Usage
+		lastContent := ""
+		toolChoice := "auto"
+		loopGuard := newToolLoopGuard(a.config.MaxRepeatedToolCalls)
 
 		for iteration := 0; iteration < a.config.MaxIterations; iteration++ {
 			if ctx.Err() != nil {
 				return
 			}
 
+			// Deliver messages the user queued while the previous step ran.
+			if iteration > 0 {
+				if queue := messageQueueFromContext(ctx); queue != nil {
+					for _, message := range queue.Drain() {
+						a.addMessage(llm.Message{
+							Role:    llm.RoleUser,
+							Content: llm.StringPtr(message),
+						})
+						logAgentEvent(ctx, "user_message_injected", map[string]interface{}{
+							"iteration": iteration + 1,
+							"length":    len(message),
+						})
+						events <- StreamEvent{
+							Type:    EventTypeUserMessage,
+							Content: message,
+						}
+					}
+				}
+			}
+
 			// Create chat request
 			request := &llm.ChatRequest{
 				Model:       a.config.Model,
@@ -385,7 +651,7 @@ func (a *agent) QueryStream(ctx context.Context, query string) (<-chan StreamEve
 				Temperature: a.config.Temperature,
 				MaxTokens:   a.config.MaxTokens,
 				Tools:       availableTools,
-				ToolChoice:  "auto",
+				ToolChoice:  toolChoice,
 				Stream:      true,
 			}
 			logAgentEvent(ctx, "llm_request", map[string]interface{}{
@@ -396,10 +662,23 @@ func (a *agent) QueryStream(ctx context.Context, query string) (<-chan StreamEve
 			})
 
 			// Send streaming request to LLM
+			if err := a.checkCostBudget(request); err != nil {
+				events <- StreamEvent{
+					Type:  EventTypeError,
+					Error: err,
+				}
+				return
+			}
+			if err := a.waitMinInterval(ctx); err != nil {
+				return
+			}
 			requestCtx, cancel := a.withRequestTimeout(ctx)
 			streamEvents, err := a.client.ChatStream(requestCtx, request)
 			if err != nil {
 				cancel()
+				if a.deadlineError(ctx) != nil {
+					return
+				}
 				logAgentEvent(ctx, "llm_error", map[string]interface{}{
 					"mode":      "stream",
 					"iteration": iteration + 1,
@@ -415,6 +694,8 @@ func (a *agent) QueryStream(ctx context.Context, query string) (<-chan StreamEve
 			// Collect the full response
 			var fullContent strings.Builder
 			var streamToolCalls []streamToolCallState
+			var stepUsage *llm.Usage
+			var streamModel, finishReason string
 			events <- StreamEvent{
 				Type:    EventTypeMessageStart,
 				Message: cloneLLMMessageForStream(llm.Message{Role: llm.RoleAssistant}),
@@ -431,6 +712,14 @@ func (a *agent) QueryStream(ctx context.Context, query string) (<-chan StreamEve
 					if !ok {
 						break streamLoop
 					}
+					// Providers report usage once in a trailing chunk or
+					// cumulatively on every chunk; keep the latest.
+					if event.Usage != nil {
+						stepUsage = event.Usage
+					}
+					if event.Model != "" {
+						streamModel = event.Model
+					}
 					if len(event.Choices) > 0 {
 						choice := event.Choices[0]
 
@@ -468,8 +757,9 @@ func (a *agent) QueryStream(ctx context.Context, query string) (<-chan StreamEve
 							}
 						}
 
-						// Check finish reason
-						// (finish reason handled later if needed)
+						if choice.FinishReason != "" {
+							finishReason = choice.FinishReason
+						}
 					}
 				}
 			}
@@ -479,6 +769,18 @@ func (a *agent) QueryStream(ctx context.Context, query string) (<-chan StreamEve
 				return
 			}
 
+			a.recordCost(request, streamModel, stepUsage)
+			if stepUsage != nil {
+				totalUsage.PromptTokens += stepUsage.PromptTokens
+				totalUsage.CompletionTokens += stepUsage.CompletionTokens
+				totalUsage.TotalTokens += stepUsage.TotalTokens
+				usage := totalUsage
+				events <- StreamEvent{
+					Type:  EventTypeUsage,
+					Usage: &usage,
+				}
+			}
+
 			// Create assistant message from collected content
 			contentStr := fullContent.String()
 			toolCalls := sanitizeLLMToolCalls(toLLMToolCallsFromStream(streamToolCalls))
@@ -520,12 +822,37 @@ func (a *agent) QueryStream(ctx context.Context, query string) (<-chan StreamEve
 			}
 			a.addMessage(assistantMsg)
 			committedTurnState = true
+			if strings.TrimSpace(contentStr) != "" {
+				lastContent = contentStr
+			}
 
 			// Execute tools if needed
 			if len(toolCalls) > 0 {
 				if ctx.Err() != nil {
 					return
 				}
+				if loopGuard.tripped {
+					a.addToolLoopReplies(toolCalls, loopGuard)
+					logAgentEvent(ctx, "agent_error", map[string]interface{}{
+						"mode":  "stream",
+						"error": ErrToolLoop.Error(),
+					})
+					events <- StreamEvent{
+						Type:  EventTypeError,
+						Error: ErrToolLoop,
+					}
+					return
+				}
+				if loopGuard.observe(toolCalls) {
+					a.addToolLoopReplies(toolCalls, loopGuard)
+					logAgentEvent(ctx, "tool_loop", map[string]interface{}{
+						"mode":      "stream",
+						"iteration": iteration + 1,
+						"repeats":   loopGuard.repeats,
+					})
+					toolChoice = "none"
+					continue
+				}
 				if a.config.MaxToolCalls > 0 && totalToolCalls+len(toolCalls) > a.config.MaxToolCalls {
 					events <- StreamEvent{
 						Type:  EventTypeError,
@@ -534,6 +861,7 @@ func (a *agent) QueryStream(ctx context.Context, query string) (<-chan StreamEve
 					return
 				}
 				totalToolCalls += len(toolCalls)
+				events <- toolProposalEvent(ctx, "stream", toolCalls)
 				// Convert to tool calls
 				calls := make([]tools.ToolCall, len(toolCalls))
 				for i, tc := range toolCalls {
@@ -562,8 +890,13 @@ func (a *agent) QueryStream(ctx context.Context, query string) (<-chan StreamEve
 					})
 				}
 
-				// Execute tools
-				results := a.toolRegistry.ExecuteToolCalls(ctx, calls)
+				// Execute tools, forwarding any progress they report
+				results := a.executePermittedTools(ctx, calls, func(calls []tools.ToolCall) []tools.ToolResult {
+					toolCtx := a.toolContext(ctx)
+					return a.toolRegistry.ExecuteToolCallsFunc(toolCtx, calls, func(tc tools.ToolCall) tools.ToolResult {
+						return a.toolRegistry.ExecuteToolCallWithProgress(toolCtx, tc, toolProgressReporter(toolCtx, tc, events))
+					})
+				})
 
 				// Send tool results and add to memory
 				for _, result := range results {
@@ -576,10 +909,11 @@ func (a *agent) QueryStream(ctx context.Context, query string) (<-chan StreamEve
 					events <- StreamEvent{
 						Type: EventTypeToolResult,
 						Tool: &ToolEvent{
-							ID:     result.ID,
-							Name:   result.Name,
-							Result: content,
-							Error:  result.Error,
+							ID:          result.ID,
+							Name:        result.Name,
+							Result:      content,
+							Error:       result.Error,
+							OutputBytes: result.OutputBytes,
 						},
 					}
 					toolFields := map[string]interface{}{
@@ -594,6 +928,7 @@ func (a *agent) QueryStream(ctx context.Context, query string) (<-chan StreamEve
 						toolFields["error"] = result.Error.Error()
 					}
 					logAgentEvent(ctx, "tool_result", toolFields)
+					loopGuard.record(result.ID, content)
 
 					// Add to memory
 					a.addMessage(llm.Message{
@@ -603,6 +938,7 @@ func (a *agent) QueryStream(ctx context.Context, query string) (<-chan StreamEve
 					})
 					committedTurnState = true
 				}
+				events <- toolBatchEvent(ctx, "stream", summarizeToolBatch(results))
 
 				// Continue to next iteration
 				continue
@@ -610,25 +946,42 @@ func (a *agent) QueryStream(ctx context.Context, query string) (<-chan StreamEve
 
 			// Send completion event
 			events <- StreamEvent{
-				Type: EventTypeComplete,
+				Type:         EventTypeComplete,
+				FinishReason: finishReason,
 			}
 			logAgentEvent(ctx, "run_complete", map[string]interface{}{
-				"mode":   "stream",
-				"status": "completed",
+				"mode":          "stream",
+				"status":        "completed",
+				"finish_reason": finishReason,
 			})
 			completed = true
 			return
 		}
 
-		// Max iterations reached
-		logAgentEvent(ctx, "agent_error", map[string]interface{}{
-			"mode":  "stream",
-			"error": fmt.Sprintf("max iterations (%d) reached", a.config.MaxIterations),
-		})
+		// Max iterations reached: partial output was already streamed, so end
+		// the run with a warning unless the model never produced any text.
+		if lastContent == "" {
+			logAgentEvent(ctx, "agent_error", map[string]interface{}{
+				"mode":  "stream",
+				"error": fmt.Sprintf("max iterations (%d) reached", a.config.MaxIterations),
+			})
+			events <- StreamEvent{
+				Type:  EventTypeError,
+				Error: fmt.Errorf("max iterations (%d) reached", a.config.MaxIterations),
+			}
+			return
+		}
 		events <- StreamEvent{
-			Type:  EventTypeError,
-			Error: fmt.Errorf("max iterations (%d) reached", a.config.MaxIterations),
+			Type:       EventTypeMaxIterations,
+			Content:    lastContent,
+			Iterations: a.config.MaxIterations,
 		}
+		logAgentEvent(ctx, "run_complete", map[string]interface{}{
+			"mode":       "stream",
+			"status":     "max_iterations",
+			"iterations": a.config.MaxIterations,
+		})
+		completed = true
 	}()
 
 	return events, nil
@@ -644,16 +997,9 @@ func (a *agent) Clear() {
 
 	// Re-add system prompt with tool list
 	if a.config.SystemPrompt != "" {
-		// Get tool information to enhance the system prompt
-		toolInfo := a.getToolListForPrompt()
-		enhancedPrompt := a.config.SystemPrompt
-		if toolInfo != "" {
-			enhancedPrompt = a.config.SystemPrompt + "\n\n" + toolInfo
-		}
-
 		a.memory.Messages = append(a.memory.Messages, llm.Message{
 			Role:    llm.RoleSystem,
-			Content: llm.StringPtr(enhancedPrompt),
+			Content: llm.StringPtr(a.buildSystemPrompt(a.config.SystemPrompt)),
 		})
 	}
 }
@@ -674,13 +1020,7 @@ func (a *agent) SetSystemPrompt(prompt string) {
 	defer a.mu.Unlock()
 
 	a.config.SystemPrompt = prompt
-
-	// Get tool information to enhance the system prompt
-	toolInfo := a.getToolListForPrompt()
-	enhancedPrompt := prompt
-	if toolInfo != "" {
-		enhancedPrompt = prompt + "\n\n" + toolInfo
-	}
+	enhancedPrompt := a.buildSystemPrompt(prompt)
 
 	// Update the first message if it's a system message
 	if len(a.memory.Messages) > 0 && a.memory.Messages[0].Role == llm.RoleSystem {
@@ -694,6 +1034,49 @@ func (a *agent) SetSystemPrompt(prompt string) {
 	}
 }
 
+// Name returns the configured agent name
+func (a *agent) Name() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.config.Name
+}
+
+// buildSystemPrompt renders the prompt template and appends the tool list.
+func (a *agent) buildSystemPrompt(prompt string) string {
+	prompt = renderPromptTemplate(prompt, a.config.Name)
+	if toolInfo := a.getToolListForPrompt(); toolInfo != "" {
+		prompt = prompt + "\n\n" + toolInfo
+	}
+	return prompt
+}
+
+// promptTemplateData holds the values available to system prompt templates.
+type promptTemplateData struct {
+	AgentName string
+}
+
+// renderPromptTemplate expands {{.AgentName}} in the system prompt. Prompts
+// that are not valid templates are used verbatim. When a name is configured
+// but the prompt does not reference it, the name is stated up front so the
+// model can identify itself.
+func renderPromptTemplate(prompt, name string) string {
+	if strings.Contains(prompt, "{{") {
+		if tmpl, err := template.New("system").Parse(prompt); err == nil {
+			var buf strings.Builder
+			if err := tmpl.Execute(&buf, promptTemplateData{AgentName: name}); err == nil {
+				if strings.Contains(prompt, ".AgentName") {
+					return buf.String()
+				}
+				prompt = buf.String()
+			}
+		}
+	}
+	if name != "" {
+		return fmt.Sprintf("Your name is %s.\n\n%s", name, prompt)
+	}
+	return prompt
+}
+
 // addMessage adds a message to memory with size management
 func (a *agent) addMessage(msg llm.Message) {
 	a.mu.Lock()
@@ -713,9 +1096,33 @@ func (a *agent) addMessage(msg llm.Message) {
 			trimCount := len(a.memory.Messages) - a.memory.MaxSize
 			a.memory.Messages = a.memory.Messages[trimCount:]
 		}
+		a.memory.Messages = dropOrphanedToolResults(a.memory.Messages)
 	}
 }
 
+// dropOrphanedToolResults removes tool results whose tool call is no longer
+// in messages. Trimming can drop an assistant message while keeping the
+// results that follow it, and providers reject a tool result that does not
+// answer a call earlier in the conversation.
+func dropOrphanedToolResults(messages []llm.Message) []llm.Message {
+	calls := make(map[string]bool)
+	kept := messages[:0]
+	for _, msg := range messages {
+		switch msg.Role {
+		case llm.RoleAssistant:
+			for _, tc := range msg.ToolCalls {
+				calls[tc.ID] = true
+			}
+		case llm.RoleTool:
+			if !calls[msg.ToolCallID] {
+				continue
+			}
+		}
+		kept = append(kept, msg)
+	}
+	return kept
+}
+
 // getMessages returns a copy of messages for API calls, ensuring compatibility.
 func (a *agent) getMessages() []llm.Message {
 	a.mu.RLock()
@@ -744,6 +1151,13 @@ func WithSystemPrompt(prompt string) Option {
 	}
 }
 
+// WithName sets the agent's name, used in its system prompt and responses.
+func WithName(name string) Option {
+	return func(c *Config) {
+		c.Name = strings.TrimSpace(name)
+	}
+}
+
 // WithModel sets the model ID to send on each chat request.
 func WithModel(model string) Option {
 	return func(c *Config) {
@@ -765,6 +1179,14 @@ func WithMaxToolCalls(max int) Option {
 	}
 }
 
+// WithMaxRepeatedToolCalls sets how many identical tool-call rounds may run
+// in a row before the agent stops tool use; 0 disables the check
+func WithMaxRepeatedToolCalls(max int) Option {
+	return func(c *Config) {
+		c.MaxRepeatedToolCalls = max
+	}
+}
+
 // WithTemperature sets the temperature
 func WithTemperature(temp float32) Option {
 	return func(c *Config) {
@@ -800,6 +1222,14 @@ func WithTimeout(d time.Duration) Option {
 	}
 }
 
+// WithDeadline caps the wall-clock time of each Query and QueryStream, LLM
+// calls, retries and tool runs included
+func WithDeadline(d time.Duration) Option {
+	return func(c *Config) {
+		c.Deadline = d
+	}
+}
+
 // WithTools sets the allowed tools
 func WithTools(tools []string) Option {
 	return func(c *Config) {
@@ -807,6 +1237,14 @@ func WithTools(tools []string) Option {
 	}
 }
 
+// WithBlockedTools disables tools by name, e.g. "bash", on top of any
+// WithTools allowlist.
+func WithBlockedTools(tools []string) Option {
+	return func(c *Config) {
+		c.BlockedTools = tools
+	}
+}
+
 // WithVerbose enables verbose mode
 func WithVerbose(verbose bool) Option {
 	return func(c *Config) {
@@ -821,6 +1259,90 @@ func WithMemorySize(size int) Option {
 	}
 }
 
+// WithRetryBudget caps the total number of LLM retries across all iterations
+// of a single Query. Zero leaves each call to its client's MaxRetries.
+func WithRetryBudget(n int) Option {
+	return func(c *Config) {
+		c.RetryBudget = n
+	}
+}
+
+// WithMinInterval spaces successive LLM calls within a query at least d
+// apart, to stay under provider rate limits during rapid tool iterations.
+func WithMinInterval(d time.Duration) Option {
+	return func(c *Config) {
+		c.MinInterval = d
+	}
+}
+
+// WithMinIntervalAcrossQueries applies WithMinInterval spacing between
+// queries as well, not just within one
+func WithMinIntervalAcrossQueries(enabled bool) Option {
+	return func(c *Config) {
+		c.MinIntervalAcrossQueries = enabled
+	}
+}
+
+// WithFallbackProviders retries a failed LLM request on each of providers in
+// turn, using their default models. The clients are built with the factory
+// given to WithClientFactory.
+func WithFallbackProviders(providers []string) Option {
+	return func(c *Config) {
+		c.FallbackProviders = providers
+	}
+}
+
+// WithClientFactory sets how WithFallbackProviders builds a client from a
+// provider name
+func WithClientFactory(factory func(provider string) (llm.Client, error)) Option {
+	return func(c *Config) {
+		c.clientFactory = factory
+	}
+}
+
+// WithJSONRepairAttempts lets QueryJSON re-prompt the model with the parse
+// error up to n times when its reply is not valid JSON
+func WithJSONRepairAttempts(n int) Option {
+	return func(c *Config) {
+		c.JSONRepairAttempts = n
+	}
+}
+
+// WithFailWhenBusy makes Query and QueryStream return ErrBusy when another
+// query is already running on the agent, instead of waiting for it to finish
+func WithFailWhenBusy(enabled bool) Option {
+	return func(c *Config) {
+		c.FailWhenBusy = enabled
+	}
+}
+
+// WithCostTracker prices every LLM request with ce and records its reported
+// usage, so ce.Session() holds the running cost
+func WithCostTracker(ce *cost.CostEstimator) Option {
+	return func(c *Config) {
+		c.CostTracker = ce
+	}
+}
+
+// WithMaxCost makes the agent refuse any request whose estimated cost would
+// take the cost tracker's session total past usd. It has no effect without
+// WithCostTracker.
+func WithMaxCost(usd float64) Option {
+	return func(c *Config) {
+		c.MaxCost = usd
+	}
+}
+
+// WithModelSelector picks the model from the client's ListModels before the
+// first query, overriding WithModel. The models carry prices from the cost
+// tracker's table so selectors such as llm.CheapestWithCapabilities can
+// compare them.
+func WithModelSelector(selector func([]llm.Model) llm.Model) Option {
+	return func(c *Config) {
+		c.ModelSelector = selector
+	}
+}
+
 // WithProgressHandler sets a progress handler function
 func WithProgressHandler(handler func(ProgressEvent)) Option {
 	return func(c *Config) {
@@ -897,12 +1419,12 @@ func (a *agent) getToolListForPrompt() string {
 	sort.Strings(toolNames)
 
 	for _, name := range toolNames {
-		tool, err := a.toolRegistry.Get(name)
+		desc, err := a.toolRegistry.Description(name)
 		if err != nil {
 			continue
 		}
 
-		toolInfo.WriteString(fmt.Sprintf("- %s: %s\n", name, tool.Description()))
+		toolInfo.WriteString(fmt.Sprintf("- %s: %s\n", name, desc))
 	}
 
 	toolInfo.WriteString("\n")
@@ -1432,103 +1954,202 @@ func cloneLLMMessageForStream(msg llm.Message) *llm.Message {
 	return &cloned
 }
 
-// executeToolsWithEvents executes tools and emits events without streaming
-func (a *agent) executeToolsWithEvents(ctx context.Context, calls []tools.ToolCall, eventChan chan<- StreamEvent) []tools.ToolResult {
-	results := make([]tools.ToolResult, len(calls))
-	var wg sync.WaitGroup
+// toolProgressReporter forwards progress reported by a running tool to
+// eventChan as EventTypeToolProgress events.
+func toolProgressReporter(ctx context.Context, tc tools.ToolCall, eventChan chan<- StreamEvent) tools.ProgressReporter {
+	return tools.ProgressFunc(func(message string, percent float64) {
+		if percent < 0 {
+			percent = 0
+		}
+		select {
+		case eventChan <- StreamEvent{
+			Type: EventTypeToolProgress,
+			Tool: &ToolEvent{
+				ID:       tc.ID,
+				Name:     tc.Name,
+				Progress: percent,
+				Message:  message,
+			},
+		}:
+		case <-ctx.Done():
+		}
+	})
+}
+
+// availableToolSchemas returns the schemas advertised to the model: the
+// WithTools allowlist (or every registered tool) minus blocked tools and
+// tools the registry reports as not configured.
+func (a *agent) availableToolSchemas() []map[string]interface{} {
+	names := a.config.Tools
+	if len(names) == 0 {
+		// If no specific tools configured, use all available tools
+		names = a.toolRegistry.List()
+	}
+
+	var schemas []map[string]interface{}
+	for _, toolName := range names {
+		if !a.toolPermitted(toolName) || !a.toolRegistry.Advertised(toolName) {
+			continue
+		}
+		if schema, err := a.toolRegistry.GetSchema(toolName); err == nil {
+			schemas = append(schemas, schema)
+		}
+	}
+	return schemas
+}
 
+// toolPermitted reports whether the agent may run the named tool.
+func (a *agent) toolPermitted(name string) bool {
+	for _, blocked := range a.config.BlockedTools {
+		if blocked == name {
+			return false
+		}
+	}
+	if len(a.config.Tools) == 0 {
+		return true
+	}
+	for _, allowed := range a.config.Tools {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// executePermittedTools runs the permitted calls through run and answers the
+// rest with an error result, so the model learns the tool is unavailable
+// without it ever executing. Results keep the order of calls.
+func (a *agent) executePermittedTools(ctx context.Context, calls []tools.ToolCall, run func([]tools.ToolCall) []tools.ToolResult) []tools.ToolResult {
+	results := make([]tools.ToolResult, len(calls))
+	permitted := make([]tools.ToolCall, 0, len(calls))
+	permittedIdx := make([]int, 0, len(calls))
 	for i, call := range calls {
-		wg.Add(1)
-		go func(idx int, tc tools.ToolCall) {
-			defer wg.Done()
+		if a.toolPermitted(call.Name) {
+			permitted = append(permitted, call)
+			permittedIdx = append(permittedIdx, i)
+			continue
+		}
+		logAgentEvent(ctx, "tool_denied", map[string]interface{}{
+			"tool_id": call.ID,
+			"tool":    call.Name,
+		})
+		results[i] = tools.ToolResult{
+			ID:    call.ID,
+			Name:  call.Name,
+			Error: tools.NewToolError("TOOL_NOT_PERMITTED", fmt.Sprintf("tool not permitted: %s", call.Name)),
+		}
+	}
 
-			// Generate unique ID if not present
-			if tc.ID == "" {
-				tc.ID = generateToolID()
-			}
+	if len(permitted) > 0 {
+		for j, result := range run(permitted) {
+			results[permittedIdx[j]] = result
+		}
+	}
+	return results
+}
 
-			args, normalizedArgs := llm.NormalizeToolArguments(tc.Arguments)
-			tc.Arguments = normalizedArgs
+// toolContext gives tools access to the agent's client and model, so tools
+// such as summarize can make their own requests.
+func (a *agent) toolContext(ctx context.Context) context.Context {
+	return tools.WithChatClient(ctx, a.client, a.config.Model)
+}
 
-			// Print to stderr in query mode (no event channel)
-			if eventChan == nil {
-				fmt.Fprintf(os.Stderr, "🔧 Calling tool: %s\n", tc.Name)
-			}
+// executeToolsWithEvents executes tools and emits events without streaming
+func (a *agent) executeToolsWithEvents(ctx context.Context, calls []tools.ToolCall, eventChan chan<- StreamEvent) []tools.ToolResult {
+	ctx = a.toolContext(ctx)
+	return a.toolRegistry.ExecuteToolCallsFunc(ctx, calls, func(tc tools.ToolCall) tools.ToolResult {
+		// Generate unique ID if not present
+		if tc.ID == "" {
+			tc.ID = generateToolID()
+		}
 
-			// Emit tool start event if channel provided
-			if eventChan != nil {
-				if os.Getenv("SIMPLE_AGENT_DEBUG") == "true" {
-					fmt.Fprintf(os.Stderr, "[Agent] Sending tool start event for %s (ID: %s)\n", tc.Name, tc.ID)
-				}
-				select {
-				case eventChan <- StreamEvent{
-					Type: EventTypeToolStart,
-					Tool: &ToolEvent{
-						ID:      tc.ID,
-						Name:    tc.Name,
-						Args:    args,
-						ArgsRaw: string(normalizedArgs),
-					},
-				}:
-				case <-ctx.Done():
-					return
+		args, normalizedArgs := llm.NormalizeToolArguments(tc.Arguments)
+		tc.Arguments = normalizedArgs
+
+		// Print to stderr in query mode (no event channel)
+		if eventChan == nil {
+			fmt.Fprintf(os.Stderr, "🔧 Calling tool: %s\n", tc.Name)
+		}
+
+		// Emit tool start event if channel provided
+		if eventChan != nil {
+			if os.Getenv("SIMPLE_AGENT_DEBUG") == "true" {
+				fmt.Fprintf(os.Stderr, "[Agent] Sending tool start event for %s (ID: %s)\n", tc.Name, tc.ID)
+			}
+			select {
+			case eventChan <- StreamEvent{
+				Type: EventTypeToolStart,
+				Tool: &ToolEvent{
+					ID:      tc.ID,
+					Name:    tc.Name,
+					Args:    args,
+					ArgsRaw: string(normalizedArgs),
+				},
+			}:
+			case <-ctx.Done():
+				return tools.ToolResult{
+					ID:    tc.ID,
+					Name:  tc.Name,
+					Error: tools.NewToolError("CANCELLED", "Not run: the request was cancelled").WithDetail("error", ctx.Err().Error()),
 				}
 			}
+		}
 
-			// Execute the tool
-			startTime := time.Now()
-			result := a.toolRegistry.ExecuteToolCall(ctx, tc)
-			duration := time.Since(startTime)
-			results[idx] = result
+		// Execute the tool, forwarding any progress it reports
+		var reporter tools.ProgressReporter
+		if eventChan != nil {
+			reporter = toolProgressReporter(ctx, tc, eventChan)
+		}
+		startTime := time.Now()
+		result := a.toolRegistry.ExecuteToolCallWithProgress(ctx, tc, reporter)
+		duration := time.Since(startTime)
 
-			// Print completion in query mode
-			if eventChan == nil {
-				fmt.Fprintf(os.Stderr, "🔧 %s completed in %v\n", tc.Name, duration)
-			}
+		// Print completion in query mode
+		if eventChan == nil {
+			fmt.Fprintf(os.Stderr, "🔧 %s completed in %v\n", tc.Name, duration)
+		}
 
-			// Emit tool result event if channel provided
-			if eventChan != nil {
-				eventType := EventTypeToolResult
-				if result.Error != nil {
-					// Distinguish cancel/timeout from generic errors when possible.
-					if toolErr, ok := result.Error.(*tools.ToolError); ok {
-						switch toolErr.Code {
-						case "EXECUTION_CANCELLED":
-							eventType = EventTypeToolCancel
-						case "EXECUTION_TIMEOUT":
-							eventType = EventTypeToolTimeout
-						}
-					}
-					if eventType == EventTypeToolResult {
-						lowerErr := strings.ToLower(result.Error.Error())
-						switch {
-						case strings.Contains(lowerErr, "context canceled"), strings.Contains(lowerErr, "cancelled"):
-							eventType = EventTypeToolCancel
-						case strings.Contains(lowerErr, "deadline exceeded"), strings.Contains(lowerErr, "timed out"):
-							eventType = EventTypeToolTimeout
-						}
+		// Emit tool result event if channel provided
+		if eventChan != nil {
+			eventType := EventTypeToolResult
+			if result.Error != nil {
+				// Distinguish cancel/timeout from generic errors when possible.
+				if toolErr, ok := result.Error.(*tools.ToolError); ok {
+					switch toolErr.Code {
+					case "EXECUTION_CANCELLED":
+						eventType = EventTypeToolCancel
+					case "EXECUTION_TIMEOUT":
+						eventType = EventTypeToolTimeout
 					}
 				}
-
-				select {
-				case eventChan <- StreamEvent{
-					Type: eventType,
-					Tool: &ToolEvent{
-						ID:      tc.ID,
-						Name:    tc.Name,
-						Args:    args,
-						ArgsRaw: string(normalizedArgs),
-						Result:  result.Result,
-						Error:   result.Error,
-					},
-				}:
-				case <-ctx.Done():
-					return
+				if eventType == EventTypeToolResult {
+					lowerErr := strings.ToLower(result.Error.Error())
+					switch {
+					case strings.Contains(lowerErr, "context canceled"), strings.Contains(lowerErr, "cancelled"):
+						eventType = EventTypeToolCancel
+					case strings.Contains(lowerErr, "deadline exceeded"), strings.Contains(lowerErr, "timed out"):
+						eventType = EventTypeToolTimeout
+					}
 				}
 			}
-		}(i, call)
-	}
 
-	wg.Wait()
-	return results
+			select {
+			case eventChan <- StreamEvent{
+				Type: eventType,
+				Tool: &ToolEvent{
+					ID:          tc.ID,
+					Name:        tc.Name,
+					Args:        args,
+					ArgsRaw:     string(normalizedArgs),
+					Result:      result.Result,
+					Display:     result.Display(),
+					Error:       result.Error,
+					OutputBytes: result.OutputBytes,
+				},
+			}:
+			case <-ctx.Done():
+			}
+		}
+		return result
+	})
 }
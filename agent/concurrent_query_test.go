@@ -0,0 +1,133 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/tools/registry"
+)
+
+// echoClient answers with the last user message, optionally waiting on
+// release first so tests can hold a query open.
+type echoClient struct {
+	scriptedClient
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c *echoClient) Chat(ctx context.Context, req *llm.ChatRequest) (*llm.ChatResponse, error) {
+	if c.started != nil {
+		c.started <- struct{}{}
+	}
+	if c.release != nil {
+		select {
+		case <-c.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	var last string
+	for _, msg := range req.Messages {
+		if msg.Role == llm.RoleUser {
+			last = llm.GetStringValue(msg.Content)
+		}
+	}
+	return &llm.ChatResponse{
+		Choices: []llm.Choice{{
+			Message:      llm.Message{Role: llm.RoleAssistant, Content: llm.StringPtr("echo: " + last)},
+			FinishReason: "stop",
+		}},
+	}, nil
+}
+
+func TestQuery_ConcurrentCallsKeepMemoryPaired(t *testing.T) {
+	a := New(&echoClient{}, WithModel("gpt-4o"), WithMemorySize(100)).(*agent)
+	a.toolRegistry = registry.New()
+
+	const queries = 8
+	var wg sync.WaitGroup
+	for i := 0; i < queries; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			query := fmt.Sprintf("question %d", i)
+			resp, err := a.Query(context.Background(), query)
+			if err != nil {
+				t.Errorf("Query %d: %v", i, err)
+				return
+			}
+			if resp.Content != "echo: "+query {
+				t.Errorf("Query %d: expected its own answer, got %q", i, resp.Content)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var turns []llm.Message
+	for _, msg := range a.GetMemory() {
+		if msg.Role != llm.RoleSystem {
+			turns = append(turns, msg)
+		}
+	}
+	if len(turns) != 2*queries {
+		t.Fatalf("expected %d messages, got %d", 2*queries, len(turns))
+	}
+	for i := 0; i < len(turns); i += 2 {
+		user, reply := turns[i], turns[i+1]
+		if user.Role != llm.RoleUser || reply.Role != llm.RoleAssistant {
+			t.Fatalf("turn %d is not a user/assistant pair: %s, %s", i/2, user.Role, reply.Role)
+		}
+		if want := "echo: " + llm.GetStringValue(user.Content); llm.GetStringValue(reply.Content) != want {
+			t.Fatalf("turn %d interleaved: %q answered with %q", i/2, llm.GetStringValue(user.Content), llm.GetStringValue(reply.Content))
+		}
+	}
+}
+
+func TestQuery_FailWhenBusyReturnsErrBusy(t *testing.T) {
+	client := &echoClient{started: make(chan struct{}, 1), release: make(chan struct{})}
+	a := New(client, WithModel("gpt-4o"), WithFailWhenBusy(true)).(*agent)
+	a.toolRegistry = registry.New()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := a.Query(context.Background(), "first")
+		done <- err
+	}()
+	<-client.started
+
+	if _, err := a.Query(context.Background(), "second"); !errors.Is(err, ErrBusy) {
+		t.Fatalf("expected ErrBusy from Query, got %v", err)
+	}
+	if _, err := a.QueryStream(context.Background(), "second"); !errors.Is(err, ErrBusy) {
+		t.Fatalf("expected ErrBusy from QueryStream, got %v", err)
+	}
+
+	close(client.release)
+	if err := <-done; err != nil {
+		t.Fatalf("first Query: %v", err)
+	}
+	if _, err := a.Query(context.Background(), "third"); err != nil {
+		t.Fatalf("expected the agent to be free again, got %v", err)
+	}
+}
+
+func TestQuery_WaitingCallHonoursContext(t *testing.T) {
+	client := &echoClient{started: make(chan struct{}, 1), release: make(chan struct{})}
+	a := New(client, WithModel("gpt-4o")).(*agent)
+	a.toolRegistry = registry.New()
+
+	go a.Query(context.Background(), "first")
+	<-client.started
+	defer close(client.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := a.Query(ctx, "second"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the queued Query to give up with its context, got %v", err)
+	}
+}
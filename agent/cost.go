@@ -0,0 +1,73 @@
+package agent
+
+import (
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/llm/cost"
+)
+
+// checkCostBudget refuses request when the cost tracker estimates it would
+// take the session past MaxCost
+func (a *agent) checkCostBudget(request *llm.ChatRequest) error {
+	if a.config.CostTracker == nil {
+		return nil
+	}
+	_, err := a.config.CostTracker.CheckBudget(request, a.config.MaxCost)
+	return err
+}
+
+// recordCost adds a completed request's usage to the cost tracker. The model
+// the provider reports is preferred since it names the exact snapshot that
+// was billed; clients fill request.Model with their default when it is empty.
+func (a *agent) recordCost(request *llm.ChatRequest, reportedModel string, usage *llm.Usage) {
+	if a.config.CostTracker == nil {
+		return
+	}
+	a.config.CostTracker.RecordUsage(billedModel(request, reportedModel), usage)
+}
+
+func billedModel(request *llm.ChatRequest, reportedModel string) string {
+	if reportedModel != "" {
+		return reportedModel
+	}
+	return request.Model
+}
+
+// prices returns the cost tracker's price table, or the default one when
+// cost tracking is off
+func (a *agent) prices() cost.PriceTable {
+	if a.config.CostTracker != nil {
+		return a.config.CostTracker.Prices()
+	}
+	return cost.DefaultPriceTable()
+}
+
+// queryCost sums the estimated cost of the requests one query makes
+type queryCost struct {
+	usd      float64
+	priced   int  // Requests with usage for a model in the price table
+	unpriced bool // Some request's model has no price
+}
+
+func (q *queryCost) add(prices cost.PriceTable, model string, usage *llm.Usage) {
+	if usage == nil {
+		return
+	}
+	price, ok := prices.Lookup(model)
+	if !ok {
+		q.unpriced = true
+		return
+	}
+	q.priced++
+	q.usd += price.Cost(usage.PromptTokens, usage.CompletionTokens)
+}
+
+// apply sets the response's estimated cost. The cost is unavailable, rather
+// than zero, when no usage was reported or any request went to a model
+// without a price.
+func (q queryCost) apply(resp *Response) {
+	resp.CostAvailable = q.priced > 0 && !q.unpriced
+	resp.EstimatedCostUSD = 0
+	if resp.CostAvailable {
+		resp.EstimatedCostUSD = q.usd
+	}
+}
@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/llm/cost"
+)
+
+func TestWithCostTracker_RecordsUsageAndEnforcesMaxCost(t *testing.T) {
+	tracker := cost.NewCostEstimator(cost.PriceTable{"test-model": {InputPerMTok: 1000, OutputPerMTok: 2000}})
+	client := &scriptedClient{reply: "ok", usage: &llm.Usage{PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500}}
+	a := New(client,
+		WithModel("test-model"),
+		WithMaxTokens(400), // bounds each estimate to about $0.80 of output
+		WithSystemPrompt("sys"),
+		WithTools([]string{}),
+		WithCostTracker(tracker),
+		WithMaxCost(2.5),
+	).(*agent)
+
+	if _, err := a.Query(context.Background(), "hi"); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	session := tracker.Session()
+	// 1000 prompt tokens at $1000/MTok + 500 completion at $2000/MTok
+	if session.Requests != 1 || session.USD != 2 {
+		t.Fatalf("expected $2 recorded for one request, got %+v", session)
+	}
+
+	_, err := a.Query(context.Background(), "again")
+	if !errors.Is(err, cost.ErrBudgetExceeded) {
+		t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+	}
+	if len(client.requests) != 1 {
+		t.Fatalf("expected the over-budget request not to be sent, got %d requests", len(client.requests))
+	}
+}
+
+func TestQuery_EstimatedCostFromUsage(t *testing.T) {
+	prices := cost.PriceTable{"test-model": {InputPerMTok: 3, OutputPerMTok: 15}}
+	client := &scriptedClient{reply: "ok", usage: &llm.Usage{PromptTokens: 2000, CompletionTokens: 400, TotalTokens: 2400}}
+	a := New(client, WithModel("test-model"), WithTools([]string{}), WithCostTracker(cost.NewCostEstimator(prices)))
+
+	resp, err := a.Query(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	// 2000 * $3/MTok + 400 * $15/MTok = $0.006 + $0.006
+	if !resp.CostAvailable || math.Abs(resp.EstimatedCostUSD-0.012) > 1e-12 {
+		t.Fatalf("expected $0.012, got available=%v cost=%v", resp.CostAvailable, resp.EstimatedCostUSD)
+	}
+}
+
+func TestQuery_EstimatedCostUnavailableForUnknownModel(t *testing.T) {
+	client := &scriptedClient{reply: "ok", usage: &llm.Usage{PromptTokens: 2000, CompletionTokens: 400, TotalTokens: 2400}}
+	a := New(client, WithModel("my-local-model"), WithTools([]string{}))
+
+	resp, err := a.Query(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if resp.CostAvailable || resp.EstimatedCostUSD != 0 {
+		t.Fatalf("expected cost to be unavailable, got available=%v cost=%v", resp.CostAvailable, resp.EstimatedCostUSD)
+	}
+}
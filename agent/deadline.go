@@ -0,0 +1,28 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// errQueryDeadline is the cancellation cause of a query that ran past
+// Config.Deadline, telling it apart from a caller's own cancellation
+var errQueryDeadline = errors.New("query deadline exceeded")
+
+// withDeadline bounds ctx by Config.Deadline when one is set
+func (a *agent) withDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if a.config.Deadline <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, a.config.Deadline, errQueryDeadline)
+}
+
+// deadlineError returns the error a query reports when ctx ran past
+// Config.Deadline, or nil when it did not. It wraps context.DeadlineExceeded.
+func (a *agent) deadlineError(ctx context.Context) error {
+	if !errors.Is(context.Cause(ctx), errQueryDeadline) {
+		return nil
+	}
+	return fmt.Errorf("query ran past its %s deadline: %w", a.config.Deadline, context.DeadlineExceeded)
+}
@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// slowToolClient takes delay to answer each request, and every answer says
+// which step it is and calls noop_tool again, so only a deadline ends the run.
+type slowToolClient struct {
+	scriptedClient
+	delay time.Duration
+	calls atomic.Int32
+}
+
+func (c *slowToolClient) step(ctx context.Context) (llm.Message, error) {
+	select {
+	case <-time.After(c.delay):
+	case <-ctx.Done():
+		return llm.Message{}, ctx.Err()
+	}
+	n := c.calls.Add(1)
+	return llm.Message{
+		Role:    llm.RoleAssistant,
+		Content: llm.StringPtr(fmt.Sprintf("step %d", n)),
+		ToolCalls: []llm.ToolCall{{
+			ID:       fmt.Sprintf("call_%d", n),
+			Type:     "function",
+			Function: llm.FunctionCall{Name: "noop_tool", Arguments: json.RawMessage(`{}`)},
+		}},
+	}, nil
+}
+
+func (c *slowToolClient) Chat(ctx context.Context, _ *llm.ChatRequest) (*llm.ChatResponse, error) {
+	msg, err := c.step(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &llm.ChatResponse{Choices: []llm.Choice{{Message: msg, FinishReason: "tool_calls"}}}, nil
+}
+
+func (c *slowToolClient) ChatStream(ctx context.Context, _ *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	msg, err := c.step(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan llm.StreamEvent, 1)
+	ch <- llm.StreamEvent{Choices: []llm.Choice{{Delta: &msg}}}
+	close(ch)
+	return ch, nil
+}
+
+func TestQuery_DeadlineReturnsPartialResults(t *testing.T) {
+	var runs atomic.Int32
+	client := &slowToolClient{delay: 60 * time.Millisecond}
+	a := newPermissionAgent(t, client, &runs, WithTools([]string{"noop_tool"}), WithDeadline(150*time.Millisecond))
+
+	start := time.Now()
+	resp, err := a.Query(context.Background(), "keep going")
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected an error wrapping context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed > 400*time.Millisecond {
+		t.Fatalf("expected the query to stop near its 150ms deadline, took %v", elapsed)
+	}
+	if resp == nil {
+		t.Fatal("expected the partial response alongside the error")
+	}
+	if resp.Content != "step 2" || !resp.Truncated {
+		t.Fatalf("expected the last content gathered, truncated, got %q (truncated=%v)", resp.Content, resp.Truncated)
+	}
+	if len(resp.ToolCalls) != 2 || runs.Load() != 2 {
+		t.Fatalf("expected the 2 tool calls run before the deadline, got %d results and %d runs", len(resp.ToolCalls), runs.Load())
+	}
+}
+
+func TestQuery_CallerCancellationIsNotADeadline(t *testing.T) {
+	var runs atomic.Int32
+	client := &slowToolClient{delay: 60 * time.Millisecond}
+	a := newPermissionAgent(t, client, &runs, WithTools([]string{"noop_tool"}), WithDeadline(time.Minute))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 90*time.Millisecond)
+	defer cancel()
+	resp, err := a.Query(ctx, "keep going")
+	if resp != nil || !errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), "ran past") {
+		t.Fatalf("expected the caller's own timeout unchanged, got %v, %v", resp, err)
+	}
+}
+
+func TestQueryStream_DeadlineEndsWithError(t *testing.T) {
+	var runs atomic.Int32
+	client := &slowToolClient{delay: 60 * time.Millisecond}
+	a := newPermissionAgent(t, client, &runs, WithTools([]string{"noop_tool"}), WithDeadline(150*time.Millisecond))
+
+	events, err := a.QueryStream(context.Background(), "keep going")
+	if err != nil {
+		t.Fatalf("QueryStream: %v", err)
+	}
+	var content strings.Builder
+	var last StreamEvent
+	for event := range events {
+		if event.Type == EventTypeMessage {
+			content.WriteString(event.Content)
+		}
+		last = event
+	}
+	if last.Type != EventTypeError || !errors.Is(last.Error, context.DeadlineExceeded) {
+		t.Fatalf("expected the stream to end with a deadline error, got %+v", last)
+	}
+	if !strings.Contains(content.String(), "step 2") {
+		t.Fatalf("expected the content streamed before the deadline, got %q", content.String())
+	}
+}
@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/llm/fallback"
+)
+
+// withFallbacks chains client with the configured fallback providers. A
+// provider whose client cannot be built stays in the chain as a client that
+// always fails, so the reason shows up in the FallbackError.
+func withFallbacks(client llm.Client, config Config) llm.Client {
+	if len(config.FallbackProviders) == 0 {
+		return client
+	}
+
+	chain := []llm.Client{client}
+	for _, provider := range config.FallbackProviders {
+		var fallbackClient llm.Client
+		err := errors.New("no client factory configured")
+		if config.clientFactory != nil {
+			fallbackClient, err = config.clientFactory(provider)
+		}
+		if err != nil {
+			fallbackClient = unavailableClient{provider: provider, err: fmt.Errorf("failed to create client: %w", err)}
+		}
+		chain = append(chain, fallbackClient)
+	}
+	return fallback.NewFallbackClient(chain)
+}
+
+// unavailableClient stands in for a fallback provider that could not be
+// created
+type unavailableClient struct {
+	provider string
+	err      error
+}
+
+func (c unavailableClient) Provider() string { return c.provider }
+
+func (c unavailableClient) Chat(context.Context, *llm.ChatRequest) (*llm.ChatResponse, error) {
+	return nil, c.err
+}
+
+func (c unavailableClient) ChatStream(context.Context, *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	return nil, c.err
+}
+
+func (c unavailableClient) ListModels(context.Context) ([]llm.Model, error) { return nil, c.err }
+
+func (c unavailableClient) GetModel(context.Context, string) (*llm.Model, error) { return nil, c.err }
+
+func (c unavailableClient) Close() error { return nil }
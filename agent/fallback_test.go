@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/llm/fallback"
+	"github.com/nachoal/simple-agent-go/tools/registry"
+)
+
+// failingClient fails every chat call with err
+type failingClient struct {
+	scriptedClient
+	err error
+}
+
+func (c *failingClient) Chat(context.Context, *llm.ChatRequest) (*llm.ChatResponse, error) {
+	return nil, c.err
+}
+
+func TestWithFallbackProviders_AnswersFromFallback(t *testing.T) {
+	backup := &scriptedClient{reply: "answered by anthropic"}
+	var built []string
+	a := New(&failingClient{err: errors.New("status 429: rate limited")},
+		WithModel("gpt-4o"),
+		WithClientFactory(func(provider string) (llm.Client, error) {
+			built = append(built, provider)
+			return backup, nil
+		}),
+		WithFallbackProviders([]string{"anthropic"}),
+	).(*agent)
+	a.toolRegistry = registry.New()
+
+	resp, err := a.Query(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if resp.Content != "answered by anthropic" {
+		t.Fatalf("expected the fallback's answer, got %q", resp.Content)
+	}
+	if len(built) != 1 || built[0] != "anthropic" {
+		t.Fatalf("expected the factory to build anthropic, got %v", built)
+	}
+	if len(backup.requests) != 1 || backup.requests[0].Model != "" {
+		t.Fatalf("expected the fallback to use its default model, got %+v", backup.requests)
+	}
+}
+
+func TestWithFallbackProviders_ReportsClientsThatCouldNotBeBuilt(t *testing.T) {
+	a := New(&failingClient{err: errors.New("status 503: overloaded")},
+		WithClientFactory(func(provider string) (llm.Client, error) {
+			return nil, errors.New("ANTHROPIC_API_KEY not set")
+		}),
+		WithFallbackProviders([]string{"anthropic"}),
+	).(*agent)
+	a.toolRegistry = registry.New()
+
+	_, err := a.Query(context.Background(), "hello")
+	var fallbackErr *fallback.FallbackError
+	if !errors.As(err, &fallbackErr) {
+		t.Fatalf("expected a FallbackError, got %T (%v)", err, err)
+	}
+	if len(fallbackErr.Attempts) != 2 || fallbackErr.Attempts[1].Provider != "anthropic" {
+		t.Fatalf("unexpected attempts %+v", fallbackErr.Attempts)
+	}
+	if !strings.Contains(err.Error(), "ANTHROPIC_API_KEY not set") {
+		t.Fatalf("expected the construction error in %q", err.Error())
+	}
+}
@@ -6,16 +6,21 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/nachoal/simple-agent-go/history"
 	"github.com/nachoal/simple-agent-go/internal/runlog"
 )
 
+// titleTimeout bounds the request that names a session
+const titleTimeout = 15 * time.Second
+
 // HistoryAgent wraps an agent with conversation history support
 type HistoryAgent struct {
 	Agent
 	historyManager *history.Manager
 	currentSession *history.Session
+	titleGenerator history.TitleGenerator
 }
 
 // NewHistoryAgent creates a new agent with history support
@@ -47,13 +52,15 @@ func (ha *HistoryAgent) Query(ctx context.Context, query string) (*Response, err
 
 		// Convert and store all new messages since our last save
 		// We need to sync our session with the agent's memory
-		ha.currentSession.Messages = ha.historyManager.ConvertFromLLMMessages(agentMemory)
+		ha.currentSession.SetMessages(ha.historyManager.ConvertFromLLMMessages(agentMemory))
 
 		// Save session with complete history
 		if saveErr := ha.historyManager.FinishRun(ha.currentSession, runID, history.RunStatusCompleted, nil); saveErr != nil {
 			// Log error but don't fail the query
 			fmt.Fprintf(os.Stderr, "\n[WARNING] Failed to save conversation history: %v\n", saveErr)
 			fmt.Fprintf(os.Stderr, "Your conversation may not be saved. Please check disk space and permissions.\n\n")
+		} else {
+			ha.generateTitle(ctx)
 		}
 	} else if err != nil && ha.currentSession != nil {
 		// Query failed - rollback to initial state
@@ -98,7 +105,7 @@ func (ha *HistoryAgent) QueryStream(ctx context.Context, query string) (<-chan S
 
 			agentMemory := ha.Agent.GetMemory()
 			if len(agentMemory) > initialAgentMessageCount {
-				ha.currentSession.Messages = ha.historyManager.ConvertFromLLMMessages(agentMemory)
+				ha.currentSession.SetMessages(ha.historyManager.ConvertFromLLMMessages(agentMemory))
 				return
 			}
 
@@ -106,30 +113,39 @@ func (ha *HistoryAgent) QueryStream(ctx context.Context, query string) (<-chan S
 		}
 
 		for event := range events {
-			// Forward the event
-			intercepted <- event
+			// Completion is forwarded once the session is saved, so the
+			// caller cannot start the next run while it is still being written.
+			if event.Type != EventTypeComplete && event.Type != EventTypeMaxIterations {
+				intercepted <- event
+			}
 
 			// Check for completion or error
 			switch event.Type {
-			case EventTypeComplete:
+			case EventTypeComplete, EventTypeMaxIterations:
 				streamSucceeded = true
+				var saveErr error
 				// Get the complete memory from the agent (includes all tool interactions)
 				if ha.currentSession != nil {
 					agentMemory := ha.Agent.GetMemory()
-					ha.currentSession.Messages = ha.historyManager.ConvertFromLLMMessages(agentMemory)
+					ha.currentSession.SetMessages(ha.historyManager.ConvertFromLLMMessages(agentMemory))
 
 					// Save session with complete history
-					if err := ha.historyManager.FinishRun(ha.currentSession, runID, history.RunStatusCompleted, nil); err != nil {
-						// Send error event through the stream
-						intercepted <- StreamEvent{
-							Type:  EventTypeError,
-							Error: fmt.Errorf("failed to save conversation history: %w", err),
-						}
-						// Also log to stderr
-						fmt.Fprintf(os.Stderr, "\n[WARNING] Failed to save conversation history: %v\n", err)
+					saveErr = ha.historyManager.FinishRun(ha.currentSession, runID, history.RunStatusCompleted, nil)
+					if saveErr == nil {
+						ha.generateTitle(ctx)
 					}
 					runFinalized = true
 				}
+				intercepted <- event
+				if saveErr != nil {
+					// Send error event through the stream
+					intercepted <- StreamEvent{
+						Type:  EventTypeError,
+						Error: fmt.Errorf("failed to save conversation history: %w", saveErr),
+					}
+					// Also log to stderr
+					fmt.Fprintf(os.Stderr, "\n[WARNING] Failed to save conversation history: %v\n", saveErr)
+				}
 			case EventTypeError:
 				// Stream failed - rollback the session
 				if ha.currentSession != nil && !streamSucceeded {
@@ -207,6 +223,35 @@ func statusFromRunError(ctx context.Context, err error) history.RunStatus {
 	return history.RunStatusCompleted
 }
 
+// SetTitleGenerator enables naming the session with generate once it has a
+// few messages; nil keeps the title taken from the first message.
+func (ha *HistoryAgent) SetTitleGenerator(generate history.TitleGenerator) {
+	ha.titleGenerator = generate
+}
+
+// TitleGenerator returns the generator set by SetTitleGenerator, if any
+func (ha *HistoryAgent) TitleGenerator() history.TitleGenerator {
+	return ha.titleGenerator
+}
+
+// generateTitle names the session when it is due for a generated title. It
+// runs after a run is saved, so a failure only logs and leaves the title
+// taken from the first message.
+func (ha *HistoryAgent) generateTitle(ctx context.Context) {
+	if ha.titleGenerator == nil || ha.currentSession == nil || ha.historyManager == nil {
+		return
+	}
+	titleCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), titleTimeout)
+	defer cancel()
+	if err := ha.historyManager.GenerateTitle(titleCtx, ha.currentSession, ha.titleGenerator); err != nil {
+		runlog.EventFromContext(ctx, "session_title_error", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	if ha.currentSession.Metadata.TitleGenerated {
+		runlog.EventFromContext(ctx, "session_title", map[string]interface{}{"title": ha.currentSession.Metadata.Title})
+	}
+}
+
 // GetSession returns the current session
 func (ha *HistoryAgent) GetSession() *history.Session {
 	return ha.currentSession
@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/nachoal/simple-agent-go/history"
@@ -53,6 +54,12 @@ func (a *preservingStubAgent) SetRequestParams(RequestParams) {}
 
 func (a *preservingStubAgent) GetRequestParams() RequestParams { return RequestParams{} }
 
+func (a *preservingStubAgent) QueryJSON(context.Context, string, interface{}) (*Response, error) {
+	return nil, nil
+}
+
+func (a *preservingStubAgent) Name() string { return "" }
+
 func TestHistoryAgentQueryStream_PreservesCommittedTurnOnCancel(t *testing.T) {
 	home := t.TempDir()
 	t.Setenv("HOME", home)
@@ -156,3 +163,89 @@ func TestHistoryAgentRestoreMemoryFromSessionUsesVisibleTranscript(t *testing.T)
 		t.Fatalf("unexpected restored assistant content: %+v", got[2])
 	}
 }
+
+func TestHistoryAgentTitlesSessionAfterSecondExchange(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	mgr, err := history.NewManager()
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	session, err := mgr.StartSession("/tmp/project", "openai", "gpt-4")
+	if err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+
+	ha := NewHistoryAgent(New(&scriptedClient{reply: "Sure."}), mgr, session)
+	calls := 0
+	ha.SetTitleGenerator(func(context.Context, []history.Message) (string, error) {
+		calls++
+		return "Planning the Release", nil
+	})
+
+	for _, query := range []string{"help me plan the release", "what about the changelog?"} {
+		if _, err := ha.Query(context.Background(), query); err != nil {
+			t.Fatalf("Query: %v", err)
+		}
+		if query == "help me plan the release" && calls != 0 {
+			t.Fatal("expected no title after the first exchange")
+		}
+	}
+
+	loaded, err := mgr.LoadSession(session.ID)
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if calls != 1 || loaded.Metadata.Title != "Planning the Release" {
+		t.Fatalf("expected the generated title saved after one call, got %q after %d calls", loaded.Metadata.Title, calls)
+	}
+}
+
+func TestHistoryAgentRecordsModelPerTurnAcrossSwitches(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	mgr, err := history.NewManager()
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	session, err := mgr.StartSession("/tmp/project", "openai", "gpt-4o")
+	if err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+
+	ha := NewHistoryAgent(New(&scriptedClient{reply: "From GPT."}), mgr, session)
+	if _, err := ha.Query(context.Background(), "first question"); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+
+	// Switch models the way /model does: new agent, same memory and session.
+	replacement := New(&scriptedClient{reply: "From Claude."})
+	replacement.SetMemory(ha.GetMemory())
+	ha.ReplaceAgent(replacement)
+	session.Provider, session.Model = "anthropic", "claude-sonnet-4-20250514"
+	if _, err := ha.Query(context.Background(), "second question"); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+
+	loaded, err := mgr.LoadSession(session.ID)
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	var got []string
+	for _, msg := range loaded.Messages {
+		if msg.Role == "assistant" {
+			got = append(got, msg.ModelName()+": "+*msg.Content)
+		}
+	}
+	want := []string{"openai/gpt-4o: From GPT.", "anthropic/claude-sonnet-4-20250514: From Claude."}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("expected each turn's model to survive save and load, got %q", got)
+	}
+	if used := loaded.ModelsUsed(); len(used) != 2 {
+		t.Fatalf("expected two models used, got %q", used)
+	}
+}
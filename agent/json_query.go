@@ -0,0 +1,117 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// ErrInvalidJSON is returned by QueryJSON when the final reply still does not
+// decode, after any repair attempts allowed by WithJSONRepairAttempts.
+var ErrInvalidJSON = errors.New("model did not return valid JSON")
+
+const jsonInstruction = "Respond with only a single valid JSON value: no prose and no code fences."
+
+type jsonModeKey struct{}
+
+// withJSONMode marks ctx so Query asks the provider for a JSON object reply.
+func withJSONMode(ctx context.Context) context.Context {
+	return context.WithValue(ctx, jsonModeKey{}, true)
+}
+
+func jsonResponseFormat(ctx context.Context) *llm.ResponseFormat {
+	if on, _ := ctx.Value(jsonModeKey{}).(bool); on {
+		return &llm.ResponseFormat{Type: "json_object"}
+	}
+	return nil
+}
+
+// QueryJSON sends query asking for a JSON reply and decodes it into v. When
+// the reply does not decode and the agent was created with
+// WithJSONRepairAttempts, the parse error is sent back to the model as a new
+// turn so it can correct itself. The returned Response is the last reply, with
+// usage summed across attempts.
+func (a *agent) QueryJSON(ctx context.Context, query string, v interface{}) (*Response, error) {
+	return queryJSON(ctx, a.Query, a.config.JSONRepairAttempts, query, v)
+}
+
+// QueryJSON is the history-saving counterpart of the wrapped agent's
+// QueryJSON; every attempt is recorded in the session.
+func (ha *HistoryAgent) QueryJSON(ctx context.Context, query string, v interface{}) (*Response, error) {
+	attempts := 0
+	if inner, ok := ha.Agent.(*agent); ok {
+		attempts = inner.config.JSONRepairAttempts
+	}
+	return queryJSON(ctx, ha.Query, attempts, query, v)
+}
+
+func queryJSON(ctx context.Context, query func(context.Context, string) (*Response, error), attempts int, prompt string, v interface{}) (*Response, error) {
+	ctx = withJSONMode(ctx)
+	response, err := query(ctx, prompt+"\n\n"+jsonInstruction)
+	if err != nil {
+		return nil, err
+	}
+	usage := response.Usage
+
+	for attempt := 0; ; attempt++ {
+		decodeErr := decodeJSONReply(response.Content, v)
+		if decodeErr == nil {
+			response.Usage = usage
+			return response, nil
+		}
+		if attempt >= attempts {
+			response.Usage = usage
+			if attempts > 0 {
+				return response, fmt.Errorf("%w after %d repair attempt(s): %v", ErrInvalidJSON, attempts, decodeErr)
+			}
+			return response, fmt.Errorf("%w: %v", ErrInvalidJSON, decodeErr)
+		}
+
+		logAgentEvent(ctx, "json_repair", map[string]interface{}{
+			"attempt": attempt + 1,
+			"error":   decodeErr.Error(),
+		})
+		repair := fmt.Sprintf("Your previous reply could not be parsed as JSON: %v\n%s", decodeErr, jsonInstruction)
+		response, err = query(ctx, repair)
+		if err != nil {
+			return nil, err
+		}
+		usage = addUsage(usage, response.Usage)
+	}
+}
+
+// decodeJSONReply decodes content into v, tolerating surrounding whitespace
+// and a Markdown code fence, which models add even when told not to.
+func decodeJSONReply(content string, v interface{}) error {
+	content = strings.TrimSpace(content)
+	if strings.HasPrefix(content, "```") {
+		content = strings.TrimPrefix(content, "```")
+		if newline := strings.IndexByte(content, '\n'); newline >= 0 {
+			content = content[newline+1:]
+		}
+		content = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(content), "```"))
+	}
+	if content == "" {
+		return errors.New("the reply was empty")
+	}
+	return json.Unmarshal([]byte(content), v)
+}
+
+func addUsage(total, next *llm.Usage) *llm.Usage {
+	if next == nil {
+		return total
+	}
+	if total == nil {
+		copied := *next
+		return &copied
+	}
+	return &llm.Usage{
+		PromptTokens:     total.PromptTokens + next.PromptTokens,
+		CompletionTokens: total.CompletionTokens + next.CompletionTokens,
+		TotalTokens:      total.TotalTokens + next.TotalTokens,
+	}
+}
@@ -0,0 +1,107 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/tools/registry"
+)
+
+// sequenceClient replies with each of replies in turn, repeating the last.
+type sequenceClient struct {
+	scriptedClient
+	mu       sync.Mutex
+	replies  []string
+	requests []*llm.ChatRequest
+}
+
+func (c *sequenceClient) Chat(_ context.Context, req *llm.ChatRequest) (*llm.ChatResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	reply := c.replies[min(len(c.requests), len(c.replies)-1)]
+	c.requests = append(c.requests, req)
+	return &llm.ChatResponse{
+		Choices: []llm.Choice{{
+			Message:      llm.Message{Role: llm.RoleAssistant, Content: llm.StringPtr(reply)},
+			FinishReason: "stop",
+		}},
+		Usage: &llm.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+	}, nil
+}
+
+func newJSONTestAgent(client llm.Client, opts ...Option) Agent {
+	a := New(client, append([]Option{WithModel("gpt-4o")}, opts...)...).(*agent)
+	a.toolRegistry = registry.New()
+	return a
+}
+
+func lastUserContent(req *llm.ChatRequest) string {
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == llm.RoleUser {
+			return llm.GetStringValue(req.Messages[i].Content)
+		}
+	}
+	return ""
+}
+
+func TestQueryJSON_RepairsInvalidReply(t *testing.T) {
+	client := &sequenceClient{replies: []string{`{"city": "Paris",}`, "```json\n{\"city\": \"Paris\"}\n```"}}
+	a := newJSONTestAgent(client, WithJSONRepairAttempts(2))
+
+	var out struct {
+		City string `json:"city"`
+	}
+	resp, err := a.QueryJSON(context.Background(), "Which city is the Louvre in?", &out)
+	if err != nil {
+		t.Fatalf("QueryJSON: %v", err)
+	}
+	if out.City != "Paris" {
+		t.Fatalf("expected the repaired reply to decode, got %+v", out)
+	}
+	if len(client.requests) != 2 {
+		t.Fatalf("expected one repair round trip, got %d requests", len(client.requests))
+	}
+	if rf := client.requests[0].ResponseFormat; rf == nil || rf.Type != "json_object" {
+		t.Fatalf("expected JSON mode on the request, got %+v", rf)
+	}
+	if repair := lastUserContent(client.requests[1]); !strings.Contains(repair, "could not be parsed as JSON") || !strings.Contains(repair, "invalid character") {
+		t.Fatalf("expected the parse error in the repair prompt, got %q", repair)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 30 {
+		t.Fatalf("expected usage summed across attempts, got %+v", resp.Usage)
+	}
+}
+
+func TestQueryJSON_FailsWhenRepairsExhausted(t *testing.T) {
+	client := &sequenceClient{replies: []string{"not json"}}
+	a := newJSONTestAgent(client, WithJSONRepairAttempts(2))
+
+	var out map[string]interface{}
+	resp, err := a.QueryJSON(context.Background(), "List three colours", &out)
+	if !errors.Is(err, ErrInvalidJSON) || !strings.Contains(err.Error(), "after 2 repair attempt(s)") {
+		t.Fatalf("expected ErrInvalidJSON after 2 repairs, got %v", err)
+	}
+	if len(client.requests) != 3 {
+		t.Fatalf("expected the first try plus 2 repairs, got %d requests", len(client.requests))
+	}
+	if resp == nil || resp.Content != "not json" {
+		t.Fatalf("expected the last reply alongside the error, got %+v", resp)
+	}
+}
+
+func TestQueryJSON_NoRepairByDefault(t *testing.T) {
+	client := &sequenceClient{replies: []string{"not json", `{"ok": true}`}}
+	a := newJSONTestAgent(client)
+
+	var out map[string]interface{}
+	if _, err := a.QueryJSON(context.Background(), "Say ok", &out); !errors.Is(err, ErrInvalidJSON) {
+		t.Fatalf("expected ErrInvalidJSON, got %v", err)
+	}
+	if len(client.requests) != 1 {
+		t.Fatalf("expected no repair attempts by default, got %d requests", len(client.requests))
+	}
+}
@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// ErrToolLoop is returned when the model keeps calling tools after the agent
+// caught it repeating the same tool call and asked it to answer instead.
+var ErrToolLoop = errors.New("model kept repeating the same tool call")
+
+// toolLoopGuard notices a model making the same tool calls, with the same
+// arguments, round after round; without it such a run only ends at
+// MaxIterations.
+type toolLoopGuard struct {
+	limit   int               // identical rounds allowed to run; 0 disables the guard
+	key     string            // the calls of the last round
+	repeats int               // rounds in a row that made those calls
+	pending map[string]string // call ID to call key for the round being run
+	results map[string]string // each call's result the last time it ran
+	tripped bool              // the model was told to stop calling tools
+}
+
+func newToolLoopGuard(limit int) *toolLoopGuard {
+	return &toolLoopGuard{
+		limit:   limit,
+		pending: make(map[string]string),
+		results: make(map[string]string),
+	}
+}
+
+// observe records a round of tool calls and reports whether it is one
+// identical round too many, in which case the calls should be answered with
+// reply instead of run and the model asked for a final answer.
+func (g *toolLoopGuard) observe(calls []llm.ToolCall) bool {
+	if g.limit <= 0 {
+		return false
+	}
+	keys := make([]string, len(calls))
+	clear(g.pending)
+	for i, tc := range calls {
+		keys[i] = toolCallKey(tc)
+		g.pending[tc.ID] = keys[i]
+	}
+	key := strings.Join(keys, "\n")
+	if key == g.key {
+		g.repeats++
+	} else {
+		g.key, g.repeats = key, 1
+		clear(g.results)
+	}
+	if g.repeats > g.limit {
+		g.tripped = true
+	}
+	return g.tripped
+}
+
+// record keeps a call's result to quote back if the model repeats it
+func (g *toolLoopGuard) record(id, content string) {
+	if key, ok := g.pending[id]; ok {
+		g.results[key] = content
+	}
+}
+
+// reply is the tool message sent in place of running a repeated call
+func (g *toolLoopGuard) reply(tc llm.ToolCall) string {
+	if result, ok := g.results[toolCallKey(tc)]; ok {
+		return fmt.Sprintf("You already called %s with these arguments %d times in a row; the result was:\n%s\n\nDo not call it again. Answer with the information you already have.", tc.Function.Name, g.limit, result)
+	}
+	return fmt.Sprintf("%s was not run: tool use stopped because the same tool call kept repeating. Answer with the information you already have.", tc.Function.Name)
+}
+
+func toolCallKey(tc llm.ToolCall) string {
+	_, args := llm.NormalizeToolArguments(tc.Function.Arguments)
+	return tc.Function.Name + " " + string(args)
+}
+
+// addToolLoopReplies answers every call of a round with the guard's reply so
+// memory never holds tool calls without results.
+func (a *agent) addToolLoopReplies(calls []llm.ToolCall, guard *toolLoopGuard) {
+	for _, tc := range calls {
+		a.addMessage(llm.Message{
+			Role:       llm.RoleTool,
+			Content:    llm.StringPtr(guard.reply(tc)),
+			ToolCallID: tc.ID,
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// repeatingToolClient makes the same noop_tool call on every step. When
+// obey is set it answers in text once a request turns tool use off.
+type repeatingToolClient struct {
+	loopingToolClient
+	obey    bool
+	choices []string
+}
+
+func (c *repeatingToolClient) Chat(ctx context.Context, req *llm.ChatRequest) (*llm.ChatResponse, error) {
+	c.choices = append(c.choices, req.ToolChoice.(string))
+	if c.obey && req.ToolChoice == "none" {
+		return &llm.ChatResponse{Choices: []llm.Choice{{
+			Message:      llm.Message{Role: llm.RoleAssistant, Content: llm.StringPtr("The tool says ok.")},
+			FinishReason: "stop",
+		}}}, nil
+	}
+	return c.loopingToolClient.Chat(ctx, req)
+}
+
+func (c *repeatingToolClient) ChatStream(ctx context.Context, req *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	c.choices = append(c.choices, req.ToolChoice.(string))
+	return c.loopingToolClient.ChatStream(ctx, req)
+}
+
+func TestQuery_RepeatedToolCallsAreAnsweredFromLastResult(t *testing.T) {
+	client := &repeatingToolClient{obey: true}
+	a := newLoopingAgent(t, client)
+	a.config.MaxIterations = 10
+	a.config.MaxRepeatedToolCalls = 2
+
+	resp, err := a.Query(context.Background(), "check the tool")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if resp.Content != "The tool says ok." || len(resp.ToolCalls) != 2 {
+		t.Fatalf("expected an answer after two tool runs, got %+v", resp)
+	}
+	if want := []string{"auto", "auto", "auto", "none"}; !slices.Equal(client.choices, want) {
+		t.Fatalf("expected tool choices %v, got %v", want, client.choices)
+	}
+
+	memory := a.GetMemory()
+	reply := memory[len(memory)-2]
+	if reply.Role != llm.RoleTool || reply.ToolCallID != "call_3" || reply.Content == nil ||
+		!strings.Contains(*reply.Content, "You already called noop_tool with these arguments 2 times in a row; the result was:\nok") {
+		t.Fatalf("expected the repeated call to be answered with the last result, got %+v", reply)
+	}
+}
+
+func TestQueryStream_StopsWhenToolCallsKeepRepeating(t *testing.T) {
+	client := &repeatingToolClient{}
+	a := newLoopingAgent(t, client)
+	a.config.MaxIterations = 10
+	a.config.MaxRepeatedToolCalls = 2
+
+	stream, err := a.QueryStream(context.Background(), "check the tool")
+	if err != nil {
+		t.Fatalf("QueryStream: %v", err)
+	}
+	var last StreamEvent
+	for event := range stream {
+		last = event
+	}
+	if last.Type != EventTypeError || !errors.Is(last.Error, ErrToolLoop) {
+		t.Fatalf("expected the run to end with ErrToolLoop, got %+v", last)
+	}
+	if client.calls != 4 {
+		t.Fatalf("expected the run to stop on the fourth step, got %d", client.calls)
+	}
+
+	// Every tool call in memory still has a result.
+	memory := a.GetMemory()
+	if lastMsg := memory[len(memory)-1]; lastMsg.Role != llm.RoleTool || lastMsg.ToolCallID != "call_4" {
+		t.Fatalf("expected the final call to be answered, got %+v", lastMsg)
+	}
+}
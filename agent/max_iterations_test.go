@@ -0,0 +1,130 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/tools"
+	"github.com/nachoal/simple-agent-go/tools/base"
+	"github.com/nachoal/simple-agent-go/tools/registry"
+)
+
+// loopingToolClient calls noop_tool on every step and never finishes. When
+// narrate is set, each step also carries text describing progress so far.
+type loopingToolClient struct {
+	scriptedClient
+	narrate bool
+	calls   int
+}
+
+func (c *loopingToolClient) next() llm.Message {
+	c.calls++
+	msg := llm.Message{
+		Role: llm.RoleAssistant,
+		ToolCalls: []llm.ToolCall{{
+			ID:       fmt.Sprintf("call_%d", c.calls),
+			Type:     "function",
+			Function: llm.FunctionCall{Name: "noop_tool", Arguments: json.RawMessage(`{}`)},
+		}},
+	}
+	if c.narrate {
+		msg.Content = llm.StringPtr(fmt.Sprintf("Found %d of 3 files so far.", c.calls))
+	}
+	return msg
+}
+
+func (c *loopingToolClient) Chat(context.Context, *llm.ChatRequest) (*llm.ChatResponse, error) {
+	return &llm.ChatResponse{Choices: []llm.Choice{{Message: c.next(), FinishReason: "tool_calls"}}}, nil
+}
+
+func (c *loopingToolClient) ChatStream(context.Context, *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	delta := c.next()
+	ch := make(chan llm.StreamEvent, 1)
+	ch <- llm.StreamEvent{Choices: []llm.Choice{{Delta: &delta}}}
+	close(ch)
+	return ch, nil
+}
+
+func newLoopingAgent(t *testing.T, client llm.Client) *agent {
+	t.Helper()
+	reg := registry.New()
+	if err := reg.Register("noop_tool", func() tools.Tool {
+		return &noopTool{BaseTool: base.BaseTool{ToolName: "noop_tool", ToolDesc: "does nothing"}}
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	a := New(client, WithTools([]string{"noop_tool"}), WithMaxIterations(3)).(*agent)
+	a.toolRegistry = reg
+	return a
+}
+
+func TestQuery_MaxIterationsReturnsPartialContent(t *testing.T) {
+	a := newLoopingAgent(t, &loopingToolClient{narrate: true})
+
+	resp, err := a.Query(context.Background(), "find the files")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if !resp.Truncated || resp.Iterations != 3 {
+		t.Fatalf("expected truncated response after 3 iterations, got %+v", resp)
+	}
+	if resp.Content != "Found 3 of 3 files so far." {
+		t.Fatalf("expected last assistant content, got %q", resp.Content)
+	}
+	if len(resp.ToolCalls) != 3 {
+		t.Fatalf("expected tool results to be kept, got %d", len(resp.ToolCalls))
+	}
+}
+
+func TestQuery_MaxIterationsWithoutContentFails(t *testing.T) {
+	a := newLoopingAgent(t, &loopingToolClient{})
+
+	resp, err := a.Query(context.Background(), "find the files")
+	if err == nil || !strings.Contains(err.Error(), "max iterations (3)") {
+		t.Fatalf("expected max iterations error, got resp=%+v err=%v", resp, err)
+	}
+}
+
+func TestQueryStream_MaxIterationsEmitsWarning(t *testing.T) {
+	a := newLoopingAgent(t, &loopingToolClient{narrate: true})
+
+	stream, err := a.QueryStream(context.Background(), "find the files")
+	if err != nil {
+		t.Fatalf("QueryStream: %v", err)
+	}
+	var last StreamEvent
+	for event := range stream {
+		if event.Type == EventTypeError {
+			t.Fatalf("unexpected error event: %v", event.Error)
+		}
+		last = event
+	}
+
+	if last.Type != EventTypeMaxIterations || last.Iterations != 3 {
+		t.Fatalf("expected a final max_iterations event, got %+v", last)
+	}
+	if last.Content != "Found 3 of 3 files so far." {
+		t.Fatalf("expected last assistant content, got %q", last.Content)
+	}
+}
+
+func TestQueryStream_MaxIterationsWithoutContentErrors(t *testing.T) {
+	a := newLoopingAgent(t, &loopingToolClient{})
+
+	stream, err := a.QueryStream(context.Background(), "find the files")
+	if err != nil {
+		t.Fatalf("QueryStream: %v", err)
+	}
+	var last StreamEvent
+	for event := range stream {
+		last = event
+	}
+
+	if last.Type != EventTypeError || last.Error == nil || !strings.Contains(last.Error.Error(), "max iterations (3)") {
+		t.Fatalf("expected a final max iterations error, got %+v", last)
+	}
+}
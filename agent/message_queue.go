@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+type messageQueueKey struct{}
+
+// MessageQueue holds user messages sent while a streamed run is in progress.
+// QueryStream adds queued messages to memory before its next LLM request, so
+// they can steer the rest of the run without cancelling it. It is safe for
+// concurrent use.
+type MessageQueue struct {
+	mu      sync.Mutex
+	pending []string
+}
+
+// NewMessageQueue returns an empty queue.
+func NewMessageQueue() *MessageQueue {
+	return &MessageQueue{}
+}
+
+// Push queues a message. Blank messages are ignored.
+func (q *MessageQueue) Push(message string) {
+	if strings.TrimSpace(message) == "" {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = append(q.pending, message)
+}
+
+// Drain removes and returns every queued message in the order it was pushed.
+func (q *MessageQueue) Drain() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	pending := q.pending
+	q.pending = nil
+	return pending
+}
+
+// Len returns the number of queued messages.
+func (q *MessageQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// WithMessageQueue attaches q to ctx so QueryStream delivers its messages at
+// the next iteration boundary.
+func WithMessageQueue(ctx context.Context, q *MessageQueue) context.Context {
+	return context.WithValue(ctx, messageQueueKey{}, q)
+}
+
+func messageQueueFromContext(ctx context.Context) *MessageQueue {
+	q, _ := ctx.Value(messageQueueKey{}).(*MessageQueue)
+	return q
+}
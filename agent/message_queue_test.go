@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/tools"
+	"github.com/nachoal/simple-agent-go/tools/base"
+	"github.com/nachoal/simple-agent-go/tools/registry"
+)
+
+// queueingStreamClient queues a user message while the first step is
+// streaming and records every request it receives.
+type queueingStreamClient struct {
+	messageUpdateStreamClient
+	queue    *MessageQueue
+	requests [][]llm.Message
+}
+
+func (c *queueingStreamClient) ChatStream(ctx context.Context, req *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	c.requests = append(c.requests, append([]llm.Message(nil), req.Messages...))
+	if len(c.requests) == 1 {
+		c.queue.Push("actually focus on the auth module")
+	}
+	return c.messageUpdateStreamClient.ChatStream(ctx, req)
+}
+
+func TestQueryStream_DeliversQueuedMessageAtNextIteration(t *testing.T) {
+	reg := registry.New()
+	if err := reg.Register("noop_tool", func() tools.Tool {
+		return &noopTool{BaseTool: base.BaseTool{ToolName: "noop_tool", ToolDesc: "does nothing"}}
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	queue := NewMessageQueue()
+	client := &queueingStreamClient{queue: queue}
+	a := New(client, WithTools([]string{"noop_tool"})).(*agent)
+	a.toolRegistry = reg
+
+	stream, err := a.QueryStream(WithMessageQueue(context.Background(), queue), "review the code")
+	if err != nil {
+		t.Fatalf("QueryStream: %v", err)
+	}
+	var delivered []string
+	for event := range stream {
+		if event.Type == EventTypeUserMessage {
+			delivered = append(delivered, event.Content)
+		}
+	}
+
+	if len(client.requests) != 2 {
+		t.Fatalf("expected 2 LLM requests, got %d", len(client.requests))
+	}
+	for _, msg := range client.requests[0] {
+		if llm.GetStringValue(msg.Content) == "actually focus on the auth module" {
+			t.Fatal("queued message must not reach the request that was already in flight")
+		}
+	}
+	second := client.requests[1]
+	last := second[len(second)-1]
+	if last.Role != llm.RoleUser || llm.GetStringValue(last.Content) != "actually focus on the auth module" {
+		t.Fatalf("expected queued message to end the next request, got %+v", last)
+	}
+	if prev := second[len(second)-2]; prev.Role != llm.RoleTool {
+		t.Fatalf("expected queued message after the tool result, got %s", prev.Role)
+	}
+	if len(delivered) != 1 || queue.Len() != 0 {
+		t.Fatalf("expected one delivery event and an empty queue, got %v (len %d)", delivered, queue.Len())
+	}
+}
@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// fakeClock is a manual clock whose sleep advances time instantly.
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Sleep(_ context.Context, d time.Duration) error {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	return nil
+}
+
+// clockedClient records the fake time at which each Chat call starts, and
+// makes each call take a little time.
+type clockedClient struct {
+	*callThenAnswerClient
+	clock   *fakeClock
+	callAt  []time.Time
+	latency time.Duration
+}
+
+func (c *clockedClient) Chat(ctx context.Context, req *llm.ChatRequest) (*llm.ChatResponse, error) {
+	c.callAt = append(c.callAt, c.clock.now)
+	c.clock.now = c.clock.now.Add(c.latency)
+	return c.callThenAnswerClient.Chat(ctx, req)
+}
+
+func newPacedAgent(t *testing.T, client *clockedClient, opts ...Option) *agent {
+	t.Helper()
+	var runs atomic.Int32
+	a := newPermissionAgent(t, client, &runs, append([]Option{WithTools([]string{"noop_tool"})}, opts...)...)
+	a.now = client.clock.Now
+	a.sleep = client.clock.Sleep
+	return a
+}
+
+func TestQuery_MinIntervalSpacesIterations(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	client := &clockedClient{callThenAnswerClient: &callThenAnswerClient{tool: "noop_tool"}, clock: clock, latency: 300 * time.Millisecond}
+	a := newPacedAgent(t, client, WithMinInterval(2*time.Second))
+
+	if _, err := a.Query(context.Background(), "go"); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+
+	if len(client.callAt) != 2 {
+		t.Fatalf("expected 2 LLM calls, got %d", len(client.callAt))
+	}
+	if gap := client.callAt[1].Sub(client.callAt[0]); gap < 2*time.Second {
+		t.Fatalf("expected calls at least 2s apart, got %v", gap)
+	}
+	if len(clock.sleeps) != 1 || clock.sleeps[0] != 1700*time.Millisecond {
+		t.Fatalf("expected one 1.7s wait covering only the remaining interval, got %v", clock.sleeps)
+	}
+}
+
+func TestQuery_MinIntervalResetsBetweenQueriesUnlessEnabled(t *testing.T) {
+	for _, across := range []bool{false, true} {
+		clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+		client := &clockedClient{callThenAnswerClient: &callThenAnswerClient{tool: "noop_tool"}, clock: clock}
+		a := newPacedAgent(t, client, WithMinInterval(time.Second), WithMinIntervalAcrossQueries(across))
+
+		for i := 0; i < 2; i++ {
+			client.calls = 0
+			if _, err := a.Query(context.Background(), "go"); err != nil {
+				t.Fatalf("Query: %v", err)
+			}
+		}
+
+		// Each query waits once between its own two calls; pacing across
+		// queries adds a wait before the second query's first call.
+		want := 2
+		if across {
+			want = 3
+		}
+		if len(clock.sleeps) != want {
+			t.Fatalf("across=%v: expected %d waits, got %v", across, want, clock.sleeps)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/nachoal/simple-agent-go/llm/cost"
+)
+
+// selectModel runs the configured ModelSelector over the provider's models
+// the first time a query needs it. The models are priced from the cost
+// tracker's table, or the default one, before the selector sees them. When
+// the models cannot be listed or the selector picks none, the configured
+// model stays in use and selection is tried again on the next query.
+func (a *agent) selectModel(ctx context.Context) {
+	if a.config.ModelSelector == nil || a.modelSelected {
+		return
+	}
+	models, err := a.client.ListModels(ctx)
+	if err != nil {
+		logAgentEvent(ctx, "model_select_error", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	prices := cost.DefaultPriceTable()
+	if a.config.CostTracker != nil {
+		prices = a.config.CostTracker.Prices()
+	}
+	prices.PriceModels(models)
+
+	chosen := a.config.ModelSelector(models)
+	if chosen.ID == "" {
+		return
+	}
+	a.config.Model = chosen.ID
+	a.modelSelected = true
+	logAgentEvent(ctx, "model_selected", map[string]interface{}{
+		"model":      chosen.ID,
+		"candidates": len(models),
+	})
+}
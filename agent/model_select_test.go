@@ -0,0 +1,51 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// listingClient is a scriptedClient that also lists models
+type listingClient struct {
+	scriptedClient
+	models []llm.Model
+	lists  int
+}
+
+func (c *listingClient) ListModels(context.Context) ([]llm.Model, error) {
+	c.lists++
+	return c.models, nil
+}
+
+func TestWithModelSelectorPicksCheapestToolModelFromPriceTable(t *testing.T) {
+	client := &listingClient{
+		scriptedClient: scriptedClient{reply: "done"},
+		models: []llm.Model{
+			{ID: "gpt-4o"},
+			{ID: "gpt-3.5-turbo-instruct", InputPricePerMTok: 0.01, OutputPricePerMTok: 0.01},
+			{ID: "gpt-4.1-nano-2025-04-14"},
+			{ID: "gpt-4o-mini"},
+		},
+	}
+	a := New(client,
+		WithModel("gpt-4o"),
+		WithModelSelector(llm.CheapestWithCapabilities(llm.CapabilityTools)),
+	)
+
+	for i := 0; i < 2; i++ {
+		if _, err := a.Query(context.Background(), "hi"); err != nil {
+			t.Fatalf("Query: %v", err)
+		}
+	}
+
+	for _, req := range client.requests {
+		if req.Model != "gpt-4.1-nano-2025-04-14" {
+			t.Fatalf("expected the cheapest tool model, got %q", req.Model)
+		}
+	}
+	if client.lists != 1 {
+		t.Fatalf("expected models to be listed once, got %d", client.lists)
+	}
+}
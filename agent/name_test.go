@@ -0,0 +1,49 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+func TestWithName_FlowsIntoResponseAndPrompt(t *testing.T) {
+	a := New(runlogQueryClient{},
+		WithName("Researcher"),
+		WithSystemPrompt("You are {{.AgentName}}, a careful analyst."),
+		WithTools([]string{}),
+	)
+
+	if a.Name() != "Researcher" {
+		t.Fatalf("expected Name() to be Researcher, got %q", a.Name())
+	}
+
+	memory := a.GetMemory()
+	if len(memory) == 0 || memory[0].Role != llm.RoleSystem || memory[0].Content == nil {
+		t.Fatalf("expected system prompt in memory, got %#v", memory)
+	}
+	if !strings.HasPrefix(*memory[0].Content, "You are Researcher, a careful analyst.") {
+		t.Fatalf("expected AgentName to be rendered in system prompt, got %q", *memory[0].Content)
+	}
+
+	resp, err := a.Query(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if resp.AgentName != "Researcher" {
+		t.Fatalf("expected Response.AgentName=Researcher, got %q", resp.AgentName)
+	}
+}
+
+func TestRenderPromptTemplate(t *testing.T) {
+	if got := renderPromptTemplate("Be helpful.", "Scout"); got != "Your name is Scout.\n\nBe helpful." {
+		t.Fatalf("expected name to be stated when prompt omits it, got %q", got)
+	}
+	if got := renderPromptTemplate("Be helpful.", ""); got != "Be helpful." {
+		t.Fatalf("expected unnamed prompt unchanged, got %q", got)
+	}
+	if got := renderPromptTemplate("Use {{ braces", "Scout"); got != "Your name is Scout.\n\nUse {{ braces" {
+		t.Fatalf("expected invalid template to be used verbatim, got %q", got)
+	}
+}
@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+const plannerSystemPrompt = `You are a planning assistant. Break the user's task into a short numbered list of concrete steps that another assistant with tool access will carry out.
+Reply with the numbered steps only. Do not perform the task yourself.`
+
+// RunPlanExecute solves task in two phases: planner writes a numbered plan
+// without tools, then an agent backed by executor carries the plan out with
+// tools. opts configure the executor agent; the planner uses its client's
+// default model. The returned Response carries the plan and combined usage.
+func RunPlanExecute(ctx context.Context, task string, planner, executor llm.Client, opts ...Option) (*Response, error) {
+	if planner == nil || executor == nil {
+		return nil, errors.New("plan-execute requires both a planner and an executor client")
+	}
+	task = strings.TrimSpace(task)
+	if task == "" {
+		return nil, errors.New("plan-execute requires a task")
+	}
+
+	exec := New(executor, opts...).(*agent)
+
+	planCtx, cancel := exec.withRequestTimeout(ctx)
+	defer cancel()
+	logAgentEvent(ctx, "plan_request", map[string]interface{}{"task_len": len(task)})
+	planResp, err := planner.Chat(planCtx, &llm.ChatRequest{
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: llm.StringPtr(plannerSystemPrompt)},
+			{Role: llm.RoleUser, Content: llm.StringPtr(task)},
+		},
+		Temperature: exec.config.Temperature,
+		MaxTokens:   exec.config.MaxTokens,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("planner failed: %w", err)
+	}
+	if len(planResp.Choices) == 0 {
+		return nil, errors.New("planner returned no response")
+	}
+	plan := strings.TrimSpace(llm.GetStringValue(planResp.Choices[0].Message.Content))
+	if plan == "" {
+		return nil, errors.New("planner returned an empty plan")
+	}
+	logAgentEvent(ctx, "plan_ready", map[string]interface{}{"plan_len": len(plan)})
+
+	response, err := exec.Query(ctx, executionPrompt(task, plan))
+	if err != nil {
+		return nil, fmt.Errorf("executor failed: %w", err)
+	}
+
+	response.Plan = plan
+	if planResp.Usage != nil {
+		if response.Usage == nil {
+			response.Usage = &llm.Usage{}
+		}
+		response.Usage.PromptTokens += planResp.Usage.PromptTokens
+		response.Usage.CompletionTokens += planResp.Usage.CompletionTokens
+		response.Usage.TotalTokens += planResp.Usage.TotalTokens
+
+		// The planner ran on its client's default model, which it reports.
+		if price, ok := exec.prices().Lookup(planResp.Model); ok && response.CostAvailable {
+			response.EstimatedCostUSD += price.Cost(planResp.Usage.PromptTokens, planResp.Usage.CompletionTokens)
+		} else {
+			response.CostAvailable = false
+			response.EstimatedCostUSD = 0
+		}
+	}
+	return response, nil
+}
+
+func executionPrompt(task, plan string) string {
+	return fmt.Sprintf("Task:\n%s\n\nFollow this plan step by step, using tools where needed, then give the final answer:\n%s", task, plan)
+}
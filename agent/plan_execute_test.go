@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// scriptedClient replies with fixed content and records every request.
+type scriptedClient struct {
+	mu       sync.Mutex
+	reply    string
+	usage    *llm.Usage
+	requests []*llm.ChatRequest
+}
+
+func (c *scriptedClient) Chat(_ context.Context, req *llm.ChatRequest) (*llm.ChatResponse, error) {
+	c.mu.Lock()
+	c.requests = append(c.requests, req)
+	c.mu.Unlock()
+	return &llm.ChatResponse{
+		Choices: []llm.Choice{{
+			Message:      llm.Message{Role: llm.RoleAssistant, Content: llm.StringPtr(c.reply)},
+			FinishReason: "stop",
+		}},
+		Usage: c.usage,
+	}, nil
+}
+
+func (c *scriptedClient) ChatStream(context.Context, *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	return nil, nil
+}
+func (c *scriptedClient) ListModels(context.Context) ([]llm.Model, error)      { return nil, nil }
+func (c *scriptedClient) GetModel(context.Context, string) (*llm.Model, error) { return nil, nil }
+func (c *scriptedClient) Close() error                                         { return nil }
+
+func TestRunPlanExecute_ExecutorReceivesPlan(t *testing.T) {
+	planner := &scriptedClient{
+		reply: "1. List the files\n2. Summarize main.go",
+		usage: &llm.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+	}
+	executor := &scriptedClient{
+		reply: "main.go starts the CLI.",
+		usage: &llm.Usage{PromptTokens: 20, CompletionTokens: 7, TotalTokens: 27},
+	}
+
+	resp, err := RunPlanExecute(context.Background(), "Explain main.go", planner, executor, WithModel("cheap-model"))
+	if err != nil {
+		t.Fatalf("RunPlanExecute: %v", err)
+	}
+
+	if len(planner.requests) != 1 {
+		t.Fatalf("expected one planner call, got %d", len(planner.requests))
+	}
+	planReq := planner.requests[0]
+	if len(planReq.Tools) != 0 || llm.GetStringValue(planReq.Messages[len(planReq.Messages)-1].Content) != "Explain main.go" {
+		t.Fatalf("unexpected planner request: %+v", planReq)
+	}
+
+	if len(executor.requests) != 1 {
+		t.Fatalf("expected one executor call, got %d", len(executor.requests))
+	}
+	execReq := executor.requests[0]
+	if execReq.Model != "cheap-model" {
+		t.Fatalf("expected executor options to apply, got model %q", execReq.Model)
+	}
+	userMsg := llm.GetStringValue(execReq.Messages[len(execReq.Messages)-1].Content)
+	if !strings.Contains(userMsg, "Explain main.go") || !strings.Contains(userMsg, "2. Summarize main.go") {
+		t.Fatalf("expected executor prompt to contain the task and plan, got %q", userMsg)
+	}
+
+	if resp.Content != "main.go starts the CLI." {
+		t.Fatalf("unexpected final answer: %q", resp.Content)
+	}
+	if resp.Plan != planner.reply {
+		t.Fatalf("expected plan on response, got %q", resp.Plan)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 42 {
+		t.Fatalf("expected combined usage of 42 tokens, got %+v", resp.Usage)
+	}
+}
+
+func TestRunPlanExecute_EmptyPlanFails(t *testing.T) {
+	_, err := RunPlanExecute(context.Background(), "task", &scriptedClient{reply: "  "}, &scriptedClient{reply: "x"})
+	if err == nil || !strings.Contains(err.Error(), "empty plan") {
+		t.Fatalf("expected empty plan error, got %v", err)
+	}
+}
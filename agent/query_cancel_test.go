@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// cancellingToolLoopClient always asks for another tool call and cancels the
+// caller's context on the configured call.
+type cancellingToolLoopClient struct {
+	calls    int32
+	cancelOn int32
+	cancel   context.CancelFunc
+}
+
+func (c *cancellingToolLoopClient) Chat(context.Context, *llm.ChatRequest) (*llm.ChatResponse, error) {
+	n := atomic.AddInt32(&c.calls, 1)
+	if n == c.cancelOn {
+		c.cancel()
+	}
+	return &llm.ChatResponse{
+		Choices: []llm.Choice{{
+			Message: llm.Message{
+				Role: llm.RoleAssistant,
+				ToolCalls: []llm.ToolCall{{
+					ID:   fmt.Sprintf("call-%d", n),
+					Type: "function",
+					Function: llm.FunctionCall{
+						Name:      "missing_tool",
+						Arguments: json.RawMessage(`{}`),
+					},
+				}},
+			},
+			FinishReason: "tool_calls",
+		}},
+	}, nil
+}
+
+func (c *cancellingToolLoopClient) ChatStream(context.Context, *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	return nil, nil
+}
+func (c *cancellingToolLoopClient) ListModels(context.Context) ([]llm.Model, error) { return nil, nil }
+func (c *cancellingToolLoopClient) GetModel(context.Context, string) (*llm.Model, error) {
+	return nil, nil
+}
+func (c *cancellingToolLoopClient) Close() error { return nil }
+
+func TestQuery_ReturnsPromptlyWhenCancelledMidLoop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &cancellingToolLoopClient{cancelOn: 3, cancel: cancel}
+	a := New(client, WithTools(nil), WithMaxIterations(50))
+
+	start := time.Now()
+	_, err := a.Query(ctx, "loop forever")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("cancelled query took too long: %v", time.Since(start))
+	}
+	if got := atomic.LoadInt32(&client.calls); got != 3 {
+		t.Fatalf("expected loop to stop after the cancelling call, got %d LLM calls", got)
+	}
+
+	// The response that arrived after cancellation must not leave an
+	// unanswered tool call in memory.
+	memory := a.GetMemory()
+	last := memory[len(memory)-1]
+	if last.Role != llm.RoleTool || last.ToolCallID != "call-2" {
+		t.Fatalf("expected memory to end with the last completed tool result, got %+v", last)
+	}
+}
@@ -134,6 +134,7 @@ func TestQueryStream_PreservesCommittedMemoryOnCancel(t *testing.T) {
 	initialMemory := a.GetMemory()
 
 	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 	stream, err := a.QueryStream(ctx, "use the tool and wait")
 	if err != nil {
 		t.Fatalf("unexpected QueryStream error: %v", err)
@@ -0,0 +1,114 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/tools"
+	"github.com/nachoal/simple-agent-go/tools/base"
+	"github.com/nachoal/simple-agent-go/tools/registry"
+)
+
+// flakyClient fails every call a fixed number of times before answering,
+// retrying like the provider clients do: up to maxRetries per call, subject
+// to the context's retry budget. The first toolTurns answers call noop_tool.
+type flakyClient struct {
+	scriptedClient
+	failuresPerCall int
+	maxRetries      int
+	toolTurns       int
+	calls           int
+	retries         int
+}
+
+func (c *flakyClient) Chat(ctx context.Context, req *llm.ChatRequest) (*llm.ChatResponse, error) {
+	c.calls++
+	err := errors.New("status 503")
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			if !llm.AllowRetry(ctx) {
+				return nil, fmt.Errorf("retry budget exhausted: %w", err)
+			}
+			c.retries++
+		}
+		if attempt < c.failuresPerCall {
+			continue
+		}
+		if c.calls <= c.toolTurns {
+			return &llm.ChatResponse{Choices: []llm.Choice{{
+				Message: llm.Message{Role: llm.RoleAssistant, ToolCalls: []llm.ToolCall{{
+					ID:       fmt.Sprintf("call-%d", c.calls),
+					Type:     "function",
+					Function: llm.FunctionCall{Name: "noop_tool", Arguments: json.RawMessage(`{}`)},
+				}}},
+				FinishReason: "tool_calls",
+			}}}, nil
+		}
+		return &llm.ChatResponse{Choices: []llm.Choice{{
+			Message:      llm.Message{Role: llm.RoleAssistant, Content: llm.StringPtr("done")},
+			FinishReason: "stop",
+		}}}, nil
+	}
+	return nil, fmt.Errorf("max retries exceeded: %w", err)
+}
+
+type noopToolParams struct{}
+
+type noopTool struct {
+	base.BaseTool
+}
+
+func (t *noopTool) Parameters() interface{} { return &noopToolParams{} }
+
+func (t *noopTool) Execute(context.Context, json.RawMessage) (string, error) { return "ok", nil }
+
+func newRetryBudgetAgent(t *testing.T, client llm.Client, budget int) *agent {
+	t.Helper()
+	reg := registry.New()
+	if err := reg.Register("noop_tool", func() tools.Tool {
+		return &noopTool{BaseTool: base.BaseTool{ToolName: "noop_tool", ToolDesc: "does nothing"}}
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	a := New(client, WithTools([]string{"noop_tool"}), WithRetryBudget(budget)).(*agent)
+	a.toolRegistry = reg
+	return a
+}
+
+func TestQuery_RetryBudgetSharedAcrossIterations(t *testing.T) {
+	client := &flakyClient{failuresPerCall: 2, maxRetries: 3, toolTurns: 2}
+	a := newRetryBudgetAgent(t, client, 3)
+
+	_, err := a.Query(context.Background(), "run the tool twice")
+	if err == nil {
+		t.Fatalf("expected query to fail once the retry budget is spent")
+	}
+	if client.retries > 3 {
+		t.Fatalf("expected at most 3 retries across the query, got %d", client.retries)
+	}
+	if client.calls != 2 {
+		t.Fatalf("expected the second call to exhaust the budget, got %d calls", client.calls)
+	}
+}
+
+func TestQuery_RetryBudgetResetsPerQuery(t *testing.T) {
+	client := &flakyClient{failuresPerCall: 1, maxRetries: 3, toolTurns: 2}
+	a := newRetryBudgetAgent(t, client, 3)
+
+	resp, err := a.Query(context.Background(), "run the tool twice")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if resp.Content != "done" || client.retries != 3 {
+		t.Fatalf("expected success using exactly 3 retries, got %q with %d retries", resp.Content, client.retries)
+	}
+
+	client.calls, client.toolTurns = 0, 0
+	if _, err := a.Query(context.Background(), "again"); err != nil {
+		t.Fatalf("expected a fresh budget for the next query: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/tools"
+	"github.com/nachoal/simple-agent-go/tools/base"
+	"github.com/nachoal/simple-agent-go/tools/registry"
+)
+
+// finishReasonStreamClient calls noop_tool, then streams an answer that is
+// cut off at the token limit.
+type finishReasonStreamClient struct {
+	scriptedClient
+	calls int
+}
+
+func (c *finishReasonStreamClient) ChatStream(context.Context, *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	c.calls++
+	ch := make(chan llm.StreamEvent, 2)
+	if c.calls == 1 {
+		call := llm.ToolCall{ID: "call_1", Type: "function", Function: llm.FunctionCall{Name: "noop_tool", Arguments: []byte(`{}`)}}
+		ch <- llm.StreamEvent{Choices: []llm.Choice{{Delta: &llm.Message{ToolCalls: []llm.ToolCall{call}}}}}
+		ch <- llm.StreamEvent{Choices: []llm.Choice{{FinishReason: "tool_calls"}}}
+	} else {
+		ch <- llm.StreamEvent{Choices: []llm.Choice{{Delta: &llm.Message{Content: llm.StringPtr("The first half of")}}}}
+		ch <- llm.StreamEvent{Choices: []llm.Choice{{FinishReason: "length"}}}
+	}
+	close(ch)
+	return ch, nil
+}
+
+func TestQueryStream_CompleteEventCarriesFinishReason(t *testing.T) {
+	reg := registry.New()
+	if err := reg.Register("noop_tool", func() tools.Tool {
+		return &noopTool{BaseTool: base.BaseTool{ToolName: "noop_tool", ToolDesc: "does nothing"}}
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	a := New(&finishReasonStreamClient{}, WithTools([]string{"noop_tool"})).(*agent)
+	a.toolRegistry = reg
+
+	stream, err := a.QueryStream(context.Background(), "write a long essay")
+	if err != nil {
+		t.Fatalf("QueryStream: %v", err)
+	}
+
+	var complete *StreamEvent
+	for event := range stream {
+		if event.Type == EventTypeComplete {
+			event := event
+			complete = &event
+		}
+	}
+	if complete == nil {
+		t.Fatal("expected the run to complete")
+	}
+	if complete.FinishReason != "length" {
+		t.Fatalf("expected the final turn's length finish on the complete event, got %q", complete.FinishReason)
+	}
+}
@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/tools"
+	"github.com/nachoal/simple-agent-go/tools/base"
+	"github.com/nachoal/simple-agent-go/tools/registry"
+)
+
+// messageUpdateStreamClient streams text in two chunks followed by a tool
+// call split across two deltas, then answers "done" on the next turn.
+type messageUpdateStreamClient struct {
+	scriptedClient
+	calls int
+}
+
+func (c *messageUpdateStreamClient) ChatStream(context.Context, *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	c.calls++
+	var deltas []llm.Message
+	if c.calls == 1 {
+		deltas = []llm.Message{
+			{Content: llm.StringPtr("Let me ")},
+			{Content: llm.StringPtr("check.")},
+			{ToolCalls: []llm.ToolCall{{ID: "call_1", Type: "function", Function: llm.FunctionCall{Name: "noop_tool", Arguments: json.RawMessage(`"{\"input\":"`)}}}},
+			{ToolCalls: []llm.ToolCall{{Function: llm.FunctionCall{Arguments: json.RawMessage(`"\"x\"}"`)}}}},
+		}
+	} else {
+		deltas = []llm.Message{{Content: llm.StringPtr("done")}}
+	}
+
+	ch := make(chan llm.StreamEvent, len(deltas))
+	for i := range deltas {
+		ch <- llm.StreamEvent{Choices: []llm.Choice{{Delta: &deltas[i]}}}
+	}
+	close(ch)
+	return ch, nil
+}
+
+func TestQueryStream_MessageUpdatesCarryCumulativeMessage(t *testing.T) {
+	reg := registry.New()
+	if err := reg.Register("noop_tool", func() tools.Tool {
+		return &noopTool{BaseTool: base.BaseTool{ToolName: "noop_tool", ToolDesc: "does nothing"}}
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	a := New(&messageUpdateStreamClient{}, WithTools([]string{"noop_tool"})).(*agent)
+	a.toolRegistry = reg
+
+	stream, err := a.QueryStream(context.Background(), "check something")
+	if err != nil {
+		t.Fatalf("QueryStream: %v", err)
+	}
+
+	var updates []*llm.Message
+	var end *llm.Message
+	for event := range stream {
+		if end != nil {
+			continue
+		}
+		switch event.Type {
+		case EventTypeMessageUpdate:
+			updates = append(updates, event.Message)
+		case EventTypeMessageEnd:
+			end = event.Message
+		}
+	}
+
+	wantContent := []string{"Let me ", "Let me check.", "Let me check.", "Let me check."}
+	if len(updates) != len(wantContent) {
+		t.Fatalf("expected %d updates, got %d", len(wantContent), len(updates))
+	}
+	for i, update := range updates {
+		if got := llm.GetStringValue(update.Content); got != wantContent[i] || update.Role != llm.RoleAssistant {
+			t.Fatalf("update %d: expected cumulative content %q, got %q (%s)", i, wantContent[i], got, update.Role)
+		}
+	}
+
+	last := updates[len(updates)-1]
+	if len(last.ToolCalls) != 1 || last.ToolCalls[0].Function.Name != "noop_tool" {
+		t.Fatalf("expected final update to include the tool call, got %+v", last.ToolCalls)
+	}
+	if args, _ := llm.NormalizeToolArguments(last.ToolCalls[0].Function.Arguments); args["input"] != "x" {
+		t.Fatalf("expected completed tool arguments, got %s", last.ToolCalls[0].Function.Arguments)
+	}
+	if end == nil || llm.GetStringValue(end.Content) != "Let me check." || len(end.ToolCalls) != 1 {
+		t.Fatalf("expected message end to carry the full message, got %+v", end)
+	}
+}
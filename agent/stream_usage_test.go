@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/tools"
+	"github.com/nachoal/simple-agent-go/tools/base"
+	"github.com/nachoal/simple-agent-go/tools/registry"
+)
+
+// usageStreamClient replays messageUpdateStreamClient and ends each stream
+// with an OpenAI-style usage chunk that has no choices.
+type usageStreamClient struct {
+	messageUpdateStreamClient
+}
+
+func (c *usageStreamClient) ChatStream(ctx context.Context, req *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	inner, err := c.messageUpdateStreamClient.ChatStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	usage := &llm.Usage{PromptTokens: 10 * c.calls, CompletionTokens: 5, TotalTokens: 10*c.calls + 5}
+
+	ch := make(chan llm.StreamEvent, 8)
+	for event := range inner {
+		ch <- event
+	}
+	ch <- llm.StreamEvent{Choices: []llm.Choice{}, Usage: usage}
+	close(ch)
+	return ch, nil
+}
+
+func TestQueryStream_AccumulatesStreamedUsage(t *testing.T) {
+	reg := registry.New()
+	if err := reg.Register("noop_tool", func() tools.Tool {
+		return &noopTool{BaseTool: base.BaseTool{ToolName: "noop_tool", ToolDesc: "does nothing"}}
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	a := New(&usageStreamClient{}, WithTools([]string{"noop_tool"})).(*agent)
+	a.toolRegistry = reg
+
+	stream, err := a.QueryStream(context.Background(), "check something")
+	if err != nil {
+		t.Fatalf("QueryStream: %v", err)
+	}
+
+	var usages []llm.Usage
+	completed := false
+	for event := range stream {
+		switch event.Type {
+		case EventTypeUsage:
+			if event.Usage == nil {
+				t.Fatal("usage event without usage")
+			}
+			usages = append(usages, *event.Usage)
+		case EventTypeComplete:
+			completed = true
+		}
+	}
+
+	if !completed {
+		t.Fatal("expected the run to complete")
+	}
+	want := []llm.Usage{
+		{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		{PromptTokens: 30, CompletionTokens: 10, TotalTokens: 40},
+	}
+	if len(usages) != len(want) {
+		t.Fatalf("expected %d usage events, got %+v", len(want), usages)
+	}
+	for i := range want {
+		if usages[i] != want[i] {
+			t.Fatalf("usage event %d: expected cumulative %+v, got %+v", i, want[i], usages[i])
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"unicode/utf8"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// messageTokenOverhead approximates the tokens a provider adds around each
+// message for its role and separators
+const messageTokenOverhead = 4
+
+// EstimateTokens approximates how many tokens text uses, at roughly four
+// characters per token. It is meant for relative sizes, not billing.
+func EstimateTokens(text string) int {
+	n := utf8.RuneCountInString(text)
+	return (n + 3) / 4
+}
+
+// EstimateMessageTokens approximates the tokens one message adds to a request
+func EstimateMessageTokens(msg llm.Message) int {
+	tokens := messageTokenOverhead + EstimateTokens(llm.GetStringValue(msg.Content)) + EstimateTokens(llm.GetStringValue(msg.ReasoningContent))
+	for _, call := range msg.ToolCalls {
+		tokens += EstimateTokens(call.Function.Name) + EstimateTokens(string(call.Function.Arguments))
+	}
+	return tokens
+}
+
+// TokenBreakdown splits a memory's estimated tokens between the system prompt
+// and the conversation, so a large, tool-laden system prompt does not hide
+// how much the conversation itself uses
+type TokenBreakdown struct {
+	System       int // System prompt tokens; sent with every request and never trimmed
+	Conversation int // Tokens of every other message
+	Messages     int // Number of conversation messages
+}
+
+// Total returns the estimated tokens of the whole memory
+func (b TokenBreakdown) Total() int {
+	return b.System + b.Conversation
+}
+
+// EstimateMemoryTokens estimates the tokens of messages, counting system
+// messages separately from the conversation
+func EstimateMemoryTokens(messages []llm.Message) TokenBreakdown {
+	var breakdown TokenBreakdown
+	for _, msg := range messages {
+		if msg.Role == llm.RoleSystem {
+			breakdown.System += EstimateMessageTokens(msg)
+			continue
+		}
+		breakdown.Conversation += EstimateMessageTokens(msg)
+		breakdown.Messages++
+	}
+	return breakdown
+}
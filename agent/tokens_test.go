@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	cases := map[string]int{
+		"":          0,
+		"abc":       1,
+		"abcd":      1,
+		"abcde":     2,
+		"月之暗面":      1,
+		"hello, wo": 3,
+	}
+	for text, want := range cases {
+		if got := EstimateTokens(text); got != want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
+
+func TestEstimateMemoryTokens_SplitsSystemPrompt(t *testing.T) {
+	system := strings.Repeat("x", 4000) // 1000 tokens
+	messages := []llm.Message{
+		{Role: llm.RoleSystem, Content: llm.StringPtr(system)},
+		{Role: llm.RoleUser, Content: llm.StringPtr(strings.Repeat("u", 40))}, // 10
+		{Role: llm.RoleAssistant, ToolCalls: []llm.ToolCall{{
+			Function: llm.FunctionCall{Name: "read", Arguments: json.RawMessage(`{"path":"a.go"}`)}, // 1 + 4
+		}}},
+		{Role: llm.RoleTool, Content: llm.StringPtr(strings.Repeat("t", 80)), ToolCallID: "call_1"}, // 20
+		{Role: llm.RoleAssistant, Content: llm.StringPtr("done")},                                   // 1
+	}
+
+	got := EstimateMemoryTokens(messages)
+	want := TokenBreakdown{
+		System:       1000 + messageTokenOverhead,
+		Conversation: 10 + 5 + 20 + 1 + 4*messageTokenOverhead,
+		Messages:     4,
+	}
+	if got != want {
+		t.Fatalf("EstimateMemoryTokens = %+v, want %+v", got, want)
+	}
+	if got.Total() != want.System+want.Conversation {
+		t.Fatalf("Total = %d, want %d", got.Total(), want.System+want.Conversation)
+	}
+}
+
+func TestAddMessage_TrimmingKeepsSystemPromptOutOfConversation(t *testing.T) {
+	a := New(nil, WithSystemPrompt("You are terse."), WithMemorySize(3)).(*agent)
+	for _, text := range []string{"one", "two", "three", "four"} {
+		a.addMessage(llm.Message{Role: llm.RoleUser, Content: llm.StringPtr(text)})
+	}
+
+	memory := a.GetMemory()
+	if len(memory) != 3 || memory[0].Role != llm.RoleSystem {
+		t.Fatalf("expected trimming to keep the system prompt, got %+v", memory)
+	}
+	breakdown := EstimateMemoryTokens(memory)
+	if breakdown.Messages != 2 || breakdown.System != EstimateMessageTokens(memory[0]) {
+		t.Fatalf("unexpected breakdown after trimming: %+v", breakdown)
+	}
+}
+
+func TestAddMessage_TrimmingDropsOrphanedToolResults(t *testing.T) {
+	a := New(nil, WithSystemPrompt("You are terse."), WithMemorySize(3)).(*agent)
+	a.addMessage(llm.Message{Role: llm.RoleUser, Content: llm.StringPtr("list files")})
+	a.addMessage(llm.Message{
+		Role: llm.RoleAssistant,
+		ToolCalls: []llm.ToolCall{{
+			ID:       "call_1",
+			Type:     "function",
+			Function: llm.FunctionCall{Name: "list", Arguments: []byte(`{}`)},
+		}},
+	})
+	a.addMessage(llm.Message{Role: llm.RoleTool, Name: "list", ToolCallID: "call_1", Content: llm.StringPtr("a.go")})
+	// This pushes the assistant's tool call out of memory, but not its result.
+	a.addMessage(llm.Message{Role: llm.RoleAssistant, Content: llm.StringPtr("There is a.go.")})
+
+	memory := a.GetMemory()
+	for _, msg := range memory {
+		if msg.Role == llm.RoleTool {
+			t.Fatalf("expected the orphaned tool result to be dropped, got %+v", memory)
+		}
+	}
+	if len(memory) != 2 || memory[0].Role != llm.RoleSystem || llm.GetStringValue(memory[1].Content) != "There is a.go." {
+		t.Fatalf("unexpected memory after trimming: %+v", memory)
+	}
+}
+
+func TestAddMessage_TrimmingKeepsAnsweredToolResults(t *testing.T) {
+	a := New(nil, WithSystemPrompt("You are terse."), WithMemorySize(4)).(*agent)
+	a.addMessage(llm.Message{Role: llm.RoleUser, Content: llm.StringPtr("list files")})
+	a.addMessage(llm.Message{
+		Role:      llm.RoleAssistant,
+		ToolCalls: []llm.ToolCall{{ID: "call_1", Type: "function", Function: llm.FunctionCall{Name: "list"}}},
+	})
+	a.addMessage(llm.Message{Role: llm.RoleTool, Name: "list", ToolCallID: "call_1", Content: llm.StringPtr("a.go")})
+	a.addMessage(llm.Message{Role: llm.RoleAssistant, Content: llm.StringPtr("There is a.go.")})
+
+	memory := a.GetMemory()
+	if len(memory) != 4 || len(memory[1].ToolCalls) != 1 || memory[2].ToolCallID != "call_1" || memory[2].Name != "list" {
+		t.Fatalf("expected the answered tool result to survive trimming, got %+v", memory)
+	}
+}
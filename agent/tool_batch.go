@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/tools"
+)
+
+// summarizeToolBatch counts the successes and failures among the results of
+// one model turn's tool calls.
+func summarizeToolBatch(results []tools.ToolResult) ToolBatchSummary {
+	var summary ToolBatchSummary
+	for _, result := range results {
+		if result.Error != nil {
+			summary.Failed++
+			summary.FailedTools = append(summary.FailedTools, result.Name)
+			continue
+		}
+		summary.Succeeded++
+	}
+	return summary
+}
+
+// toolBatchEvent logs the batch summary and returns the event reporting it
+func toolBatchEvent(ctx context.Context, mode string, summary ToolBatchSummary) StreamEvent {
+	logAgentEvent(ctx, "tool_batch_complete", map[string]interface{}{
+		"mode":      mode,
+		"succeeded": summary.Succeeded,
+		"failed":    summary.Failed,
+	})
+	return StreamEvent{Type: EventTypeToolBatchComplete, ToolBatch: &summary}
+}
+
+// toolProposalEvent logs a model turn's tool calls and returns the event
+// announcing them before any runs. The calls are copied, so a consumer that
+// keeps or edits them cannot change what the agent executes.
+func toolProposalEvent(ctx context.Context, mode string, calls []llm.ToolCall) StreamEvent {
+	names := make([]string, len(calls))
+	for i, call := range calls {
+		names[i] = call.Function.Name
+	}
+	logAgentEvent(ctx, "tool_call_proposed", map[string]interface{}{
+		"mode":  mode,
+		"tools": names,
+	})
+	return StreamEvent{Type: EventTypeToolCallProposed, ToolCalls: cloneToolCallsForStream(calls)}
+}
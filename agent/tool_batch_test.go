@@ -0,0 +1,183 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/tools"
+	"github.com/nachoal/simple-agent-go/tools/base"
+	"github.com/nachoal/simple-agent-go/tools/registry"
+)
+
+// failingTool always returns an error
+type failingTool struct {
+	base.BaseTool
+}
+
+func (t *failingTool) Parameters() interface{} { return &noopToolParams{} }
+
+func (t *failingTool) Execute(context.Context, json.RawMessage) (string, error) {
+	return "", errors.New("disk full")
+}
+
+// batchClient asks for one successful and two failing tool calls in a single
+// turn, then answers "done".
+type batchClient struct {
+	scriptedClient
+	calls int
+}
+
+func (c *batchClient) step() llm.Message {
+	c.calls++
+	if c.calls > 1 {
+		return llm.Message{Role: llm.RoleAssistant, Content: llm.StringPtr("done")}
+	}
+	call := func(id, name string) llm.ToolCall {
+		return llm.ToolCall{ID: id, Type: "function", Function: llm.FunctionCall{Name: name, Arguments: json.RawMessage(`{}`)}}
+	}
+	return llm.Message{
+		Role:      llm.RoleAssistant,
+		Content:   llm.StringPtr(""),
+		ToolCalls: []llm.ToolCall{call("call_1", "ok_tool"), call("call_2", "write_tool"), call("call_3", "edit_tool")},
+	}
+}
+
+func (c *batchClient) Chat(context.Context, *llm.ChatRequest) (*llm.ChatResponse, error) {
+	return &llm.ChatResponse{Choices: []llm.Choice{{Message: c.step(), FinishReason: "stop"}}}, nil
+}
+
+func (c *batchClient) ChatStream(context.Context, *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	msg := c.step()
+	ch := make(chan llm.StreamEvent, 1)
+	ch <- llm.StreamEvent{Choices: []llm.Choice{{Delta: &msg}}}
+	close(ch)
+	return ch, nil
+}
+
+func newBatchAgent(t *testing.T) *agent {
+	t.Helper()
+	reg := registry.New()
+	register := func(name string, factory func() tools.Tool) {
+		if err := reg.Register(name, factory); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	register("ok_tool", func() tools.Tool {
+		return &countingTool{BaseTool: base.BaseTool{ToolName: "ok_tool", ToolDesc: "succeeds"}, runs: new(atomic.Int32)}
+	})
+	for _, name := range []string{"write_tool", "edit_tool"} {
+		name := name
+		register(name, func() tools.Tool { return &failingTool{BaseTool: base.BaseTool{ToolName: name, ToolDesc: "fails"}} })
+	}
+	a := New(&batchClient{}, WithTools([]string{"ok_tool", "write_tool", "edit_tool"})).(*agent)
+	a.toolRegistry = reg
+	return a
+}
+
+func TestQuery_CountsToolFailures(t *testing.T) {
+	a := newBatchAgent(t)
+
+	resp, err := a.Query(context.Background(), "update the files")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if resp.Content != "done" {
+		t.Fatalf("expected the run to continue past the failures, got %q", resp.Content)
+	}
+	if resp.ToolFailures != 2 || len(resp.ToolCalls) != 3 {
+		t.Fatalf("expected 2 failures among 3 tool calls, got %d of %d", resp.ToolFailures, len(resp.ToolCalls))
+	}
+}
+
+func TestQueryStream_EmitsToolBatchSummary(t *testing.T) {
+	a := newBatchAgent(t)
+
+	stream, err := a.QueryStream(context.Background(), "update the files")
+	if err != nil {
+		t.Fatalf("QueryStream: %v", err)
+	}
+	var batches []*ToolBatchSummary
+	results := 0
+	for event := range stream {
+		switch event.Type {
+		case EventTypeToolResult:
+			results++
+		case EventTypeToolBatchComplete:
+			if results != 3 {
+				t.Fatalf("expected the summary after all 3 tool results, got it after %d", results)
+			}
+			batches = append(batches, event.ToolBatch)
+		case EventTypeError:
+			t.Fatalf("unexpected error: %v", event.Error)
+		}
+	}
+	if len(batches) != 1 || batches[0] == nil {
+		t.Fatalf("expected one batch summary, got %+v", batches)
+	}
+	got := *batches[0]
+	if got.Succeeded != 1 || got.Failed != 2 {
+		t.Fatalf("expected 1 success and 2 failures, got %+v", got)
+	}
+	if len(got.FailedTools) != 2 || got.FailedTools[0] != "write_tool" || got.FailedTools[1] != "edit_tool" {
+		t.Fatalf("expected the failed tools in call order, got %v", got.FailedTools)
+	}
+}
+
+// assertProposedBeforeStart checks that one tool_call_proposed event names
+// every call of the turn before the first of them starts.
+func assertProposedBeforeStart(t *testing.T, events []StreamEvent) {
+	t.Helper()
+	proposed := map[string]bool{}
+	starts := 0
+	for _, event := range events {
+		switch event.Type {
+		case EventTypeToolCallProposed:
+			if len(proposed) > 0 {
+				t.Fatal("expected one proposal for the turn")
+			}
+			for _, call := range event.ToolCalls {
+				proposed[call.Function.Name] = true
+			}
+		case EventTypeToolStart:
+			starts++
+			if !proposed[event.Tool.Name] {
+				t.Fatalf("tool_start for %s came before it was proposed", event.Tool.Name)
+			}
+		}
+	}
+	if len(proposed) != 3 || starts != 3 {
+		t.Fatalf("expected 3 proposed calls and 3 starts, got %d and %d", len(proposed), starts)
+	}
+}
+
+func TestQueryStream_ProposesToolCallsBeforeStarting(t *testing.T) {
+	a := newBatchAgent(t)
+	stream, err := a.QueryStream(context.Background(), "do three things")
+	if err != nil {
+		t.Fatalf("QueryStream: %v", err)
+	}
+	var events []StreamEvent
+	for event := range stream {
+		events = append(events, event)
+	}
+	assertProposedBeforeStart(t, events)
+}
+
+func TestQuery_ProposesToolCallsOnEventChannel(t *testing.T) {
+	a := newBatchAgent(t)
+	eventChan := make(chan StreamEvent, 100)
+	ctx := context.WithValue(context.Background(), "toolEventChan", eventChan)
+	if _, err := a.Query(ctx, "do three things"); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	close(eventChan)
+	var events []StreamEvent
+	for event := range eventChan {
+		events = append(events, event)
+	}
+	assertProposedBeforeStart(t, events)
+}
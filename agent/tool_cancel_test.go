@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/tools"
+	"github.com/nachoal/simple-agent-go/tools/base"
+	"github.com/nachoal/simple-agent-go/tools/registry"
+)
+
+// blockingTestTool runs until its context is cancelled.
+type blockingTestTool struct {
+	base.BaseTool
+	started *int64
+	running chan<- struct{}
+}
+
+func (t *blockingTestTool) Parameters() interface{} { return &progressTestParams{} }
+
+func (t *blockingTestTool) Execute(ctx context.Context, _ json.RawMessage) (string, error) {
+	atomic.AddInt64(t.started, 1)
+	t.running <- struct{}{}
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestExecuteToolsWithEvents_SkipsQueuedCallsAfterCancel(t *testing.T) {
+	var started int64
+	running := make(chan struct{}, 4)
+	reg := registry.New()
+	if err := reg.Register("block", func() tools.Tool {
+		return &blockingTestTool{BaseTool: base.BaseTool{ToolName: "block", ToolDesc: "blocks"}, started: &started, running: running}
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	reg.SetMaxConcurrency(1)
+
+	a := New(nil).(*agent)
+	a.toolRegistry = reg
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-running
+		cancel()
+	}()
+
+	events := make(chan StreamEvent, 16)
+	done := make(chan []tools.ToolResult, 1)
+	go func() {
+		done <- a.executeToolsWithEvents(ctx, []tools.ToolCall{
+			{ID: "a", Name: "block", Arguments: json.RawMessage(`{}`)},
+			{ID: "b", Name: "block", Arguments: json.RawMessage(`{}`)},
+			{ID: "c", Name: "block", Arguments: json.RawMessage(`{}`)},
+		}, events)
+	}()
+
+	var results []tools.ToolResult
+	select {
+	case results = <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("executeToolsWithEvents did not return after cancellation")
+	}
+	if got := atomic.LoadInt64(&started); got != 1 {
+		t.Fatalf("expected only the first call to start, got %d", got)
+	}
+	for _, result := range results[1:] {
+		toolErr, ok := result.Error.(*tools.ToolError)
+		if !ok || toolErr.Code != "CANCELLED" {
+			t.Fatalf("expected queued call to be CANCELLED, got %+v", result)
+		}
+	}
+}
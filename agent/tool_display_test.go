@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/tools"
+	"github.com/nachoal/simple-agent-go/tools/base"
+	"github.com/nachoal/simple-agent-go/tools/registry"
+)
+
+const (
+	buildSummary = "build ok: 3 packages"
+	buildLog     = "compiling a...\ncompiling b...\ncompiling c...\nbuild ok: 3 packages"
+)
+
+// buildTool returns a one-line summary for the model and the full log for
+// the user.
+type buildTool struct {
+	base.BaseTool
+}
+
+func (t *buildTool) Parameters() interface{} { return &progressTestParams{} }
+
+func (t *buildTool) Execute(context.Context, json.RawMessage) (string, error) {
+	return buildSummary, nil
+}
+
+func (t *buildTool) ExecuteWithDisplay(context.Context, json.RawMessage) (tools.ToolOutput, error) {
+	return tools.ToolOutput{ModelText: buildSummary, DisplayText: buildLog}, nil
+}
+
+// buildOnceClient calls the build tool on its first step and answers on the
+// next.
+type buildOnceClient struct {
+	scriptedClient
+	calls int
+}
+
+func (c *buildOnceClient) Chat(ctx context.Context, req *llm.ChatRequest) (*llm.ChatResponse, error) {
+	c.calls++
+	if c.calls > 1 {
+		return c.scriptedClient.Chat(ctx, req)
+	}
+	return &llm.ChatResponse{Choices: []llm.Choice{{
+		Message: llm.Message{
+			Role: llm.RoleAssistant,
+			ToolCalls: []llm.ToolCall{{
+				ID:       "call_1",
+				Type:     "function",
+				Function: llm.FunctionCall{Name: "build", Arguments: json.RawMessage(`{}`)},
+			}},
+		},
+		FinishReason: "tool_calls",
+	}}}, nil
+}
+
+func newBuildAgent(t *testing.T, client llm.Client) *agent {
+	t.Helper()
+	reg := registry.New()
+	if err := reg.Register("build", func() tools.Tool {
+		return &buildTool{BaseTool: base.BaseTool{ToolName: "build", ToolDesc: "builds the project"}}
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	a := New(client, WithTools([]string{"build"})).(*agent)
+	a.toolRegistry = reg
+	return a
+}
+
+func TestQuery_SendsModelTextToMemory(t *testing.T) {
+	a := newBuildAgent(t, &buildOnceClient{scriptedClient: scriptedClient{reply: "The build passed."}})
+
+	resp, err := a.Query(context.Background(), "build it")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Result != buildSummary || resp.ToolCalls[0].DisplayText != buildLog {
+		t.Fatalf("unexpected tool results: %+v", resp.ToolCalls)
+	}
+
+	var toolMessages []llm.Message
+	for _, msg := range a.GetMemory() {
+		if msg.Role == llm.RoleTool {
+			toolMessages = append(toolMessages, msg)
+		}
+	}
+	if len(toolMessages) != 1 || llm.GetStringValue(toolMessages[0].Content) != buildSummary {
+		t.Fatalf("expected the model to see only the summary, got %+v", toolMessages)
+	}
+}
+
+func TestExecuteToolsWithEvents_CarriesDisplayText(t *testing.T) {
+	a := newBuildAgent(t, nil)
+
+	events := make(chan StreamEvent, 4)
+	results := a.executeToolsWithEvents(context.Background(), []tools.ToolCall{{
+		ID:        "call-1",
+		Name:      "build",
+		Arguments: json.RawMessage(`{}`),
+	}}, events)
+	close(events)
+
+	if len(results) != 1 || results[0].Result != buildSummary {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	var result *ToolEvent
+	for event := range events {
+		if event.Type == EventTypeToolResult {
+			result = event.Tool
+		}
+	}
+	if result == nil || result.Result != buildSummary || result.Display != buildLog {
+		t.Fatalf("expected the summary for the model and the log for display, got %+v", result)
+	}
+}
@@ -0,0 +1,144 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/tools"
+	"github.com/nachoal/simple-agent-go/tools/base"
+	"github.com/nachoal/simple-agent-go/tools/registry"
+)
+
+// countingTool records how many times it ran.
+type countingTool struct {
+	base.BaseTool
+	runs *atomic.Int32
+}
+
+func (t *countingTool) Parameters() interface{} { return &noopToolParams{} }
+
+func (t *countingTool) Execute(context.Context, json.RawMessage) (string, error) {
+	t.runs.Add(1)
+	return "ran", nil
+}
+
+// callThenAnswerClient asks for one call to tool, then answers "done". It
+// records every request so tests can inspect the advertised tools and the
+// tool message fed back.
+type callThenAnswerClient struct {
+	scriptedClient
+	tool  string
+	calls int
+	seen  []*llm.ChatRequest
+}
+
+func (c *callThenAnswerClient) step(req *llm.ChatRequest) llm.Message {
+	c.calls++
+	c.seen = append(c.seen, req)
+	if c.calls == 1 {
+		return llm.Message{
+			Role:    llm.RoleAssistant,
+			Content: llm.StringPtr(""),
+			ToolCalls: []llm.ToolCall{{
+				ID:       "call_1",
+				Type:     "function",
+				Function: llm.FunctionCall{Name: c.tool, Arguments: json.RawMessage(`{}`)},
+			}},
+		}
+	}
+	return llm.Message{Role: llm.RoleAssistant, Content: llm.StringPtr("done")}
+}
+
+func (c *callThenAnswerClient) Chat(_ context.Context, req *llm.ChatRequest) (*llm.ChatResponse, error) {
+	return &llm.ChatResponse{Choices: []llm.Choice{{Message: c.step(req), FinishReason: "stop"}}}, nil
+}
+
+func (c *callThenAnswerClient) ChatStream(_ context.Context, req *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	msg := c.step(req)
+	ch := make(chan llm.StreamEvent, 1)
+	ch <- llm.StreamEvent{Choices: []llm.Choice{{Delta: &msg}}}
+	close(ch)
+	return ch, nil
+}
+
+func newPermissionAgent(t *testing.T, client llm.Client, runs *atomic.Int32, opts ...Option) *agent {
+	t.Helper()
+	reg := registry.New()
+	for _, name := range []string{"noop_tool", "bash"} {
+		name := name
+		if err := reg.Register(name, func() tools.Tool {
+			return &countingTool{BaseTool: base.BaseTool{ToolName: name, ToolDesc: "counts runs"}, runs: runs}
+		}); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	a := New(client, opts...).(*agent)
+	a.toolRegistry = reg
+	return a
+}
+
+func assertDeniedToolMessage(t *testing.T, req *llm.ChatRequest) {
+	t.Helper()
+	last := req.Messages[len(req.Messages)-1]
+	if last.Role != llm.RoleTool || last.ToolCallID != "call_1" || !strings.Contains(llm.GetStringValue(last.Content), "tool not permitted: bash") {
+		t.Fatalf("expected a not-permitted tool message, got %+v", last)
+	}
+}
+
+func TestQuery_DisallowedToolIsNotExecuted(t *testing.T) {
+	var runs atomic.Int32
+	client := &callThenAnswerClient{tool: "bash"}
+	a := newPermissionAgent(t, client, &runs, WithTools([]string{"noop_tool"}))
+
+	resp, err := a.Query(context.Background(), "list files")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if runs.Load() != 0 {
+		t.Fatalf("disallowed tool ran %d times", runs.Load())
+	}
+	if resp.Content != "done" || len(client.seen) != 2 {
+		t.Fatalf("expected the model to get a second turn, got %q after %d requests", resp.Content, len(client.seen))
+	}
+	assertDeniedToolMessage(t, client.seen[1])
+}
+
+func TestQueryStream_BlockedToolIsNotExecutedOrAdvertised(t *testing.T) {
+	var runs atomic.Int32
+	client := &callThenAnswerClient{tool: "bash"}
+	a := newPermissionAgent(t, client, &runs, WithTools([]string{"noop_tool", "bash"}), WithBlockedTools([]string{"bash"}))
+
+	stream, err := a.QueryStream(context.Background(), "list files")
+	if err != nil {
+		t.Fatalf("QueryStream: %v", err)
+	}
+	var denied *ToolEvent
+	for event := range stream {
+		if event.Type == EventTypeToolResult && event.Tool != nil && event.Tool.Name == "bash" {
+			denied = event.Tool
+		}
+	}
+
+	if runs.Load() != 0 {
+		t.Fatalf("blocked tool ran %d times", runs.Load())
+	}
+	if denied == nil || denied.Error == nil {
+		t.Fatalf("expected an error result event for the blocked tool, got %+v", denied)
+	}
+	if len(client.seen) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(client.seen))
+	}
+	assertDeniedToolMessage(t, client.seen[1])
+	for _, schema := range client.seen[0].Tools {
+		if fn, _ := schema["function"].(map[string]interface{}); fn["name"] == "bash" {
+			t.Fatal("blocked tool must not be advertised")
+		}
+	}
+	if len(client.seen[0].Tools) != 1 {
+		t.Fatalf("expected only noop_tool advertised, got %d schemas", len(client.seen[0].Tools))
+	}
+}
@@ -0,0 +1,136 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/tools"
+	"github.com/nachoal/simple-agent-go/tools/base"
+	"github.com/nachoal/simple-agent-go/tools/registry"
+)
+
+type progressTestParams struct{}
+
+// progressTestTool reports three progress updates before returning.
+type progressTestTool struct {
+	base.BaseTool
+}
+
+func (t *progressTestTool) Parameters() interface{} { return &progressTestParams{} }
+
+func (t *progressTestTool) Execute(ctx context.Context, _ json.RawMessage) (string, error) {
+	for i := 1; i <= 3; i++ {
+		tools.ReportProgressPercent(ctx, fmt.Sprintf("step %d", i), float64(i)/3)
+	}
+	return "done", nil
+}
+
+func TestExecuteToolsWithEvents_ForwardsToolProgress(t *testing.T) {
+	reg := registry.New()
+	if err := reg.Register("progress_tool", func() tools.Tool {
+		return &progressTestTool{BaseTool: base.BaseTool{ToolName: "progress_tool", ToolDesc: "reports progress"}}
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	a := New(nil).(*agent)
+	a.toolRegistry = reg
+
+	events := make(chan StreamEvent, 16)
+	results := a.executeToolsWithEvents(context.Background(), []tools.ToolCall{{
+		ID:        "call-1",
+		Name:      "progress_tool",
+		Arguments: json.RawMessage(`{}`),
+	}}, events)
+	close(events)
+
+	if len(results) != 1 || results[0].Error != nil || results[0].Result != "done" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+
+	var progress []*ToolEvent
+	for event := range events {
+		if event.Type == EventTypeToolProgress {
+			progress = append(progress, event.Tool)
+		}
+	}
+	if len(progress) != 3 {
+		t.Fatalf("expected 3 progress events, got %d", len(progress))
+	}
+	for i, event := range progress {
+		if event.ID != "call-1" || event.Name != "progress_tool" || event.Message != fmt.Sprintf("step %d", i+1) {
+			t.Fatalf("unexpected progress event %d: %+v", i, event)
+		}
+	}
+	if progress[2].Progress != 1 {
+		t.Fatalf("expected final progress of 1, got %v", progress[2].Progress)
+	}
+}
+
+// progressStreamClient asks for progress_tool once, then answers "done".
+type progressStreamClient struct {
+	scriptedClient
+	calls int
+}
+
+func (c *progressStreamClient) ChatStream(context.Context, *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	c.calls++
+	msg := llm.Message{Role: llm.RoleAssistant, Content: llm.StringPtr("done")}
+	if c.calls == 1 {
+		msg = llm.Message{
+			Role:    llm.RoleAssistant,
+			Content: llm.StringPtr(""),
+			ToolCalls: []llm.ToolCall{{
+				ID:       "call-1",
+				Type:     "function",
+				Function: llm.FunctionCall{Name: "progress_tool", Arguments: json.RawMessage(`{}`)},
+			}},
+		}
+	}
+	ch := make(chan llm.StreamEvent, 1)
+	ch <- llm.StreamEvent{Choices: []llm.Choice{{Delta: &msg}}}
+	close(ch)
+	return ch, nil
+}
+
+func TestQueryStream_ForwardsToolProgress(t *testing.T) {
+	reg := registry.New()
+	if err := reg.Register("progress_tool", func() tools.Tool {
+		return &progressTestTool{BaseTool: base.BaseTool{ToolName: "progress_tool", ToolDesc: "reports progress"}}
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	a := New(&progressStreamClient{}, WithTools([]string{"progress_tool"})).(*agent)
+	a.toolRegistry = reg
+
+	stream, err := a.QueryStream(context.Background(), "run it")
+	if err != nil {
+		t.Fatalf("QueryStream: %v", err)
+	}
+	var progress []*ToolEvent
+	sawResult := false
+	for event := range stream {
+		switch event.Type {
+		case EventTypeToolProgress:
+			if sawResult {
+				t.Fatal("progress event arrived after the tool's result")
+			}
+			progress = append(progress, event.Tool)
+		case EventTypeToolResult:
+			sawResult = true
+		case EventTypeError:
+			t.Fatalf("unexpected error: %v", event.Error)
+		}
+	}
+	if len(progress) != 3 {
+		t.Fatalf("expected 3 progress events, got %d", len(progress))
+	}
+	for i, event := range progress {
+		if event.ID != "call-1" || event.Name != "progress_tool" || event.Message != fmt.Sprintf("step %d", i+1) {
+			t.Fatalf("unexpected progress event %d: %+v", i, event)
+		}
+	}
+}
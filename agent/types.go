@@ -2,14 +2,21 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/llm/cost"
 	"github.com/nachoal/simple-agent-go/tools"
 )
 
+// ErrBusy is returned by Query and QueryStream when the agent is already
+// running a query and was created with WithFailWhenBusy
+var ErrBusy = errors.New("agent is busy with another query")
+
 // Config contains agent configuration
 type Config struct {
+	Name            string
 	SystemPrompt    string
 	Model           string
 	MaxIterations   int
@@ -18,12 +25,31 @@ type Config struct {
 	MaxTokens       int
 	TopP            float32
 	ExtraBody       map[string]interface{}
-	Tools           []string
+	Tools           []string // Allowlist of tools the agent may advertise and run; empty means all
+	BlockedTools    []string // Tools the agent never advertises or runs, even if allowed
 	Verbose         bool
-	Timeout         time.Duration
+	Timeout         time.Duration // Per-request HTTP timeout
+	Deadline        time.Duration // Wall-clock cap on a whole Query or QueryStream; 0 means none
 	MemorySize      int
+	RetryBudget     int // Total LLM retries allowed per Query; 0 means unlimited
 	StreamResponses bool
+	FailWhenBusy    bool                // Return ErrBusy instead of waiting when another query is running
 	progressHandler func(ProgressEvent) // temporary storage for handler
+	// Fallback
+	FallbackProviders []string                                  // Providers tried in order when the primary client fails
+	clientFactory     func(provider string) (llm.Client, error) // builds FallbackProviders clients
+	// Cost
+	CostTracker *cost.CostEstimator // Prices requests and records what they cost; nil disables cost tracking
+	MaxCost     float64             // Refuse requests that would take CostTracker's session total past this many USD; 0 means no limit
+	// Pacing
+	MinInterval              time.Duration // Minimum delay between successive LLM calls; 0 disables pacing
+	MinIntervalAcrossQueries bool          // Also keep MinInterval between the last call of one query and the next
+	// JSON
+	JSONRepairAttempts int // Times QueryJSON re-prompts the model with the parse error before failing
+	// Model selection
+	ModelSelector func([]llm.Model) llm.Model // Chooses Model from the provider's models before the first query; nil keeps Model
+	// Loop guard
+	MaxRepeatedToolCalls int // Rounds in a row the same tool calls may run before the agent answers them from the last result and asks for a reply without tools; 0 disables the check
 	// Feature flags
 	EnableLMStudioParser bool // Parse LM Studio channel-markup tool calls when true
 }
@@ -35,6 +61,7 @@ func DefaultConfig() Config {
 		Model:                "",
 		MaxIterations:        1000,
 		MaxToolCalls:         1000,
+		MaxRepeatedToolCalls: 3,
 		Temperature:          0.7,
 		MaxTokens:            8192,
 		TopP:                 0,
@@ -64,11 +91,20 @@ type Memory struct {
 
 // Response represents an agent response
 type Response struct {
+	AgentName    string
 	Content      string
 	ToolCalls    []ToolResult
 	Usage        *llm.Usage
 	FinishReason string
+	Plan         string // Plan produced by RunPlanExecute, if any
+	Truncated    bool   // Run stopped at MaxIterations; Content is the last partial answer
+	Iterations   int    // LLM steps taken
+	ToolFailures int    // Tool calls that returned an error, over all turns
 	Error        error
+
+	// Cost
+	EstimatedCostUSD float64 // Usage priced from the cost tracker's or the default price table
+	CostAvailable    bool    // False when usage was missing or a model has no price; EstimatedCostUSD is then 0
 }
 
 // ToolResult is an alias for tools.ToolResult
@@ -76,41 +112,63 @@ type ToolResult = tools.ToolResult
 
 // StreamEvent represents an event in the response stream
 type StreamEvent struct {
-	Type    EventType
-	Content string
-	Message *llm.Message
-	Tool    *ToolEvent
-	Error   error
+	Type       EventType
+	Content    string
+	Message    *llm.Message
+	Tool       *ToolEvent
+	Usage      *llm.Usage
+	Iterations int // Steps taken, set on max_iterations events
+	Error      error
+	ToolBatch  *ToolBatchSummary // Set on tool_batch_complete events
+	ToolCalls  []llm.ToolCall    // Set on tool_call_proposed events
+	// FinishReason is set on complete events to the provider's reason for
+	// ending the final response: "stop", or "length" when it was cut off at
+	// the token limit, for example
+	FinishReason string
+}
+
+// ToolBatchSummary counts how the tool calls of one model turn ended
+type ToolBatchSummary struct {
+	Succeeded   int
+	Failed      int
+	FailedTools []string // Names of the failed tools, in call order
 }
 
 // EventType represents the type of stream event
 type EventType string
 
 const (
-	EventTypeMessageStart  EventType = "message_start"
-	EventTypeMessageUpdate EventType = "message_update"
-	EventTypeMessageEnd    EventType = "message_end"
-	EventTypeMessage       EventType = "message"
-	EventTypeToolStart     EventType = "tool_start"
-	EventTypeToolProgress  EventType = "tool_progress"
-	EventTypeToolResult    EventType = "tool_result"
-	EventTypeToolTimeout   EventType = "tool_timeout"
-	EventTypeToolCancel    EventType = "tool_cancel"
-	EventTypeThinking      EventType = "thinking" // LLM is reasoning
-	EventTypeError         EventType = "error"
-	EventTypeComplete      EventType = "complete"
+	EventTypeMessageStart      EventType = "message_start"
+	EventTypeMessageUpdate     EventType = "message_update"     // Message is the cumulative assistant message so far
+	EventTypeMessageEnd        EventType = "message_end"        // Message is the final assistant message with completed tool calls
+	EventTypeMessage           EventType = "message"            // Content is a text delta
+	EventTypeToolCallProposed  EventType = "tool_call_proposed" // ToolCalls holds a turn's tool calls, sent before any of them starts
+	EventTypeToolStart         EventType = "tool_start"
+	EventTypeToolProgress      EventType = "tool_progress"
+	EventTypeToolResult        EventType = "tool_result"
+	EventTypeToolTimeout       EventType = "tool_timeout"
+	EventTypeToolCancel        EventType = "tool_cancel"
+	EventTypeToolBatchComplete EventType = "tool_batch_complete" // ToolBatch summarizes the turn's tool calls once all have finished
+	EventTypeUserMessage       EventType = "user_message"        // Content is a queued user message added to memory mid-run
+	EventTypeThinking          EventType = "thinking"            // LLM is reasoning
+	EventTypeUsage             EventType = "usage"               // Usage is the run's cumulative token usage so far
+	EventTypeError             EventType = "error"
+	EventTypeComplete          EventType = "complete"
+	EventTypeMaxIterations     EventType = "max_iterations" // Run stopped at MaxIterations; Content is the last assistant content
 )
 
 // ToolEvent contains information about a tool execution
 type ToolEvent struct {
-	ID       string                 // Unique tool execution ID
-	Name     string                 // Tool name
-	Args     map[string]interface{} // Parsed arguments
-	ArgsRaw  string                 // Raw JSON string
-	Result   string                 // Execution result
-	Error    error                  // Execution error
-	Progress float64                // Progress percentage (0-1)
-	Message  string                 // Progress message
+	ID          string                 // Unique tool execution ID
+	Name        string                 // Tool name
+	Args        map[string]interface{} // Parsed arguments
+	ArgsRaw     string                 // Raw JSON string
+	Result      string                 // Execution result, as sent to the model
+	Display     string                 // Result to show the user; differs from Result when the tool returns separate display text
+	Error       error                  // Execution error
+	Progress    float64                // Progress percentage (0-1)
+	Message     string                 // Progress message
+	OutputBytes int                    // Size of the tool's output when Result was truncated, 0 otherwise
 }
 
 // ProgressEvent represents agent progress events
@@ -158,6 +216,12 @@ type Agent interface {
 
 	// GetRequestParams returns the current per-request model parameters
 	GetRequestParams() RequestParams
+
+	// QueryJSON sends a query asking for a JSON reply and decodes it into v
+	QueryJSON(ctx context.Context, query string, v interface{}) (*Response, error)
+
+	// Name returns the configured agent name, or "" if none was set
+	Name() string
 }
 
 const defaultSystemPrompt = `You are an AI assistant that can leverage external tools to answer the user.
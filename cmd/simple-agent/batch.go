@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/nachoal/simple-agent-go/agent"
+	"golang.org/x/term"
+)
+
+// Exit codes for scripted use
+const (
+	exitLLMError    = 1
+	exitToolError   = 2
+	exitConfigError = 3
+)
+
+// exitError carries the process exit code for an error returned by a command.
+// Errors without one exit with 1.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitCode tags err with code, leaving nil and already-tagged errors alone.
+func withExitCode(code int, err error) error {
+	var tagged *exitError
+	if err == nil || errors.As(err, &tagged) {
+		return err
+	}
+	return &exitError{code: code, err: err}
+}
+
+// exitCode returns the exit code for an error returned by rootCmd.Execute.
+func exitCode(err error) int {
+	var tagged *exitError
+	if errors.As(err, &tagged) {
+		return tagged.code
+	}
+	return 1
+}
+
+// stdinIsTerminal is a variable so tests can simulate piped input.
+var stdinIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// appendPipedInput adds everything read from r to the query, separated by a
+// blank line, so `cat file | simple-agent query "summarize this"` sends both.
+func appendPipedInput(query string, r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read stdin: %w", err)
+	}
+	piped := strings.TrimRight(string(data), "\r\n")
+	switch {
+	case strings.TrimSpace(piped) == "":
+		return query, nil
+	case strings.TrimSpace(query) == "":
+		return piped, nil
+	default:
+		return query + "\n\n" + piped, nil
+	}
+}
+
+// parseQueryOutputFormat validates --output-format.
+func parseQueryOutputFormat(format string) (string, error) {
+	switch f := strings.ToLower(strings.TrimSpace(format)); f {
+	case "", "text":
+		return "text", nil
+	case "json", "csv":
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported --output-format %q (use text, json, or csv)", format)
+	}
+}
+
+// writeQueryOutput prints a query response in the requested format: text is
+// the response content alone, json an object with the response, token count,
+// and model, and csv one row per tool call result.
+func writeQueryOutput(w io.Writer, format string, response *agent.Response, model string) error {
+	switch format {
+	case "json":
+		payload := struct {
+			Response string `json:"response"`
+			Tokens   int    `json:"tokens"`
+			Model    string `json:"model"`
+		}{Response: response.Content, Model: model}
+		if response.Usage != nil {
+			payload.Tokens = response.Usage.TotalTokens
+		}
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case "csv":
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"id", "tool", "status", "result"})
+		for _, result := range response.ToolCalls {
+			status, content := "ok", result.Result
+			if result.Error != nil {
+				status, content = "error", result.Error.Error()
+			}
+			_ = cw.Write([]string{result.ID, result.Name, status, content})
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		_, err := fmt.Fprintln(w, response.Content)
+		return err
+	}
+}
+
+// toolCallsFailed reports whether any tool call in the response failed.
+func toolCallsFailed(response *agent.Response) bool {
+	for _, result := range response.ToolCalls {
+		if result.Error != nil {
+			return true
+		}
+	}
+	return false
+}
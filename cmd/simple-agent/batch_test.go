@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/agent"
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+func TestAppendPipedInput(t *testing.T) {
+	tests := []struct {
+		query, stdin, want string
+	}{
+		{query: "summarize this:", stdin: "line one\nline two\n", want: "summarize this:\n\nline one\nline two"},
+		{query: "", stdin: "just stdin\n", want: "just stdin"},
+		{query: "just args", stdin: "", want: "just args"},
+	}
+	for _, tt := range tests {
+		got, err := appendPipedInput(tt.query, strings.NewReader(tt.stdin))
+		if err != nil {
+			t.Fatalf("appendPipedInput: %v", err)
+		}
+		if got != tt.want {
+			t.Fatalf("appendPipedInput(%q, %q) = %q, want %q", tt.query, tt.stdin, got, tt.want)
+		}
+	}
+}
+
+func TestWriteQueryOutputFormats(t *testing.T) {
+	response := &agent.Response{
+		Content: "The answer is 4",
+		Usage:   &llm.Usage{TotalTokens: 42},
+		ToolCalls: []agent.ToolResult{
+			{ID: "call_1", Name: "calculate", Result: "4"},
+			{ID: "call_2", Name: "read", Error: errors.New("file, not found")},
+		},
+	}
+
+	var text bytes.Buffer
+	if err := writeQueryOutput(&text, "text", response, "gpt-4o"); err != nil {
+		t.Fatal(err)
+	}
+	if text.String() != "The answer is 4\n" {
+		t.Fatalf("unexpected text output %q", text.String())
+	}
+
+	var js bytes.Buffer
+	if err := writeQueryOutput(&js, "json", response, "gpt-4o"); err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"response":"The answer is 4","tokens":42,"model":"gpt-4o"}` + "\n"; js.String() != want {
+		t.Fatalf("got %q, want %q", js.String(), want)
+	}
+
+	var rows bytes.Buffer
+	if err := writeQueryOutput(&rows, "csv", response, "gpt-4o"); err != nil {
+		t.Fatal(err)
+	}
+	want := "id,tool,status,result\ncall_1,calculate,ok,4\ncall_2,read,error,\"file, not found\"\n"
+	if rows.String() != want {
+		t.Fatalf("got %q, want %q", rows.String(), want)
+	}
+}
+
+func TestParseQueryOutputFormatRejectsUnknown(t *testing.T) {
+	if format, err := parseQueryOutputFormat("JSON"); err != nil || format != "json" {
+		t.Fatalf("expected json, got %q (%v)", format, err)
+	}
+	if _, err := parseQueryOutputFormat("yaml"); err == nil {
+		t.Fatal("expected an error for yaml")
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	config := withExitCode(exitConfigError, errors.New("bad flag"))
+	if got := exitCode(fmt.Errorf("wrapped: %w", config)); got != exitConfigError {
+		t.Fatalf("expected config exit code through wrapping, got %d", got)
+	}
+	if got := exitCode(withExitCode(exitLLMError, config)); got != exitConfigError {
+		t.Fatalf("retagging should keep the original code, got %d", got)
+	}
+	if got := exitCode(errors.New("plain")); got != 1 {
+		t.Fatalf("expected 1 for untagged errors, got %d", got)
+	}
+	if withExitCode(exitToolError, nil) != nil {
+		t.Fatal("expected nil to stay nil")
+	}
+	if !toolCallsFailed(&agent.Response{ToolCalls: []agent.ToolResult{{Error: errors.New("boom")}}}) {
+		t.Fatal("expected a failed tool call to be reported")
+	}
+}
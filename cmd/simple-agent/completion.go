@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/config"
+	"github.com/nachoal/simple-agent-go/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// completionModelTimeout bounds the ListModels call behind --model completion
+// so pressing Tab never hangs on a slow or unreachable provider.
+const completionModelTimeout = 3 * time.Second
+
+// queryStarters are offered when completing the query command's message
+var queryStarters = []string{
+	"Explain this codebase",
+	"Summarize the recent changes",
+	"Find bugs in",
+	"Write tests for",
+	"Refactor",
+	"What does this error mean:",
+}
+
+var completionInstall bool
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish]",
+	Short: "Generate a shell completion script",
+	Long: `Print a completion script for bash, zsh, or fish.
+
+Load it in the current shell with:
+
+  source <(simple-agent completion bash)
+  source <(simple-agent completion zsh)
+  simple-agent completion fish | source
+
+or pass --install to add it to your shell's startup files: bash and zsh get a
+line in ~/.bashrc or ~/.zshrc, fish gets ~/.config/fish/completions/simple-agent.fish.`,
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: []string{"bash", "zsh", "fish"},
+	RunE:      runCompletion,
+}
+
+func runCompletion(cmd *cobra.Command, args []string) error {
+	shell := args[0]
+	if completionInstall {
+		path, err := installCompletion(shell)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Installed %s completions in %s; restart your shell to use them\n", shell, path)
+		return nil
+	}
+	return writeCompletion(cmd.OutOrStdout(), shell)
+}
+
+func writeCompletion(w io.Writer, shell string) error {
+	switch shell {
+	case "bash":
+		return rootCmd.GenBashCompletionV2(w, true)
+	case "zsh":
+		return rootCmd.GenZshCompletion(w)
+	case "fish":
+		return rootCmd.GenFishCompletion(w, true)
+	default:
+		return fmt.Errorf("unsupported shell %q (use bash, zsh, or fish)", shell)
+	}
+}
+
+// installCompletion makes the completions load in new shells and returns the
+// file it changed. bash and zsh source the generated script from their rc
+// file, so it stays current across upgrades; running it again is a no-op.
+func installCompletion(shell string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+
+	if shell == "fish" {
+		path := filepath.Join(home, ".config", "fish", "completions", "simple-agent.fish")
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", err
+		}
+		file, err := os.Create(path)
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+		return path, writeCompletion(file, shell)
+	}
+
+	var path string
+	switch shell {
+	case "bash":
+		path = filepath.Join(home, ".bashrc")
+	case "zsh":
+		path = filepath.Join(home, ".zshrc")
+	default:
+		return "", fmt.Errorf("unsupported shell %q (use bash, zsh, or fish)", shell)
+	}
+	line := fmt.Sprintf("source <(simple-agent completion %s)", shell)
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if strings.Contains(string(existing), line) {
+		return path, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	snippet := "\n# simple-agent shell completion\n" + line + "\n"
+	if len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+		snippet = "\n" + snippet
+	}
+	if _, err := file.WriteString(snippet); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func completeProviders(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	loadCompletionModelRegistry()
+	return filterCompletions(allProviderNames(), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeModels lists the models of the provider given with --provider, or
+// the configured default provider, asking the provider itself when it can be
+// reached and falling back to the models declared in models.json.
+func completeModels(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	loadCompletionModelRegistry()
+	name := completionProvider()
+
+	seen := make(map[string]struct{})
+	var ids []string
+	add := func(id string) {
+		if _, ok := seen[id]; ok || id == "" {
+			return
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+
+	if client, err := createLLMClient(name, getDefaultModel(name)); err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), completionModelTimeout)
+		listed, err := client.ListModels(ctx)
+		cancel()
+		client.Close()
+		if err == nil {
+			for _, m := range listed {
+				add(m.ID)
+			}
+		}
+	}
+	if customModelRegistry != nil {
+		for _, m := range customModelRegistry.StaticModels()[name] {
+			add(m.ID)
+		}
+	}
+	sort.Strings(ids)
+	return filterCompletions(ids, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeQueryStarters(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return filterCompletions(queryStarters, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completionProvider resolves the provider the same way the TUI does: the
+// --provider flag, then the saved default, then DEFAULT_PROVIDER.
+func completionProvider() string {
+	name := provider
+	if name == "" {
+		if configManager, err := config.NewManager(); err == nil {
+			name = configManager.GetDefaultProvider()
+		}
+	}
+	if name == "" {
+		name = getEnvOrDefault("DEFAULT_PROVIDER", "openai")
+	}
+	return canonicalProvider(name)
+}
+
+// loadCompletionModelRegistry loads models.json for completions, which run
+// without the setup the TUI and query commands do.
+func loadCompletionModelRegistry() {
+	if customModelRegistry != nil {
+		return
+	}
+	modelsPath, err := models.DefaultModelsPath()
+	if err != nil {
+		return
+	}
+	registry := models.NewRegistry(modelsPath)
+	if err := registry.Reload(); err != nil {
+		return
+	}
+	customModelRegistry = registry
+}
+
+func filterCompletions(values []string, prefix string) []string {
+	var out []string
+	lowerPrefix := strings.ToLower(prefix)
+	for _, value := range values {
+		if strings.HasPrefix(strings.ToLower(value), lowerPrefix) {
+			out = append(out, value)
+		}
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCompleteProvidersFiltersByPrefix(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	all, _ := completeProviders(rootCmd, nil, "")
+	for _, want := range []string{"openai", "anthropic", "ollama"} {
+		if !slices.Contains(all, want) {
+			t.Fatalf("expected %q in provider completions, got %v", want, all)
+		}
+	}
+
+	if got, _ := completeProviders(rootCmd, nil, "o"); !slices.Equal(got, []string{"ollama", "openai"}) {
+		t.Fatalf("expected ollama and openai for prefix o, got %v", got)
+	}
+}
+
+func TestCompleteQueryStartersOnlyForFirstArgument(t *testing.T) {
+	got, _ := completeQueryStarters(queryCmd, nil, "Expl")
+	if !slices.Equal(got, []string{"Explain this codebase"}) {
+		t.Fatalf("unexpected starters %v", got)
+	}
+	if got, _ := completeQueryStarters(queryCmd, []string{"hello"}, ""); len(got) != 0 {
+		t.Fatalf("expected no completions after the message, got %v", got)
+	}
+}
+
+func TestCompletionCommandPrintsScript(t *testing.T) {
+	newSessionsTestHome(t)
+	t.Cleanup(func() { completionInstall = false })
+
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		if out := runSessionsCommand(t, "completion", shell); !strings.Contains(out, "simple-agent") {
+			t.Fatalf("%s completion script does not mention simple-agent:\n%s", shell, out)
+		}
+	}
+}
+
+func TestInstallCompletionIsIdempotent(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	rc := filepath.Join(home, ".zshrc")
+	if err := os.WriteFile(rc, []byte("export EDITOR=vim"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		path, err := installCompletion("zsh")
+		if err != nil {
+			t.Fatalf("installCompletion: %v", err)
+		}
+		if path != rc {
+			t.Fatalf("expected %s, got %s", rc, path)
+		}
+	}
+	data, err := os.ReadFile(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(data), "source <(simple-agent completion zsh)"); got != 1 {
+		t.Fatalf("expected the snippet once, found %d times:\n%s", got, data)
+	}
+	if !strings.HasPrefix(string(data), "export EDITOR=vim\n") {
+		t.Fatalf("existing rc content was not preserved:\n%s", data)
+	}
+
+	path, err := installCompletion("fish")
+	if err != nil {
+		t.Fatalf("installCompletion fish: %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || !strings.Contains(string(data), "simple-agent") {
+		t.Fatalf("expected a fish completion script at %s: %v", path, err)
+	}
+}
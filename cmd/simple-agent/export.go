@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nachoal/simple-agent-go/history"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportSessionID string
+	exportFormat    string
+	exportOutput    string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a saved conversation as Markdown, HTML, or JSON",
+	Long: `Export a saved conversation as Markdown, HTML, or JSON.
+
+Without --session-id the most recent session for the current directory is
+exported. Without --output the file is written to
+~/Downloads/simple-agent-<session-id>.<ext> (or the current directory when
+there is no Downloads folder); use --output - to print to stdout.`,
+	Args: cobra.NoArgs,
+	RunE: runExport,
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	format, err := history.ParseExportFormat(exportFormat)
+	if err != nil {
+		return err
+	}
+
+	historyMgr, err := newHistoryManager()
+	if err != nil {
+		return fmt.Errorf("failed to create history manager: %w", err)
+	}
+	var session *history.Session
+	if exportSessionID != "" {
+		session, err = historyMgr.LoadSession(exportSessionID)
+		if err != nil {
+			return fmt.Errorf("session %s not found: %w", exportSessionID, err)
+		}
+	} else {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		session, err = historyMgr.GetLastSessionForPath(cwd)
+		if err != nil {
+			return err
+		}
+	}
+
+	if exportOutput == "-" {
+		return history.Export(cmd.OutOrStdout(), session, format)
+	}
+
+	var buf bytes.Buffer
+	if err := history.Export(&buf, session, format); err != nil {
+		return err
+	}
+	path := exportOutput
+	if path == "" {
+		path = history.DefaultExportPath(session.ID, format)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Exported session %s to %s\n", session.ID, path)
+	return nil
+}
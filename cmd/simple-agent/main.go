@@ -3,11 +3,15 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -20,6 +24,7 @@ import (
 	"github.com/nachoal/simple-agent-go/history"
 	"github.com/nachoal/simple-agent-go/internal/harnessllm"
 	"github.com/nachoal/simple-agent-go/internal/models"
+	"github.com/nachoal/simple-agent-go/internal/prompttemplate"
 	"github.com/nachoal/simple-agent-go/internal/resources"
 	"github.com/nachoal/simple-agent-go/internal/runlog"
 	"github.com/nachoal/simple-agent-go/internal/runtimeprompt"
@@ -28,16 +33,23 @@ import (
 	"github.com/nachoal/simple-agent-go/internal/userpaths"
 	"github.com/nachoal/simple-agent-go/llm"
 	"github.com/nachoal/simple-agent-go/llm/anthropic"
+	"github.com/nachoal/simple-agent-go/llm/circuitbreaker"
+	"github.com/nachoal/simple-agent-go/llm/cohere"
+	"github.com/nachoal/simple-agent-go/llm/cost"
 	"github.com/nachoal/simple-agent-go/llm/deepseek"
 	"github.com/nachoal/simple-agent-go/llm/groq"
 	"github.com/nachoal/simple-agent-go/llm/lmstudio"
+	"github.com/nachoal/simple-agent-go/llm/middleware"
 	"github.com/nachoal/simple-agent-go/llm/minmax"
+	"github.com/nachoal/simple-agent-go/llm/mistral"
 	"github.com/nachoal/simple-agent-go/llm/moonshot"
 	"github.com/nachoal/simple-agent-go/llm/ollama"
 	"github.com/nachoal/simple-agent-go/llm/openai"
 	"github.com/nachoal/simple-agent-go/llm/perplexity"
+	"github.com/nachoal/simple-agent-go/tools"
 	"github.com/nachoal/simple-agent-go/tools/registry"
 	"github.com/nachoal/simple-agent-go/tui"
+	"github.com/nachoal/simple-agent-go/tui/styles"
 )
 
 var (
@@ -46,25 +58,45 @@ var (
 	model        string
 	verbose      bool
 	yolo         bool
+	confirmCmds  bool
+	dockerImage  string
+	workdir      string
+	fallbacks    string
+	requestLog   string
 	continueConv bool
 	resume       string
 	resumeSet    bool
+	branchFrom   string
 	customParser string
 	toolsFlag    string
+	agentName    string
+	templateName string
+	templateVars []string
+	systemPrompt string
+	queryStream  bool
+	outputFormat string
+	grpcEndpoint string
+	grpcSession  string
 	maxTokens    int
+	maxCost      float64
 	timeoutMins  int
+	imageMaxDim  int
+	themeName    string
 	toolsJSON    bool
 	doctorJSON   bool
 	modelsJSON   bool
 
 	customModelRegistry *models.Registry
 
+	// requestLogFile receives LLM request/response lines when --request-log is set
+	requestLogFile *middleware.RotatingFile
+
 	// Root command
 	rootCmd = &cobra.Command{
 		Use:   "simple-agent",
 		Short: "AI agent with tool support",
 		Long:  "Simple Agent Go - A powerful AI agent framework with multiple LLM providers and tool support",
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			// Enable debug logging if verbose flag is set
 			if verbose {
 				os.Setenv("SIMPLE_AGENT_DEBUG", "true")
@@ -75,8 +107,44 @@ var (
 				os.Setenv("SIMPLE_AGENT_YOLO", "true")
 			}
 
+			// Ask before running bash commands outside the allowlist
+			if confirmCmds {
+				os.Setenv("SIMPLE_AGENT_BASH_CONFIRM", "true")
+			}
+
+			// Run bash tool commands inside a Docker container when requested
+			if dockerImage != "" {
+				os.Setenv("SIMPLE_AGENT_DOCKER_IMAGE", dockerImage)
+			}
+
+			// Confine the file tools, and bash, to --workdir instead of the current directory
+			if workdir != "" {
+				root, err := resolveWorkdir(workdir)
+				if err != nil {
+					return err
+				}
+				toolinit.SetWorkdir(root)
+			}
+
+			if maxCost < 0 {
+				return fmt.Errorf("--max-cost must not be negative")
+			}
+			if imageMaxDim < 0 {
+				return fmt.Errorf("--image-max-dimension must not be negative")
+			}
+
+			// Log every LLM request and response to --request-log
+			if requestLog != "" {
+				file, err := middleware.NewRotatingFile(requestLog, middleware.DefaultMaxLogSize, middleware.DefaultMaxLogBackups)
+				if err != nil {
+					return err
+				}
+				requestLogFile = file
+			}
+
 			// Check if resume flag was explicitly set
 			resumeSet = cmd.Flags().Changed("resume")
+			return nil
 		},
 		RunE: runTUI,
 	}
@@ -85,8 +153,13 @@ var (
 	queryCmd = &cobra.Command{
 		Use:   "query [message]",
 		Short: "Send a one-shot query without entering TUI",
-		Args:  cobra.MinimumNArgs(1),
-		RunE:  runQuery,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if templateName != "" || !stdinIsTerminal() {
+				return nil
+			}
+			return cobra.MinimumNArgs(1)(cmd, args)
+		},
+		RunE: runQuery,
 	}
 
 	// Tools command
@@ -129,19 +202,31 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&model, "model", "", "Model to use")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
 	rootCmd.PersistentFlags().BoolVar(&yolo, "yolo", false, "Allow the bash tool to run any command (DANGEROUS)")
+	rootCmd.PersistentFlags().BoolVar(&confirmCmds, "confirm-commands", false, "Ask before running bash commands outside the allowlist instead of rejecting them")
+	rootCmd.PersistentFlags().StringVar(&dockerImage, "docker-image", "", "Run bash tool commands in this Docker image with no network (overrides SIMPLE_AGENT_DOCKER_IMAGE)")
+	rootCmd.PersistentFlags().StringVar(&workdir, "workdir", "", "Directory the file tools are confined to and bash runs in (default: current directory)")
+	rootCmd.PersistentFlags().StringVar(&requestLog, "request-log", "", "Append a JSON line for each LLM request and response to this file (content is redacted)")
+	rootCmd.PersistentFlags().StringVar(&fallbacks, "fallback-providers", "", "Comma-separated providers to retry on, in order, when the main provider fails (e.g. openai,anthropic)")
+	rootCmd.PersistentFlags().StringVar(&agentName, "name", "", "Name the agent uses to identify itself (e.g. Researcher)")
 	rootCmd.PersistentFlags().StringVar(
 		&toolsFlag,
 		"tools",
 		"",
 		"Comma-separated tool names to enable (e.g. read,bash,edit,write). Use 'all' to enable all registered tools.",
 	)
+	rootCmd.PersistentFlags().StringVar(&systemPrompt, "system", "", "System prompt, or a path to a file containing it (overrides SIMPLE_AGENT_SYSTEM_PROMPT)")
 
 	// TUI-specific flags
 	rootCmd.Flags().BoolVarP(&continueConv, "continue", "c", false, "Continue the most recent conversation")
 	rootCmd.Flags().StringVarP(&resume, "resume", "r", "", "Resume a specific session ID or open the recent-session picker if no ID is provided")
+	rootCmd.Flags().StringVar(&branchFrom, "branch-from", "", "Start a new session forked from <session-id>:<message-index>")
+	rootCmd.Flags().StringVar(&themeName, "theme", "", "TUI color theme: "+strings.Join(styles.ThemeNames(), ", ")+" (default: the theme last chosen with /theme)")
 	rootCmd.PersistentFlags().StringVar(&customParser, "custom-parser", "", "Enable custom parsing for provider output (e.g., 'lmstudio')")
 	rootCmd.PersistentFlags().IntVar(&maxTokens, "max-tokens", 0, "Max tokens per completion (0 = use default: 8192)")
+	rootCmd.PersistentFlags().Float64Var(&maxCost, "max-cost", 0, "Stop sending requests once their estimated cost would take this session past this many USD (0 = no limit)")
 	rootCmd.PersistentFlags().IntVar(&timeoutMins, "timeout", 0, "Per-request timeout in minutes (0 = use default: 10)")
+	rootCmd.PersistentFlags().BoolVar(&encryptSessions, "encrypt-sessions", false, "Save sessions encrypted, asking for a passphrase unless "+sessionKeyEnv+" or encryption.key in the config sets one")
+	rootCmd.PersistentFlags().IntVar(&imageMaxDim, "image-max-dimension", 0, "Downscale image attachments to at most this many pixels on the longest side before sending (0 = send as-is)")
 
 	// Set NoOptDefVal for resume flag - this value is used when -r is provided without an argument
 	rootCmd.Flags().Lookup("resume").NoOptDefVal = "picker"
@@ -151,11 +236,44 @@ func init() {
 	rootCmd.AddCommand(toolsCmd)
 	rootCmd.AddCommand(modelsCmd)
 	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(sessionsCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(searchCmd)
+	rootCmd.AddCommand(completionCmd)
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configInitCmd)
 	toolsCmd.AddCommand(listToolsCmd)
+	toolsCmd.AddCommand(toolStatsCmd)
 	modelsCmd.AddCommand(listModelsCmd)
+	sessionsCmd.AddCommand(listSessionsCmd)
+	sessionsCmd.AddCommand(exportSessionCmd)
+	sessionsCmd.AddCommand(encryptSessionsCmd)
+	sessionsCmd.AddCommand(decryptSessionsCmd)
 	listToolsCmd.Flags().BoolVar(&toolsJSON, "json", false, "Output tools as JSON")
 	listModelsCmd.Flags().BoolVar(&modelsJSON, "json", false, "Output models as JSON")
 	doctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "Output diagnostics as JSON")
+	listSessionsCmd.Flags().BoolVar(&sessionsListJSON, "json", false, "Output sessions as JSON")
+	exportSessionCmd.Flags().StringVar(&sessionsExportFormat, "format", "json", "Output format: json or md")
+	exportSessionCmd.Flags().BoolVar(&sessionsExportAll, "all", false, "Export every session as a JSON array (or concatenated Markdown)")
+	exportCmd.Flags().StringVar(&exportSessionID, "session-id", "", "Session to export (default: the most recent session for the current directory)")
+	exportCmd.Flags().StringVar(&exportFormat, "format", "markdown", "Output format: markdown, html, or json")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "File to write, or - for stdout (default: ~/Downloads/simple-agent-<session-id>.<ext>)")
+	searchCmd.Flags().BoolVar(&searchReindex, "reindex", false, "Rebuild the search index from the session files before searching")
+	searchCmd.Flags().BoolVar(&searchJSON, "json", false, "Output matches as JSON")
+	queryCmd.Flags().StringVar(&templateName, "template", "", "Render the prompt from a template (name in ~/.simple-agent/templates or a file path)")
+	queryCmd.Flags().StringArrayVar(&templateVars, "var", nil, "Template variable as key=value (repeatable)")
+	queryCmd.Flags().BoolVar(&queryStream, "stream", false, "Print the response as it is generated (tool activity goes to stderr)")
+	queryCmd.Flags().StringVar(&outputFormat, "output-format", "text", "Output format: text (response only), json ({response, tokens, model}), or csv (one row per tool call result)")
+	queryCmd.Flags().StringVar(&grpcEndpoint, "grpc-endpoint", "", "Send the query to a 'simple-agent serve --grpc' server at this address instead of running it here")
+	queryCmd.Flags().StringVar(&grpcSession, "grpc-session", "", "With --grpc-endpoint, continue this server-side session")
+	configInitCmd.Flags().BoolVar(&configForce, "force", false, "Overwrite an existing .simple-agent.yaml")
+	completionCmd.Flags().BoolVar(&completionInstall, "install", false, "Add the completion script to your shell's startup files instead of printing it")
+
+	// Shell completion for flag values and the query message
+	rootCmd.RegisterFlagCompletionFunc("provider", completeProviders)
+	rootCmd.RegisterFlagCompletionFunc("model", completeModels)
+	queryCmd.ValidArgsFunction = completeQueryStarters
 
 	// Bind flags to viper
 	viper.BindPFlags(rootCmd.PersistentFlags())
@@ -175,21 +293,32 @@ func main() {
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
 func runTUI(cmd *cobra.Command, args []string) error {
+	// Input piped in means a script is driving us: answer once and exit
+	if !stdinIsTerminal() {
+		return runQuery(cmd, args)
+	}
+
 	// Enable debug logging if verbose flag is set
 	if verbose {
 		os.Setenv("SIMPLE_AGENT_DEBUG", "true")
 	}
+	defer flushToolStats()
 
 	// Create config manager
 	configManager, err := config.NewManager()
 	if err != nil {
 		return fmt.Errorf("failed to create config manager: %w", err)
 	}
+	if themeName != "" {
+		if _, ok := styles.LookupTheme(themeName); !ok {
+			return fmt.Errorf("unknown theme %q (available: %s)", themeName, strings.Join(styles.ThemeNames(), ", "))
+		}
+	}
 
 	// Resolve launch directory once; resume/continue may re-anchor the runtime later.
 	launchCwd, err := os.Getwd()
@@ -197,6 +326,13 @@ func runTUI(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get current directory: %w", err)
 	}
 
+	// Read a --system file before resume/continue can change the working directory.
+	project := configManager.Project()
+	baseSystemPrompt, err := resolveBaseSystemPrompt(projectSystemPrompt(systemPrompt, project))
+	if err != nil {
+		return err
+	}
+
 	// Get provider and model from config or flags
 	if provider == "" {
 		// First check config, then env, then default
@@ -221,7 +357,7 @@ func runTUI(cmd *cobra.Command, args []string) error {
 	}
 
 	// Initialize history manager
-	historyMgr, err := history.NewManager()
+	historyMgr, err := newHistoryManager()
 	if err != nil {
 		return fmt.Errorf("failed to initialize history: %w", err)
 	}
@@ -268,14 +404,14 @@ func runTUI(cmd *cobra.Command, args []string) error {
 	}
 
 	buildSystemPrompt := func() string {
-		return runtimeprompt.Build(agent.DefaultConfig().SystemPrompt, cwd, selfInfo, resourceLoader.Snapshot())
+		return runtimeprompt.Build(baseSystemPrompt, cwd, selfInfo, resourceLoader.Snapshot())
 	}
 
 	providerSetByFlag := cmd.Flags().Changed("provider")
 	allowStartupFallback := !providerSetByFlag || selection.restore
 	llmClient, provider, model, fallbackMsg, err := createLLMClientWithStartupFallback(provider, model, allowStartupFallback)
 	if err != nil {
-		return fmt.Errorf("failed to create %s client: %w", provider, err)
+		return fmt.Errorf("failed to create %s client: %w", provider, withSetupHint(err))
 	}
 	if fallbackMsg != "" {
 		fmt.Fprintf(os.Stderr, "Warning: %s\n", fallbackMsg)
@@ -299,8 +435,10 @@ func runTUI(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Create all provider clients for model selection.
+	// Create all provider clients for model selection, remembering why the
+	// others could not be created so the selector can say so.
 	providers := make(map[string]llm.Client)
+	unavailableProviders := make(map[string]string)
 	providerNames := allProviderNames()
 	successCount := 0
 	for _, name := range providerNames {
@@ -308,11 +446,14 @@ func runTUI(cmd *cobra.Command, args []string) error {
 		if err == nil {
 			providers[name] = client
 			successCount++
+		} else {
+			unavailableProviders[name] = providerUnavailableReason(name, err)
 		}
 	}
 	// Ensure currently selected provider is available in selector map.
 	if _, ok := providers[strings.ToLower(provider)]; !ok {
 		providers[strings.ToLower(provider)] = llmClient
+		delete(unavailableProviders, strings.ToLower(provider))
 		successCount++
 	}
 	if verbose {
@@ -322,18 +463,34 @@ func runTUI(cmd *cobra.Command, args []string) error {
 	// Create agent
 	enableLMStudioParser := strings.Contains(strings.ToLower(customParser), "lmstudio")
 
-	toolsRaw := strings.TrimSpace(toolsFlag)
+	toolsRaw := projectToolsFlag(toolsFlag, project)
 	toolsOverride, toolsAll, err := parseToolsOverride(toolsRaw)
 	if err != nil {
 		return err
 	}
+	fallbackProviders, err := parseFallbackProviders(fallbacks)
+	if err != nil {
+		return err
+	}
+
+	// Warn about providers that cannot be reached now rather than failing
+	// on the first message
+	healthTargets := map[string]llm.Client{provider: llmClient}
+	for _, name := range fallbackProviders {
+		if client, ok := providers[name]; ok {
+			healthTargets[name] = client
+		}
+	}
+	warnUnavailableProviders(context.Background(), os.Stderr, healthTargets)
 
 	effectiveToolsForHeader := agent.DefaultConfig().Tools
+	costTracker := cost.NewCostEstimator(configuredPriceTable())
 	buildAgentOptions := func(modelName string) []agent.Option {
 		opts := []agent.Option{
+			agent.WithName(agentName),
 			agent.WithModel(modelName),
 			agent.WithSystemPrompt(buildSystemPrompt()),
-			agent.WithMaxIterations(1000),
+			agent.WithMaxIterations(projectMaxIterations(project)),
 			agent.WithMaxToolCalls(1000),
 			agent.WithTemperature(0.7),
 			agent.WithLMStudioParser(enableLMStudioParser),
@@ -351,7 +508,9 @@ func runTUI(cmd *cobra.Command, args []string) error {
 				opts = append(opts, agent.WithTools(toolsOverride))
 			}
 		}
-		return opts
+		opts = append(opts, projectAgentOptions(project)...)
+		opts = append(opts, costAgentOptions(costTracker)...)
+		return append(opts, fallbackAgentOptions(fallbackProviders)...)
 	}
 	if toolsRaw != "" {
 		if toolsAll {
@@ -365,6 +524,9 @@ func runTUI(cmd *cobra.Command, args []string) error {
 
 	// Create history-aware agent
 	historyAgent := agent.NewHistoryAgent(agentInstance, historyMgr, session)
+	if configManager.AutoTitle() {
+		historyAgent.SetTitleGenerator(history.NewLLMTitleGenerator(llmClient, model))
+	}
 
 	// Restore memory if continuing/resuming
 	if selection.restore {
@@ -390,7 +552,7 @@ func runTUI(cmd *cobra.Command, args []string) error {
 			}
 		} else {
 			fmt.Println("\n=== DEFAULT SYSTEM PROMPT ===")
-			fmt.Println(agent.DefaultConfig().SystemPrompt)
+			fmt.Println(baseSystemPrompt)
 			fmt.Println("\n=== AVAILABLE TOOLS ===")
 			toolNames := registry.List()
 			for _, name := range toolNames {
@@ -406,6 +568,24 @@ func runTUI(cmd *cobra.Command, args []string) error {
 	// Create and run TUI (bordered version with providers and history)
 	tuiModel := tui.NewBorderedTUIWithHistory(llmClient, historyAgent, provider, model, providers, configManager)
 	tuiModel.SetConfiguredTools(effectiveToolsForHeader)
+	tuiModel.SetUnavailableProviders(unavailableProviders)
+	tuiModel.SetCostTracker(costTracker, maxCost)
+	tuiModel.SetImageMaxDimension(imageMaxDim)
+	// --theme wins over the theme saved by /theme
+	theme := themeName
+	if theme == "" {
+		theme = configManager.GetTheme()
+	}
+	if theme != "" {
+		if err := tuiModel.SetTheme(theme); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v; using the default theme\n", err)
+		}
+	}
+	if style := configManager.MarkdownStyle(); style != "" {
+		if err := tuiModel.SetMarkdownStyle(style); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v; using the theme's markdown style\n", err)
+		}
+	}
 	tuiModel.SetClientFactory(func(providerName, modelName string) (llm.Client, error) {
 		return createLLMClient(providerName, modelName)
 	})
@@ -424,10 +604,13 @@ func runTUI(cmd *cobra.Command, args []string) error {
 			}
 		}
 		refreshed := make(map[string]llm.Client)
+		clear(unavailableProviders)
 		for _, name := range allProviderNames() {
 			client, err := createLLMClient(name, getDefaultModel(name))
 			if err == nil {
 				refreshed[name] = client
+			} else {
+				unavailableProviders[name] = providerUnavailableReason(name, err)
 			}
 		}
 		for name := range providers {
@@ -447,7 +630,6 @@ func runTUI(cmd *cobra.Command, args []string) error {
 	}
 
 	printSessionResumeFooter(historyAgent.GetSession(), startedAt)
-
 	return nil
 }
 
@@ -462,6 +644,25 @@ func resolveTUISessionSelection(historyMgr *history.Manager, launchCwd string) (
 		return tuiSessionSelection{}, fmt.Errorf("cannot use --continue and --resume together")
 	}
 
+	if strings.TrimSpace(branchFrom) != "" {
+		if continueConv || resumeSet {
+			return tuiSessionSelection{}, fmt.Errorf("cannot use --branch-from with --continue or --resume")
+		}
+		sessionID, index, err := parseBranchFrom(branchFrom)
+		if err != nil {
+			return tuiSessionSelection{}, err
+		}
+		session, err := historyMgr.Branch(sessionID, index)
+		if err != nil {
+			return tuiSessionSelection{}, fmt.Errorf("failed to branch session %s: %w", sessionID, err)
+		}
+		return tuiSessionSelection{
+			session:      session,
+			restore:      true,
+			announcement: fmt.Sprintf("Branched session %s at message %d into new session %s.", sessionID, index, session.ID),
+		}, nil
+	}
+
 	if continueConv {
 		session, err := historyMgr.GetLastSession()
 		if err != nil {
@@ -535,6 +736,19 @@ func resolveTUISessionSelection(historyMgr *history.Manager, launchCwd string) (
 	}, nil
 }
 
+func parseBranchFrom(raw string) (string, int, error) {
+	raw = strings.TrimSpace(raw)
+	idx := strings.LastIndex(raw, ":")
+	if idx <= 0 || idx == len(raw)-1 {
+		return "", 0, fmt.Errorf("invalid --branch-from %q; expected <session-id>:<message-index>", raw)
+	}
+	index, err := strconv.Atoi(raw[idx+1:])
+	if err != nil || index < 0 {
+		return "", 0, fmt.Errorf("invalid message index in --branch-from %q", raw)
+	}
+	return raw[:idx], index, nil
+}
+
 func activateSessionWorkspace(session *history.Session) error {
 	if session == nil || strings.TrimSpace(session.Path) == "" {
 		return nil
@@ -604,18 +818,117 @@ func normalizeResumeArgs(args []string) []string {
 	return out
 }
 
+// resolveBaseSystemPrompt picks the base system prompt from --system, then
+// SIMPLE_AGENT_SYSTEM_PROMPT, then the agent default. A value naming an
+// existing file is replaced by the file's contents; anything else is used as
+// the prompt text itself.
+func resolveBaseSystemPrompt(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		value = strings.TrimSpace(os.Getenv("SIMPLE_AGENT_SYSTEM_PROMPT"))
+	}
+	if value == "" {
+		return agent.DefaultConfig().SystemPrompt, nil
+	}
+
+	if info, err := os.Stat(value); err == nil && !info.IsDir() {
+		data, err := os.ReadFile(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to read system prompt file %s: %w", value, err)
+		}
+		prompt := strings.TrimSpace(string(data))
+		if prompt == "" {
+			return "", fmt.Errorf("system prompt file %s is empty", value)
+		}
+		return prompt, nil
+	}
+	return value, nil
+}
+
+// resolveWorkdir returns --workdir as an absolute path, checking that it
+// names an existing directory.
+func resolveWorkdir(value string) (string, error) {
+	root, err := filepath.Abs(strings.TrimSpace(value))
+	if err != nil {
+		return "", fmt.Errorf("invalid --workdir %q: %w", value, err)
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return "", fmt.Errorf("invalid --workdir %q: %w", value, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("invalid --workdir %q: not a directory", value)
+	}
+	return root, nil
+}
+
+// buildQueryPrompt returns the one-shot prompt. With a template, the rendered
+// template comes first and any positional arguments are appended after it.
+func buildQueryPrompt(name string, vars []string, args []string) (string, error) {
+	message := strings.Join(args, " ")
+	if name == "" {
+		return message, nil
+	}
+
+	parsed, err := prompttemplate.ParseVars(vars)
+	if err != nil {
+		return "", err
+	}
+	text, err := prompttemplate.Load(name)
+	if err != nil {
+		return "", err
+	}
+	rendered, err := prompttemplate.Render(name, text, parsed)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(message) != "" {
+		rendered += "\n\n" + message
+	}
+	return rendered, nil
+}
+
 func runQuery(cmd *cobra.Command, args []string) error {
 	// Enable debug logging if verbose flag is set
 	if verbose {
 		os.Setenv("SIMPLE_AGENT_DEBUG", "true")
 	}
+	defer flushToolStats()
 
-	query := strings.Join(args, " ")
-
+	format, err := parseQueryOutputFormat(outputFormat)
+	if err != nil {
+		return withExitCode(exitConfigError, err)
+	}
+	if queryStream && format != "text" {
+		return withExitCode(exitConfigError, fmt.Errorf("--stream only works with --output-format text"))
+	}
+	query, err := buildQueryPrompt(templateName, templateVars, args)
+	if err != nil {
+		return withExitCode(exitConfigError, err)
+	}
+	if !stdinIsTerminal() {
+		if query, err = appendPipedInput(query, os.Stdin); err != nil {
+			return err
+		}
+	}
+	if strings.TrimSpace(query) == "" {
+		return withExitCode(exitConfigError, fmt.Errorf("no query given: pass a message or pipe one on stdin"))
+	}
+	if grpcEndpoint != "" {
+		return runRemoteQuery(context.Background(), grpcEndpoint, query, format)
+	}
 	cwd, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("failed to get current directory: %w", err)
 	}
+	project, _, err := config.LoadProject(cwd)
+	if err != nil {
+		return withExitCode(exitConfigError, err)
+	}
+	baseSystemPrompt, err := resolveBaseSystemPrompt(projectSystemPrompt(systemPrompt, project))
+	if err != nil {
+		return withExitCode(exitConfigError, err)
+	}
 
 	queryLogger, loggerErr := runlog.New(cwd, "query")
 	if loggerErr == nil {
@@ -628,7 +941,7 @@ func runQuery(cmd *cobra.Command, args []string) error {
 	}
 	selfInfo := selfknowledge.Discover(cwd)
 	buildSystemPrompt := func() string {
-		return runtimeprompt.Build(agent.DefaultConfig().SystemPrompt, cwd, selfInfo, resourceLoader.Snapshot())
+		return runtimeprompt.Build(baseSystemPrompt, cwd, selfInfo, resourceLoader.Snapshot())
 	}
 
 	modelsPath, err := models.DefaultModelsPath()
@@ -640,58 +953,24 @@ func runQuery(cmd *cobra.Command, args []string) error {
 		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 	}
 
-	// Get provider and model
-	if provider == "" {
-		provider = getEnvOrDefault("DEFAULT_PROVIDER", "openai")
-	}
-	provider = canonicalProvider(provider)
-	if model == "" {
-		model = getEnvOrDefault("DEFAULT_MODEL", getDefaultModel(provider))
-	}
+	provider, model = resolveProviderModel(provider, model, project)
 
 	// Create LLM client
 	providerSetByFlag := cmd.Flags().Changed("provider")
 	llmClient, _, _, fallbackMsg, err := createLLMClientWithStartupFallback(provider, model, !providerSetByFlag)
 	if err != nil {
-		return fmt.Errorf("failed to create LLM client: %w", err)
+		return withExitCode(exitConfigError, fmt.Errorf("failed to create LLM client: %w", err))
 	}
 	if fallbackMsg != "" {
 		fmt.Fprintf(os.Stderr, "Warning: %s\n", fallbackMsg)
 	}
 	defer llmClient.Close()
 
-	// Determine custom parsers
-	enableLMStudioParser := strings.Contains(strings.ToLower(customParser), "lmstudio")
-
 	// Create agent
-	toolsRaw := strings.TrimSpace(toolsFlag)
-	toolsOverride, toolsAll, err := parseToolsOverride(toolsRaw)
+	agentOpts, err := queryAgentOptions(model, buildSystemPrompt(), project, projectToolsFlag(toolsFlag, project))
 	if err != nil {
-		return err
-	}
-
-	agentOpts := []agent.Option{
-		agent.WithModel(model),
-		agent.WithSystemPrompt(buildSystemPrompt()),
-		agent.WithMaxIterations(1000),
-		agent.WithMaxToolCalls(1000),
-		agent.WithTemperature(0.7),
-		agent.WithLMStudioParser(enableLMStudioParser),
-	}
-	if maxTokens > 0 {
-		agentOpts = append(agentOpts, agent.WithMaxTokens(maxTokens))
-	}
-	if timeoutMins > 0 {
-		agentOpts = append(agentOpts, agent.WithTimeout(time.Duration(timeoutMins)*time.Minute))
-	}
-	if toolsRaw != "" {
-		if toolsAll {
-			agentOpts = append(agentOpts, agent.WithTools(nil)) // empty means "all tools"
-		} else {
-			agentOpts = append(agentOpts, agent.WithTools(toolsOverride))
-		}
+		return withExitCode(exitConfigError, err)
 	}
-
 	agentInstance := agent.New(llmClient, agentOpts...)
 
 	// If verbose, show the enhanced system prompt (including tools)
@@ -707,7 +986,7 @@ func runQuery(cmd *cobra.Command, args []string) error {
 			}
 		} else {
 			fmt.Println("\n=== DEFAULT SYSTEM PROMPT ===")
-			fmt.Println(agent.DefaultConfig().SystemPrompt)
+			fmt.Println(baseSystemPrompt)
 			fmt.Println("\n=== AVAILABLE TOOLS ===")
 			toolNames := registry.List()
 			for _, name := range toolNames {
@@ -735,7 +1014,15 @@ func runQuery(cmd *cobra.Command, args []string) error {
 		})
 		runlog.EventFromContext(ctx, "run_start", nil)
 	}
-	response, err := agentInstance.Query(ctx, query)
+	var response *agent.Response
+	if queryStream {
+		response, err = streamQuery(ctx, agentInstance, query, os.Stdout, os.Stderr)
+		if err == nil {
+			priceStreamedResponse(response, configuredPriceTable(), model)
+		}
+	} else {
+		response, err = agentInstance.Query(ctx, query)
+	}
 	if err != nil {
 		if queryLogger != nil {
 			runlog.EventFromContext(ctx, "run_end", map[string]interface{}{
@@ -743,11 +1030,18 @@ func runQuery(cmd *cobra.Command, args []string) error {
 				"error":  err.Error(),
 			})
 		}
-		return fmt.Errorf("query failed: %w", err)
+		return withExitCode(exitLLMError, fmt.Errorf("query failed: %w", err))
 	}
 
-	// Print response
-	fmt.Println(response.Content)
+	// Print response (already written incrementally when streaming)
+	if !queryStream {
+		if err := writeQueryOutput(os.Stdout, format, response, model); err != nil {
+			return err
+		}
+	}
+	if response.Truncated {
+		fmt.Fprintf(os.Stderr, "(stopped after %d tool iterations)\n", response.Iterations)
+	}
 
 	if queryLogger != nil {
 		fields := map[string]interface{}{
@@ -761,26 +1055,90 @@ func runQuery(cmd *cobra.Command, args []string) error {
 	}
 
 	if verbose && response.Usage != nil {
-		fmt.Printf("\n[Tokens: %d]\n", response.Usage.TotalTokens)
+		fmt.Printf("\n[Tokens: %d, estimated cost: %s]\n", response.Usage.TotalTokens, formatResponseCost(response))
 	}
 
+	if toolCallsFailed(response) {
+		return withExitCode(exitToolError, fmt.Errorf("one or more tool calls failed"))
+	}
 	return nil
 }
 
+// resolveProviderModel fills in the provider and model a query runs with:
+// the given values, then .simple-agent.yaml, then the environment
+func resolveProviderModel(provider, model string, project *config.ProjectConfig) (string, string) {
+	if provider == "" {
+		provider = project.Provider
+		if model == "" {
+			model = project.Model
+		}
+	}
+	if provider == "" {
+		provider = getEnvOrDefault("DEFAULT_PROVIDER", "openai")
+	}
+	provider = canonicalProvider(provider)
+	if model == "" {
+		model = getEnvOrDefault("DEFAULT_MODEL", getDefaultModel(provider))
+	}
+	return provider, model
+}
+
+// queryAgentOptions returns the options for a one-shot agent, as set by the
+// global flags and .simple-agent.yaml; toolsRaw is a --tools value
+func queryAgentOptions(model, systemPrompt string, project *config.ProjectConfig, toolsRaw string) ([]agent.Option, error) {
+	toolsOverride, toolsAll, err := parseToolsOverride(toolsRaw)
+	if err != nil {
+		return nil, err
+	}
+	fallbackProviders, err := parseFallbackProviders(fallbacks)
+	if err != nil {
+		return nil, err
+	}
+
+	agentOpts := []agent.Option{
+		agent.WithName(agentName),
+		agent.WithModel(model),
+		agent.WithSystemPrompt(systemPrompt),
+		agent.WithMaxIterations(projectMaxIterations(project)),
+		agent.WithMaxToolCalls(1000),
+		agent.WithTemperature(0.7),
+		agent.WithLMStudioParser(strings.Contains(strings.ToLower(customParser), "lmstudio")),
+	}
+	if maxTokens > 0 {
+		agentOpts = append(agentOpts, agent.WithMaxTokens(maxTokens))
+	}
+	if timeoutMins > 0 {
+		agentOpts = append(agentOpts, agent.WithTimeout(time.Duration(timeoutMins)*time.Minute))
+	}
+	if toolsRaw != "" {
+		if toolsAll {
+			agentOpts = append(agentOpts, agent.WithTools(nil)) // empty means "all tools"
+		} else {
+			agentOpts = append(agentOpts, agent.WithTools(toolsOverride))
+		}
+	}
+	agentOpts = append(agentOpts, projectAgentOptions(project)...)
+	agentOpts = append(agentOpts, costAgentOptions(cost.NewCostEstimator(configuredPriceTable()))...)
+	agentOpts = append(agentOpts, fallbackAgentOptions(fallbackProviders)...)
+	return agentOpts, nil
+}
+
 func listTools(cmd *cobra.Command, args []string) {
 	toolNames := registry.List()
 
 	if toolsJSON {
 		sort.Strings(toolNames)
-		payload := make([]map[string]string, 0, len(toolNames))
+		payload := make([]map[string]interface{}, 0, len(toolNames))
 		for _, name := range toolNames {
 			tool, err := registry.Get(name)
 			if err != nil || tool == nil {
 				continue
 			}
-			payload = append(payload, map[string]string{
+			description, _ := registry.Description(name)
+			payload = append(payload, map[string]interface{}{
 				"name":        name,
-				"description": tool.Description(),
+				"description": description,
+				"available":   tools.IsAvailable(tool),
 			})
 		}
 		data, err := json.MarshalIndent(payload, "", "  ")
@@ -796,14 +1154,20 @@ func listTools(cmd *cobra.Command, args []string) {
 
 	// Define icons for tools
 	icons := map[string]string{
-		"calculate":      "🧮",
-		"read":           "📄",
-		"write":          "💾",
-		"edit":           "📝",
-		"directory_list": "📁",
-		"bash":           "🖥️",
-		"wikipedia":      "📚",
-		"google_search":  "🔍",
+		"calculate":       "🧮",
+		"read":            "📄",
+		"write":           "💾",
+		"edit":            "📝",
+		"directory_list":  "📁",
+		"bash":            "🖥️",
+		"wikipedia":       "📚",
+		"google_search":   "🔍",
+		"arxiv":           "🎓",
+		"sqlite_query":    "🗄️",
+		"file_structured": "📊",
+		"diff":            "🔀",
+		"feed":            "🗞️",
+		"scaffold":        "🏗️",
 	}
 
 	// Sort tools by name for consistent output
@@ -823,7 +1187,11 @@ func listTools(cmd *cobra.Command, args []string) {
 
 		// Format name with padding
 		paddedName := fmt.Sprintf("%-15s", name)
-		fmt.Printf("  %s %s - %s\n", icon, paddedName, tool.Description())
+		desc, _ := registry.Description(name)
+		if !tools.IsAvailable(tool) {
+			desc = "(not configured) " + desc
+		}
+		fmt.Printf("  %s %s - %s\n", icon, paddedName, desc)
 	}
 }
 
@@ -1035,8 +1403,152 @@ func parseToolsOverride(raw string) ([]string, bool, error) {
 	return out, false, nil
 }
 
+// parseFallbackProviders splits --fallback-providers into canonical provider
+// names, rejecting unknown ones up front rather than at the first failure.
+func parseFallbackProviders(raw string) ([]string, error) {
+	known := make(map[string]struct{})
+	for _, name := range allProviderNames() {
+		known[name] = struct{}{}
+	}
+
+	var providers []string
+	for _, part := range strings.Split(raw, ",") {
+		name := canonicalProvider(part)
+		if name == "" {
+			continue
+		}
+		if _, ok := known[name]; !ok {
+			return nil, fmt.Errorf("unknown provider %q in --fallback-providers (available: %s)", name, strings.Join(allProviderNames(), ", "))
+		}
+		providers = append(providers, name)
+	}
+	return providers, nil
+}
+
+// fallbackAgentOptions builds each fallback provider's client with its
+// default model.
+func fallbackAgentOptions(providers []string) []agent.Option {
+	if len(providers) == 0 {
+		return nil
+	}
+	return []agent.Option{
+		agent.WithClientFactory(func(provider string) (llm.Client, error) {
+			return createLLMClient(provider, getDefaultModel(provider))
+		}),
+		agent.WithFallbackProviders(providers),
+	}
+}
+
+// costAgentOptions tracks spending with tracker and applies --max-cost.
+func costAgentOptions(tracker *cost.CostEstimator) []agent.Option {
+	return []agent.Option{
+		agent.WithCostTracker(tracker),
+		agent.WithMaxCost(maxCost),
+	}
+}
+
+// configuredPriceTable is the default price table with the config file's
+// pricing entries, converted from per-1K to per-million-token rates, on top.
+// An unreadable config leaves the defaults.
+func configuredPriceTable() cost.PriceTable {
+	prices := cost.DefaultPriceTable()
+	path, err := config.Path()
+	if err != nil {
+		return prices
+	}
+	cfg, err := config.LoadFile(path)
+	if err != nil || len(cfg.Pricing) == 0 {
+		return prices
+	}
+	overrides := make(cost.PriceTable, len(cfg.Pricing))
+	for model, price := range cfg.Pricing {
+		overrides[model] = cost.Price{InputPerMTok: price.InputPer1K * 1000, OutputPerMTok: price.OutputPer1K * 1000}
+	}
+	return prices.WithOverrides(overrides)
+}
+
+// priceStreamedResponse sets the estimated cost Query would have reported,
+// which streamed runs only carry as usage
+func priceStreamedResponse(response *agent.Response, prices cost.PriceTable, model string) {
+	if response.Usage == nil {
+		return
+	}
+	if price, ok := prices.Lookup(model); ok {
+		response.EstimatedCostUSD = price.Cost(response.Usage.PromptTokens, response.Usage.CompletionTokens)
+		response.CostAvailable = true
+	}
+}
+
+func formatResponseCost(response *agent.Response) string {
+	if !response.CostAvailable {
+		return "unavailable"
+	}
+	return fmt.Sprintf("$%.4f", response.EstimatedCostUSD)
+}
+
+// providerHealthCheckTimeout bounds the startup connectivity check. It runs
+// before the TUI takes over the terminal, so it is kept short: a provider
+// slower than this to list its models gets a warning rather than holding up
+// startup.
+const providerHealthCheckTimeout = 1500 * time.Millisecond
+
+// warnUnavailableProviders health-checks clients concurrently and writes a
+// warning for each provider that fails.
+func warnUnavailableProviders(ctx context.Context, w io.Writer, clients map[string]llm.Client) {
+	ctx, cancel := context.WithTimeout(ctx, providerHealthCheckTimeout)
+	defer cancel()
+
+	names := make([]string, 0, len(clients))
+	for name := range clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, client llm.Client) {
+			defer wg.Done()
+			errs[i] = llm.HealthCheck(ctx, client)
+		}(i, clients[name])
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		switch {
+		case err == nil:
+		case errors.Is(err, context.DeadlineExceeded):
+			fmt.Fprintf(w, "Warning: provider %s did not answer a health check within %v\n", names[i], providerHealthCheckTimeout)
+		default:
+			fmt.Fprintf(w, "Warning: provider %s is unavailable: %v\n", names[i], err)
+		}
+	}
+}
+
 func createLLMClient(provider, model string) (llm.Client, error) {
-	clientOpts := clientOptionsForModel(model)
+	return createLLMClientWithOptions(provider, model, configuredAPIKeyOptions(canonicalProvider(provider))...)
+}
+
+// createLLMClientWithOptions builds a provider client, applying extra options
+// (such as an explicit API key) after the model defaults. The client is
+// wrapped in a circuit breaker, so a provider that keeps failing fails fast
+// (and --fallback-providers moves on) instead of timing out on every call. With
+// --request-log it is also wrapped to log its requests.
+func createLLMClientWithOptions(provider, model string, extra ...llm.ClientOption) (llm.Client, error) {
+	client, err := newProviderClient(provider, model, extra...)
+	if err != nil {
+		return nil, err
+	}
+	client = circuitbreaker.NewCircuitBreaker(client, circuitbreaker.Config{})
+	if requestLogFile != nil {
+		return middleware.NewLoggingClient(client, requestLogFile, middleware.LoggingOptions{}), nil
+	}
+	return client, nil
+}
+
+func newProviderClient(provider, model string, extra ...llm.ClientOption) (llm.Client, error) {
+	clientOpts := append(clientOptionsForModel(model), extra...)
 
 	if harnessllm.Enabled() {
 		return harnessllm.New(clientOpts...)
@@ -1070,6 +1582,12 @@ func createLLMClient(provider, model string) (llm.Client, error) {
 	case "deepseek":
 		return deepseek.NewClient(clientOpts...)
 
+	case "mistral":
+		return mistral.NewClient(clientOpts...)
+
+	case "cohere":
+		return cohere.NewClient(clientOpts...)
+
 	case "perplexity":
 		return perplexity.NewClient(clientOpts...)
 
@@ -1125,6 +1643,15 @@ func createLLMClientWithStartupFallback(provider, model string, allowFallback bo
 	return fallbackClient, fallbackProvider, fallbackModel, msg, nil
 }
 
+// providerUnavailableReason explains a provider client that could not be
+// created, naming the environment variable to set when its key is missing
+func providerUnavailableReason(provider string, err error) string {
+	if env, ok := providerAPIKeyEnv[canonicalProvider(provider)]; ok && strings.Contains(strings.ToLower(err.Error()), "api key") {
+		return env + " not set"
+	}
+	return err.Error()
+}
+
 func isLMStudioProvider(provider string) bool {
 	return canonicalProvider(provider) == "lmstudio"
 }
@@ -1143,8 +1670,10 @@ func getDefaultModel(provider string) string {
 		"openai":     "gpt-4-turbo-preview",
 		"anthropic":  "claude-3-opus-20240229",
 		"minmax":     "MiniMax-M2.5",
-		"moonshot":   "moonshot-v1-8k",
+		"moonshot":   "moonshot-v1-128k",
 		"deepseek":   "deepseek-chat",
+		"mistral":    "mistral-large-latest",
+		"cohere":     "command-r-plus",
 		"perplexity": "llama-3.1-sonar-huge-128k-online",
 		"groq":       "mixtral-8x7b-32768",
 		"lmstudio":   "local-model",
@@ -1172,7 +1701,7 @@ func canonicalProvider(provider string) string {
 }
 
 func allProviderNames() []string {
-	base := []string{"openai", "anthropic", "minmax", "moonshot", "deepseek", "perplexity", "groq", "lmstudio", "ollama"}
+	base := []string{"openai", "anthropic", "minmax", "moonshot", "deepseek", "mistral", "cohere", "perplexity", "groq", "lmstudio", "ollama"}
 	seen := make(map[string]struct{}, len(base))
 	for _, name := range base {
 		seen[name] = struct{}{}
@@ -1205,6 +1734,9 @@ func clientOptionsForModel(model string) []llm.ClientOption {
 	if timeout > 0 {
 		opts = append(opts, llm.WithTimeout(timeout))
 	}
+	if imageMaxDim > 0 {
+		opts = append(opts, llm.WithImageMaxDimension(imageMaxDim))
+	}
 	return opts
 }
 
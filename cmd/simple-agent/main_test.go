@@ -1,8 +1,16 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/llm"
 )
 
 func TestNormalizeResumeArgs(t *testing.T) {
@@ -42,3 +50,92 @@ func TestNormalizeResumeArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveWorkdir(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.txt")
+	if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	got, err := resolveWorkdir(dir)
+	if err != nil || got != dir {
+		t.Fatalf("resolveWorkdir(%q) = %q, %v", dir, got, err)
+	}
+	if _, err := resolveWorkdir(file); err == nil {
+		t.Fatalf("expected an error for a file")
+	}
+	if _, err := resolveWorkdir(filepath.Join(dir, "missing")); err == nil {
+		t.Fatalf("expected an error for a missing directory")
+	}
+}
+
+func TestParseFallbackProviders(t *testing.T) {
+	got, err := parseFallbackProviders(" OpenAI, claude,,ollama ")
+	if err != nil {
+		t.Fatalf("parseFallbackProviders: %v", err)
+	}
+	if want := []string{"openai", "anthropic", "ollama"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	if got, err := parseFallbackProviders(""); err != nil || len(got) != 0 {
+		t.Fatalf("expected no providers for an empty flag, got %v, %v", got, err)
+	}
+	if _, err := parseFallbackProviders("openai,nope"); err == nil {
+		t.Fatalf("expected an error for an unknown provider")
+	}
+}
+
+// healthClient reports err from HealthCheck
+type healthClient struct {
+	llm.Client
+	err error
+}
+
+func (c healthClient) HealthCheck(context.Context) error { return c.err }
+
+func TestWarnUnavailableProviders(t *testing.T) {
+	var out strings.Builder
+	warnUnavailableProviders(context.Background(), &out, map[string]llm.Client{
+		"openai":    healthClient{},
+		"anthropic": healthClient{err: errors.New("status 401: invalid x-api-key")},
+	})
+
+	got := out.String()
+	if got != "Warning: provider anthropic is unavailable: status 401: invalid x-api-key\n" {
+		t.Fatalf("unexpected warnings %q", got)
+	}
+}
+
+// slowHealthClient does not answer until its context is done
+type slowHealthClient struct {
+	llm.Client
+}
+
+func (slowHealthClient) HealthCheck(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestWarnUnavailableProvidersDoesNotWaitForSlowProviders(t *testing.T) {
+	var out strings.Builder
+	start := time.Now()
+	warnUnavailableProviders(context.Background(), &out, map[string]llm.Client{"ollama": slowHealthClient{}})
+
+	if elapsed := time.Since(start); elapsed > 2*providerHealthCheckTimeout {
+		t.Fatalf("health check held up startup for %v", elapsed)
+	}
+	if got := out.String(); !strings.Contains(got, "provider ollama did not answer a health check within") {
+		t.Fatalf("unexpected warnings %q", got)
+	}
+}
+
+func TestProviderUnavailableReason(t *testing.T) {
+	if got := providerUnavailableReason("OpenAI", errors.New("OpenAI API key not provided")); got != "OPENAI_API_KEY not set" {
+		t.Fatalf("expected the missing key's variable, got %q", got)
+	}
+	if got := providerUnavailableReason("lmstudio", errors.New("connection refused")); got != "connection refused" {
+		t.Fatalf("expected the error itself, got %q", got)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nachoal/simple-agent-go/agent"
+	"github.com/nachoal/simple-agent-go/config"
+	"github.com/spf13/cobra"
+)
+
+// defaultMaxIterations is used when .simple-agent.yaml sets no max_iterations
+const defaultMaxIterations = 1000
+
+var configForce bool
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Configuration commands",
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create a .simple-agent.yaml template in the current directory",
+	Long: `Create a .simple-agent.yaml file in the current directory with every setting
+commented out. Settings in it override ~/.simple-agent/config.json for this
+directory and its subdirectories; command-line flags still take precedence.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigInit,
+}
+
+func runConfigInit(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	path := filepath.Join(cwd, config.ProjectFileName)
+	if _, err := os.Stat(path); err == nil && !configForce {
+		return fmt.Errorf("%s already exists (use --force to overwrite it)", path)
+	}
+	if err := os.WriteFile(path, []byte(config.ProjectTemplate), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Created %s\n", path)
+	return nil
+}
+
+// projectSystemPrompt returns --system, or the project's system_prompt when
+// the flag is unset
+func projectSystemPrompt(flag string, project *config.ProjectConfig) string {
+	if strings.TrimSpace(flag) != "" {
+		return flag
+	}
+	return project.SystemPrompt
+}
+
+// projectToolsFlag returns --tools, or the project's enabled tools when the
+// flag is unset
+func projectToolsFlag(flag string, project *config.ProjectConfig) string {
+	if raw := strings.TrimSpace(flag); raw != "" {
+		return raw
+	}
+	return strings.Join(project.Tools.Enabled, ",")
+}
+
+func projectMaxIterations(project *config.ProjectConfig) int {
+	if project.MaxIterations > 0 {
+		return project.MaxIterations
+	}
+	return defaultMaxIterations
+}
+
+// projectAgentOptions blocks the tools the project disables
+func projectAgentOptions(project *config.ProjectConfig) []agent.Option {
+	if len(project.Tools.Disabled) == 0 {
+		return nil
+	}
+	disabled := make([]string, 0, len(project.Tools.Disabled))
+	for _, name := range project.Tools.Disabled {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			disabled = append(disabled, name)
+		}
+	}
+	return []agent.Option{agent.WithBlockedTools(disabled)}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/config"
+)
+
+func TestConfigInitWritesTemplateOnce(t *testing.T) {
+	newSessionsTestHome(t)
+	t.Cleanup(func() { configForce = false })
+
+	out := runSessionsCommand(t, "config", "init")
+	cwd, _ := os.Getwd()
+	path := filepath.Join(cwd, config.ProjectFileName)
+	if !strings.Contains(out, path) {
+		t.Fatalf("expected the created path in output, got %q", out)
+	}
+	cfg, err := config.LoadProjectFile(path)
+	if err != nil {
+		t.Fatalf("template does not parse: %v", err)
+	}
+	if cfg.Provider != "" || len(cfg.Tools.Disabled) != 0 {
+		t.Fatalf("expected every setting commented out, got %+v", cfg)
+	}
+
+	rootCmd.SetArgs([]string{"config", "init"})
+	t.Cleanup(func() { rootCmd.SetArgs(nil) })
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected an error for an existing file, got %v", err)
+	}
+}
+
+func TestProjectSettingsFillUnsetFlags(t *testing.T) {
+	project := &config.ProjectConfig{
+		SystemPrompt: "Be brief.",
+		Tools:        config.ProjectTools{Enabled: []string{"read", "grep"}, Disabled: []string{" Bash "}},
+	}
+	if got := projectSystemPrompt("", project); got != "Be brief." {
+		t.Fatalf("unexpected prompt %q", got)
+	}
+	if got := projectSystemPrompt("flag", project); got != "flag" {
+		t.Fatalf("expected --system to win, got %q", got)
+	}
+	if got := projectToolsFlag("", project); got != "read,grep" {
+		t.Fatalf("unexpected tools %q", got)
+	}
+	if got := projectToolsFlag("bash", project); got != "bash" {
+		t.Fatalf("expected --tools to win, got %q", got)
+	}
+	if got := projectMaxIterations(project); got != defaultMaxIterations {
+		t.Fatalf("unexpected max iterations %d", got)
+	}
+	if len(projectAgentOptions(project)) != 1 || projectAgentOptions(&config.ProjectConfig{}) != nil {
+		t.Fatal("expected a blocked-tools option only when tools are disabled")
+	}
+}
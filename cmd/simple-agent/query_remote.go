@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/nachoal/simple-agent-go/agent"
+	"github.com/nachoal/simple-agent-go/internal/agentrpc"
+	"github.com/nachoal/simple-agent-go/proto/agentpb"
+)
+
+// runRemoteQuery sends a one-shot query to a "simple-agent serve --grpc"
+// server and prints the answer as runQuery would. Provider, model, and tools
+// are sent only when set by flags, so the server's defaults apply otherwise.
+func runRemoteQuery(ctx context.Context, endpoint, query, format string) error {
+	req := &agentpb.QueryRequest{
+		Prompt:    query,
+		Provider:  provider,
+		Model:     model,
+		SessionId: grpcSession,
+	}
+	if toolsFlag != "" {
+		names, all, err := parseToolsOverride(toolsFlag)
+		if err != nil {
+			return withExitCode(exitConfigError, err)
+		}
+		if all {
+			names = []string{"all"}
+		}
+		req.Tools = names
+	}
+
+	conn, client, err := agentrpc.Dial(endpoint, os.Getenv(agentrpc.TokenEnv))
+	if err != nil {
+		return withExitCode(exitConfigError, fmt.Errorf("failed to connect to %s: %w", endpoint, err))
+	}
+	defer conn.Close()
+
+	var response *agent.Response
+	if queryStream {
+		var events <-chan agent.StreamEvent
+		events, err = agentrpc.StreamEvents(ctx, client, req)
+		if err == nil {
+			response, _, err = printStreamEvents(events, os.Stdout, os.Stderr)
+		}
+	} else {
+		var resp *agentpb.QueryResponse
+		resp, err = client.Query(ctx, req)
+		if err == nil {
+			response = agentrpc.ResponseFromProto(resp)
+		}
+	}
+	if err != nil {
+		return withExitCode(exitLLMError, fmt.Errorf("remote query failed: %w", err))
+	}
+
+	if !queryStream {
+		if err := writeQueryOutput(os.Stdout, format, response, model); err != nil {
+			return err
+		}
+	}
+	if response.Truncated {
+		fmt.Fprintf(os.Stderr, "(stopped after %d tool iterations)\n", response.Iterations)
+	}
+	if toolCallsFailed(response) {
+		return withExitCode(exitToolError, fmt.Errorf("one or more tool calls failed"))
+	}
+	return nil
+}
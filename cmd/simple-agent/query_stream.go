@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nachoal/simple-agent-go/agent"
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// streamQuery runs a one-shot query through QueryStream, writing assistant
+// text to out as it arrives and tool activity to errOut. If the provider
+// cannot stream, it warns on errOut and falls back to a blocking Query.
+func streamQuery(ctx context.Context, a agent.Agent, query string, out, errOut io.Writer) (*agent.Response, error) {
+	originalMemory := a.GetMemory()
+	events, err := a.QueryStream(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	response, wroteOutput, streamErr := printStreamEvents(events, out, errOut)
+	response.AgentName = a.Name()
+	if streamErr != nil {
+		if errors.Is(streamErr, llm.ErrStreamingNotSupported) && !wroteOutput {
+			fmt.Fprintf(errOut, "Warning: %v; waiting for the full response\n", streamErr)
+			a.SetMemory(originalMemory)
+			response, err := a.Query(ctx, query)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintln(out, response.Content)
+			return response, nil
+		}
+		return nil, streamErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// printStreamEvents writes a run's events as they arrive, assistant text to
+// out and tool activity to errOut, and collects them into a response. It
+// reports whether anything was written and the run's first error.
+func printStreamEvents(events <-chan agent.StreamEvent, out, errOut io.Writer) (*agent.Response, bool, error) {
+	response := &agent.Response{}
+	var streamErr error
+	wroteOutput := false
+	atLineStart := true
+	// Text of the current turn, for streams without message_end events
+	var turnText strings.Builder
+	sawMessageEnd := false
+	for event := range events {
+		switch event.Type {
+		case agent.EventTypeMessage:
+			if event.Content == "" {
+				continue
+			}
+			fmt.Fprint(out, event.Content)
+			turnText.WriteString(event.Content)
+			wroteOutput = true
+			atLineStart = strings.HasSuffix(event.Content, "\n")
+		case agent.EventTypeMessageEnd:
+			if !atLineStart {
+				fmt.Fprintln(out)
+				atLineStart = true
+			}
+			sawMessageEnd = true
+			if event.Message != nil && event.Message.Content != nil {
+				response.Content = *event.Message.Content
+			}
+		case agent.EventTypeToolStart:
+			if event.Tool != nil {
+				wroteOutput = true
+				fmt.Fprintf(errOut, "[tool] %s %s\n", event.Tool.Name, event.Tool.ArgsRaw)
+			}
+			if !atLineStart {
+				fmt.Fprintln(out)
+				atLineStart = true
+			}
+			turnText.Reset()
+		case agent.EventTypeToolResult:
+			if event.Tool == nil {
+				continue
+			}
+			response.ToolCalls = append(response.ToolCalls, agent.ToolResult{
+				ID:     event.Tool.ID,
+				Name:   event.Tool.Name,
+				Result: event.Tool.Result,
+				Error:  event.Tool.Error,
+			})
+			if event.Tool.Error != nil {
+				fmt.Fprintf(errOut, "[tool] %s failed: %v\n", event.Tool.Name, event.Tool.Error)
+			} else {
+				fmt.Fprintf(errOut, "[tool] %s done (%d bytes)\n", event.Tool.Name, len(event.Tool.Result))
+			}
+		case agent.EventTypeMaxIterations:
+			response.Truncated = true
+			response.Iterations = event.Iterations
+			response.Content = event.Content
+		case agent.EventTypeUsage:
+			if event.Usage != nil {
+				usage := *event.Usage
+				response.Usage = &usage
+			}
+		case agent.EventTypeComplete:
+			response.FinishReason = event.FinishReason
+		case agent.EventTypeError:
+			if streamErr == nil {
+				streamErr = event.Error
+			}
+		}
+	}
+	if !sawMessageEnd && !response.Truncated {
+		response.Content = turnText.String()
+		if !atLineStart {
+			fmt.Fprintln(out)
+		}
+	}
+	return response, wroteOutput, streamErr
+}
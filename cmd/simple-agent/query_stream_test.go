@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/agent"
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// fakeStreamAgent replays scripted stream events. When written is set, it
+// waits for the previous delta to reach the writer before sending the next.
+type fakeStreamAgent struct {
+	events   []agent.StreamEvent
+	written  chan struct{}
+	queryOut string
+	memory   []llm.Message
+	queries  int
+}
+
+func (a *fakeStreamAgent) Query(context.Context, string) (*agent.Response, error) {
+	a.queries++
+	return &agent.Response{Content: a.queryOut}, nil
+}
+
+func (a *fakeStreamAgent) QueryStream(ctx context.Context, query string) (<-chan agent.StreamEvent, error) {
+	a.memory = append(a.memory, llm.Message{Role: llm.RoleUser, Content: llm.StringPtr(query)})
+	events := make(chan agent.StreamEvent)
+	go func() {
+		defer close(events)
+		for _, event := range a.events {
+			events <- event
+			if event.Type == agent.EventTypeMessage && a.written != nil {
+				select {
+				case <-a.written:
+				case <-time.After(2 * time.Second):
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+func (a *fakeStreamAgent) Clear()                                {}
+func (a *fakeStreamAgent) GetMemory() []llm.Message              { return append([]llm.Message(nil), a.memory...) }
+func (a *fakeStreamAgent) SetSystemPrompt(string)                {}
+func (a *fakeStreamAgent) SetMemory(m []llm.Message)             { a.memory = m }
+func (a *fakeStreamAgent) SetRequestParams(agent.RequestParams)  {}
+func (a *fakeStreamAgent) GetRequestParams() agent.RequestParams { return agent.RequestParams{} }
+func (a *fakeStreamAgent) Name() string                          { return "" }
+func (a *fakeStreamAgent) QueryJSON(context.Context, string, interface{}) (*agent.Response, error) {
+	return nil, nil
+}
+
+// signalWriter records every write and signals after each one.
+type signalWriter struct {
+	writes []string
+	signal chan struct{}
+}
+
+func (w *signalWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, string(p))
+	if w.signal != nil {
+		w.signal <- struct{}{}
+	}
+	return len(p), nil
+}
+
+func TestStreamQueryWritesDeltasIncrementally(t *testing.T) {
+	written := make(chan struct{}, 1)
+	fake := &fakeStreamAgent{
+		written: written,
+		events: []agent.StreamEvent{
+			{Type: agent.EventTypeMessageStart},
+			{Type: agent.EventTypeMessage, Content: "Hello"},
+			{Type: agent.EventTypeMessage, Content: ", world"},
+			{Type: agent.EventTypeUsage, Usage: &llm.Usage{PromptTokens: 12, CompletionTokens: 3, TotalTokens: 15}},
+			{Type: agent.EventTypeMessageEnd, Message: &llm.Message{Role: llm.RoleAssistant, Content: llm.StringPtr("Hello, world")}},
+			{Type: agent.EventTypeComplete},
+		},
+	}
+	out := &signalWriter{signal: written}
+	var errOut bytes.Buffer
+
+	resp, err := streamQuery(context.Background(), fake, "hi", out, &errOut)
+	if err != nil {
+		t.Fatalf("streamQuery: %v", err)
+	}
+	if got := strings.Join(out.writes, "|"); got != "Hello|, world|\n" {
+		t.Fatalf("expected one write per delta, got %q", got)
+	}
+	if resp.Content != "Hello, world" {
+		t.Fatalf("expected final content, got %q", resp.Content)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 15 {
+		t.Fatalf("expected streamed usage on the response, got %+v", resp.Usage)
+	}
+}
+
+func TestStreamQueryReportsToolsOnStderr(t *testing.T) {
+	fake := &fakeStreamAgent{
+		events: []agent.StreamEvent{
+			{Type: agent.EventTypeMessageEnd, Message: &llm.Message{Role: llm.RoleAssistant, Content: llm.StringPtr("")}},
+			{Type: agent.EventTypeToolStart, Tool: &agent.ToolEvent{Name: "calculate", ArgsRaw: `{"expression":"2+2"}`}},
+			{Type: agent.EventTypeToolResult, Tool: &agent.ToolEvent{Name: "calculate", Result: "4"}},
+			{Type: agent.EventTypeMessage, Content: "It is 4."},
+			{Type: agent.EventTypeMessageEnd, Message: &llm.Message{Role: llm.RoleAssistant, Content: llm.StringPtr("It is 4.")}},
+			{Type: agent.EventTypeComplete},
+		},
+	}
+	var out, errOut bytes.Buffer
+
+	if _, err := streamQuery(context.Background(), fake, "2+2?", &out, &errOut); err != nil {
+		t.Fatalf("streamQuery: %v", err)
+	}
+	if out.String() != "It is 4.\n" {
+		t.Fatalf("expected only assistant text on stdout, got %q", out.String())
+	}
+	want := "[tool] calculate {\"expression\":\"2+2\"}\n[tool] calculate done (1 bytes)\n"
+	if errOut.String() != want {
+		t.Fatalf("unexpected stderr:\n%s", errOut.String())
+	}
+}
+
+func TestStreamQueryFallsBackWhenStreamingUnsupported(t *testing.T) {
+	fake := &fakeStreamAgent{
+		queryOut: "blocking answer",
+		memory:   []llm.Message{{Role: llm.RoleSystem, Content: llm.StringPtr("sys")}},
+		events: []agent.StreamEvent{
+			{Type: agent.EventTypeError, Error: fmt.Errorf("LLM stream request failed: %w", fmt.Errorf("%w for DeepSeek client", llm.ErrStreamingNotSupported))},
+		},
+	}
+	var out, errOut bytes.Buffer
+
+	resp, err := streamQuery(context.Background(), fake, "hi", &out, &errOut)
+	if err != nil {
+		t.Fatalf("streamQuery: %v", err)
+	}
+	if fake.queries != 1 || resp.Content != "blocking answer" || out.String() != "blocking answer\n" {
+		t.Fatalf("expected blocking fallback, got queries=%d resp=%+v out=%q", fake.queries, resp, out.String())
+	}
+	if !strings.Contains(errOut.String(), "Warning: ") {
+		t.Fatalf("expected fallback warning, got %q", errOut.String())
+	}
+	if len(fake.memory) != 1 {
+		t.Fatalf("expected memory restored before fallback, got %d messages", len(fake.memory))
+	}
+}
+
+func TestPrintStreamEventsWithoutMessageEnd(t *testing.T) {
+	// Remote streams carry text deltas but no message_end events
+	events := make(chan agent.StreamEvent, 6)
+	events <- agent.StreamEvent{Type: agent.EventTypeMessage, Content: "Let me check."}
+	events <- agent.StreamEvent{Type: agent.EventTypeToolStart, Tool: &agent.ToolEvent{Name: "calculate", ArgsRaw: `{}`}}
+	events <- agent.StreamEvent{Type: agent.EventTypeToolResult, Tool: &agent.ToolEvent{Name: "calculate", Result: "4"}}
+	events <- agent.StreamEvent{Type: agent.EventTypeMessage, Content: "It is "}
+	events <- agent.StreamEvent{Type: agent.EventTypeMessage, Content: "4."}
+	events <- agent.StreamEvent{Type: agent.EventTypeComplete, FinishReason: "stop"}
+	close(events)
+	var out, errOut bytes.Buffer
+
+	resp, wrote, err := printStreamEvents(events, &out, &errOut)
+	if err != nil || !wrote {
+		t.Fatalf("printStreamEvents: wrote=%v err=%v", wrote, err)
+	}
+	if out.String() != "Let me check.\nIt is 4.\n" {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+	if resp.Content != "It is 4." || resp.FinishReason != "stop" || len(resp.ToolCalls) != 1 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchReindex bool
+	searchJSON    bool
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search saved conversations for text",
+	Long: `Search every saved conversation for text, ignoring case.
+
+Matching sessions are listed most recent match first, each with a snippet of
+the message that matched. Use --reindex to rebuild the search index if results
+look stale or the index was damaged.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearch,
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	historyMgr, err := newHistoryManager()
+	if err != nil {
+		return fmt.Errorf("failed to create history manager: %w", err)
+	}
+	if searchReindex {
+		if err := historyMgr.RebuildIndex(); err != nil {
+			return fmt.Errorf("failed to rebuild search index: %w", err)
+		}
+	}
+
+	query := strings.Join(args, " ")
+	matches, err := historyMgr.SearchMatches(query)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if searchJSON {
+		return writeJSON(out, matches)
+	}
+	if len(matches) == 0 {
+		fmt.Fprintf(out, "No sessions match %q\n", query)
+		return nil
+	}
+	for i, match := range matches {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "%s  %s  %s\n", match.ID, match.MatchedAt.Local().Format("2006-01-02 15:04"), match.Title)
+		fmt.Fprintf(out, "  %s: %s\n", match.Role, match.Snippet)
+	}
+	return nil
+}
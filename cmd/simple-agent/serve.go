@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+
+	"github.com/nachoal/simple-agent-go/agent"
+	"github.com/nachoal/simple-agent-go/config"
+	"github.com/nachoal/simple-agent-go/internal/agentrpc"
+	"github.com/nachoal/simple-agent-go/internal/models"
+	"github.com/nachoal/simple-agent-go/internal/resources"
+	"github.com/nachoal/simple-agent-go/internal/runtimeprompt"
+	"github.com/nachoal/simple-agent-go/internal/selfknowledge"
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/proto/agentpb"
+)
+
+var (
+	serveGRPCAddr    string
+	serveHTTPAddr    string
+	serveHeartbeat   int
+	serveRateLimit   float64
+	serveRateBurst   int
+	serveSessionTTL  int
+	serveMaxSessions int
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the agent to other programs",
+	Long: `Serve the agent over gRPC as the AgentService in proto/agent.proto, over
+HTTP, or both.
+
+With --http, POST a QueryRequest as JSON to /v1/query for a JSON answer, or
+to /v1/stream for the run's events as server-sent events. Idle event streams
+get a ": keepalive" comment every --heartbeat seconds so proxies don't close
+them during slow tool runs.
+
+Each call runs in a fresh agent unless it names a session, with the
+session_id request field or the "session-id" metadata header; calls in the
+same session share one conversation. Provider, model, and tools come from the
+usual flags and .simple-agent.yaml, and a call may override them. Calls can
+only pick tools from the server's set: --tools, or ` + defaultServeTools + `
+when --tools is not set.
+
+Set ` + agentrpc.TokenEnv + ` to require "authorization: Bearer <token>" on
+every call ("Authorization: Bearer <token>" over HTTP). Without it the server
+only listens on loopback addresses. Connections are not encrypted.`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVar(&serveGRPCAddr, "grpc", "", "Address to serve gRPC on (e.g. :50051)")
+	serveCmd.Flags().StringVar(&serveHTTPAddr, "http", "", "Address to serve HTTP and server-sent events on (e.g. :8080)")
+	serveCmd.Flags().IntVar(&serveHeartbeat, "heartbeat", int(llm.DefaultSSEHeartbeat/time.Second), "Seconds an HTTP event stream may be idle before a keepalive comment is sent")
+	serveCmd.Flags().Float64Var(&serveRateLimit, "rate-limit", 2, "Calls per second allowed from each client address (0 = no limit)")
+	serveCmd.Flags().IntVar(&serveRateBurst, "rate-burst", 10, "Calls a client may make at once before --rate-limit applies")
+	serveCmd.Flags().IntVar(&serveSessionTTL, "session-ttl", 30, "Minutes an idle session is kept")
+	serveCmd.Flags().IntVar(&serveMaxSessions, "max-sessions", agentrpc.DefaultMaxSessions, "Sessions kept at once; the least recently used idle one is dropped to make room")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	defer flushToolStats()
+
+	if serveGRPCAddr == "" && serveHTTPAddr == "" {
+		return withExitCode(exitConfigError, fmt.Errorf("--grpc or --http is required (e.g. --grpc :50051)"))
+	}
+	if serveHeartbeat <= 0 {
+		return withExitCode(exitConfigError, fmt.Errorf("--heartbeat must be positive"))
+	}
+	if serveRateLimit < 0 {
+		return withExitCode(exitConfigError, fmt.Errorf("--rate-limit must not be negative"))
+	}
+	if serveSessionTTL <= 0 {
+		return withExitCode(exitConfigError, fmt.Errorf("--session-ttl must be positive"))
+	}
+	if serveMaxSessions <= 0 {
+		return withExitCode(exitConfigError, fmt.Errorf("--max-sessions must be positive"))
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	project, _, err := config.LoadProject(cwd)
+	if err != nil {
+		return withExitCode(exitConfigError, err)
+	}
+	baseSystemPrompt, err := resolveBaseSystemPrompt(projectSystemPrompt(systemPrompt, project))
+	if err != nil {
+		return withExitCode(exitConfigError, err)
+	}
+	serverTools := projectToolsFlag(toolsFlag, project)
+	if _, _, err := parseToolsOverride(serverTools); err != nil {
+		return withExitCode(exitConfigError, err)
+	}
+	if _, err := parseFallbackProviders(fallbacks); err != nil {
+		return withExitCode(exitConfigError, err)
+	}
+
+	resourceLoader, err := resources.NewLoader(cwd, "")
+	if err != nil {
+		return fmt.Errorf("failed to initialize resource loader: %w", err)
+	}
+	selfInfo := selfknowledge.Discover(cwd)
+
+	modelsPath, err := models.DefaultModelsPath()
+	if err != nil {
+		return fmt.Errorf("failed to resolve models config path: %w", err)
+	}
+	customModelRegistry = models.NewRegistry(modelsPath)
+	if err := customModelRegistry.Reload(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	factory := func(ctx context.Context, req *agentpb.QueryRequest) (agent.Agent, func(), error) {
+		reqProvider, reqModel := provider, model
+		if req.GetProvider() != "" {
+			reqProvider, reqModel = req.GetProvider(), ""
+		}
+		if req.GetModel() != "" {
+			reqModel = req.GetModel()
+		}
+		reqProvider, reqModel = resolveProviderModel(reqProvider, reqModel, project)
+
+		toolsRaw, err := requestTools(req.GetTools(), serverTools)
+		if err != nil {
+			return nil, nil, err
+		}
+		systemPrompt := runtimeprompt.Build(baseSystemPrompt, cwd, selfInfo, resourceLoader.Snapshot())
+		agentOpts, err := queryAgentOptions(reqModel, systemPrompt, project, toolsRaw)
+		if err != nil {
+			return nil, nil, err
+		}
+		llmClient, err := createLLMClient(reqProvider, reqModel)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create LLM client: %w", err)
+		}
+		return agent.New(llmClient, agentOpts...), func() { llmClient.Close() }, nil
+	}
+
+	token := os.Getenv(agentrpc.TokenEnv)
+	if token == "" {
+		for _, addr := range []string{serveGRPCAddr, serveHTTPAddr} {
+			if addr != "" && !isLoopbackAddr(addr) {
+				return withExitCode(exitConfigError, fmt.Errorf("%s is not set; set it or listen on a loopback address (e.g. 127.0.0.1%s) instead of %s", agentrpc.TokenEnv, portOf(addr), addr))
+			}
+		}
+	}
+	var limiter *agentrpc.RateLimiter
+	if serveRateLimit > 0 {
+		limiter = agentrpc.NewRateLimiter(serveRateLimit, serveRateBurst)
+	}
+
+	server := agentrpc.NewServer(factory,
+		agentrpc.WithSessionTTL(time.Duration(serveSessionTTL)*time.Minute),
+		agentrpc.WithMaxSessions(serveMaxSessions),
+	)
+	defer server.Close()
+
+	var grpcLis, httpLis net.Listener
+	if serveGRPCAddr != "" {
+		if grpcLis, err = net.Listen("tcp", serveGRPCAddr); err != nil {
+			return withExitCode(exitConfigError, fmt.Errorf("failed to listen on %s: %w", serveGRPCAddr, err))
+		}
+	}
+	if serveHTTPAddr != "" {
+		if httpLis, err = net.Listen("tcp", serveHTTPAddr); err != nil {
+			if grpcLis != nil {
+				grpcLis.Close()
+			}
+			return withExitCode(exitConfigError, fmt.Errorf("failed to listen on %s: %w", serveHTTPAddr, err))
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errs := make(chan error, 2)
+	running := 0
+
+	if grpcLis != nil {
+		grpcServer := agentrpc.NewGRPCServer(server, token, limiter)
+		go func() {
+			<-ctx.Done()
+			grpcServer.GracefulStop()
+		}()
+		go func() {
+			err := grpcServer.Serve(grpcLis)
+			if errors.Is(err, grpc.ErrServerStopped) {
+				err = nil
+			}
+			errs <- err
+		}()
+		running++
+		fmt.Fprintf(os.Stderr, "Serving AgentService over gRPC on %s\n", grpcLis.Addr())
+	}
+	if httpLis != nil {
+		httpServer := &http.Server{
+			Handler:           agentrpc.NewHTTPHandler(server, token, limiter, agentrpc.WithHeartbeat(time.Duration(serveHeartbeat)*time.Second)),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			<-ctx.Done()
+			// Give running streams a moment to finish, then cut them off
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := httpServer.Shutdown(shutdownCtx); err != nil {
+				httpServer.Close()
+			}
+		}()
+		go func() {
+			err := httpServer.Serve(httpLis)
+			if errors.Is(err, http.ErrServerClosed) {
+				err = nil
+			}
+			errs <- err
+		}()
+		running++
+		fmt.Fprintf(os.Stderr, "Serving AgentService over HTTP on %s\n", httpLis.Addr())
+	}
+
+	// When one server fails, stop the other too
+	var firstErr error
+	for ; running > 0; running-- {
+		if err := <-errs; err != nil && firstErr == nil {
+			firstErr = err
+			stop()
+		}
+	}
+	return firstErr
+}
+
+// defaultServeTools are the tools remote calls get when the server was
+// started without --tools. They only read the workspace, so a caller can't
+// run commands or change files unless the operator opts in.
+const defaultServeTools = "read,directory_list,diff,file_structured,calculate"
+
+// isLoopbackAddr reports whether a listen address only accepts connections
+// from this machine. An empty host listens on every interface.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || host == "" {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// portOf returns the ":port" part of a listen address, or "" if it has none.
+func portOf(addr string) string {
+	if _, port, err := net.SplitHostPort(addr); err == nil {
+		return ":" + port
+	}
+	return ""
+}
+
+// requestTools returns the --tools value for a call. With no tools asked
+// for, the server's set is used; otherwise a call may only narrow that set.
+// A server started without --tools uses defaultServeTools, and one started
+// with --tools all lets calls pick any tool.
+func requestTools(requested []string, serverTools string) (string, error) {
+	if serverTools == "" {
+		serverTools = defaultServeTools
+	}
+	if len(requested) == 0 {
+		return serverTools, nil
+	}
+	raw := strings.Join(requested, ",")
+	names, all, err := parseToolsOverride(raw)
+	if err != nil {
+		return "", err
+	}
+	allowed, serverAll, _ := parseToolsOverride(serverTools)
+	if serverAll {
+		return raw, nil
+	}
+	if all {
+		return serverTools, nil
+	}
+	for _, name := range names {
+		if !slices.Contains(allowed, name) {
+			return "", fmt.Errorf("tool %q is not enabled on this server", name)
+		}
+	}
+	return raw, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import "testing"
+
+func TestRequestTools(t *testing.T) {
+	cases := []struct {
+		name      string
+		requested []string
+		server    string
+		want      string
+		wantErr   bool
+	}{
+		{name: "server default", server: "", want: defaultServeTools},
+		{name: "server list", server: "read,write", want: "read,write"},
+		{name: "default set when server has no --tools", requested: []string{"read"}, server: "", want: "read"},
+		{name: "outside default set", requested: []string{"bash"}, server: "", wantErr: true},
+		{name: "all means the default set", requested: []string{"all"}, server: "", want: defaultServeTools},
+		{name: "any tool when server allows all", requested: []string{"bash"}, server: "all", want: "bash"},
+		{name: "subset of server list", requested: []string{"read"}, server: "read,write", want: "read"},
+		{name: "all means the server list", requested: []string{"all"}, server: "read,write", want: "read,write"},
+		{name: "outside server list", requested: []string{"bash"}, server: "read,write", wantErr: true},
+		{name: "unknown tool", requested: []string{"nope"}, server: "", wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := requestTools(tc.requested, tc.server)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("requestTools: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsLoopbackAddr(t *testing.T) {
+	cases := map[string]bool{
+		"127.0.0.1:50051": true,
+		"localhost:8080":  true,
+		"[::1]:50051":     true,
+		":50051":          false,
+		"0.0.0.0:50051":   false,
+		"[::]:8080":       false,
+		"10.0.0.5:50051":  false,
+		"agent-host:8080": false,
+		"50051":           false,
+	}
+	for addr, want := range cases {
+		if got := isLoopbackAddr(addr); got != want {
+			t.Errorf("isLoopbackAddr(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nachoal/simple-agent-go/config"
+	"github.com/nachoal/simple-agent-go/history"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// sessionKeyEnv holds the session encryption passphrase, overriding the
+// config file's encryption.key
+const sessionKeyEnv = "SIMPLE_AGENT_SESSION_KEY"
+
+var encryptSessions bool
+
+var encryptSessionsCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt every saved session with the session encryption key",
+	Long: `Encrypt every saved session with the session encryption key: ` + sessionKeyEnv + `,
+then encryption.key in ~/.simple-agent/config.json, or a passphrase typed at
+the prompt. Sessions already encrypted must use the same key.`,
+	Args: cobra.NoArgs,
+	RunE: runEncryptSessions,
+}
+
+var decryptSessionsCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Decrypt every saved session back to plain JSON",
+	Long: `Decrypt every saved session back to plain JSON, using the key that encrypted
+them (see "simple-agent sessions encrypt --help"). Remove encryption.key from
+the config afterwards, or new sessions will be saved encrypted again.`,
+	Args: cobra.NoArgs,
+	RunE: runDecryptSessions,
+}
+
+// newHistoryManager opens the session store, encrypting it when
+// --encrypt-sessions is passed or a key is configured
+func newHistoryManager() (*history.Manager, error) {
+	historyMgr, err := history.NewManager()
+	if err != nil {
+		return nil, err
+	}
+	key, err := sessionEncryptionKey(encryptSessions)
+	if err != nil {
+		return nil, err
+	}
+	historyMgr.SetEncryptionKey(key)
+	return historyMgr, nil
+}
+
+// sessionEncryptionKey returns the passphrase from SIMPLE_AGENT_SESSION_KEY
+// or the config file. Without either it asks for one when prompt is set and
+// returns nil otherwise.
+func sessionEncryptionKey(prompt bool) ([]byte, error) {
+	if key := os.Getenv(sessionKeyEnv); key != "" {
+		return []byte(key), nil
+	}
+	if path, err := config.Path(); err == nil {
+		cfg, err := config.LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Encryption != nil && cfg.Encryption.Key != "" {
+			return []byte(cfg.Encryption.Key), nil
+		}
+	}
+	if !prompt {
+		return nil, nil
+	}
+	if !stdinIsTerminal() {
+		return nil, fmt.Errorf("no session encryption key: set %s or encryption.key in ~/.simple-agent/config.json", sessionKeyEnv)
+	}
+	return promptPassphrase(os.Stderr, "Session encryption passphrase: ")
+}
+
+// promptPassphrase reads a passphrase from the terminal without echoing it
+func promptPassphrase(out io.Writer, label string) ([]byte, error) {
+	fmt.Fprint(out, label)
+	key, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	if len(key) == 0 {
+		return nil, errors.New("the passphrase is empty")
+	}
+	return key, nil
+}
+
+func runEncryptSessions(cmd *cobra.Command, args []string) error {
+	key, err := sessionEncryptionKey(true)
+	if err != nil {
+		return err
+	}
+	historyMgr, err := history.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to create history manager: %w", err)
+	}
+	if err := historyMgr.ChangeEncryptionKey(key, key); err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), "Sessions encrypted.")
+	return nil
+}
+
+func runDecryptSessions(cmd *cobra.Command, args []string) error {
+	key, err := sessionEncryptionKey(true)
+	if err != nil {
+		return err
+	}
+	historyMgr, err := history.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to create history manager: %w", err)
+	}
+	if err := historyMgr.ChangeEncryptionKey(key, nil); err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), "Sessions decrypted.")
+	return nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/history"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sessionsExportFormat string
+	sessionsExportAll    bool
+	sessionsListJSON     bool
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:     "sessions",
+	Aliases: []string{"session"},
+	Short:   "Inspect, export, and encrypt saved conversation sessions",
+}
+
+var listSessionsCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List sessions for the current directory",
+	Args:  cobra.NoArgs,
+	RunE:  runListSessions,
+}
+
+var exportSessionCmd = &cobra.Command{
+	Use:   "export [session-id]",
+	Short: "Print a session as JSON (default) or Markdown",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if sessionsExportAll {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	RunE: runExportSession,
+}
+
+func runListSessions(cmd *cobra.Command, args []string) error {
+	historyMgr, err := newHistoryManager()
+	if err != nil {
+		return fmt.Errorf("failed to create history manager: %w", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	sessions, err := historyMgr.ListSessionsForPath(cwd)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if sessionsListJSON {
+		return writeJSON(out, sessions)
+	}
+	if len(sessions) == 0 {
+		fmt.Fprintf(out, "No sessions for %s\n", cwd)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tUPDATED\tMESSAGES\tMODEL\tTITLE")
+	for _, info := range sessions {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n",
+			info.ID,
+			info.UpdatedAt.Local().Format("2006-01-02 15:04"),
+			info.Messages,
+			strings.Trim(info.Provider+"/"+info.Model, "/"),
+			info.Title,
+		)
+	}
+	return w.Flush()
+}
+
+func runExportSession(cmd *cobra.Command, args []string) error {
+	format := strings.ToLower(strings.TrimSpace(sessionsExportFormat))
+	if format != "json" && format != "md" {
+		return fmt.Errorf("unsupported format %q (use json or md)", sessionsExportFormat)
+	}
+
+	historyMgr, err := newHistoryManager()
+	if err != nil {
+		return fmt.Errorf("failed to create history manager: %w", err)
+	}
+
+	var sessions []*history.Session
+	if sessionsExportAll {
+		infos, err := historyMgr.ListSessions(0)
+		if err != nil {
+			return err
+		}
+		sessions = make([]*history.Session, 0, len(infos))
+		for _, info := range infos {
+			session, err := historyMgr.LoadSession(info.ID)
+			if err != nil {
+				return fmt.Errorf("failed to load session %s: %w", info.ID, err)
+			}
+			sessions = append(sessions, session)
+		}
+	} else {
+		session, err := historyMgr.LoadSession(args[0])
+		if err != nil {
+			return fmt.Errorf("session %s not found: %w", args[0], err)
+		}
+		sessions = []*history.Session{session}
+	}
+
+	out := cmd.OutOrStdout()
+	if format == "md" {
+		for i, session := range sessions {
+			if i > 0 {
+				fmt.Fprint(out, "\n---\n\n")
+			}
+			fmt.Fprint(out, sessionMarkdown(session))
+		}
+		return nil
+	}
+	if sessionsExportAll {
+		return writeJSON(out, sessions)
+	}
+	return writeJSON(out, sessions[0])
+}
+
+func writeJSON(out io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
+
+// sessionMarkdown renders a session as plain Markdown: a metadata list
+// followed by one section per user, assistant, and tool message.
+func sessionMarkdown(session *history.Session) string {
+	var b strings.Builder
+	title := strings.TrimSpace(session.Metadata.Title)
+	if title == "" {
+		title = "Session " + session.ID
+	}
+	b.WriteString("# " + title + "\n\n")
+	b.WriteString(fmt.Sprintf("- Session: %s\n", session.ID))
+	b.WriteString(fmt.Sprintf("- Path: %s\n", session.Path))
+	b.WriteString(fmt.Sprintf("- Model: %s\n", strings.Trim(session.Provider+"/"+session.Model, "/")))
+	b.WriteString(fmt.Sprintf("- Created: %s\n", session.CreatedAt.Format(time.RFC3339)))
+	b.WriteString(fmt.Sprintf("- Updated: %s\n", session.UpdatedAt.Format(time.RFC3339)))
+
+	for _, msg := range session.Messages {
+		content := ""
+		if msg.Content != nil {
+			content = strings.TrimSpace(*msg.Content)
+		}
+
+		var header string
+		switch msg.Role {
+		case "user":
+			header = "You"
+		case "assistant":
+			header = "Assistant"
+		case "tool":
+			header = "Tool result"
+		default:
+			continue
+		}
+		if content == "" && len(msg.ToolCalls) == 0 {
+			continue
+		}
+
+		b.WriteString("\n## " + header + "\n\n")
+		if content != "" {
+			b.WriteString(content + "\n")
+		}
+		for _, call := range msg.ToolCalls {
+			b.WriteString(fmt.Sprintf("\n**Tool call:** `%s` `%s`\n", call.Function.Name, call.Function.Arguments))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/history"
+)
+
+func newSessionsTestHome(t *testing.T) (*history.Manager, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	workspace := filepath.Join(home, "project")
+	t.Chdir(t.TempDir())
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %v", err)
+	}
+
+	mgr, err := history.NewManager()
+	if err != nil {
+		t.Fatalf("history.NewManager: %v", err)
+	}
+	for _, path := range []string{cwd, workspace} {
+		session, err := mgr.StartSession(path, "openai", "gpt-4o")
+		if err != nil {
+			t.Fatalf("StartSession: %v", err)
+		}
+		question, answer := "How do I list files?", "Use ls:\n\n```sh\nls -la\n```"
+		session.Messages = []history.Message{
+			{Role: "user", Content: &question},
+			{Role: "assistant", Content: &answer},
+		}
+		if err := mgr.SaveSession(session); err != nil {
+			t.Fatalf("SaveSession: %v", err)
+		}
+	}
+	return mgr, cwd
+}
+
+func runSessionsCommand(t *testing.T, args ...string) string {
+	t.Helper()
+	sessionsExportFormat, sessionsExportAll, sessionsListJSON = "json", false, false
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs(args)
+	t.Cleanup(func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("%v: %v", args, err)
+	}
+	return out.String()
+}
+
+func TestSessionsList_ShowsCurrentPathOnly(t *testing.T) {
+	mgr, cwd := newSessionsTestHome(t)
+	infos, err := mgr.ListSessionsForPath(cwd)
+	if err != nil || len(infos) != 1 {
+		t.Fatalf("expected one session for cwd, got %v (%v)", infos, err)
+	}
+
+	out := runSessionsCommand(t, "sessions", "list", "--json")
+	var listed []history.SessionInfo
+	if err := json.Unmarshal([]byte(out), &listed); err != nil {
+		t.Fatalf("decode list output: %v\n%s", err, out)
+	}
+	if len(listed) != 1 || listed[0].ID != infos[0].ID {
+		t.Fatalf("expected only the cwd session, got %+v", listed)
+	}
+}
+
+func TestSessionsExport_JSONMarkdownAndAll(t *testing.T) {
+	mgr, cwd := newSessionsTestHome(t)
+	infos, _ := mgr.ListSessionsForPath(cwd)
+	id := infos[0].ID
+
+	var session history.Session
+	out := runSessionsCommand(t, "sessions", "export", id)
+	if err := json.Unmarshal([]byte(out), &session); err != nil || session.ID != id || len(session.Messages) != 2 {
+		t.Fatalf("unexpected JSON export (%v):\n%s", err, out)
+	}
+
+	out = runSessionsCommand(t, "sessions", "export", id, "--format", "md")
+	for _, want := range []string{"# " + infos[0].Title + "\n", "- Session: " + id, "## You\n\nHow do I list files?", "## Assistant\n\nUse ls:\n\n```sh\nls -la\n```"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in Markdown export:\n%s", want, out)
+		}
+	}
+
+	var all []history.Session
+	out = runSessionsCommand(t, "sessions", "export", "--all")
+	if err := json.Unmarshal([]byte(out), &all); err != nil || len(all) != 2 {
+		t.Fatalf("expected both sessions in --all export (%v):\n%s", err, out)
+	}
+}
+
+func TestExport_WritesToDownloadsOrOutput(t *testing.T) {
+	mgr, cwd := newSessionsTestHome(t)
+	infos, _ := mgr.ListSessionsForPath(cwd)
+	id := infos[0].ID
+	t.Cleanup(func() { exportSessionID, exportFormat, exportOutput = "", "markdown", "" })
+
+	home, _ := os.UserHomeDir()
+	downloads := filepath.Join(home, "Downloads")
+	if err := os.MkdirAll(downloads, 0755); err != nil {
+		t.Fatalf("mkdir Downloads: %v", err)
+	}
+	runSessionsCommand(t, "export", "--format", "html")
+	data, err := os.ReadFile(filepath.Join(downloads, "simple-agent-"+id+".html"))
+	if err != nil {
+		t.Fatalf("expected the cwd session in ~/Downloads: %v", err)
+	}
+	if !strings.Contains(string(data), "How do I list files?") {
+		t.Fatalf("unexpected HTML export:\n%s", data)
+	}
+
+	out := runSessionsCommand(t, "export", "--session-id", id, "--format", "markdown", "--output", "-")
+	if !strings.HasPrefix(out, "# Conversation\n") || !strings.Contains(out, "## User\n\nHow do I list files?") {
+		t.Fatalf("unexpected Markdown export:\n%s", out)
+	}
+}
+
+func TestSearch_PrintsMatchesWithSnippets(t *testing.T) {
+	newSessionsTestHome(t)
+	t.Cleanup(func() { searchReindex, searchJSON = false, false })
+
+	out := runSessionsCommand(t, "search", "LIST", "files")
+	if strings.Count(out, "user: How do I list files?") != 2 {
+		t.Fatalf("expected both sessions with a snippet, got:\n%s", out)
+	}
+
+	out = runSessionsCommand(t, "search", "--reindex", "nothing like this")
+	if !strings.Contains(out, `No sessions match "nothing like this"`) {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+}
+
+func TestSessionCommandAlias(t *testing.T) {
+	for _, want := range []string{"encrypt", "decrypt", "list", "export"} {
+		cmd, _, err := rootCmd.Find([]string{"session", want})
+		if err != nil {
+			t.Fatalf("session %s: %v", want, err)
+		}
+		if cmd.Name() != want || cmd.Parent() != sessionsCmd {
+			t.Fatalf("session %s resolved to %q", want, cmd.CommandPath())
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/config"
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/spf13/cobra"
+)
+
+const setupHealthCheckTimeout = 20 * time.Second
+
+// providerAPIKeyEnv maps built-in providers to the environment variable their
+// client reads the API key from. Local providers need no key.
+var providerAPIKeyEnv = map[string]string{
+	"openai":     "OPENAI_API_KEY",
+	"anthropic":  "ANTHROPIC_API_KEY",
+	"minmax":     "MINIMAX_API_KEY",
+	"moonshot":   "MOONSHOT_API_KEY",
+	"deepseek":   "DEEPSEEK_API_KEY",
+	"mistral":    "MISTRAL_API_KEY",
+	"cohere":     "COHERE_API_KEY",
+	"perplexity": "PERPLEXITY_API_KEY",
+	"groq":       "GROQ_API_KEY",
+}
+
+var setupProviders = []string{"openai", "anthropic", "minmax", "moonshot", "deepseek", "mistral", "cohere", "perplexity", "groq", "lmstudio", "ollama"}
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Interactively configure a provider, API key, and default model",
+	RunE:  runInit,
+}
+
+// setupWizard walks a new user through choosing a provider and storing its
+// credentials. Client construction and validation are injectable for tests.
+type setupWizard struct {
+	in            *bufio.Reader
+	out           io.Writer
+	configManager *config.Manager
+	newClient     func(provider, model, apiKey string) (llm.Client, error)
+	healthCheck   func(ctx context.Context, client llm.Client) error
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	configManager, err := config.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to create config manager: %w", err)
+	}
+
+	wizard := &setupWizard{
+		in:            bufio.NewReader(os.Stdin),
+		out:           os.Stdout,
+		configManager: configManager,
+		newClient:     createSetupClient,
+		healthCheck:   llm.HealthCheck,
+	}
+	return wizard.Run(cmd.Context())
+}
+
+// Run prompts for provider, API key, and model, validates the credentials,
+// and writes them to the config.
+func (w *setupWizard) Run(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	fmt.Fprintln(w.out, "Welcome to simple-agent! Let's configure a provider.")
+	fmt.Fprintln(w.out)
+	for i, name := range setupProviders {
+		fmt.Fprintf(w.out, "  %d) %s\n", i+1, name)
+	}
+
+	providerName, err := w.askProvider()
+	if err != nil {
+		return err
+	}
+
+	apiKey := ""
+	if envVar, ok := providerAPIKeyEnv[providerName]; ok {
+		apiKey, err = w.ask(fmt.Sprintf("API key for %s (leave blank to use $%s): ", providerName, envVar))
+		if err != nil {
+			return err
+		}
+		if apiKey == "" && os.Getenv(envVar) == "" {
+			return fmt.Errorf("no API key entered and %s is not set", envVar)
+		}
+	}
+
+	defaultModel := getDefaultModel(providerName)
+	modelName, err := w.ask(fmt.Sprintf("Default model [%s]: ", defaultModel))
+	if err != nil {
+		return err
+	}
+	if modelName == "" {
+		modelName = defaultModel
+	}
+
+	fmt.Fprintf(w.out, "Checking %s connectivity...\n", providerName)
+	if err := w.validate(ctx, providerName, modelName, apiKey); err != nil {
+		fmt.Fprintf(w.out, "Health check failed: %v\n", err)
+		answer, askErr := w.ask("Save this configuration anyway? [y/N]: ")
+		if askErr != nil {
+			return askErr
+		}
+		if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			return fmt.Errorf("setup aborted: %w", err)
+		}
+	} else {
+		fmt.Fprintln(w.out, "Health check passed.")
+	}
+
+	if apiKey != "" {
+		if err := w.configManager.SetAPIKey(providerName, apiKey); err != nil {
+			return err
+		}
+	}
+	if err := w.configManager.SetDefaults(providerName, modelName); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w.out, "Saved %s (%s) as the default. Run `simple-agent` to start chatting.\n", providerName, modelName)
+	return nil
+}
+
+func (w *setupWizard) askProvider() (string, error) {
+	for {
+		answer, err := w.ask(fmt.Sprintf("Provider [1-%d or name]: ", len(setupProviders)))
+		if err != nil {
+			return "", err
+		}
+		if n, convErr := strconv.Atoi(answer); convErr == nil && n >= 1 && n <= len(setupProviders) {
+			return setupProviders[n-1], nil
+		}
+		name := canonicalProvider(answer)
+		for _, candidate := range setupProviders {
+			if candidate == name {
+				return name, nil
+			}
+		}
+		fmt.Fprintf(w.out, "Unknown provider %q.\n", answer)
+	}
+}
+
+func (w *setupWizard) ask(prompt string) (string, error) {
+	fmt.Fprint(w.out, prompt)
+	line, err := w.in.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		if errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("setup cancelled: no more input")
+		}
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func (w *setupWizard) validate(ctx context.Context, providerName, modelName, apiKey string) error {
+	client, err := w.newClient(providerName, modelName, apiKey)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	checkCtx, cancel := context.WithTimeout(ctx, setupHealthCheckTimeout)
+	defer cancel()
+	return w.healthCheck(checkCtx, client)
+}
+
+func createSetupClient(providerName, modelName, apiKey string) (llm.Client, error) {
+	if apiKey == "" {
+		return createLLMClient(providerName, modelName)
+	}
+	return createLLMClientWithOptions(providerName, modelName, llm.WithAPIKey(apiKey))
+}
+
+// configuredAPIKeyOptions returns an API key option from the saved config
+// when the provider's environment variable is unset.
+func configuredAPIKeyOptions(providerName string) []llm.ClientOption {
+	envVar, ok := providerAPIKeyEnv[providerName]
+	if !ok || os.Getenv(envVar) != "" {
+		return nil
+	}
+	configManager, err := config.NewManager()
+	if err != nil {
+		return nil
+	}
+	if apiKey := configManager.GetAPIKey(providerName); apiKey != "" {
+		return []llm.ClientOption{llm.WithAPIKey(apiKey)}
+	}
+	return nil
+}
+
+// withSetupHint points users at `simple-agent init` when a client failed to
+// construct because no API key is configured.
+func withSetupHint(err error) error {
+	if err != nil && strings.Contains(err.Error(), "API key not provided") {
+		return fmt.Errorf("%w (run `simple-agent init` to configure a provider)", err)
+	}
+	return err
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/config"
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+type setupStubClient struct {
+	apiKey string
+}
+
+func (setupStubClient) Chat(context.Context, *llm.ChatRequest) (*llm.ChatResponse, error) {
+	return nil, nil
+}
+func (setupStubClient) ChatStream(context.Context, *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	return nil, nil
+}
+func (setupStubClient) ListModels(context.Context) ([]llm.Model, error)      { return nil, nil }
+func (setupStubClient) GetModel(context.Context, string) (*llm.Model, error) { return nil, nil }
+func (setupStubClient) Close() error                                         { return nil }
+
+func newTestSetupWizard(t *testing.T, input string, healthErr error) (*setupWizard, *config.Manager, *[]string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("ANTHROPIC_API_KEY", "")
+
+	configManager, err := config.NewManager()
+	if err != nil {
+		t.Fatalf("config.NewManager: %v", err)
+	}
+
+	var checkedKeys []string
+	wizard := &setupWizard{
+		in:            bufio.NewReader(strings.NewReader(input)),
+		out:           &bytes.Buffer{},
+		configManager: configManager,
+		newClient: func(provider, model, apiKey string) (llm.Client, error) {
+			return setupStubClient{apiKey: apiKey}, nil
+		},
+		healthCheck: func(ctx context.Context, client llm.Client) error {
+			checkedKeys = append(checkedKeys, client.(setupStubClient).apiKey)
+			return healthErr
+		},
+	}
+	return wizard, configManager, &checkedKeys
+}
+
+func TestSetupWizard_WritesValidatedConfig(t *testing.T) {
+	wizard, _, checkedKeys := newTestSetupWizard(t, "claude\nsk-ant-test\n\n", nil)
+
+	if err := wizard.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if len(*checkedKeys) != 1 || (*checkedKeys)[0] != "sk-ant-test" {
+		t.Fatalf("expected one health check with the entered key, got %v", *checkedKeys)
+	}
+
+	reloaded, err := config.NewManager()
+	if err != nil {
+		t.Fatalf("reload config: %v", err)
+	}
+	if reloaded.GetDefaultProvider() != "anthropic" {
+		t.Fatalf("expected default provider anthropic, got %q", reloaded.GetDefaultProvider())
+	}
+	if reloaded.GetDefaultModel() != getDefaultModel("anthropic") {
+		t.Fatalf("expected default model %q, got %q", getDefaultModel("anthropic"), reloaded.GetDefaultModel())
+	}
+	if reloaded.GetAPIKey("anthropic") != "sk-ant-test" {
+		t.Fatalf("expected stored API key, got %q", reloaded.GetAPIKey("anthropic"))
+	}
+
+	info, err := os.Stat(filepath.Join(os.Getenv("HOME"), ".simple-agent", "config.json"))
+	if err != nil {
+		t.Fatalf("stat config: %v", err)
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		t.Fatalf("expected config to be private, got %v", info.Mode().Perm())
+	}
+}
+
+func TestSetupWizard_FailedHealthCheckDoesNotSave(t *testing.T) {
+	wizard, configManager, _ := newTestSetupWizard(t, "2\nbad-key\ncustom-model\nn\n", errors.New("401 unauthorized"))
+
+	err := wizard.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "401 unauthorized") {
+		t.Fatalf("expected health check error, got %v", err)
+	}
+	if configManager.GetAPIKey("anthropic") != "" || configManager.GetDefaultModel() != "" {
+		t.Fatalf("expected nothing saved after failed validation")
+	}
+}
+
+func TestSetupWizard_RepromptsUnknownProvider(t *testing.T) {
+	wizard, configManager, checkedKeys := newTestSetupWizard(t, "nope\n11\nllava\n", nil)
+
+	if err := wizard.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if configManager.GetDefaultProvider() != "ollama" || configManager.GetDefaultModel() != "llava" {
+		t.Fatalf("expected ollama/llava, got %s/%s", configManager.GetDefaultProvider(), configManager.GetDefaultModel())
+	}
+	if len(*checkedKeys) != 1 || (*checkedKeys)[0] != "" {
+		t.Fatalf("expected keyless health check for local provider, got %v", *checkedKeys)
+	}
+	if !strings.Contains(wizard.out.(*bytes.Buffer).String(), `Unknown provider "nope"`) {
+		t.Fatalf("expected unknown provider message")
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/agent"
+	"github.com/nachoal/simple-agent-go/internal/resources"
+	"github.com/nachoal/simple-agent-go/internal/runtimeprompt"
+	"github.com/nachoal/simple-agent-go/internal/selfknowledge"
+)
+
+func TestResolveBaseSystemPrompt(t *testing.T) {
+	dir := t.TempDir()
+	promptFile := filepath.Join(dir, "reviewer.md")
+	if err := os.WriteFile(promptFile, []byte("You review Go code.\n"), 0644); err != nil {
+		t.Fatalf("write prompt file: %v", err)
+	}
+
+	t.Setenv("SIMPLE_AGENT_SYSTEM_PROMPT", "")
+	got, err := resolveBaseSystemPrompt(promptFile)
+	if err != nil || got != "You review Go code." {
+		t.Fatalf("expected file contents, got %q (err %v)", got, err)
+	}
+
+	got, err = resolveBaseSystemPrompt("You are terse.")
+	if err != nil || got != "You are terse." {
+		t.Fatalf("expected literal prompt, got %q (err %v)", got, err)
+	}
+
+	got, err = resolveBaseSystemPrompt(filepath.Join(dir, "missing.md"))
+	if err != nil || got != filepath.Join(dir, "missing.md") {
+		t.Fatalf("expected missing path to be used literally, got %q (err %v)", got, err)
+	}
+
+	got, err = resolveBaseSystemPrompt("")
+	if err != nil || got != agent.DefaultConfig().SystemPrompt {
+		t.Fatalf("expected default prompt, got %q (err %v)", got, err)
+	}
+
+	t.Setenv("SIMPLE_AGENT_SYSTEM_PROMPT", promptFile)
+	got, err = resolveBaseSystemPrompt("")
+	if err != nil || got != "You review Go code." {
+		t.Fatalf("expected env file contents, got %q (err %v)", got, err)
+	}
+	got, err = resolveBaseSystemPrompt("Flag wins.")
+	if err != nil || got != "Flag wins." {
+		t.Fatalf("expected flag to override env, got %q (err %v)", got, err)
+	}
+
+	empty := filepath.Join(dir, "empty.md")
+	if err := os.WriteFile(empty, []byte("  \n"), 0644); err != nil {
+		t.Fatalf("write empty file: %v", err)
+	}
+	if _, err := resolveBaseSystemPrompt(empty); err == nil {
+		t.Fatal("expected error for empty prompt file")
+	}
+}
+
+func TestResolvedSystemPromptIsAgentFirstMessage(t *testing.T) {
+	t.Setenv("SIMPLE_AGENT_SYSTEM_PROMPT", "You only answer in haiku.")
+	base, err := resolveBaseSystemPrompt("")
+	if err != nil {
+		t.Fatalf("resolve prompt: %v", err)
+	}
+	dir := t.TempDir()
+	prompt := runtimeprompt.Build(base, dir, selfknowledge.Info{}, resources.Snapshot{})
+
+	memory := agent.New(setupStubClient{}, agent.WithSystemPrompt(prompt)).GetMemory()
+	if len(memory) == 0 || memory[0].Role != "system" || memory[0].Content == nil {
+		t.Fatalf("expected system message first, got %+v", memory)
+	}
+	if !strings.HasPrefix(*memory[0].Content, "You only answer in haiku.") {
+		t.Fatalf("expected overridden prompt first, got %q", *memory[0].Content)
+	}
+}
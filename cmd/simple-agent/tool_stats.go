@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nachoal/simple-agent-go/internal/userpaths"
+	"github.com/nachoal/simple-agent-go/tools/registry"
+	"github.com/spf13/cobra"
+)
+
+const toolStatsFileName = "tool_stats.json"
+
+var toolStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show tool usage across past sessions",
+	Args:  cobra.NoArgs,
+	RunE:  runToolStats,
+}
+
+func runToolStats(cmd *cobra.Command, args []string) error {
+	path, err := toolStatsPath()
+	if err != nil {
+		return err
+	}
+	stats, err := registry.LoadStatsFile(path)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if len(stats) == 0 {
+		fmt.Fprintln(out, "No tool usage recorded yet.")
+		return nil
+	}
+	return registry.WriteStatsTable(out, stats)
+}
+
+func toolStatsPath() (string, error) {
+	configDir, err := userpaths.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, toolStatsFileName), nil
+}
+
+// persistToolStats folds the tool usage recorded during this run into
+// ~/.simple-agent/tool_stats.json.
+func persistToolStats() error {
+	stats := registry.GetStats()
+	if len(stats) == 0 {
+		return nil
+	}
+
+	path, err := toolStatsPath()
+	if err != nil {
+		return err
+	}
+	if err := registry.UpdateStatsFile(path, stats); err != nil {
+		return err
+	}
+	registry.ResetStats()
+	return nil
+}
+
+// flushToolStats persists this run's tool usage, warning on failure. Commands
+// defer it so runs that end in an error are counted too.
+func flushToolStats() {
+	if err := persistToolStats(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
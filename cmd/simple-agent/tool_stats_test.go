@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/tools"
+	"github.com/nachoal/simple-agent-go/tools/registry"
+)
+
+func TestPersistToolStatsAndStatsCommand(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	registry.ResetStats()
+	t.Cleanup(registry.ResetStats)
+
+	for i := 0; i < 2; i++ {
+		registry.ExecuteToolCall(context.Background(), tools.ToolCall{ID: "x", Name: "no_such_tool"})
+		if err := persistToolStats(); err != nil {
+			t.Fatalf("persistToolStats: %v", err)
+		}
+	}
+
+	path, err := toolStatsPath()
+	if err != nil {
+		t.Fatalf("toolStatsPath: %v", err)
+	}
+	saved, err := registry.LoadStatsFile(path)
+	if err != nil {
+		t.Fatalf("LoadStatsFile: %v", err)
+	}
+	if got := saved["no_such_tool"]; got.Calls != 2 || got.Errors != 2 {
+		t.Fatalf("expected stats accumulated across sessions, got %+v", got)
+	}
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"tools", "stats"})
+	t.Cleanup(func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("tools stats: %v", err)
+	}
+	if !strings.Contains(out.String(), "TOOL") || !strings.Contains(out.String(), "no_such_tool  2 ") {
+		t.Fatalf("unexpected stats output:\n%s", out.String())
+	}
+}
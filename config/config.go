@@ -9,35 +9,111 @@ import (
 
 // Config represents the application configuration
 type Config struct {
-	DefaultProvider string `json:"default_provider"`
-	DefaultModel    string `json:"default_model"`
+	DefaultProvider string                `json:"default_provider"`
+	DefaultModel    string                `json:"default_model"`
+	APIKeys         map[string]string     `json:"api_keys,omitempty"`
+	Shell           *ShellConfig          `json:"shell,omitempty"`
+	Theme           string                `json:"theme,omitempty"`
+	RecentModels    []RecentModel         `json:"recent_models,omitempty"`
+	Pricing         map[string]ModelPrice `json:"pricing,omitempty"`
+	Clipboard       *ClipboardConfig      `json:"clipboard,omitempty"`
+	MarkdownStyle   string                `json:"markdown_style,omitempty"`
+	AutoTitle       bool                  `json:"auto_title,omitempty"`
+	Encryption      *EncryptionConfig     `json:"encryption,omitempty"`
 }
 
-// Manager handles configuration persistence
+// MaxRecentModels is how many models the /model selector remembers
+const MaxRecentModels = 5
+
+// RecentModel is a model picked in the /model selector
+type RecentModel struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+}
+
+// ModelPrice overrides or adds a model's price, in US dollars per 1,000
+// tokens, for cost estimates
+type ModelPrice struct {
+	InputPer1K  float64 `json:"input_per_1k"`
+	OutputPer1K float64 `json:"output_per_1k"`
+}
+
+// ClipboardConfig controls the agent's access to the system clipboard
+type ClipboardConfig struct {
+	// AllowRead enables the clipboard_read tool, which is off by default
+	// because the clipboard often holds private text
+	AllowRead bool `json:"allow_read,omitempty"`
+}
+
+// EncryptionConfig controls encryption of saved sessions
+type EncryptionConfig struct {
+	// Key is the passphrase session files are encrypted with. When set,
+	// sessions are saved encrypted.
+	Key string `json:"key,omitempty"`
+}
+
+// ShellConfig configures the bash tool
+type ShellConfig struct {
+	// AllowedCommands replaces the built-in list of commands the bash tool
+	// runs without --yolo
+	AllowedCommands []string `json:"allowed_commands,omitempty"`
+	// Confirm asks the user before running commands outside AllowedCommands
+	// instead of rejecting them
+	Confirm bool `json:"confirm,omitempty"`
+}
+
+// Manager handles configuration persistence. Settings from .simple-agent.yaml
+// files in the working directory and its parents override the global config
+// but are never written back to it.
 type Manager struct {
-	configPath string
-	config     *Config
+	configPath   string
+	config       *Config
+	project      *ProjectConfig
+	projectFiles []string
+}
+
+// Path returns the location of the config file, ~/.simple-agent/config.json
+func Path() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".simple-agent", "config.json"), nil
+}
+
+// LoadFile reads the config file at path without creating anything. A
+// missing file yields an empty config.
+func LoadFile(path string) (*Config, error) {
+	cfg := &Config{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	return cfg, nil
 }
 
 // NewManager creates a new config manager
 func NewManager() (*Manager, error) {
-	// Get user's home directory
-	homeDir, err := os.UserHomeDir()
+	configPath, err := Path()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return nil, err
 	}
 
 	// Create config directory if it doesn't exist
-	configDir := filepath.Join(homeDir, ".simple-agent")
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
 		return nil, fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	configPath := filepath.Join(configDir, "config.json")
-
 	m := &Manager{
 		configPath: configPath,
 		config:     &Config{},
+		project:    &ProjectConfig{},
 	}
 
 	// Load existing config if it exists
@@ -45,6 +121,15 @@ func NewManager() (*Manager, error) {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
+	// Layer project settings from the working directory on top
+	if cwd, err := os.Getwd(); err == nil {
+		project, files, err := LoadProject(cwd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load project config: %w", err)
+		}
+		m.project, m.projectFiles = project, files
+	}
+
 	return m, nil
 }
 
@@ -69,29 +154,128 @@ func (m *Manager) Save() error {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	if err := os.WriteFile(m.configPath, data, 0644); err != nil {
+	// The config may hold API keys, so keep it private to the user.
+	if err := os.WriteFile(m.configPath, data, 0600); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
+	if err := os.Chmod(m.configPath, 0600); err != nil {
+		return fmt.Errorf("failed to restrict config permissions: %w", err)
+	}
 
 	return nil
 }
 
-// GetDefaultProvider returns the default provider
+// GetDefaultProvider returns the default provider, preferring the project's
 func (m *Manager) GetDefaultProvider() string {
+	if m.project != nil && m.project.Provider != "" {
+		return m.project.Provider
+	}
 	if m.config.DefaultProvider == "" {
 		return "openai"
 	}
 	return m.config.DefaultProvider
 }
 
-// GetDefaultModel returns the default model
+// GetDefaultModel returns the default model, preferring the project's. A
+// project that picks a provider but no model gets that provider's default
+// rather than a global model meant for another provider.
 func (m *Manager) GetDefaultModel() string {
+	if m.project != nil && (m.project.Model != "" || m.project.Provider != "") {
+		return m.project.Model
+	}
 	return m.config.DefaultModel
 }
 
+// Project returns the merged .simple-agent.yaml settings, empty when there
+// are none
+func (m *Manager) Project() *ProjectConfig {
+	if m.project == nil {
+		return &ProjectConfig{}
+	}
+	return m.project
+}
+
+// ProjectFiles returns the .simple-agent.yaml files that were loaded,
+// farthest first
+func (m *Manager) ProjectFiles() []string {
+	return m.projectFiles
+}
+
 // SetDefaults updates the default provider and model
 func (m *Manager) SetDefaults(provider, model string) error {
 	m.config.DefaultProvider = provider
 	m.config.DefaultModel = model
 	return m.Save()
 }
+
+// GetTheme returns the saved TUI theme name, or "" when none was chosen
+func (m *Manager) GetTheme() string {
+	return m.config.Theme
+}
+
+// SetTheme saves the TUI theme name
+func (m *Manager) SetTheme(name string) error {
+	m.config.Theme = name
+	return m.Save()
+}
+
+// MarkdownStyle returns the configured markdown style, or "" to follow the theme
+func (m *Manager) MarkdownStyle() string {
+	return m.config.MarkdownStyle
+}
+
+// AutoTitle reports whether sessions should be named by the model, which
+// costs one extra request per session
+func (m *Manager) AutoTitle() bool {
+	return m.config.AutoTitle
+}
+
+// EncryptionKey returns the passphrase sessions are encrypted with, or "" to
+// save them in plain text
+func (m *Manager) EncryptionKey() string {
+	if m.config.Encryption == nil {
+		return ""
+	}
+	return m.config.Encryption.Key
+}
+
+// RecentModels returns the models picked most recently, newest first
+func (m *Manager) RecentModels() []RecentModel {
+	return m.config.RecentModels
+}
+
+// AddRecentModel moves a model to the front of the recents list, dropping
+// the oldest beyond MaxRecentModels
+func (m *Manager) AddRecentModel(provider, model string) error {
+	recents := []RecentModel{{Provider: provider, Model: model}}
+	for _, recent := range m.config.RecentModels {
+		if recent.Provider == provider && recent.Model == model {
+			continue
+		}
+		if len(recents) == MaxRecentModels {
+			break
+		}
+		recents = append(recents, recent)
+	}
+	m.config.RecentModels = recents
+	return m.Save()
+}
+
+// Pricing returns the model prices set in the config file, keyed by model
+func (m *Manager) Pricing() map[string]ModelPrice {
+	return m.config.Pricing
+}
+
+// GetAPIKey returns the stored API key for a provider, if any
+func (m *Manager) GetAPIKey(provider string) string {
+	return m.config.APIKeys[provider]
+}
+
+// SetAPIKey stores the API key for a provider
+func (m *Manager) SetAPIKey(provider, apiKey string) error {
+	if m.config.APIKeys == nil {
+		m.config.APIKeys = make(map[string]string)
+	}
+	m.config.APIKeys[provider] = apiKey
+	return m.Save()
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestAddRecentModelKeepsNewestFirst(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Chdir(home)
+
+	m, err := NewManager()
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	for _, model := range []string{"a", "b", "c", "d", "e", "f", "c"} {
+		if err := m.AddRecentModel("openai", model); err != nil {
+			t.Fatalf("AddRecentModel: %v", err)
+		}
+	}
+
+	var got []string
+	for _, recent := range m.RecentModels() {
+		got = append(got, recent.Model)
+	}
+	if want := []string{"c", "f", "e", "d", "b"}; !slices.Equal(got, want) {
+		t.Fatalf("expected recents %v, got %v", want, got)
+	}
+
+	saved, err := LoadFile(filepath.Join(home, ".simple-agent", "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.RecentModels) != MaxRecentModels || saved.RecentModels[0].Model != "c" {
+		t.Fatalf("expected recents to be saved, got %+v", saved.RecentModels)
+	}
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFileName is the per-project config file, looked up in the working
+// directory and its parents
+const ProjectFileName = ".simple-agent.yaml"
+
+// ProjectConfig holds the settings a .simple-agent.yaml file can override.
+// Empty fields leave the global config or built-in default in place.
+type ProjectConfig struct {
+	Provider      string       `yaml:"provider,omitempty"`
+	Model         string       `yaml:"model,omitempty"`
+	SystemPrompt  string       `yaml:"system_prompt,omitempty"`
+	MaxIterations int          `yaml:"max_iterations,omitempty"`
+	Tools         ProjectTools `yaml:"tools,omitempty"`
+}
+
+// ProjectTools restricts the tools available in a project
+type ProjectTools struct {
+	// Enabled lists the only tools the agent may use, like --tools
+	Enabled []string `yaml:"enabled,omitempty"`
+	// Disabled lists tools the agent may never use in the project
+	Disabled []string `yaml:"disabled,omitempty"`
+}
+
+// ProjectTemplate is written by "simple-agent config init"
+const ProjectTemplate = `# Project settings for simple-agent. They override ~/.simple-agent/config.json
+# for this directory and everything below it; command-line flags still win.
+
+# provider: anthropic
+# model: claude-3-5-sonnet-20241022
+
+# Prompt text, or a path to a file relative to this one.
+# system_prompt: ./prompts/agent.md
+
+# max_iterations: 50
+
+# tools:
+#   # Only these tools are offered to the model (like --tools).
+#   enabled: [read, edit, grep]
+#   # These tools are never offered, even if enabled.
+#   disabled: [bash, write]
+`
+
+// FindProjectFiles returns the .simple-agent.yaml files that apply to dir,
+// farthest first. The search walks up from dir and stops after the home
+// directory, or at the filesystem root when dir is outside home.
+func FindProjectFiles(dir string) []string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+	home, _ := os.UserHomeDir()
+
+	var files []string
+	for {
+		path := filepath.Join(dir, ProjectFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			files = append([]string{path}, files...)
+		}
+		parent := filepath.Dir(dir)
+		if dir == home || parent == dir {
+			return files
+		}
+		dir = parent
+	}
+}
+
+// LoadProject merges the project files that apply to dir, the nearest taking
+// precedence. Disabled tools accumulate, so a nested project cannot re-enable
+// a tool its parent disabled. It also returns the files that were read.
+func LoadProject(dir string) (*ProjectConfig, []string, error) {
+	merged := &ProjectConfig{}
+	files := FindProjectFiles(dir)
+	for _, path := range files {
+		cfg, err := LoadProjectFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		merged.merge(cfg)
+	}
+	return merged, files, nil
+}
+
+// LoadProjectFile reads one .simple-agent.yaml file. A system_prompt naming a
+// file next to the config is turned into that file's path.
+func LoadProjectFile(path string) (*ProjectConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &ProjectConfig{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if cfg.MaxIterations < 0 {
+		return nil, fmt.Errorf("%s: max_iterations must not be negative", path)
+	}
+	if prompt := cfg.SystemPrompt; prompt != "" && !filepath.IsAbs(prompt) {
+		candidate := filepath.Join(filepath.Dir(path), prompt)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			cfg.SystemPrompt = candidate
+		}
+	}
+	return cfg, nil
+}
+
+func (c *ProjectConfig) merge(other *ProjectConfig) {
+	if other.Provider != "" {
+		c.Provider = other.Provider
+		// A model chosen for another provider would not work with this one.
+		c.Model = ""
+	}
+	if other.Model != "" {
+		c.Model = other.Model
+	}
+	if other.SystemPrompt != "" {
+		c.SystemPrompt = other.SystemPrompt
+	}
+	if other.MaxIterations > 0 {
+		c.MaxIterations = other.MaxIterations
+	}
+	if len(other.Tools.Enabled) > 0 {
+		c.Tools.Enabled = other.Tools.Enabled
+	}
+	c.Tools.Disabled = append(c.Tools.Disabled, other.Tools.Disabled...)
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeProjectFile(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ProjectFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadProjectMergesParentDirectories(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	repo := filepath.Join(home, "code", "repo")
+	sub := filepath.Join(repo, "service")
+
+	writeProjectFile(t, home, "provider: ollama\nmodel: llama3\n")
+	writeProjectFile(t, repo, "provider: anthropic\nsystem_prompt: prompt.md\nmax_iterations: 25\ntools:\n  disabled: [bash]\n")
+	writeProjectFile(t, sub, "model: claude-3-5-haiku-20241022\ntools:\n  enabled: [read, grep]\n  disabled: [write]\n")
+	if err := os.WriteFile(filepath.Join(repo, "prompt.md"), []byte("Be brief."), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, files, err := LoadProject(sub)
+	if err != nil {
+		t.Fatalf("LoadProject: %v", err)
+	}
+	if len(files) != 3 || files[0] != filepath.Join(home, ProjectFileName) {
+		t.Fatalf("expected three files, farthest first, got %v", files)
+	}
+	if cfg.Provider != "anthropic" || cfg.Model != "claude-3-5-haiku-20241022" {
+		t.Fatalf("expected the nearest provider and model, got %s/%s", cfg.Provider, cfg.Model)
+	}
+	if cfg.SystemPrompt != filepath.Join(repo, "prompt.md") || cfg.MaxIterations != 25 {
+		t.Fatalf("unexpected prompt %q or max iterations %d", cfg.SystemPrompt, cfg.MaxIterations)
+	}
+	if !slices.Equal(cfg.Tools.Enabled, []string{"read", "grep"}) || !slices.Equal(cfg.Tools.Disabled, []string{"bash", "write"}) {
+		t.Fatalf("unexpected tools %+v", cfg.Tools)
+	}
+
+	// A provider override drops a model inherited from a parent
+	cfg, _, err = LoadProject(repo)
+	if err != nil {
+		t.Fatalf("LoadProject: %v", err)
+	}
+	if cfg.Provider != "anthropic" || cfg.Model != "" {
+		t.Fatalf("expected the parent's model to be dropped, got %s/%s", cfg.Provider, cfg.Model)
+	}
+}
+
+func TestManagerPrefersProjectSettings(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	project := filepath.Join(home, "repo")
+	writeProjectFile(t, project, "provider: groq\n")
+	t.Chdir(project)
+
+	m, err := NewManager()
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	if err := m.SetDefaults("openai", "gpt-4o"); err != nil {
+		t.Fatalf("SetDefaults: %v", err)
+	}
+	if m.GetDefaultProvider() != "groq" || m.GetDefaultModel() != "" {
+		t.Fatalf("expected the project provider without the global model, got %s/%s", m.GetDefaultProvider(), m.GetDefaultModel())
+	}
+
+	saved, err := LoadFile(filepath.Join(home, ".simple-agent", "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.DefaultProvider != "openai" {
+		t.Fatalf("project settings leaked into the global config: %+v", saved)
+	}
+}
+
+func TestLoadProjectRejectsInvalidYAML(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeProjectFile(t, home, "tools: [not, a, map\n")
+	if _, _, err := LoadProject(home); err == nil {
+		t.Fatal("expected a parse error")
+	}
+}
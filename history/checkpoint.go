@@ -0,0 +1,36 @@
+package history
+
+import (
+	"sort"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// SetCheckpoint saves messages as the session branch called name, replacing
+// any branch of that name. The messages are copied, so later changes to the
+// conversation do not reach the checkpoint.
+func (s *Session) SetCheckpoint(name string, messages []llm.Message) {
+	if s.Branches == nil {
+		s.Branches = make(map[string][]Message)
+	}
+	s.Branches[name] = FromLLMMessages(messages)
+}
+
+// Checkpoint returns the messages of the session branch called name
+func (s *Session) Checkpoint(name string) ([]llm.Message, bool) {
+	messages, ok := s.Branches[name]
+	if !ok {
+		return nil, false
+	}
+	return ToLLMMessages(messages), true
+}
+
+// CheckpointNames returns the names of the session's branches, sorted
+func (s *Session) CheckpointNames() []string {
+	names := make([]string, 0, len(s.Branches))
+	for name := range s.Branches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
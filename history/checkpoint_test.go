@@ -0,0 +1,55 @@
+package history
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+func TestCheckpointSurvivesSaveAndLoad(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	mgr, err := NewManager()
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	session, err := mgr.StartSession("/tmp/project", "openai", "gpt-4")
+	if err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+
+	messages := []llm.Message{
+		{Role: llm.RoleUser, Content: llm.StringPtr("list the files")},
+		{Role: llm.RoleAssistant, ToolCalls: []llm.ToolCall{{ID: "1", Type: "function", Function: llm.FunctionCall{Name: "directory_list", Arguments: json.RawMessage(`{"path":"."}`)}}}},
+		{Role: llm.RoleTool, Content: llm.StringPtr("main.go"), ToolCallID: "1"},
+	}
+	session.SetCheckpoint("before-refactor", messages)
+	messages[0].Content = llm.StringPtr("changed later")
+	if err := mgr.SaveSession(session); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+
+	loaded, err := mgr.LoadSession(session.ID)
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	restored, ok := loaded.Checkpoint("before-refactor")
+	if !ok || len(restored) != 3 {
+		t.Fatalf("expected the 3-message checkpoint back, got %v %+v", ok, restored)
+	}
+	if got := llm.GetStringValue(restored[0].Content); got != "list the files" {
+		t.Fatalf("expected the checkpoint to keep the original message, got %q", got)
+	}
+	if restored[1].ToolCalls[0].Function.Name != "directory_list" || restored[2].ToolCallID != "1" {
+		t.Fatalf("expected tool calls and results to round-trip, got %+v", restored)
+	}
+	if names := loaded.CheckpointNames(); len(names) != 1 || names[0] != "before-refactor" {
+		t.Fatalf("unexpected checkpoint names %v", names)
+	}
+	if _, ok := loaded.Checkpoint("missing"); ok {
+		t.Fatal("expected no checkpoint for an unknown name")
+	}
+}
@@ -0,0 +1,286 @@
+package history
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// encryptedMagic starts every encrypted session file. The rest of the file is
+// base64 of the key derivation salt, the AES-GCM nonce, and the ciphertext.
+const encryptedMagic = "SAGENC:1:"
+
+const (
+	encryptionSaltSize = 16
+	encryptionKeySize  = 32 // AES-256
+	// scrypt cost parameters recommended for interactive use (2017): about
+	// 32 MB of memory per derivation, which makes guessing passphrases on
+	// GPUs expensive
+	encryptionScryptN = 1 << 15
+	encryptionScryptR = 8
+	encryptionScryptP = 1
+)
+
+var (
+	// ErrSessionEncrypted is returned when reading an encrypted session
+	// without an encryption key
+	ErrSessionEncrypted = errors.New("session is encrypted: set an encryption key to read it")
+	// ErrWrongEncryptionKey is returned when an encrypted session does not
+	// decrypt with the key given, or its file is damaged
+	ErrWrongEncryptionKey = errors.New("wrong encryption key or damaged session file")
+)
+
+// sessionCrypto encrypts session files with AES-256-GCM under a key derived
+// from a passphrase with scrypt. Each file records the salt its key was
+// derived with; files written by one Manager share a salt, so the slow
+// derivation runs once per salt rather than once per file.
+type sessionCrypto struct {
+	passphrase []byte
+	salt       []byte
+
+	mu   sync.Mutex
+	keys map[string][]byte // Derived keys by salt
+}
+
+// newSessionCrypto returns nil for an empty passphrase, which means session
+// files are written in plain text.
+func newSessionCrypto(passphrase []byte) *sessionCrypto {
+	if len(passphrase) == 0 {
+		return nil
+	}
+	salt := make([]byte, encryptionSaltSize)
+	rand.Read(salt)
+	return &sessionCrypto{
+		passphrase: bytes.Clone(passphrase),
+		salt:       salt,
+		keys:       make(map[string][]byte),
+	}
+}
+
+// SetEncryptionKey makes the manager encrypt the session files it saves with
+// a key derived from passphrase, and decrypt encrypted ones it loads. An
+// empty passphrase turns encryption off; files already encrypted then fail to
+// load with ErrSessionEncrypted. Encrypted sessions are left out of the
+// search index, which is stored in plain text.
+func (m *Manager) SetEncryptionKey(passphrase []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.crypto = newSessionCrypto(passphrase)
+}
+
+// ChangeEncryptionKey rewrites every session file under newKey. Encrypted
+// files are decrypted with oldKey and plain-text files are read as they are,
+// so (nil, key) or (key, key) encrypts everything and (key, nil) decrypts
+// everything. Either every file is rewritten or none is: nothing is written
+// unless every encrypted file decrypts, the new files are written under
+// temporary names before any is put in place, and files already replaced are
+// restored if a later one cannot be. The manager uses newKey from then on.
+func (m *Manager) ChangeEncryptionKey(oldKey, newKey []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids, err := m.sessionFileIDs()
+	if err != nil {
+		return err
+	}
+	old := newSessionCrypto(oldKey)
+	original := make([][]byte, len(ids))
+	plain := make([][]byte, len(ids))
+	for i, id := range ids {
+		if original[i], err = os.ReadFile(m.sessionPath(id)); err != nil {
+			return fmt.Errorf("failed to read session %s: %w", id, err)
+		}
+		if plain[i], err = decodeSessionData(original[i], old); err != nil {
+			return fmt.Errorf("failed to decrypt session %s: %w", id, err)
+		}
+	}
+
+	next := newSessionCrypto(newKey)
+	tmpPaths := make([]string, 0, len(ids))
+	removeTemps := func(paths []string) {
+		for _, path := range paths {
+			os.Remove(path)
+		}
+	}
+	for i, id := range ids {
+		data := plain[i]
+		if next != nil {
+			if data, err = next.seal(data); err != nil {
+				removeTemps(tmpPaths)
+				return fmt.Errorf("failed to encrypt session %s: %w", id, err)
+			}
+		}
+		tmp, err := writeTempFile(m.sessionsDir, id+".json.*.tmp", data)
+		if err != nil {
+			removeTemps(tmpPaths)
+			return fmt.Errorf("failed to write session %s: %w", id, err)
+		}
+		tmpPaths = append(tmpPaths, tmp)
+	}
+
+	for i, id := range ids {
+		if err := m.rename(tmpPaths[i], m.sessionPath(id)); err != nil {
+			removeTemps(tmpPaths[i:])
+			restoreErr := m.restoreSessionFiles(ids[:i], original[:i])
+			if restoreErr != nil {
+				return fmt.Errorf("failed to replace session %s: %w (and failed to restore earlier sessions: %v)", id, err, restoreErr)
+			}
+			return fmt.Errorf("failed to replace session %s: %w", id, err)
+		}
+	}
+	m.crypto = next
+	return m.rebuildIndexLocked()
+}
+
+// restoreSessionFiles puts back session files as they were before a failed
+// re-key; callers hold m.mu.
+func (m *Manager) restoreSessionFiles(ids []string, contents [][]byte) error {
+	var errs []error
+	for i, id := range ids {
+		tmp, err := writeTempFile(m.sessionsDir, id+".json.*.tmp", contents[i])
+		if err == nil {
+			if err = m.rename(tmp, m.sessionPath(id)); err != nil {
+				os.Remove(tmp)
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("session %s: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeTempFile writes data to a new file in dir named after pattern, as
+// os.CreateTemp names them, and returns its path
+func writeTempFile(dir, pattern string, data []byte) (string, error) {
+	f, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), 0644)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// sessionFileIDs lists the IDs of the session files on disk; callers hold m.mu.
+func (m *Manager) sessionFileIDs() ([]string, error) {
+	entries, err := os.ReadDir(m.sessionsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sessions directory: %w", err)
+	}
+	var ids []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".json" || name == filepath.Base(m.metaPath) || name == filepath.Base(m.indexPath) {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(name, ".json"))
+	}
+	return ids, nil
+}
+
+// isEncryptedSession reports whether a session file's contents are encrypted
+func isEncryptedSession(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(encryptedMagic))
+}
+
+// decodeSessionData returns a session file's JSON, decrypting it with c when
+// the file is encrypted
+func decodeSessionData(data []byte, c *sessionCrypto) ([]byte, error) {
+	if !isEncryptedSession(data) {
+		return data, nil
+	}
+	if c == nil {
+		return nil, ErrSessionEncrypted
+	}
+	return c.open(data)
+}
+
+// seal encrypts a session's JSON into the encrypted file format
+func (c *sessionCrypto) seal(plaintext []byte) ([]byte, error) {
+	gcm, err := c.aead(c.salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	rand.Read(nonce)
+
+	payload := append(bytes.Clone(c.salt), nonce...)
+	payload = gcm.Seal(payload, nonce, plaintext, []byte(encryptedMagic))
+	out := make([]byte, len(encryptedMagic)+base64.StdEncoding.EncodedLen(len(payload)))
+	copy(out, encryptedMagic)
+	base64.StdEncoding.Encode(out[len(encryptedMagic):], payload)
+	return out, nil
+}
+
+// open decrypts a file written by seal
+func (c *sessionCrypto) open(data []byte) ([]byte, error) {
+	encoded := bytes.TrimSpace(data[len(encryptedMagic):])
+	payload := make([]byte, base64.StdEncoding.DecodedLen(len(encoded)))
+	n, err := base64.StdEncoding.Decode(payload, encoded)
+	if err != nil {
+		return nil, ErrWrongEncryptionKey
+	}
+	payload = payload[:n]
+	if len(payload) < encryptionSaltSize {
+		return nil, ErrWrongEncryptionKey
+	}
+	salt, payload := payload[:encryptionSaltSize], payload[encryptionSaltSize:]
+	gcm, err := c.aead(salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) < gcm.NonceSize() {
+		return nil, ErrWrongEncryptionKey
+	}
+	nonce, ciphertext := payload[:gcm.NonceSize()], payload[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, []byte(encryptedMagic))
+	if err != nil {
+		return nil, ErrWrongEncryptionKey
+	}
+	return plaintext, nil
+}
+
+// aead returns AES-256-GCM under the key derived for salt
+func (c *sessionCrypto) aead(salt []byte) (cipher.AEAD, error) {
+	c.mu.Lock()
+	key, ok := c.keys[string(salt)]
+	if !ok {
+		var err error
+		key, err = scrypt.Key(c.passphrase, salt, encryptionScryptN, encryptionScryptR, encryptionScryptP, encryptionKeySize)
+		if err != nil {
+			c.mu.Unlock()
+			return nil, fmt.Errorf("failed to derive encryption key: %w", err)
+		}
+		c.keys[string(salt)] = key
+	}
+	c.mu.Unlock()
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
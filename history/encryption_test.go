@@ -0,0 +1,192 @@
+package history
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+func newEncryptionTestManager(t *testing.T) *Manager {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	mgr, err := NewManager()
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	return mgr
+}
+
+func saveTestSession(t *testing.T, mgr *Manager, text string) *Session {
+	t.Helper()
+	session, err := mgr.StartSession("/tmp/secret", "openai", "gpt-4o")
+	if err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+	session.Messages = append(session.Messages, Message{Role: "user", Content: strPtr(text)})
+	if err := mgr.SaveSession(session); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+	return session
+}
+
+func TestEncryptedSessionRoundTrip(t *testing.T) {
+	mgr := newEncryptionTestManager(t)
+	mgr.SetEncryptionKey([]byte("correct horse"))
+	session := saveTestSession(t, mgr, "the launch code is 0000")
+
+	data, err := os.ReadFile(mgr.sessionPath(session.ID))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte(encryptedMagic)) || bytes.Contains(data, []byte("launch code")) {
+		t.Fatalf("expected an encrypted session file, got %q", data)
+	}
+
+	// A fresh manager has a new salt, so this also covers deriving the key
+	// from the one stored in the file.
+	reader, err := NewManager()
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	if _, err := reader.LoadSession(session.ID); !errors.Is(err, ErrSessionEncrypted) {
+		t.Fatalf("expected ErrSessionEncrypted without a key, got %v", err)
+	}
+	reader.SetEncryptionKey([]byte("wrong horse"))
+	if _, err := reader.LoadSession(session.ID); !errors.Is(err, ErrWrongEncryptionKey) {
+		t.Fatalf("expected ErrWrongEncryptionKey, got %v", err)
+	}
+	reader.SetEncryptionKey([]byte("correct horse"))
+	loaded, err := reader.LoadSession(session.ID)
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if len(loaded.Messages) != 1 || *loaded.Messages[0].Content != "the launch code is 0000" {
+		t.Fatalf("unexpected messages after decrypting: %+v", loaded.Messages)
+	}
+
+	if results, err := reader.Search("launch"); err != nil || len(results) != 0 {
+		t.Fatalf("expected encrypted sessions to stay out of the search index, got %+v, %v", results, err)
+	}
+}
+
+func TestChangeEncryptionKeyMigratesSessions(t *testing.T) {
+	mgr := newEncryptionTestManager(t)
+	plain := saveTestSession(t, mgr, "written before encryption")
+
+	if err := mgr.ChangeEncryptionKey(nil, []byte("first")); err != nil {
+		t.Fatalf("encrypting: %v", err)
+	}
+	encrypted := saveTestSession(t, mgr, "written while encrypted")
+	for _, id := range []string{plain.ID, encrypted.ID} {
+		data, err := os.ReadFile(mgr.sessionPath(id))
+		if err != nil || !isEncryptedSession(data) {
+			t.Fatalf("expected session %s to be encrypted (err %v)", id, err)
+		}
+	}
+
+	if err := mgr.ChangeEncryptionKey([]byte("wrong"), []byte("second")); !errors.Is(err, ErrWrongEncryptionKey) {
+		t.Fatalf("expected a wrong old key to fail, got %v", err)
+	}
+	if err := mgr.ChangeEncryptionKey([]byte("first"), []byte("second")); err != nil {
+		t.Fatalf("re-keying: %v", err)
+	}
+	if err := mgr.ChangeEncryptionKey([]byte("second"), nil); err != nil {
+		t.Fatalf("decrypting: %v", err)
+	}
+
+	for _, session := range []*Session{plain, encrypted} {
+		data, err := os.ReadFile(mgr.sessionPath(session.ID))
+		if err != nil || isEncryptedSession(data) {
+			t.Fatalf("expected session %s in plain text (err %v)", session.ID, err)
+		}
+		loaded, err := mgr.LoadSession(session.ID)
+		if err != nil {
+			t.Fatalf("LoadSession: %v", err)
+		}
+		if *loaded.Messages[0].Content != *session.Messages[0].Content {
+			t.Fatalf("session %s content changed: %q", session.ID, *loaded.Messages[0].Content)
+		}
+	}
+	if results, err := mgr.Search("encrypt"); err != nil || len(results) != 2 {
+		t.Fatalf("expected decrypted sessions back in the search index, got %+v, %v", results, err)
+	}
+}
+
+func TestEncryptionUsesScrypt(t *testing.T) {
+	c := newSessionCrypto([]byte("correct horse"))
+	sealed, err := c.seal([]byte(`{"id":"x"}`))
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	payload, err := base64.StdEncoding.DecodeString(string(sealed[len(encryptedMagic):]))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want, err := scrypt.Key([]byte("correct horse"), payload[:encryptionSaltSize], encryptionScryptN, encryptionScryptR, encryptionScryptP, encryptionKeySize)
+	if err != nil {
+		t.Fatalf("scrypt: %v", err)
+	}
+	if got := c.keys[string(payload[:encryptionSaltSize])]; !bytes.Equal(got, want) {
+		t.Fatal("expected the file key to be derived with scrypt")
+	}
+}
+
+func TestChangeEncryptionKeyIsAllOrNothing(t *testing.T) {
+	mgr := newEncryptionTestManager(t)
+	mgr.SetEncryptionKey([]byte("first"))
+	var sessions []*Session
+	for _, text := range []string{"one", "two", "three"} {
+		sessions = append(sessions, saveTestSession(t, mgr, text))
+	}
+	before := make(map[string][]byte)
+	for _, session := range sessions {
+		data, err := os.ReadFile(mgr.sessionPath(session.ID))
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		before[session.ID] = data
+	}
+
+	// Fail the second rename, after one session has been replaced
+	renames := 0
+	mgr.rename = func(oldpath, newpath string) error {
+		renames++
+		if renames == 2 {
+			return errors.New("disk full")
+		}
+		return os.Rename(oldpath, newpath)
+	}
+	if err := mgr.ChangeEncryptionKey([]byte("first"), []byte("second")); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("expected the rename failure, got %v", err)
+	}
+
+	for id, data := range before {
+		after, err := os.ReadFile(mgr.sessionPath(id))
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		if !bytes.Equal(after, data) {
+			t.Fatalf("session %s should be left as it was", id)
+		}
+	}
+	entries, err := os.ReadDir(mgr.sessionsDir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			t.Fatalf("temporary file %s left behind", entry.Name())
+		}
+	}
+	if _, err := mgr.LoadSession(sessions[0].ID); err != nil {
+		t.Fatalf("sessions should still load with the old key: %v", err)
+	}
+}
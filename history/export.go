@@ -0,0 +1,273 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ExportFormat names a format a session can be exported to
+type ExportFormat string
+
+const (
+	ExportMarkdown ExportFormat = "markdown"
+	ExportHTML     ExportFormat = "html"
+	ExportJSON     ExportFormat = "json"
+)
+
+// ParseExportFormat resolves a user-supplied format name. An empty name means
+// Markdown, and "md" and "htm" are accepted as aliases.
+func ParseExportFormat(name string) (ExportFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "markdown", "md":
+		return ExportMarkdown, nil
+	case "html", "htm":
+		return ExportHTML, nil
+	case "json":
+		return ExportJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q (use markdown, html, or json)", name)
+	}
+}
+
+// Extension returns the file extension used for the format, without a dot
+func (f ExportFormat) Extension() string {
+	switch f {
+	case ExportHTML:
+		return "html"
+	case ExportJSON:
+		return "json"
+	default:
+		return "md"
+	}
+}
+
+// DefaultExportPath returns where an export lands when no path is given:
+// ~/Downloads/simple-agent-<session-id>.<ext>, or the current directory when
+// there is no Downloads folder. Sessions without an ID are named by time.
+func DefaultExportPath(sessionID string, format ExportFormat) string {
+	if sessionID == "" {
+		sessionID = time.Now().Format("20060102-150405")
+	}
+	name := fmt.Sprintf("simple-agent-%s.%s", sessionID, format.Extension())
+	if home, err := os.UserHomeDir(); err == nil {
+		downloads := filepath.Join(home, "Downloads")
+		if info, err := os.Stat(downloads); err == nil && info.IsDir() {
+			return filepath.Join(downloads, name)
+		}
+	}
+	return name
+}
+
+// Export writes the session's conversation to w. Markdown and HTML render
+// user, assistant, and tool messages with their timestamps; JSON writes the
+// raw llm.Message array. System messages are never included.
+func Export(w io.Writer, session *Session, format ExportFormat) error {
+	switch format {
+	case ExportMarkdown:
+		_, err := io.WriteString(w, exportMarkdown(session))
+		return err
+	case ExportHTML:
+		return exportHTML(w, session)
+	case ExportJSON:
+		var messages []Message
+		for _, msg := range session.Messages {
+			if msg.Role != "system" {
+				messages = append(messages, msg)
+			}
+		}
+		data, err := json.MarshalIndent(ToLLMMessages(messages), "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// exportEntry is one rendered message shared by the Markdown and HTML exports
+type exportEntry struct {
+	Role      string // "user", "assistant", or "tool"
+	Heading   string
+	Timestamp string
+	Content   string
+	ToolCalls []exportToolCall
+}
+
+type exportToolCall struct {
+	Name      string
+	Arguments string
+}
+
+// exportEntries flattens the session into displayable entries, naming each
+// tool result after the call that produced it.
+func exportEntries(session *Session) []exportEntry {
+	toolNames := make(map[string]string)
+	var entries []exportEntry
+	for _, msg := range session.Messages {
+		entry := exportEntry{Role: msg.Role, Content: strings.TrimSpace(contentOf(msg))}
+		if !msg.Timestamp.IsZero() {
+			entry.Timestamp = msg.Timestamp.UTC().Format(time.RFC3339)
+		}
+		switch msg.Role {
+		case "user":
+			entry.Heading = "User"
+		case "assistant":
+			entry.Heading = "Assistant"
+			for _, call := range msg.ToolCalls {
+				toolNames[call.ID] = call.Function.Name
+				entry.ToolCalls = append(entry.ToolCalls, exportToolCall{
+					Name:      call.Function.Name,
+					Arguments: prettyArguments(call.Function.Arguments),
+				})
+			}
+		case "tool":
+			entry.Heading = "Tool result"
+			if name := toolNames[msg.ToolCallID]; name != "" {
+				entry.Heading = fmt.Sprintf("Tool result (%s)", name)
+			}
+		default:
+			continue
+		}
+		if entry.Content == "" && len(entry.ToolCalls) == 0 {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func contentOf(msg Message) string {
+	if msg.Content == nil {
+		return ""
+	}
+	return *msg.Content
+}
+
+// prettyArguments indents JSON tool arguments, leaving anything else as-is
+func prettyArguments(arguments string) string {
+	var v interface{}
+	if err := json.Unmarshal([]byte(arguments), &v); err != nil {
+		return strings.TrimSpace(arguments)
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return strings.TrimSpace(arguments)
+	}
+	return string(data)
+}
+
+func exportMarkdown(session *Session) string {
+	var b strings.Builder
+	b.WriteString("# Conversation\n")
+	if title := strings.TrimSpace(session.Metadata.Title); title != "" {
+		b.WriteString("\n" + title + "\n")
+	}
+
+	for _, entry := range exportEntries(session) {
+		b.WriteString("\n## " + entry.Heading + "\n\n")
+		if entry.Timestamp != "" {
+			b.WriteString("_" + entry.Timestamp + "_\n\n")
+		}
+		if entry.Content != "" {
+			if entry.Role == "tool" {
+				b.WriteString(codeBlock("", entry.Content))
+			} else {
+				b.WriteString(entry.Content + "\n")
+			}
+		}
+		for i, call := range entry.ToolCalls {
+			if i > 0 || entry.Content != "" {
+				b.WriteString("\n")
+			}
+			b.WriteString(fmt.Sprintf("**Tool call:** `%s`\n\n", call.Name))
+			b.WriteString(codeBlock("json", call.Arguments))
+		}
+	}
+	return b.String()
+}
+
+// codeBlock fences text with one more backtick than the longest run inside
+// it, so content that contains fences of its own cannot close the block.
+func codeBlock(lang, text string) string {
+	longest, run := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	return fence + lang + "\n" + text + "\n" + fence + "\n"
+}
+
+// exportHTMLTemplate is a self-contained page styled after the TUI's dark
+// palette: blue for the user, teal for tools, gray for metadata.
+var exportHTMLTemplate = template.Must(template.New("export").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { background: #1e1e1e; color: #e5e7eb; font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; max-width: 960px; margin: 2rem auto; padding: 0 1rem; line-height: 1.5; }
+h1 { font-size: 1.4rem; border-bottom: 1px solid #3f3f46; padding-bottom: .5rem; }
+.meta { color: #6b7280; font-size: .85rem; }
+.message { border: 1px solid #3f3f46; border-radius: 6px; padding: .75rem 1rem; margin: 1rem 0; }
+.message h2 { font-size: 1rem; margin: 0 0 .25rem; }
+.user h2 { color: #5b9bd5; }
+.assistant h2 { color: #e5e7eb; }
+.tool h2, .tool-call { color: #4ecdc4; }
+pre { white-space: pre-wrap; word-wrap: break-word; margin: .5rem 0 0; }
+pre.code { background: #111827; padding: .5rem; border-radius: 4px; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- if .Session}}
+<p class="meta">Session {{.Session}}{{if .Model}} · {{.Model}}{{end}}</p>
+{{- end}}
+{{- range .Entries}}
+<div class="message {{.Role}}">
+<h2>{{.Heading}}</h2>
+{{- if .Timestamp}}
+<div class="meta">{{.Timestamp}}</div>
+{{- end}}
+{{- if .Content}}
+<pre{{if eq .Role "tool"}} class="code"{{end}}>{{.Content}}</pre>
+{{- end}}
+{{- range .ToolCalls}}
+<div class="tool-call">Tool call: {{.Name}}</div>
+<pre class="code">{{.Arguments}}</pre>
+{{- end}}
+</div>
+{{- end}}
+</body>
+</html>
+`))
+
+func exportHTML(w io.Writer, session *Session) error {
+	title := strings.TrimSpace(session.Metadata.Title)
+	if title == "" {
+		title = "Conversation"
+	}
+	return exportHTMLTemplate.Execute(w, struct {
+		Title   string
+		Session string
+		Model   string
+		Entries []exportEntry
+	}{
+		Title:   title,
+		Session: session.ID,
+		Model:   strings.Trim(session.Provider+"/"+session.Model, "/"),
+		Entries: exportEntries(session),
+	})
+}
@@ -0,0 +1,98 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+func exportTestSession() *Session {
+	at := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+	system, question, answer, result := "You are helpful.", "What is 6*7? <b>", "It is 42.\n\n```go\nfmt.Println(42)\n```", "42"
+	return &Session{
+		ID:       "abc123",
+		Provider: "openai",
+		Model:    "gpt-4o",
+		Messages: []Message{
+			{Role: "system", Content: &system, Timestamp: at},
+			{Role: "user", Content: &question, Timestamp: at},
+			{Role: "assistant", Timestamp: at, ToolCalls: []ToolCall{{
+				ID: "call_1", Type: "function",
+				Function: FunctionCall{Name: "calculate", Arguments: `{"expression":"6*7"}`},
+			}}},
+			{Role: "tool", Content: &result, ToolCallID: "call_1", Timestamp: at},
+			{Role: "assistant", Content: &answer, Timestamp: at},
+		},
+	}
+}
+
+func TestExport_Markdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, exportTestSession(), ExportMarkdown); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# Conversation\n",
+		"## User\n\n_2025-03-01T09:30:00Z_\n\nWhat is 6*7? <b>\n",
+		"**Tool call:** `calculate`\n\n```json\n{\n  \"expression\": \"6*7\"\n}\n```\n",
+		"## Tool result (calculate)\n\n_2025-03-01T09:30:00Z_\n\n```\n42\n```\n",
+		"## Assistant\n\n_2025-03-01T09:30:00Z_\n\nIt is 42.\n\n```go\nfmt.Println(42)\n```\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in Markdown export:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "You are helpful.") {
+		t.Fatalf("expected the system prompt to be left out:\n%s", out)
+	}
+}
+
+func TestExport_HTMLIsSelfContainedAndEscaped(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, exportTestSession(), ExportHTML); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"<!DOCTYPE html>", "<style>", "What is 6*7? &lt;b&gt;", "Tool result (calculate)", "Session abc123 · openai/gpt-4o"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in HTML export:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<link") || strings.Contains(out, "<script") {
+		t.Fatalf("expected no external resources in HTML export:\n%s", out)
+	}
+}
+
+func TestExport_JSONWritesLLMMessages(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, exportTestSession(), ExportJSON); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	var messages []llm.Message
+	if err := json.Unmarshal(buf.Bytes(), &messages); err != nil {
+		t.Fatalf("decode JSON export: %v\n%s", err, buf.String())
+	}
+	if len(messages) != 4 || messages[0].Role != llm.RoleUser || messages[2].ToolCallID != "call_1" {
+		t.Fatalf("unexpected JSON export: %+v", messages)
+	}
+	if messages[1].ToolCalls[0].Function.Name != "calculate" {
+		t.Fatalf("expected the tool call to survive, got %+v", messages[1])
+	}
+}
+
+func TestParseExportFormat(t *testing.T) {
+	cases := map[string]ExportFormat{"": ExportMarkdown, "md": ExportMarkdown, "HTML": ExportHTML, "json": ExportJSON}
+	for name, want := range cases {
+		if got, err := ParseExportFormat(name); err != nil || got != want {
+			t.Errorf("ParseExportFormat(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseExportFormat("pdf"); err == nil {
+		t.Errorf("expected an error for an unsupported format")
+	}
+}
@@ -14,11 +14,17 @@ import (
 	"github.com/nachoal/simple-agent-go/llm"
 )
 
+const branchIndexPrefix = "branch:"
+
 // Manager handles conversation history persistence
 type Manager struct {
 	sessionsDir string
 	metaPath    string
+	indexPath   string         // Word index used by Search; see search.go
+	crypto      *sessionCrypto // Set by SetEncryptionKey; see encryption.go
 	mu          sync.RWMutex
+
+	rename func(oldpath, newpath string) error // os.Rename; replaced in tests
 }
 
 // NewManager creates a new history manager
@@ -33,6 +39,8 @@ func NewManager() (*Manager, error) {
 	m := &Manager{
 		sessionsDir: sessionsDir,
 		metaPath:    filepath.Join(sessionsDir, "meta.json"),
+		indexPath:   filepath.Join(sessionsDir, "index.json"),
+		rename:      os.Rename,
 	}
 
 	// Create directory
@@ -91,10 +99,14 @@ func (m *Manager) StartSession(path, provider, model string) (*Session, error) {
 func (m *Manager) SaveSession(session *Session) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	return m.saveSessionLocked(session)
+}
 
+// saveSessionLocked is SaveSession; callers hold m.mu.
+func (m *Manager) saveSessionLocked(session *Session) error {
 	session.UpdatedAt = time.Now()
 
-	// Generate title if empty
+	// Title the session after its first message once it has one
 	if session.Metadata.Title == "" {
 		session.Metadata.Title = m.generateTitle(session)
 	}
@@ -105,8 +117,13 @@ func (m *Manager) SaveSession(session *Session) error {
 		return fmt.Errorf("failed to marshal session: %w", err)
 	}
 
-	filename := filepath.Join(m.sessionsDir, session.ID+".json")
-	if err := os.WriteFile(filename, data, 0644); err != nil {
+	if m.crypto != nil {
+		if data, err = m.crypto.seal(data); err != nil {
+			return fmt.Errorf("failed to encrypt session: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(m.sessionPath(session.ID), data, 0644); err != nil {
 		return fmt.Errorf("failed to write session file: %w", err)
 	}
 
@@ -121,6 +138,10 @@ func (m *Manager) SaveSession(session *Session) error {
 		return fmt.Errorf("failed to save meta: %w", err)
 	}
 
+	if err := m.indexSession(session); err != nil {
+		return fmt.Errorf("failed to update search index: %w", err)
+	}
+
 	return nil
 }
 
@@ -182,19 +203,37 @@ func (m *Manager) FinishRun(session *Session, runID string, status RunStatus, er
 func (m *Manager) LoadSession(id string) (*Session, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
+	return m.readSession(id)
+}
 
-	filename := filepath.Join(m.sessionsDir, id+".json")
-	data, err := os.ReadFile(filename)
+// readSession loads a session file; callers hold m.mu.
+func (m *Manager) readSession(id string) (*Session, error) {
+	session, _, err := m.readSessionFile(id)
+	return session, err
+}
+
+// readSessionFile is readSession that also reports whether the file was
+// encrypted; callers hold m.mu.
+func (m *Manager) readSessionFile(id string) (*Session, bool, error) {
+	data, err := os.ReadFile(m.sessionPath(id))
 	if err != nil {
-		return nil, fmt.Errorf("failed to read session file: %w", err)
+		return nil, false, fmt.Errorf("failed to read session file: %w", err)
+	}
+	encrypted := isEncryptedSession(data)
+	if data, err = decodeSessionData(data, m.crypto); err != nil {
+		return nil, encrypted, fmt.Errorf("failed to read session %s: %w", id, err)
 	}
 
 	var session Session
 	if err := json.Unmarshal(data, &session); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal session: %w", err)
+		return nil, encrypted, fmt.Errorf("failed to unmarshal session: %w", err)
 	}
 
-	return &session, nil
+	return &session, encrypted, nil
+}
+
+func (m *Manager) sessionPath(id string) string {
+	return filepath.Join(m.sessionsDir, id+".json")
 }
 
 // GetLastSessionForPath returns the most recent session for a given path
@@ -287,8 +326,109 @@ func (m *Manager) ListSessions(limit int) ([]SessionInfo, error) {
 	return m.loadSessionInfos(ids, limit), nil
 }
 
+// Branch forks a session into a new session containing the parent's messages
+// up to and including afterMessageIndex. The branch shares the parent's
+// workspace, provider, and model so it can be resumed like any other session.
+func (m *Manager) Branch(sessionID string, afterMessageIndex int) (*Session, error) {
+	parent, err := m.LoadSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if afterMessageIndex < 0 || afterMessageIndex >= len(parent.Messages) {
+		return nil, fmt.Errorf("branch point %d out of range for session %s (%d messages)", afterMessageIndex, sessionID, len(parent.Messages))
+	}
+
+	branch, err := m.StartSession(parent.Path, parent.Provider, parent.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	branch.Messages = make([]Message, afterMessageIndex+1)
+	copy(branch.Messages, parent.Messages[:afterMessageIndex+1])
+	branch.Metadata.Tags = append([]string{}, parent.Metadata.Tags...)
+	branch.Metadata.ParentID = parent.ID
+	branch.Metadata.BranchPoint = afterMessageIndex
+	if parent.Metadata.Title != "" {
+		branch.Metadata.Title = parent.Metadata.Title + " (branch)"
+	}
+
+	if err := m.updatePathIndex(branchIndexKey(parent.ID), branch.ID); err != nil {
+		return nil, fmt.Errorf("failed to update branch index: %w", err)
+	}
+	if err := m.SaveSession(branch); err != nil {
+		return nil, fmt.Errorf("failed to persist branch: %w", err)
+	}
+
+	return branch, nil
+}
+
+// ListBranches returns the sessions forked directly from the given session.
+func (m *Manager) ListBranches(sessionID string) ([]SessionInfo, error) {
+	m.mu.RLock()
+	meta, err := m.loadMeta()
+	m.mu.RUnlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load meta: %w", err)
+	}
+
+	branchIDs, ok := meta.PathIndex[branchIndexKey(sessionID)]
+	if !ok {
+		return []SessionInfo{}, nil
+	}
+
+	return m.loadSessionInfos(branchIDs, 0), nil
+}
+
+// MergeBranch appends the messages a branch added after its branch point to
+// the end of its parent session. The parent records how far each branch has
+// been merged in the same write that appends the messages, so merging again,
+// or retrying a merge that failed after the parent was saved, appends only
+// the messages added since.
+func (m *Manager) MergeBranch(branchID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	branch, err := m.readSession(branchID)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(branch.Metadata.ParentID) == "" {
+		return fmt.Errorf("session %s is not a branch", branchID)
+	}
+
+	parent, err := m.readSession(branch.Metadata.ParentID)
+	if err != nil {
+		return fmt.Errorf("failed to load parent session: %w", err)
+	}
+
+	mergedThrough := max(branch.Metadata.MergedThrough, parent.Metadata.MergedBranches[branchID])
+	start := max(branch.Metadata.BranchPoint, mergedThrough) + 1
+	if start < len(branch.Messages) {
+		parent.Messages = append(parent.Messages, branch.Messages[start:]...)
+		if parent.Metadata.MergedBranches == nil {
+			parent.Metadata.MergedBranches = make(map[string]int)
+		}
+		parent.Metadata.MergedBranches[branchID] = len(branch.Messages) - 1
+		if err := m.saveSessionLocked(parent); err != nil {
+			return err
+		}
+		mergedThrough = len(branch.Messages) - 1
+	}
+	if branch.Metadata.MergedThrough == mergedThrough {
+		return nil
+	}
+	branch.Metadata.MergedThrough = mergedThrough
+	return m.saveSessionLocked(branch)
+}
+
 // ConvertFromLLMMessages converts LLM messages to history messages
 func (m *Manager) ConvertFromLLMMessages(llmMessages []llm.Message) []Message {
+	return FromLLMMessages(llmMessages)
+}
+
+// FromLLMMessages converts LLM messages to history messages stamped with the
+// current time, since LLM messages carry no timestamps of their own.
+func FromLLMMessages(llmMessages []llm.Message) []Message {
 	messages := make([]Message, len(llmMessages))
 	for i, msg := range llmMessages {
 		messages[i] = Message{
@@ -318,6 +458,11 @@ func (m *Manager) ConvertFromLLMMessages(llmMessages []llm.Message) []Message {
 
 // ConvertToLLMMessages converts history messages to LLM messages
 func (m *Manager) ConvertToLLMMessages(histMessages []Message) []llm.Message {
+	return ToLLMMessages(histMessages)
+}
+
+// ToLLMMessages converts history messages to LLM messages
+func ToLLMMessages(histMessages []Message) []llm.Message {
 	messages := make([]llm.Message, len(histMessages))
 	for i, msg := range histMessages {
 		messages[i] = llm.Message{
@@ -421,6 +566,12 @@ func (m *Manager) updatePathIndex(path, sessionID string) error {
 	return m.saveMeta(meta)
 }
 
+// branchIndexKey is the PathIndex key under which a parent's branches are
+// recorded. Workspace paths are absolute, so the prefix cannot collide.
+func branchIndexKey(parentID string) string {
+	return branchIndexPrefix + parentID
+}
+
 func (m *Manager) loadSessionInfos(sessionIDs []string, limit int) []SessionInfo {
 	sessions := make([]SessionInfo, 0, len(sessionIDs))
 	for _, id := range sessionIDs {
@@ -446,9 +597,13 @@ func (m *Manager) loadSessionInfos(sessionIDs []string, limit int) []SessionInfo
 }
 
 func sessionInfoFromSession(session *Session) SessionInfo {
+	title := session.Metadata.Title
+	if title == "" {
+		title = fmt.Sprintf("Session %s", session.CreatedAt.Format("Jan 02 15:04"))
+	}
 	return SessionInfo{
 		ID:            session.ID,
-		Title:         session.Metadata.Title,
+		Title:         title,
 		CreatedAt:     session.CreatedAt,
 		UpdatedAt:     session.UpdatedAt,
 		Path:          session.Path,
@@ -456,6 +611,9 @@ func sessionInfoFromSession(session *Session) SessionInfo {
 		Provider:      session.Provider,
 		Model:         session.Model,
 		LastRunStatus: session.Metadata.LastRunStatus,
+		ParentID:      session.Metadata.ParentID,
+		BranchPoint:   session.Metadata.BranchPoint,
+		Tags:          session.Metadata.Tags,
 	}
 }
 
@@ -474,9 +632,7 @@ func (m *Manager) generateTitle(session *Session) string {
 			return content
 		}
 	}
-
-	// Fallback to timestamp
-	return fmt.Sprintf("Session %s", session.CreatedAt.Format("Jan 02 15:04"))
+	return ""
 }
 
 func generateRandomID(length int) string {
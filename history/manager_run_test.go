@@ -169,3 +169,159 @@ func TestManagerConvertToResumeMessagesFiltersToolTranscript(t *testing.T) {
 func strPtr(v string) *string {
 	return &v
 }
+
+func TestManagerBranchAndMerge(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	mgr, err := NewManager()
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+
+	parent, err := mgr.StartSession("/tmp/project", "openai", "gpt-4")
+	if err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+	contents := []string{"system", "first question", "first answer", "second question", "second answer"}
+	roles := []string{"system", "user", "assistant", "user", "assistant"}
+	for i := range contents {
+		content := contents[i]
+		parent.Messages = append(parent.Messages, Message{Role: roles[i], Content: &content})
+	}
+	if err := mgr.SaveSession(parent); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+
+	if _, err := mgr.Branch(parent.ID, len(parent.Messages)); err == nil {
+		t.Fatalf("expected out-of-range branch point to fail")
+	}
+
+	branch, err := mgr.Branch(parent.ID, 2)
+	if err != nil {
+		t.Fatalf("Branch: %v", err)
+	}
+	if len(branch.Messages) != 3 {
+		t.Fatalf("expected 3 copied messages, got %d", len(branch.Messages))
+	}
+	if branch.Metadata.ParentID != parent.ID || branch.Metadata.BranchPoint != 2 {
+		t.Fatalf("unexpected branch metadata: %+v", branch.Metadata)
+	}
+
+	branches, err := mgr.ListBranches(parent.ID)
+	if err != nil {
+		t.Fatalf("ListBranches: %v", err)
+	}
+	if len(branches) != 1 || branches[0].ID != branch.ID {
+		t.Fatalf("expected branch %s to be listed, got %+v", branch.ID, branches)
+	}
+
+	alt := "alternate question"
+	branch.Messages = append(branch.Messages, Message{Role: "user", Content: &alt})
+	if err := mgr.SaveSession(branch); err != nil {
+		t.Fatalf("SaveSession branch: %v", err)
+	}
+	if err := mgr.MergeBranch(branch.ID); err != nil {
+		t.Fatalf("MergeBranch: %v", err)
+	}
+
+	merged, err := mgr.LoadSession(parent.ID)
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if len(merged.Messages) != 6 {
+		t.Fatalf("expected merged parent to have 6 messages, got %d", len(merged.Messages))
+	}
+	if last := merged.Messages[5]; last.Content == nil || *last.Content != alt {
+		t.Fatalf("expected branch message appended to parent, got %+v", last)
+	}
+
+	// Merging again appends only what the branch added since the last merge
+	if err := mgr.MergeBranch(branch.ID); err != nil {
+		t.Fatalf("second MergeBranch: %v", err)
+	}
+	branch, err = mgr.LoadSession(branch.ID)
+	if err != nil {
+		t.Fatalf("LoadSession branch: %v", err)
+	}
+	followUp := "follow-up question"
+	branch.Messages = append(branch.Messages, Message{Role: "user", Content: &followUp})
+	if err := mgr.SaveSession(branch); err != nil {
+		t.Fatalf("SaveSession branch: %v", err)
+	}
+	if err := mgr.MergeBranch(branch.ID); err != nil {
+		t.Fatalf("third MergeBranch: %v", err)
+	}
+	merged, err = mgr.LoadSession(parent.ID)
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if len(merged.Messages) != 7 || *merged.Messages[5].Content != alt || *merged.Messages[6].Content != followUp {
+		t.Fatalf("expected repeated merges to append each branch message once, got %d messages", len(merged.Messages))
+	}
+
+	if err := mgr.MergeBranch(parent.ID); err == nil {
+		t.Fatalf("expected merging a non-branch session to fail")
+	}
+}
+
+func TestMergeBranchRetryAfterBranchSaveFails(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	mgr, err := NewManager()
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	parent, err := mgr.StartSession("/tmp/project", "openai", "gpt-4")
+	if err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+	question, answer := "question", "answer"
+	parent.Messages = []Message{{Role: "user", Content: &question}, {Role: "assistant", Content: &answer}}
+	if err := mgr.SaveSession(parent); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+	branch, err := mgr.Branch(parent.ID, 1)
+	if err != nil {
+		t.Fatalf("Branch: %v", err)
+	}
+	alt := "alternate question"
+	branch.Messages = append(branch.Messages, Message{Role: "user", Content: &alt})
+	if err := mgr.SaveSession(branch); err != nil {
+		t.Fatalf("SaveSession branch: %v", err)
+	}
+
+	// Merge, then put the branch file back as if saving it had failed after
+	// the parent was written
+	before, err := os.ReadFile(mgr.sessionPath(branch.ID))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if err := mgr.MergeBranch(branch.ID); err != nil {
+		t.Fatalf("MergeBranch: %v", err)
+	}
+	if err := os.WriteFile(mgr.sessionPath(branch.ID), before, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if err := mgr.MergeBranch(branch.ID); err != nil {
+		t.Fatalf("retried MergeBranch: %v", err)
+	}
+	merged, err := mgr.LoadSession(parent.ID)
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if len(merged.Messages) != 3 || *merged.Messages[2].Content != alt {
+		t.Fatalf("expected the branch message merged once, got %d messages", len(merged.Messages))
+	}
+	reloaded, err := mgr.LoadSession(branch.ID)
+	if err != nil {
+		t.Fatalf("LoadSession branch: %v", err)
+	}
+	if reloaded.Metadata.MergedThrough != 2 {
+		t.Fatalf("expected the retry to record the merge on the branch, got %d", reloaded.Metadata.MergedThrough)
+	}
+}
@@ -0,0 +1,73 @@
+package history
+
+import "strings"
+
+// SetMessages replaces the session's messages with messages. Assistant
+// messages the session already had keep the provider and model recorded on
+// them; new ones are attributed to the session's current Provider and Model.
+// Messages are matched by content and tool calls rather than by position,
+// since trimming and compacting the agent's memory shifts them.
+func (s *Session) SetMessages(messages []Message) {
+	type producer struct{ provider, model string }
+	known := make(map[string][]producer)
+	for _, msg := range s.Messages {
+		if msg.Role == "assistant" {
+			key := assistantKey(msg)
+			known[key] = append(known[key], producer{msg.Provider, msg.Model})
+		}
+	}
+
+	for i := range messages {
+		if messages[i].Role != "assistant" {
+			continue
+		}
+		key := assistantKey(messages[i])
+		if queue := known[key]; len(queue) > 0 {
+			// Messages saved before models were recorded stay unattributed
+			// rather than being credited to whatever model is current now.
+			messages[i].Provider, messages[i].Model = queue[0].provider, queue[0].model
+			known[key] = queue[1:]
+			continue
+		}
+		messages[i].Provider, messages[i].Model = s.Provider, s.Model
+	}
+	s.Messages = messages
+}
+
+// ModelsUsed returns the distinct "provider/model" names recorded on the
+// session's assistant messages, in the order they were first used
+func (s *Session) ModelsUsed() []string {
+	var used []string
+	seen := make(map[string]bool)
+	for _, msg := range s.Messages {
+		name := msg.ModelName()
+		if msg.Role != "assistant" || name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		used = append(used, name)
+	}
+	return used
+}
+
+// ModelName returns "provider/model" for a message with a recorded model,
+// or "" when none was recorded
+func (m Message) ModelName() string {
+	if m.Model == "" {
+		return ""
+	}
+	return strings.Trim(m.Provider+"/"+m.Model, "/")
+}
+
+// assistantKey identifies an assistant message across saves
+func assistantKey(msg Message) string {
+	var b strings.Builder
+	if msg.Content != nil {
+		b.WriteString(*msg.Content)
+	}
+	for _, call := range msg.ToolCalls {
+		b.WriteString("\x00")
+		b.WriteString(call.ID)
+	}
+	return b.String()
+}
@@ -0,0 +1,36 @@
+package history
+
+import "testing"
+
+func TestSetMessagesKeepsRecordedModels(t *testing.T) {
+	text := func(s string) *string { return &s }
+	session := &Session{
+		Provider: "openai",
+		Model:    "gpt-4o",
+		Messages: []Message{
+			{Role: "user", Content: text("hi")},
+			{Role: "assistant", Content: text("legacy reply")},
+			{Role: "user", Content: text("again")},
+			{Role: "assistant", Content: text("ok"), Provider: "ollama", Model: "llama3.2"},
+		},
+	}
+	session.Provider, session.Model = "anthropic", "claude-sonnet-4-20250514"
+
+	session.SetMessages([]Message{
+		{Role: "user", Content: text("hi")},
+		{Role: "assistant", Content: text("legacy reply")},
+		{Role: "user", Content: text("again")},
+		{Role: "assistant", Content: text("ok")},
+		{Role: "user", Content: text("and now?")},
+		{Role: "assistant", Content: text("ok")},
+	})
+
+	// The reply saved before models were recorded stays unattributed, and
+	// only the new "ok" goes to the current model.
+	want := []string{"", "", "", "ollama/llama3.2", "", "anthropic/claude-sonnet-4-20250514"}
+	for i, msg := range session.Messages {
+		if got := msg.ModelName(); got != want[i] {
+			t.Fatalf("message %d: expected model %q, got %q", i, want[i], got)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const searchIndexVersion = "1"
+
+// searchSnippetRadius is how many characters of context a snippet keeps on
+// each side of the match.
+const searchSnippetRadius = 40
+
+// SearchMatch is a session found by Search, with the most recent message that
+// matched and a short excerpt around the match.
+type SearchMatch struct {
+	SessionInfo
+	MatchedAt time.Time `json:"matched_at"`
+	Role      string    `json:"role"`
+	Snippet   string    `json:"snippet"`
+}
+
+// searchIndex is the reverse index stored in index.json. Words maps each
+// stemmed word to the sessions containing it; Sessions records each session's
+// words so re-saving a session can drop the ones it no longer contains.
+type searchIndex struct {
+	Version  string              `json:"version"`
+	Words    map[string][]string `json:"words"`
+	Sessions map[string][]string `json:"sessions"`
+}
+
+// Search returns the sessions whose messages contain query, compared case
+// insensitively, most recent match first.
+func (m *Manager) Search(query string) ([]SessionInfo, error) {
+	matches, err := m.SearchMatches(query)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]SessionInfo, len(matches))
+	for i, match := range matches {
+		infos[i] = match.SessionInfo
+	}
+	return infos, nil
+}
+
+// SearchMatches is Search with the matching message and a snippet for each
+// session. The word index narrows the candidates to sessions with a word
+// starting with each word of the query; those are then checked for the full
+// query as a substring, so a query that begins mid-word ("arser" for
+// "parser") finds nothing. A missing or unreadable index is rebuilt.
+func (m *Manager) SearchMatches(query string) ([]SearchMatch, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("search query is empty")
+	}
+
+	m.mu.RLock()
+	index, err := m.loadSearchIndex()
+	m.mu.RUnlock()
+	if err != nil {
+		if err := m.RebuildIndex(); err != nil {
+			return nil, err
+		}
+		m.mu.RLock()
+		index, err = m.loadSearchIndex()
+		m.mu.RUnlock()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load search index: %w", err)
+		}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	needle := strings.ToLower(strings.Join(strings.Fields(query), " "))
+	var matches []SearchMatch
+	for _, id := range index.candidates(searchWords(query)) {
+		session, err := m.readSession(id)
+		if err != nil {
+			continue
+		}
+		if match, ok := matchSession(session, needle); ok {
+			matches = append(matches, match)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].MatchedAt.Equal(matches[j].MatchedAt) {
+			return matches[i].UpdatedAt.After(matches[j].UpdatedAt)
+		}
+		return matches[i].MatchedAt.After(matches[j].MatchedAt)
+	})
+	return matches, nil
+}
+
+// RebuildIndex regenerates index.json from every session file, repairing an
+// index that is missing, corrupted, or out of step with the sessions on disk.
+func (m *Manager) RebuildIndex() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rebuildIndexLocked()
+}
+
+func (m *Manager) rebuildIndexLocked() error {
+	ids, err := m.sessionFileIDs()
+	if err != nil {
+		return err
+	}
+
+	index := newSearchIndex()
+	for _, id := range ids {
+		session, encrypted, err := m.readSessionFile(id)
+		if err != nil || encrypted || session.ID == "" {
+			continue
+		}
+		index.set(session.ID, sessionWords(session))
+	}
+	return m.saveSearchIndex(index)
+}
+
+// indexSession records the session's words in the index; callers hold m.mu.
+// Without a usable index the whole index is rebuilt, which also picks up
+// sessions saved before the index existed. A session just saved encrypted is
+// dropped from the index so its words are not kept in plain text.
+func (m *Manager) indexSession(session *Session) error {
+	index, err := m.loadSearchIndex()
+	if err != nil {
+		return m.rebuildIndexLocked()
+	}
+	var words []string
+	if m.crypto == nil {
+		words = sessionWords(session)
+	}
+	index.set(session.ID, words)
+	return m.saveSearchIndex(index)
+}
+
+func (m *Manager) loadSearchIndex() (*searchIndex, error) {
+	data, err := os.ReadFile(m.indexPath)
+	if err != nil {
+		return nil, err
+	}
+	var index searchIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, err
+	}
+	if index.Version != searchIndexVersion || index.Words == nil || index.Sessions == nil {
+		return nil, errors.New("search index has an unknown format")
+	}
+	return &index, nil
+}
+
+func (m *Manager) saveSearchIndex(index *searchIndex) error {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(m.indexPath, data, 0644)
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		Version:  searchIndexVersion,
+		Words:    make(map[string][]string),
+		Sessions: make(map[string][]string),
+	}
+}
+
+// set replaces the words recorded for a session
+func (idx *searchIndex) set(id string, words []string) {
+	for _, word := range idx.Sessions[id] {
+		ids := removeString(idx.Words[word], id)
+		if len(ids) == 0 {
+			delete(idx.Words, word)
+		} else {
+			idx.Words[word] = ids
+		}
+	}
+	delete(idx.Sessions, id)
+	if len(words) == 0 {
+		return
+	}
+	idx.Sessions[id] = words
+	for _, word := range words {
+		idx.Words[word] = append(idx.Words[word], id)
+	}
+}
+
+// candidates returns the sessions that contain, for every query word, an
+// indexed word starting with its stem. Stems of related forms can differ in
+// length ("files" is stored as "fil", "file" as "file"), so an indexed stem
+// that is itself a prefix of the query stem also counts. Over-matching is
+// harmless: SearchMatches checks each candidate for the full query.
+func (idx *searchIndex) candidates(words []string) []string {
+	var result map[string]struct{}
+	for _, word := range words {
+		found := make(map[string]struct{})
+		for indexed, ids := range idx.Words {
+			if !strings.HasPrefix(indexed, word) && !(len(indexed) >= 3 && strings.HasPrefix(word, indexed)) {
+				continue
+			}
+			for _, id := range ids {
+				found[id] = struct{}{}
+			}
+		}
+		if result == nil {
+			result = found
+			continue
+		}
+		for id := range result {
+			if _, ok := found[id]; !ok {
+				delete(result, id)
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(result))
+	for id := range result {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// matchSession finds the most recent non-system message containing needle,
+// which is already lower-cased.
+func matchSession(session *Session, needle string) (SearchMatch, bool) {
+	for i := len(session.Messages) - 1; i >= 0; i-- {
+		msg := session.Messages[i]
+		if msg.Role == "system" || msg.Content == nil {
+			continue
+		}
+		snippet, ok := searchSnippet(*msg.Content, needle)
+		if !ok {
+			continue
+		}
+		matchedAt := msg.Timestamp
+		if matchedAt.IsZero() {
+			matchedAt = session.UpdatedAt
+		}
+		return SearchMatch{
+			SessionInfo: sessionInfoFromSession(session),
+			MatchedAt:   matchedAt,
+			Role:        msg.Role,
+			Snippet:     snippet,
+		}, true
+	}
+	return SearchMatch{}, false
+}
+
+// searchSnippet returns the text around the first case-insensitive match of
+// needle in content, on one line, or false when content does not contain it.
+func searchSnippet(content, needle string) (string, bool) {
+	flat := strings.Join(strings.Fields(content), " ")
+	lower := strings.ToLower(flat)
+	offset := strings.Index(lower, needle)
+	if offset < 0 {
+		return "", false
+	}
+
+	// Lower-casing can change byte lengths, so only reuse the original text
+	// when it lines up rune for rune.
+	text, lowerRunes := []rune(flat), []rune(lower)
+	if len(text) != len(lowerRunes) {
+		text = lowerRunes
+	}
+	at := utf8.RuneCountInString(lower[:offset])
+	start := max(0, at-searchSnippetRadius)
+	end := min(len(text), at+utf8.RuneCountInString(needle)+searchSnippetRadius)
+	snippet := string(text[start:end])
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(text) {
+		snippet += "…"
+	}
+	return snippet, true
+}
+
+// sessionWords returns the distinct stemmed words in a session's messages
+func sessionWords(session *Session) []string {
+	seen := make(map[string]struct{})
+	var words []string
+	for _, msg := range session.Messages {
+		if msg.Role == "system" || msg.Content == nil {
+			continue
+		}
+		for _, word := range searchWords(*msg.Content) {
+			if _, ok := seen[word]; ok {
+				continue
+			}
+			seen[word] = struct{}{}
+			words = append(words, word)
+		}
+	}
+	sort.Strings(words)
+	return words
+}
+
+// searchWords splits text into lower-cased, stemmed words of letters and digits
+func searchWords(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	words := make([]string, 0, len(fields))
+	for _, field := range fields {
+		words = append(words, stemWord(field))
+	}
+	return words
+}
+
+// stemSuffixes are stripped longest first; a word keeps at least three
+// characters so short words such as "is" or "bus" are left alone.
+var stemSuffixes = []string{"ations", "ation", "ings", "ing", "edly", "ies", "ied", "ed", "es", "ly", "s"}
+
+// stemWord reduces a word to a crude stem by stripping one common English
+// suffix, so "parsing", "parsed", and "parses" index together.
+func stemWord(word string) string {
+	for _, suffix := range stemSuffixes {
+		if strings.HasSuffix(word, suffix) && len([]rune(word))-len(suffix) >= 3 {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+	return word
+}
+
+func removeString(values []string, target string) []string {
+	out := values[:0]
+	for _, v := range values {
+		if v != target {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,134 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newSearchTestManager(t *testing.T) *Manager {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	mgr, err := NewManager()
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	return mgr
+}
+
+func saveSearchSession(t *testing.T, mgr *Manager, at time.Time, contents ...string) *Session {
+	t.Helper()
+	session, err := mgr.StartSession("/tmp/project", "openai", "gpt-4o")
+	if err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+	for i, content := range contents {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		text := content
+		session.Messages = append(session.Messages, Message{Role: role, Content: &text, Timestamp: at.Add(time.Duration(i) * time.Minute)})
+	}
+	if err := mgr.SaveSession(session); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+	return session
+}
+
+func TestSearch_CaseInsensitiveAndRankedByMatchRecency(t *testing.T) {
+	mgr := newSearchTestManager(t)
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	older := saveSearchSession(t, mgr, base, "How do I parse YAML config files?", "Use a YAML parser.")
+	newer := saveSearchSession(t, mgr, base.Add(24*time.Hour), "Tell me about Go modules", "Sure. Parsing yaml config in Go needs a library.")
+	saveSearchSession(t, mgr, base.Add(48*time.Hour), "Unrelated chat about lunch")
+
+	infos, err := mgr.Search("yaml CONFIG")
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(infos) != 2 || infos[0].ID != newer.ID || infos[1].ID != older.ID {
+		t.Fatalf("expected [%s %s], got %+v", newer.ID, older.ID, infos)
+	}
+
+	matches, err := mgr.SearchMatches("config file")
+	if err != nil {
+		t.Fatalf("SearchMatches: %v", err)
+	}
+	if len(matches) != 1 || matches[0].ID != older.ID || matches[0].Role != "user" {
+		t.Fatalf("expected the stemmed word to find the older session, got %+v", matches)
+	}
+	if !strings.Contains(matches[0].Snippet, "YAML config files") {
+		t.Fatalf("expected a snippet around the match, got %q", matches[0].Snippet)
+	}
+
+	if infos, _ := mgr.Search("pars"); len(infos) != 2 {
+		t.Fatalf("expected a word prefix to match both sessions, got %+v", infos)
+	}
+	if infos, _ := mgr.Search("kubernetes"); len(infos) != 0 {
+		t.Fatalf("expected no matches, got %+v", infos)
+	}
+	if _, err := mgr.Search("   "); err == nil {
+		t.Fatalf("expected an error for an empty query")
+	}
+}
+
+func TestSearch_IndexFollowsSavesAndRepairs(t *testing.T) {
+	mgr := newSearchTestManager(t)
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	session := saveSearchSession(t, mgr, base, "Deploy the walrus service")
+
+	text := "Rename the service to narwhal"
+	session.Messages = []Message{{Role: "user", Content: &text, Timestamp: base}}
+	if err := mgr.SaveSession(session); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+	if infos, _ := mgr.Search("walrus"); len(infos) != 0 {
+		t.Fatalf("expected words removed by a re-save to drop out of the index, got %+v", infos)
+	}
+	if infos, _ := mgr.Search("narwhal"); len(infos) != 1 {
+		t.Fatalf("expected the new words to be indexed, got %+v", infos)
+	}
+
+	indexPath := filepath.Join(mgr.sessionsDir, "index.json")
+	if err := os.WriteFile(indexPath, []byte("{not json"), 0644); err != nil {
+		t.Fatalf("corrupt index: %v", err)
+	}
+	if infos, err := mgr.Search("narwhal"); err != nil || len(infos) != 1 {
+		t.Fatalf("expected search to rebuild a corrupted index, got %+v (%v)", infos, err)
+	}
+
+	// Sessions saved before the index existed are picked up by the first
+	// save that finds no index.
+	if err := os.Remove(indexPath); err != nil {
+		t.Fatalf("remove index: %v", err)
+	}
+	saveSearchSession(t, mgr, base, "Something else entirely")
+	index, err := mgr.loadSearchIndex()
+	if err != nil {
+		t.Fatalf("load index: %v", err)
+	}
+	if ids := index.Words["narwhal"]; len(ids) != 1 || ids[0] != session.ID {
+		t.Fatalf("expected the rebuilt index to include the earlier session, got %v", ids)
+	}
+
+	if err := mgr.RebuildIndex(); err != nil {
+		t.Fatalf("RebuildIndex: %v", err)
+	}
+	if infos, _ := mgr.Search("entirely"); len(infos) != 1 {
+		t.Fatalf("expected RebuildIndex to keep every session searchable, got %+v", infos)
+	}
+}
+
+func TestStemWord(t *testing.T) {
+	cases := map[string]string{"parsing": "pars", "parsed": "pars", "parses": "pars", "files": "fil", "is": "is", "bus": "bus", "stations": "station", "configurations": "configur"}
+	for word, want := range cases {
+		if got := stemWord(word); got != want {
+			t.Errorf("stemWord(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package history
+
+import (
+	"strings"
+)
+
+// NormalizeTag trims a tag and lowercases it, so "Work" and "work " are the
+// same tag
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// HasTag reports whether the session is tagged tag
+func (s *Session) HasTag(tag string) bool {
+	return hasTag(s.Metadata.Tags, tag)
+}
+
+// AddTag tags the session, reporting false when it already had the tag
+func (s *Session) AddTag(tag string) bool {
+	tag = NormalizeTag(tag)
+	if tag == "" || s.HasTag(tag) {
+		return false
+	}
+	s.Metadata.Tags = append(s.Metadata.Tags, tag)
+	return true
+}
+
+// RemoveTag untags the session, reporting false when it did not have the tag
+func (s *Session) RemoveTag(tag string) bool {
+	tag = NormalizeTag(tag)
+	for i, existing := range s.Metadata.Tags {
+		if existing == tag {
+			s.Metadata.Tags = append(s.Metadata.Tags[:i:i], s.Metadata.Tags[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ListSessionsByTag returns the sessions tagged tag, newest first. An empty
+// path lists matching sessions across every workspace.
+func (m *Manager) ListSessionsByTag(path, tag string) ([]SessionInfo, error) {
+	var sessions []SessionInfo
+	var err error
+	if path == "" {
+		sessions, err = m.ListSessions(0)
+	} else {
+		sessions, err = m.ListSessionsForPath(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return FilterSessionsByTag(sessions, tag), nil
+}
+
+// FilterSessionsByTag returns the sessions tagged tag, keeping their order
+func FilterSessionsByTag(sessions []SessionInfo, tag string) []SessionInfo {
+	matched := make([]SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		if hasTag(session.Tags, tag) {
+			matched = append(matched, session)
+		}
+	}
+	return matched
+}
+
+func hasTag(tags []string, tag string) bool {
+	tag = NormalizeTag(tag)
+	for _, existing := range tags {
+		if existing == tag {
+			return true
+		}
+	}
+	return false
+}
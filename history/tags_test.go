@@ -0,0 +1,66 @@
+package history
+
+import "testing"
+
+func TestTagsPersistAndFilterListings(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	mgr, err := NewManager()
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	start := func(path string, tags ...string) *Session {
+		t.Helper()
+		session, err := mgr.StartSession(path, "openai", "gpt-4o")
+		if err != nil {
+			t.Fatalf("StartSession: %v", err)
+		}
+		for _, tag := range tags {
+			session.AddTag(tag)
+		}
+		if err := mgr.SaveSession(session); err != nil {
+			t.Fatalf("SaveSession: %v", err)
+		}
+		return session
+	}
+	tagged := start("/tmp/api", "Work ", "bugfix")
+	start("/tmp/api")
+	start("/tmp/site", "work")
+
+	if tagged.AddTag("work") {
+		t.Fatal("expected a tag differing only in case and spaces to be a duplicate")
+	}
+	if !tagged.RemoveTag("bugfix") || tagged.RemoveTag("bugfix") {
+		t.Fatal("expected bugfix to be removed once")
+	}
+	if err := mgr.SaveSession(tagged); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+	loaded, err := mgr.LoadSession(tagged.ID)
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if len(loaded.Metadata.Tags) != 1 || !loaded.HasTag("work") {
+		t.Fatalf("expected only the work tag saved, got %q", loaded.Metadata.Tags)
+	}
+
+	inPath, err := mgr.ListSessionsByTag("/tmp/api", "WORK")
+	if err != nil {
+		t.Fatalf("ListSessionsByTag: %v", err)
+	}
+	if len(inPath) != 1 || inPath[0].ID != tagged.ID {
+		t.Fatalf("expected the one tagged session in /tmp/api, got %+v", inPath)
+	}
+	everywhere, err := mgr.ListSessionsByTag("", "work")
+	if err != nil {
+		t.Fatalf("ListSessionsByTag: %v", err)
+	}
+	if len(everywhere) != 2 {
+		t.Fatalf("expected tagged sessions from both paths, got %+v", everywhere)
+	}
+	if none, _ := mgr.ListSessionsByTag("", "bugfix"); len(none) != 0 {
+		t.Fatalf("expected no sessions left tagged bugfix, got %+v", none)
+	}
+}
@@ -0,0 +1,119 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// TitleAfterMessages is how many user and assistant messages a session
+// needs before a TitleGenerator is asked to name it
+const TitleAfterMessages = 4
+
+// maxTitleTranscript caps how much of the conversation is sent for titling
+const maxTitleTranscript = 2000
+
+// TitleGenerator names a conversation in a few words
+type TitleGenerator func(ctx context.Context, messages []Message) (string, error)
+
+const titleSystemPrompt = "You name conversations. Reply with only a title of 3 to 6 words that says what the conversation is about: no quotes, no trailing punctuation, no explanation."
+
+var titleThinkRe = regexp.MustCompile(`(?is)<think>.*?</think>`)
+
+// NewLLMTitleGenerator returns a TitleGenerator that asks model on client for
+// a 3-6 word title.
+func NewLLMTitleGenerator(client llm.Client, model string) TitleGenerator {
+	return func(ctx context.Context, messages []Message) (string, error) {
+		resp, err := client.Chat(ctx, &llm.ChatRequest{
+			Model: model,
+			Messages: []llm.Message{
+				{Role: llm.RoleSystem, Content: llm.StringPtr(titleSystemPrompt)},
+				{Role: llm.RoleUser, Content: llm.StringPtr(titleTranscript(messages))},
+			},
+		})
+		if err != nil {
+			return "", err
+		}
+		if len(resp.Choices) == 0 {
+			return "", errors.New("model returned no choices")
+		}
+		title := cleanTitle(llm.GetStringValue(resp.Choices[0].Message.Content))
+		if title == "" {
+			return "", errors.New("model returned an empty title")
+		}
+		return title, nil
+	}
+}
+
+// GenerateTitle replaces the session's first-message title with one from
+// generate once the session has TitleAfterMessages user and assistant
+// messages, and saves it. Sessions are titled this way only once. On error
+// the session keeps its current title.
+func (m *Manager) GenerateTitle(ctx context.Context, session *Session, generate TitleGenerator) error {
+	if session == nil || generate == nil || session.Metadata.TitleGenerated {
+		return nil
+	}
+	conversational := 0
+	for _, msg := range session.Messages {
+		if (msg.Role == "user" || msg.Role == "assistant") && msg.Content != nil && strings.TrimSpace(*msg.Content) != "" {
+			conversational++
+		}
+	}
+	if conversational < TitleAfterMessages {
+		return nil
+	}
+
+	title, err := generate(ctx, session.Messages)
+	if err != nil {
+		return fmt.Errorf("failed to generate session title: %w", err)
+	}
+	session.Metadata.Title = title
+	session.Metadata.TitleGenerated = true
+	return m.SaveSession(session)
+}
+
+// titleTranscript renders the start of the conversation for the titling
+// request
+func titleTranscript(messages []Message) string {
+	var b strings.Builder
+	b.WriteString("Conversation:\n")
+	for _, msg := range messages {
+		if (msg.Role != "user" && msg.Role != "assistant") || msg.Content == nil {
+			continue
+		}
+		content := strings.TrimSpace(titleThinkRe.ReplaceAllString(*msg.Content, ""))
+		if content == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s: %s\n", msg.Role, content)
+		if b.Len() >= maxTitleTranscript {
+			break
+		}
+	}
+	transcript := b.String()
+	if len(transcript) > maxTitleTranscript {
+		transcript = strings.ToValidUTF8(transcript[:maxTitleTranscript], "") + "…"
+	}
+	return transcript
+}
+
+// cleanTitle reduces a model's reply to a bare one-line title
+func cleanTitle(reply string) string {
+	reply = strings.TrimSpace(titleThinkRe.ReplaceAllString(reply, ""))
+	if i := strings.IndexByte(reply, '\n'); i >= 0 {
+		reply = reply[:i]
+	}
+	reply = strings.TrimSpace(reply)
+	if len(reply) >= 6 && strings.EqualFold(reply[:6], "title:") {
+		reply = strings.TrimSpace(reply[6:])
+	}
+	reply = strings.Trim(reply, "\"'`*#. ")
+	if runes := []rune(reply); len(runes) > 60 {
+		reply = strings.TrimSpace(string(runes[:59])) + "…"
+	}
+	return reply
+}
@@ -0,0 +1,117 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// titleClient answers every chat request with reply, or fails with err
+type titleClient struct {
+	reply    string
+	err      error
+	requests []*llm.ChatRequest
+}
+
+func (c *titleClient) Chat(_ context.Context, req *llm.ChatRequest) (*llm.ChatResponse, error) {
+	c.requests = append(c.requests, req)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &llm.ChatResponse{Choices: []llm.Choice{{Message: llm.Message{Role: llm.RoleAssistant, Content: llm.StringPtr(c.reply)}}}}, nil
+}
+
+func (c *titleClient) ChatStream(context.Context, *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *titleClient) ListModels(context.Context) ([]llm.Model, error)      { return nil, nil }
+func (c *titleClient) GetModel(context.Context, string) (*llm.Model, error) { return nil, nil }
+func (c *titleClient) Close() error                                         { return nil }
+
+func startTitleSession(t *testing.T, turns int) (*Manager, *Session) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	mgr, err := NewManager()
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	session, err := mgr.StartSession("/tmp/project", "openai", "gpt-4")
+	if err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+	messages := []llm.Message{{Role: llm.RoleSystem, Content: llm.StringPtr("system")}}
+	for i := 0; i < turns; i++ {
+		messages = append(messages,
+			llm.Message{Role: llm.RoleUser, Content: llm.StringPtr("help me debug this weird error in my test suite, it fails only on CI")},
+			llm.Message{Role: llm.RoleAssistant, Content: llm.StringPtr("Let's look at the CI logs.")},
+		)
+	}
+	session.Messages = FromLLMMessages(messages)
+	if err := mgr.SaveSession(session); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+	return mgr, session
+}
+
+func TestGenerateTitlePersistsModelTitle(t *testing.T) {
+	mgr, session := startTitleSession(t, 2)
+	if session.Metadata.Title != "help me debug this weird error in my test suite..." {
+		t.Fatalf("expected the first-message title before generation, got %q", session.Metadata.Title)
+	}
+	client := &titleClient{reply: "<think>short and specific</think>Title: \"Debugging CI-Only Test Failures.\""}
+
+	if err := mgr.GenerateTitle(context.Background(), session, NewLLMTitleGenerator(client, "gpt-4o-mini")); err != nil {
+		t.Fatalf("GenerateTitle: %v", err)
+	}
+	if len(client.requests) != 1 || client.requests[0].Model != "gpt-4o-mini" {
+		t.Fatalf("expected one titling request to the given model, got %+v", client.requests)
+	}
+
+	loaded, err := mgr.LoadSession(session.ID)
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if loaded.Metadata.Title != "Debugging CI-Only Test Failures" || !loaded.Metadata.TitleGenerated {
+		t.Fatalf("expected the generated title to be saved, got %q (generated=%v)", loaded.Metadata.Title, loaded.Metadata.TitleGenerated)
+	}
+	infos, err := mgr.ListSessions(1)
+	if err != nil || len(infos) != 1 || infos[0].Title != "Debugging CI-Only Test Failures" {
+		t.Fatalf("expected the session list to show the generated title, got %+v, %v", infos, err)
+	}
+
+	// A titled session is not titled again.
+	if err := mgr.GenerateTitle(context.Background(), loaded, NewLLMTitleGenerator(client, "gpt-4o-mini")); err != nil || len(client.requests) != 1 {
+		t.Fatalf("expected no second titling request, got %d requests, %v", len(client.requests), err)
+	}
+}
+
+func TestGenerateTitleWaitsForEnoughMessages(t *testing.T) {
+	mgr, session := startTitleSession(t, 1)
+	client := &titleClient{reply: "Too Early"}
+
+	if err := mgr.GenerateTitle(context.Background(), session, NewLLMTitleGenerator(client, "")); err != nil {
+		t.Fatalf("GenerateTitle: %v", err)
+	}
+	if len(client.requests) != 0 || session.Metadata.TitleGenerated {
+		t.Fatalf("expected no titling before %d messages", TitleAfterMessages)
+	}
+}
+
+func TestGenerateTitleKeepsFallbackOnError(t *testing.T) {
+	mgr, session := startTitleSession(t, 2)
+	fallback := session.Metadata.Title
+	client := &titleClient{err: errors.New("rate limited")}
+
+	if err := mgr.GenerateTitle(context.Background(), session, NewLLMTitleGenerator(client, "")); err == nil {
+		t.Fatal("expected the generator's error")
+	}
+	if session.Metadata.Title != fallback || session.Metadata.TitleGenerated {
+		t.Fatalf("expected the first-message title kept, got %q", session.Metadata.Title)
+	}
+}
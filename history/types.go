@@ -16,16 +16,25 @@ type Session struct {
 	Metadata  Metadata  `json:"metadata"`
 	Messages  []Message `json:"messages"`
 	Runs      []Run     `json:"runs,omitempty"`
+	// Branches holds the conversation checkpoints saved with /checkpoint,
+	// keyed by name
+	Branches map[string][]Message `json:"branches,omitempty"`
 }
 
 // Metadata contains session metadata
 type Metadata struct {
-	Title         string    `json:"title"`
-	Tags          []string  `json:"tags"`
-	TokenCount    int       `json:"token_count"`
-	LastRunID     string    `json:"last_run_id,omitempty"`
-	LastRunStatus RunStatus `json:"last_run_status,omitempty"`
-	LastRunAt     time.Time `json:"last_run_at,omitempty"`
+	Title          string    `json:"title"`
+	TitleGenerated bool      `json:"title_generated,omitempty"` // Title came from a TitleGenerator
+	Tags           []string  `json:"tags"`
+	TokenCount     int       `json:"token_count"`
+	LastRunID      string    `json:"last_run_id,omitempty"`
+	LastRunStatus  RunStatus `json:"last_run_status,omitempty"`
+	LastRunAt      time.Time `json:"last_run_at,omitempty"`
+	ParentID       string    `json:"parent_id,omitempty"`
+	BranchPoint    int       `json:"branch_point,omitempty"`
+	MergedThrough  int       `json:"merged_through,omitempty"` // Index of the last branch message merged into the parent
+	// Index of the last message merged from each branch, by branch ID
+	MergedBranches map[string]int `json:"merged_branches,omitempty"`
 }
 
 // Message represents a conversation message
@@ -35,6 +44,8 @@ type Message struct {
 	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
 	ToolCallID string     `json:"tool_call_id,omitempty"`
 	Timestamp  time.Time  `json:"timestamp"`
+	Provider   string     `json:"provider,omitempty"` // Provider of the model that wrote an assistant message
+	Model      string     `json:"model,omitempty"`    // Model that wrote an assistant message; empty in sessions saved before it was recorded
 }
 
 type RunStatus string
@@ -81,13 +92,17 @@ type MetaIndex struct {
 
 // SessionInfo provides summary information for session listing
 type SessionInfo struct {
-	ID            string    `json:"id"`
-	Title         string    `json:"title"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
-	Path          string    `json:"path"`
-	Messages      int       `json:"messages"`
-	Provider      string    `json:"provider"`
-	Model         string    `json:"model"`
-	LastRunStatus RunStatus `json:"last_run_status,omitempty"`
+	ID             string    `json:"id"`
+	Title          string    `json:"title"`
+	TitleGenerated bool      `json:"title_generated,omitempty"` // Title came from a TitleGenerator
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	Path           string    `json:"path"`
+	Messages       int       `json:"messages"`
+	Provider       string    `json:"provider"`
+	Model          string    `json:"model"`
+	LastRunStatus  RunStatus `json:"last_run_status,omitempty"`
+	ParentID       string    `json:"parent_id,omitempty"`
+	BranchPoint    int       `json:"branch_point,omitempty"`
+	Tags           []string  `json:"tags,omitempty"`
 }
@@ -0,0 +1,69 @@
+package agentrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/nachoal/simple-agent-go/agent"
+	"github.com/nachoal/simple-agent-go/proto/agentpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Dial connects to an AgentService at addr, sending token as a bearer token
+// when it is not empty. The connection is not encrypted; put a TLS proxy in
+// front of servers reached over untrusted networks.
+func Dial(addr, token string, opts ...grpc.DialOption) (*grpc.ClientConn, agentpb.AgentServiceClient, error) {
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, opts...)
+	if token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(token)))
+	}
+	conn, err := grpc.NewClient(addr, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, agentpb.NewAgentServiceClient(conn), nil
+}
+
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (bearerToken) RequireTransportSecurity() bool { return false }
+
+// StreamEvents calls Stream and returns its chunks as agent stream events.
+// The channel is closed when the stream ends; a failed stream ends with an
+// error event.
+func StreamEvents(ctx context.Context, client agentpb.AgentServiceClient, req *agentpb.QueryRequest) (<-chan agent.StreamEvent, error) {
+	stream, err := client.Stream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan agent.StreamEvent)
+	go func() {
+		defer close(events)
+		for {
+			chunk, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			event := agent.StreamEvent{Type: agent.EventTypeError, Error: err}
+			if err == nil {
+				event = EventFromProto(chunk)
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return events, nil
+}
@@ -0,0 +1,144 @@
+package agentrpc
+
+import (
+	"errors"
+
+	"github.com/nachoal/simple-agent-go/agent"
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/proto/agentpb"
+)
+
+// responseToProto converts an agent response for the wire
+func responseToProto(resp *agent.Response, sessionID string) *agentpb.QueryResponse {
+	out := &agentpb.QueryResponse{
+		Content:      resp.Content,
+		Usage:        usageToProto(resp.Usage),
+		FinishReason: resp.FinishReason,
+		Truncated:    resp.Truncated,
+		Iterations:   int32(resp.Iterations),
+		SessionId:    sessionID,
+	}
+	for _, result := range resp.ToolCalls {
+		out.ToolResults = append(out.ToolResults, toolResultToProto(result.ID, result.Name, result.Result, result.Error))
+	}
+	return out
+}
+
+// ResponseFromProto converts a QueryResponse back into an agent response, so
+// remote answers can be printed like local ones
+func ResponseFromProto(resp *agentpb.QueryResponse) *agent.Response {
+	out := &agent.Response{
+		Content:      resp.GetContent(),
+		Usage:        usageFromProto(resp.GetUsage()),
+		FinishReason: resp.GetFinishReason(),
+		Truncated:    resp.GetTruncated(),
+		Iterations:   int(resp.GetIterations()),
+	}
+	for _, result := range resp.GetToolResults() {
+		out.ToolCalls = append(out.ToolCalls, agent.ToolResult{
+			ID:     result.GetId(),
+			Name:   result.GetName(),
+			Result: result.GetResult(),
+			Error:  errorFromString(result.GetError()),
+		})
+		if result.GetError() != "" {
+			out.ToolFailures++
+		}
+	}
+	return out
+}
+
+// eventToProto converts a stream event for the wire
+func eventToProto(event agent.StreamEvent) *agentpb.StreamChunk {
+	chunk := &agentpb.StreamChunk{
+		Type:         string(event.Type),
+		Content:      event.Content,
+		Usage:        usageToProto(event.Usage),
+		FinishReason: event.FinishReason,
+		Iterations:   int32(event.Iterations),
+	}
+	if event.Error != nil {
+		chunk.Error = event.Error.Error()
+	}
+	if event.Tool != nil {
+		chunk.ToolCall = &agentpb.ToolCall{Id: event.Tool.ID, Name: event.Tool.Name, Arguments: event.Tool.ArgsRaw}
+		switch event.Type {
+		case agent.EventTypeToolResult, agent.EventTypeToolTimeout, agent.EventTypeToolCancel:
+			chunk.ToolResult = toolResultToProto(event.Tool.ID, event.Tool.Name, event.Tool.Result, event.Tool.Error)
+		}
+	}
+	for _, call := range event.ToolCalls {
+		chunk.ProposedToolCalls = append(chunk.ProposedToolCalls, &agentpb.ToolCall{
+			Id:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: string(call.Function.Arguments),
+		})
+	}
+	return chunk
+}
+
+// EventFromProto converts a StreamChunk back into the stream event it was
+// made from. Messages, progress, and tool batch summaries are not sent over
+// the wire.
+func EventFromProto(chunk *agentpb.StreamChunk) agent.StreamEvent {
+	event := agent.StreamEvent{
+		Type:         agent.EventType(chunk.GetType()),
+		Content:      chunk.GetContent(),
+		Usage:        usageFromProto(chunk.GetUsage()),
+		Iterations:   int(chunk.GetIterations()),
+		Error:        errorFromString(chunk.GetError()),
+		FinishReason: chunk.GetFinishReason(),
+	}
+	if call := chunk.GetToolCall(); call != nil {
+		event.Tool = &agent.ToolEvent{ID: call.GetId(), Name: call.GetName(), ArgsRaw: call.GetArguments()}
+		if result := chunk.GetToolResult(); result != nil {
+			event.Tool.Result = result.GetResult()
+			event.Tool.Error = errorFromString(result.GetError())
+		}
+	}
+	for _, call := range chunk.GetProposedToolCalls() {
+		event.ToolCalls = append(event.ToolCalls, llm.ToolCall{
+			ID:       call.GetId(),
+			Type:     "function",
+			Function: llm.FunctionCall{Name: call.GetName(), Arguments: []byte(call.GetArguments())},
+		})
+	}
+	return event
+}
+
+func toolResultToProto(id, name, result string, err error) *agentpb.ToolResult {
+	out := &agentpb.ToolResult{Id: id, Name: name, Result: result}
+	if err != nil {
+		out.Error = err.Error()
+	}
+	return out
+}
+
+func usageToProto(usage *llm.Usage) *agentpb.Usage {
+	if usage == nil {
+		return nil
+	}
+	return &agentpb.Usage{
+		PromptTokens:     int32(usage.PromptTokens),
+		CompletionTokens: int32(usage.CompletionTokens),
+		TotalTokens:      int32(usage.TotalTokens),
+	}
+}
+
+func usageFromProto(usage *agentpb.Usage) *llm.Usage {
+	if usage == nil {
+		return nil
+	}
+	return &llm.Usage{
+		PromptTokens:     int(usage.GetPromptTokens()),
+		CompletionTokens: int(usage.GetCompletionTokens()),
+		TotalTokens:      int(usage.GetTotalTokens()),
+	}
+}
+
+func errorFromString(s string) error {
+	if s == "" {
+		return nil
+	}
+	return errors.New(s)
+}
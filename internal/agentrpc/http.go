@@ -0,0 +1,171 @@
+package agentrpc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/proto/agentpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxHTTPRequestSize bounds a request body
+const maxHTTPRequestSize = 1 << 20
+
+var jsonOptions = protojson.MarshalOptions{UseProtoNames: true}
+
+// HTTPHandlerOption configures the handler from NewHTTPHandler
+type HTTPHandlerOption func(*httpHandler)
+
+// WithHeartbeat sets how long an event stream may be idle before a
+// ": keepalive" comment is sent
+func WithHeartbeat(interval time.Duration) HTTPHandlerOption {
+	return func(h *httpHandler) {
+		if interval > 0 {
+			h.heartbeat = interval
+		}
+	}
+}
+
+type httpHandler struct {
+	server    *Server
+	token     string
+	limiter   *RateLimiter
+	heartbeat time.Duration
+}
+
+// NewHTTPHandler serves s over HTTP with the same auth and rate limit as
+// NewGRPCServer. Both endpoints take a QueryRequest as JSON:
+//
+//	POST /v1/query   returns a QueryResponse as JSON
+//	POST /v1/stream  returns the run's StreamChunks as server-sent events,
+//	                 named by chunk type, with heartbeats while idle
+//
+// A "Session-Id" header works like the session-id metadata header.
+func NewHTTPHandler(s *Server, token string, limiter *RateLimiter, opts ...HTTPHandlerOption) http.Handler {
+	h := &httpHandler{server: s, token: token, limiter: limiter, heartbeat: llm.DefaultSSEHeartbeat}
+	for _, opt := range opts {
+		opt(h)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/query", h.query)
+	mux.HandleFunc("POST /v1/stream", h.stream)
+	return mux
+}
+
+func (h *httpHandler) query(w http.ResponseWriter, r *http.Request) {
+	ctx, req, err := h.parse(r)
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+	resp, err := h.server.Query(ctx, req)
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *httpHandler) stream(w http.ResponseWriter, r *http.Request) {
+	ctx, req, err := h.parse(r)
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // Stop nginx from buffering the stream
+	w.WriteHeader(http.StatusOK)
+
+	sse := llm.NewSSEWriter(w)
+	stop := sse.Heartbeat(h.heartbeat)
+	defer stop()
+	err = h.server.stream(ctx, req, func(chunk *agentpb.StreamChunk) error {
+		data, err := jsonOptions.Marshal(chunk)
+		if err != nil {
+			return err
+		}
+		return sse.WriteEvent(chunk.GetType(), string(data))
+	})
+	if err != nil && ctx.Err() == nil {
+		data, _ := jsonOptions.Marshal(&agentpb.StreamChunk{Type: "error", Error: status.Convert(err).Message()})
+		_ = sse.WriteEvent("error", string(data))
+	}
+}
+
+// parse checks the caller's token and rate, and decodes the request
+func (h *httpHandler) parse(r *http.Request) (context.Context, *agentpb.QueryRequest, error) {
+	ctx := r.Context()
+	if h.token != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", r.Header.Get("Authorization")))
+		if err := checkToken(ctx, h.token); err != nil {
+			return nil, nil, err
+		}
+	}
+	if h.limiter != nil && !h.limiter.Allow(remoteHost(r.RemoteAddr)) {
+		return nil, nil, status.Error(codes.ResourceExhausted, "rate limit exceeded; retry later")
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxHTTPRequestSize))
+	if err != nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "failed to read request: %v", err)
+	}
+	req := &agentpb.QueryRequest{}
+	if err := protojson.Unmarshal(body, req); err != nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "invalid request: %v", err)
+	}
+	if session := strings.TrimSpace(r.Header.Get("Session-Id")); session != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(SessionMetadataKey, session))
+	}
+	return ctx, req, nil
+}
+
+func remoteHost(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+func writeJSON(w http.ResponseWriter, code int, msg proto.Message) {
+	data, err := jsonOptions.Marshal(msg)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(data)
+}
+
+// writeHTTPError writes a gRPC status error as {"error": "..."} with the
+// matching HTTP status
+func writeHTTPError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	code := http.StatusInternalServerError
+	switch st.Code() {
+	case codes.InvalidArgument, codes.FailedPrecondition:
+		code = http.StatusBadRequest
+	case codes.Unauthenticated:
+		code = http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		code = http.StatusTooManyRequests
+	case codes.Canceled, codes.DeadlineExceeded:
+		code = http.StatusGatewayTimeout
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	data, _ := json.Marshal(map[string]string{"error": st.Message()})
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,109 @@
+package agentrpc
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/agent"
+	"github.com/nachoal/simple-agent-go/proto/agentpb"
+)
+
+// slowAgent streams a message, stalls as a slow tool would, then completes
+type slowAgent struct {
+	agent.Agent
+	delay time.Duration
+}
+
+func (s *slowAgent) QueryStream(ctx context.Context, prompt string) (<-chan agent.StreamEvent, error) {
+	events := make(chan agent.StreamEvent)
+	go func() {
+		defer close(events)
+		events <- agent.StreamEvent{Type: agent.EventTypeMessage, Content: prompt}
+		select {
+		case <-time.After(s.delay):
+		case <-ctx.Done():
+			return
+		}
+		events <- agent.StreamEvent{Type: agent.EventTypeComplete, FinishReason: "stop"}
+	}()
+	return events, nil
+}
+
+func newHTTPTestServer(t *testing.T, a agent.Agent, token string, opts ...HTTPHandlerOption) *httptest.Server {
+	t.Helper()
+	srv := NewServer(func(context.Context, *agentpb.QueryRequest) (agent.Agent, func(), error) {
+		return a, nil, nil
+	})
+	ts := httptest.NewServer(NewHTTPHandler(srv, token, nil, opts...))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func post(t *testing.T, url, token, body string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return resp, string(data)
+}
+
+func TestHTTPStreamSendsHeartbeatsDuringSlowResponse(t *testing.T) {
+	ts := newHTTPTestServer(t, &slowAgent{delay: 200 * time.Millisecond}, "", WithHeartbeat(20*time.Millisecond))
+
+	resp, body := post(t, ts.URL+"/v1/stream", "", `{"prompt":"hi"}`)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected response %d %q: %s", resp.StatusCode, resp.Header.Get("Content-Type"), body)
+	}
+
+	message := strings.Index(body, "event: message\n")
+	heartbeat := strings.Index(body, ": keepalive\n\n")
+	complete := strings.Index(body, "event: complete\n")
+	if message < 0 || heartbeat < 0 || complete < 0 {
+		t.Fatalf("expected message, heartbeat, and complete events, got %q", body)
+	}
+	if !(message < heartbeat && heartbeat < complete) {
+		t.Fatalf("heartbeats should fill the gap between events, got %q", body)
+	}
+	if !strings.Contains(body, `"finish_reason":"stop"`) {
+		t.Fatalf("expected the complete chunk as JSON, got %q", body)
+	}
+}
+
+func TestHTTPQuery(t *testing.T) {
+	ts := newHTTPTestServer(t, &fakeAgent{}, "secret")
+
+	resp, body := post(t, ts.URL+"/v1/query", "secret", `{"prompt":"hi","session_id":"abc"}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", resp.StatusCode, body)
+	}
+	if !strings.Contains(body, `"content":"hi #1"`) || !strings.Contains(body, `"session_id":"abc"`) {
+		t.Fatalf("unexpected body %s", body)
+	}
+
+	resp, body = post(t, ts.URL+"/v1/query", "wrong", `{"prompt":"hi"}`)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a bad token, got %d: %s", resp.StatusCode, body)
+	}
+	resp, body = post(t, ts.URL+"/v1/query", "secret", `{"prompt":""}`)
+	if resp.StatusCode != http.StatusBadRequest || !strings.Contains(body, "prompt is required") {
+		t.Fatalf("expected 400 for an empty prompt, got %d: %s", resp.StatusCode, body)
+	}
+}
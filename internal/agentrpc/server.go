@@ -0,0 +1,291 @@
+// Package agentrpc serves an agent over gRPC as the AgentService defined in
+// proto/agent.proto, or over HTTP with server-sent events, and calls one
+// remotely.
+package agentrpc
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/agent"
+	"github.com/nachoal/simple-agent-go/proto/agentpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// SessionMetadataKey is the metadata header that names a session, as an
+// alternative to QueryRequest.session_id
+const SessionMetadataKey = "session-id"
+
+// DefaultSessionTTL is how long a session is kept after its last request
+const DefaultSessionTTL = 30 * time.Minute
+
+// DefaultMaxSessions is how many sessions a server keeps at once
+const DefaultMaxSessions = 100
+
+// AgentFactory builds the agent that answers a request, using the request's
+// provider, model, and tools when they are set. release is called once the
+// agent is no longer needed: after the call for requests without a session,
+// or when a session expires.
+type AgentFactory func(ctx context.Context, req *agentpb.QueryRequest) (a agent.Agent, release func(), err error)
+
+// Server implements AgentService. Requests without a session ID get a fresh
+// agent; requests with one share an agent, and so its conversation, until the
+// session has been idle for the session TTL. When the server holds its
+// maximum number of sessions, a new one replaces the least recently used idle
+// session.
+type Server struct {
+	agentpb.UnimplementedAgentServiceServer
+
+	factory     AgentFactory
+	ttl         time.Duration
+	maxSessions int
+	now         func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+type session struct {
+	agent    agent.Agent
+	release  func()
+	lastUsed time.Time
+	active   int // Requests in progress; a session in use never expires
+}
+
+// ServerOption configures a Server
+type ServerOption func(*Server)
+
+// WithSessionTTL sets how long an idle session is kept
+func WithSessionTTL(ttl time.Duration) ServerOption {
+	return func(s *Server) {
+		if ttl > 0 {
+			s.ttl = ttl
+		}
+	}
+}
+
+// WithMaxSessions sets how many sessions are kept at once
+func WithMaxSessions(n int) ServerOption {
+	return func(s *Server) {
+		if n > 0 {
+			s.maxSessions = n
+		}
+	}
+}
+
+// NewServer creates a Server that builds agents with factory
+func NewServer(factory AgentFactory, opts ...ServerOption) *Server {
+	s := &Server{
+		factory:     factory,
+		ttl:         DefaultSessionTTL,
+		maxSessions: DefaultMaxSessions,
+		now:         time.Now,
+		sessions:    make(map[string]*session),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// NewGRPCServer returns a gRPC server with s registered. When token is not
+// empty, every call must carry it as a bearer token; when limiter is not nil,
+// each client is held to its rate.
+func NewGRPCServer(s *Server, token string, limiter *RateLimiter, opts ...grpc.ServerOption) *grpc.Server {
+	var unary []grpc.UnaryServerInterceptor
+	var stream []grpc.StreamServerInterceptor
+	if token != "" {
+		unary = append(unary, UnaryAuthInterceptor(token))
+		stream = append(stream, StreamAuthInterceptor(token))
+	}
+	if limiter != nil {
+		unary = append(unary, limiter.UnaryInterceptor())
+		stream = append(stream, limiter.StreamInterceptor())
+	}
+	opts = append(opts, grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...))
+
+	server := grpc.NewServer(opts...)
+	agentpb.RegisterAgentServiceServer(server, s)
+	return server
+}
+
+// Query runs the prompt to completion and returns the answer
+func (s *Server) Query(ctx context.Context, req *agentpb.QueryRequest) (*agentpb.QueryResponse, error) {
+	if strings.TrimSpace(req.GetPrompt()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "prompt is required")
+	}
+	a, sessionID, done, err := s.acquire(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	resp, err := a.Query(ctx, req.GetPrompt())
+	if err != nil {
+		return nil, runError(ctx, err)
+	}
+	return responseToProto(resp, sessionID), nil
+}
+
+// Stream runs the prompt and sends the agent's events as they happen
+func (s *Server) Stream(req *agentpb.QueryRequest, stream agentpb.AgentService_StreamServer) error {
+	return s.stream(stream.Context(), req, stream.Send)
+}
+
+// stream runs the prompt and passes each event to send
+func (s *Server) stream(ctx context.Context, req *agentpb.QueryRequest, send func(*agentpb.StreamChunk) error) error {
+	if strings.TrimSpace(req.GetPrompt()) == "" {
+		return status.Error(codes.InvalidArgument, "prompt is required")
+	}
+	a, _, done, err := s.acquire(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	events, err := a.QueryStream(ctx, req.GetPrompt())
+	if err != nil {
+		return runError(ctx, err)
+	}
+	for event := range events {
+		if err := send(eventToProto(event)); err != nil {
+			// The client is gone and ctx is cancelled; let the run wind down
+			go func() {
+				for range events {
+				}
+			}()
+			return err
+		}
+	}
+	return nil
+}
+
+// acquire returns the agent for req and a func to call when the request ends
+func (s *Server) acquire(ctx context.Context, req *agentpb.QueryRequest) (agent.Agent, string, func(), error) {
+	sessionID := strings.TrimSpace(req.GetSessionId())
+	if sessionID == "" {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(SessionMetadataKey); len(values) > 0 {
+				sessionID = strings.TrimSpace(values[0])
+			}
+		}
+	}
+
+	if sessionID == "" {
+		a, release, err := s.factory(ctx, req)
+		if err != nil {
+			return nil, "", nil, status.Errorf(codes.FailedPrecondition, "failed to create agent: %v", err)
+		}
+		return a, "", releaseFunc(release), nil
+	}
+
+	s.mu.Lock()
+	s.expireSessionsLocked()
+	sess, ok := s.sessions[sessionID]
+	if ok {
+		sess.active++
+	} else if !s.makeRoomLocked() {
+		s.mu.Unlock()
+		return nil, "", nil, errTooManySessions
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		// Build the agent without the lock so a slow build doesn't hold up
+		// other calls
+		a, release, err := s.factory(ctx, req)
+		if err != nil {
+			return nil, "", nil, status.Errorf(codes.FailedPrecondition, "failed to create agent: %v", err)
+		}
+		s.mu.Lock()
+		if existing, found := s.sessions[sessionID]; found {
+			// Another call started the same session first; join it
+			sess = existing
+		} else if !s.makeRoomLocked() {
+			s.mu.Unlock()
+			releaseFunc(release)()
+			return nil, "", nil, errTooManySessions
+		} else {
+			sess = &session{agent: a, release: release}
+			s.sessions[sessionID] = sess
+			release = nil
+		}
+		sess.active++
+		s.mu.Unlock()
+		releaseFunc(release)()
+	}
+
+	done := func() {
+		s.mu.Lock()
+		sess.active--
+		sess.lastUsed = s.now()
+		s.mu.Unlock()
+	}
+	return sess.agent, sessionID, done, nil
+}
+
+// expireSessionsLocked releases sessions idle for longer than the TTL;
+// callers hold s.mu
+func (s *Server) expireSessionsLocked() {
+	now := s.now()
+	for id, sess := range s.sessions {
+		if sess.active == 0 && now.Sub(sess.lastUsed) > s.ttl {
+			releaseFunc(sess.release)()
+			delete(s.sessions, id)
+		}
+	}
+}
+
+var errTooManySessions = status.Error(codes.ResourceExhausted, "too many sessions in use; try again later")
+
+// makeRoomLocked makes room for one more session, dropping the least
+// recently used idle session when the server is full. It reports false when
+// every session is in use; callers hold s.mu.
+func (s *Server) makeRoomLocked() bool {
+	if len(s.sessions) < s.maxSessions {
+		return true
+	}
+	oldestID := ""
+	var oldest *session
+	for id, sess := range s.sessions {
+		if sess.active == 0 && (oldest == nil || sess.lastUsed.Before(oldest.lastUsed)) {
+			oldestID, oldest = id, sess
+		}
+	}
+	if oldest == nil {
+		return false
+	}
+	releaseFunc(oldest.release)()
+	delete(s.sessions, oldestID)
+	return true
+}
+
+// Close releases every session's agent
+func (s *Server) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, sess := range s.sessions {
+		releaseFunc(sess.release)()
+		delete(s.sessions, id)
+	}
+}
+
+func releaseFunc(release func()) func() {
+	if release == nil {
+		return func() {}
+	}
+	return release
+}
+
+// runError maps an agent error to a gRPC status
+func runError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return status.FromContextError(ctxErr).Err()
+	}
+	return status.Errorf(codes.Internal, "query failed: %v", err)
+}
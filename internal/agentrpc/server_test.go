@@ -0,0 +1,322 @@
+package agentrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/agent"
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/proto/agentpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeAgent answers with the prompt and how many prompts it has seen
+type fakeAgent struct {
+	agent.Agent
+
+	mu      sync.Mutex
+	prompts int
+}
+
+func (f *fakeAgent) Query(_ context.Context, prompt string) (*agent.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.prompts++
+	return &agent.Response{
+		Content:      fmt.Sprintf("%s #%d", prompt, f.prompts),
+		Usage:        &llm.Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5},
+		FinishReason: "stop",
+		Iterations:   1,
+		ToolCalls:    []agent.ToolResult{{ID: "c1", Name: "calculate", Result: "4"}},
+	}, nil
+}
+
+func (f *fakeAgent) QueryStream(_ context.Context, prompt string) (<-chan agent.StreamEvent, error) {
+	events := make(chan agent.StreamEvent, 4)
+	events <- agent.StreamEvent{Type: agent.EventTypeMessage, Content: prompt}
+	events <- agent.StreamEvent{Type: agent.EventTypeToolResult, Tool: &agent.ToolEvent{ID: "c1", Name: "calculate", ArgsRaw: `{"input":"2+2"}`, Error: errors.New("boom")}}
+	events <- agent.StreamEvent{Type: agent.EventTypeComplete, FinishReason: "stop"}
+	close(events)
+	return events, nil
+}
+
+type testEnv struct {
+	client  agentpb.AgentServiceClient
+	created func() int
+}
+
+func startServer(t *testing.T, token string, limiter *RateLimiter, clientToken string) testEnv {
+	t.Helper()
+	var mu sync.Mutex
+	created := 0
+	srv := NewServer(func(context.Context, *agentpb.QueryRequest) (agent.Agent, func(), error) {
+		mu.Lock()
+		created++
+		mu.Unlock()
+		return &fakeAgent{}, nil, nil
+	})
+	grpcServer := NewGRPCServer(srv, token, limiter)
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = grpcServer.Serve(lis) }()
+	t.Cleanup(func() {
+		grpcServer.Stop()
+		srv.Close()
+	})
+
+	conn, client, err := Dial("passthrough:///bufnet", clientToken, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	}))
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return testEnv{client: client, created: func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return created
+	}}
+}
+
+func TestServerQuery(t *testing.T) {
+	env := startServer(t, "secret", nil, "secret")
+
+	resp, err := env.client.Query(context.Background(), &agentpb.QueryRequest{Prompt: "hi"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	got := ResponseFromProto(resp)
+	if got.Content != "hi #1" || got.FinishReason != "stop" || got.Usage.TotalTokens != 5 {
+		t.Fatalf("unexpected response: %+v", got)
+	}
+	if len(got.ToolCalls) != 1 || got.ToolCalls[0].Result != "4" {
+		t.Fatalf("unexpected tool calls: %+v", got.ToolCalls)
+	}
+
+	_, err = env.client.Query(context.Background(), &agentpb.QueryRequest{Prompt: "  "})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for an empty prompt, got %v", err)
+	}
+}
+
+func TestServerRejectsBadToken(t *testing.T) {
+	for _, clientToken := range []string{"", "wrong"} {
+		env := startServer(t, "secret", nil, clientToken)
+		_, err := env.client.Query(context.Background(), &agentpb.QueryRequest{Prompt: "hi"})
+		if status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("token %q: expected Unauthenticated, got %v", clientToken, err)
+		}
+
+		stream, err := env.client.Stream(context.Background(), &agentpb.QueryRequest{Prompt: "hi"})
+		if err == nil {
+			_, err = stream.Recv()
+		}
+		if status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("token %q: expected Unauthenticated from Stream, got %v", clientToken, err)
+		}
+		if env.created() != 0 {
+			t.Fatalf("no agent should be built for rejected calls")
+		}
+	}
+}
+
+func TestServerRateLimit(t *testing.T) {
+	env := startServer(t, "", NewRateLimiter(0.001, 2), "")
+
+	for i := 0; i < 2; i++ {
+		if _, err := env.client.Query(context.Background(), &agentpb.QueryRequest{Prompt: "hi"}); err != nil {
+			t.Fatalf("call %d within burst: %v", i+1, err)
+		}
+	}
+	_, err := env.client.Query(context.Background(), &agentpb.QueryRequest{Prompt: "hi"})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted over the limit, got %v", err)
+	}
+}
+
+func TestServerSessions(t *testing.T) {
+	env := startServer(t, "", nil, "")
+	ctx := metadata.AppendToOutgoingContext(context.Background(), SessionMetadataKey, "abc")
+
+	first, err := env.client.Query(ctx, &agentpb.QueryRequest{Prompt: "one"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	second, err := env.client.Query(context.Background(), &agentpb.QueryRequest{Prompt: "two", SessionId: "abc"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if first.GetContent() != "one #1" || second.GetContent() != "two #2" {
+		t.Fatalf("session should keep one agent, got %q then %q", first.GetContent(), second.GetContent())
+	}
+	if second.GetSessionId() != "abc" {
+		t.Fatalf("expected session ID in the response, got %q", second.GetSessionId())
+	}
+
+	stateless, err := env.client.Query(context.Background(), &agentpb.QueryRequest{Prompt: "three"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if stateless.GetContent() != "three #1" || stateless.GetSessionId() != "" {
+		t.Fatalf("calls without a session should get a fresh agent, got %+v", stateless)
+	}
+	if env.created() != 2 {
+		t.Fatalf("expected 2 agents, got %d", env.created())
+	}
+}
+
+func TestServerSessionExpires(t *testing.T) {
+	now := time.Unix(0, 0)
+	created := 0
+	srv := NewServer(func(context.Context, *agentpb.QueryRequest) (agent.Agent, func(), error) {
+		created++
+		return &fakeAgent{}, nil, nil
+	}, WithSessionTTL(time.Minute))
+	srv.now = func() time.Time { return now }
+
+	req := &agentpb.QueryRequest{Prompt: "hi", SessionId: "abc"}
+	if _, err := srv.Query(context.Background(), req); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	now = now.Add(30 * time.Second)
+	if _, err := srv.Query(context.Background(), req); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if created != 1 {
+		t.Fatalf("session should still be live, got %d agents", created)
+	}
+	now = now.Add(2 * time.Minute)
+	resp, err := srv.Query(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if created != 2 || resp.GetContent() != "hi #1" {
+		t.Fatalf("idle session should expire, got %d agents and %q", created, resp.GetContent())
+	}
+}
+
+func TestServerBuildsAgentsOutsideLock(t *testing.T) {
+	slow := make(chan struct{})
+	srv := NewServer(func(_ context.Context, req *agentpb.QueryRequest) (agent.Agent, func(), error) {
+		if req.GetSessionId() == "slow" {
+			<-slow
+		}
+		return &fakeAgent{}, nil, nil
+	})
+	defer srv.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := srv.Query(context.Background(), &agentpb.QueryRequest{Prompt: "hi", SessionId: "slow"})
+		done <- err
+	}()
+
+	// Another session must not wait for the slow build
+	fast := make(chan error, 1)
+	go func() {
+		_, err := srv.Query(context.Background(), &agentpb.QueryRequest{Prompt: "hi", SessionId: "fast"})
+		fast <- err
+	}()
+	select {
+	case err := <-fast:
+		if err != nil {
+			t.Fatalf("Query: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("a slow agent build blocked another session")
+	}
+	close(slow)
+	if err := <-done; err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+}
+
+func TestServerMaxSessions(t *testing.T) {
+	now := time.Unix(0, 0)
+	released := map[string]bool{}
+	srv := NewServer(func(_ context.Context, req *agentpb.QueryRequest) (agent.Agent, func(), error) {
+		id := req.GetSessionId()
+		return &fakeAgent{}, func() { released[id] = true }, nil
+	}, WithMaxSessions(2))
+	srv.now = func() time.Time { return now }
+
+	for _, id := range []string{"a", "b"} {
+		now = now.Add(time.Second)
+		if _, err := srv.Query(context.Background(), &agentpb.QueryRequest{Prompt: "hi", SessionId: id}); err != nil {
+			t.Fatalf("Query: %v", err)
+		}
+	}
+	now = now.Add(time.Second)
+	if _, err := srv.Query(context.Background(), &agentpb.QueryRequest{Prompt: "hi", SessionId: "c"}); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if !released["a"] || released["b"] || len(srv.sessions) != 2 {
+		t.Fatalf("expected the least recently used session to be dropped, released %v", released)
+	}
+
+	// With every session busy there is nothing to drop
+	_, _, doneB, err := srv.acquire(context.Background(), &agentpb.QueryRequest{SessionId: "b"})
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	defer doneB()
+	_, _, doneC, err := srv.acquire(context.Background(), &agentpb.QueryRequest{SessionId: "c"})
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	defer doneC()
+	_, err = srv.Query(context.Background(), &agentpb.QueryRequest{Prompt: "hi", SessionId: "d"})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted, got %v", err)
+	}
+}
+
+func TestStreamEvents(t *testing.T) {
+	env := startServer(t, "", nil, "")
+
+	events, err := StreamEvents(context.Background(), env.client, &agentpb.QueryRequest{Prompt: "hi"})
+	if err != nil {
+		t.Fatalf("StreamEvents: %v", err)
+	}
+	var got []agent.StreamEvent
+	for event := range events {
+		got = append(got, event)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 events, got %+v", got)
+	}
+	if got[0].Type != agent.EventTypeMessage || got[0].Content != "hi" {
+		t.Fatalf("unexpected message event: %+v", got[0])
+	}
+	tool := got[1].Tool
+	if got[1].Type != agent.EventTypeToolResult || tool == nil || tool.Name != "calculate" || tool.ArgsRaw != `{"input":"2+2"}` || tool.Error == nil || tool.Error.Error() != "boom" {
+		t.Fatalf("unexpected tool event: %+v", got[1])
+	}
+	if got[2].Type != agent.EventTypeComplete || got[2].FinishReason != "stop" {
+		t.Fatalf("unexpected complete event: %+v", got[2])
+	}
+}
+
+func TestStreamEventsReportsErrors(t *testing.T) {
+	env := startServer(t, "secret", nil, "")
+
+	events, err := StreamEvents(context.Background(), env.client, &agentpb.QueryRequest{Prompt: "hi"})
+	if err != nil {
+		t.Fatalf("StreamEvents: %v", err)
+	}
+	var last agent.StreamEvent
+	for event := range events {
+		last = event
+	}
+	if last.Type != agent.EventTypeError || status.Code(last.Error) != codes.Unauthenticated {
+		t.Fatalf("expected an Unauthenticated error event, got %+v", last)
+	}
+}
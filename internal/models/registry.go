@@ -46,6 +46,8 @@ var builtInProviderNames = map[string]struct{}{
 	"minmax":     {},
 	"moonshot":   {},
 	"deepseek":   {},
+	"mistral":    {},
+	"cohere":     {},
 	"perplexity": {},
 	"groq":       {},
 	"lmstudio":   {},
@@ -209,6 +211,13 @@ func (r *Registry) MergeLiveModels(provider string, live []llm.Model) []llm.Mode
 				if staticModel.SupportsVision {
 					merged.SupportsVision = true
 				}
+				// Limits reported by the provider take precedence
+				if merged.ContextWindow == 0 {
+					merged.ContextWindow = staticModel.ContextWindow
+				}
+				if merged.MaxOutputTokens == 0 {
+					merged.MaxOutputTokens = staticModel.MaxOutputTokens
+				}
 				index[staticModel.ID] = merged
 			} else {
 				index[staticModel.ID] = staticModel
@@ -305,12 +314,14 @@ func toLLMModel(provider string, def ModelDefinition) llm.Model {
 		description = "Configured via models.json"
 	}
 	return llm.Model{
-		ID:             def.ID,
-		Object:         "model",
-		OwnedBy:        provider,
-		MaxTokens:      def.MaxTokens,
-		Description:    description,
-		SupportsVision: hasImageInput(def),
+		ID:              def.ID,
+		Object:          "model",
+		OwnedBy:         provider,
+		MaxTokens:       def.MaxTokens,
+		Description:     description,
+		SupportsVision:  hasImageInput(def),
+		ContextWindow:   def.ContextWindow,
+		MaxOutputTokens: def.MaxTokens,
 	}
 }
 
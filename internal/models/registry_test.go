@@ -60,18 +60,20 @@ func TestRegistryMergeLiveModels(t *testing.T) {
 	r.providers["lmstudio"] = ProviderConfig{
 		Name: "lmstudio",
 		Models: []ModelDefinition{
-			{ID: "qwen/test", Name: "Static Name", Input: []string{"text"}, MaxTokens: 2048},
+			{ID: "qwen/test", Name: "Static Name", Input: []string{"text"}, ContextWindow: 65536, MaxTokens: 2048},
+			{ID: "live/reported", ContextWindow: 4096},
 		},
 	}
 
 	live := []llm.Model{
 		{ID: "qwen/test", Description: "Live Name", MaxTokens: 1000},
 		{ID: "live/only", Description: "Live Only"},
+		{ID: "live/reported", ContextWindow: 32768},
 	}
 
 	merged := r.MergeLiveModels("lmstudio", live)
-	if len(merged) != 2 {
-		t.Fatalf("expected 2 models, got %d", len(merged))
+	if len(merged) != 3 {
+		t.Fatalf("expected 3 models, got %d", len(merged))
 	}
 
 	var foundStatic bool
@@ -84,6 +86,12 @@ func TestRegistryMergeLiveModels(t *testing.T) {
 			if model.MaxTokens != 2048 {
 				t.Fatalf("expected static maxTokens override, got %d", model.MaxTokens)
 			}
+			if model.ContextWindow != 65536 || model.MaxOutputTokens != 2048 {
+				t.Fatalf("expected static limits to fill the gaps, got context %d output %d", model.ContextWindow, model.MaxOutputTokens)
+			}
+		}
+		if model.ID == "live/reported" && model.ContextWindow != 32768 {
+			t.Fatalf("expected provider-reported context window to win, got %d", model.ContextWindow)
 		}
 	}
 	if !foundStatic {
@@ -0,0 +1,143 @@
+package prompttemplate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/nachoal/simple-agent-go/internal/userpaths"
+)
+
+// Extension is the file extension used for templates in the templates directory.
+const Extension = ".tmpl"
+
+var missingKeyRe = regexp.MustCompile(`map has no entry for key "([^"]*)"`)
+
+// ParseVars turns key=value pairs into a variable map.
+func ParseVars(pairs []string) (map[string]string, error) {
+	vars := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid template variable %q (expected key=value)", pair)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+// Load reads a template by name from ~/.simple-agent/templates, or from the
+// given path when name points at an existing file.
+func Load(name string) (string, error) {
+	dir, err := userpaths.TemplatesDir()
+	if err != nil {
+		return "", err
+	}
+	return LoadFrom(dir, name)
+}
+
+// LoadFrom reads a template by name from dir. The extension is optional.
+func LoadFrom(dir, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("template name is required")
+	}
+
+	candidates := []string{name}
+	if !filepath.IsAbs(name) {
+		candidates = append(candidates, filepath.Join(dir, name))
+		if filepath.Ext(name) != Extension {
+			candidates = append(candidates, filepath.Join(dir, name+Extension))
+		}
+	}
+	for _, path := range candidates {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read template %q: %w", path, err)
+		}
+		return string(data), nil
+	}
+	return "", fmt.Errorf("template %q not found in %s", name, dir)
+}
+
+// LoadNamed reads a template by name from dir only. Unlike LoadFrom it does
+// not accept paths, so callers such as tools cannot read files outside dir.
+func LoadNamed(dir, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("template name is required")
+	}
+	if name != filepath.Base(name) || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("template name %q must not be a path", name)
+	}
+	if filepath.Ext(name) != Extension {
+		name += Extension
+	}
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("template %q not found in %s", strings.TrimSuffix(name, Extension), dir)
+		}
+		return "", fmt.Errorf("failed to read template %q: %w", name, err)
+	}
+	return string(data), nil
+}
+
+// List returns the names of templates in dir without their extension.
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != Extension {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(entry.Name(), Extension))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Render executes text as a text/template with vars as its data. Every
+// variable the template references is required.
+func Render(name, text string, vars map[string]string) (string, error) {
+	out, err := RenderFile(name, text, vars)
+	return strings.TrimSpace(out), err
+}
+
+// RenderFile is Render without trimming the output, for templates rendered
+// into files, where leading indentation and the final newline matter.
+func RenderFile(name, text string, vars map[string]string) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %q: %w", name, err)
+	}
+	if vars == nil {
+		vars = map[string]string{}
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, vars); err != nil {
+		if match := missingKeyRe.FindStringSubmatch(err.Error()); match != nil {
+			return "", fmt.Errorf("template %q requires variable %q (pass %s=<value>)", name, match[1], match[1])
+		}
+		return "", fmt.Errorf("failed to render template %q: %w", name, err)
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,66 @@
+package prompttemplate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRender_SubstitutesVars(t *testing.T) {
+	vars, err := ParseVars([]string{"file=main.go", "focus=error handling"})
+	if err != nil {
+		t.Fatalf("ParseVars: %v", err)
+	}
+
+	out, err := Render("review", "Review {{.file}} with a focus on {{.focus}}.\n", vars)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if out != "Review main.go with a focus on error handling." {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+func TestRender_MissingRequiredVar(t *testing.T) {
+	_, err := Render("review", "Review {{.file}}", map[string]string{"other": "x"})
+	if err == nil {
+		t.Fatalf("expected error for missing variable")
+	}
+	if !strings.Contains(err.Error(), `requires variable "file"`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestParseVars_RejectsMalformedPairs(t *testing.T) {
+	if _, err := ParseVars([]string{"novalue"}); err == nil {
+		t.Fatalf("expected error for pair without '='")
+	}
+	vars, err := ParseVars([]string{"query=a=b"})
+	if err != nil || vars["query"] != "a=b" {
+		t.Fatalf("expected value to keep later '=' characters, got %v (%v)", vars, err)
+	}
+}
+
+func TestLoadFrom_ResolvesNameWithoutExtension(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "commit.tmpl"), []byte("Write a commit message for {{.diff}}"), 0644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+
+	text, err := LoadFrom(dir, "commit")
+	if err != nil {
+		t.Fatalf("LoadFrom: %v", err)
+	}
+	if !strings.Contains(text, "{{.diff}}") {
+		t.Fatalf("unexpected template text: %q", text)
+	}
+	if _, err := LoadFrom(dir, "missing"); err == nil {
+		t.Fatalf("expected error for missing template")
+	}
+
+	names, err := List(dir)
+	if err != nil || len(names) != 1 || names[0] != "commit" {
+		t.Fatalf("unexpected template list: %v (%v)", names, err)
+	}
+}
@@ -1,27 +1,55 @@
 package toolinit
 
 import (
+	"os"
+	"strconv"
+	"strings"
+
 	"github.com/nachoal/simple-agent-go/tools"
 	"github.com/nachoal/simple-agent-go/tools/registry"
 )
 
-// RegisterAll registers all built-in tools
+// workdir is the root the file tools are confined to, and where bash runs;
+// empty means the current working directory.
+var workdir string
+
+// SetWorkdir confines the file tools created after this call to dir and runs
+// bash commands there.
+func SetWorkdir(dir string) {
+	workdir = dir
+}
+
+// RegisterAll registers all built-in tools. Tools missing their credentials,
+// such as google_search without GOOGLE_API_KEY, are not advertised to the
+// model unless SIMPLE_AGENT_SHOW_UNAVAILABLE_TOOLS is set.
+// SIMPLE_AGENT_TOOL_MAX_OUTPUT_BYTES changes how much output a tool without
+// its own limit may return to the model; a negative value turns the limit off.
 func RegisterAll() {
+	v := os.Getenv("SIMPLE_AGENT_SHOW_UNAVAILABLE_TOOLS")
+	registry.SetShowUnavailable(v == "1" || strings.EqualFold(v, "true"))
+	if limit, err := strconv.Atoi(strings.TrimSpace(os.Getenv("SIMPLE_AGENT_TOOL_MAX_OUTPUT_BYTES"))); err == nil {
+		registry.SetOutputLimit(limit)
+	}
+
 	// File operations
 	registry.Register("read", func() tools.Tool {
-		return tools.NewReadTool()
+		return tools.NewReadTool(tools.WithRoot(workdir))
 	})
 
 	registry.Register("write", func() tools.Tool {
-		return tools.NewWriteTool()
+		return tools.NewWriteTool(tools.WithRoot(workdir))
 	})
 
 	registry.Register("edit", func() tools.Tool {
-		return tools.NewEditTool()
+		return tools.NewEditTool(tools.WithRoot(workdir))
 	})
 
 	registry.Register("directory_list", func() tools.Tool {
-		return tools.NewDirectoryListTool()
+		return tools.NewDirectoryListTool(tools.WithRoot(workdir))
+	})
+
+	registry.Register("scaffold", func() tools.Tool {
+		return tools.NewScaffoldTool(tools.WithRoot(workdir))
 	})
 
 	// Utility tools
@@ -30,7 +58,27 @@ func RegisterAll() {
 	})
 
 	registry.Register("bash", func() tools.Tool {
-		return tools.NewBashTool()
+		return tools.NewBashTool(tools.WithWorkdir(workdir))
+	})
+
+	registry.Register("git", func() tools.Tool {
+		return tools.NewGitTool(tools.WithRoot(workdir))
+	})
+
+	registry.Register("sqlite_query", func() tools.Tool {
+		return tools.NewSQLiteTool(tools.WithRoot(workdir))
+	})
+
+	registry.Register("file_structured", func() tools.Tool {
+		return tools.NewStructuredFileTool(tools.WithRoot(workdir))
+	})
+
+	registry.Register("diff", func() tools.Tool {
+		return tools.NewDiffTool(tools.WithRoot(workdir))
+	})
+
+	registry.Register("clipboard_read", func() tools.Tool {
+		return tools.NewClipboardReadTool()
 	})
 
 	// Search tools
@@ -42,6 +90,31 @@ func RegisterAll() {
 		return tools.NewGoogleSearchTool()
 	})
 
+	registry.Register("arxiv", func() tools.Tool {
+		return tools.NewArXivTool()
+	})
+
+	// Web tools
+	registry.Register("http_fetch", func() tools.Tool {
+		return tools.NewHTTPFetchTool()
+	})
+
+	registry.Register("web_fetch", func() tools.Tool {
+		return tools.NewWebFetchTool()
+	})
+
+	registry.Register("feed", func() tools.Tool {
+		return tools.NewFeedTool()
+	})
+
+	registry.Register("http_request", func() tools.Tool {
+		return tools.NewHTTPRequestTool()
+	})
+
+	registry.Register("summarize", func() tools.Tool {
+		return tools.NewSummarizeTool(tools.WithRoot(workdir))
+	})
+
 	// Demo tool for testing
 	// Temporarily disabled due to schema issues
 	// registry.Register("demo_tool", func() tools.Tool {
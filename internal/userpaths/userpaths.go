@@ -9,9 +9,10 @@ import (
 )
 
 const (
-	configDirName  = ".simple-agent"
-	agentDirName   = "agent"
-	harnessDirName = "harness"
+	configDirName    = ".simple-agent"
+	agentDirName     = "agent"
+	harnessDirName   = "harness"
+	templatesDirName = "templates"
 )
 
 // ConfigDir returns ~/.simple-agent and ensures it exists.
@@ -72,3 +73,18 @@ func repoSlug(repoRoot string) string {
 	_, _ = hasher.Write([]byte(filepath.Clean(repoRoot)))
 	return fmt.Sprintf("%s-%08x", base, hasher.Sum32())
 }
+
+// TemplatesDir returns ~/.simple-agent/templates and ensures it exists.
+func TemplatesDir() (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(configDir, templatesDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create templates directory %q: %w", dir, err)
+	}
+
+	return dir, nil
+}
@@ -1,13 +1,14 @@
 package anthropic
 
 import (
-	"bufio"
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -28,6 +29,9 @@ type Client struct {
 	httpClient *http.Client
 }
 
+// Claude 3 and later models accept image content blocks.
+var _ llm.MultimodalClient = (*Client)(nil)
+
 // AnthropicMessage represents a message in Anthropic's format
 type AnthropicMessage struct {
 	Role    string      `json:"role"`
@@ -70,13 +74,54 @@ type AnthropicResponse struct {
 
 // AnthropicContentBlock represents a content block in the response
 type AnthropicContentBlock struct {
-	Type    string          `json:"type"`
-	Text    string          `json:"text,omitempty"`
-	ID      string          `json:"id,omitempty"`
-	Name    string          `json:"name,omitempty"`
-	Input   json.RawMessage `json:"input,omitempty"`
-	ToolUse string          `json:"tool_use_id,omitempty"`
-	Content string          `json:"content,omitempty"`
+	Type    string                `json:"type"`
+	Text    string                `json:"text,omitempty"`
+	ID      string                `json:"id,omitempty"`
+	Name    string                `json:"name,omitempty"`
+	Input   json.RawMessage       `json:"input,omitempty"`
+	ToolUse string                `json:"tool_use_id,omitempty"`
+	Content string                `json:"content,omitempty"`
+	Source  *AnthropicImageSource `json:"source,omitempty"`
+}
+
+// AnthropicImageSource is the inline data of an image content block
+type AnthropicImageSource struct {
+	Type      string `json:"type"` // Always "base64"
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
+}
+
+// anthropicStreamEvent is one server-sent event of a streamed response
+type anthropicStreamEvent struct {
+	Type         string                 `json:"type"`
+	Index        int                    `json:"index"`
+	Message      *AnthropicResponse     `json:"message,omitempty"`       // message_start
+	ContentBlock *AnthropicContentBlock `json:"content_block,omitempty"` // content_block_start
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text,omitempty"`         // text_delta
+		PartialJSON string `json:"partial_json,omitempty"` // input_json_delta
+		StopReason  string `json:"stop_reason,omitempty"`  // message_delta
+	} `json:"delta"`
+	Usage *AnthropicUsage `json:"usage,omitempty"` // message_delta
+}
+
+// streamingToolUse collects a tool_use block's input as it streams in
+type streamingToolUse struct {
+	id, name string
+	input    strings.Builder
+}
+
+func (t *streamingToolUse) toolCall() llm.ToolCall {
+	input := strings.TrimSpace(t.input.String())
+	if input == "" {
+		input = "{}"
+	}
+	return llm.ToolCall{
+		ID:       t.id,
+		Type:     "function",
+		Function: llm.FunctionCall{Name: t.name, Arguments: json.RawMessage(input)},
+	}
 }
 
 // AnthropicUsage represents token usage
@@ -255,74 +300,97 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest) (<-ch
 		defer close(events)
 		defer resp.Body.Close()
 
-		scanner := bufio.NewScanner(resp.Body)
-		var currentMessage strings.Builder
+		reader := llm.NewSSEReader(resp.Body)
+		id, model := "", anthropicReq.Model
+		var usage llm.Usage
+		toolUses := make(map[int]*streamingToolUse)
+		finished := false
 
-		for scanner.Scan() {
-			line := scanner.Text()
+		send := func(choice llm.Choice, usage *llm.Usage) bool {
+			select {
+			case events <- llm.StreamEvent{
+				ID:      id,
+				Object:  "chat.completion.chunk",
+				Created: time.Now().Unix(),
+				Model:   model,
+				Choices: []llm.Choice{choice},
+				Usage:   usage,
+			}:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
 
-			// Skip empty lines
-			if line == "" {
-				continue
+		for {
+			data, err := reader.Next()
+			if err != nil {
+				return
 			}
 
 			// Parse SSE event
-			if strings.HasPrefix(line, "data: ") {
-				data := strings.TrimPrefix(line, "data: ")
+			var event anthropicStreamEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				llm.DebugSkippedEvent("Anthropic", data, err)
+				continue
+			}
 
-				var event map[string]interface{}
-				if err := json.Unmarshal([]byte(data), &event); err != nil {
-					continue
+			// Convert Anthropic stream event to standard format
+			switch event.Type {
+			case "message_start":
+				if event.Message != nil {
+					id = event.Message.ID
+					if event.Message.Model != "" {
+						model = event.Message.Model
+					}
+					usage.PromptTokens = event.Message.Usage.InputTokens
 				}
-
-				// Convert Anthropic stream event to standard format
-				if event["type"] == "content_block_delta" {
-					delta, _ := event["delta"].(map[string]interface{})
-					if text, ok := delta["text"].(string); ok {
-						currentMessage.WriteString(text)
-
-						streamEvent := llm.StreamEvent{
-							ID:      event["id"].(string),
-							Object:  "chat.completion.chunk",
-							Created: time.Now().Unix(),
-							Model:   anthropicReq.Model,
-							Choices: []llm.Choice{
-								{
-									Index: 0,
-									Delta: &llm.Message{
-										Content: llm.StringPtr(text),
-									},
-								},
-							},
-						}
-
-						select {
-						case events <- streamEvent:
-						case <-ctx.Done():
-							return
-						}
+			case "content_block_start":
+				if block := event.ContentBlock; block != nil && block.Type == "tool_use" {
+					toolUses[event.Index] = &streamingToolUse{id: block.ID, name: block.Name}
+				}
+			case "content_block_delta":
+				switch event.Delta.Type {
+				case "input_json_delta":
+					if toolUse, ok := toolUses[event.Index]; ok {
+						toolUse.input.WriteString(event.Delta.PartialJSON)
 					}
-				} else if event["type"] == "message_stop" {
-					// Send final event with finish reason
-					streamEvent := llm.StreamEvent{
-						ID:      event["id"].(string),
-						Object:  "chat.completion.chunk",
-						Created: time.Now().Unix(),
-						Model:   anthropicReq.Model,
-						Choices: []llm.Choice{
-							{
-								Index:        0,
-								FinishReason: "stop",
-							},
-						},
+				default:
+					if event.Delta.Text == "" {
+						continue
 					}
-
-					select {
-					case events <- streamEvent:
-					case <-ctx.Done():
+					if !send(llm.Choice{Delta: &llm.Message{Content: llm.StringPtr(event.Delta.Text)}}, nil) {
 						return
 					}
 				}
+			case "content_block_stop":
+				// A tool call is sent whole once its input is complete.
+				toolUse, ok := toolUses[event.Index]
+				if !ok {
+					continue
+				}
+				delete(toolUses, event.Index)
+				delta := &llm.Message{Role: llm.RoleAssistant, ToolCalls: []llm.ToolCall{toolUse.toolCall()}}
+				if !send(llm.Choice{Delta: delta}, nil) {
+					return
+				}
+			case "message_delta":
+				if event.Usage != nil {
+					usage.CompletionTokens = event.Usage.OutputTokens
+				}
+				usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+				finished = true
+				final := usage
+				if !send(llm.Choice{FinishReason: finishReason(event.Delta.StopReason)}, &final) {
+					return
+				}
+			case "message_stop":
+				// message_delta normally carries the stop reason; without one
+				// the response still ended normally.
+				if !finished && !send(llm.Choice{FinishReason: "stop"}, nil) {
+					return
+				}
+				finished = true
 			}
 		}
 	}()
@@ -330,10 +398,67 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest) (<-ch
 	return events, nil
 }
 
-// ListModels returns available Anthropic models
+// maxModelPages caps how many pages ListModels follows, so a server that
+// keeps reporting has_more cannot loop forever.
+const maxModelPages = 20
+
+// modelsPage is one page of the models endpoint
+type modelsPage struct {
+	Data []struct {
+		ID          string `json:"id"`
+		Type        string `json:"type"`
+		DisplayName string `json:"display_name"`
+		CreatedAt   string `json:"created_at"`
+	} `json:"data"`
+	HasMore bool   `json:"has_more"`
+	FirstID string `json:"first_id"`
+	LastID  string `json:"last_id"`
+}
+
+// ListModels returns available Anthropic models, following the endpoint's
+// pagination with after_id until has_more is false
 func (c *Client) ListModels(ctx context.Context) ([]llm.Model, error) {
+	var models []llm.Model
+	afterID := ""
+	for page := 0; page < maxModelPages; page++ {
+		response, err := c.listModelsPage(ctx, afterID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Convert to llm.Model format
+		for _, m := range response.Data {
+			model := llm.Model{
+				ID:          m.ID,
+				Object:      "model",
+				OwnedBy:     "anthropic",
+				Description: m.DisplayName,
+			}
+			// Parse created_at timestamp if needed
+			if t, err := time.Parse(time.RFC3339, m.CreatedAt); err == nil {
+				model.Created = t.Unix()
+			}
+			models = append(models, model)
+		}
+
+		if !response.HasMore || response.LastID == "" || response.LastID == afterID {
+			break
+		}
+		afterID = response.LastID
+	}
+
+	return models, nil
+}
+
+func (c *Client) listModelsPage(ctx context.Context, afterID string) (*modelsPage, error) {
+	query := url.Values{}
+	query.Set("limit", "1000")
+	if afterID != "" {
+		query.Set("after_id", afterID)
+	}
+
 	// Create request for models endpoint
-	req, err := http.NewRequestWithContext(ctx, "GET", c.options.BaseURL+"/models", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", c.options.BaseURL+"/models?"+query.Encode(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -354,40 +479,11 @@ func (c *Client) ListModels(ctx context.Context) ([]llm.Model, error) {
 		return nil, fmt.Errorf("Anthropic API error: status %d, body: %s", resp.StatusCode, string(body))
 	}
 
-	// Parse response
-	var response struct {
-		Data []struct {
-			ID          string `json:"id"`
-			Type        string `json:"type"`
-			DisplayName string `json:"display_name"`
-			CreatedAt   string `json:"created_at"`
-		} `json:"data"`
-		HasMore bool   `json:"has_more"`
-		FirstID string `json:"first_id"`
-		LastID  string `json:"last_id"`
-	}
-
+	var response modelsPage
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
-
-	// Convert to llm.Model format
-	models := make([]llm.Model, 0, len(response.Data))
-	for _, m := range response.Data {
-		model := llm.Model{
-			ID:          m.ID,
-			Object:      "model",
-			OwnedBy:     "anthropic",
-			Description: m.DisplayName,
-		}
-		// Parse created_at timestamp if needed
-		if t, err := time.Parse(time.RFC3339, m.CreatedAt); err == nil {
-			model.Created = t.Unix()
-		}
-		models = append(models, model)
-	}
-
-	return models, nil
+	return &response, nil
 }
 
 // GetModel returns details about a specific model
@@ -525,6 +621,19 @@ func (c *Client) convertRequest(req *llm.ChatRequest) *AnthropicRequest {
 	return anthropicReq
 }
 
+// finishReason maps an Anthropic stop_reason to its OpenAI-style
+// finish_reason
+func finishReason(stopReason string) string {
+	switch stopReason {
+	case "tool_use":
+		return "tool_calls"
+	case "max_tokens":
+		return "length"
+	default:
+		return "stop"
+	}
+}
+
 // convertResponse converts from Anthropic format to standard format
 func (c *Client) convertResponse(resp *AnthropicResponse) *llm.ChatResponse {
 	// Build message content and tool calls
@@ -547,14 +656,6 @@ func (c *Client) convertResponse(resp *AnthropicResponse) *llm.ChatResponse {
 		}
 	}
 
-	// Determine finish reason
-	finishReason := "stop"
-	if resp.StopReason == "tool_use" {
-		finishReason = "tool_calls"
-	} else if resp.StopReason == "max_tokens" {
-		finishReason = "length"
-	}
-
 	return &llm.ChatResponse{
 		ID:      resp.ID,
 		Object:  "chat.completion",
@@ -568,7 +669,7 @@ func (c *Client) convertResponse(resp *AnthropicResponse) *llm.ChatResponse {
 					Content:   llm.StringPtr(content.String()),
 					ToolCalls: toolCalls,
 				},
-				FinishReason: finishReason,
+				FinishReason: finishReason(resp.StopReason),
 			},
 		},
 		Usage: &llm.Usage{
@@ -585,6 +686,9 @@ func (c *Client) doWithRetries(ctx context.Context, fn func() error) error {
 
 	for i := 0; i <= c.options.MaxRetries; i++ {
 		if i > 0 {
+			if !llm.AllowRetry(ctx) {
+				return fmt.Errorf("retry budget exhausted: %w", lastErr)
+			}
 			// Exponential backoff
 			delay := time.Duration(i) * time.Second
 			select {
@@ -611,3 +715,133 @@ func (c *Client) doWithRetries(ctx context.Context, fn func() error) error {
 
 	return fmt.Errorf("max retries exceeded: %w", lastErr)
 }
+
+// --- Multimodal helpers ---
+
+// buildImageRequest creates a single-turn request whose user message holds
+// one base64 image block per image followed by the prompt as a text block.
+// Images are placed first, as Anthropic recommends.
+func (c *Client) buildImageRequest(prompt string, imagePaths []string, opts map[string]interface{}, stream bool) (*AnthropicRequest, error) {
+	var content []AnthropicContentBlock
+	for _, p := range imagePaths {
+		data, mime, err := llm.PrepareImage(p, c.options.ImageMaxDimension)
+		if err != nil {
+			return nil, fmt.Errorf("encode image %s: %w", p, err)
+		}
+		content = append(content, AnthropicContentBlock{
+			Type: "image",
+			Source: &AnthropicImageSource{
+				Type:      "base64",
+				MediaType: mime,
+				Data:      base64.StdEncoding.EncodeToString(data),
+			},
+		})
+	}
+	content = append(content, AnthropicContentBlock{Type: "text", Text: prompt})
+
+	request := &llm.ChatRequest{Stream: stream}
+	// Lightweight handling of common opts
+	if v, ok := opts["max_tokens"].(int); ok {
+		request.MaxTokens = v
+	}
+	if v, ok := opts["temperature"].(float64); ok {
+		request.Temperature = float32(v)
+	}
+
+	anthropicReq := c.convertRequest(request)
+	anthropicReq.Messages = []AnthropicMessage{{Role: "user", Content: content}}
+	return anthropicReq, nil
+}
+
+// postImageRequest sends an image request and returns the response once the
+// API has accepted it.
+func (c *Client) postImageRequest(anthropicReq *AnthropicRequest) (*http.Response, error) {
+	body, err := json.Marshal(anthropicReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", c.options.BaseURL+"/messages", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.setHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+	if anthropicReq.Stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("Anthropic API error: status %d, body: %s", resp.StatusCode, string(b))
+	}
+	return resp, nil
+}
+
+// ChatWithImages sends a prompt + images to a Claude model
+func (c *Client) ChatWithImages(prompt string, imagePaths []string, opts map[string]interface{}) (string, error) {
+	anthropicReq, err := c.buildImageRequest(prompt, imagePaths, opts, false)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.postImageRequest(anthropicReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var out AnthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	return llm.GetStringValue(c.convertResponse(&out).Choices[0].Message.Content), nil
+}
+
+// StreamChatWithImages streams text chunks for prompt + images
+func (c *Client) StreamChatWithImages(prompt string, imagePaths []string, opts map[string]interface{}) (<-chan string, error) {
+	anthropicReq, err := c.buildImageRequest(prompt, imagePaths, opts, true)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.postImageRequest(anthropicReq)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		defer resp.Body.Close()
+		reader := llm.NewSSEReader(resp.Body)
+		for {
+			data, err := reader.Next()
+			if err != nil {
+				return
+			}
+			var event struct {
+				Type  string `json:"type"`
+				Delta struct {
+					Text string `json:"text"`
+				} `json:"delta"`
+			}
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				llm.DebugSkippedEvent("Anthropic", data, err)
+				continue
+			}
+			switch event.Type {
+			case "content_block_delta":
+				if event.Delta.Text != "" {
+					ch <- event.Delta.Text
+				}
+			case "message_stop":
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
@@ -0,0 +1,217 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+func TestListModelsFollowsPagination(t *testing.T) {
+	pages := map[string]string{
+		"":                         "models_page1.json",
+		"claude-sonnet-4-20250514": "models_page2.json",
+	}
+	var afterIDs []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		afterID := r.URL.Query().Get("after_id")
+		afterIDs = append(afterIDs, afterID)
+		fixture, ok := pages[afterID]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			t.Errorf("read fixture: %v", err)
+		}
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(llm.WithAPIKey("test-key"), llm.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	models, err := client.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels: %v", err)
+	}
+
+	if len(afterIDs) != 2 || afterIDs[1] != "claude-sonnet-4-20250514" {
+		t.Fatalf("expected a second request after the first page's last_id, got %q", afterIDs)
+	}
+	want := []string{"claude-opus-4-1-20250805", "claude-sonnet-4-20250514", "claude-3-5-haiku-20241022"}
+	if len(models) != len(want) {
+		t.Fatalf("expected %d models, got %+v", len(want), models)
+	}
+	for i, id := range want {
+		if models[i].ID != id {
+			t.Fatalf("model %d: expected %s, got %s", i, id, models[i].ID)
+		}
+	}
+	if models[2].Description != "Claude Haiku 3.5" || models[2].Created == 0 {
+		t.Fatalf("expected display name and created time on later pages, got %+v", models[2])
+	}
+}
+
+func TestChatWithImagesSendsImageBlocks(t *testing.T) {
+	var got struct {
+		Model     string `json:"model"`
+		MaxTokens int    `json:"max_tokens"`
+		Stream    bool   `json:"stream"`
+		Messages  []struct {
+			Role    string                  `json:"role"`
+			Content []AnthropicContentBlock `json:"content"`
+		} `json:"messages"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/messages" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if got.Stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Write([]byte("event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"A red \"}}\n\n"))
+			w.Write([]byte("event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"pixel.\"}}\n\n"))
+			w.Write([]byte("event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n"))
+			return
+		}
+		w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"A red pixel."}],"model":"claude-sonnet-4-20250514","stop_reason":"end_turn","usage":{"input_tokens":20,"output_tokens":4}}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(llm.WithAPIKey("test-key"), llm.WithBaseURL(srv.URL), llm.WithModel("claude-sonnet-4-20250514"))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	fixture := filepath.Join("testdata", "pixel.png")
+
+	checkRequest := func(stream bool) {
+		t.Helper()
+		if got.Model != "claude-sonnet-4-20250514" || got.MaxTokens == 0 || got.Stream != stream {
+			t.Fatalf("unexpected request: %+v", got)
+		}
+		if len(got.Messages) != 1 || got.Messages[0].Role != "user" || len(got.Messages[0].Content) != 2 {
+			t.Fatalf("expected one user message with an image and a text block, got %+v", got.Messages)
+		}
+		image, text := got.Messages[0].Content[0], got.Messages[0].Content[1]
+		if image.Type != "image" || image.Source == nil || image.Source.Type != "base64" || image.Source.MediaType != "image/png" {
+			t.Fatalf("expected a base64 PNG image block first, got %+v", image)
+		}
+		data, err := base64.StdEncoding.DecodeString(image.Source.Data)
+		if err != nil {
+			t.Fatalf("image data is not base64: %v", err)
+		}
+		if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+			t.Fatalf("image data is not a PNG: %v", err)
+		}
+		if text.Type != "text" || text.Text != "What color is this?" {
+			t.Fatalf("expected the prompt as a text block, got %+v", text)
+		}
+	}
+
+	out, err := client.ChatWithImages("What color is this?", []string{fixture}, nil)
+	if err != nil {
+		t.Fatalf("ChatWithImages: %v", err)
+	}
+	if out != "A red pixel." {
+		t.Fatalf("expected the reply text, got %q", out)
+	}
+	checkRequest(false)
+
+	chunks, err := client.StreamChatWithImages("What color is this?", []string{fixture}, nil)
+	if err != nil {
+		t.Fatalf("StreamChatWithImages: %v", err)
+	}
+	var streamed string
+	for chunk := range chunks {
+		streamed += chunk
+	}
+	if streamed != "A red pixel." {
+		t.Fatalf("expected the streamed reply, got %q", streamed)
+	}
+	checkRequest(true)
+}
+
+func TestChatStreamEmitsToolUseBlocks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := os.ReadFile(filepath.Join("testdata", "stream_tool_use.sse"))
+		if err != nil {
+			t.Errorf("read fixture: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(llm.WithAPIKey("test-key"), llm.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	stream, err := client.ChatStream(context.Background(), &llm.ChatRequest{
+		Messages: []llm.Message{{Role: llm.RoleUser, Content: llm.StringPtr("Weather in SF?")}},
+	})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+
+	var text string
+	var calls []llm.ToolCall
+	var finish string
+	var usage *llm.Usage
+	for event := range stream {
+		if event.ID != "msg_01XFDUDYJgAACzvnptvVoYEL" || event.Model != "claude-sonnet-4-20250514" {
+			t.Fatalf("expected the message_start id and model on every event, got %q %q", event.ID, event.Model)
+		}
+		if event.Usage != nil {
+			usage = event.Usage
+		}
+		for _, choice := range event.Choices {
+			if choice.FinishReason != "" {
+				finish = choice.FinishReason
+			}
+			if choice.Delta == nil {
+				continue
+			}
+			text += llm.GetStringValue(choice.Delta.Content)
+			calls = append(calls, choice.Delta.ToolCalls...)
+		}
+	}
+
+	if text != "Okay, let's check the weather." {
+		t.Fatalf("unexpected text %q", text)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 tool calls, got %+v", calls)
+	}
+	if calls[0].ID != "toolu_01T1x1fJ34qAmk2tNTrN7Up6" || calls[0].Function.Name != "get_weather" {
+		t.Fatalf("unexpected first tool call %+v", calls[0])
+	}
+	var args struct{ Location string }
+	if err := json.Unmarshal(calls[0].Function.Arguments, &args); err != nil || args.Location != "San Francisco, CA" {
+		t.Fatalf("expected the input_json_delta fragments joined, got %s (%v)", calls[0].Function.Arguments, err)
+	}
+	if calls[1].Function.Name != "get_time" || string(calls[1].Function.Arguments) != "{}" {
+		t.Fatalf("expected a tool call without input to get {}, got %+v", calls[1])
+	}
+	if finish != "tool_calls" {
+		t.Fatalf("expected the tool_use stop reason as tool_calls, got %q", finish)
+	}
+	if usage == nil || usage.PromptTokens != 472 || usage.CompletionTokens != 89 || usage.TotalTokens != 561 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+}
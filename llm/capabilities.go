@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"math"
+	"strings"
+)
+
+// Capabilities is a set of features a model supports
+type Capabilities uint8
+
+const (
+	// CapabilityTools means the model accepts tool schemas and returns tool calls
+	CapabilityTools Capabilities = 1 << iota
+	// CapabilityVision means the model accepts image inputs
+	CapabilityVision
+)
+
+// Has reports whether c includes every capability in caps
+func (c Capabilities) Has(caps Capabilities) bool {
+	return c&caps == caps
+}
+
+// noToolModels lists model ID prefixes known to reject tool calling. Chat
+// models are assumed to support tools unless they match one of these.
+var noToolModels = []string{
+	"o1-mini",
+	"o1-preview",
+	"gpt-3.5-turbo-instruct",
+	"babbage",
+	"davinci",
+	"text-embedding",
+	"dall-e",
+	"whisper",
+	"tts",
+	"deepseek-reasoner",
+	"llama-3.1-sonar",
+	"gemma",
+	"llama2",
+	"codellama",
+}
+
+// ModelCapabilities returns what model supports: vision when the provider or
+// models.json says so, and tools unless the model is in the table of models
+// without tool calling.
+func ModelCapabilities(model Model) Capabilities {
+	var caps Capabilities
+	id := strings.ToLower(model.ID)
+	// Provider-qualified IDs such as "google/gemma-2-9b" match on the model name.
+	if i := strings.LastIndex(id, "/"); i >= 0 {
+		id = id[i+1:]
+	}
+	caps |= CapabilityTools
+	for _, prefix := range noToolModels {
+		if strings.HasPrefix(id, prefix) {
+			caps &^= CapabilityTools
+			break
+		}
+	}
+	if model.SupportsVision {
+		caps |= CapabilityVision
+	}
+	return caps
+}
+
+// CheapestWithCapabilities returns a model selector that picks the cheapest
+// of the models supporting caps, comparing input plus output price per
+// million tokens. Models without a price are only chosen when none of the
+// capable models has one. The selector returns the zero Model when no model
+// qualifies.
+func CheapestWithCapabilities(caps Capabilities) func([]Model) Model {
+	return func(models []Model) Model {
+		var best, firstCapable Model
+		bestPrice := math.Inf(1)
+		for _, model := range models {
+			if !ModelCapabilities(model).Has(caps) {
+				continue
+			}
+			if firstCapable.ID == "" {
+				firstCapable = model
+			}
+			if !model.Priced() {
+				continue
+			}
+			if price := model.InputPricePerMTok + model.OutputPricePerMTok; price < bestPrice {
+				best, bestPrice = model, price
+			}
+		}
+		if best.ID == "" {
+			return firstCapable
+		}
+		return best
+	}
+}
+
+// Priced reports whether the model has a known price
+func (m Model) Priced() bool {
+	return m.InputPricePerMTok > 0 || m.OutputPricePerMTok > 0
+}
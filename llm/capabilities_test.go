@@ -0,0 +1,33 @@
+package llm
+
+import "testing"
+
+func TestCheapestWithCapabilitiesSkipsModelsWithoutTools(t *testing.T) {
+	models := []Model{
+		{ID: "gpt-4o", InputPricePerMTok: 2.50, OutputPricePerMTok: 10.00},
+		{ID: "gpt-3.5-turbo-instruct", InputPricePerMTok: 0.05, OutputPricePerMTok: 0.10},
+		{ID: "gpt-4o-mini", InputPricePerMTok: 0.15, OutputPricePerMTok: 0.60},
+		{ID: "local-model"},
+	}
+
+	got := CheapestWithCapabilities(CapabilityTools)(models)
+	if got.ID != "gpt-4o-mini" {
+		t.Fatalf("expected gpt-4o-mini, got %q", got.ID)
+	}
+
+	vision := CheapestWithCapabilities(CapabilityTools | CapabilityVision)(models)
+	if vision.ID != "" {
+		t.Fatalf("expected no vision model, got %q", vision.ID)
+	}
+}
+
+func TestCheapestWithCapabilitiesFallsBackToUnpricedModel(t *testing.T) {
+	models := []Model{
+		{ID: "gemma-2b"},
+		{ID: "llama3.1:8b"},
+		{ID: "qwen2.5"},
+	}
+	if got := CheapestWithCapabilities(CapabilityTools)(models); got.ID != "llama3.1:8b" {
+		t.Fatalf("expected the first tool-capable model, got %q", got.ID)
+	}
+}
@@ -0,0 +1,259 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/llm/fallback"
+)
+
+// ErrCircuitOpen is returned without contacting the provider while the
+// circuit is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+var errImagesUnsupported = errors.New("this provider client does not support images")
+
+// CircuitState is the state of a CircuitBreaker
+type CircuitState int
+
+const (
+	// Closed passes every request through
+	Closed CircuitState = iota
+	// Open rejects requests with ErrCircuitOpen until ResetTimeout elapses
+	Open
+	// HalfOpen lets a single trial request through to decide whether to
+	// close the circuit again
+	HalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// Default settings used for zero Config fields
+const (
+	DefaultThreshold    = 5
+	DefaultWindow       = time.Minute
+	DefaultResetTimeout = 30 * time.Second
+)
+
+// Config controls when a CircuitBreaker opens and how long it stays open
+type Config struct {
+	Threshold    int           // Consecutive failures that open the circuit
+	Window       time.Duration // Failures older than this no longer count toward Threshold
+	ResetTimeout time.Duration // How long the circuit stays open before a trial request
+}
+
+// CircuitMetrics counts what a CircuitBreaker has seen
+type CircuitMetrics struct {
+	Requests            int // Calls that reached the provider
+	Successes           int
+	Failures            int
+	Rejected            int // Calls refused with ErrCircuitOpen
+	ConsecutiveFailures int // Failures currently counting toward Threshold
+	Opens               int // Times the circuit has opened
+}
+
+// CircuitBreaker wraps an llm.Client and stops sending it requests after
+// repeated failures, so a provider that is down fails fast instead of
+// timing out on every call. Chat and ChatStream are guarded; ChatStream
+// counts only failures to open the stream. Errors caused by the caller's
+// context being cancelled are not counted.
+type CircuitBreaker struct {
+	client llm.Client
+	config Config
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures []time.Time // consecutive failures within Window
+	openedAt time.Time
+	trial    bool // a half-open trial request is in flight
+	metrics  CircuitMetrics
+
+	now func() time.Time
+}
+
+// NewCircuitBreaker wraps client. Zero fields in config take the defaults.
+func NewCircuitBreaker(client llm.Client, config Config) *CircuitBreaker {
+	if config.Threshold <= 0 {
+		config.Threshold = DefaultThreshold
+	}
+	if config.Window <= 0 {
+		config.Window = DefaultWindow
+	}
+	if config.ResetTimeout <= 0 {
+		config.ResetTimeout = DefaultResetTimeout
+	}
+	return &CircuitBreaker{client: client, config: config, now: time.Now}
+}
+
+// State returns the current state. An open circuit whose ResetTimeout has
+// elapsed reports HalfOpen: the next request will be let through as a trial.
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.state == Open && !cb.now().Before(cb.openedAt.Add(cb.config.ResetTimeout)) {
+		return HalfOpen
+	}
+	return cb.state
+}
+
+// Metrics returns a snapshot of the counters
+func (cb *CircuitBreaker) Metrics() CircuitMetrics {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	metrics := cb.metrics
+	metrics.ConsecutiveFailures = len(cb.failures)
+	return metrics
+}
+
+// Chat sends the request unless the circuit is open
+func (cb *CircuitBreaker) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatResponse, error) {
+	if err := cb.allow(); err != nil {
+		return nil, err
+	}
+	resp, err := cb.client.Chat(ctx, request)
+	cb.record(ctx, err)
+	return resp, err
+}
+
+// ChatStream opens a stream unless the circuit is open
+func (cb *CircuitBreaker) ChatStream(ctx context.Context, request *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	if err := cb.allow(); err != nil {
+		return nil, err
+	}
+	events, err := cb.client.ChatStream(ctx, request)
+	cb.record(ctx, err)
+	return events, err
+}
+
+// ListModels passes through to the wrapped client
+func (cb *CircuitBreaker) ListModels(ctx context.Context) ([]llm.Model, error) {
+	return cb.client.ListModels(ctx)
+}
+
+// GetModel passes through to the wrapped client
+func (cb *CircuitBreaker) GetModel(ctx context.Context, modelID string) (*llm.Model, error) {
+	return cb.client.GetModel(ctx, modelID)
+}
+
+// HealthCheck checks the wrapped client regardless of the circuit state
+func (cb *CircuitBreaker) HealthCheck(ctx context.Context) error {
+	return llm.HealthCheck(ctx, cb.client)
+}
+
+// Close closes the wrapped client
+func (cb *CircuitBreaker) Close() error {
+	return cb.client.Close()
+}
+
+// Provider names the wrapped client, so fallback errors name the provider
+// rather than the breaker
+func (cb *CircuitBreaker) Provider() string {
+	return fallback.ProviderName(cb.client)
+}
+
+// ChatWithImages passes through to the wrapped client when it supports
+// images, so wrapping a vision-capable client keeps /attach working
+func (cb *CircuitBreaker) ChatWithImages(prompt string, imagePaths []string, opts map[string]interface{}) (string, error) {
+	mm, ok := cb.client.(llm.MultimodalClient)
+	if !ok {
+		return "", errImagesUnsupported
+	}
+	return mm.ChatWithImages(prompt, imagePaths, opts)
+}
+
+// StreamChatWithImages passes through to the wrapped client when it supports
+// images
+func (cb *CircuitBreaker) StreamChatWithImages(prompt string, imagePaths []string, opts map[string]interface{}) (<-chan string, error) {
+	mm, ok := cb.client.(llm.MultimodalClient)
+	if !ok {
+		return nil, errImagesUnsupported
+	}
+	return mm.StreamChatWithImages(prompt, imagePaths, opts)
+}
+
+// allow reports whether a request may go to the provider, moving an open
+// circuit to half-open once ResetTimeout has passed.
+func (cb *CircuitBreaker) allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case Open:
+		if cb.now().Before(cb.openedAt.Add(cb.config.ResetTimeout)) {
+			cb.metrics.Rejected++
+			return ErrCircuitOpen
+		}
+		cb.state = HalfOpen
+		cb.trial = true
+	case HalfOpen:
+		// Only one trial at a time
+		if cb.trial {
+			cb.metrics.Rejected++
+			return ErrCircuitOpen
+		}
+		cb.trial = true
+	}
+	cb.metrics.Requests++
+	return nil
+}
+
+func (cb *CircuitBreaker) record(ctx context.Context, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	wasTrial := cb.state == HalfOpen
+	cb.trial = false
+
+	if err == nil {
+		cb.metrics.Successes++
+		cb.failures = nil
+		cb.state = Closed
+		return
+	}
+	if ctx.Err() != nil {
+		// The caller gave up; that says nothing about the provider. A
+		// cancelled trial leaves the circuit half-open for the next one.
+		return
+	}
+
+	cb.metrics.Failures++
+	now := cb.now()
+	if wasTrial {
+		cb.open(now)
+		return
+	}
+
+	cutoff := now.Add(-cb.config.Window)
+	kept := cb.failures[:0]
+	for _, at := range cb.failures {
+		if at.After(cutoff) {
+			kept = append(kept, at)
+		}
+	}
+	cb.failures = append(kept, now)
+	if len(cb.failures) >= cb.config.Threshold {
+		cb.open(now)
+	}
+}
+
+func (cb *CircuitBreaker) open(now time.Time) {
+	cb.state = Open
+	cb.openedAt = now
+	cb.failures = nil
+	cb.metrics.Opens++
+}
@@ -0,0 +1,209 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/llm/fallback"
+)
+
+var errUnavailable = errors.New("status 503: service unavailable")
+
+// flakyClient fails Chat with err while err is set
+type flakyClient struct {
+	err   error
+	calls int
+}
+
+func (c *flakyClient) Chat(context.Context, *llm.ChatRequest) (*llm.ChatResponse, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &llm.ChatResponse{}, nil
+}
+
+func (c *flakyClient) ChatStream(context.Context, *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	events := make(chan llm.StreamEvent)
+	close(events)
+	return events, nil
+}
+
+func (c *flakyClient) ListModels(context.Context) ([]llm.Model, error)      { return nil, nil }
+func (c *flakyClient) GetModel(context.Context, string) (*llm.Model, error) { return nil, nil }
+func (c *flakyClient) Close() error                                         { return nil }
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestBreaker(client llm.Client) (*CircuitBreaker, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	cb := NewCircuitBreaker(client, Config{Threshold: 3, Window: time.Minute, ResetTimeout: 30 * time.Second})
+	cb.now = clock.Now
+	return cb, clock
+}
+
+func chat(cb *CircuitBreaker) error {
+	_, err := cb.Chat(context.Background(), &llm.ChatRequest{})
+	return err
+}
+
+func TestCircuitBreaker_ClosedToOpenAfterThreshold(t *testing.T) {
+	client := &flakyClient{err: errUnavailable}
+	cb, _ := newTestBreaker(client)
+
+	for i := 0; i < 3; i++ {
+		if cb.State() != Closed {
+			t.Fatalf("expected closed before failure %d, got %s", i+1, cb.State())
+		}
+		if err := chat(cb); !errors.Is(err, errUnavailable) {
+			t.Fatalf("expected the provider error, got %v", err)
+		}
+	}
+	if cb.State() != Open {
+		t.Fatalf("expected open after 3 failures, got %s", cb.State())
+	}
+
+	if err := chat(cb); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if client.calls != 3 {
+		t.Fatalf("expected the open circuit not to reach the provider, got %d calls", client.calls)
+	}
+	m := cb.Metrics()
+	if m.Requests != 3 || m.Failures != 3 || m.Rejected != 1 || m.Opens != 1 {
+		t.Fatalf("unexpected metrics %+v", m)
+	}
+}
+
+func TestCircuitBreaker_FailuresOutsideWindowDoNotOpen(t *testing.T) {
+	client := &flakyClient{err: errUnavailable}
+	cb, clock := newTestBreaker(client)
+
+	chat(cb)
+	chat(cb)
+	clock.Advance(2 * time.Minute)
+	chat(cb)
+	if cb.State() != Closed {
+		t.Fatalf("expected failures older than the window to be forgotten, got %s", cb.State())
+	}
+	if got := cb.Metrics().ConsecutiveFailures; got != 1 {
+		t.Fatalf("expected 1 failure in the window, got %d", got)
+	}
+
+	// A success resets the count
+	client.err = nil
+	chat(cb)
+	if got := cb.Metrics().ConsecutiveFailures; got != 0 {
+		t.Fatalf("expected success to reset the count, got %d", got)
+	}
+}
+
+func TestCircuitBreaker_OpenToHalfOpenAfterResetTimeout(t *testing.T) {
+	client := &flakyClient{err: errUnavailable}
+	cb, clock := newTestBreaker(client)
+	for i := 0; i < 3; i++ {
+		chat(cb)
+	}
+
+	clock.Advance(29 * time.Second)
+	if cb.State() != Open {
+		t.Fatalf("expected open before the reset timeout, got %s", cb.State())
+	}
+	clock.Advance(time.Second)
+	if cb.State() != HalfOpen {
+		t.Fatalf("expected half-open once the reset timeout elapsed, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreaker_HalfOpenToClosedOnSuccess(t *testing.T) {
+	client := &flakyClient{err: errUnavailable}
+	cb, clock := newTestBreaker(client)
+	for i := 0; i < 3; i++ {
+		chat(cb)
+	}
+	clock.Advance(30 * time.Second)
+
+	client.err = nil
+	if err := chat(cb); err != nil {
+		t.Fatalf("expected the trial request to go through, got %v", err)
+	}
+	if cb.State() != Closed {
+		t.Fatalf("expected closed after a successful trial, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreaker_HalfOpenToOpenOnFailure(t *testing.T) {
+	client := &flakyClient{err: errUnavailable}
+	cb, clock := newTestBreaker(client)
+	for i := 0; i < 3; i++ {
+		chat(cb)
+	}
+	clock.Advance(30 * time.Second)
+
+	if _, err := cb.ChatStream(context.Background(), &llm.ChatRequest{}); !errors.Is(err, errUnavailable) {
+		t.Fatalf("expected the trial to reach the provider, got %v", err)
+	}
+	if cb.State() != Open {
+		t.Fatalf("expected a failed trial to reopen the circuit, got %s", cb.State())
+	}
+	if err := chat(cb); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen after reopening, got %v", err)
+	}
+	if opens := cb.Metrics().Opens; opens != 2 {
+		t.Fatalf("expected 2 opens, got %d", opens)
+	}
+}
+
+func TestCircuitBreaker_IgnoresCallerCancellation(t *testing.T) {
+	client := &flakyClient{err: context.Canceled}
+	cb, _ := newTestBreaker(client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 5; i++ {
+		cb.Chat(ctx, &llm.ChatRequest{})
+	}
+	if cb.State() != Closed {
+		t.Fatalf("expected cancelled requests not to open the circuit, got %s", cb.State())
+	}
+}
+
+// namedClient names its provider the way fallback.ProviderName looks for
+type namedClient struct {
+	flakyClient
+}
+
+func (c *namedClient) Provider() string { return "openai" }
+
+func TestCircuitBreaker_FailsOverFastInFallbackChain(t *testing.T) {
+	primary := &namedClient{flakyClient{err: errUnavailable}}
+	cb, _ := newTestBreaker(primary)
+	chain := fallback.NewFallbackClient([]llm.Client{cb, &flakyClient{}})
+
+	for i := 0; i < 5; i++ {
+		if _, err := chain.Chat(context.Background(), &llm.ChatRequest{}); err != nil {
+			t.Fatalf("fallback should answer, got %v", err)
+		}
+	}
+	if primary.calls != 3 {
+		t.Fatalf("expected the open circuit to skip the primary after 3 failures, got %d calls", primary.calls)
+	}
+
+	// Once every provider fails, the error names the provider behind the breaker
+	cb2, _ := newTestBreaker(&namedClient{flakyClient{err: errUnavailable}})
+	_, err := fallback.NewFallbackClient([]llm.Client{cb2}).Chat(context.Background(), &llm.ChatRequest{})
+	var failure *fallback.FallbackError
+	if !errors.As(err, &failure) || failure.Attempts[0].Provider != "openai" {
+		t.Fatalf("expected the attempt to name openai, got %v", err)
+	}
+}
@@ -2,9 +2,14 @@ package llm
 
 import (
 	"context"
+	"errors"
 	"io"
 )
 
+// ErrStreamingNotSupported is returned (possibly wrapped) by ChatStream on
+// clients that only implement blocking Chat.
+var ErrStreamingNotSupported = errors.New("streaming not supported")
+
 // Client defines the interface for LLM providers
 type Client interface {
 	// Chat sends a chat request and returns the response
@@ -36,6 +41,15 @@ type Model struct {
 	Description string   `json:"description,omitempty"`
 	// SupportsVision indicates the model can process image inputs
 	SupportsVision bool `json:"supports_vision,omitempty"`
+	// ContextWindow is the total number of tokens (prompt plus completion)
+	// the model accepts, when the provider or models.json reports it
+	ContextWindow int `json:"context_window,omitempty"`
+	// MaxOutputTokens caps the tokens the model can generate in one response
+	MaxOutputTokens int `json:"max_output_tokens,omitempty"`
+	// InputPricePerMTok and OutputPricePerMTok are the model's price in US
+	// dollars per million tokens; both are 0 when the price is unknown
+	InputPricePerMTok  float64 `json:"input_price_per_mtok,omitempty"`
+	OutputPricePerMTok float64 `json:"output_price_per_mtok,omitempty"`
 }
 
 // StreamReader provides a reader interface for streaming responses
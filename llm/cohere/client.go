@@ -0,0 +1,734 @@
+package cohere
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+const (
+	// The chat format below (preamble, chat_history, parameter_definitions
+	// and the stream-start/text-generation/... events) is the v1 chat API's.
+	defaultBaseURL = "https://api.cohere.com/v1"
+	defaultTimeout = 60 * time.Second
+	defaultModel   = "command-r-plus"
+	maxStreamLine  = 1024 * 1024
+)
+
+// Client implements the LLM client interface for Cohere
+type Client struct {
+	options    llm.ClientOptions
+	httpClient *http.Client
+}
+
+// CohereRequest represents a request to Cohere's chat API
+type CohereRequest struct {
+	Model         string             `json:"model"`
+	Message       string             `json:"message"`
+	Preamble      string             `json:"preamble,omitempty"`
+	ChatHistory   []CohereMessage    `json:"chat_history,omitempty"`
+	Tools         []CohereTool       `json:"tools,omitempty"`
+	ToolResults   []CohereToolResult `json:"tool_results,omitempty"`
+	Temperature   float32            `json:"temperature,omitempty"`
+	MaxTokens     int                `json:"max_tokens,omitempty"`
+	P             float32            `json:"p,omitempty"`
+	StopSequences []string           `json:"stop_sequences,omitempty"`
+	Stream        bool               `json:"stream,omitempty"`
+}
+
+// CohereMessage is one turn of chat_history. Roles are USER, CHATBOT, SYSTEM
+// and TOOL.
+type CohereMessage struct {
+	Role        string             `json:"role"`
+	Message     string             `json:"message,omitempty"`
+	ToolCalls   []CohereToolCall   `json:"tool_calls,omitempty"`
+	ToolResults []CohereToolResult `json:"tool_results,omitempty"`
+}
+
+// CohereTool describes a tool with flat parameter definitions instead of a
+// JSON schema
+type CohereTool struct {
+	Name                 string                               `json:"name"`
+	Description          string                               `json:"description"`
+	ParameterDefinitions map[string]CohereParameterDefinition `json:"parameter_definitions,omitempty"`
+}
+
+// CohereParameterDefinition describes one tool parameter
+type CohereParameterDefinition struct {
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// CohereToolCall is a tool invocation. Parameters are a JSON object, not an
+// encoded string, and calls carry no ID.
+type CohereToolCall struct {
+	Name       string                 `json:"name"`
+	Parameters map[string]interface{} `json:"parameters"`
+}
+
+// CohereToolResult pairs a tool call with its outputs
+type CohereToolResult struct {
+	Call    CohereToolCall           `json:"call"`
+	Outputs []map[string]interface{} `json:"outputs"`
+}
+
+// CohereResponse represents a response from Cohere's chat API
+type CohereResponse struct {
+	ResponseID   string           `json:"response_id"`
+	Text         string           `json:"text"`
+	GenerationID string           `json:"generation_id"`
+	FinishReason string           `json:"finish_reason"`
+	ToolCalls    []CohereToolCall `json:"tool_calls,omitempty"`
+	Meta         CohereMeta       `json:"meta"`
+}
+
+// CohereMeta carries token accounting
+type CohereMeta struct {
+	BilledUnits struct {
+		InputTokens  float64 `json:"input_tokens"`
+		OutputTokens float64 `json:"output_tokens"`
+	} `json:"billed_units"`
+}
+
+// cohereStreamEvent is one event of a streamed chat response
+type cohereStreamEvent struct {
+	EventType    string           `json:"event_type"`
+	GenerationID string           `json:"generation_id,omitempty"`
+	Text         string           `json:"text,omitempty"`
+	ToolCalls    []CohereToolCall `json:"tool_calls,omitempty"`
+	FinishReason string           `json:"finish_reason,omitempty"`
+	Response     *CohereResponse  `json:"response,omitempty"`
+}
+
+// NewClient creates a new Cohere client
+func NewClient(opts ...llm.ClientOption) (*Client, error) {
+	options := llm.ClientOptions{
+		BaseURL:      defaultBaseURL,
+		Timeout:      defaultTimeout,
+		MaxRetries:   3,
+		DefaultModel: defaultModel,
+		Headers:      make(map[string]string),
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	// Get API key from environment if not provided
+	if options.APIKey == "" {
+		options.APIKey = os.Getenv("COHERE_API_KEY")
+		if options.APIKey == "" {
+			return nil, fmt.Errorf("Cohere API key not provided")
+		}
+	}
+
+	// Create HTTP client
+	httpClient := &http.Client{
+		Timeout: options.Timeout,
+	}
+
+	return &Client{
+		options:    options,
+		httpClient: httpClient,
+	}, nil
+}
+
+// Chat sends a chat request to Cohere
+func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatResponse, error) {
+	// Convert to Cohere format
+	cohereReq := c.convertRequest(request)
+
+	// Create request body
+	body, err := json.Marshal(cohereReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Execute request with retries
+	var response CohereResponse
+	err = c.doWithRetries(ctx, func() error {
+		req, err := c.newChatRequest(ctx, body)
+		if err != nil {
+			return err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to execute request: %w", err)
+		}
+		defer resp.Body.Close()
+
+		// Read response body
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+
+		// Check for errors
+		if resp.StatusCode != http.StatusOK {
+			return apiError(resp.StatusCode, respBody)
+		}
+
+		// Parse response
+		if err := json.Unmarshal(respBody, &response); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.convertResponse(&response, cohereReq.Model), nil
+}
+
+// ChatStream sends a streaming chat request to Cohere
+func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	// Convert to Cohere format
+	cohereReq := c.convertRequest(request)
+	cohereReq.Stream = true
+
+	// Create request body
+	body, err := json.Marshal(cohereReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Open the stream, retrying rate limits before any event is read
+	var resp *http.Response
+	err = c.doWithRetries(ctx, func() error {
+		req, err := c.newChatRequest(ctx, body)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "text/event-stream")
+
+		r, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to execute request: %w", err)
+		}
+		if r.StatusCode != http.StatusOK {
+			defer r.Body.Close()
+			body, _ := io.ReadAll(r.Body)
+			return apiError(r.StatusCode, body)
+		}
+		resp = r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Create event channel
+	events := make(chan llm.StreamEvent)
+
+	// Start goroutine to read stream
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		generationID := ""
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLine)
+		for scanner.Scan() {
+			data, ok := streamPayload(scanner.Text())
+			if !ok {
+				continue
+			}
+
+			var event cohereStreamEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				llm.DebugSkippedEvent("Cohere", data, err)
+				continue
+			}
+
+			streamEvent := llm.StreamEvent{
+				ID:      generationID,
+				Object:  "chat.completion.chunk",
+				Created: time.Now().Unix(),
+				Model:   cohereReq.Model,
+			}
+			switch event.EventType {
+			case "stream-start":
+				generationID = event.GenerationID
+				continue
+			case "text-generation":
+				streamEvent.Choices = []llm.Choice{{
+					Delta: &llm.Message{Content: llm.StringPtr(event.Text)},
+				}}
+			case "tool-calls-generation":
+				streamEvent.Choices = []llm.Choice{{
+					Delta: &llm.Message{ToolCalls: convertToolCalls(generationID, event.ToolCalls)},
+				}}
+			case "stream-end":
+				choice := llm.Choice{FinishReason: finishReason(event.FinishReason, false)}
+				if event.Response != nil {
+					choice.FinishReason = finishReason(event.FinishReason, len(event.Response.ToolCalls) > 0)
+					streamEvent.Usage = usage(event.Response.Meta)
+				}
+				streamEvent.Choices = []llm.Choice{choice}
+			default:
+				continue
+			}
+
+			select {
+			case events <- streamEvent:
+			case <-ctx.Done():
+				return
+			}
+			if event.EventType == "stream-end" {
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// streamPayload extracts the JSON event from a stream line. Cohere sends
+// newline-delimited JSON, or SSE "data:" lines when asked for
+// text/event-stream; both are accepted.
+func streamPayload(line string) (string, bool) {
+	line = trimStreamSpace(line)
+	if line == "" || strings.HasPrefix(line, ":") || strings.HasPrefix(line, "event:") {
+		return "", false
+	}
+	if data, ok := strings.CutPrefix(line, "data:"); ok {
+		line = trimStreamSpace(data)
+	}
+	return line, line != ""
+}
+
+// trimStreamSpace trims whitespace and the byte order marks some proxies
+// put before each line
+func trimStreamSpace(s string) string {
+	return strings.TrimFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '\ufeff'
+	})
+}
+
+// ListModels returns available Cohere chat models
+func (c *Client) ListModels(ctx context.Context) ([]llm.Model, error) {
+	var response struct {
+		Models []cohereModel `json:"models"`
+	}
+	if err := c.getJSON(ctx, "/models?endpoint=chat", &response); err != nil {
+		return nil, err
+	}
+
+	models := make([]llm.Model, 0, len(response.Models))
+	for _, m := range response.Models {
+		models = append(models, m.toModel())
+	}
+	return models, nil
+}
+
+// GetModel returns details about a specific model
+func (c *Client) GetModel(ctx context.Context, modelID string) (*llm.Model, error) {
+	var m cohereModel
+	if err := c.getJSON(ctx, "/models/"+url.PathEscape(modelID), &m); err != nil {
+		return nil, err
+	}
+	model := m.toModel()
+	return &model, nil
+}
+
+// cohereModel is an entry from Cohere's /models endpoint
+type cohereModel struct {
+	Name          string   `json:"name"`
+	Endpoints     []string `json:"endpoints"`
+	ContextLength int      `json:"context_length"`
+}
+
+func (m cohereModel) toModel() llm.Model {
+	return llm.Model{
+		ID:            m.Name,
+		Object:        "model",
+		OwnedBy:       "cohere",
+		ContextWindow: m.ContextLength,
+	}
+}
+
+// getJSON issues a GET against the API and decodes the JSON response into out
+func (c *Client) getJSON(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.options.BaseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.setHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return apiError(resp.StatusCode, body)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// Close cleans up resources
+func (c *Client) Close() error {
+	return nil
+}
+
+// setHeaders sets common headers for requests
+func (c *Client) setHeaders(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+c.options.APIKey)
+	req.Header.Set("User-Agent", "simple-agent-go/1.0")
+
+	// Add custom headers
+	for k, v := range c.options.Headers {
+		req.Header.Set(k, v)
+	}
+}
+
+// newChatRequest builds a chat request. It is called once per attempt so
+// retries always send the full body.
+func (c *Client) newChatRequest(ctx context.Context, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.options.BaseURL+"/chat", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers
+	c.setHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// apiError formats an error response; Cohere reports {"message": "..."}
+func apiError(status int, body []byte) error {
+	var errResp struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Message != "" {
+		return fmt.Errorf("Cohere API error: status %d: %s", status, errResp.Message)
+	}
+	return fmt.Errorf("Cohere API error: status %d, body: %s", status, string(body))
+}
+
+// convertRequest converts from standard format to Cohere format. System
+// messages become the preamble. The final user message becomes message; when
+// the conversation instead ends with tool results, they are sent as
+// tool_results with an empty message. Everything earlier is chat_history.
+func (c *Client) convertRequest(req *llm.ChatRequest) *CohereRequest {
+	cohereReq := &CohereRequest{
+		Model:         req.Model,
+		Temperature:   req.Temperature,
+		MaxTokens:     req.MaxTokens,
+		P:             req.TopP,
+		StopSequences: req.Stop,
+		Stream:        req.Stream,
+	}
+
+	if cohereReq.Model == "" {
+		cohereReq.Model = c.options.DefaultModel
+	}
+
+	// Tool results reference the call that produced them, which Cohere
+	// identifies by name and parameters rather than an ID.
+	calls := make(map[string]CohereToolCall)
+	var preamble []string
+	var conversation []llm.Message
+	for _, msg := range req.Messages {
+		if msg.Role == llm.RoleSystem {
+			preamble = append(preamble, llm.GetStringValue(msg.Content))
+			continue
+		}
+		for _, toolCall := range msg.ToolCalls {
+			calls[toolCall.ID] = CohereToolCall{
+				Name:       toolCall.Function.Name,
+				Parameters: decodeParameters(toolCall.Function.Arguments),
+			}
+		}
+		conversation = append(conversation, msg)
+	}
+	cohereReq.Preamble = strings.Join(preamble, "\n\n")
+
+	// Split off the current turn
+	end := len(conversation)
+	if end > 0 && conversation[end-1].Role == llm.RoleUser {
+		cohereReq.Message = llm.GetStringValue(conversation[end-1].Content)
+		end--
+	} else {
+		start := end
+		for start > 0 && conversation[start-1].Role == llm.RoleTool {
+			start--
+		}
+		for _, msg := range conversation[start:end] {
+			cohereReq.ToolResults = append(cohereReq.ToolResults, toolResult(msg, calls))
+		}
+		end = start
+	}
+
+	// Convert earlier messages
+	for _, msg := range conversation[:end] {
+		switch msg.Role {
+		case llm.RoleUser:
+			cohereReq.ChatHistory = append(cohereReq.ChatHistory, CohereMessage{
+				Role:    "USER",
+				Message: llm.GetStringValue(msg.Content),
+			})
+		case llm.RoleAssistant:
+			turn := CohereMessage{
+				Role:    "CHATBOT",
+				Message: llm.GetStringValue(msg.Content),
+			}
+			for _, toolCall := range msg.ToolCalls {
+				turn.ToolCalls = append(turn.ToolCalls, calls[toolCall.ID])
+			}
+			cohereReq.ChatHistory = append(cohereReq.ChatHistory, turn)
+		case llm.RoleTool:
+			// Consecutive tool results share one TOOL turn
+			result := toolResult(msg, calls)
+			if n := len(cohereReq.ChatHistory); n > 0 && cohereReq.ChatHistory[n-1].Role == "TOOL" {
+				cohereReq.ChatHistory[n-1].ToolResults = append(cohereReq.ChatHistory[n-1].ToolResults, result)
+			} else {
+				cohereReq.ChatHistory = append(cohereReq.ChatHistory, CohereMessage{
+					Role:        "TOOL",
+					ToolResults: []CohereToolResult{result},
+				})
+			}
+		}
+	}
+
+	// Convert tools
+	for _, tool := range req.Tools {
+		if fn, ok := tool["function"].(map[string]interface{}); ok {
+			name, _ := fn["name"].(string)
+			description, _ := fn["description"].(string)
+			schema, _ := fn["parameters"].(map[string]interface{})
+			cohereReq.Tools = append(cohereReq.Tools, CohereTool{
+				Name:                 name,
+				Description:          description,
+				ParameterDefinitions: parameterDefinitions(schema),
+			})
+		}
+	}
+
+	return cohereReq
+}
+
+// toolResult converts a tool message into a Cohere tool result
+func toolResult(msg llm.Message, calls map[string]CohereToolCall) CohereToolResult {
+	call, ok := calls[msg.ToolCallID]
+	if !ok {
+		call = CohereToolCall{Name: msg.Name, Parameters: map[string]interface{}{}}
+	}
+	return CohereToolResult{
+		Call:    call,
+		Outputs: []map[string]interface{}{{"output": llm.GetStringValue(msg.Content)}},
+	}
+}
+
+// parameterDefinitions flattens a JSON schema's top-level properties into
+// Cohere parameter definitions
+func parameterDefinitions(schema map[string]interface{}) map[string]CohereParameterDefinition {
+	properties, _ := schema["properties"].(map[string]interface{})
+	if len(properties) == 0 {
+		return nil
+	}
+
+	required := make(map[string]bool)
+	switch names := schema["required"].(type) {
+	case []interface{}:
+		for _, name := range names {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	case []string:
+		for _, name := range names {
+			required[name] = true
+		}
+	}
+
+	definitions := make(map[string]CohereParameterDefinition, len(properties))
+	for name, raw := range properties {
+		property, _ := raw.(map[string]interface{})
+		description, _ := property["description"].(string)
+		jsonType, _ := property["type"].(string)
+		definitions[name] = CohereParameterDefinition{
+			Description: description,
+			Type:        parameterType(jsonType),
+			Required:    required[name],
+		}
+	}
+	return definitions
+}
+
+// parameterType maps JSON schema types to the Python-style names Cohere uses
+func parameterType(jsonType string) string {
+	switch jsonType {
+	case "integer":
+		return "int"
+	case "number":
+		return "float"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "list"
+	case "object":
+		return "dict"
+	default:
+		return "str"
+	}
+}
+
+// decodeParameters turns encoded tool arguments back into a JSON object
+func decodeParameters(arguments json.RawMessage) map[string]interface{} {
+	parameters := map[string]interface{}{}
+	if len(arguments) == 0 {
+		return parameters
+	}
+	// Arguments may arrive double-encoded as a JSON string
+	var encoded string
+	if err := json.Unmarshal(arguments, &encoded); err == nil {
+		arguments = json.RawMessage(encoded)
+	}
+	if err := json.Unmarshal(arguments, &parameters); err != nil {
+		return map[string]interface{}{}
+	}
+	return parameters
+}
+
+// convertToolCalls converts Cohere tool calls, which carry no IDs, assigning
+// IDs unique within the generation
+func convertToolCalls(generationID string, calls []CohereToolCall) []llm.ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	if generationID == "" {
+		generationID = fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+
+	toolCalls := make([]llm.ToolCall, 0, len(calls))
+	for i, call := range calls {
+		parameters := call.Parameters
+		if parameters == nil {
+			parameters = map[string]interface{}{}
+		}
+		arguments, err := json.Marshal(parameters)
+		if err != nil {
+			arguments = []byte("{}")
+		}
+		toolCalls = append(toolCalls, llm.ToolCall{
+			ID:   fmt.Sprintf("call_%s_%d", generationID, i),
+			Type: "function",
+			Function: llm.FunctionCall{
+				Name:      call.Name,
+				Arguments: json.RawMessage(arguments),
+			},
+		})
+	}
+	return toolCalls
+}
+
+// convertResponse converts from Cohere format to standard format
+func (c *Client) convertResponse(resp *CohereResponse, model string) *llm.ChatResponse {
+	return &llm.ChatResponse{
+		ID:      resp.ResponseID,
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   model,
+		Choices: []llm.Choice{
+			{
+				Index: 0,
+				Message: llm.Message{
+					Role:      llm.RoleAssistant,
+					Content:   llm.StringPtr(resp.Text),
+					ToolCalls: convertToolCalls(resp.GenerationID, resp.ToolCalls),
+				},
+				FinishReason: finishReason(resp.FinishReason, len(resp.ToolCalls) > 0),
+			},
+		},
+		Usage: usage(resp.Meta),
+	}
+}
+
+// finishReason maps Cohere finish reasons to OpenAI-style ones
+func finishReason(reason string, hasToolCalls bool) string {
+	if hasToolCalls {
+		return "tool_calls"
+	}
+	switch reason {
+	case "COMPLETE", "STOP_SEQUENCE", "":
+		return "stop"
+	case "MAX_TOKENS":
+		return "length"
+	default:
+		return strings.ToLower(reason)
+	}
+}
+
+func usage(meta CohereMeta) *llm.Usage {
+	input := int(meta.BilledUnits.InputTokens)
+	output := int(meta.BilledUnits.OutputTokens)
+	if input == 0 && output == 0 {
+		return nil
+	}
+	return &llm.Usage{
+		PromptTokens:     input,
+		CompletionTokens: output,
+		TotalTokens:      input + output,
+	}
+}
+
+// doWithRetries executes a function with retries
+func (c *Client) doWithRetries(ctx context.Context, fn func() error) error {
+	var lastErr error
+
+	for i := 0; i <= c.options.MaxRetries; i++ {
+		if i > 0 {
+			if !llm.AllowRetry(ctx) {
+				return fmt.Errorf("retry budget exhausted: %w", lastErr)
+			}
+			// Exponential backoff
+			delay := time.Duration(i) * time.Second
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if err := fn(); err != nil {
+			lastErr = err
+			// Check if error is retryable
+			if strings.Contains(err.Error(), "status 429") || // Rate limit
+				strings.Contains(err.Error(), "status 500") || // Server error
+				strings.Contains(err.Error(), "status 502") || // Bad gateway
+				strings.Contains(err.Error(), "status 503") { // Service unavailable
+				continue
+			}
+			return err
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("max retries exceeded: %w", lastErr)
+}
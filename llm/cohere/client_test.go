@@ -0,0 +1,204 @@
+package cohere
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// fixtureServer serves a recorded Cohere response and hands the decoded
+// request body to inspect.
+func fixtureServer(t *testing.T, fixture string, inspect func(CohereRequest)) *httptest.Server {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("expected bearer auth, got %q", got)
+		}
+		body, _ := io.ReadAll(r.Body)
+		var req CohereRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if inspect != nil {
+			inspect(req)
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestClient(t *testing.T, baseURL string) *Client {
+	t.Helper()
+	client, err := NewClient(llm.WithAPIKey("test-key"), llm.WithBaseURL(baseURL))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client
+}
+
+func weatherTool() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "function",
+		"function": map[string]interface{}{
+			"name":        "get_weather",
+			"description": "Look up the forecast",
+			"parameters": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"city": map[string]interface{}{"type": "string", "description": "City name"},
+					"days": map[string]interface{}{"type": "integer"},
+				},
+				"required": []interface{}{"city"},
+			},
+		},
+	}
+}
+
+func TestConvertRequestTranslatesConversation(t *testing.T) {
+	client := newTestClient(t, "http://unused")
+	req := client.convertRequest(&llm.ChatRequest{
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: llm.StringPtr("Be brief.")},
+			{Role: llm.RoleUser, Content: llm.StringPtr("Weather in Toronto?")},
+			{Role: llm.RoleAssistant, Content: llm.StringPtr(""), ToolCalls: []llm.ToolCall{{
+				ID:       "call_1",
+				Type:     "function",
+				Function: llm.FunctionCall{Name: "get_weather", Arguments: json.RawMessage(`{"city":"Toronto"}`)},
+			}}},
+			{Role: llm.RoleTool, ToolCallID: "call_1", Content: llm.StringPtr("Sunny, 21C")},
+		},
+		Tools: []map[string]interface{}{weatherTool()},
+	})
+
+	if req.Model != defaultModel || req.Preamble != "Be brief." || req.Message != "" {
+		t.Fatalf("unexpected request header fields: %+v", req)
+	}
+	if len(req.ChatHistory) != 2 || req.ChatHistory[0].Role != "USER" || req.ChatHistory[1].Role != "CHATBOT" {
+		t.Fatalf("unexpected chat history: %+v", req.ChatHistory)
+	}
+	if got := req.ChatHistory[1].ToolCalls[0].Parameters["city"]; got != "Toronto" {
+		t.Fatalf("expected tool call parameters as an object, got %+v", req.ChatHistory[1].ToolCalls)
+	}
+	if len(req.ToolResults) != 1 || req.ToolResults[0].Call.Name != "get_weather" || req.ToolResults[0].Outputs[0]["output"] != "Sunny, 21C" {
+		t.Fatalf("expected trailing tool message as tool_results, got %+v", req.ToolResults)
+	}
+
+	params := req.Tools[0].ParameterDefinitions
+	if params["city"] != (CohereParameterDefinition{Description: "City name", Type: "str", Required: true}) {
+		t.Fatalf("unexpected city definition %+v", params["city"])
+	}
+	if params["days"].Type != "int" || params["days"].Required {
+		t.Fatalf("unexpected days definition %+v", params["days"])
+	}
+}
+
+func TestChatConvertsToolCallParameters(t *testing.T) {
+	srv := fixtureServer(t, "chat_response.json", func(req CohereRequest) {
+		if req.Message != "What is 2+2?" || req.Stream {
+			t.Errorf("unexpected request %+v", req)
+		}
+	})
+	client := newTestClient(t, srv.URL)
+
+	resp, err := client.Chat(context.Background(), &llm.ChatRequest{
+		Messages: []llm.Message{{Role: llm.RoleUser, Content: llm.StringPtr("What is 2+2?")}},
+	})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	choice := resp.Choices[0]
+	if choice.FinishReason != "tool_calls" || len(choice.Message.ToolCalls) != 1 {
+		t.Fatalf("expected one tool call, got %+v", choice)
+	}
+	call := choice.Message.ToolCalls[0]
+	if call.ID == "" || call.Function.Name != "calculate" || string(call.Function.Arguments) != `{"expression":"2+2"}` {
+		t.Fatalf("unexpected tool call %+v (args %s)", call, call.Function.Arguments)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 28 {
+		t.Fatalf("expected billed units as usage, got %+v", resp.Usage)
+	}
+}
+
+func TestChatStreamReplaysRecordedFixture(t *testing.T) {
+	srv := fixtureServer(t, "chat_stream.jsonl", func(req CohereRequest) {
+		if !req.Stream {
+			t.Errorf("expected stream=true in request")
+		}
+	})
+	client := newTestClient(t, srv.URL)
+
+	events, err := client.ChatStream(context.Background(), &llm.ChatRequest{
+		Messages: []llm.Message{{Role: llm.RoleUser, Content: llm.StringPtr("Weather in Toronto?")}},
+		Tools:    []map[string]interface{}{weatherTool()},
+	})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+
+	var content strings.Builder
+	var toolCalls []llm.ToolCall
+	var finish string
+	var usage *llm.Usage
+	for event := range events {
+		for _, choice := range event.Choices {
+			if choice.Delta != nil {
+				content.WriteString(llm.GetStringValue(choice.Delta.Content))
+				toolCalls = append(toolCalls, choice.Delta.ToolCalls...)
+			}
+			if choice.FinishReason != "" {
+				finish = choice.FinishReason
+			}
+		}
+		if event.Usage != nil {
+			usage = event.Usage
+		}
+	}
+
+	if got := content.String(); got != "I will check the weather." {
+		t.Fatalf("unexpected streamed content %q", got)
+	}
+	if len(toolCalls) != 1 || toolCalls[0].ID != "call_gen-7d2c_0" || string(toolCalls[0].Function.Arguments) != `{"city":"Toronto","days":2}` {
+		t.Fatalf("unexpected tool calls %+v", toolCalls)
+	}
+	if finish != "tool_calls" {
+		t.Fatalf("expected finish_reason tool_calls, got %q", finish)
+	}
+	if usage == nil || usage.TotalTokens != 43 {
+		t.Fatalf("expected usage from stream-end, got %+v", usage)
+	}
+}
+
+func TestStreamPayloadAcceptsSSEAndJSONLines(t *testing.T) {
+	for line, want := range map[string]string{
+		`{"event_type":"stream-start"}`:       `{"event_type":"stream-start"}`,
+		`data: {"event_type":"stream-start"}`: `{"event_type":"stream-start"}`,
+		"\ufeffdata: \ufeff{}":                "{}",
+		`event: stream-start`:                 "",
+		`: keepalive`:                         "",
+		``:                                    "",
+	} {
+		got, ok := streamPayload(line)
+		if got != want || ok != (want != "") {
+			t.Fatalf("streamPayload(%q) = %q, %v; want %q", line, got, ok, want)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package cost
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// ErrBudgetExceeded is returned by CheckBudget when a request would take the
+// session past its spending limit
+var ErrBudgetExceeded = errors.New("cost budget exceeded")
+
+// Price is a model's rate in US dollars per million tokens
+type Price struct {
+	InputPerMTok  float64
+	OutputPerMTok float64
+}
+
+// PriceTable maps model names to prices. A model matches its exact name or,
+// failing that, the longest name it starts with, so dated snapshots such as
+// "gpt-4o-2024-08-06" use the "gpt-4o" price.
+type PriceTable map[string]Price
+
+// Lookup returns the price for model
+func (t PriceTable) Lookup(model string) (Price, bool) {
+	model = strings.ToLower(strings.TrimSpace(model))
+	if model == "" {
+		return Price{}, false
+	}
+	if price, ok := t[model]; ok {
+		return price, true
+	}
+	best := ""
+	for name := range t {
+		if len(name) > len(best) && strings.HasPrefix(model, name) {
+			best = name
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return t[best], true
+}
+
+// WithOverrides returns a copy of the table with overrides added, replacing
+// the prices of models both tables name
+func (t PriceTable) WithOverrides(overrides PriceTable) PriceTable {
+	merged := make(PriceTable, len(t)+len(overrides))
+	for name, price := range t {
+		merged[name] = price
+	}
+	for name, price := range overrides {
+		merged[strings.ToLower(strings.TrimSpace(name))] = price
+	}
+	return merged
+}
+
+// PriceModels fills in the price of each model that has none from the table
+func (t PriceTable) PriceModels(models []llm.Model) {
+	for i := range models {
+		if models[i].Priced() {
+			continue
+		}
+		if price, ok := t.Lookup(models[i].ID); ok {
+			models[i].InputPricePerMTok = price.InputPerMTok
+			models[i].OutputPricePerMTok = price.OutputPerMTok
+		}
+	}
+}
+
+// Cost returns the dollar cost of the given token counts at this price
+func (p Price) Cost(inputTokens, outputTokens int) float64 {
+	return (float64(inputTokens)*p.InputPerMTok + float64(outputTokens)*p.OutputPerMTok) / 1_000_000
+}
+
+// DefaultPriceTable returns list prices for OpenAI, Anthropic and Groq models
+func DefaultPriceTable() PriceTable {
+	return PriceTable{
+		// OpenAI
+		"gpt-5":         {InputPerMTok: 1.25, OutputPerMTok: 10.00},
+		"gpt-5-mini":    {InputPerMTok: 0.25, OutputPerMTok: 2.00},
+		"gpt-5-nano":    {InputPerMTok: 0.05, OutputPerMTok: 0.40},
+		"gpt-4.1":       {InputPerMTok: 2.00, OutputPerMTok: 8.00},
+		"gpt-4.1-mini":  {InputPerMTok: 0.40, OutputPerMTok: 1.60},
+		"gpt-4.1-nano":  {InputPerMTok: 0.10, OutputPerMTok: 0.40},
+		"gpt-4o":        {InputPerMTok: 2.50, OutputPerMTok: 10.00},
+		"gpt-4o-mini":   {InputPerMTok: 0.15, OutputPerMTok: 0.60},
+		"gpt-4-turbo":   {InputPerMTok: 10.00, OutputPerMTok: 30.00},
+		"gpt-4":         {InputPerMTok: 30.00, OutputPerMTok: 60.00},
+		"gpt-3.5-turbo": {InputPerMTok: 0.50, OutputPerMTok: 1.50},
+		"o1":            {InputPerMTok: 15.00, OutputPerMTok: 60.00},
+		"o1-mini":       {InputPerMTok: 1.10, OutputPerMTok: 4.40},
+		"o3":            {InputPerMTok: 2.00, OutputPerMTok: 8.00},
+		"o3-mini":       {InputPerMTok: 1.10, OutputPerMTok: 4.40},
+		"o4-mini":       {InputPerMTok: 1.10, OutputPerMTok: 4.40},
+
+		// Anthropic
+		"claude-opus-4-5":   {InputPerMTok: 5.00, OutputPerMTok: 25.00},
+		"claude-opus-4":     {InputPerMTok: 15.00, OutputPerMTok: 75.00},
+		"claude-sonnet-4":   {InputPerMTok: 3.00, OutputPerMTok: 15.00},
+		"claude-haiku-4-5":  {InputPerMTok: 1.00, OutputPerMTok: 5.00},
+		"claude-3-7-sonnet": {InputPerMTok: 3.00, OutputPerMTok: 15.00},
+		"claude-3-5-sonnet": {InputPerMTok: 3.00, OutputPerMTok: 15.00},
+		"claude-3-5-haiku":  {InputPerMTok: 0.80, OutputPerMTok: 4.00},
+		"claude-3-opus":     {InputPerMTok: 15.00, OutputPerMTok: 75.00},
+		"claude-3-sonnet":   {InputPerMTok: 3.00, OutputPerMTok: 15.00},
+		"claude-3-haiku":    {InputPerMTok: 0.25, OutputPerMTok: 1.25},
+
+		// Groq
+		"llama-3.3-70b-versatile":     {InputPerMTok: 0.59, OutputPerMTok: 0.79},
+		"llama-3.1-8b-instant":        {InputPerMTok: 0.05, OutputPerMTok: 0.08},
+		"llama3-70b-8192":             {InputPerMTok: 0.59, OutputPerMTok: 0.79},
+		"llama3-8b-8192":              {InputPerMTok: 0.05, OutputPerMTok: 0.08},
+		"mixtral-8x7b-32768":          {InputPerMTok: 0.24, OutputPerMTok: 0.24},
+		"gemma2-9b-it":                {InputPerMTok: 0.20, OutputPerMTok: 0.20},
+		"openai/gpt-oss-120b":         {InputPerMTok: 0.15, OutputPerMTok: 0.75},
+		"openai/gpt-oss-20b":          {InputPerMTok: 0.10, OutputPerMTok: 0.50},
+		"moonshotai/kimi-k2-instruct": {InputPerMTok: 1.00, OutputPerMTok: 3.00},
+	}
+}
+
+// CostEstimate is the expected cost of a request before it is sent
+type CostEstimate struct {
+	Model        string
+	InputTokens  int     // Estimated from the messages and tool schemas
+	OutputTokens int     // The request's MaxTokens, as an upper bound; 0 when unset
+	USD          float64 // 0 when the model has no price
+	Priced       bool    // Whether the model was found in the price table
+}
+
+// CostRecord is the cost of a completed request from its reported usage
+type CostRecord struct {
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	USD              float64
+	Priced           bool
+}
+
+// SessionCost sums every request recorded by a CostEstimator
+type SessionCost struct {
+	Requests         int
+	PromptTokens     int
+	CompletionTokens int
+	USD              float64
+	Unpriced         int // Requests whose model had no price, so are not in USD
+}
+
+// CostEstimator prices requests against a PriceTable and keeps a running
+// total of what has been spent. It is safe for concurrent use.
+type CostEstimator struct {
+	prices PriceTable
+
+	mu      sync.Mutex
+	session SessionCost
+}
+
+// NewCostEstimator creates an estimator using prices, or DefaultPriceTable
+// when prices is nil
+func NewCostEstimator(prices PriceTable) *CostEstimator {
+	if prices == nil {
+		prices = DefaultPriceTable()
+	}
+	return &CostEstimator{prices: prices}
+}
+
+// Prices returns the table the estimator prices requests with
+func (c *CostEstimator) Prices() PriceTable {
+	return c.prices
+}
+
+// Estimate computes the expected cost of req. Input tokens are approximated
+// at four characters per token; output is bounded by req.MaxTokens.
+func (c *CostEstimator) Estimate(req *llm.ChatRequest) CostEstimate {
+	estimate := CostEstimate{Model: req.Model, InputTokens: estimateRequestTokens(req), OutputTokens: req.MaxTokens}
+	if price, ok := c.prices.Lookup(req.Model); ok {
+		estimate.Priced = true
+		estimate.USD = price.Cost(estimate.InputTokens, estimate.OutputTokens)
+	}
+	return estimate
+}
+
+// Record adds the reported usage of resp to the session
+func (c *CostEstimator) Record(resp *llm.ChatResponse) CostRecord {
+	if resp == nil {
+		return CostRecord{}
+	}
+	return c.RecordUsage(resp.Model, resp.Usage)
+}
+
+// RecordUsage adds usage for model to the session. Streaming callers that
+// have no ChatResponse use it directly. A nil usage records nothing.
+func (c *CostEstimator) RecordUsage(model string, usage *llm.Usage) CostRecord {
+	record := CostRecord{Model: model}
+	if usage == nil {
+		return record
+	}
+	record.PromptTokens = usage.PromptTokens
+	record.CompletionTokens = usage.CompletionTokens
+	if price, ok := c.prices.Lookup(model); ok {
+		record.Priced = true
+		record.USD = price.Cost(usage.PromptTokens, usage.CompletionTokens)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.session.Requests++
+	c.session.PromptTokens += record.PromptTokens
+	c.session.CompletionTokens += record.CompletionTokens
+	c.session.USD += record.USD
+	if !record.Priced {
+		c.session.Unpriced++
+	}
+	return record
+}
+
+// Session returns the totals recorded since the estimator was created
+func (c *CostEstimator) Session() SessionCost {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.session
+}
+
+// CheckBudget estimates req and returns an error wrapping ErrBudgetExceeded
+// if it would take the session's spending past maxUSD. A maxUSD <= 0 means
+// no limit. Unpriced models are always allowed since their cost is unknown.
+func (c *CostEstimator) CheckBudget(req *llm.ChatRequest, maxUSD float64) (CostEstimate, error) {
+	estimate := c.Estimate(req)
+	if maxUSD <= 0 || !estimate.Priced {
+		return estimate, nil
+	}
+	spent := c.Session().USD
+	if spent+estimate.USD > maxUSD {
+		return estimate, fmt.Errorf("%w: request to %s estimated at $%.4f with $%.4f of $%.2f remaining",
+			ErrBudgetExceeded, estimate.Model, estimate.USD, max(maxUSD-spent, 0), maxUSD)
+	}
+	return estimate, nil
+}
+
+// estimateRequestTokens approximates the input tokens of req from the text of
+// its messages and tool schemas
+func estimateRequestTokens(req *llm.ChatRequest) int {
+	chars := 0
+	for _, msg := range req.Messages {
+		chars += utf8.RuneCountInString(llm.GetStringValue(msg.Content))
+		for _, call := range msg.ToolCalls {
+			chars += utf8.RuneCountInString(call.Function.Name) + utf8.RuneCount(call.Function.Arguments)
+		}
+	}
+	if len(req.Tools) > 0 {
+		if data, err := json.Marshal(req.Tools); err == nil {
+			chars += utf8.RuneCount(data)
+		}
+	}
+	// About four characters per token, plus a few tokens of framing per message
+	return (chars+3)/4 + 4*len(req.Messages)
+}
@@ -0,0 +1,124 @@
+package cost
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestPriceTableLookup(t *testing.T) {
+	table := DefaultPriceTable()
+	cases := map[string]Price{
+		"gpt-4o":                     table["gpt-4o"],
+		"gpt-4o-2024-08-06":          table["gpt-4o"],
+		"gpt-4o-mini-2024-07-18":     table["gpt-4o-mini"],
+		"gpt-4-turbo-preview":        table["gpt-4-turbo"],
+		"GPT-4":                      table["gpt-4"],
+		"claude-3-5-sonnet-20241022": table["claude-3-5-sonnet"],
+		"llama-3.3-70b-versatile":    table["llama-3.3-70b-versatile"],
+	}
+	for model, want := range cases {
+		got, ok := table.Lookup(model)
+		if !ok || got != want {
+			t.Errorf("Lookup(%q) = %+v, %v; want %+v", model, got, ok, want)
+		}
+	}
+	for _, model := range []string{"", "llama2", "local-model"} {
+		if _, ok := table.Lookup(model); ok {
+			t.Errorf("Lookup(%q): expected no price", model)
+		}
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	ce := NewCostEstimator(PriceTable{"test-model": {InputPerMTok: 2, OutputPerMTok: 10}})
+	req := &llm.ChatRequest{
+		Model:     "test-model",
+		MaxTokens: 1000,
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: llm.StringPtr(strings.Repeat("s", 396))},
+			{Role: llm.RoleUser, Content: llm.StringPtr(strings.Repeat("u", 400))},
+		},
+	}
+
+	estimate := ce.Estimate(req)
+	// 796 chars -> 199 tokens, plus 4 per message
+	if estimate.InputTokens != 207 || estimate.OutputTokens != 1000 || !estimate.Priced {
+		t.Fatalf("unexpected estimate %+v", estimate)
+	}
+	if want := (207*2.0 + 1000*10.0) / 1e6; !approx(estimate.USD, want) {
+		t.Fatalf("expected $%f, got $%f", want, estimate.USD)
+	}
+
+	req.Model = "unknown"
+	if estimate := ce.Estimate(req); estimate.Priced || estimate.USD != 0 {
+		t.Fatalf("expected an unpriced estimate, got %+v", estimate)
+	}
+}
+
+func TestRecordSumsSession(t *testing.T) {
+	ce := NewCostEstimator(PriceTable{"test-model": {InputPerMTok: 1, OutputPerMTok: 4}})
+
+	first := ce.Record(&llm.ChatResponse{Model: "test-model-0613", Usage: &llm.Usage{PromptTokens: 1_000_000, CompletionTokens: 500_000}})
+	if !first.Priced || !approx(first.USD, 3) {
+		t.Fatalf("unexpected record %+v", first)
+	}
+	ce.RecordUsage("test-model", &llm.Usage{PromptTokens: 500_000})
+	ce.RecordUsage("local-model", &llm.Usage{PromptTokens: 10, CompletionTokens: 5})
+	ce.RecordUsage("test-model", nil)
+
+	session := ce.Session()
+	if session.Requests != 3 || session.Unpriced != 1 || !approx(session.USD, 3.5) {
+		t.Fatalf("unexpected session %+v", session)
+	}
+	if session.PromptTokens != 1_500_010 || session.CompletionTokens != 500_005 {
+		t.Fatalf("unexpected session tokens %+v", session)
+	}
+}
+
+func TestCheckBudget(t *testing.T) {
+	ce := NewCostEstimator(PriceTable{"test-model": {InputPerMTok: 1_000_000, OutputPerMTok: 0}})
+	req := &llm.ChatRequest{Model: "test-model", Messages: []llm.Message{{Role: llm.RoleUser, Content: llm.StringPtr("abcd")}}}
+	// 1 token + 4 framing = $5 per request
+
+	if _, err := ce.CheckBudget(req, 0); err != nil {
+		t.Fatalf("expected no limit with maxUSD 0, got %v", err)
+	}
+	if _, err := ce.CheckBudget(req, 5); err != nil {
+		t.Fatalf("expected a request within budget to pass, got %v", err)
+	}
+
+	ce.RecordUsage("test-model", &llm.Usage{PromptTokens: 1})
+	_, err := ce.CheckBudget(req, 5)
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "$4.0000 of $5.00 remaining") {
+		t.Fatalf("expected the remaining budget in the error, got %v", err)
+	}
+
+	req.Model = "local-model"
+	if _, err := ce.CheckBudget(req, 5); err != nil {
+		t.Fatalf("expected unpriced models to be allowed, got %v", err)
+	}
+}
+
+func TestPriceTableWithOverrides(t *testing.T) {
+	base := PriceTable{"gpt-4o": {InputPerMTok: 2.5, OutputPerMTok: 10}}
+	merged := base.WithOverrides(PriceTable{"GPT-4o": {InputPerMTok: 1, OutputPerMTok: 4}, "my-model": {InputPerMTok: 0.5}})
+
+	if price, _ := merged.Lookup("gpt-4o-2024-08-06"); price.InputPerMTok != 1 {
+		t.Fatalf("expected the override for gpt-4o, got %+v", price)
+	}
+	if _, ok := merged.Lookup("my-model"); !ok {
+		t.Fatal("expected the added model to be priced")
+	}
+	if base["gpt-4o"].InputPerMTok != 2.5 {
+		t.Fatal("expected the base table to be left unchanged")
+	}
+}
@@ -123,7 +123,7 @@ func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatR
 
 // ChatStream is not implemented for DeepSeek yet
 func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
-	return nil, fmt.Errorf("streaming not implemented for DeepSeek client")
+	return nil, fmt.Errorf("%w for DeepSeek client", llm.ErrStreamingNotSupported)
 }
 
 // ListModels returns available DeepSeek models
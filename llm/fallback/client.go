@@ -0,0 +1,141 @@
+package fallback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// Client tries each wrapped client in order until one succeeds
+type Client struct {
+	clients []llm.Client
+}
+
+// NewFallbackClient returns a client that sends each request to clients[0]
+// and, if that fails, to the next client in the slice. Only when every
+// client fails is an error returned, as a *FallbackError.
+//
+// The request's Model names a model of the first provider, so fallback
+// clients receive a copy with Model cleared and use their own default.
+// Model listing and lookups go to the first client only.
+func NewFallbackClient(clients []llm.Client) llm.Client {
+	return &Client{clients: append([]llm.Client(nil), clients...)}
+}
+
+// Attempt records why one provider in the chain failed
+type Attempt struct {
+	Provider string
+	Err      error
+}
+
+// FallbackError is returned when every client in the chain failed
+type FallbackError struct {
+	Attempts []Attempt
+}
+
+func (e *FallbackError) Error() string {
+	if len(e.Attempts) == 0 {
+		return "fallback: no providers configured"
+	}
+	parts := make([]string, len(e.Attempts))
+	for i, attempt := range e.Attempts {
+		parts[i] = fmt.Sprintf("%s: %v", attempt.Provider, attempt.Err)
+	}
+	return fmt.Sprintf("all %d providers failed: %s", len(e.Attempts), strings.Join(parts, "; "))
+}
+
+// Unwrap exposes each provider's error to errors.Is and errors.As
+func (e *FallbackError) Unwrap() []error {
+	errs := make([]error, len(e.Attempts))
+	for i, attempt := range e.Attempts {
+		errs[i] = attempt.Err
+	}
+	return errs
+}
+
+// Chat sends the request to each client in turn until one answers
+func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatResponse, error) {
+	failure := &FallbackError{}
+	for i, client := range c.clients {
+		resp, err := client.Chat(ctx, requestFor(i, request))
+		if err == nil {
+			return resp, nil
+		}
+		failure.Attempts = append(failure.Attempts, Attempt{Provider: ProviderName(client), Err: err})
+		// A cancelled request would fail the same way everywhere
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, failure
+}
+
+// ChatStream opens a stream on the first client that accepts the request.
+// Errors reported inside an already open stream are not retried.
+func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	failure := &FallbackError{}
+	for i, client := range c.clients {
+		events, err := client.ChatStream(ctx, requestFor(i, request))
+		if err == nil {
+			return events, nil
+		}
+		failure.Attempts = append(failure.Attempts, Attempt{Provider: ProviderName(client), Err: err})
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, failure
+}
+
+// ListModels returns the first client's models
+func (c *Client) ListModels(ctx context.Context) ([]llm.Model, error) {
+	if len(c.clients) == 0 {
+		return nil, &FallbackError{}
+	}
+	return c.clients[0].ListModels(ctx)
+}
+
+// GetModel looks the model up on the first client
+func (c *Client) GetModel(ctx context.Context, modelID string) (*llm.Model, error) {
+	if len(c.clients) == 0 {
+		return nil, &FallbackError{}
+	}
+	return c.clients[0].GetModel(ctx, modelID)
+}
+
+// Close closes every client in the chain
+func (c *Client) Close() error {
+	var errs []error
+	for _, client := range c.clients {
+		if err := client.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func requestFor(index int, request *llm.ChatRequest) *llm.ChatRequest {
+	if index == 0 || request == nil || request.Model == "" {
+		return request
+	}
+	copied := *request
+	copied.Model = ""
+	return &copied
+}
+
+// ProviderName labels a client in a FallbackError. Clients can name
+// themselves with a Provider() string method; otherwise the name of the
+// client's package (such as "openai") is used.
+func ProviderName(client llm.Client) string {
+	if named, ok := client.(interface{ Provider() string }); ok {
+		return named.Provider()
+	}
+	name := strings.TrimPrefix(fmt.Sprintf("%T", client), "*")
+	if pkg, _, ok := strings.Cut(name, "."); ok {
+		return pkg
+	}
+	return name
+}
@@ -0,0 +1,159 @@
+package fallback
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+var errRateLimited = errors.New("status 429: rate limited")
+
+// mockClient answers with reply, or fails every call with err
+type mockClient struct {
+	name     string
+	reply    string
+	err      error
+	requests []*llm.ChatRequest
+	closed   bool
+}
+
+func (c *mockClient) Provider() string { return c.name }
+
+func (c *mockClient) Chat(_ context.Context, req *llm.ChatRequest) (*llm.ChatResponse, error) {
+	c.requests = append(c.requests, req)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &llm.ChatResponse{
+		Choices: []llm.Choice{{
+			Message:      llm.Message{Role: llm.RoleAssistant, Content: llm.StringPtr(c.reply)},
+			FinishReason: "stop",
+		}},
+	}, nil
+}
+
+func (c *mockClient) ChatStream(_ context.Context, req *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	c.requests = append(c.requests, req)
+	if c.err != nil {
+		return nil, c.err
+	}
+	events := make(chan llm.StreamEvent, 1)
+	events <- llm.StreamEvent{Choices: []llm.Choice{{Delta: &llm.Message{Content: llm.StringPtr(c.reply)}}}}
+	close(events)
+	return events, nil
+}
+
+func (c *mockClient) ListModels(context.Context) ([]llm.Model, error) {
+	return []llm.Model{{ID: c.name + "-model"}}, c.err
+}
+
+func (c *mockClient) GetModel(context.Context, string) (*llm.Model, error) { return nil, c.err }
+
+func (c *mockClient) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestChatFallsBackWhenFirstClientFails(t *testing.T) {
+	primary := &mockClient{name: "openai", err: errRateLimited}
+	backup := &mockClient{name: "anthropic", reply: "from backup"}
+	client := NewFallbackClient([]llm.Client{primary, backup})
+
+	resp, err := client.Chat(context.Background(), &llm.ChatRequest{Model: "gpt-4o"})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if got := llm.GetStringValue(resp.Choices[0].Message.Content); got != "from backup" {
+		t.Fatalf("expected the backup's answer, got %q", got)
+	}
+	if len(primary.requests) != 1 || primary.requests[0].Model != "gpt-4o" {
+		t.Fatalf("expected the primary to get the request as is, got %+v", primary.requests)
+	}
+	if len(backup.requests) != 1 || backup.requests[0].Model != "" {
+		t.Fatalf("expected the backup to use its own default model, got %+v", backup.requests)
+	}
+}
+
+func TestChatStreamFallsBackWhenFirstClientFails(t *testing.T) {
+	primary := &mockClient{name: "openai", err: errRateLimited}
+	backup := &mockClient{name: "anthropic", reply: "streamed"}
+	client := NewFallbackClient([]llm.Client{primary, backup})
+
+	events, err := client.ChatStream(context.Background(), &llm.ChatRequest{Model: "gpt-4o", Stream: true})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	var content strings.Builder
+	for event := range events {
+		content.WriteString(llm.GetStringValue(event.Choices[0].Delta.Content))
+	}
+	if content.String() != "streamed" {
+		t.Fatalf("expected the backup's stream, got %q", content.String())
+	}
+}
+
+func TestChatReturnsFallbackErrorWhenAllFail(t *testing.T) {
+	unavailable := errors.New("status 503: overloaded")
+	client := NewFallbackClient([]llm.Client{
+		&mockClient{name: "openai", err: errRateLimited},
+		&mockClient{name: "anthropic", err: unavailable},
+	})
+
+	_, err := client.Chat(context.Background(), &llm.ChatRequest{})
+	var fallbackErr *FallbackError
+	if !errors.As(err, &fallbackErr) {
+		t.Fatalf("expected *FallbackError, got %T (%v)", err, err)
+	}
+	if len(fallbackErr.Attempts) != 2 || fallbackErr.Attempts[0].Provider != "openai" || fallbackErr.Attempts[1].Provider != "anthropic" {
+		t.Fatalf("unexpected attempts %+v", fallbackErr.Attempts)
+	}
+	if !errors.Is(err, errRateLimited) || !errors.Is(err, unavailable) {
+		t.Fatalf("expected both provider errors to be wrapped, got %v", err)
+	}
+	for _, want := range []string{"openai: status 429", "anthropic: status 503"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in %q", want, err.Error())
+		}
+	}
+}
+
+func TestChatStopsOnCancelledContext(t *testing.T) {
+	primary := &mockClient{name: "openai", err: context.Canceled}
+	backup := &mockClient{name: "anthropic", reply: "unused"}
+	client := NewFallbackClient([]llm.Client{primary, backup})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.Chat(ctx, &llm.ChatRequest{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancellation error, got %v", err)
+	}
+	if len(backup.requests) != 0 {
+		t.Fatalf("expected no fallback after cancellation")
+	}
+}
+
+func TestListModelsUsesFirstClientAndCloseClosesAll(t *testing.T) {
+	primary := &mockClient{name: "openai"}
+	backup := &mockClient{name: "anthropic"}
+	client := NewFallbackClient([]llm.Client{primary, backup})
+
+	models, err := client.ListModels(context.Background())
+	if err != nil || len(models) != 1 || models[0].ID != "openai-model" {
+		t.Fatalf("expected the first client's models, got %+v, %v", models, err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if !primary.closed || !backup.closed {
+		t.Fatalf("expected every client to be closed")
+	}
+}
+
+func TestProviderNameFallsBackToPackageName(t *testing.T) {
+	if got := ProviderName(&Client{}); got != "fallback" {
+		t.Fatalf("expected the package name, got %q", got)
+	}
+}
@@ -1,7 +1,6 @@
 package groq
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -129,8 +128,9 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest) (<-ch
 		request.Model = c.options.DefaultModel
 	}
 
-	// Enable streaming
+	// Enable streaming and ask for a trailing usage chunk
 	request.Stream = true
+	request.StreamOptions = &llm.StreamOptions{IncludeUsage: true}
 
 	// Create request body
 	body, err := json.Marshal(request)
@@ -170,35 +170,29 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest) (<-ch
 		defer close(events)
 		defer resp.Body.Close()
 
-		scanner := bufio.NewScanner(resp.Body)
-		for scanner.Scan() {
-			line := scanner.Text()
+		reader := llm.NewSSEReader(resp.Body)
+		for {
+			data, err := reader.Next()
+			if err != nil {
+				return
+			}
+
+			// Check for end of stream
+			if data == "[DONE]" {
+				return
+			}
 
-			// Skip empty lines
-			if line == "" {
+			// Parse event
+			var event llm.StreamEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				llm.DebugSkippedEvent("Groq", data, err)
 				continue
 			}
 
-			// Parse SSE event
-			if strings.HasPrefix(line, "data: ") {
-				data := strings.TrimPrefix(line, "data: ")
-
-				// Check for end of stream
-				if data == "[DONE]" {
-					return
-				}
-
-				// Parse event
-				var event llm.StreamEvent
-				if err := json.Unmarshal([]byte(data), &event); err != nil {
-					continue // Skip invalid events
-				}
-
-				select {
-				case events <- event:
-				case <-ctx.Done():
-					return
-				}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
 			}
 		}
 	}()
@@ -287,6 +281,9 @@ func (c *Client) doWithRetries(ctx context.Context, fn func() error) error {
 
 	for i := 0; i <= c.options.MaxRetries; i++ {
 		if i > 0 {
+			if !llm.AllowRetry(ctx) {
+				return fmt.Errorf("retry budget exhausted: %w", lastErr)
+			}
 			// Exponential backoff
 			delay := time.Duration(i) * time.Second
 			select {
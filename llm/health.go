@@ -0,0 +1,20 @@
+package llm
+
+import "context"
+
+// HealthChecker is implemented by clients that can verify provider
+// connectivity and credentials cheaply.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// HealthCheck verifies that client can reach its provider with the configured
+// credentials. Clients that do not implement HealthChecker are probed with
+// ListModels, which every provider authenticates.
+func HealthCheck(ctx context.Context, client Client) error {
+	if checker, ok := client.(HealthChecker); ok {
+		return checker.HealthCheck(ctx)
+	}
+	_, err := client.ListModels(ctx)
+	return err
+}
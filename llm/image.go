@@ -0,0 +1,349 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	// Register GIF decoding so GIF attachments are normalized to PNG.
+	_ "image/gif"
+)
+
+const attachmentJPEGQuality = 90
+
+// LoadImageAttachment reads a local image and returns its bytes with embedded
+// metadata (EXIF, GPS, XMP, text chunks) removed, along with the MIME type of
+// the returned bytes.
+func LoadImageAttachment(path string) ([]byte, string, error) {
+	mime, err := mimeFromImagePath(path)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("read image: %w", err)
+	}
+	return StripImageMetadata(data, mime)
+}
+
+// EncodeImageDataURL reads a local image, strips its metadata, and returns it
+// as a base64 data URL.
+func EncodeImageDataURL(path string) (string, error) {
+	data, mime, err := LoadImageAttachment(path)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("data:%s;base64,%s", mime, base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// PrepareImage loads an image path or base64 data URL the way it will be
+// sent to a provider: metadata is stripped and, when maxDimension is positive,
+// images larger than that are downscaled (see DownscaleImage).
+func PrepareImage(image string, maxDimension int) ([]byte, string, error) {
+	var (
+		raw  []byte
+		mime string
+	)
+	if strings.HasPrefix(strings.ToLower(image), "data:image/") {
+		header, payload, ok := strings.Cut(image, ",")
+		if !ok || !strings.HasSuffix(strings.ToLower(header), ";base64") {
+			return nil, "", fmt.Errorf("unsupported image data URL")
+		}
+		decoded, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return nil, "", fmt.Errorf("decode image data URL: %w", err)
+		}
+		raw = decoded
+		mime = strings.TrimSuffix(strings.TrimPrefix(header, "data:"), ";base64")
+	} else {
+		pathMime, err := mimeFromImagePath(image)
+		if err != nil {
+			return nil, "", err
+		}
+		data, err := os.ReadFile(image)
+		if err != nil {
+			return nil, "", fmt.Errorf("read image: %w", err)
+		}
+		raw = data
+		mime = pathMime
+	}
+
+	data, resizedMime, resized, err := DownscaleImage(raw, maxDimension)
+	if err != nil {
+		return nil, "", err
+	}
+	if resized {
+		return data, resizedMime, nil
+	}
+	return StripImageMetadata(raw, mime)
+}
+
+// PrepareImageDataURL is PrepareImage returning a base64 data URL.
+func PrepareImageDataURL(image string, maxDimension int) (string, error) {
+	data, mime, err := PrepareImage(image, maxDimension)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("data:%s;base64,%s", mime, base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// SanitizeImageDataURL re-encodes a pasted base64 data URL so that any
+// metadata embedded in the original image is dropped.
+func SanitizeImageDataURL(dataURL string) (string, error) {
+	header, payload, ok := strings.Cut(dataURL, ",")
+	if !ok || !strings.HasSuffix(strings.ToLower(header), ";base64") {
+		return "", fmt.Errorf("unsupported image data URL")
+	}
+	raw, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", fmt.Errorf("decode image data URL: %w", err)
+	}
+
+	fallbackMime := strings.TrimSuffix(strings.TrimPrefix(header, "data:"), ";base64")
+	data, mime, err := StripImageMetadata(raw, fallbackMime)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("data:%s;base64,%s", mime, base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// StripImageMetadata decodes and re-encodes image bytes, normalizing JPEG
+// input to JPEG and PNG/GIF input to PNG. Re-encoding only writes pixel data,
+// so EXIF and other metadata never survive; a JPEG's EXIF orientation is
+// applied to the pixels first so phone photos stay upright. Formats the
+// standard library cannot decode (e.g. WebP) are returned unchanged with
+// fallbackMime.
+func StripImageMetadata(data []byte, fallbackMime string) ([]byte, string, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		if _, _, cfgErr := image.DecodeConfig(bytes.NewReader(data)); cfgErr == image.ErrFormat {
+			return data, fallbackMime, nil
+		}
+		return nil, "", fmt.Errorf("decode image: %w", err)
+	}
+	if format == "jpeg" {
+		img = orientImage(img, jpegOrientation(data))
+	}
+
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: attachmentJPEGQuality}); err != nil {
+			return nil, "", fmt.Errorf("encode jpeg: %w", err)
+		}
+		return buf.Bytes(), "image/jpeg", nil
+	}
+
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, "", fmt.Errorf("encode png: %w", err)
+	}
+	return buf.Bytes(), "image/png", nil
+}
+
+// DownscaleImage shrinks an image whose longest side exceeds maxDimension so
+// that side becomes maxDimension, keeping the aspect ratio, and re-encodes it
+// as JPEG (transparent areas are flattened onto white, and a JPEG's EXIF
+// orientation is applied). It reports false and
+// leaves data untouched when maxDimension is 0, the image already fits, or the
+// format cannot be decoded.
+func DownscaleImage(data []byte, maxDimension int) ([]byte, string, bool, error) {
+	if maxDimension <= 0 {
+		return data, "", false, nil
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || max(cfg.Width, cfg.Height) <= maxDimension {
+		return data, "", false, nil
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", false, fmt.Errorf("decode image: %w", err)
+	}
+	if format == "jpeg" {
+		img = orientImage(img, jpegOrientation(data))
+	}
+
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if width >= height {
+		height = max(1, height*maxDimension/width)
+		width = maxDimension
+	} else {
+		width = max(1, width*maxDimension/height)
+		height = maxDimension
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, resizeImage(img, width, height), &jpeg.Options{Quality: attachmentJPEGQuality}); err != nil {
+		return nil, "", false, fmt.Errorf("encode jpeg: %w", err)
+	}
+	return buf.Bytes(), "image/jpeg", true, nil
+}
+
+// resizeImage scales src to width x height by averaging the source pixels
+// each destination pixel covers, which avoids the aliasing of nearest-neighbour
+// sampling when shrinking photos by large factors.
+func resizeImage(src image.Image, width, height int) *image.RGBA {
+	bounds := src.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), src, bounds.Min, draw.Over)
+
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := y * srcH / height
+		y1 := max(y0+1, (y+1)*srcH/height)
+		for x := 0; x < width; x++ {
+			x0 := x * srcW / width
+			x1 := max(x0+1, (x+1)*srcW/width)
+			var r, g, b, n uint32
+			for sy := y0; sy < y1; sy++ {
+				row := flat.Pix[sy*flat.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					px := row[sx*4:]
+					r += uint32(px[0])
+					g += uint32(px[1])
+					b += uint32(px[2])
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = 0xff
+		}
+	}
+	return dst
+}
+
+// jpegOrientation returns the EXIF Orientation tag (1-8) of JPEG data, or 1
+// when the image has none.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xFF { // Fill byte
+			i++
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 { // Image data starts; no EXIF before it
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+size]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+// exifOrientation reads the Orientation tag from IFD0 of a TIFF-structured
+// EXIF payload.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < entries; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) != 0x0112 {
+			continue
+		}
+		if order.Uint16(tiff[entry+2:]) != 3 { // SHORT
+			return 1
+		}
+		if v := int(order.Uint16(tiff[entry+8:])); v >= 1 && v <= 8 {
+			return v
+		}
+		return 1
+	}
+	return 1
+}
+
+// orientImage returns img flipped and rotated so it displays upright for the
+// given EXIF orientation. Orientations 5-8 swap width and height.
+func orientImage(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dstW, dstH := w, h
+	if orientation >= 5 {
+		dstW, dstH = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // Mirrored horizontally
+				dx, dy = w-1-x, y
+			case 3: // Rotated 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // Mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // Transposed
+				dx, dy = y, x
+			case 6: // Needs a 90° clockwise turn
+				dx, dy = h-1-y, x
+			case 7: // Transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // Needs a 90° counter-clockwise turn
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// mimeFromImagePath returns the MIME type for an image file's extension, and
+// an error for extensions that are not supported images.
+func mimeFromImagePath(path string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".png":
+		return "image/png", nil
+	case ".jpg", ".jpeg":
+		return "image/jpeg", nil
+	case ".gif":
+		return "image/gif", nil
+	case ".webp":
+		return "image/webp", nil
+	default:
+		return "", fmt.Errorf("unsupported image type %q for %s: use a .png, .jpg, .jpeg, .gif, or .webp file", ext, path)
+	}
+}
@@ -0,0 +1,291 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// jpegWithGPSExif returns a small JPEG carrying an APP1 EXIF segment whose
+// IFD0 points at a GPS IFD with a latitude reference tag.
+func jpegWithGPSExif(t *testing.T) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 30), G: uint8(y * 30), B: 128, A: 255})
+		}
+	}
+	var plain bytes.Buffer
+	if err := jpeg.Encode(&plain, img, nil); err != nil {
+		t.Fatalf("encode fixture: %v", err)
+	}
+
+	// TIFF header + IFD0 (1 entry: GPSInfo) + GPS IFD (1 entry: GPSLatitudeRef "N").
+	var tiff bytes.Buffer
+	le := binary.LittleEndian
+	tiff.WriteString("II")
+	_ = binary.Write(&tiff, le, uint16(42))
+	_ = binary.Write(&tiff, le, uint32(8))
+	_ = binary.Write(&tiff, le, uint16(1))
+	_ = binary.Write(&tiff, le, [3]uint16{0x8825, 4, 0})
+	_ = binary.Write(&tiff, le, [2]uint32{1, 26})
+	_ = binary.Write(&tiff, le, uint32(0))
+	_ = binary.Write(&tiff, le, uint16(1))
+	_ = binary.Write(&tiff, le, [3]uint16{0x0001, 2, 0})
+	_ = binary.Write(&tiff, le, uint32(2))
+	tiff.Write([]byte{'N', 0, 0, 0})
+	_ = binary.Write(&tiff, le, uint32(0))
+
+	return withExifSegment(plain.Bytes(), tiff.Bytes())
+}
+
+// withExifSegment inserts an APP1 EXIF segment holding tiff right after the
+// JPEG start-of-image marker.
+func withExifSegment(raw, tiff []byte) []byte {
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	var app1 bytes.Buffer
+	app1.Write([]byte{0xFF, 0xE1})
+	_ = binary.Write(&app1, binary.BigEndian, uint16(len(payload)+2))
+	app1.Write(payload)
+
+	out := append([]byte{}, raw[:2]...)
+	out = append(out, app1.Bytes()...)
+	return append(out, raw[2:]...)
+}
+
+// jpegWithOrientation returns a 32x16 JPEG, red on the left half and blue on
+// the right, tagged with the given EXIF orientation.
+func jpegWithOrientation(t *testing.T, orientation uint16) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 32, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 32; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= 16 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var plain bytes.Buffer
+	if err := jpeg.Encode(&plain, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatalf("encode fixture: %v", err)
+	}
+
+	// Big-endian TIFF header + IFD0 with a single Orientation (SHORT) entry.
+	var tiff bytes.Buffer
+	be := binary.BigEndian
+	tiff.WriteString("MM")
+	_ = binary.Write(&tiff, be, uint16(42))
+	_ = binary.Write(&tiff, be, uint32(8))
+	_ = binary.Write(&tiff, be, uint16(1))
+	_ = binary.Write(&tiff, be, [2]uint16{0x0112, 3})
+	_ = binary.Write(&tiff, be, uint32(1))
+	_ = binary.Write(&tiff, be, [2]uint16{orientation, 0})
+	_ = binary.Write(&tiff, be, uint32(0))
+	return withExifSegment(plain.Bytes(), tiff.Bytes())
+}
+
+func TestEncodeImageDataURL_StripsExifGPS(t *testing.T) {
+	fixture := jpegWithGPSExif(t)
+	if !bytes.Contains(fixture, []byte("Exif")) {
+		t.Fatalf("fixture should contain EXIF data")
+	}
+	if _, _, err := image.Decode(bytes.NewReader(fixture)); err != nil {
+		t.Fatalf("fixture should be a valid JPEG: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "phone.jpg")
+	if err := os.WriteFile(path, fixture, 0644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	dataURL, err := EncodeImageDataURL(path)
+	if err != nil {
+		t.Fatalf("EncodeImageDataURL: %v", err)
+	}
+	if !strings.HasPrefix(dataURL, "data:image/jpeg;base64,") {
+		t.Fatalf("unexpected data URL prefix: %.40s", dataURL)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(dataURL, "data:image/jpeg;base64,"))
+	if err != nil {
+		t.Fatalf("decode data URL: %v", err)
+	}
+	if bytes.Contains(decoded, []byte("Exif")) {
+		t.Fatalf("expected EXIF to be stripped from output")
+	}
+	if _, format, err := image.Decode(bytes.NewReader(decoded)); err != nil || format != "jpeg" {
+		t.Fatalf("expected decodable jpeg output, got format=%q err=%v", format, err)
+	}
+}
+
+func TestSanitizeImageDataURL_StripsExif(t *testing.T) {
+	in := "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(jpegWithGPSExif(t))
+
+	out, err := SanitizeImageDataURL(in)
+	if err != nil {
+		t.Fatalf("SanitizeImageDataURL: %v", err)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(out[strings.Index(out, ",")+1:])
+	if err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if bytes.Contains(decoded, []byte("Exif")) {
+		t.Fatalf("expected EXIF to be stripped from pasted data URL")
+	}
+}
+
+func TestStripImageMetadata_PassesThroughUnknownFormats(t *testing.T) {
+	raw := []byte("RIFF\x00\x00\x00\x00WEBPVP8 ")
+
+	out, mime, err := StripImageMetadata(raw, "image/webp")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mime != "image/webp" || !bytes.Equal(out, raw) {
+		t.Fatalf("expected unknown format to pass through unchanged, got mime=%q", mime)
+	}
+}
+
+func TestStripImageMetadata_AppliesExifOrientation(t *testing.T) {
+	isRed := func(c color.Color) bool {
+		r, _, b, _ := c.RGBA()
+		return r > 0xC000 && b < 0x4000
+	}
+
+	// Orientation 6 means the camera was turned a quarter clockwise, so the
+	// left (red) half must end up on top.
+	out, mime, err := StripImageMetadata(jpegWithOrientation(t, 6), "image/jpeg")
+	if err != nil || mime != "image/jpeg" {
+		t.Fatalf("StripImageMetadata: mime %q, err %v", mime, err)
+	}
+	img, _, err := image.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 16 || b.Dy() != 32 {
+		t.Fatalf("expected the rotated image to be 16x32, got %dx%d", b.Dx(), b.Dy())
+	}
+	if !isRed(img.At(8, 4)) || isRed(img.At(8, 28)) {
+		t.Fatalf("expected red on top and blue below after rotating")
+	}
+	if bytes.Contains(out, []byte("Exif")) {
+		t.Fatalf("expected EXIF to be stripped after applying the orientation")
+	}
+
+	// Orientation 3 is upside down: the red half moves to the right.
+	out, _, err = StripImageMetadata(jpegWithOrientation(t, 3), "image/jpeg")
+	if err != nil {
+		t.Fatalf("StripImageMetadata: %v", err)
+	}
+	if img, _, err = image.Decode(bytes.NewReader(out)); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if isRed(img.At(4, 8)) || !isRed(img.At(28, 8)) {
+		t.Fatalf("expected the image to be turned 180 degrees")
+	}
+}
+
+func TestLoadImageAttachment_RejectsUnknownExtensions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, jpegWithGPSExif(t), 0644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	if _, _, err := LoadImageAttachment(path); err == nil || !strings.Contains(err.Error(), "unsupported image type") {
+		t.Fatalf("expected an unsupported image type error, got %v", err)
+	}
+	if _, _, err := PrepareImage(path, 0); err == nil {
+		t.Fatalf("expected PrepareImage to reject a .txt path")
+	}
+}
+
+// noisyPNG writes a width x height PNG of pseudo-random pixels, which PNG
+// cannot compress much, standing in for a large photo.
+func noisyPNG(t *testing.T, width, height int) string {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	seed := uint32(1)
+	for i := range img.Pix {
+		seed = seed*1664525 + 1013904223
+		img.Pix[i] = uint8(seed >> 24)
+	}
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 0xff
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode fixture: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "large.png")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	return path
+}
+
+func TestPrepareImage_DownscalesLargeImages(t *testing.T) {
+	path := noisyPNG(t, 2000, 1000)
+
+	original, originalMime, err := PrepareImage(path, 0)
+	if err != nil {
+		t.Fatalf("PrepareImage without limit: %v", err)
+	}
+	if originalMime != "image/png" {
+		t.Fatalf("expected the image to be sent as PNG without a limit, got %s", originalMime)
+	}
+
+	resized, mime, err := PrepareImage(path, 500)
+	if err != nil {
+		t.Fatalf("PrepareImage: %v", err)
+	}
+	if mime != "image/jpeg" {
+		t.Fatalf("expected a downscaled image to be re-encoded as JPEG, got %s", mime)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(resized))
+	if err != nil || format != "jpeg" {
+		t.Fatalf("expected a decodable JPEG, got format %q err %v", format, err)
+	}
+	if cfg.Width != 500 || cfg.Height != 250 {
+		t.Fatalf("expected 500x250 keeping the aspect ratio, got %dx%d", cfg.Width, cfg.Height)
+	}
+	if len(resized) >= len(original)/4 {
+		t.Fatalf("expected downscaling to shrink the payload, got %d bytes from %d", len(resized), len(original))
+	}
+
+	url, err := PrepareImageDataURL(path, 500)
+	if err != nil {
+		t.Fatalf("PrepareImageDataURL: %v", err)
+	}
+	if want := "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(resized); url != want {
+		t.Fatalf("expected the data URL to carry the downscaled JPEG")
+	}
+}
+
+func TestDownscaleImage_LeavesSmallImagesAlone(t *testing.T) {
+	data, err := os.ReadFile(noisyPNG(t, 64, 32))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	out, _, resized, err := DownscaleImage(data, 64)
+	if err != nil {
+		t.Fatalf("DownscaleImage: %v", err)
+	}
+	if resized || !bytes.Equal(out, data) {
+		t.Fatalf("expected an image within the limit to be returned unchanged")
+	}
+	if _, _, resized, _ := DownscaleImage([]byte("not an image"), 16); resized {
+		t.Fatalf("expected undecodable data to be left alone")
+	}
+}
@@ -1,10 +1,8 @@
 package lmstudio
 
 import (
-	"bufio"
 	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -217,35 +215,29 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest) (<-ch
 		defer close(events)
 		defer resp.Body.Close()
 
-		scanner := bufio.NewScanner(resp.Body)
-		for scanner.Scan() {
-			line := scanner.Text()
-
-			// Skip empty lines
-			if line == "" {
-				continue
+		reader := llm.NewSSEReader(resp.Body)
+		for {
+			data, err := reader.Next()
+			if err != nil {
+				return
 			}
 
-			// Parse SSE event
-			if strings.HasPrefix(line, "data: ") {
-				data := strings.TrimPrefix(line, "data: ")
-
-				// Check for end of stream
-				if data == "[DONE]" {
-					return
-				}
+			// Check for end of stream
+			if data == "[DONE]" {
+				return
+			}
 
-				// Parse event
-				var event llm.StreamEvent
-				if err := json.Unmarshal([]byte(data), &event); err != nil {
-					continue // Skip invalid events
-				}
+			// Parse event
+			var event llm.StreamEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				llm.DebugSkippedEvent("LM Studio", data, err)
+				continue
+			}
 
-				select {
-				case events <- event:
-				case <-ctx.Done():
-					return
-				}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
 			}
 		}
 	}()
@@ -281,8 +273,15 @@ func (c *Client) ListModels(ctx context.Context) ([]llm.Model, error) {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	// The OpenAI-compatible listing carries no context length; LM Studio's
+	// native API does. It is optional, so a failure only leaves it unset.
+	contextLengths, _ := c.contextLengths(ctx)
+
 	// Set OwnedBy and vision flag for known vision-capable IDs
 	for i := range response.Data {
+		if length, ok := contextLengths[response.Data[i].ID]; ok {
+			response.Data[i].ContextWindow = length
+		}
 		if response.Data[i].OwnedBy == "" {
 			response.Data[i].OwnedBy = "local"
 		}
@@ -301,6 +300,55 @@ func (c *Client) ListModels(ctx context.Context) ([]llm.Model, error) {
 	return response.Data, nil
 }
 
+// nativeModel is an entry from LM Studio's /api/v0/models endpoint
+type nativeModel struct {
+	ID                  string `json:"id"`
+	MaxContextLength    int    `json:"max_context_length"`
+	LoadedContextLength int    `json:"loaded_context_length"`
+}
+
+// contextLengths returns the context length of each model keyed by ID. A
+// loaded model reports the length it was loaded with, which is what requests
+// are limited to; otherwise the model's maximum is used.
+func (c *Client) contextLengths(ctx context.Context) (map[string]int, error) {
+	nativeURL := strings.TrimSuffix(strings.TrimSuffix(c.options.BaseURL, "/"), "/v1") + "/api/v0/models"
+	req, err := http.NewRequestWithContext(ctx, "GET", nativeURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.setHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("LM Studio error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var response struct {
+		Data []nativeModel `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	lengths := make(map[string]int, len(response.Data))
+	for _, m := range response.Data {
+		switch {
+		case m.LoadedContextLength > 0:
+			lengths[m.ID] = m.LoadedContextLength
+		case m.MaxContextLength > 0:
+			lengths[m.ID] = m.MaxContextLength
+		}
+	}
+	return lengths, nil
+}
+
 // GetModel returns details about a specific model
 func (c *Client) GetModel(ctx context.Context, modelID string) (*llm.Model, error) {
 	models, err := c.ListModels(ctx)
@@ -374,22 +422,11 @@ type lmChatReq struct {
 	Stream      bool        `json:"stream,omitempty"`
 }
 
-// encodeImageToDataURL converts an image to data URL format
-func (c *Client) encodeImageToDataURL(imagePath string) (string, error) {
-	data, err := os.ReadFile(imagePath)
-	if err != nil {
-		return "", fmt.Errorf("read image: %w", err)
-	}
-	mime := "image/jpeg"
-	if strings.HasSuffix(strings.ToLower(imagePath), ".png") {
-		mime = "image/png"
-	} else if strings.HasSuffix(strings.ToLower(imagePath), ".gif") {
-		mime = "image/gif"
-	} else if strings.HasSuffix(strings.ToLower(imagePath), ".webp") {
-		mime = "image/webp"
-	}
-	b64 := base64.StdEncoding.EncodeToString(data)
-	return fmt.Sprintf("data:%s;base64,%s", mime, b64), nil
+// encodeImageToDataURL converts an image path or pasted data URL into a data
+// URL with any embedded metadata (EXIF, GPS) stripped, downscaling it first
+// when the client was created with llm.WithImageMaxDimension.
+func (c *Client) encodeImageToDataURL(image string) (string, error) {
+	return llm.PrepareImageDataURL(image, c.options.ImageMaxDimension)
 }
 
 // ChatWithImages sends a prompt + images using LM Studio's OpenAI-compatible API
@@ -397,15 +434,9 @@ func (c *Client) ChatWithImages(prompt string, imagePaths []string, opts map[str
 	// Build content array
 	parts := []lmContentPart{{Type: "text", Text: prompt}}
 	for _, p := range imagePaths {
-		var url string
-		if strings.HasPrefix(strings.ToLower(p), "data:image/") {
-			url = p
-		} else {
-			var err error
-			url, err = c.encodeImageToDataURL(p)
-			if err != nil {
-				return "", err
-			}
+		url, err := c.encodeImageToDataURL(p)
+		if err != nil {
+			return "", err
 		}
 		parts = append(parts, lmContentPart{Type: "image_url", ImageURL: &lmImageURL{URL: url}})
 	}
@@ -465,15 +496,9 @@ func (c *Client) StreamChatWithImages(prompt string, imagePaths []string, opts m
 	// Build content array
 	parts := []lmContentPart{{Type: "text", Text: prompt}}
 	for _, p := range imagePaths {
-		var url string
-		if strings.HasPrefix(strings.ToLower(p), "data:image/") {
-			url = p
-		} else {
-			var err error
-			url, err = c.encodeImageToDataURL(p)
-			if err != nil {
-				return nil, err
-			}
+		url, err := c.encodeImageToDataURL(p)
+		if err != nil {
+			return nil, err
 		}
 		parts = append(parts, lmContentPart{Type: "image_url", ImageURL: &lmImageURL{URL: url}})
 	}
@@ -517,16 +542,12 @@ func (c *Client) StreamChatWithImages(prompt string, imagePaths []string, opts m
 	go func() {
 		defer close(ch)
 		defer resp.Body.Close()
-		scanner := bufio.NewScanner(resp.Body)
-		for scanner.Scan() {
-			line := scanner.Text()
-			if line == "" {
-				continue
-			}
-			if !strings.HasPrefix(line, "data: ") {
-				continue
+		reader := llm.NewSSEReader(resp.Body)
+		for {
+			data, err := reader.Next()
+			if err != nil {
+				return
 			}
-			data := strings.TrimPrefix(line, "data: ")
 			if data == "[DONE]" {
 				return
 			}
@@ -538,6 +559,7 @@ func (c *Client) StreamChatWithImages(prompt string, imagePaths []string, opts m
 				} `json:"choices"`
 			}
 			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				llm.DebugSkippedEvent("LM Studio", data, err)
 				continue
 			}
 			if len(event.Choices) > 0 && event.Choices[0].Delta.Content != "" {
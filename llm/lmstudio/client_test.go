@@ -0,0 +1,78 @@
+package lmstudio
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+func TestListModelsReadsContextLengthFromNativeAPI(t *testing.T) {
+	native, err := os.ReadFile(filepath.Join("testdata", "api_v0_models.json"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/models":
+			w.Write([]byte(`{"object":"list","data":[{"id":"qwen2.5-7b-instruct","object":"model","owned_by":"organization_owner"},{"id":"meta-llama-3.1-8b-instruct","object":"model","owned_by":"organization_owner"},{"id":"text-embedding-nomic","object":"model"}]}`))
+		case "/api/v0/models":
+			w.Write(native)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(llm.WithBaseURL(srv.URL + "/v1"))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	models, err := client.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels: %v", err)
+	}
+
+	want := map[string]int{
+		"qwen2.5-7b-instruct":        8192,   // loaded length wins
+		"meta-llama-3.1-8b-instruct": 131072, // falls back to the maximum
+		"text-embedding-nomic":       0,
+	}
+	if len(models) != len(want) {
+		t.Fatalf("expected %d models, got %d", len(want), len(models))
+	}
+	for _, model := range models {
+		if model.ContextWindow != want[model.ID] {
+			t.Fatalf("%s: expected context window %d, got %d", model.ID, want[model.ID], model.ContextWindow)
+		}
+	}
+}
+
+func TestListModelsWithoutNativeAPI(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"object":"list","data":[{"id":"qwen2.5-7b-instruct","object":"model"}]}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(llm.WithBaseURL(srv.URL + "/v1"))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	models, err := client.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels should not fail when /api/v0 is unavailable: %v", err)
+	}
+	if len(models) != 1 || models[0].ContextWindow != 0 {
+		t.Fatalf("unexpected models %+v", models)
+	}
+}
@@ -0,0 +1,320 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+var errImagesUnsupported = errors.New("this provider client does not support images")
+
+// redacted replaces message content and tool arguments that the options
+// exclude from the log
+const redacted = "[redacted]"
+
+// LoggingOptions controls how much of each request and response is logged
+type LoggingOptions struct {
+	IncludeContent       bool // Log message content; off by default since prompts may be private
+	IncludeToolArguments bool // Log tool call arguments; off by default for the same reason
+	MaxContentLen        int  // Truncate logged content and arguments to this many runes; 0 means no limit
+}
+
+// LoggingClient wraps an llm.Client and writes one JSON line per request and
+// per response to a writer. Requests record the model, message count and
+// tools offered; responses record the finish reason, token usage and latency.
+// A request and its response share an "id".
+type LoggingClient struct {
+	inner llm.Client
+	opts  LoggingOptions
+
+	mu  sync.Mutex // serializes writes to w
+	w   io.Writer
+	seq atomic.Int64
+	now func() time.Time
+}
+
+// NewLoggingClient wraps inner so that every Chat and ChatStream call is
+// logged to w
+func NewLoggingClient(inner llm.Client, w io.Writer, opts LoggingOptions) llm.Client {
+	return &LoggingClient{inner: inner, w: w, opts: opts, now: time.Now}
+}
+
+type requestRecord struct {
+	Time         string       `json:"time"`
+	Event        string       `json:"event"`
+	ID           int64        `json:"id"`
+	Stream       bool         `json:"stream"`
+	Model        string       `json:"model"`
+	MessageCount int          `json:"message_count"`
+	Tools        []string     `json:"tools,omitempty"`
+	Messages     []messageLog `json:"messages,omitempty"`
+}
+
+type responseRecord struct {
+	Time         string        `json:"time"`
+	Event        string        `json:"event"`
+	ID           int64         `json:"id"`
+	Stream       bool          `json:"stream"`
+	Model        string        `json:"model,omitempty"`
+	FinishReason string        `json:"finish_reason,omitempty"`
+	Usage        *llm.Usage    `json:"usage,omitempty"`
+	LatencyMS    int64         `json:"latency_ms"`
+	Content      string        `json:"content,omitempty"`
+	ToolCalls    []toolCallLog `json:"tool_calls,omitempty"`
+	Error        string        `json:"error,omitempty"`
+}
+
+type messageLog struct {
+	Role      llm.Role      `json:"role"`
+	Content   string        `json:"content,omitempty"`
+	ToolCalls []toolCallLog `json:"tool_calls,omitempty"`
+}
+
+type toolCallLog struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments,omitempty"`
+}
+
+// Chat logs the request, forwards it, and logs the response or error
+func (c *LoggingClient) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatResponse, error) {
+	id := c.logRequest(request, false)
+	start := c.now()
+
+	resp, err := c.inner.Chat(ctx, request)
+
+	record := responseRecord{Event: "response", ID: id, LatencyMS: c.now().Sub(start).Milliseconds()}
+	if err != nil {
+		record.Error = err.Error()
+	} else if resp != nil {
+		record.Model = resp.Model
+		record.Usage = resp.Usage
+		if len(resp.Choices) > 0 {
+			choice := resp.Choices[0]
+			record.FinishReason = choice.FinishReason
+			record.Content = c.content(llm.GetStringValue(choice.Message.Content))
+			record.ToolCalls = c.toolCalls(choice.Message.ToolCalls)
+		}
+	}
+	c.write(&record.Time, record)
+	return resp, err
+}
+
+// ChatStream logs the request and, once the stream ends, a single response
+// record assembled from its events
+func (c *LoggingClient) ChatStream(ctx context.Context, request *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	id := c.logRequest(request, true)
+	start := c.now()
+
+	events, err := c.inner.ChatStream(ctx, request)
+	if err != nil {
+		record := responseRecord{Event: "response", ID: id, Stream: true, LatencyMS: c.now().Sub(start).Milliseconds(), Error: err.Error()}
+		c.write(&record.Time, record)
+		return nil, err
+	}
+
+	out := make(chan llm.StreamEvent)
+	go func() {
+		defer close(out)
+		record := responseRecord{Event: "response", ID: id, Stream: true}
+		var content []byte
+		var calls []llm.ToolCall
+		for event := range events {
+			if event.Model != "" {
+				record.Model = event.Model
+			}
+			if event.Usage != nil {
+				record.Usage = event.Usage
+			}
+			for _, choice := range event.Choices {
+				if choice.FinishReason != "" {
+					record.FinishReason = choice.FinishReason
+				}
+				if choice.Delta == nil {
+					continue
+				}
+				if choice.Delta.Content != nil {
+					content = append(content, *choice.Delta.Content...)
+				}
+				calls = mergeToolCallDeltas(calls, choice.Delta.ToolCalls)
+			}
+			out <- event
+		}
+		record.LatencyMS = c.now().Sub(start).Milliseconds()
+		record.Content = c.content(string(content))
+		record.ToolCalls = c.toolCalls(calls)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			record.Error = ctxErr.Error()
+		}
+		c.write(&record.Time, record)
+	}()
+	return out, nil
+}
+
+// ListModels passes through to the wrapped client
+func (c *LoggingClient) ListModels(ctx context.Context) ([]llm.Model, error) {
+	return c.inner.ListModels(ctx)
+}
+
+// GetModel passes through to the wrapped client
+func (c *LoggingClient) GetModel(ctx context.Context, modelID string) (*llm.Model, error) {
+	return c.inner.GetModel(ctx, modelID)
+}
+
+// Close closes the wrapped client
+func (c *LoggingClient) Close() error {
+	return c.inner.Close()
+}
+
+// HealthCheck checks the wrapped client
+func (c *LoggingClient) HealthCheck(ctx context.Context) error {
+	return llm.HealthCheck(ctx, c.inner)
+}
+
+// ChatWithImages passes through to the wrapped client when it supports
+// images, so wrapping a vision-capable client keeps /attach working. These
+// single-turn calls are not logged.
+func (c *LoggingClient) ChatWithImages(prompt string, imagePaths []string, opts map[string]interface{}) (string, error) {
+	mm, ok := c.inner.(llm.MultimodalClient)
+	if !ok {
+		return "", errImagesUnsupported
+	}
+	return mm.ChatWithImages(prompt, imagePaths, opts)
+}
+
+// StreamChatWithImages passes through to the wrapped client when it supports
+// images
+func (c *LoggingClient) StreamChatWithImages(prompt string, imagePaths []string, opts map[string]interface{}) (<-chan string, error) {
+	mm, ok := c.inner.(llm.MultimodalClient)
+	if !ok {
+		return nil, errImagesUnsupported
+	}
+	return mm.StreamChatWithImages(prompt, imagePaths, opts)
+}
+
+func (c *LoggingClient) logRequest(request *llm.ChatRequest, stream bool) int64 {
+	id := c.seq.Add(1)
+	record := requestRecord{
+		Event:        "request",
+		ID:           id,
+		Stream:       stream,
+		Model:        request.Model,
+		MessageCount: len(request.Messages),
+		Tools:        toolNames(request.Tools),
+	}
+	if c.opts.IncludeContent || c.opts.IncludeToolArguments {
+		record.Messages = make([]messageLog, len(request.Messages))
+		for i, msg := range request.Messages {
+			record.Messages[i] = messageLog{
+				Role:      msg.Role,
+				Content:   c.content(llm.GetStringValue(msg.Content)),
+				ToolCalls: c.toolCalls(msg.ToolCalls),
+			}
+		}
+	}
+	c.write(&record.Time, record)
+	return id
+}
+
+// write stamps the record's time and appends it to the log as one line.
+// Logging failures are ignored so they never break a request.
+func (c *LoggingClient) write(stamp *string, record interface{}) {
+	*stamp = c.now().UTC().Format(time.RFC3339Nano)
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, _ = c.w.Write(append(data, '\n'))
+}
+
+// content returns s as it should appear in the log: redacted unless content
+// logging is on, and truncated to MaxContentLen.
+func (c *LoggingClient) content(s string) string {
+	if s == "" {
+		return ""
+	}
+	if !c.opts.IncludeContent {
+		return redacted
+	}
+	return c.truncate(s)
+}
+
+func (c *LoggingClient) toolCalls(calls []llm.ToolCall) []toolCallLog {
+	if len(calls) == 0 {
+		return nil
+	}
+	logged := make([]toolCallLog, len(calls))
+	for i, call := range calls {
+		logged[i] = toolCallLog{Name: call.Function.Name}
+		if len(call.Function.Arguments) == 0 {
+			continue
+		}
+		if c.opts.IncludeToolArguments {
+			_, args := llm.NormalizeToolArguments(call.Function.Arguments)
+			logged[i].Arguments = c.truncate(string(args))
+		} else {
+			logged[i].Arguments = redacted
+		}
+	}
+	return logged
+}
+
+func (c *LoggingClient) truncate(s string) string {
+	if c.opts.MaxContentLen <= 0 {
+		return s
+	}
+	runes := []rune(s)
+	if len(runes) <= c.opts.MaxContentLen {
+		return s
+	}
+	return string(runes[:c.opts.MaxContentLen]) + "..."
+}
+
+// toolNames returns the sorted function names from OpenAI-style tool schemas
+func toolNames(tools []map[string]interface{}) []string {
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		if fn, ok := tool["function"].(map[string]interface{}); ok {
+			if name, ok := fn["name"].(string); ok {
+				names = append(names, name)
+				continue
+			}
+		}
+		if name, ok := tool["name"].(string); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// mergeToolCallDeltas folds streamed tool call fragments into calls. A delta
+// with an ID starts a new call; one without continues the last call's
+// arguments. Argument fragments sent as JSON strings are unquoted so the
+// pieces join into the arguments object.
+func mergeToolCallDeltas(calls []llm.ToolCall, deltas []llm.ToolCall) []llm.ToolCall {
+	for _, delta := range deltas {
+		if delta.ID != "" || len(calls) == 0 {
+			calls = append(calls, llm.ToolCall{ID: delta.ID})
+		}
+		last := &calls[len(calls)-1]
+		if last.Function.Name == "" {
+			last.Function.Name = delta.Function.Name
+		}
+		fragment := delta.Function.Arguments
+		var unquoted string
+		if len(fragment) > 0 && fragment[0] == '"' && json.Unmarshal(fragment, &unquoted) == nil {
+			fragment = json.RawMessage(unquoted)
+		}
+		last.Function.Arguments = append(last.Function.Arguments, fragment...)
+	}
+	return calls
+}
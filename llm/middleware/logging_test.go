@@ -0,0 +1,224 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// stubClient answers Chat with resp and ChatStream with events.
+type stubClient struct {
+	resp   *llm.ChatResponse
+	err    error
+	events []llm.StreamEvent
+}
+
+func (c *stubClient) Chat(context.Context, *llm.ChatRequest) (*llm.ChatResponse, error) {
+	return c.resp, c.err
+}
+
+func (c *stubClient) ChatStream(context.Context, *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	ch := make(chan llm.StreamEvent, len(c.events))
+	for _, event := range c.events {
+		ch <- event
+	}
+	close(ch)
+	return ch, nil
+}
+
+func (c *stubClient) ListModels(context.Context) ([]llm.Model, error)      { return nil, nil }
+func (c *stubClient) GetModel(context.Context, string) (*llm.Model, error) { return nil, nil }
+func (c *stubClient) Close() error                                         { return nil }
+
+const secret = "my password is hunter2"
+
+func secretRequest() *llm.ChatRequest {
+	return &llm.ChatRequest{
+		Model: "gpt-4o",
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: llm.StringPtr("You are helpful.")},
+			{Role: llm.RoleUser, Content: llm.StringPtr(secret)},
+		},
+		Tools: []map[string]interface{}{
+			{"type": "function", "function": map[string]interface{}{"name": "read"}},
+			{"type": "function", "function": map[string]interface{}{"name": "bash"}},
+		},
+	}
+}
+
+func secretResponse() *llm.ChatResponse {
+	return &llm.ChatResponse{
+		Model: "gpt-4o-2024-08-06",
+		Choices: []llm.Choice{{
+			Message: llm.Message{
+				Role:    llm.RoleAssistant,
+				Content: llm.StringPtr("I will not repeat " + secret),
+				ToolCalls: []llm.ToolCall{{
+					ID:       "call_1",
+					Function: llm.FunctionCall{Name: "bash", Arguments: json.RawMessage(`{"command":"echo hunter2"}`)},
+				}},
+			},
+			FinishReason: "tool_calls",
+		}},
+		Usage: &llm.Usage{PromptTokens: 12, CompletionTokens: 5, TotalTokens: 17},
+	}
+}
+
+// newClock returns a clock that advances by step on every call.
+func newClock(step time.Duration) func() time.Time {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line is not JSON: %q: %v", line, err)
+		}
+		lines = append(lines, record)
+	}
+	return lines
+}
+
+func TestLoggingClient_RedactsContentByDefault(t *testing.T) {
+	var buf bytes.Buffer
+	client := NewLoggingClient(&stubClient{resp: secretResponse()}, &buf, LoggingOptions{})
+	client.(*LoggingClient).now = newClock(250 * time.Millisecond)
+
+	if _, err := client.Chat(context.Background(), secretRequest()); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	if strings.Contains(buf.String(), "hunter2") || strings.Contains(buf.String(), "You are helpful") {
+		t.Fatalf("expected content and tool arguments to be redacted, got:\n%s", buf.String())
+	}
+
+	lines := logLines(t, &buf)
+	if len(lines) != 2 {
+		t.Fatalf("expected a request and a response line, got %d", len(lines))
+	}
+	request, response := lines[0], lines[1]
+	if request["event"] != "request" || request["model"] != "gpt-4o" || request["message_count"] != float64(2) {
+		t.Fatalf("unexpected request record %v", request)
+	}
+	if tools, _ := request["tools"].([]interface{}); len(tools) != 2 || tools[0] != "bash" || tools[1] != "read" {
+		t.Fatalf("expected tool names in the request record, got %v", request["tools"])
+	}
+	if _, ok := request["messages"]; ok {
+		t.Fatalf("expected no messages in the request record, got %v", request["messages"])
+	}
+
+	if response["event"] != "response" || response["id"] != request["id"] {
+		t.Fatalf("expected the response to share the request id, got %v", response)
+	}
+	if response["finish_reason"] != "tool_calls" || response["latency_ms"] != float64(250) {
+		t.Fatalf("unexpected response record %v", response)
+	}
+	if usage, _ := response["usage"].(map[string]interface{}); usage["total_tokens"] != float64(17) {
+		t.Fatalf("expected token usage in the response record, got %v", response["usage"])
+	}
+	if response["content"] != redacted {
+		t.Fatalf("expected response content to be redacted, got %v", response["content"])
+	}
+	calls, _ := response["tool_calls"].([]interface{})
+	if len(calls) != 1 {
+		t.Fatalf("expected one tool call, got %v", response["tool_calls"])
+	}
+	if call := calls[0].(map[string]interface{}); call["name"] != "bash" || call["arguments"] != redacted {
+		t.Fatalf("expected the tool name with redacted arguments, got %v", call)
+	}
+}
+
+func TestLoggingClient_IncludesContentWhenEnabled(t *testing.T) {
+	var buf bytes.Buffer
+	client := NewLoggingClient(&stubClient{resp: secretResponse()}, &buf, LoggingOptions{
+		IncludeContent:       true,
+		IncludeToolArguments: true,
+		MaxContentLen:        10,
+	})
+
+	if _, err := client.Chat(context.Background(), secretRequest()); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	lines := logLines(t, &buf)
+	messages, _ := lines[0]["messages"].([]interface{})
+	if len(messages) != 2 {
+		t.Fatalf("expected both messages in the request record, got %v", lines[0]["messages"])
+	}
+	if got := messages[1].(map[string]interface{})["content"]; got != "my passwor..." {
+		t.Fatalf("expected truncated user content, got %v", got)
+	}
+	call := lines[1]["tool_calls"].([]interface{})[0].(map[string]interface{})
+	if call["arguments"] != `{"command"...` {
+		t.Fatalf("expected truncated tool arguments, got %v", call["arguments"])
+	}
+}
+
+func TestLoggingClient_LogsErrors(t *testing.T) {
+	var buf bytes.Buffer
+	client := NewLoggingClient(&stubClient{err: errors.New("status 503")}, &buf, LoggingOptions{})
+
+	if _, err := client.Chat(context.Background(), secretRequest()); err == nil {
+		t.Fatalf("expected the wrapped error")
+	}
+	if _, err := client.ChatStream(context.Background(), secretRequest()); err == nil {
+		t.Fatalf("expected the wrapped error")
+	}
+
+	lines := logLines(t, &buf)
+	if len(lines) != 4 || lines[1]["error"] != "status 503" || lines[3]["error"] != "status 503" {
+		t.Fatalf("expected error responses to be logged, got %v", lines)
+	}
+}
+
+func TestLoggingClient_LogsStreamOnceItEnds(t *testing.T) {
+	var buf bytes.Buffer
+	inner := &stubClient{events: []llm.StreamEvent{
+		{Model: "gpt-4o", Choices: []llm.Choice{{Delta: &llm.Message{Content: llm.StringPtr("Hello ")}}}},
+		{Choices: []llm.Choice{{Delta: &llm.Message{ToolCalls: []llm.ToolCall{{ID: "call_1", Function: llm.FunctionCall{Name: "read", Arguments: json.RawMessage(`"{\"path\":"`)}}}}}}},
+		{Choices: []llm.Choice{{Delta: &llm.Message{ToolCalls: []llm.ToolCall{{Function: llm.FunctionCall{Arguments: json.RawMessage(`"\"a.go\"}"`)}}}}, FinishReason: "tool_calls"}}},
+		{Usage: &llm.Usage{TotalTokens: 9}},
+	}}
+	client := NewLoggingClient(inner, &buf, LoggingOptions{IncludeContent: true, IncludeToolArguments: true})
+
+	events, err := client.ChatStream(context.Background(), secretRequest())
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	count := 0
+	for range events {
+		count++
+	}
+	if count != 4 {
+		t.Fatalf("expected every event to be forwarded, got %d", count)
+	}
+
+	lines := logLines(t, &buf)
+	if len(lines) != 2 {
+		t.Fatalf("expected a request and a response line, got %d", len(lines))
+	}
+	response := lines[1]
+	if response["stream"] != true || response["model"] != "gpt-4o" || response["finish_reason"] != "tool_calls" || response["content"] != "Hello " {
+		t.Fatalf("unexpected stream response record %v", response)
+	}
+	call := response["tool_calls"].([]interface{})[0].(map[string]interface{})
+	if call["name"] != "read" || call["arguments"] != `{"path":"a.go"}` {
+		t.Fatalf("expected streamed tool arguments to be joined, got %v", call)
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingFile_RollsOverAtMaxSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "requests.jsonl")
+	file, err := NewRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatalf("NewRotatingFile: %v", err)
+	}
+	defer file.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := file.Write([]byte(line)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	want := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	}
+	for name, content := range want {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		if string(data) != content {
+			t.Fatalf("%s: expected %q, got %q", filepath.Base(name), content, data)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected at most 2 backups, stat .3: %v", err)
+	}
+}
+
+func TestRotatingFile_AppendsToExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "requests.jsonl")
+	if err := os.WriteFile(path, []byte("old\n"), 0644); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	file, err := NewRotatingFile(path, 1024, 1)
+	if err != nil {
+		t.Fatalf("NewRotatingFile: %v", err)
+	}
+	file.Write([]byte("new\n"))
+	file.Close()
+
+	data, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(data), "old\n") || !strings.HasSuffix(string(data), "new\n") {
+		t.Fatalf("expected appended log, got %q", data)
+	}
+}
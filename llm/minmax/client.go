@@ -1,7 +1,6 @@
 package minmax
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -205,28 +204,26 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest) (<-ch
 		}()
 		defer close(done)
 
-		scanner := bufio.NewScanner(resp.Body)
-		for scanner.Scan() {
-			line := scanner.Text()
-			if line == "" {
+		reader := llm.NewSSEReader(resp.Body)
+		for {
+			data, err := reader.Next()
+			if err != nil {
+				return
+			}
+			if data == "[DONE]" {
+				return
+			}
+
+			var event llm.StreamEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				llm.DebugSkippedEvent("MiniMax", data, err)
 				continue
 			}
-			if strings.HasPrefix(line, "data: ") {
-				data := strings.TrimPrefix(line, "data: ")
-				if data == "[DONE]" {
-					return
-				}
-
-				var event llm.StreamEvent
-				if err := json.Unmarshal([]byte(data), &event); err != nil {
-					continue
-				}
-
-				select {
-				case events <- event:
-				case <-ctx.Done():
-					return
-				}
+
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
 			}
 		}
 	}()
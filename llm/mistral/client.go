@@ -0,0 +1,382 @@
+package mistral
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+const (
+	defaultBaseURL = "https://api.mistral.ai/v1"
+	defaultTimeout = 60 * time.Second
+	defaultModel   = "mistral-large-latest"
+)
+
+// modelAliases maps bare Mistral model family names to their rolling
+// "-latest" IDs; the API only accepts the versioned or "-latest" forms.
+var modelAliases = map[string]string{
+	"mistral-large":  "mistral-large-latest",
+	"mistral-medium": "mistral-medium-latest",
+	"mistral-small":  "mistral-small-latest",
+	"codestral":      "codestral-latest",
+	"pixtral-large":  "pixtral-large-latest",
+}
+
+// Client implements the LLM client interface for Mistral AI
+type Client struct {
+	options    llm.ClientOptions
+	httpClient *http.Client
+}
+
+// NewClient creates a new Mistral client
+func NewClient(opts ...llm.ClientOption) (*Client, error) {
+	options := llm.ClientOptions{
+		BaseURL:      defaultBaseURL,
+		Timeout:      defaultTimeout,
+		MaxRetries:   3,
+		DefaultModel: defaultModel,
+		Headers:      make(map[string]string),
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	// Get API key from environment if not provided
+	if options.APIKey == "" {
+		options.APIKey = os.Getenv("MISTRAL_API_KEY")
+		if options.APIKey == "" {
+			return nil, fmt.Errorf("Mistral API key not provided")
+		}
+	}
+
+	// Create HTTP client
+	httpClient := &http.Client{
+		Timeout: options.Timeout,
+	}
+
+	return &Client{
+		options:    options,
+		httpClient: httpClient,
+	}, nil
+}
+
+// Chat sends a chat request to Mistral
+func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatResponse, error) {
+	c.prepareRequest(request)
+
+	// Create request body
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Execute request with retries
+	var response llm.ChatResponse
+	err = c.doWithRetries(ctx, func() error {
+		req, err := c.newChatRequest(ctx, body)
+		if err != nil {
+			return err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to execute request: %w", err)
+		}
+		defer resp.Body.Close()
+
+		// Read response body
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+
+		// Check for errors
+		if resp.StatusCode != http.StatusOK {
+			return apiError(resp.StatusCode, respBody)
+		}
+
+		// Parse response
+		if err := json.Unmarshal(respBody, &response); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// prepareRequest resolves the model and drops fields Mistral does not
+// accept. Its API rejects unknown request fields with a 422, so OpenAI-only
+// options must not be sent.
+func (c *Client) prepareRequest(request *llm.ChatRequest) {
+	// Set default model if not specified
+	if request.Model == "" {
+		request.Model = c.options.DefaultModel
+	}
+	if model, ok := modelAliases[strings.ToLower(strings.TrimSpace(request.Model))]; ok {
+		request.Model = model
+	}
+
+	request.ExtraBody = nil
+	// Mistral always sends usage on the final stream chunk
+	request.StreamOptions = nil
+}
+
+// newChatRequest builds a chat completions request. It is called once per
+// attempt so retries always send the full body.
+func (c *Client) newChatRequest(ctx context.Context, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.options.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers
+	c.setHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// apiError formats an error response. Mistral returns either an OpenAI-style
+// {"error": {...}} object or a flat {"message": ...} body depending on the
+// failure, so both are checked.
+func apiError(status int, body []byte) error {
+	var errResp struct {
+		Message interface{} `json:"message"`
+		Error   struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errResp); err == nil {
+		if errResp.Error.Message != "" {
+			return fmt.Errorf("Mistral API error: status %d: %s", status, errResp.Error.Message)
+		}
+		if msg, ok := errResp.Message.(string); ok && msg != "" {
+			return fmt.Errorf("Mistral API error: status %d: %s", status, msg)
+		}
+	}
+	return fmt.Errorf("Mistral API error: status %d, body: %s", status, string(body))
+}
+
+// ChatStream sends a streaming chat request to Mistral
+func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	c.prepareRequest(request)
+
+	// Enable streaming
+	request.Stream = true
+
+	// Create request body
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Open the stream, retrying rate limits before any event is read
+	var resp *http.Response
+	err = c.doWithRetries(ctx, func() error {
+		req, err := c.newChatRequest(ctx, body)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "text/event-stream")
+
+		r, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to execute request: %w", err)
+		}
+		if r.StatusCode != http.StatusOK {
+			defer r.Body.Close()
+			body, _ := io.ReadAll(r.Body)
+			return apiError(r.StatusCode, body)
+		}
+		resp = r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Create event channel
+	events := make(chan llm.StreamEvent)
+
+	// Start goroutine to read stream
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		reader := llm.NewSSEReader(resp.Body)
+		for {
+			data, err := reader.Next()
+			if err != nil {
+				return
+			}
+
+			// Check for end of stream
+			if data == "[DONE]" {
+				return
+			}
+
+			// Parse event
+			var event llm.StreamEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				llm.DebugSkippedEvent("Mistral", data, err)
+				continue
+			}
+
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// mistralModel is a model card from Mistral's /models endpoint
+type mistralModel struct {
+	ID               string `json:"id"`
+	Object           string `json:"object"`
+	Created          int64  `json:"created"`
+	OwnedBy          string `json:"owned_by"`
+	Description      string `json:"description"`
+	MaxContextLength int    `json:"max_context_length"`
+	Capabilities     struct {
+		Vision bool `json:"vision"`
+	} `json:"capabilities"`
+}
+
+func (m mistralModel) toModel() llm.Model {
+	return llm.Model{
+		ID:             m.ID,
+		Object:         m.Object,
+		Created:        m.Created,
+		OwnedBy:        m.OwnedBy,
+		ContextWindow:  m.MaxContextLength,
+		Description:    m.Description,
+		SupportsVision: m.Capabilities.Vision,
+	}
+}
+
+// ListModels returns available Mistral models
+func (c *Client) ListModels(ctx context.Context) ([]llm.Model, error) {
+	var response struct {
+		Data []mistralModel `json:"data"`
+	}
+	if err := c.getJSON(ctx, "/models", &response); err != nil {
+		return nil, err
+	}
+
+	models := make([]llm.Model, 0, len(response.Data))
+	for _, m := range response.Data {
+		models = append(models, m.toModel())
+	}
+	return models, nil
+}
+
+// GetModel returns details about a specific model
+func (c *Client) GetModel(ctx context.Context, modelID string) (*llm.Model, error) {
+	if alias, ok := modelAliases[strings.ToLower(strings.TrimSpace(modelID))]; ok {
+		modelID = alias
+	}
+
+	var m mistralModel
+	if err := c.getJSON(ctx, "/models/"+url.PathEscape(modelID), &m); err != nil {
+		return nil, err
+	}
+	model := m.toModel()
+	return &model, nil
+}
+
+// getJSON issues a GET against the API and decodes the JSON response into out
+func (c *Client) getJSON(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.options.BaseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.setHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return apiError(resp.StatusCode, body)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// Close cleans up resources
+func (c *Client) Close() error {
+	return nil
+}
+
+// setHeaders sets common headers for requests
+func (c *Client) setHeaders(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+c.options.APIKey)
+	req.Header.Set("User-Agent", "simple-agent-go/1.0")
+
+	// Add custom headers
+	for k, v := range c.options.Headers {
+		req.Header.Set(k, v)
+	}
+}
+
+// doWithRetries executes a function with retries
+func (c *Client) doWithRetries(ctx context.Context, fn func() error) error {
+	var lastErr error
+
+	for i := 0; i <= c.options.MaxRetries; i++ {
+		if i > 0 {
+			if !llm.AllowRetry(ctx) {
+				return fmt.Errorf("retry budget exhausted: %w", lastErr)
+			}
+			// Exponential backoff
+			delay := time.Duration(i) * time.Second
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if err := fn(); err != nil {
+			lastErr = err
+			// Check if error is retryable
+			if strings.Contains(err.Error(), "status 429") || // Rate limit
+				strings.Contains(err.Error(), "status 500") || // Server error
+				strings.Contains(err.Error(), "status 502") || // Bad gateway
+				strings.Contains(err.Error(), "status 503") { // Service unavailable
+				continue
+			}
+			return err
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("max retries exceeded: %w", lastErr)
+}
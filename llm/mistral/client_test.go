@@ -0,0 +1,141 @@
+package mistral
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+func newTestClient(t *testing.T, baseURL string) *Client {
+	t.Helper()
+	client, err := NewClient(llm.WithAPIKey("test-key"), llm.WithBaseURL(baseURL))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return data
+}
+
+func TestChatStreamReplaysRecordedFixture(t *testing.T) {
+	fixture := readFixture(t, "chat_stream.txt")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("expected bearer auth, got %q", got)
+		}
+		body, _ := io.ReadAll(r.Body)
+		var raw map[string]interface{}
+		if err := json.Unmarshal(body, &raw); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if raw["model"] != defaultModel || raw["stream"] != true {
+			t.Errorf("expected a streamed request for the default model, got %s", body)
+		}
+		for _, field := range []string{"extra_body", "stream_options"} {
+			if _, ok := raw[field]; ok {
+				t.Errorf("Mistral rejects unknown fields; %s must not be sent", field)
+			}
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write(fixture)
+	}))
+	defer srv.Close()
+	client := newTestClient(t, srv.URL)
+
+	events, err := client.ChatStream(context.Background(), &llm.ChatRequest{
+		Messages:      []llm.Message{{Role: llm.RoleUser, Content: llm.StringPtr("Say hello")}},
+		ExtraBody:     map[string]interface{}{"thinking": map[string]interface{}{"type": "disabled"}},
+		StreamOptions: &llm.StreamOptions{IncludeUsage: true},
+	})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+
+	var content strings.Builder
+	var usage *llm.Usage
+	for event := range events {
+		for _, choice := range event.Choices {
+			if choice.Delta != nil && choice.Delta.Content != nil {
+				content.WriteString(*choice.Delta.Content)
+			}
+		}
+		if event.Usage != nil {
+			usage = event.Usage
+		}
+	}
+
+	if got := content.String(); got != "Bonjour from Paris." {
+		t.Fatalf("unexpected streamed content %q", got)
+	}
+	if usage == nil || usage.TotalTokens != 14 {
+		t.Fatalf("expected usage from the final chunk, got %+v", usage)
+	}
+}
+
+func TestChatResolvesModelAliasAndReportsErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llm.ChatRequest
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if req.Model != "codestral-latest" {
+			t.Errorf("expected codestral alias to resolve to codestral-latest, got %q", req.Model)
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message":"Unauthorized","request_id":"abc"}`))
+	}))
+	defer srv.Close()
+	client := newTestClient(t, srv.URL)
+
+	_, err := client.Chat(context.Background(), &llm.ChatRequest{
+		Model:    "codestral",
+		Messages: []llm.Message{{Role: llm.RoleUser, Content: llm.StringPtr("hello")}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "status 401: Unauthorized") {
+		t.Fatalf("expected a 401 error with Mistral's message, got %v", err)
+	}
+}
+
+func TestListModelsMapsModelCards(t *testing.T) {
+	fixture := readFixture(t, "models.json")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(fixture)
+	}))
+	defer srv.Close()
+	client := newTestClient(t, srv.URL)
+
+	models, err := client.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels: %v", err)
+	}
+	if len(models) != 2 {
+		t.Fatalf("expected 2 models, got %d", len(models))
+	}
+	if models[0].ID != "mistral-large-latest" || models[0].ContextWindow != 131072 || models[0].SupportsVision {
+		t.Fatalf("unexpected first model %+v", models[0])
+	}
+	if !models[1].SupportsVision {
+		t.Fatalf("expected pixtral to report vision support, got %+v", models[1])
+	}
+}
@@ -17,9 +17,14 @@ import (
 const (
 	defaultBaseURL = "https://api.moonshot.ai/v1"
 	defaultTimeout = 60 * time.Second
-	defaultModel   = "moonshot-v1-8k"
+	defaultModel   = "moonshot-v1-128k"
 )
 
+// modelAliases maps shorthand model names to Moonshot model IDs
+var modelAliases = map[string]string{
+	"kimi": "kimi-latest",
+}
+
 // Client implements the LLM client interface for Moonshot/Kimi
 type Client struct {
 	options    llm.ClientOptions
@@ -62,10 +67,72 @@ func NewClient(opts ...llm.ClientOption) (*Client, error) {
 
 // Chat sends a chat request to Moonshot
 func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatResponse, error) {
+	c.prepareRequest(request)
+
+	// Create request body
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Execute request with retries; Moonshot rate limits aggressively
+	var response llm.ChatResponse
+	err = c.doWithRetries(ctx, func() error {
+		req, err := c.newChatRequest(ctx, body)
+		if err != nil {
+			return err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to execute request: %w", err)
+		}
+		defer resp.Body.Close()
+
+		// Read response body
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+
+		// Check for errors
+		if resp.StatusCode != http.StatusOK {
+			var errResp struct {
+				Error struct {
+					Message string `json:"message"`
+					Type    string `json:"type"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(respBody, &errResp); err == nil && errResp.Error.Message != "" {
+				return fmt.Errorf("Moonshot API error: status %d: %s", resp.StatusCode, errResp.Error.Message)
+			}
+			return fmt.Errorf("Moonshot API error: status %d, body: %s", resp.StatusCode, string(respBody))
+		}
+
+		// Parse response
+		if err := json.Unmarshal(respBody, &response); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// prepareRequest resolves the model and applies Moonshot's model-specific
+// defaults. Chat and ChatStream send the same request body.
+func (c *Client) prepareRequest(request *llm.ChatRequest) {
 	// Set default model if not specified
 	if request.Model == "" {
 		request.Model = c.options.DefaultModel
 	}
+	if model, ok := modelAliases[strings.ToLower(strings.TrimSpace(request.Model))]; ok {
+		request.Model = model
+	}
 
 	// Model-specific defaults for Kimi K2.5
 	if isKimiK25Model(request.Model) {
@@ -101,14 +168,11 @@ func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatR
 			request.Temperature = 0.3
 		}
 	}
+}
 
-	// Create request body
-	body, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// Create HTTP request
+// newChatRequest builds a chat completions request. It is called once per
+// attempt so retries always send the full body.
+func (c *Client) newChatRequest(ctx context.Context, body []byte) (*http.Request, error) {
 	req, err := http.NewRequestWithContext(ctx, "POST", c.options.BaseURL+"/chat/completions", bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -117,41 +181,7 @@ func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatR
 	// Set headers
 	c.setHeaders(req)
 	req.Header.Set("Content-Type", "application/json")
-
-	// Execute request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Read response body
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	// Check for errors
-	if resp.StatusCode != http.StatusOK {
-		var errResp struct {
-			Error struct {
-				Message string `json:"message"`
-				Type    string `json:"type"`
-			} `json:"error"`
-		}
-		if err := json.Unmarshal(respBody, &errResp); err == nil && errResp.Error.Message != "" {
-			return nil, fmt.Errorf("Moonshot API error: %s", errResp.Error.Message)
-		}
-		return nil, fmt.Errorf("Moonshot API error: status %d, body: %s", resp.StatusCode, string(respBody))
-	}
-
-	// Parse response
-	var response llm.ChatResponse
-	if err := json.Unmarshal(respBody, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	return &response, nil
+	return req, nil
 }
 
 func isKimiK25Model(model string) bool {
@@ -178,9 +208,80 @@ func isThinkingDisabled(extra map[string]interface{}) bool {
 	return strings.EqualFold(typeVal, "disabled")
 }
 
-// ChatStream is not implemented for Moonshot yet
+// ChatStream sends a streaming chat request to Moonshot
 func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
-	return nil, fmt.Errorf("streaming not implemented for Moonshot client")
+	c.prepareRequest(request)
+
+	// Enable streaming
+	request.Stream = true
+
+	// Create request body
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Open the stream, retrying rate limits before any event is read
+	var resp *http.Response
+	err = c.doWithRetries(ctx, func() error {
+		req, err := c.newChatRequest(ctx, body)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "text/event-stream")
+
+		r, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to execute request: %w", err)
+		}
+		if r.StatusCode != http.StatusOK {
+			defer r.Body.Close()
+			body, _ := io.ReadAll(r.Body)
+			return fmt.Errorf("Moonshot API error: status %d, body: %s", r.StatusCode, string(body))
+		}
+		resp = r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Create event channel
+	events := make(chan llm.StreamEvent)
+
+	// Start goroutine to read stream
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		reader := llm.NewSSEReader(resp.Body)
+		for {
+			data, err := reader.Next()
+			if err != nil {
+				return
+			}
+
+			// Check for end of stream
+			if data == "[DONE]" {
+				return
+			}
+
+			// Parse event
+			var event llm.StreamEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				llm.DebugSkippedEvent("Moonshot", data, err)
+				continue
+			}
+
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
 }
 
 // ListModels returns available Moonshot models
@@ -279,3 +380,39 @@ func (c *Client) setHeaders(req *http.Request) {
 		req.Header.Set(k, v)
 	}
 }
+
+// doWithRetries executes a function with retries
+func (c *Client) doWithRetries(ctx context.Context, fn func() error) error {
+	var lastErr error
+
+	for i := 0; i <= c.options.MaxRetries; i++ {
+		if i > 0 {
+			if !llm.AllowRetry(ctx) {
+				return fmt.Errorf("retry budget exhausted: %w", lastErr)
+			}
+			// Exponential backoff
+			delay := time.Duration(i) * time.Second
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if err := fn(); err != nil {
+			lastErr = err
+			// Check if error is retryable
+			if strings.Contains(err.Error(), "status 429") || // Rate limit
+				strings.Contains(err.Error(), "status 500") || // Server error
+				strings.Contains(err.Error(), "status 502") || // Bad gateway
+				strings.Contains(err.Error(), "status 503") { // Service unavailable
+				continue
+			}
+			return err
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("max retries exceeded: %w", lastErr)
+}
@@ -0,0 +1,113 @@
+package moonshot
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+func newTestClient(t *testing.T, baseURL string, opts ...llm.ClientOption) *Client {
+	t.Helper()
+	opts = append([]llm.ClientOption{llm.WithAPIKey("test-key"), llm.WithBaseURL(baseURL)}, opts...)
+	client, err := NewClient(opts...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client
+}
+
+func TestChatStreamReplaysRecordedFixture(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "chat_stream.txt"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req llm.ChatRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if !req.Stream || req.Model != defaultModel || req.Temperature != 0.3 {
+			t.Errorf("expected a streamed request with Chat's defaults, got %+v", req)
+		}
+		if got := r.Header.Get("Accept"); got != "text/event-stream" {
+			t.Errorf("expected Accept text/event-stream, got %q", got)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write(fixture)
+	}))
+	defer srv.Close()
+	client := newTestClient(t, srv.URL)
+
+	events, err := client.ChatStream(context.Background(), &llm.ChatRequest{
+		Messages: []llm.Message{{Role: llm.RoleUser, Content: llm.StringPtr("What is 月之暗面?")}},
+	})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+
+	var content strings.Builder
+	var finish string
+	for event := range events {
+		for _, choice := range event.Choices {
+			if choice.Delta != nil && choice.Delta.Content != nil {
+				content.WriteString(*choice.Delta.Content)
+			}
+			if choice.FinishReason != "" {
+				finish = choice.FinishReason
+			}
+		}
+	}
+
+	if got := content.String(); got != "月之暗面 is Moonshot AI." {
+		t.Fatalf("unexpected streamed content %q", got)
+	}
+	if finish != "stop" {
+		t.Fatalf("expected finish_reason stop, got %q", finish)
+	}
+}
+
+func TestChatRetriesRateLimitAndResolvesKimiAlias(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req llm.ChatRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("attempt %d: decode request: %v", attempts.Load()+1, err)
+		}
+		if req.Model != "kimi-latest" {
+			t.Errorf("expected kimi alias to resolve to kimi-latest, got %q", req.Model)
+		}
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":{"message":"rate limited","type":"rate_limit_reached_error"}}`))
+			return
+		}
+		w.Write([]byte(`{"id":"chatcmpl-1","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer srv.Close()
+	client := newTestClient(t, srv.URL, llm.WithMaxRetries(1))
+
+	resp, err := client.Chat(context.Background(), &llm.ChatRequest{
+		Model:    "kimi",
+		Messages: []llm.Message{{Role: llm.RoleUser, Content: llm.StringPtr("hello")}},
+	})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if attempts.Load() != 2 {
+		t.Fatalf("expected one retry after the 429, got %d attempts", attempts.Load())
+	}
+	if got := llm.GetStringValue(resp.Choices[0].Message.Content); got != "hi" {
+		t.Fatalf("unexpected content %q", got)
+	}
+}
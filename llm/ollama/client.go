@@ -28,6 +28,10 @@ type Client struct {
 	httpClient *http.Client
 }
 
+// Ollama's /api/chat takes base64 images on messages, so paste-image and
+// /attach work with vision models such as llava and moondream.
+var _ llm.MultimodalClient = (*Client)(nil)
+
 // OllamaToolCall represents a tool call in Ollama's format
 type OllamaToolCall struct {
 	Function struct {
@@ -61,6 +65,7 @@ type OllamaResponse struct {
 	CreatedAt       time.Time     `json:"created_at"`
 	Message         OllamaMessage `json:"message"`
 	Done            bool          `json:"done"`
+	DoneReason      string        `json:"done_reason,omitempty"` // "stop", or "length" at num_predict
 	TotalDuration   int64         `json:"total_duration,omitempty"`
 	LoadDuration    int64         `json:"load_duration,omitempty"`
 	PromptEvalCount int           `json:"prompt_eval_count,omitempty"`
@@ -68,12 +73,25 @@ type OllamaResponse struct {
 	EvalDuration    int64         `json:"eval_duration,omitempty"`
 }
 
+// finishReason maps Ollama's done_reason to an OpenAI-style finish_reason
+func finishReason(doneReason string, hasToolCalls bool) string {
+	switch {
+	case hasToolCalls:
+		return "tool_calls"
+	case doneReason == "length":
+		return "length"
+	default:
+		return "stop"
+	}
+}
+
 // OllamaStreamResponse for streaming
 type OllamaStreamResponse struct {
-	Model     string        `json:"model"`
-	CreatedAt time.Time     `json:"created_at"`
-	Message   OllamaMessage `json:"message"`
-	Done      bool          `json:"done"`
+	Model      string        `json:"model"`
+	CreatedAt  time.Time     `json:"created_at"`
+	Message    OllamaMessage `json:"message"`
+	Done       bool          `json:"done"`
+	DoneReason string        `json:"done_reason,omitempty"`
 }
 
 // NewClient creates a new Ollama client
@@ -229,6 +247,7 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest) (<-ch
 		defer resp.Body.Close()
 
 		scanner := bufio.NewScanner(resp.Body)
+		sawToolCalls := false
 		for scanner.Scan() {
 			line := scanner.Text()
 
@@ -276,13 +295,11 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest) (<-ch
 				},
 			}
 
-			// Set finish reason if done
+			// Set finish reason if done; tool calls can arrive before the
+			// final chunk
+			sawToolCalls = sawToolCalls || len(delta.ToolCalls) > 0
 			if streamResp.Done {
-				if len(delta.ToolCalls) > 0 {
-					event.Choices[0].FinishReason = "tool_calls"
-				} else {
-					event.Choices[0].FinishReason = "stop"
-				}
+				event.Choices[0].FinishReason = finishReason(streamResp.DoneReason, sawToolCalls)
 			}
 
 			select {
@@ -337,23 +354,90 @@ func (c *Client) ListModels(ctx context.Context) ([]llm.Model, error) {
 	models := make([]llm.Model, len(response.Models))
 	for i, model := range response.Models {
 		supportsVision := isOllamaVisionModel(model.Name)
+		models[i] = llm.Model{
+			ID:      model.Name,
+			Object:  "model",
+			Created: model.ModifiedAt.Unix(),
+			OwnedBy: "ollama",
+		}
+		// /api/tags omits the context length and capabilities; /api/show
+		// reports them per model. A model that cannot be shown is still
+		// listed, just without them.
+		if show, err := c.show(ctx, model.Name); err == nil {
+			models[i].ContextWindow = show.ContextLength()
+			supportsVision = supportsVision || show.SupportsVision()
+		}
 		desc := fmt.Sprintf("Local model (%s)", formatBytes(model.Size))
 		if supportsVision {
 			desc = desc + " · Vision"
 		}
-		models[i] = llm.Model{
-			ID:             model.Name,
-			Object:         "model",
-			Created:        model.ModifiedAt.Unix(),
-			OwnedBy:        "ollama",
-			Description:    desc,
-			SupportsVision: supportsVision,
-		}
+		models[i].Description = desc
+		models[i].SupportsVision = supportsVision
 	}
 
 	return models, nil
 }
 
+// OllamaShowResponse is the subset of /api/show used for model metadata
+type OllamaShowResponse struct {
+	ModelInfo    map[string]interface{} `json:"model_info"`
+	Capabilities []string               `json:"capabilities"` // e.g. "completion", "tools", "vision"; reported by Ollama 0.6+
+}
+
+// SupportsVision reports whether the model accepts images
+func (r *OllamaShowResponse) SupportsVision() bool {
+	for _, capability := range r.Capabilities {
+		if capability == "vision" {
+			return true
+		}
+	}
+	return false
+}
+
+// ContextLength returns the trained context length from model_info, which
+// Ollama keys by architecture (e.g. "llama.context_length").
+func (r *OllamaShowResponse) ContextLength() int {
+	arch, _ := r.ModelInfo["general.architecture"].(string)
+	if arch == "" {
+		return 0
+	}
+	// JSON numbers decode as float64
+	length, _ := r.ModelInfo[arch+".context_length"].(float64)
+	return int(length)
+}
+
+// show asks /api/show for a model's metadata
+func (c *Client) show(ctx context.Context, name string) (*OllamaShowResponse, error) {
+	body, err := json.Marshal(map[string]string{"model": name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.options.BaseURL+"/api/show", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.setHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("Ollama error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var show OllamaShowResponse
+	if err := json.NewDecoder(resp.Body).Decode(&show); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &show, nil
+}
+
 // GetModel returns details about a specific model
 func (c *Client) GetModel(ctx context.Context, modelID string) (*llm.Model, error) {
 	models, err := c.ListModels(ctx)
@@ -471,12 +555,6 @@ func (c *Client) convertResponse(resp *OllamaResponse, model string) *llm.ChatRe
 		}
 	}
 
-	// Determine finish reason
-	finishReason := "stop"
-	if len(message.ToolCalls) > 0 {
-		finishReason = "tool_calls"
-	}
-
 	return &llm.ChatResponse{
 		ID:      fmt.Sprintf("ollama-%d", time.Now().UnixNano()),
 		Object:  "chat.completion",
@@ -486,7 +564,7 @@ func (c *Client) convertResponse(resp *OllamaResponse, model string) *llm.ChatRe
 			{
 				Index:        0,
 				Message:      message,
-				FinishReason: finishReason,
+				FinishReason: finishReason(resp.DoneReason, len(message.ToolCalls) > 0),
 			},
 		},
 		Usage: &llm.Usage{
@@ -524,6 +602,9 @@ func isOllamaVisionModel(name string) bool {
 	case strings.Contains(n, "llava"),
 		strings.Contains(n, "bakllava"),
 		strings.Contains(n, "moondream"),
+		strings.Contains(n, "minicpm-v"),
+		strings.Contains(n, "gemma3"),
+		strings.Contains(n, "qwen2.5vl"),
 		strings.Contains(n, ":vision"),
 		strings.Contains(n, "-vision"):
 		return true
@@ -534,9 +615,17 @@ func isOllamaVisionModel(name string) bool {
 
 // --- Multimodal helpers ---
 
-// encodeImageBase64 reads a local image file and returns base64-encoded contents
+// encodeImageBase64 reads a local image file or pasted data URL and returns
+// base64-encoded contents with any embedded metadata (EXIF, GPS) stripped.
 func (c *Client) encodeImageBase64(path string) (string, error) {
-	data, err := os.ReadFile(path)
+	if strings.HasPrefix(strings.ToLower(path), "data:image/") {
+		dataURL, err := llm.SanitizeImageDataURL(path)
+		if err != nil {
+			return "", err
+		}
+		return dataURL[strings.Index(dataURL, ",")+1:], nil
+	}
+	data, _, err := llm.LoadImageAttachment(path)
 	if err != nil {
 		return "", err
 	}
@@ -549,13 +638,6 @@ func (c *Client) ChatWithImages(prompt string, imagePaths []string, opts map[str
 	// Encode images
 	var imgs []string
 	for _, p := range imagePaths {
-		if strings.HasPrefix(strings.ToLower(p), "data:image/") {
-			// Extract base64 after comma
-			if idx := strings.Index(p, ","); idx != -1 && idx+1 < len(p) {
-				imgs = append(imgs, p[idx+1:])
-				continue
-			}
-		}
 		enc, err := c.encodeImageBase64(p)
 		if err != nil {
 			return "", fmt.Errorf("encode image %s: %w", p, err)
@@ -613,12 +695,6 @@ func (c *Client) StreamChatWithImages(prompt string, imagePaths []string, opts m
 	// Encode images
 	var imgs []string
 	for _, p := range imagePaths {
-		if strings.HasPrefix(strings.ToLower(p), "data:image/") {
-			if idx := strings.Index(p, ","); idx != -1 && idx+1 < len(p) {
-				imgs = append(imgs, p[idx+1:])
-				continue
-			}
-		}
 		enc, err := c.encodeImageBase64(p)
 		if err != nil {
 			return nil, fmt.Errorf("encode image %s: %w", p, err)
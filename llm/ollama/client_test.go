@@ -0,0 +1,211 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+func TestListModelsReadsContextLengthFromShow(t *testing.T) {
+	show, err := os.ReadFile(filepath.Join("testdata", "show.json"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			w.Write([]byte(`{"models":[{"name":"llama3.2:latest","modified_at":"2025-01-02T15:04:05Z","size":2019393189},{"name":"broken:latest","size":1}]}`))
+		case "/api/show":
+			var req struct {
+				Model string `json:"model"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("decode show request: %v", err)
+			}
+			if req.Model != "llama3.2:latest" {
+				http.Error(w, `{"error":"model not found"}`, http.StatusNotFound)
+				return
+			}
+			w.Write(show)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(llm.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	models, err := client.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels: %v", err)
+	}
+	if len(models) != 2 {
+		t.Fatalf("expected 2 models, got %d", len(models))
+	}
+	if models[0].ContextWindow != 131072 {
+		t.Fatalf("expected context window 131072 from /api/show, got %d", models[0].ContextWindow)
+	}
+	if models[1].ContextWindow != 0 {
+		t.Fatalf("expected no context window when /api/show fails, got %d", models[1].ContextWindow)
+	}
+}
+
+func TestListModelsReadsVisionCapabilityFromShow(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			w.Write([]byte(`{"models":[{"name":"qwen2.5vl:7b","size":1},{"name":"llama3.2:latest","size":1}]}`))
+		case "/api/show":
+			var req struct {
+				Model string `json:"model"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			caps := `["completion","tools"]`
+			if req.Model == "qwen2.5vl:7b" {
+				caps = `["completion","vision"]`
+			}
+			w.Write([]byte(`{"model_info":{},"capabilities":` + caps + `}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(llm.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	models, err := client.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels: %v", err)
+	}
+	if !models[0].SupportsVision {
+		t.Fatalf("expected %s to support vision from its capabilities", models[0].ID)
+	}
+	if models[1].SupportsVision {
+		t.Fatalf("expected %s not to support vision", models[1].ID)
+	}
+}
+
+func TestChatWithImagesSendsBase64Images(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatalf("encode fixture: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "cat.png")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	var got OllamaRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			w.Write([]byte(`{"models":[]}`))
+			return
+		case "/api/chat":
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode chat request: %v", err)
+		}
+		if got.Stream {
+			w.Write([]byte(`{"message":{"role":"assistant","content":"A "},"done":false}` + "\n"))
+			w.Write([]byte(`{"message":{"role":"assistant","content":"cat."},"done":true}` + "\n"))
+			return
+		}
+		w.Write([]byte(`{"message":{"role":"assistant","content":"A cat."},"done":true}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(llm.WithBaseURL(srv.URL), llm.WithModel("llava"))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	checkRequest := func(stream bool) {
+		t.Helper()
+		if got.Model != "llava" || got.Stream != stream || len(got.Messages) != 1 {
+			t.Fatalf("unexpected request: %+v", got)
+		}
+		images := got.Messages[0].Images
+		if len(images) != 1 {
+			t.Fatalf("expected one image on the message, got %d", len(images))
+		}
+		if _, err := base64.StdEncoding.DecodeString(images[0]); err != nil {
+			t.Fatalf("expected a base64 image, got %q: %v", images[0], err)
+		}
+	}
+
+	out, err := client.ChatWithImages("What is this?", []string{path}, nil)
+	if err != nil {
+		t.Fatalf("ChatWithImages: %v", err)
+	}
+	if out != "A cat." {
+		t.Fatalf("expected the reply, got %q", out)
+	}
+	checkRequest(false)
+
+	chunks, err := client.StreamChatWithImages("What is this?", []string{path}, nil)
+	if err != nil {
+		t.Fatalf("StreamChatWithImages: %v", err)
+	}
+	var streamed string
+	for chunk := range chunks {
+		streamed += chunk
+	}
+	if streamed != "A cat." {
+		t.Fatalf("expected the streamed reply, got %q", streamed)
+	}
+	checkRequest(true)
+}
+
+func TestChatStreamMapsDoneReason(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			w.Write([]byte(`{"models":[]}`))
+		case "/api/chat":
+			w.Write([]byte(`{"message":{"role":"assistant","content":"The first"},"done":false}` + "\n"))
+			w.Write([]byte(`{"message":{"role":"assistant","content":" half"},"done":true,"done_reason":"length"}` + "\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(llm.WithBaseURL(srv.URL), llm.WithModel("llama3.2"))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	stream, err := client.ChatStream(context.Background(), &llm.ChatRequest{
+		Messages: []llm.Message{{Role: llm.RoleUser, Content: llm.StringPtr("Write an essay")}},
+	})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	var finish string
+	for event := range stream {
+		if reason := event.Choices[0].FinishReason; reason != "" {
+			finish = reason
+		}
+	}
+	if finish != "length" {
+		t.Fatalf("expected done_reason length as the finish reason, got %q", finish)
+	}
+}
@@ -1,7 +1,6 @@
 package openai
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -27,6 +26,10 @@ type Client struct {
 	httpClient *http.Client
 }
 
+// GPT-4o, GPT-4 Turbo and the vision previews take images as image_url
+// content parts.
+var _ llm.MultimodalClient = (*Client)(nil)
+
 // NewClient creates a new OpenAI client
 func NewClient(opts ...llm.ClientOption) (*Client, error) {
 	options := llm.ClientOptions{
@@ -132,8 +135,9 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest) (<-ch
 		request.Model = c.options.DefaultModel
 	}
 
-	// Enable streaming
+	// Enable streaming and ask for a trailing usage chunk
 	request.Stream = true
+	request.StreamOptions = &llm.StreamOptions{IncludeUsage: true}
 
 	// Create the request for OpenAI API
 	openAIReq := c.buildOpenAIRequest(request)
@@ -176,35 +180,29 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest) (<-ch
 		defer close(events)
 		defer resp.Body.Close()
 
-		scanner := bufio.NewScanner(resp.Body)
-		for scanner.Scan() {
-			line := scanner.Text()
-
-			// Skip empty lines
-			if line == "" {
-				continue
+		reader := llm.NewSSEReader(resp.Body)
+		for {
+			data, err := reader.Next()
+			if err != nil {
+				return
 			}
 
-			// Parse SSE event
-			if strings.HasPrefix(line, "data: ") {
-				data := strings.TrimPrefix(line, "data: ")
-
-				// Check for end of stream
-				if data == "[DONE]" {
-					return
-				}
+			// Check for end of stream
+			if data == "[DONE]" {
+				return
+			}
 
-				// Parse event
-				var event llm.StreamEvent
-				if err := json.Unmarshal([]byte(data), &event); err != nil {
-					continue // Skip invalid events
-				}
+			// Parse event
+			var event llm.StreamEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				llm.DebugSkippedEvent("OpenAI", data, err)
+				continue
+			}
 
-				select {
-				case events <- event:
-				case <-ctx.Done():
-					return
-				}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
 			}
 		}
 	}()
@@ -298,6 +296,9 @@ func (c *Client) doWithRetries(ctx context.Context, fn func() error) error {
 
 	for i := 0; i <= c.options.MaxRetries; i++ {
 		if i > 0 {
+			if !llm.AllowRetry(ctx) {
+				return fmt.Errorf("retry budget exhausted: %w", lastErr)
+			}
 			// Exponential backoff
 			delay := time.Duration(i) * time.Second
 			select {
@@ -359,6 +360,9 @@ func (c *Client) buildOpenAIRequest(request *llm.ChatRequest) map[string]interfa
 	}
 	if request.Stream {
 		reqMap["stream"] = request.Stream
+		if request.StreamOptions != nil {
+			reqMap["stream_options"] = request.StreamOptions
+		}
 	}
 	if len(request.Tools) > 0 {
 		reqMap["tools"] = request.Tools
@@ -392,3 +396,140 @@ func (c *Client) buildOpenAIRequest(request *llm.ChatRequest) map[string]interfa
 
 	return reqMap
 }
+
+type contentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *imageURL `json:"image_url,omitempty"`
+}
+
+type imageURL struct {
+	URL string `json:"url"`
+}
+
+type imageMessage struct {
+	Role    string        `json:"role"`
+	Content []contentPart `json:"content"`
+}
+
+// encodeImageToDataURL converts an image path or pasted data URL into a data
+// URL with any embedded metadata (EXIF, GPS) stripped, downscaling it first
+// when the client was created with llm.WithImageMaxDimension.
+func (c *Client) encodeImageToDataURL(image string) (string, error) {
+	return llm.PrepareImageDataURL(image, c.options.ImageMaxDimension)
+}
+
+// buildImageRequest creates a single-turn request whose user message uses
+// OpenAI's content-array format: the prompt as a text part followed by one
+// image_url part per image, each sent inline as a base64 data URL.
+func (c *Client) buildImageRequest(prompt string, imagePaths []string, opts map[string]interface{}, stream bool) (map[string]interface{}, error) {
+	parts := []contentPart{{Type: "text", Text: prompt}}
+	for _, p := range imagePaths {
+		url, err := c.encodeImageToDataURL(p)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, contentPart{Type: "image_url", ImageURL: &imageURL{URL: url}})
+	}
+
+	request := &llm.ChatRequest{Model: c.options.DefaultModel, Stream: stream}
+	// Lightweight handling of common opts
+	if v, ok := opts["max_tokens"].(int); ok {
+		request.MaxTokens = v
+	}
+	if v, ok := opts["temperature"].(float64); ok {
+		request.Temperature = float32(v)
+	}
+
+	reqMap := c.buildOpenAIRequest(request)
+	reqMap["messages"] = []imageMessage{{Role: "user", Content: parts}}
+	return reqMap, nil
+}
+
+// postImageRequest sends an image request and returns the response once the
+// API has accepted it.
+func (c *Client) postImageRequest(reqMap map[string]interface{}, stream bool) (*http.Response, error) {
+	body, err := json.Marshal(reqMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", c.options.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.setHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+	if stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("OpenAI API error: status %d, body: %s", resp.StatusCode, string(b))
+	}
+	return resp, nil
+}
+
+// ChatWithImages sends a prompt + images to a vision-capable OpenAI model
+func (c *Client) ChatWithImages(prompt string, imagePaths []string, opts map[string]interface{}) (string, error) {
+	reqMap, err := c.buildImageRequest(prompt, imagePaths, opts, false)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.postImageRequest(reqMap, false)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var out llm.ChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(out.Choices) > 0 {
+		return llm.GetStringValue(out.Choices[0].Message.Content), nil
+	}
+	return "", nil
+}
+
+// StreamChatWithImages streams chunks for prompt + images
+func (c *Client) StreamChatWithImages(prompt string, imagePaths []string, opts map[string]interface{}) (<-chan string, error) {
+	reqMap, err := c.buildImageRequest(prompt, imagePaths, opts, true)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.postImageRequest(reqMap, true)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		defer resp.Body.Close()
+		reader := llm.NewSSEReader(resp.Body)
+		for {
+			data, err := reader.Next()
+			if err != nil || data == "[DONE]" {
+				return
+			}
+			var event llm.StreamEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				llm.DebugSkippedEvent("OpenAI", data, err)
+				continue
+			}
+			if len(event.Choices) > 0 && event.Choices[0].Delta != nil {
+				if content := llm.GetStringValue(event.Choices[0].Delta.Content); content != "" {
+					ch <- content
+				}
+			}
+		}
+	}()
+	return ch, nil
+}
@@ -0,0 +1,160 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+func newTestClient(t *testing.T, baseURL string) *Client {
+	t.Helper()
+	client, err := NewClient(llm.WithAPIKey("test-key"), llm.WithBaseURL(baseURL), llm.WithModel("gpt-4o"))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client
+}
+
+func writeTestPNG(t *testing.T) string {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	img.Set(1, 1, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "chart.png")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("write png: %v", err)
+	}
+	return path
+}
+
+type imageRequestBody struct {
+	Model     string         `json:"model"`
+	Stream    bool           `json:"stream"`
+	MaxTokens int            `json:"max_tokens"`
+	Messages  []imageMessage `json:"messages"`
+}
+
+func TestChatWithImagesSendsContentArray(t *testing.T) {
+	var got imageRequestBody
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test-key" {
+			t.Errorf("unexpected Authorization header %q", auth)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Write([]byte(`{"id":"chatcmpl-1","choices":[{"index":0,"message":{"role":"assistant","content":"A red dot."},"finish_reason":"stop"}]}`))
+	}))
+	defer srv.Close()
+	client := newTestClient(t, srv.URL)
+
+	out, err := client.ChatWithImages("What is in this image?", []string{writeTestPNG(t)}, map[string]interface{}{"max_tokens": 100})
+	if err != nil {
+		t.Fatalf("ChatWithImages: %v", err)
+	}
+	if out != "A red dot." {
+		t.Fatalf("unexpected reply %q", out)
+	}
+
+	if got.Model != "gpt-4o" || got.Stream || got.MaxTokens != 100 {
+		t.Fatalf("unexpected request fields: %+v", got)
+	}
+	if len(got.Messages) != 1 || got.Messages[0].Role != "user" {
+		t.Fatalf("expected a single user message, got %+v", got.Messages)
+	}
+	parts := got.Messages[0].Content
+	if len(parts) != 2 {
+		t.Fatalf("expected text and image parts, got %+v", parts)
+	}
+	if parts[0].Type != "text" || parts[0].Text != "What is in this image?" || parts[0].ImageURL != nil {
+		t.Fatalf("unexpected text part %+v", parts[0])
+	}
+	if parts[1].Type != "image_url" || parts[1].ImageURL == nil {
+		t.Fatalf("unexpected image part %+v", parts[1])
+	}
+
+	url := parts[1].ImageURL.URL
+	payload, ok := strings.CutPrefix(url, "data:image/png;base64,")
+	if !ok {
+		t.Fatalf("expected a PNG data URL, got %q", url)
+	}
+	raw, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		t.Fatalf("decode data URL: %v", err)
+	}
+	if _, format, err := image.Decode(bytes.NewReader(raw)); err != nil || format != "png" {
+		t.Fatalf("expected a decodable PNG, got format %q err %v", format, err)
+	}
+}
+
+func TestEncodeImageToDataURLAcceptsPastedDataURL(t *testing.T) {
+	data, err := os.ReadFile(writeTestPNG(t))
+	if err != nil {
+		t.Fatalf("read png: %v", err)
+	}
+	pasted := "data:image/png;base64," + base64.StdEncoding.EncodeToString(data)
+	client := &Client{}
+
+	url, err := client.encodeImageToDataURL(pasted)
+	if err != nil {
+		t.Fatalf("encodeImageToDataURL: %v", err)
+	}
+	if !strings.HasPrefix(url, "data:image/png;base64,") {
+		t.Fatalf("expected a PNG data URL, got %q", url)
+	}
+
+	if _, err := client.encodeImageToDataURL(filepath.Join(t.TempDir(), "missing.png")); err == nil {
+		t.Fatalf("expected an error for a missing file")
+	}
+}
+
+func TestStreamChatWithImagesYieldsDeltas(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var got imageRequestBody
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if !got.Stream || r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("expected a streamed request, got %+v", got)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"A red \"}}]}\n\n" +
+			"data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"dot.\"}}]}\n\n" +
+			"data: [DONE]\n\n"))
+	}))
+	defer srv.Close()
+	client := newTestClient(t, srv.URL)
+
+	chunks, err := client.StreamChatWithImages("Describe", []string{writeTestPNG(t)}, nil)
+	if err != nil {
+		t.Fatalf("StreamChatWithImages: %v", err)
+	}
+	var out strings.Builder
+	for chunk := range chunks {
+		out.WriteString(chunk)
+	}
+	if out.String() != "A red dot." {
+		t.Fatalf("unexpected streamed reply %q", out.String())
+	}
+}
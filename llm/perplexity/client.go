@@ -133,15 +133,84 @@ func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatR
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	// Perplexity might include citations and other metadata in the response
-	// These would be in the message content or as tool calls
+	// Source URLs arrive in the top-level citations array, which decodes
+	// straight into response.Citations
 
 	return &response, nil
 }
 
-// ChatStream is not implemented for Perplexity yet
+// ChatStream sends a streaming chat request to Perplexity
 func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
-	return nil, fmt.Errorf("streaming not implemented for Perplexity client")
+	// Convert to Perplexity request and enable streaming
+	perplexityReq := c.convertRequest(request)
+	perplexityReq.Stream = true
+
+	// Create request body
+	body, err := json.Marshal(perplexityReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, "POST", c.options.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers
+	c.setHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+
+	// Execute request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	// Check for errors
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("Perplexity API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	// Create event channel
+	events := make(chan llm.StreamEvent)
+
+	// Start goroutine to read stream
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		reader := llm.NewSSEReader(resp.Body)
+		for {
+			data, err := reader.Next()
+			if err != nil {
+				return
+			}
+
+			// Check for end of stream
+			if data == "[DONE]" {
+				return
+			}
+
+			// Parse event
+			var event llm.StreamEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				llm.DebugSkippedEvent("Perplexity", data, err)
+				continue
+			}
+
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
 }
 
 // ListModels returns available Perplexity models
@@ -0,0 +1,139 @@
+package perplexity
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// fixtureServer serves a recorded Perplexity response and hands the decoded
+// request body to inspect.
+func fixtureServer(t *testing.T, fixture, contentType string, inspect func(*http.Request, PerplexityRequest)) *httptest.Server {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var req PerplexityRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if inspect != nil {
+			inspect(r, req)
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestClient(t *testing.T, baseURL string) *Client {
+	t.Helper()
+	client, err := NewClient(llm.WithAPIKey("test-key"), llm.WithBaseURL(baseURL))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client
+}
+
+func TestChatStreamReplaysRecordedFixture(t *testing.T) {
+	srv := fixtureServer(t, "chat_stream.txt", "text/event-stream", func(r *http.Request, req PerplexityRequest) {
+		if !req.Stream {
+			t.Errorf("expected stream=true in request")
+		}
+		if got := r.Header.Get("Accept"); got != "text/event-stream" {
+			t.Errorf("expected Accept text/event-stream, got %q", got)
+		}
+		if !req.ReturnCitations {
+			t.Errorf("expected Perplexity-specific fields to be kept when streaming")
+		}
+	})
+	client := newTestClient(t, srv.URL)
+
+	events, err := client.ChatStream(context.Background(), &llm.ChatRequest{
+		Messages: []llm.Message{{Role: llm.RoleUser, Content: llm.StringPtr("When was Go 1.0 released?")}},
+	})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+
+	var content strings.Builder
+	var finish string
+	count := 0
+	for event := range events {
+		count++
+		for _, choice := range event.Choices {
+			if choice.Delta != nil && choice.Delta.Content != nil {
+				content.WriteString(*choice.Delta.Content)
+			}
+			if choice.FinishReason != "" {
+				finish = choice.FinishReason
+			}
+		}
+	}
+
+	if count != 3 {
+		t.Fatalf("expected 3 events, got %d", count)
+	}
+	if got := content.String(); got != "Go 1.0 was released in March 2012 [1]." {
+		t.Fatalf("unexpected streamed content %q", got)
+	}
+	if finish != "stop" {
+		t.Fatalf("expected finish_reason stop, got %q", finish)
+	}
+}
+
+func TestChatStreamReturnsAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"bad key"}}`, http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	client := newTestClient(t, srv.URL)
+
+	_, err := client.ChatStream(context.Background(), &llm.ChatRequest{})
+	if err == nil || !strings.Contains(err.Error(), "status 401") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+}
+
+func TestChatExposesCitations(t *testing.T) {
+	srv := fixtureServer(t, "chat_response.json", "application/json", func(r *http.Request, req PerplexityRequest) {
+		if req.Stream {
+			t.Errorf("expected non-streaming request")
+		}
+	})
+	client := newTestClient(t, srv.URL)
+
+	resp, err := client.Chat(context.Background(), &llm.ChatRequest{
+		Messages: []llm.Message{{Role: llm.RoleUser, Content: llm.StringPtr("When was Go 1.0 released?")}},
+	})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	want := []string{"https://go.dev/doc/", "https://go.dev/blog/"}
+	if len(resp.Citations) != len(want) {
+		t.Fatalf("expected citations %v, got %v", want, resp.Citations)
+	}
+	for i := range want {
+		if resp.Citations[i] != want[i] {
+			t.Fatalf("expected citations %v, got %v", want, resp.Citations)
+		}
+	}
+}
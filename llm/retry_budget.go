@@ -0,0 +1,67 @@
+package llm
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type retryBudgetKey struct{}
+
+// RetryBudget caps the total number of retries shared by every LLM call that
+// runs under the same context. It is safe for concurrent use.
+type RetryBudget struct {
+	remaining atomic.Int64
+	used      atomic.Int64
+}
+
+// NewRetryBudget returns a budget that allows n retries in total.
+func NewRetryBudget(n int) *RetryBudget {
+	b := &RetryBudget{}
+	b.remaining.Store(int64(n))
+	return b
+}
+
+// Take consumes one retry, reporting false when the budget is spent.
+func (b *RetryBudget) Take() bool {
+	for {
+		left := b.remaining.Load()
+		if left <= 0 {
+			return false
+		}
+		if b.remaining.CompareAndSwap(left, left-1) {
+			b.used.Add(1)
+			return true
+		}
+	}
+}
+
+// Used returns how many retries have been consumed.
+func (b *RetryBudget) Used() int {
+	return int(b.used.Load())
+}
+
+// Remaining returns how many retries are left.
+func (b *RetryBudget) Remaining() int {
+	return int(b.remaining.Load())
+}
+
+// WithRetryBudget attaches budget to ctx so provider clients share it.
+func WithRetryBudget(ctx context.Context, budget *RetryBudget) context.Context {
+	return context.WithValue(ctx, retryBudgetKey{}, budget)
+}
+
+// RetryBudgetFromContext returns the budget attached to ctx, if any.
+func RetryBudgetFromContext(ctx context.Context) (*RetryBudget, bool) {
+	budget, ok := ctx.Value(retryBudgetKey{}).(*RetryBudget)
+	return budget, ok && budget != nil
+}
+
+// AllowRetry reports whether a client may retry a failed call under ctx,
+// consuming one retry from its budget. Without a budget it always allows.
+func AllowRetry(ctx context.Context) bool {
+	budget, ok := RetryBudgetFromContext(ctx)
+	if !ok {
+		return true
+	}
+	return budget.Take()
+}
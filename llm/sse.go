@@ -0,0 +1,176 @@
+package llm
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// maxSSELineSize bounds a single SSE line; large tool-call chunks can exceed
+// bufio.Scanner's 64KB default. It also bounds how much of an incomplete JSON
+// value is held while waiting for the rest.
+const maxSSELineSize = 1024 * 1024
+
+// utf8BOM is the byte order mark some providers put before a stream or
+// before each event's data
+const utf8BOM = "\ufeff"
+
+// SSEReader reads server-sent events from a streaming response body. It
+// follows the SSE framing rules: consecutive "data:" lines are joined with
+// newlines, a blank line ends the event, and comment lines starting with ":"
+// (such as ": keepalive" heartbeats from proxies) are ignored. A UTF-8 byte
+// order mark or indentation before a field, and a byte order mark at the
+// start of its data, are dropped.
+//
+// Some gateways pack several JSON objects into one event, or spread one
+// object over several lines or events, so event data is split into the JSON
+// values it holds and each is returned separately.
+type SSEReader struct {
+	scanner *bufio.Scanner
+	data    []string
+	// queue holds values already split out of an event
+	queue []string
+	// partial is the start of a JSON value whose rest is in a later event
+	partial string
+}
+
+// NewSSEReader creates a reader over an SSE stream
+func NewSSEReader(r io.Reader) *SSEReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSSELineSize)
+	return &SSEReader{scanner: scanner}
+}
+
+// Next returns the next JSON value from the stream's event data, or the
+// whole data of an event that is not JSON (such as "[DONE]"), and io.EOF
+// once the stream ends. A final event that is not followed by a blank line
+// is still returned, as is an incomplete value left at the end.
+func (r *SSEReader) Next() (string, error) {
+	for len(r.queue) == 0 {
+		lines, err := r.nextEvent()
+		if errors.Is(err, io.EOF) && r.partial != "" {
+			data := r.partial
+			r.partial = ""
+			return data, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		r.queue = r.split(lines)
+	}
+	data := r.queue[0]
+	r.queue = r.queue[1:]
+	return data, nil
+}
+
+// nextEvent returns the data lines of the next event
+func (r *SSEReader) nextEvent() ([]string, error) {
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+		if line == "" {
+			if len(r.data) > 0 {
+				return r.flush(), nil
+			}
+			continue
+		}
+		line = strings.TrimLeft(line, " \t"+utf8BOM)
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		if field == "data" {
+			value = strings.TrimPrefix(value, " ")
+			if trimmed := strings.TrimLeft(value, " \t"); strings.HasPrefix(trimmed, utf8BOM) {
+				value = trimmed[len(utf8BOM):]
+			}
+			r.data = append(r.data, value)
+		}
+		// event, id, and retry fields are not used by the provider clients
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(r.data) > 0 {
+		return r.flush(), nil
+	}
+	return nil, io.EOF
+}
+
+func (r *SSEReader) flush() []string {
+	lines := append([]string(nil), r.data...)
+	r.data = r.data[:0]
+	return lines
+}
+
+// split breaks an event's data lines into the JSON values they hold,
+// prefixed by any incomplete value from earlier events. When joining the
+// lines with newlines does not give valid JSON, as when a line break falls
+// inside a string, they are joined without them. An incomplete value at the
+// end is kept for the next event; data that is not JSON is returned whole.
+func (r *SSEReader) split(lines []string) []string {
+	joined := strings.Join(lines, "\n")
+	if r.partial == "" && strings.TrimSpace(joined) == "" {
+		return []string{joined}
+	}
+
+	text := r.partial + joined
+	values, rest, err := splitJSONValues(text)
+	if err != nil && len(lines) > 1 {
+		if v, rt, e := splitJSONValues(r.partial + strings.Join(lines, "")); e == nil {
+			values, rest, err = v, rt, e
+		}
+	}
+	r.partial = ""
+
+	switch {
+	case err != nil && len(values) == 0:
+		return []string{strings.TrimSpace(text)}
+	case err != nil:
+		return append(values, strings.TrimSpace(rest))
+	case rest != "" && len(rest) > maxSSELineSize:
+		return append(values, rest)
+	case rest != "":
+		r.partial = rest
+	}
+	return values
+}
+
+// DebugSkippedEvent reports, when SIMPLE_AGENT_DEBUG is set, stream data a
+// client dropped because it could not parse it, so gaps in a streamed
+// response can be traced back to the events that caused them
+func DebugSkippedEvent(provider, data string, err error) {
+	if os.Getenv("SIMPLE_AGENT_DEBUG") != "true" {
+		return
+	}
+	if len(data) > 200 {
+		data = data[:200] + "..."
+	}
+	fmt.Fprintf(os.Stderr, "[%s] Skipped stream event that failed to parse (%v): %q\n", provider, err, data)
+}
+
+// splitJSONValues decodes consecutive JSON values from text. rest is what
+// follows the last complete value: the start of an incomplete one, or with
+// an error, text that is not JSON.
+func splitJSONValues(text string) (values []string, rest string, err error) {
+	dec := json.NewDecoder(strings.NewReader(text))
+	for {
+		start := dec.InputOffset()
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		switch {
+		case err == nil:
+			values = append(values, string(raw))
+		case errors.Is(err, io.EOF):
+			return values, "", nil
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			return values, text[start:], nil
+		default:
+			return values, text[start:], err
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func readAllSSE(t *testing.T, stream string) []string {
+	t.Helper()
+	reader := NewSSEReader(strings.NewReader(stream))
+	var events []string
+	for {
+		data, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return events
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		events = append(events, data)
+	}
+}
+
+func TestSSEReaderIgnoresCommentLines(t *testing.T) {
+	stream := ": keepalive\n\n" +
+		"data: {\"n\":1}\n\n" +
+		": keepalive\n" +
+		":\n\n" +
+		"data: {\"n\":2}\n\n" +
+		"data: [DONE]\n\n"
+
+	got := readAllSSE(t, stream)
+	want := []string{`{"n":1}`, `{"n":2}`, "[DONE]"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestSSEReaderJoinsMultiLineData(t *testing.T) {
+	// A heartbeat in the middle of an event must not split its data lines.
+	stream := "event: message\n" +
+		"data: {\"choices\":\n" +
+		": keepalive\n" +
+		"data: []}\n" +
+		"id: 7\n\n" +
+		"data:no-space\n"
+
+	got := readAllSSE(t, stream)
+	want := []string{"{\"choices\":\n[]}", "no-space"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestSSEReaderHandlesCRLF(t *testing.T) {
+	got := readAllSSE(t, ": ping\r\ndata: a\r\n\r\ndata: b\r\n\r\n")
+	if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Fatalf("expected [a b], got %q", got)
+	}
+}
+
+func TestSSEReaderSplitsObjectsSharingALine(t *testing.T) {
+	stream := "data: {\"n\":1}{\"n\":2} {\"n\":3}\n\n" +
+		"data: {\"n\":4}\n" +
+		"data: {\"n\":5}\n\n" +
+		"data: [DONE]\n\n"
+
+	got := readAllSSE(t, stream)
+	want := []string{`{"n":1}`, `{"n":2}`, `{"n":3}`, `{"n":4}`, `{"n":5}`, "[DONE]"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestSSEReaderJoinsObjectsSplitAcrossLines(t *testing.T) {
+	// One object is cut inside a string across two data lines of an event,
+	// another across two events; the third event also starts a new object.
+	stream := "data: {\"content\":\"hel\n" +
+		"data: lo\"}\n\n" +
+		"data: {\"choices\":[{\"delta\":\n\n" +
+		": keepalive\n\n" +
+		"data: {\"content\":\"!\"}}]}{\"n\":\n\n" +
+		"data: 2}\n\n" +
+		"data: [DONE]\n\n"
+
+	got := readAllSSE(t, stream)
+	want := []string{
+		`{"content":"hello"}`,
+		`{"choices":[{"delta":{"content":"!"}}]}`,
+		`{"n":2}`,
+		"[DONE]",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestSSEReaderStripsByteOrderMarks(t *testing.T) {
+	stream := "\ufeffdata: {\"n\":1}\n\n" +
+		"data: \ufeff{\"n\":2}\n\n" +
+		"data:   \ufeff{\"n\":3}\n\n" +
+		"  data: {\"n\":4}\n\n" +
+		"data:  [DONE]\n\n"
+
+	got := readAllSSE(t, stream)
+	want := []string{`{"n":1}`, `{"n":2}`, `{"n":3}`, `{"n":4}`, "[DONE]"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	for _, data := range got[:4] {
+		var v struct{ N int }
+		if err := json.Unmarshal([]byte(data), &v); err != nil {
+			t.Fatalf("expected %q to parse: %v", data, err)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSSEHeartbeat is how often an idle SSEWriter sends a heartbeat.
+// Proxies commonly drop connections that are silent for 30-60 seconds.
+const DefaultSSEHeartbeat = 15 * time.Second
+
+// SSEWriter writes server-sent events, flushing after each one when the
+// underlying writer can flush (as an http.ResponseWriter can). Writes are
+// safe from several goroutines, so heartbeats can interleave with events.
+type SSEWriter struct {
+	mu        sync.Mutex
+	w         io.Writer
+	lastWrite time.Time
+	err       error
+}
+
+// NewSSEWriter creates a writer for an SSE stream
+func NewSSEWriter(w io.Writer) *SSEWriter {
+	return &SSEWriter{w: w, lastWrite: time.Now()}
+}
+
+// WriteEvent sends one event. A multi-line data value is sent as several
+// "data:" lines, which readers join back with newlines; event is left out
+// when empty.
+func (s *SSEWriter) WriteEvent(event, data string) error {
+	var b strings.Builder
+	if event != "" {
+		fmt.Fprintf(&b, "event: %s\n", event)
+	}
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	return s.write(b.String())
+}
+
+// Comment sends a comment line, which readers ignore
+func (s *SSEWriter) Comment(text string) error {
+	return s.write(": " + text + "\n\n")
+}
+
+// Heartbeat sends ": keepalive" whenever the stream has been idle for
+// interval, until stop is called, so proxies keep the connection open
+// while the agent waits on a slow model or tool. It stops on its own once
+// a write fails.
+func (s *SSEWriter) Heartbeat(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		interval = DefaultSSEHeartbeat
+	}
+	done := make(chan struct{})
+	var once sync.Once
+	go func() {
+		ticker := time.NewTicker(interval / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				s.mu.Lock()
+				idle := time.Since(s.lastWrite)
+				s.mu.Unlock()
+				if idle < interval {
+					continue
+				}
+				if err := s.Comment("keepalive"); err != nil {
+					return
+				}
+			}
+		}
+	}()
+	return func() { once.Do(func() { close(done) }) }
+}
+
+func (s *SSEWriter) write(text string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	if _, err := io.WriteString(s.w, text); err != nil {
+		s.err = err
+		return err
+	}
+	if f, ok := s.w.(interface{ Flush() }); ok {
+		f.Flush()
+	}
+	s.lastWrite = time.Now()
+	return nil
+}
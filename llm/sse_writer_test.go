@@ -0,0 +1,73 @@
+package llm
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockedBuffer is a bytes.Buffer safe to read while heartbeats write to it
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestSSEWriterRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewSSEWriter(&buf)
+	if err := w.WriteEvent("message", "{\"a\":\n1}"); err != nil {
+		t.Fatalf("WriteEvent: %v", err)
+	}
+	if err := w.Comment("keepalive"); err != nil {
+		t.Fatalf("Comment: %v", err)
+	}
+	if err := w.WriteEvent("", "[DONE]"); err != nil {
+		t.Fatalf("WriteEvent: %v", err)
+	}
+
+	if want := "event: message\ndata: {\"a\":\ndata: 1}\n\n: keepalive\n\ndata: [DONE]\n\n"; buf.String() != want {
+		t.Fatalf("expected %q, got %q", want, buf.String())
+	}
+	got := readAllSSE(t, buf.String())
+	if len(got) != 2 || got[0] != "{\"a\":\n1}" || got[1] != "[DONE]" {
+		t.Fatalf("reader should get the events back, got %q", got)
+	}
+}
+
+func TestSSEWriterHeartbeatWhileIdle(t *testing.T) {
+	var buf lockedBuffer
+	w := NewSSEWriter(&buf)
+	stop := w.Heartbeat(10 * time.Millisecond)
+	defer stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for strings.Count(buf.String(), ": keepalive\n\n") < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected heartbeats while idle, got %q", buf.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	stop()
+	if err := w.WriteEvent("", "done"); err != nil {
+		t.Fatalf("WriteEvent: %v", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if !strings.HasSuffix(buf.String(), "data: done\n\n") {
+		t.Fatalf("no heartbeats should follow stop, got %q", buf.String())
+	}
+}
@@ -97,6 +97,12 @@ type ChatRequest struct {
 	FrequencyPenalty float32                  `json:"frequency_penalty,omitempty"`
 	PresencePenalty  float32                  `json:"presence_penalty,omitempty"`
 	Stop             []string                 `json:"stop,omitempty"`
+	StreamOptions    *StreamOptions           `json:"stream_options,omitempty"`
+}
+
+// StreamOptions configures streamed responses on OpenAI-compatible APIs
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"` // Request a final chunk carrying token usage
 }
 
 // ResponseFormat specifies the format of the response
@@ -114,6 +120,7 @@ type ChatResponse struct {
 	Usage             *Usage         `json:"usage,omitempty"`
 	SystemFingerprint string         `json:"system_fingerprint,omitempty"`
 	Error             *ErrorResponse `json:"error,omitempty"`
+	Citations         []string       `json:"citations,omitempty"` // Source URLs (Perplexity)
 }
 
 // Choice represents a single response choice
@@ -157,6 +164,10 @@ type ClientOptions struct {
 	DefaultModel string
 	Organization string
 	Headers      map[string]string
+
+	// ImageMaxDimension downscales image attachments whose longest side is
+	// larger than this many pixels before they are sent; 0 sends them as-is.
+	ImageMaxDimension int
 }
 
 // ClientOption is a functional option for configuring clients
@@ -216,6 +227,14 @@ func WithHeaders(headers map[string]string) ClientOption {
 	}
 }
 
+// WithImageMaxDimension downscales image attachments to at most px pixels on
+// their longest side, re-encoding them as JPEG. 0 (the default) disables it.
+func WithImageMaxDimension(px int) ClientOption {
+	return func(o *ClientOptions) {
+		o.ImageMaxDimension = px
+	}
+}
+
 // StringPtr is a helper function to get a pointer to a string
 func StringPtr(s string) *string {
 	return &s
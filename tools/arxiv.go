@@ -0,0 +1,282 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/nachoal/simple-agent-go/tools/base"
+)
+
+const (
+	arxivAPIURL             = "http://export.arxiv.org/api/query"
+	defaultArXivMaxResults  = 5
+	maxArXivMaxResults      = 50
+	maxArXivAbstractPreview = 300
+)
+
+var arxivSortOptions = map[string]bool{
+	"relevance":       true,
+	"lastUpdatedDate": true,
+	"submittedDate":   true,
+}
+
+type ArXivParams struct {
+	Operation  string `json:"operation,omitempty" description:"Operation: search (default) or get_paper"`
+	Query      string `json:"query,omitempty" description:"Search query (search: required). Plain words search all fields; arXiv prefixes like ti: or au: are passed through"`
+	MaxResults int    `json:"max_results,omitempty" description:"Number of papers to return (search: optional, default 5, max 50)"`
+	SortBy     string `json:"sort_by,omitempty" description:"Sort order for search: relevance, lastUpdatedDate, or submittedDate (optional)"`
+	ID         string `json:"id,omitempty" description:"arXiv ID such as 2301.01234 (get_paper: required)"`
+}
+
+// ArXivTool searches arXiv and fetches paper details through the export API.
+type ArXivTool struct {
+	base.BaseTool
+	client  *http.Client
+	baseURL string
+}
+
+type arxivFeed struct {
+	Entries []arxivEntry `xml:"entry"`
+}
+
+type arxivEntry struct {
+	ID        string `xml:"id"`
+	Title     string `xml:"title"`
+	Summary   string `xml:"summary"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Authors   []struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	Links []struct {
+		Href  string `xml:"href,attr"`
+		Title string `xml:"title,attr"`
+		Type  string `xml:"type,attr"`
+	} `xml:"link"`
+	PrimaryCategory struct {
+		Term string `xml:"term,attr"`
+	} `xml:"primary_category"`
+}
+
+// Parameters returns the parameters struct
+func (t *ArXivTool) Parameters() interface{} {
+	return &ArXivParams{}
+}
+
+// Execute runs the requested arXiv operation.
+func (t *ArXivTool) Execute(ctx context.Context, params json.RawMessage) (string, error) {
+	var args ArXivParams
+	if err := json.Unmarshal(params, &args); err != nil {
+		return "", NewToolError("INVALID_PARAMS", "Failed to parse parameters").
+			WithDetail("error", err.Error())
+	}
+
+	switch strings.TrimSpace(args.Operation) {
+	case "", "search":
+		return t.search(ctx, args)
+	case "get_paper":
+		return t.getPaper(ctx, args.ID)
+	default:
+		return "", NewToolError("VALIDATION_FAILED", "Operation must be search or get_paper").
+			WithDetail("operation", args.Operation)
+	}
+}
+
+func (t *ArXivTool) search(ctx context.Context, args ArXivParams) (string, error) {
+	query := strings.TrimSpace(args.Query)
+	if query == "" {
+		return "", NewToolError("VALIDATION_FAILED", "Query cannot be empty")
+	}
+
+	maxResults := args.MaxResults
+	if maxResults <= 0 {
+		maxResults = defaultArXivMaxResults
+	}
+	if maxResults > maxArXivMaxResults {
+		maxResults = maxArXivMaxResults
+	}
+
+	values := url.Values{}
+	values.Set("search_query", arxivSearchQuery(query))
+	values.Set("start", "0")
+	values.Set("max_results", strconv.Itoa(maxResults))
+	if sortBy := strings.TrimSpace(args.SortBy); sortBy != "" {
+		if !arxivSortOptions[sortBy] {
+			return "", NewToolError("VALIDATION_FAILED", "sort_by must be relevance, lastUpdatedDate, or submittedDate").
+				WithDetail("sort_by", sortBy)
+		}
+		values.Set("sortBy", sortBy)
+		values.Set("sortOrder", "descending")
+	}
+
+	entries, err := t.fetch(ctx, values)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 0 {
+		return fmt.Sprintf("No arXiv papers found for query: %s", query), nil
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("arXiv results for '%s':\n", query))
+	for i, entry := range entries {
+		b.WriteString(fmt.Sprintf("\n%d. **%s**\n", i+1, collapseArXivText(entry.Title)))
+		b.WriteString(fmt.Sprintf("   Authors: %s\n", arxivAuthors(entry)))
+		b.WriteString(fmt.Sprintf("   ID: %s | Submitted: %s\n", arxivEntryID(entry), arxivDate(entry.Published)))
+		b.WriteString(fmt.Sprintf("   PDF: %s\n", arxivPDFURL(entry)))
+		b.WriteString(fmt.Sprintf("   Abstract: %s\n", truncateRunes(collapseArXivText(entry.Summary), maxArXivAbstractPreview)))
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+func (t *ArXivTool) getPaper(ctx context.Context, rawID string) (string, error) {
+	id := normalizeArXivID(rawID)
+	if id == "" {
+		return "", NewToolError("VALIDATION_FAILED", "Paper ID is required for get_paper")
+	}
+
+	values := url.Values{}
+	values.Set("id_list", id)
+	entries, err := t.fetch(ctx, values)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 0 || strings.TrimSpace(entries[0].Title) == "" {
+		return "", NewToolError("NOT_FOUND", "No arXiv paper found").
+			WithDetail("id", id)
+	}
+
+	entry := entries[0]
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# %s\n\n", collapseArXivText(entry.Title)))
+	b.WriteString(fmt.Sprintf("- Authors: %s\n", arxivAuthors(entry)))
+	b.WriteString(fmt.Sprintf("- ID: %s\n", arxivEntryID(entry)))
+	if entry.PrimaryCategory.Term != "" {
+		b.WriteString(fmt.Sprintf("- Category: %s\n", entry.PrimaryCategory.Term))
+	}
+	b.WriteString(fmt.Sprintf("- Submitted: %s\n", arxivDate(entry.Published)))
+	if entry.Updated != "" && arxivDate(entry.Updated) != arxivDate(entry.Published) {
+		b.WriteString(fmt.Sprintf("- Updated: %s\n", arxivDate(entry.Updated)))
+	}
+	b.WriteString(fmt.Sprintf("- PDF: %s\n\n", arxivPDFURL(entry)))
+	b.WriteString("## Abstract\n\n")
+	b.WriteString(collapseArXivText(entry.Summary))
+	return b.String(), nil
+}
+
+func (t *ArXivTool) fetch(ctx context.Context, values url.Values) ([]arxivEntry, error) {
+	baseURL := t.baseURL
+	if baseURL == "" {
+		baseURL = arxivAPIURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"?"+values.Encode(), nil)
+	if err != nil {
+		return nil, NewToolError("REQUEST_ERROR", "Failed to create request").
+			WithDetail("error", err.Error())
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, NewToolError("HTTP_ERROR", "Failed to fetch arXiv data").
+			WithDetail("error", err.Error())
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, NewToolError("READ_ERROR", "Failed to read response").
+			WithDetail("error", err.Error())
+	}
+
+	var feed arxivFeed
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, NewToolError("HTTP_ERROR", fmt.Sprintf("arXiv returned status %d", resp.StatusCode))
+		}
+		return nil, NewToolError("PARSE_ERROR", "Failed to parse arXiv response").
+			WithDetail("error", err.Error())
+	}
+
+	// The API reports bad queries as a single entry whose ID points at its
+	// error documentation.
+	if len(feed.Entries) == 1 && strings.Contains(feed.Entries[0].ID, "/api/errors") {
+		return nil, NewToolError("API_ERROR", collapseArXivText(feed.Entries[0].Summary))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, NewToolError("HTTP_ERROR", fmt.Sprintf("arXiv returned status %d", resp.StatusCode))
+	}
+	return feed.Entries, nil
+}
+
+// arxivSearchQuery searches all fields for each word of a plain query and
+// leaves queries that already use arXiv field prefixes untouched.
+func arxivSearchQuery(query string) string {
+	if strings.Contains(query, ":") {
+		return query
+	}
+	words := strings.Fields(query)
+	for i, word := range words {
+		words[i] = "all:" + word
+	}
+	return strings.Join(words, " AND ")
+}
+
+func normalizeArXivID(id string) string {
+	id = strings.TrimSpace(id)
+	for _, prefix := range []string{"https://arxiv.org/abs/", "http://arxiv.org/abs/", "https://arxiv.org/pdf/", "http://arxiv.org/pdf/"} {
+		id = strings.TrimPrefix(id, prefix)
+	}
+	if len(id) > len("arxiv:") && strings.EqualFold(id[:len("arxiv:")], "arxiv:") {
+		id = id[len("arxiv:"):]
+	}
+	return strings.TrimSuffix(id, ".pdf")
+}
+
+func arxivEntryID(entry arxivEntry) string {
+	id := strings.TrimSpace(entry.ID)
+	if idx := strings.Index(id, "/abs/"); idx >= 0 {
+		return id[idx+len("/abs/"):]
+	}
+	return id
+}
+
+func arxivPDFURL(entry arxivEntry) string {
+	for _, link := range entry.Links {
+		if link.Title == "pdf" || link.Type == "application/pdf" {
+			return link.Href
+		}
+	}
+	return "https://arxiv.org/pdf/" + arxivEntryID(entry)
+}
+
+func arxivAuthors(entry arxivEntry) string {
+	names := make([]string, 0, len(entry.Authors))
+	for _, author := range entry.Authors {
+		if name := strings.TrimSpace(author.Name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "Unknown"
+	}
+	return strings.Join(names, ", ")
+}
+
+func arxivDate(timestamp string) string {
+	timestamp = strings.TrimSpace(timestamp)
+	if len(timestamp) >= len("2006-01-02") {
+		return timestamp[:len("2006-01-02")]
+	}
+	return timestamp
+}
+
+func collapseArXivText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
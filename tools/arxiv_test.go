@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+)
+
+func newArXivFixtureServer(t *testing.T, fixture string, status int, seen *url.Values) *ArXivTool {
+	t.Helper()
+	body, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if seen != nil {
+			*seen = r.URL.Query()
+		}
+		w.Header().Set("Content-Type", "application/atom+xml")
+		w.WriteHeader(status)
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+
+	tool := NewArXivTool().(*ArXivTool)
+	tool.baseURL = srv.URL
+	return tool
+}
+
+func TestArXivTool_SearchFormatsResults(t *testing.T) {
+	var query url.Values
+	tool := newArXivFixtureServer(t, "testdata/arxiv_search.xml", http.StatusOK, &query)
+
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{"query":"attention transformer","max_results":2,"sort_by":"submittedDate"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query.Get("search_query") != "all:attention AND all:transformer" || query.Get("max_results") != "2" || query.Get("sortBy") != "submittedDate" {
+		t.Fatalf("unexpected API query: %v", query)
+	}
+	for _, want := range []string{
+		"1. **Attention Is All You Need**",
+		"Authors: Ashish Vaswani, Noam Shazeer, Niki Parmar",
+		"ID: 1706.03762v7 | Submitted: 2017-06-12",
+		"PDF: http://arxiv.org/pdf/1706.03762v7",
+		"2. **An Image is Worth 16x16 Words: Transformers for Image Recognition at Scale**",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "less time to train") || !strings.Contains(out, "...") {
+		t.Fatalf("expected abstract preview to be truncated:\n%s", out)
+	}
+
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{"query":"x","sort_by":"citations"}`)); err == nil {
+		t.Fatalf("expected invalid sort_by to be rejected")
+	}
+}
+
+func TestArXivTool_GetPaperReturnsFullAbstract(t *testing.T) {
+	var query url.Values
+	tool := newArXivFixtureServer(t, "testdata/arxiv_search.xml", http.StatusOK, &query)
+
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{"operation":"get_paper","id":"arXiv:1706.03762"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query.Get("id_list") != "1706.03762" {
+		t.Fatalf("expected normalized id_list, got %v", query)
+	}
+	for _, want := range []string{"# Attention Is All You Need", "- Category: cs.CL", "- Updated: 2023-08-02", "requiring significantly less time to train."} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestArXivTool_ReportsAPIErrors(t *testing.T) {
+	tool := newArXivFixtureServer(t, "testdata/arxiv_error.xml", http.StatusBadRequest, nil)
+
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"operation":"get_paper","id":"not-an-id"}`))
+	if err == nil || !strings.Contains(err.Error(), "incorrect id format") {
+		t.Fatalf("expected API error message, got %v", err)
+	}
+}
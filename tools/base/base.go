@@ -1,9 +1,16 @@
 package base
 
+// DefaultMaxOutputBytes is the output limit for tools that set no
+// MaxOutputBytes, when the registry has no global limit either
+const DefaultMaxOutputBytes = 32 * 1024
+
 // BaseTool provides common functionality for tools
 type BaseTool struct {
 	ToolName string
 	ToolDesc string
+	// MaxOutputBytes caps the text the tool returns to the model; longer
+	// output is truncated by the registry. 0 uses the registry's limit.
+	MaxOutputBytes int
 }
 
 // Name returns the tool name
@@ -15,3 +22,8 @@ func (b *BaseTool) Name() string {
 func (b *BaseTool) Description() string {
 	return b.ToolDesc
 }
+
+// OutputLimit returns MaxOutputBytes
+func (b *BaseTool) OutputLimit() int {
+	return b.MaxOutputBytes
+}
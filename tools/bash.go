@@ -28,6 +28,50 @@ type BashTool struct {
 	base.BaseTool
 	allowedCommands []string
 	allowAll        bool
+	confirm         bool
+	dockerImage     string
+	workdir         string
+	lookPath        func(string) (string, error)
+}
+
+// BashOption configures a BashTool.
+type BashOption func(*BashTool)
+
+// WithDockerSandbox runs commands inside a throwaway container of the given
+// image with networking disabled. Only the working directory is mounted, and
+// it is the only writable path. When docker is not installed, commands run
+// directly on the host. An empty image disables the sandbox.
+func WithDockerSandbox(image string) BashOption {
+	return func(t *BashTool) {
+		t.dockerImage = strings.TrimSpace(image)
+	}
+}
+
+// WithWorkdir runs commands in dir, and mounts it as the Docker sandbox's
+// workspace, instead of the current working directory. An empty dir keeps
+// the current working directory.
+func WithWorkdir(dir string) BashOption {
+	return func(t *BashTool) {
+		t.workdir = strings.TrimSpace(dir)
+	}
+}
+
+// WithAllowedCommands replaces the commands the tool runs without --yolo. An
+// empty list keeps the current allowlist.
+func WithAllowedCommands(commands []string) BashOption {
+	return func(t *BashTool) {
+		if len(commands) > 0 {
+			t.allowedCommands = commands
+		}
+	}
+}
+
+// WithConfirmation makes commands outside the allowlist ask for the user's
+// approval, via a ConfirmationRequired error, instead of being rejected.
+func WithConfirmation(enabled bool) BashOption {
+	return func(t *BashTool) {
+		t.confirm = enabled
+	}
 }
 
 // Parameters returns the parameters struct
@@ -66,36 +110,57 @@ func (t *BashTool) Execute(ctx context.Context, params json.RawMessage) (string,
 	// In production, implement more sophisticated sandboxing
 	baseCmd := strings.Fields(command)[0]
 	if !t.allowAll && !t.isCommandAllowed(baseCmd) {
-		return "", NewToolError("COMMAND_NOT_ALLOWED", "Command is not in the allowed list (start simple-agent with --yolo to allow any command)").
-			WithDetail("command", baseCmd).
-			WithDetail("allowed", strings.Join(t.allowedCommands, ", "))
+		if !t.confirm {
+			return "", NewToolError("COMMAND_NOT_ALLOWED", "Command is not in the allowed list (start simple-agent with --yolo to allow any command)").
+				WithDetail("command", baseCmd).
+				WithDetail("allowed", strings.Join(t.allowedCommands, ", "))
+		}
+		if !Approved(ctx, command) {
+			return "", NewToolError(ConfirmationRequired, "Command is not in the allowed list and was not run; it needs the user's approval").
+				WithDetail("command", command).
+				WithDetail("approval_token", command)
+		}
 	}
 
 	// Create context with timeout
 	cmdCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
 	defer cancel()
 
-	// Determine shell based on OS
-	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" {
-		cmd = exec.CommandContext(cmdCtx, "cmd", "/C", command)
-	} else {
-		cmd = exec.CommandContext(cmdCtx, "sh", "-c", command)
+	name, cmdArgs, sandbox, err := t.commandLine(command)
+	if err != nil {
+		return "", err
 	}
+	cmd := exec.CommandContext(cmdCtx, name, cmdArgs...)
+	cmd.Dir = t.workdir
 
-	// Capture output
+	// Capture output, reporting each line as it is produced when a progress
+	// reporter is attached to the context.
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
+	var lineWriters []*progressLineWriter
+	if reporter, ok := ProgressReporterFromContext(ctx); ok {
+		stdoutWriter := &progressLineWriter{buf: &stdout, report: reporter.ReportProgress}
+		stderrWriter := &progressLineWriter{buf: &stderr, report: reporter.ReportProgress}
+		cmd.Stdout = stdoutWriter
+		cmd.Stderr = stderrWriter
+		lineWriters = append(lineWriters, stdoutWriter, stderrWriter)
+	}
 
 	// Run the command
 	startTime := time.Now()
-	err := cmd.Run()
+	err = cmd.Run()
 	duration := time.Since(startTime)
+	for _, w := range lineWriters {
+		w.Flush()
+	}
 
 	// Build result
 	result := fmt.Sprintf("Command: %s\n", command)
 	result += fmt.Sprintf("Duration: %v\n", duration)
+	if sandbox != "" {
+		result += fmt.Sprintf("Sandbox: %s\n", sandbox)
+	}
 
 	result += "\n"
 
@@ -145,6 +210,50 @@ func (t *BashTool) Execute(ctx context.Context, params json.RawMessage) (string,
 	return result, nil
 }
 
+// commandLine returns the program and arguments that run command, plus a
+// short note describing the sandbox when one was requested.
+func (t *BashTool) commandLine(command string) (string, []string, string, error) {
+	if t.dockerImage != "" {
+		lookPath := t.lookPath
+		if lookPath == nil {
+			lookPath = exec.LookPath
+		}
+		if docker, err := lookPath("docker"); err == nil {
+			workspace, err := fileScope{root: t.workdir}.workspace()
+			if err != nil {
+				return "", nil, "", NewToolError("EXECUTION_ERROR", "Failed to resolve working directory").
+					WithDetail("error", err.Error())
+			}
+			return docker, dockerSandboxArgs(t.dockerImage, workspace, command), "docker (" + t.dockerImage + ")", nil
+		}
+		return shellCommandLine(command, "host (docker not found; ran without sandbox)")
+	}
+	return shellCommandLine(command, "")
+}
+
+func shellCommandLine(command, sandbox string) (string, []string, string, error) {
+	if runtime.GOOS == "windows" {
+		return "cmd", []string{"/C", command}, sandbox, nil
+	}
+	return "sh", []string{"-c", command}, sandbox, nil
+}
+
+// dockerSandboxArgs builds the docker run arguments for a sandboxed command:
+// no network, a read-only root filesystem with a scratch /tmp, and cwd
+// mounted read-write at /workspace.
+func dockerSandboxArgs(image, cwd, command string) []string {
+	return []string{
+		"run", "--rm",
+		"--network=none",
+		"--read-only",
+		"--tmpfs", "/tmp",
+		"-v", cwd + ":/workspace:rw",
+		"-w", "/workspace",
+		image,
+		"sh", "-c", command,
+	}
+}
+
 func validateCommandSafety(command string) error {
 	lower := strings.ToLower(command)
 
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 
@@ -87,6 +88,71 @@ func TestNewShellTool_YoloEnablesAllowAll(t *testing.T) {
 	}
 }
 
+func TestShellTool_ConfirmModeRequiresApproval(t *testing.T) {
+	tool := &BashTool{
+		BaseTool:        base.BaseTool{ToolName: "bash", ToolDesc: "test"},
+		allowedCommands: []string{"echo"},
+		confirm:         true,
+	}
+	params := json.RawMessage(`{"command":"printf approved"}`)
+
+	_, err := tool.Execute(context.Background(), params)
+	request, token, ok := ConfirmationRequest(err)
+	if !ok {
+		t.Fatalf("expected %s, got %v", ConfirmationRequired, err)
+	}
+	if request.Details["command"] != "printf approved" || token != "printf approved" {
+		t.Fatalf("expected the command in the request, got %+v", request.Details)
+	}
+
+	// Approval for a different command does not carry over
+	if _, err := tool.Execute(WithApproval(context.Background(), "printf other"), params); err == nil {
+		t.Fatalf("expected approval to be tied to the exact command")
+	}
+
+	out, err := tool.Execute(WithApproval(context.Background(), token), params)
+	if err != nil {
+		t.Fatalf("expected approved command to run, got %v", err)
+	}
+	if !strings.Contains(out, "approved") || !strings.Contains(out, "Exit Code: 0") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+
+	// Allowlisted commands still run without asking
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{"command":"echo hi"}`)); err != nil {
+		t.Fatalf("expected allowlisted command to run, got %v", err)
+	}
+}
+
+func TestNewShellTool_ReadsAllowlistFromConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("SIMPLE_AGENT_YOLO", "")
+	t.Setenv("SIMPLE_AGENT_BASH_CONFIRM", "")
+	if err := os.MkdirAll(filepath.Join(home, ".simple-agent"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := `{"default_provider":"openai","shell":{"allowed_commands":["printf"],"confirm":true}}`
+	if err := os.WriteFile(filepath.Join(home, ".simple-agent", "config.json"), []byte(cfg), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tool := NewBashTool().(*BashTool)
+	if !tool.confirm {
+		t.Fatalf("expected confirm mode from config")
+	}
+
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{"command":"printf ok"}`)); err != nil {
+		t.Fatalf("expected configured command to run, got %v", err)
+	}
+	// echo is in the built-in list but not the configured one
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"command":"echo hi"}`))
+	if _, _, ok := ConfirmationRequest(err); !ok {
+		t.Fatalf("expected echo to need approval once the config replaces the allowlist, got %v", err)
+	}
+}
+
 func TestBashTool_BlocksRiskyInstaloaderWithoutFailFastFlags(t *testing.T) {
 	tool := &BashTool{
 		BaseTool:        base.BaseTool{ToolName: "bash", ToolDesc: "test"},
@@ -170,3 +236,115 @@ func TestBashTool_BlocksInteractiveGitCommitWithoutMessage(t *testing.T) {
 		t.Fatalf("expected COMMAND_INTERACTIVE, got %q", te.Code)
 	}
 }
+
+func TestBashTool_ReportsEachOutputLineAsProgress(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh printf")
+	}
+	tool := &BashTool{
+		BaseTool: base.BaseTool{ToolName: "bash", ToolDesc: "test"},
+		allowAll: true,
+	}
+
+	var lines []string
+	ctx := WithProgressReporter(context.Background(), ProgressFunc(func(message string, _ float64) {
+		lines = append(lines, message)
+	}))
+
+	out, err := tool.Execute(ctx, json.RawMessage(`{"command":"printf 'one\\ntwo\\nthree'"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(lines, ",") != "one,two,three" {
+		t.Fatalf("expected one progress update per line, got %q", lines)
+	}
+	if !strings.Contains(out, "one\ntwo\nthree") {
+		t.Fatalf("expected full output in result, got:\n%s", out)
+	}
+}
+
+func TestShellTool_DockerSandboxArgs(t *testing.T) {
+	dir := t.TempDir()
+	withWorkingDir(t, dir)
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+
+	tool := NewBashTool(WithDockerSandbox("alpine:3.20")).(*BashTool)
+	tool.lookPath = func(string) (string, error) { return "/usr/bin/docker", nil }
+
+	name, args, sandbox, err := tool.commandLine("ls -la")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		"run", "--rm", "--network=none", "--read-only", "--tmpfs", "/tmp",
+		"-v", cwd + ":/workspace:rw", "-w", "/workspace",
+		"alpine:3.20", "sh", "-c", "ls -la",
+	}
+	if name != "/usr/bin/docker" || strings.Join(args, "\x00") != strings.Join(want, "\x00") {
+		t.Fatalf("unexpected docker invocation: %s %q", name, args)
+	}
+	if sandbox != "docker (alpine:3.20)" {
+		t.Fatalf("unexpected sandbox note %q", sandbox)
+	}
+}
+
+func TestShellTool_DockerSandboxMountsWorkdir(t *testing.T) {
+	withWorkingDir(t, t.TempDir())
+	root := t.TempDir()
+
+	tool := NewBashTool(WithDockerSandbox("alpine:3.20"), WithWorkdir(root)).(*BashTool)
+	tool.lookPath = func(string) (string, error) { return "/usr/bin/docker", nil }
+
+	_, args, _, err := tool.commandLine("ls")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Contains(args, root+":/workspace:rw") {
+		t.Fatalf("expected %s mounted at /workspace, got %q", root, args)
+	}
+}
+
+func TestShellTool_DockerSandboxFallsBackWithoutDocker(t *testing.T) {
+	tool := NewBashTool(WithDockerSandbox("alpine:3.20")).(*BashTool)
+	tool.lookPath = func(string) (string, error) { return "", os.ErrNotExist }
+
+	name, _, sandbox, err := tool.commandLine("ls")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name == "docker" || !strings.Contains(sandbox, "docker not found") {
+		t.Fatalf("expected host fallback, got %s (%s)", name, sandbox)
+	}
+}
+
+func TestShellTool_DockerSandboxFromEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("stub docker script requires sh")
+	}
+	t.Setenv("SIMPLE_AGENT_DOCKER_IMAGE", "busybox")
+	t.Setenv("SIMPLE_AGENT_YOLO", "")
+
+	dir := t.TempDir()
+	script := "#!/bin/sh\nprintf '%s\\n' \"$@\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0755); err != nil {
+		t.Fatalf("write stub docker: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	tool := NewBashTool()
+	if !strings.Contains(tool.Description(), "busybox Docker container") {
+		t.Fatalf("expected sandbox in description, got %q", tool.Description())
+	}
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{"command":"pwd"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Sandbox: docker (busybox)", "--network=none\n", "busybox\nsh\n-c\npwd\n"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
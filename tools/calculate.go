@@ -7,6 +7,7 @@ import (
 	"math"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/nachoal/simple-agent-go/tools/base"
 )
@@ -39,101 +40,551 @@ func (t *CalculateTool) Execute(ctx context.Context, params json.RawMessage) (st
 		return "", NewToolError("EMPTY_EXPRESSION", "Expression cannot be empty")
 	}
 
-	// For now, implement a simple calculator
-	// In production, use a proper expression evaluator like govaluate
-	result, err := t.evaluateSimple(expr)
+	program, err := parseCalculation(expr)
 	if err != nil {
-		return "", NewToolError("EVALUATION_ERROR", "Failed to evaluate expression").
+		return "", NewToolError("PARSE_ERROR", "Failed to parse expression").
 			WithDetail("error", err.Error()).
 			WithDetail("expression", expr)
 	}
 
-	return fmt.Sprintf("%s = %v", expr, result), nil
+	result, err := program.evaluate()
+	if err != nil {
+		return "", NewToolError("EVALUATION_ERROR", "Failed to evaluate expression").
+			WithDetail("error", err.Error()).
+			WithDetail("expression", program.String())
+	}
+
+	value := formatCalcNumber(result)
+	normalized := program.String()
+	switch {
+	case normalized == value:
+		return value, nil
+	case len(program.statements) > 1:
+		return fmt.Sprintf("Expression: %s\nResult: %s", normalized, value), nil
+	default:
+		return fmt.Sprintf("%s = %s", normalized, value), nil
+	}
+}
+
+func formatCalcNumber(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// calcConstants are the names an expression can use without assigning them
+var calcConstants = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+}
+
+// calcFunction is a built-in function with a fixed number of arguments
+type calcFunction struct {
+	arity int
+	fn    func(args []float64) (float64, error)
+}
+
+func unaryCalcFunction(fn func(float64) float64) calcFunction {
+	return calcFunction{arity: 1, fn: func(args []float64) (float64, error) {
+		return fn(args[0]), nil
+	}}
 }
 
-// evaluateSimple is a basic expression evaluator
-// In production, replace with a proper expression parsing library
-func (t *CalculateTool) evaluateSimple(expr string) (float64, error) {
-	// Remove spaces
-	expr = strings.ReplaceAll(expr, " ", "")
+var calcFunctions = map[string]calcFunction{
+	"sqrt":  unaryCalcFunction(math.Sqrt),
+	"sin":   unaryCalcFunction(math.Sin),
+	"cos":   unaryCalcFunction(math.Cos),
+	"tan":   unaryCalcFunction(math.Tan),
+	"log":   unaryCalcFunction(math.Log10),
+	"ln":    unaryCalcFunction(math.Log),
+	"exp":   unaryCalcFunction(math.Exp),
+	"abs":   unaryCalcFunction(math.Abs),
+	"floor": unaryCalcFunction(math.Floor),
+	"ceil":  unaryCalcFunction(math.Ceil),
+	"round": unaryCalcFunction(math.Round),
+	"pow": {arity: 2, fn: func(args []float64) (float64, error) {
+		return math.Pow(args[0], args[1]), nil
+	}},
+}
 
-	// Handle basic operations
-	// This is a simplified implementation
-	// Real implementation should use proper expression parsing
+// calcProgram is a sequence of statements separated by ";". Each statement
+// is an expression or an assignment; the value of the last one is the result.
+type calcProgram struct {
+	statements []calcNode
+}
 
-	// Try to parse as a simple number first
-	if val, err := strconv.ParseFloat(expr, 64); err == nil {
-		return val, nil
+func (p *calcProgram) evaluate() (float64, error) {
+	vars := make(map[string]float64)
+	var result float64
+	for _, stmt := range p.statements {
+		v, err := stmt.eval(vars)
+		if err != nil {
+			return 0, err
+		}
+		result = v
 	}
+	return result, nil
+}
+
+// String renders the program with normalized spacing and only the
+// parentheses precedence requires.
+func (p *calcProgram) String() string {
+	parts := make([]string, len(p.statements))
+	for i, stmt := range p.statements {
+		parts[i] = stmt.String()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Operator precedence, lowest first
+const (
+	precAssign = iota
+	precAdditive
+	precMultiplicative
+	precUnary
+	precPower
+	precAtom
+)
+
+type calcNode interface {
+	eval(vars map[string]float64) (float64, error)
+	precedence() int
+	String() string
+}
+
+type calcNumber struct{ value float64 }
+
+func (n calcNumber) eval(map[string]float64) (float64, error) { return n.value, nil }
+func (n calcNumber) precedence() int                          { return precAtom }
+func (n calcNumber) String() string                           { return formatCalcNumber(n.value) }
+
+type calcName struct{ name string }
+
+func (n calcName) eval(vars map[string]float64) (float64, error) {
+	if v, ok := vars[n.name]; ok {
+		return v, nil
+	}
+	if v, ok := calcConstants[n.name]; ok {
+		return v, nil
+	}
+	return 0, fmt.Errorf("undefined variable %q", n.name)
+}
+func (n calcName) precedence() int { return precAtom }
+func (n calcName) String() string  { return n.name }
+
+type calcAssign struct {
+	name  string
+	value calcNode
+}
+
+func (n calcAssign) eval(vars map[string]float64) (float64, error) {
+	v, err := n.value.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	vars[n.name] = v
+	return v, nil
+}
+func (n calcAssign) precedence() int { return precAssign }
+func (n calcAssign) String() string  { return n.name + " = " + n.value.String() }
 
-	// Handle basic binary operations
-	operators := []string{"+", "-", "*", "/", "^"}
-	for _, op := range operators {
-		parts := strings.SplitN(expr, op, 2)
-		if len(parts) == 2 {
-			left, err1 := t.evaluateSimple(parts[0])
-			right, err2 := t.evaluateSimple(parts[1])
+type calcUnary struct {
+	op      byte
+	operand calcNode
+}
+
+func (n calcUnary) eval(vars map[string]float64) (float64, error) {
+	v, err := n.operand.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	if n.op == '-' {
+		return -v, nil
+	}
+	return v, nil
+}
+func (n calcUnary) precedence() int { return precUnary }
+func (n calcUnary) String() string {
+	return string(n.op) + wrapCalcNode(n.operand, n.operand.precedence() < precUnary)
+}
+
+type calcBinary struct {
+	op          string
+	left, right calcNode
+}
+
+func (n calcBinary) eval(vars map[string]float64) (float64, error) {
+	left, err := n.left.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	right, err := n.right.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+
+	var result float64
+	switch n.op {
+	case "+":
+		result = left + right
+	case "-":
+		result = left - right
+	case "*":
+		result = left * right
+	case "/":
+		if right == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		result = left / right
+	case "%":
+		if right == 0 {
+			return 0, fmt.Errorf("modulo by zero")
+		}
+		result = math.Mod(left, right)
+	case "^":
+		result = math.Pow(left, right)
+	}
+	return checkCalcResult(n.String(), result)
+}
+
+func (n calcBinary) precedence() int {
+	switch n.op {
+	case "+", "-":
+		return precAdditive
+	case "^":
+		return precPower
+	default:
+		return precMultiplicative
+	}
+}
+
+func (n calcBinary) String() string {
+	prec := n.precedence()
+	// "^" groups to the right, the others to the left
+	leftParens := n.left.precedence() < prec || (n.op == "^" && n.left.precedence() == prec)
+	rightParens := n.right.precedence() < prec || (n.op != "^" && n.right.precedence() == prec)
+	if n.op == "^" && n.right.precedence() == precUnary {
+		rightParens = false // 2^-1 is unambiguous
+	}
+	return wrapCalcNode(n.left, leftParens) + " " + n.op + " " + wrapCalcNode(n.right, rightParens)
+}
+
+type calcCall struct {
+	name string
+	args []calcNode
+}
+
+func (n calcCall) eval(vars map[string]float64) (float64, error) {
+	fn := calcFunctions[n.name]
+	values := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(vars)
+		if err != nil {
+			return 0, err
+		}
+		values[i] = v
+	}
+	result, err := fn.fn(values)
+	if err != nil {
+		return 0, err
+	}
+	return checkCalcResult(n.String(), result)
+}
+func (n calcCall) precedence() int { return precAtom }
+func (n calcCall) String() string {
+	args := make([]string, len(n.args))
+	for i, arg := range n.args {
+		args[i] = arg.String()
+	}
+	return n.name + "(" + strings.Join(args, ", ") + ")"
+}
+
+func wrapCalcNode(n calcNode, parens bool) string {
+	if parens {
+		return "(" + n.String() + ")"
+	}
+	return n.String()
+}
 
-			if err1 != nil || err2 != nil {
-				continue
+// checkCalcResult rejects results that are not real numbers, such as
+// sqrt(-1) or an overflow, instead of reporting NaN or +Inf.
+func checkCalcResult(expr string, v float64) (float64, error) {
+	if math.IsNaN(v) {
+		return 0, fmt.Errorf("%s is not a real number", expr)
+	}
+	if math.IsInf(v, 0) {
+		return 0, fmt.Errorf("%s overflows", expr)
+	}
+	return v, nil
+}
+
+// calcParser is a recursive-descent parser over the expression grammar:
+//
+//	program    = statement { ";" statement }
+//	statement  = name "=" expression | expression
+//	expression = term { ("+" | "-") term }
+//	term       = unary { ("*" | "/" | "%") unary }
+//	unary      = ("+" | "-") unary | power
+//	power      = atom [ ("^" | "**") unary ]
+//	atom       = number | name | name "(" [ expression { "," expression } ] ")" | "(" expression ")"
+type calcParser struct {
+	input string
+	pos   int
+}
+
+func parseCalculation(input string) (*calcProgram, error) {
+	p := &calcParser{input: input}
+	program := &calcProgram{}
+	for {
+		p.skipSpace()
+		if p.done() {
+			// Tolerate a trailing ";"
+			if len(program.statements) == 0 {
+				return nil, fmt.Errorf("empty expression")
 			}
+			return program, nil
+		}
+		stmt, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		program.statements = append(program.statements, stmt)
 
-			switch op {
-			case "+":
-				return left + right, nil
-			case "-":
-				return left - right, nil
-			case "*":
-				return left * right, nil
-			case "/":
-				if right == 0 {
-					return 0, fmt.Errorf("division by zero")
-				}
-				return left / right, nil
-			case "^":
-				return math.Pow(left, right), nil
+		p.skipSpace()
+		if p.done() {
+			return program, nil
+		}
+		if !p.consume(";") {
+			return nil, p.errorf("unexpected %q", string(p.input[p.pos]))
+		}
+	}
+}
+
+func (p *calcParser) statement() (calcNode, error) {
+	start := p.pos
+	if name := p.name(); name != "" {
+		p.skipSpace()
+		// "=" but not "=="
+		if strings.HasPrefix(p.input[p.pos:], "=") && !strings.HasPrefix(p.input[p.pos:], "==") {
+			p.pos++
+			if _, ok := calcConstants[name]; ok {
+				return nil, fmt.Errorf("cannot assign to constant %q", name)
 			}
+			if _, ok := calcFunctions[name]; ok {
+				return nil, fmt.Errorf("cannot assign to function %q", name)
+			}
+			value, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			return calcAssign{name: name, value: value}, nil
+		}
+	}
+	p.pos = start
+	return p.expression()
+}
+
+func (p *calcParser) expression() (calcNode, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		op := p.peekOperator("+", "-")
+		if op == "" {
+			return left, nil
+		}
+		p.pos += len(op)
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = calcBinary{op: op, left: left, right: right}
+	}
+}
+
+func (p *calcParser) term() (calcNode, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		// "**" is power, not multiplication
+		if strings.HasPrefix(p.input[p.pos:], "**") {
+			return left, nil
+		}
+		op := p.peekOperator("*", "/", "%")
+		if op == "" {
+			return left, nil
+		}
+		p.pos += len(op)
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = calcBinary{op: op, left: left, right: right}
+	}
+}
+
+func (p *calcParser) unary() (calcNode, error) {
+	p.skipSpace()
+	if op := p.peekOperator("+", "-"); op != "" {
+		p.pos++
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return calcUnary{op: op[0], operand: operand}, nil
+	}
+	return p.power()
+}
+
+func (p *calcParser) power() (calcNode, error) {
+	base, err := p.atom()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.consume("**") || p.consume("^") {
+		exponent, err := p.unary()
+		if err != nil {
+			return nil, err
 		}
+		return calcBinary{op: "^", left: base, right: exponent}, nil
+	}
+	return base, nil
+}
+
+func (p *calcParser) atom() (calcNode, error) {
+	p.skipSpace()
+	if p.done() {
+		return nil, p.errorf("unexpected end of expression")
 	}
 
-	// Handle parentheses
-	if strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") {
-		return t.evaluateSimple(expr[1 : len(expr)-1])
+	if p.consume("(") {
+		inner, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.consume(")") {
+			return nil, p.errorf("missing closing parenthesis")
+		}
+		return inner, nil
 	}
 
-	// Handle basic functions
-	functions := map[string]func(float64) float64{
-		"sqrt": math.Sqrt,
-		"sin":  math.Sin,
-		"cos":  math.Cos,
-		"tan":  math.Tan,
-		"log":  math.Log10,
-		"ln":   math.Log,
-		"abs":  math.Abs,
+	c := rune(p.input[p.pos])
+	if unicode.IsDigit(c) || c == '.' {
+		return p.number()
 	}
 
-	for fname, fn := range functions {
-		if strings.HasPrefix(expr, fname+"(") && strings.HasSuffix(expr, ")") {
-			inner := expr[len(fname)+1 : len(expr)-1]
-			val, err := t.evaluateSimple(inner)
+	name := p.name()
+	if name == "" {
+		return nil, p.errorf("unexpected %q", string(c))
+	}
+	p.skipSpace()
+	if !p.consume("(") {
+		if _, ok := calcFunctions[name]; ok {
+			return nil, fmt.Errorf("function %q must be called with parentheses", name)
+		}
+		return calcName{name: name}, nil
+	}
+
+	fn, ok := calcFunctions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q", name)
+	}
+	var args []calcNode
+	p.skipSpace()
+	if !p.consume(")") {
+		for {
+			arg, err := p.expression()
 			if err != nil {
-				return 0, err
+				return nil, err
+			}
+			args = append(args, arg)
+			p.skipSpace()
+			if p.consume(")") {
+				break
+			}
+			if !p.consume(",") {
+				return nil, p.errorf("expected \",\" or \")\" in call to %s", name)
+			}
+		}
+	}
+	if len(args) != fn.arity {
+		return nil, fmt.Errorf("%s expects %d argument(s), got %d", name, fn.arity, len(args))
+	}
+	return calcCall{name: name, args: args}, nil
+}
+
+func (p *calcParser) number() (calcNode, error) {
+	start := p.pos
+	for !p.done() && (unicode.IsDigit(rune(p.input[p.pos])) || p.input[p.pos] == '.') {
+		p.pos++
+	}
+	// Optional exponent, e.g. 1.5e-3
+	if !p.done() && (p.input[p.pos] == 'e' || p.input[p.pos] == 'E') {
+		end := p.pos + 1
+		if end < len(p.input) && (p.input[end] == '+' || p.input[end] == '-') {
+			end++
+		}
+		if end < len(p.input) && unicode.IsDigit(rune(p.input[end])) {
+			p.pos = end
+			for !p.done() && unicode.IsDigit(rune(p.input[p.pos])) {
+				p.pos++
 			}
-			return fn(val), nil
 		}
 	}
+	value, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q", p.input[start:p.pos])
+	}
+	return calcNumber{value: value}, nil
+}
 
-	// Handle constants
-	constants := map[string]float64{
-		"pi": math.Pi,
-		"e":  math.E,
-		"PI": math.Pi,
-		"E":  math.E,
+// name reads an identifier. Names are case-insensitive, so PI and pi are the
+// same constant.
+func (p *calcParser) name() string {
+	p.skipSpace()
+	start := p.pos
+	for !p.done() {
+		c := rune(p.input[p.pos])
+		if !(unicode.IsLetter(c) || c == '_' || (p.pos > start && unicode.IsDigit(c))) {
+			break
+		}
+		p.pos++
 	}
+	return strings.ToLower(p.input[start:p.pos])
+}
 
-	if val, ok := constants[expr]; ok {
-		return val, nil
+func (p *calcParser) peekOperator(ops ...string) string {
+	for _, op := range ops {
+		if strings.HasPrefix(p.input[p.pos:], op) {
+			return op
+		}
 	}
+	return ""
+}
+
+func (p *calcParser) consume(token string) bool {
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *calcParser) skipSpace() {
+	for !p.done() && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *calcParser) done() bool {
+	return p.pos >= len(p.input)
+}
 
-	return 0, fmt.Errorf("unable to evaluate expression: %s", expr)
+func (p *calcParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s at position %d", fmt.Sprintf(format, args...), p.pos+1)
 }
@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func runCalculate(t *testing.T, input string) (string, error) {
+	t.Helper()
+	params, err := json.Marshal(map[string]string{"input": input})
+	if err != nil {
+		t.Fatalf("marshal params: %v", err)
+	}
+	return NewCalculateTool().Execute(context.Background(), params)
+}
+
+func TestCalculateTool_Precedence(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"2+3*4", "2 + 3 * 4 = 14"},
+		{"(2+3)*4", "(2 + 3) * 4 = 20"},
+		{"10 - 4 - 3", "10 - 4 - 3 = 3"},
+		{"10 - (4 - 3)", "10 - (4 - 3) = 9"},
+		{"2^3^2", "2 ^ 3 ^ 2 = 512"},
+		{"2**10", "2 ^ 10 = 1024"},
+		{"-2^2", "-2 ^ 2 = -4"},
+		{"(-2)^2", "(-2) ^ 2 = 4"},
+		{"2^-1", "2 ^ -1 = 0.5"},
+		{"7 % 3 * 2", "7 % 3 * 2 = 2"},
+		{"1.5e3 / 3", "1500 / 3 = 500"},
+		{"42", "42"},
+	}
+	for _, tc := range tests {
+		got, err := runCalculate(t, tc.input)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tc.input, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s: expected %q, got %q", tc.input, tc.want, got)
+		}
+	}
+}
+
+func TestCalculateTool_FunctionsAndConstants(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"sqrt(144) + 2^10", "sqrt(144) + 2 ^ 10 = 1036"},
+		{"pow(2, 8)", "pow(2, 8) = 256"},
+		{"abs(-3.5)", "abs(-3.5) = 3.5"},
+		{"log(1000)", "log(1000) = 3"},
+		{"cos(0) + sin(0)", "cos(0) + sin(0) = 1"},
+		{"round(PI * 100) / 100", "round(pi * 100) / 100 = 3.14"},
+		{"ln(e)", "ln(e) = 1"},
+	}
+	for _, tc := range tests {
+		got, err := runCalculate(t, tc.input)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tc.input, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s: expected %q, got %q", tc.input, tc.want, got)
+		}
+	}
+}
+
+func TestCalculateTool_Variables(t *testing.T) {
+	got, err := runCalculate(t, "x = 3; y = x + 1; x*y")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := "Expression: x = 3; y = x + 1; x * y\nResult: 12"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestCalculateTool_Errors(t *testing.T) {
+	tests := []struct {
+		input  string
+		code   string
+		detail string
+	}{
+		{"1 / 0", "EVALUATION_ERROR", "division by zero"},
+		{"x = 0; 5 / x", "EVALUATION_ERROR", "division by zero"},
+		{"5 % 0", "EVALUATION_ERROR", "modulo by zero"},
+		{"sqrt(-1)", "EVALUATION_ERROR", "not a real number"},
+		{"y + 1", "EVALUATION_ERROR", `undefined variable "y"`},
+		{"pi = 3", "PARSE_ERROR", "cannot assign to constant"},
+		{"system(1)", "PARSE_ERROR", `unknown function "system"`},
+		{"pow(2)", "PARSE_ERROR", "expects 2 argument(s)"},
+		{"(1 + 2", "PARSE_ERROR", "missing closing parenthesis"},
+		{"2 $ 3", "PARSE_ERROR", "unexpected"},
+	}
+	for _, tc := range tests {
+		_, err := runCalculate(t, tc.input)
+		toolErr, ok := err.(*ToolError)
+		if !ok {
+			t.Fatalf("%s: expected *ToolError, got %T (%v)", tc.input, err, err)
+		}
+		if toolErr.Code != tc.code {
+			t.Fatalf("%s: expected %s, got %s", tc.input, tc.code, toolErr.Code)
+		}
+		if msg, _ := toolErr.Details["error"].(string); !strings.Contains(msg, tc.detail) {
+			t.Fatalf("%s: expected error detail containing %q, got %q", tc.input, tc.detail, msg)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// ChatClient is the model a tool may call for help, such as summarize asking
+// it to condense text.
+type ChatClient struct {
+	Client llm.Client
+	Model  string
+}
+
+type chatClientKey struct{}
+
+// WithChatClient returns a context that carries the agent's client and model
+// for tools to use.
+func WithChatClient(ctx context.Context, client llm.Client, model string) context.Context {
+	return context.WithValue(ctx, chatClientKey{}, ChatClient{Client: client, Model: model})
+}
+
+// ChatClientFromContext returns the client attached to ctx, if any.
+func ChatClientFromContext(ctx context.Context) (ChatClient, bool) {
+	cc, ok := ctx.Value(chatClientKey{}).(ChatClient)
+	return cc, ok && cc.Client != nil
+}
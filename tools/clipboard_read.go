@@ -0,0 +1,147 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/nachoal/simple-agent-go/config"
+	"github.com/nachoal/simple-agent-go/tools/base"
+)
+
+type ClipboardReadParams struct{}
+
+// ClipboardReadTool returns the text on the system clipboard using the
+// platform's clipboard command. Clipboards often hold passwords and other
+// private text, so it only runs when enabled in the config or environment.
+type ClipboardReadTool struct {
+	base.BaseTool
+	enabled bool
+
+	// These are fields so tests can simulate other platforms and tools.
+	goos     string
+	lookPath func(file string) (string, error)
+	getenv   func(key string) string
+	run      func(ctx context.Context, name string, args ...string) (stdout, stderr []byte, err error)
+}
+
+// clipboardTextCommand is the command that prints the clipboard's text
+type clipboardTextCommand struct {
+	name string
+	args []string
+}
+
+// NewClipboardReadTool creates a clipboard_read tool, enabled by
+// "clipboard": {"allow_read": true} in the config file or by
+// SIMPLE_AGENT_CLIPBOARD_READ
+func NewClipboardReadTool() Tool {
+	return &ClipboardReadTool{
+		BaseTool: base.BaseTool{
+			ToolName: "clipboard_read",
+			ToolDesc: "Return the text currently on the user's clipboard. Use it when the user says they copied something. Takes no parameters. Example: {}",
+		},
+		enabled:  clipboardReadEnabled(),
+		goos:     runtime.GOOS,
+		lookPath: exec.LookPath,
+		getenv:   os.Getenv,
+		run:      runClipboardCommand,
+	}
+}
+
+// Parameters returns the parameters struct
+func (t *ClipboardReadTool) Parameters() interface{} {
+	return &ClipboardReadParams{}
+}
+
+// Available reports whether reading the clipboard has been allowed
+func (t *ClipboardReadTool) Available() bool {
+	return t.enabled
+}
+
+// Execute returns the clipboard's text
+func (t *ClipboardReadTool) Execute(ctx context.Context, params json.RawMessage) (string, error) {
+	if !t.enabled {
+		return "", NewToolError("NOT_CONFIGURED", "Reading the clipboard is disabled").
+			WithDetail("help", `Set "clipboard": {"allow_read": true} in ~/.simple-agent/config.json or SIMPLE_AGENT_CLIPBOARD_READ=1`)
+	}
+
+	cmd, err := t.command()
+	if err != nil {
+		return "", err
+	}
+	out, stderr, err := t.run(ctx, cmd.name, cmd.args...)
+	if err != nil {
+		detail := strings.TrimSpace(string(stderr))
+		if detail == "" {
+			detail = err.Error()
+		}
+		return "", NewToolError("EXECUTION_ERROR", fmt.Sprintf("%s failed", cmd.name)).
+			WithDetail("error", detail)
+	}
+
+	text := strings.ReplaceAll(string(out), "\r\n", "\n")
+	if strings.TrimSpace(text) == "" {
+		return "The clipboard has no text.", nil
+	}
+	return text, nil
+}
+
+// command picks the clipboard command for the platform. On Linux, Wayland
+// sessions prefer wl-paste and X11 sessions xclip, then xsel; whichever is
+// installed is used.
+func (t *ClipboardReadTool) command() (clipboardTextCommand, error) {
+	var candidates []clipboardTextCommand
+	var install string
+	switch t.goos {
+	case "darwin":
+		candidates = []clipboardTextCommand{{name: "pbpaste"}}
+		install = "pbpaste not found"
+	case "linux":
+		wlPaste := clipboardTextCommand{name: "wl-paste", args: []string{"--no-newline"}}
+		xclip := clipboardTextCommand{name: "xclip", args: []string{"-selection", "clipboard", "-o"}}
+		xsel := clipboardTextCommand{name: "xsel", args: []string{"--clipboard", "--output"}}
+		candidates = []clipboardTextCommand{xclip, xsel, wlPaste}
+		if t.getenv("WAYLAND_DISPLAY") != "" || strings.EqualFold(t.getenv("XDG_SESSION_TYPE"), "wayland") {
+			candidates = []clipboardTextCommand{wlPaste, xclip, xsel}
+		}
+		install = "No clipboard command found. Install xclip, xsel, or wl-clipboard with your package manager"
+	case "windows":
+		args := []string{"-NoProfile", "-NonInteractive", "-Command", "Get-Clipboard -Raw"}
+		candidates = []clipboardTextCommand{{name: "powershell", args: args}, {name: "pwsh", args: args}}
+		install = "PowerShell not found. Install Windows PowerShell or PowerShell 7 to read the clipboard"
+	default:
+		return clipboardTextCommand{}, NewToolError("NOT_AVAILABLE", fmt.Sprintf("Reading the clipboard is not supported on %s", t.goos))
+	}
+	for _, candidate := range candidates {
+		if _, err := t.lookPath(candidate.name); err == nil {
+			return candidate, nil
+		}
+	}
+	return clipboardTextCommand{}, NewToolError("NOT_AVAILABLE", install)
+}
+
+func runClipboardCommand(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	return out, []byte(stderr.String()), err
+}
+
+// clipboardReadEnabled reports whether the config file or
+// SIMPLE_AGENT_CLIPBOARD_READ allows reading the clipboard
+func clipboardReadEnabled() bool {
+	if envEnabled("SIMPLE_AGENT_CLIPBOARD_READ") {
+		return true
+	}
+	path, err := config.Path()
+	if err != nil {
+		return false
+	}
+	cfg, err := config.LoadFile(path)
+	return err == nil && cfg.Clipboard != nil && cfg.Clipboard.AllowRead
+}
@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeClipboardTool simulates goos with the given commands installed and
+// records what it runs
+func fakeClipboardTool(goos string, env map[string]string, installed ...string) (*ClipboardReadTool, *[]string) {
+	var ran []string
+	tool := NewClipboardReadTool().(*ClipboardReadTool)
+	tool.enabled = true
+	tool.goos = goos
+	tool.getenv = func(key string) string { return env[key] }
+	tool.lookPath = func(file string) (string, error) {
+		for _, name := range installed {
+			if name == file {
+				return "/usr/bin/" + file, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+	tool.run = func(_ context.Context, name string, args ...string) ([]byte, []byte, error) {
+		ran = append([]string{name}, args...)
+		return []byte("copied text\r\n"), nil, nil
+	}
+	return tool, &ran
+}
+
+func TestClipboardReadCommandPerPlatform(t *testing.T) {
+	wayland := map[string]string{"WAYLAND_DISPLAY": "wayland-0"}
+	cases := []struct {
+		name      string
+		goos      string
+		env       map[string]string
+		installed []string
+		want      []string
+	}{
+		{"macOS", "darwin", nil, []string{"pbpaste"}, []string{"pbpaste"}},
+		{"X11 xclip", "linux", nil, []string{"xclip", "wl-paste"}, []string{"xclip", "-selection", "clipboard", "-o"}},
+		{"X11 xsel", "linux", nil, []string{"xsel"}, []string{"xsel", "--clipboard", "--output"}},
+		{"Wayland", "linux", wayland, []string{"xclip", "wl-paste"}, []string{"wl-paste", "--no-newline"}},
+		{"Wayland without wl-paste", "linux", wayland, []string{"xclip"}, []string{"xclip", "-selection", "clipboard", "-o"}},
+		{"Windows", "windows", nil, []string{"pwsh"}, []string{"pwsh", "-NoProfile", "-NonInteractive", "-Command", "Get-Clipboard -Raw"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tool, ran := fakeClipboardTool(tc.goos, tc.env, tc.installed...)
+			out, err := tool.Execute(context.Background(), json.RawMessage(`{}`))
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if out != "copied text\n" {
+				t.Fatalf("unexpected output %q", out)
+			}
+			if !reflect.DeepEqual(*ran, tc.want) {
+				t.Fatalf("ran %v, want %v", *ran, tc.want)
+			}
+		})
+	}
+}
+
+func TestClipboardReadNotAvailable(t *testing.T) {
+	tool, ran := fakeClipboardTool("linux", nil)
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{}`))
+	if err == nil || !strings.Contains(err.Error(), "Install xclip, xsel, or wl-clipboard") {
+		t.Fatalf("expected an install hint, got %v", err)
+	}
+	if len(*ran) != 0 {
+		t.Fatalf("expected nothing to run, ran %v", *ran)
+	}
+
+	tool, _ = fakeClipboardTool("plan9", nil)
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{}`)); err == nil || !strings.Contains(err.Error(), "not supported on plan9") {
+		t.Fatalf("expected an unsupported platform error, got %v", err)
+	}
+}
+
+func TestClipboardReadDisabledByDefault(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SIMPLE_AGENT_CLIPBOARD_READ", "")
+	tool := NewClipboardReadTool().(*ClipboardReadTool)
+	if IsAvailable(tool) {
+		t.Fatal("expected clipboard_read to be unavailable without opting in")
+	}
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{}`)); err == nil || !strings.Contains(err.Error(), "disabled") {
+		t.Fatalf("expected a disabled error, got %v", err)
+	}
+
+	t.Setenv("SIMPLE_AGENT_CLIPBOARD_READ", "1")
+	if !IsAvailable(NewClipboardReadTool()) {
+		t.Fatal("expected SIMPLE_AGENT_CLIPBOARD_READ to enable clipboard_read")
+	}
+}
@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"context"
+	"errors"
+)
+
+// ConfirmationRequired is the ToolError code a tool returns when it will
+// only act after the user approves. The error's "approval_token" detail is
+// what the caller attaches with WithApproval when re-invoking the tool.
+const ConfirmationRequired = "CONFIRMATION_REQUIRED"
+
+// Confirmer asks the user whether a tool call that returned a
+// ConfirmationRequired error may go ahead. It blocks until the user answers
+// or ctx is done, and reports whether the call was approved.
+type Confirmer func(ctx context.Context, call ToolCall, request *ToolError) bool
+
+type confirmerKey struct{}
+
+type approvalKey struct{}
+
+// WithConfirmer returns a context that carries confirm, so tool executors can
+// ask the user instead of failing calls that need approval.
+func WithConfirmer(ctx context.Context, confirm Confirmer) context.Context {
+	return context.WithValue(ctx, confirmerKey{}, confirm)
+}
+
+// ConfirmerFromContext returns the confirmer attached to ctx, if any.
+func ConfirmerFromContext(ctx context.Context) (Confirmer, bool) {
+	confirm, ok := ctx.Value(confirmerKey{}).(Confirmer)
+	return confirm, ok && confirm != nil
+}
+
+// WithApproval returns a context in which the action identified by token has
+// been approved by the user.
+func WithApproval(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, approvalKey{}, token)
+}
+
+// Approved reports whether ctx carries the user's approval for token.
+func Approved(ctx context.Context, token string) bool {
+	approved, ok := ctx.Value(approvalKey{}).(string)
+	return ok && token != "" && approved == token
+}
+
+// ConfirmationRequest returns the ConfirmationRequired error wrapped in err
+// and its approval token. ok is false when err does not ask for approval.
+func ConfirmationRequest(err error) (request *ToolError, token string, ok bool) {
+	if !errors.As(err, &request) || request.Code != ConfirmationRequired {
+		return nil, "", false
+	}
+	token, _ = request.Details["approval_token"].(string)
+	return request, token, token != ""
+}
@@ -0,0 +1,335 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nachoal/simple-agent-go/tools/base"
+)
+
+const (
+	defaultDiffContextLines = 3
+	maxDiffContextLines     = 50
+	maxDiffInputBytes       = 2 * 1024 * 1024
+)
+
+type DiffParams struct {
+	Mode         string `json:"mode" schema:"required" description:"What a and b contain: files (paths) or strings (raw text)"`
+	A            string `json:"a" schema:"required" description:"Original file path or text"`
+	B            string `json:"b" schema:"required" description:"Changed file path or text"`
+	ContextLines int    `json:"context_lines,omitempty" description:"Unchanged lines shown around each change (optional, default 3)"`
+}
+
+// DiffTool produces a unified diff between two files or two text blocks.
+type DiffTool struct {
+	base.BaseTool
+	fileScope
+}
+
+// diffLine is one line of a line-level diff: ' ' unchanged, '-' removed, '+' added.
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// Parameters returns the parameters struct
+func (t *DiffTool) Parameters() interface{} {
+	return &DiffParams{}
+}
+
+// Execute compares a and b and returns a unified diff with a summary line.
+func (t *DiffTool) Execute(ctx context.Context, params json.RawMessage) (string, error) {
+	var args DiffParams
+	if err := json.Unmarshal(params, &args); err != nil {
+		return "", NewToolError("INVALID_PARAMS", "Failed to parse parameters").
+			WithDetail("error", err.Error())
+	}
+
+	contextLines := args.ContextLines
+	if contextLines <= 0 {
+		contextLines = defaultDiffContextLines
+	}
+	if contextLines > maxDiffContextLines {
+		contextLines = maxDiffContextLines
+	}
+
+	var textA, textB, labelA, labelB string
+	switch strings.ToLower(strings.TrimSpace(args.Mode)) {
+	case "files":
+		var err error
+		if textA, labelA, err = t.readFile(args.A); err != nil {
+			return "", err
+		}
+		if textB, labelB, err = t.readFile(args.B); err != nil {
+			return "", err
+		}
+	case "strings":
+		textA, textB, labelA, labelB = args.A, args.B, "a", "b"
+		if len(textA) > maxDiffInputBytes || len(textB) > maxDiffInputBytes {
+			return "", NewToolError("INPUT_TOO_LARGE", fmt.Sprintf("Inputs larger than %d bytes are not supported", maxDiffInputBytes))
+		}
+	default:
+		return "", NewToolError("VALIDATION_FAILED", "Mode must be files or strings").
+			WithDetail("mode", args.Mode)
+	}
+
+	lines := diffLines(splitDiffLines(textA), splitDiffLines(textB))
+	added, removed, unchanged := 0, 0, 0
+	for _, line := range lines {
+		switch line.op {
+		case '+':
+			added++
+		case '-':
+			removed++
+		default:
+			unchanged++
+		}
+	}
+
+	summary := fmt.Sprintf("%d added, %d removed, %d unchanged", added, removed, unchanged)
+	if added == 0 && removed == 0 {
+		return fmt.Sprintf("No differences (%s).", summary), nil
+	}
+
+	var b strings.Builder
+	b.WriteString(summary + "\n\n```diff\n")
+	b.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", labelA, labelB))
+	b.WriteString(unifiedHunks(lines, contextLines))
+	b.WriteString("```")
+	return b.String(), nil
+}
+
+func (t *DiffTool) readFile(path string) (string, string, error) {
+	if strings.TrimSpace(path) == "" {
+		return "", "", NewToolError("VALIDATION_FAILED", "File path is required")
+	}
+	resolved, workspace, err := t.resolve(path)
+	if err != nil {
+		return "", "", err
+	}
+	displayPath := displayPathForWorkspace(resolved, workspace)
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", "", NewToolError("FILE_NOT_FOUND", "File not found").
+				WithDetail("path", displayPath)
+		}
+		return "", "", NewToolError("READ_ERROR", "Failed to access file").
+			WithDetail("path", displayPath).
+			WithDetail("error", err.Error())
+	}
+	if info.IsDir() {
+		return "", "", NewToolError("VALIDATION_FAILED", "Path is a directory").
+			WithDetail("path", displayPath)
+	}
+	if info.Size() > maxDiffInputBytes {
+		return "", "", NewToolError("FILE_TOO_LARGE", fmt.Sprintf("Files larger than %d bytes are not supported", maxDiffInputBytes)).
+			WithDetail("path", displayPath)
+	}
+
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return "", "", NewToolError("READ_ERROR", "Failed to read file").
+			WithDetail("path", displayPath).
+			WithDetail("error", err.Error())
+	}
+	return string(data), displayPath, nil
+}
+
+func splitDiffLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines computes a shortest edit script between a and b with Myers'
+// algorithm and returns it as a sequence of unchanged, removed, and added
+// lines. Common leading and trailing lines are matched up front; when the
+// remaining edit distance exceeds maxDiffEditDistance, the middle is reported
+// as removed then added instead.
+func diffLines(a, b []string) []diffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]diffLine, 0, len(a)+len(b))
+	for _, text := range a[:prefix] {
+		lines = append(lines, diffLine{op: ' ', text: text})
+	}
+	lines = append(lines, myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, text := range a[len(a)-suffix:] {
+		lines = append(lines, diffLine{op: ' ', text: text})
+	}
+	return lines
+}
+
+const maxDiffEditDistance = 4000
+
+func myersDiff(a, b []string) []diffLine {
+	n, m := len(a), len(b)
+	maxD := n + m
+	if maxD > maxDiffEditDistance {
+		maxD = maxDiffEditDistance
+	}
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	// trace[d] holds v[-d..d] as it was before step d.
+	var trace [][]int
+	found := false
+
+search:
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break search
+			}
+		}
+	}
+
+	if !found {
+		lines := make([]diffLine, 0, n+m)
+		for _, text := range a {
+			lines = append(lines, diffLine{op: '-', text: text})
+		}
+		for _, text := range b {
+			lines = append(lines, diffLine{op: '+', text: text})
+		}
+		return lines
+	}
+
+	var reversed []diffLine
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := func(k int) int { return trace[d][k+d] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && prev(k-1) < prev(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := prev(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			reversed = append(reversed, diffLine{op: ' ', text: a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			reversed = append(reversed, diffLine{op: '+', text: b[y-1]})
+			y--
+		} else {
+			reversed = append(reversed, diffLine{op: '-', text: a[x-1]})
+			x--
+		}
+	}
+	for x > 0 && y > 0 {
+		reversed = append(reversed, diffLine{op: ' ', text: a[x-1]})
+		x--
+		y--
+	}
+
+	lines := make([]diffLine, len(reversed))
+	for i, line := range reversed {
+		lines[len(reversed)-1-i] = line
+	}
+	return lines
+}
+
+// unifiedHunks renders changed regions with up to contextLines unchanged
+// lines around them, merging regions whose context would overlap.
+func unifiedHunks(lines []diffLine, contextLines int) string {
+	var b strings.Builder
+	start := 0
+	for start < len(lines) {
+		for start < len(lines) && lines[start].op == ' ' {
+			start++
+		}
+		if start == len(lines) {
+			break
+		}
+
+		hunkStart := start - contextLines
+		if hunkStart < 0 {
+			hunkStart = 0
+		}
+		end := start
+		for end < len(lines) {
+			if lines[end].op != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(lines) && lines[run].op == ' ' {
+				run++
+			}
+			if run == len(lines) || run-end > 2*contextLines {
+				break
+			}
+			end = run
+		}
+		hunkEnd := end + contextLines
+		if hunkEnd > len(lines) {
+			hunkEnd = len(lines)
+		}
+
+		aStart, bStart := 1, 1
+		for _, line := range lines[:hunkStart] {
+			if line.op != '+' {
+				aStart++
+			}
+			if line.op != '-' {
+				bStart++
+			}
+		}
+		aLen, bLen := 0, 0
+		for _, line := range lines[hunkStart:hunkEnd] {
+			if line.op != '+' {
+				aLen++
+			}
+			if line.op != '-' {
+				bLen++
+			}
+		}
+		if aLen == 0 {
+			aStart--
+		}
+		if bLen == 0 {
+			bStart--
+		}
+
+		b.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", aStart, aLen, bStart, bLen))
+		for _, line := range lines[hunkStart:hunkEnd] {
+			b.WriteByte(line.op)
+			b.WriteString(line.text)
+			b.WriteByte('\n')
+		}
+		start = hunkEnd
+	}
+	return b.String()
+}
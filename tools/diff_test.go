@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runDiffTool(t *testing.T, args map[string]interface{}) (string, error) {
+	t.Helper()
+	raw, err := json.Marshal(args)
+	if err != nil {
+		t.Fatalf("marshal args: %v", err)
+	}
+	return NewDiffTool().Execute(context.Background(), raw)
+}
+
+func TestDiffTool_IdenticalContent(t *testing.T) {
+	out, err := runDiffTool(t, map[string]interface{}{"mode": "strings", "a": "one\ntwo\n", "b": "one\ntwo\n"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "No differences (0 added, 0 removed, 2 unchanged)." {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+func TestDiffTool_CompletelyDifferent(t *testing.T) {
+	out, err := runDiffTool(t, map[string]interface{}{"mode": "strings", "a": "alpha\nbeta", "b": "gamma"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "1 added, 2 removed, 0 unchanged\n\n```diff\n--- a\n+++ b\n@@ -1,2 +1,1 @@\n-alpha\n-beta\n+gamma\n```"
+	if out != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestDiffTool_PartialOverlapSplitsHunks(t *testing.T) {
+	var a, b []string
+	for i := 1; i <= 20; i++ {
+		line := "line " + string(rune('a'+i-1))
+		a = append(a, line)
+		switch i {
+		case 3:
+			b = append(b, "changed c")
+		case 18:
+			b = append(b, line, "inserted")
+		default:
+			b = append(b, line)
+		}
+	}
+
+	out, err := runDiffTool(t, map[string]interface{}{"mode": "strings", "a": strings.Join(a, "\n"), "b": strings.Join(b, "\n"), "context_lines": 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"2 added, 1 removed, 19 unchanged",
+		"@@ -1,5 +1,5 @@\n line a\n line b\n-line c\n+changed c\n line d\n line e\n",
+		"@@ -17,4 +17,5 @@\n line q\n line r\n+inserted\n line s\n line t\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestDiffTool_FilesModeReportsMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	withWorkingDir(t, dir)
+	if err := os.WriteFile(filepath.Join(dir, "old.txt"), []byte("x\ny\n"), 0644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("x\nz\n"), 0644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	out, err := runDiffTool(t, map[string]interface{}{"mode": "files", "a": "old.txt", "b": "new.txt"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "--- old.txt\n+++ new.txt\n") || !strings.Contains(out, "-y\n+z\n") {
+		t.Fatalf("unexpected file diff:\n%s", out)
+	}
+
+	_, err = runDiffTool(t, map[string]interface{}{"mode": "files", "a": "old.txt", "b": "missing.txt"})
+	te, ok := err.(*ToolError)
+	if !ok || te.Code != "FILE_NOT_FOUND" || te.Details["path"] != "missing.txt" {
+		t.Fatalf("expected missing file error naming missing.txt, got %v", err)
+	}
+}
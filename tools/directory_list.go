@@ -18,6 +18,7 @@ type DirectoryListParams = base.GenericParams
 // DirectoryListTool lists directory contents
 type DirectoryListTool struct {
 	base.BaseTool
+	fileScope
 }
 
 // Parameters returns the parameters struct
@@ -54,7 +55,7 @@ func (t *DirectoryListTool) Execute(ctx context.Context, params json.RawMessage)
 		path = "."
 	}
 
-	resolvedPath, workspace, err := resolveWorkspacePath(path)
+	resolvedPath, workspace, err := t.resolve(path)
 	if err != nil {
 		return "", err
 	}
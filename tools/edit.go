@@ -20,6 +20,7 @@ type EditParams struct {
 // EditTool edits files by replacing text.
 type EditTool struct {
 	base.BaseTool
+	fileScope
 }
 
 // Parameters returns the parameters struct
@@ -45,7 +46,7 @@ func (t *EditTool) Execute(ctx context.Context, params json.RawMessage) (string,
 		return "", NewToolError("VALIDATION_FAILED", "oldText and newText must be different")
 	}
 
-	resolvedPath, workspace, err := resolveWorkspacePath(args.Path)
+	resolvedPath, workspace, err := t.resolve(args.Path)
 	if err != nil {
 		return "", err
 	}
@@ -68,7 +69,7 @@ func (t *EditTool) Execute(ctx context.Context, params json.RawMessage) (string,
 		}
 
 		// Write new file
-		if err := os.WriteFile(resolvedPath, []byte(args.NewText), 0644); err != nil {
+		if err := writeFileNoFollow(resolvedPath, []byte(args.NewText), 0644); err != nil {
 			return "", NewToolError("WRITE_ERROR", "Failed to create file").
 				WithDetail("error", err.Error()).
 				WithDetail("path", displayPath)
@@ -110,7 +111,7 @@ func (t *EditTool) Execute(ctx context.Context, params json.RawMessage) (string,
 	newContent := strings.Replace(fileContent, args.OldText, args.NewText, 1)
 
 	// Write the updated content
-	if err := os.WriteFile(resolvedPath, []byte(newContent), 0644); err != nil {
+	if err := writeFileNoFollow(resolvedPath, []byte(newContent), 0644); err != nil {
 		return "", NewToolError("WRITE_ERROR", "Failed to write file").
 			WithDetail("error", err.Error()).
 			WithDetail("path", displayPath)
@@ -6,49 +6,61 @@ import (
 	"strings"
 	"time"
 
+	"github.com/nachoal/simple-agent-go/config"
 	"github.com/nachoal/simple-agent-go/tools/base"
 )
 
 // Export tool constructors to avoid import cycles
 // These are implemented in their respective files but exported here
 
-// NewReadTool creates a new read tool.
-func NewReadTool() Tool {
+// NewReadTool creates a new read tool. Like the other file tools, it is
+// confined to the current working directory unless WithRoot says otherwise.
+func NewReadTool(opts ...FileToolOption) Tool {
+	scope := newFileScope(opts)
 	return &ReadTool{
 		BaseTool: base.BaseTool{
 			ToolName: "read",
-			ToolDesc: "Read the contents of a file within the current working directory. Supports optional offset/limit for large files. Example: {\"path\":\"file.txt\",\"offset\":1,\"limit\":200}",
+			ToolDesc: scope.describe("Read the contents of a file within the current working directory. Supports optional offset/limit for large files. Example: {\"path\":\"file.txt\",\"offset\":1,\"limit\":200}"),
+			// Room for a full page plus the note on how to continue
+			MaxOutputBytes: defaultReadMaxBytes + 4*1024,
 		},
+		fileScope: scope,
 	}
 }
 
 // NewWriteTool creates a new write tool.
-func NewWriteTool() Tool {
+func NewWriteTool(opts ...FileToolOption) Tool {
+	scope := newFileScope(opts)
 	return &WriteTool{
 		BaseTool: base.BaseTool{
 			ToolName: "write",
-			ToolDesc: "Create or overwrite a file within the current working directory. Creates parent directories. Example: {\"path\":\"file.txt\",\"content\":\"hello\"}",
+			ToolDesc: scope.describe("Create or overwrite a file within the current working directory. Creates parent directories. Example: {\"path\":\"file.txt\",\"content\":\"hello\"}"),
 		},
+		fileScope: scope,
 	}
 }
 
 // NewEditTool creates a new edit tool.
-func NewEditTool() Tool {
+func NewEditTool(opts ...FileToolOption) Tool {
+	scope := newFileScope(opts)
 	return &EditTool{
 		BaseTool: base.BaseTool{
 			ToolName: "edit",
-			ToolDesc: "Edit a file within the current working directory by replacing exact oldText with newText (must be unique). Example: {\"path\":\"file.txt\",\"oldText\":\"old\",\"newText\":\"new\"}",
+			ToolDesc: scope.describe("Edit a file within the current working directory by replacing exact oldText with newText (must be unique). Example: {\"path\":\"file.txt\",\"oldText\":\"old\",\"newText\":\"new\"}"),
 		},
+		fileScope: scope,
 	}
 }
 
 // NewDirectoryListTool creates a new directory list tool
-func NewDirectoryListTool() Tool {
+func NewDirectoryListTool(opts ...FileToolOption) Tool {
+	scope := newFileScope(opts)
 	return &DirectoryListTool{
 		BaseTool: base.BaseTool{
 			ToolName: "directory_list",
-			ToolDesc: "List files and directories within the current working directory. Input must be JSON with optional 'path' field. Example: {\"path\": \"directory\"} or {} for current directory.",
+			ToolDesc: scope.describe("List files and directories within the current working directory. Input must be JSON with optional 'path' field. Example: {\"path\": \"directory\"} or {} for current directory."),
 		},
+		fileScope: scope,
 	}
 }
 
@@ -57,16 +69,18 @@ func NewCalculateTool() Tool {
 	return &CalculateTool{
 		BaseTool: base.BaseTool{
 			ToolName: "calculate",
-			ToolDesc: "Evaluates mathematical expressions with support for basic operators (+, -, *, /, %, **) and parentheses.",
+			ToolDesc: "Evaluates mathematical expressions with operators (+, -, *, /, %, ^ or **), parentheses, constants (pi, e), functions (sqrt, sin, cos, tan, log, ln, exp, abs, floor, ceil, round, pow), and variables assigned earlier in the same input. Example: {\"input\":\"r = 2; pi * r^2\"}",
 		},
 	}
 }
 
-// NewBashTool creates a new bash tool.
-func NewBashTool() Tool {
-	yolo := strings.EqualFold(os.Getenv("SIMPLE_AGENT_YOLO"), "true") ||
-		os.Getenv("SIMPLE_AGENT_YOLO") == "1" ||
-		strings.EqualFold(os.Getenv("SIMPLE_AGENT_YOLO"), "yes")
+// NewBashTool creates a new bash tool. The "shell" section of the config file
+// can replace the allowlist and enable confirmation mode, as can
+// SIMPLE_AGENT_BASH_CONFIRM; SIMPLE_AGENT_DOCKER_IMAGE enables the Docker
+// sandbox. Options are applied after these.
+func NewBashTool(opts ...BashOption) Tool {
+	yolo := yoloEnabled()
+	shell := shellConfig()
 
 	// Default allowed commands for safety
 	allowedCommands := []string{
@@ -82,13 +96,76 @@ func NewBashTool() Tool {
 		desc = "Execute bash commands in the current working directory safely with timeout and output capture. Example: {\"command\":\"ls -la\",\"timeout\":30}"
 	}
 
-	return &BashTool{
+	tool := &BashTool{
 		BaseTool: base.BaseTool{
 			ToolName: "bash",
 			ToolDesc: desc,
 		},
 		allowedCommands: allowedCommands,
 		allowAll:        yolo,
+		confirm:         shell.Confirm || envEnabled("SIMPLE_AGENT_BASH_CONFIRM"),
+		dockerImage:     strings.TrimSpace(os.Getenv("SIMPLE_AGENT_DOCKER_IMAGE")),
+	}
+	WithAllowedCommands(shell.AllowedCommands)(tool)
+	for _, opt := range opts {
+		opt(tool)
+	}
+	if tool.workdir != "" {
+		tool.ToolDesc = strings.Replace(tool.ToolDesc, "the current working directory", tool.workdir, 1)
+	}
+	if tool.confirm && !yolo {
+		tool.ToolDesc += " Commands outside the allowed list run only after the user approves them."
+	}
+	if tool.dockerImage != "" {
+		tool.ToolDesc += " Commands run in a " + tool.dockerImage + " Docker container with no network; only the working directory (mounted at /workspace) is writable."
+	}
+	return tool
+}
+
+// NewGitTool creates a new git tool.
+func NewGitTool(opts ...FileToolOption) Tool {
+	scope := newFileScope(opts)
+	return &GitTool{
+		BaseTool: base.BaseTool{
+			ToolName: "git",
+			ToolDesc: scope.describe("Run git operations (log, diff, status, add, commit, checkout, branch) in the repository containing the current working directory. Example: {\"operation\":\"log\",\"limit\":5} or {\"operation\":\"commit\",\"message\":\"Fix typo\"}"),
+		},
+		fileScope: scope,
+	}
+}
+
+// NewSQLiteTool creates a new SQLite query tool.
+func NewSQLiteTool(opts ...FileToolOption) Tool {
+	scope := newFileScope(opts)
+	return &SQLiteTool{
+		BaseTool: base.BaseTool{
+			ToolName: "sqlite_query",
+			ToolDesc: scope.describe("Query a SQLite database file within the current working directory and return rows as a markdown table. Reads run in a read-only transaction; INSERT/UPDATE/DELETE require write_mode. Example: {\"database_path\":\"app.db\",\"query\":\"SELECT * FROM users\",\"max_rows\":20}"),
+		},
+		fileScope: scope,
+	}
+}
+
+// NewDiffTool creates a new diff tool.
+func NewDiffTool(opts ...FileToolOption) Tool {
+	return &DiffTool{
+		BaseTool: base.BaseTool{
+			ToolName: "diff",
+			ToolDesc: "Compare two files or two text blocks and return a unified diff in a ```diff block with a summary of added, removed, and unchanged lines. Example: {\"mode\":\"files\",\"a\":\"old.txt\",\"b\":\"new.txt\"} or {\"mode\":\"strings\",\"a\":\"foo\\nbar\",\"b\":\"foo\\nbaz\",\"context_lines\":1}",
+		},
+		fileScope: newFileScope(opts),
+	}
+}
+
+// NewStructuredFileTool creates a new CSV/JSON summary tool.
+func NewStructuredFileTool(opts ...FileToolOption) Tool {
+	scope := newFileScope(opts)
+	return &StructuredFileTool{
+		BaseTool: base.BaseTool{
+			ToolName: "file_structured",
+			ToolDesc: scope.describe("Summarize a CSV or JSON file within the current working directory. CSV returns the header plus the first max_rows rows as a markdown table with a row count (BOM and tab delimiters handled); JSON returns its structure with long values truncated and large arrays summarized. Example: {\"file_path\":\"data.csv\",\"max_rows\":10,\"columns\":[\"name\",\"email\"]}"),
+		},
+		fileScope: scope,
 	}
 }
 
@@ -105,6 +182,19 @@ func NewWikipediaTool() Tool {
 	}
 }
 
+// NewArXivTool creates a new arXiv search tool
+func NewArXivTool() Tool {
+	return &ArXivTool{
+		BaseTool: base.BaseTool{
+			ToolName: "arxiv",
+			ToolDesc: "Search arXiv papers (title, authors, abstract preview, ID, PDF link, submission date) or fetch one paper's full abstract by ID. Example: {\"query\":\"diffusion models\",\"max_results\":5,\"sort_by\":\"submittedDate\"} or {\"operation\":\"get_paper\",\"id\":\"1706.03762\"}",
+		},
+		client: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
 // NewGoogleSearchTool creates a new Google search tool
 func NewGoogleSearchTool() Tool {
 	return &GoogleSearchTool{
@@ -119,3 +209,130 @@ func NewGoogleSearchTool() Tool {
 		searchEngineID: os.Getenv("GOOGLE_CX"),
 	}
 }
+
+// NewHTTPFetchTool creates a new HTTP fetch tool; see guardedHTTPClient for
+// the addresses it refuses.
+func NewHTTPFetchTool() Tool {
+	allowedHosts := envList("SIMPLE_AGENT_FETCH_ALLOW_HOSTS")
+
+	tool := &HTTPFetchTool{
+		BaseTool: base.BaseTool{
+			ToolName: "http_fetch",
+			ToolDesc: "Fetch a web page or API response with GET. HTML is converted to readable text, JSON is pretty-printed, and bodies are truncated to ~100KB. Example: {\"url\":\"https://go.dev/doc/\",\"timeout\":15}",
+			// The tool truncates bodies itself; leave room for its header
+			MaxOutputBytes: defaultHTTPFetchMaxBytes + 16*1024,
+		},
+		maxBytes:     defaultHTTPFetchMaxBytes,
+		allowedHosts: allowedHosts,
+	}
+	tool.client = guardedHTTPClient(maxHTTPFetchTimeoutSecs*time.Second, func(host string) bool {
+		return hostAllowed(host, tool.allowedHosts)
+	})
+	return tool
+}
+
+// NewWebFetchTool creates a new web page reading tool.
+func NewWebFetchTool() Tool {
+	tool := &WebFetchTool{
+		BaseTool: base.BaseTool{
+			ToolName: "web_fetch",
+			ToolDesc: "Read a web page: returns the title, canonical URL, and readable text (scripts, styles, and navigation removed, capped at 8000 characters). Honors robots.txt. Optional CSS selector narrows extraction. Example: {\"url\":\"https://go.dev/blog/\",\"selector\":\"article\"}",
+		},
+		allowedHosts: envList("SIMPLE_AGENT_FETCH_ALLOW_HOSTS"),
+	}
+	tool.client = guardedHTTPClient(webFetchTimeout, func(host string) bool {
+		return hostAllowed(host, tool.allowedHosts)
+	})
+	return tool
+}
+
+// NewSummarizeTool creates a tool that summarizes a long file or web page in
+// chunks using the agent's model. Files are confined like the other file
+// tools, and URLs are guarded like http_fetch.
+func NewSummarizeTool(opts ...FileToolOption) Tool {
+	scope := newFileScope(opts)
+	tool := &SummarizeTool{
+		BaseTool: base.BaseTool{
+			ToolName: "summarize",
+			ToolDesc: scope.describe("Summarize a long file within the current working directory or a web page: the text is split into chunks, each chunk is summarized, and the summaries are combined. Optional focus steers the summary. Example: {\"source\":\"docs/design.md\",\"focus\":\"open questions\"} or {\"source\":\"https://go.dev/doc/effective_go\"}"),
+		},
+		fileScope:    scope,
+		allowedHosts: envList("SIMPLE_AGENT_FETCH_ALLOW_HOSTS"),
+	}
+	tool.client = guardedHTTPClient(summarizeFetchTimeout, func(host string) bool {
+		return hostAllowed(host, tool.allowedHosts)
+	})
+	return tool
+}
+
+// NewFeedTool creates a new RSS/Atom feed reader.
+func NewFeedTool() Tool {
+	tool := &FeedTool{
+		BaseTool: base.BaseTool{
+			ToolName: "feed",
+			ToolDesc: "Fetch an RSS or Atom feed and list its latest entries with title, link, publish date, and a short summary. Example: {\"url\":\"https://go.dev/blog/feed.atom\",\"limit\":5}",
+		},
+		allowedHosts: envList("SIMPLE_AGENT_FETCH_ALLOW_HOSTS"),
+	}
+	tool.client = guardedHTTPClient(feedTimeoutSecs*time.Second, func(host string) bool {
+		return hostAllowed(host, tool.allowedHosts)
+	})
+	return tool
+}
+
+// NewHTTPRequestTool creates a new HTTP request tool. Domains are filtered by
+// SIMPLE_AGENT_HTTP_ALLOW_DOMAINS and SIMPLE_AGENT_HTTP_BLOCK_DOMAINS
+// (comma-separated); with no allowed domains every request is refused.
+// --yolo lifts the allowlist and the private address guard.
+func NewHTTPRequestTool() Tool {
+	tool := &HTTPRequestTool{
+		BaseTool: base.BaseTool{
+			ToolName: "http_request",
+			ToolDesc: "Make an HTTP request and return the final URL, status, headers, and body (HTML stripped to text, truncated at 50KB). Example: {\"method\":\"POST\",\"url\":\"https://httpbin.org/post\",\"headers\":{\"Content-Type\":\"application/json\"},\"body\":\"{}\",\"timeout_secs\":30}",
+			// The tool truncates bodies itself; leave room for status and headers
+			MaxOutputBytes: maxHTTPRequestBodyBytes + 16*1024,
+		},
+		allowedDomains: envList("SIMPLE_AGENT_HTTP_ALLOW_DOMAINS"),
+		blockedDomains: envList("SIMPLE_AGENT_HTTP_BLOCK_DOMAINS"),
+		allowAll:       yoloEnabled(),
+	}
+	tool.client = guardedHTTPClient(maxHTTPRequestTimeoutSecs*time.Second, func(host string) bool {
+		return tool.allowAll || matchesDomain(host, tool.allowedDomains)
+	})
+	tool.client.CheckRedirect = tool.checkRedirect
+	return tool
+}
+
+func yoloEnabled() bool {
+	return envEnabled("SIMPLE_AGENT_YOLO")
+}
+
+func envEnabled(key string) bool {
+	v := os.Getenv(key)
+	return strings.EqualFold(v, "true") || v == "1" || strings.EqualFold(v, "yes")
+}
+
+// shellConfig returns the bash tool settings from the config file. A missing
+// or unreadable file yields the defaults.
+func shellConfig() config.ShellConfig {
+	path, err := config.Path()
+	if err != nil {
+		return config.ShellConfig{}
+	}
+	cfg, err := config.LoadFile(path)
+	if err != nil || cfg.Shell == nil {
+		return config.ShellConfig{}
+	}
+	return *cfg.Shell
+}
+
+// envList splits a comma-separated environment variable into trimmed entries.
+func envList(key string) []string {
+	var values []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,289 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+
+	"github.com/nachoal/simple-agent-go/tools/base"
+)
+
+const (
+	defaultFeedLimit      = 10
+	maxFeedLimit          = 50
+	maxFeedBytes          = 2 * 1024 * 1024
+	maxFeedSummaryPreview = 300
+	feedTimeoutSecs       = 20
+)
+
+type FeedParams struct {
+	URL   string `json:"url" schema:"required" description:"http(s) URL of an RSS or Atom feed"`
+	Limit int    `json:"limit,omitempty" description:"Maximum entries to return (optional, default 10, max 50)"`
+}
+
+// FeedTool fetches an RSS or Atom feed and lists its latest entries.
+type FeedTool struct {
+	base.BaseTool
+	client       *http.Client
+	allowedHosts []string
+}
+
+// feedEntry is a format-neutral feed item.
+type feedEntry struct {
+	Title     string
+	Link      string
+	Published string
+	Summary   string
+}
+
+// feedDocument decodes RSS 2.0, RSS 1.0 (RDF), and Atom; the root element
+// name decides which fields are populated.
+type feedDocument struct {
+	XMLName xml.Name
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items   []rssItem   `xml:"item"`
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Description string `xml:"description"`
+}
+
+type atomEntry struct {
+	Title string `xml:"title"`
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Summary   string `xml:"summary"`
+	Content   string `xml:"content"`
+}
+
+// Parameters returns the parameters struct
+func (t *FeedTool) Parameters() interface{} {
+	return &FeedParams{}
+}
+
+// Execute fetches the feed and formats up to limit entries.
+func (t *FeedTool) Execute(ctx context.Context, params json.RawMessage) (string, error) {
+	var args FeedParams
+	if err := json.Unmarshal(params, &args); err != nil {
+		return "", NewToolError("INVALID_PARAMS", "Failed to parse parameters").
+			WithDetail("error", err.Error())
+	}
+
+	target, err := url.Parse(strings.TrimSpace(args.URL))
+	if err != nil || target.Host == "" || (target.Scheme != "http" && target.Scheme != "https") {
+		return "", NewToolError("VALIDATION_FAILED", "URL must be an absolute http or https URL").
+			WithDetail("url", args.URL)
+	}
+
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultFeedLimit
+	}
+	if limit > maxFeedLimit {
+		limit = maxFeedLimit
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, feedTimeoutSecs*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, "GET", target.String(), nil)
+	if err != nil {
+		return "", NewToolError("REQUEST_ERROR", "Failed to create request").
+			WithDetail("error", err.Error())
+	}
+	req.Header.Set("User-Agent", "simple-agent-go/1.0")
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", NewToolError("HTTP_ERROR", "Failed to fetch feed").
+			WithDetail("url", target.String()).
+			WithDetail("error", err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", NewToolError("HTTP_ERROR", fmt.Sprintf("Feed returned status %d", resp.StatusCode)).
+			WithDetail("url", target.String())
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedBytes+1))
+	if err != nil {
+		return "", NewToolError("READ_ERROR", "Failed to read response").
+			WithDetail("error", err.Error())
+	}
+	if len(body) > maxFeedBytes {
+		return "", NewToolError("FEED_TOO_LARGE", fmt.Sprintf("Feeds larger than %d bytes are not supported", maxFeedBytes)).
+			WithDetail("url", target.String())
+	}
+
+	title, format, entries, err := parseFeed(body, resp.Request.URL)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 0 {
+		return fmt.Sprintf("Feed %s (%s) has no entries.", feedLabel(title, target), format), nil
+	}
+
+	shown := entries
+	if len(shown) > limit {
+		shown = shown[:limit]
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Feed: %s (%s, %d entries", feedLabel(title, target), format, len(entries)))
+	if len(shown) < len(entries) {
+		b.WriteString(fmt.Sprintf(", showing first %d", len(shown)))
+	}
+	b.WriteString(")\n")
+	for i, entry := range shown {
+		b.WriteString(fmt.Sprintf("\n%d. **%s**\n", i+1, entry.Title))
+		if entry.Link != "" {
+			b.WriteString(fmt.Sprintf("   Link: %s\n", entry.Link))
+		}
+		if entry.Published != "" {
+			b.WriteString(fmt.Sprintf("   Published: %s\n", entry.Published))
+		}
+		if entry.Summary != "" {
+			b.WriteString(fmt.Sprintf("   Summary: %s\n", entry.Summary))
+		}
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+// parseFeed decodes an RSS or Atom document into entries, resolving relative
+// links against base.
+func parseFeed(data []byte, base *url.URL) (string, string, []feedEntry, error) {
+	var doc feedDocument
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.CharsetReader = charset.NewReaderLabel
+	if err := decoder.Decode(&doc); err != nil {
+		return "", "", nil, NewToolError("PARSE_ERROR", "Response is not a valid RSS or Atom feed").
+			WithDetail("error", err.Error())
+	}
+
+	var entries []feedEntry
+	switch strings.ToLower(doc.XMLName.Local) {
+	case "rss", "rdf":
+		items := doc.Channel.Items
+		if len(items) == 0 {
+			items = doc.Items
+		}
+		for _, item := range items {
+			link := strings.TrimSpace(item.Link)
+			if link == "" && strings.HasPrefix(strings.TrimSpace(item.GUID), "http") {
+				link = strings.TrimSpace(item.GUID)
+			}
+			published := item.PubDate
+			if strings.TrimSpace(published) == "" {
+				published = item.Date
+			}
+			entries = append(entries, newFeedEntry(item.Title, link, published, item.Description, base))
+		}
+		return collapseFeedText(doc.Channel.Title), "RSS", entries, nil
+	case "feed":
+		for _, entry := range doc.Entries {
+			link := ""
+			for _, l := range entry.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					link = l.Href
+					break
+				}
+			}
+			if link == "" && len(entry.Links) > 0 {
+				link = entry.Links[0].Href
+			}
+			published := entry.Published
+			if strings.TrimSpace(published) == "" {
+				published = entry.Updated
+			}
+			summary := entry.Summary
+			if strings.TrimSpace(summary) == "" {
+				summary = entry.Content
+			}
+			entries = append(entries, newFeedEntry(entry.Title, link, published, summary, base))
+		}
+		return collapseFeedText(doc.Title), "Atom", entries, nil
+	default:
+		return "", "", nil, NewToolError("PARSE_ERROR", "Response is not a valid RSS or Atom feed").
+			WithDetail("root_element", doc.XMLName.Local)
+	}
+}
+
+func newFeedEntry(title, link, published, summary string, base *url.URL) feedEntry {
+	entry := feedEntry{
+		Title:     collapseFeedText(title),
+		Link:      strings.TrimSpace(link),
+		Published: feedDate(published),
+		Summary:   truncateRunes(collapseFeedText(htmlToText(summary)), maxFeedSummaryPreview),
+	}
+	if entry.Title == "" {
+		entry.Title = "(untitled)"
+	}
+	if entry.Link != "" && base != nil {
+		if ref, err := url.Parse(entry.Link); err == nil {
+			entry.Link = base.ResolveReference(ref).String()
+		}
+	}
+	return entry
+}
+
+var feedDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02",
+}
+
+// feedDate normalizes common RSS and Atom timestamps to "2006-01-02 15:04 MST"
+// and returns anything unrecognized unchanged.
+func feedDate(value string) string {
+	value = strings.TrimSpace(value)
+	for _, layout := range feedDateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			if layout == "2006-01-02" {
+				return parsed.Format("2006-01-02")
+			}
+			return parsed.Format("2006-01-02 15:04 MST")
+		}
+	}
+	return value
+}
+
+func feedLabel(title string, target *url.URL) string {
+	if title != "" {
+		return title
+	}
+	return target.String()
+}
+
+func collapseFeedText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
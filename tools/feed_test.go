@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newFeedTestServer(t *testing.T, fixture, contentType string) *httptest.Server {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestFeedTool() *FeedTool {
+	tool := NewFeedTool().(*FeedTool)
+	tool.allowedHosts = []string{"127.0.0.1"}
+	return tool
+}
+
+func TestFeedTool_ParsesRSS(t *testing.T) {
+	srv := newFeedTestServer(t, "feed_rss.xml", "application/rss+xml")
+
+	out, err := newTestFeedTool().Execute(context.Background(), json.RawMessage(`{"url":"`+srv.URL+`/feed","limit":2}`))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	for _, want := range []string{
+		"Feed: Example News (RSS, 3 entries, showing first 2)",
+		"1. **Go 1.24 released**",
+		"Link: https://news.example.com/go-1-24",
+		"Published: 2025-02-11 18:00 UTC",
+		"Summary: The latest Go release is out.",
+		"Link: " + srv.URL + "/generic-aliases",
+		"Published: 2025-02-10 09:30 UTC",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Swiss tables") || strings.Contains(out, "<p>") {
+		t.Fatalf("expected limit and HTML stripping to apply:\n%s", out)
+	}
+}
+
+func TestFeedTool_ParsesAtom(t *testing.T) {
+	srv := newFeedTestServer(t, "feed_atom.xml", "application/atom+xml")
+
+	out, err := newTestFeedTool().Execute(context.Background(), json.RawMessage(`{"url":"`+srv.URL+`"}`))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	for _, want := range []string{
+		"Feed: The Go Blog (Atom, 2 entries)",
+		"Link: https://go.dev/blog/testing-time\n",
+		"Published: 2025-08-26 00:00 UTC",
+		"2. **Container-aware GOMAXPROCS**",
+		"Published: 2025-08-20 00:00 UTC",
+		"Summary: Go 1.25 respects CPU limits.",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestFeedTool_RejectsNonFeed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html><body>not a feed</body></html>"))
+	}))
+	defer srv.Close()
+
+	_, err := newTestFeedTool().Execute(context.Background(), json.RawMessage(`{"url":"`+srv.URL+`"}`))
+	if err == nil || !strings.Contains(err.Error(), "PARSE_ERROR") {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+}
+
+func TestFeedTool_BlocksInternalAddresses(t *testing.T) {
+	srv := newFeedTestServer(t, "feed_rss.xml", "application/rss+xml")
+
+	_, err := NewFeedTool().Execute(context.Background(), json.RawMessage(`{"url":"`+srv.URL+`"}`))
+	if err == nil || !strings.Contains(err.Error(), "HTTP_ERROR") {
+		t.Fatalf("expected internal address to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,396 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nachoal/simple-agent-go/tools/base"
+)
+
+const (
+	defaultStructuredMaxRows = 20
+	maxStructuredMaxRows     = 500
+	maxStructuredValueChars  = 100
+	maxStructuredJSONDepth   = 6
+	maxStructuredJSONBytes   = 50 * 1024 * 1024
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+type StructuredFileParams struct {
+	FilePath string   `json:"file_path" schema:"required" description:"Path to the CSV or JSON file"`
+	Format   string   `json:"format,omitempty" description:"File format: csv or json (optional, inferred from the extension)"`
+	MaxRows  int      `json:"max_rows,omitempty" description:"CSV rows or JSON array items to show (optional, default 20)"`
+	Columns  []string `json:"columns,omitempty" description:"Only include these columns (CSV) or keys (JSON objects) (optional)"`
+}
+
+// StructuredFileTool summarizes CSV and JSON files without returning them whole.
+type StructuredFileTool struct {
+	base.BaseTool
+	fileScope
+}
+
+// Parameters returns the parameters struct
+func (t *StructuredFileTool) Parameters() interface{} {
+	return &StructuredFileParams{}
+}
+
+// Execute reads the file and returns a bounded summary of its contents.
+func (t *StructuredFileTool) Execute(ctx context.Context, params json.RawMessage) (string, error) {
+	var args StructuredFileParams
+	if err := json.Unmarshal(params, &args); err != nil {
+		return "", NewToolError("INVALID_PARAMS", "Failed to parse parameters").
+			WithDetail("error", err.Error())
+	}
+
+	if strings.TrimSpace(args.FilePath) == "" {
+		return "", NewToolError("VALIDATION_FAILED", "File path is required")
+	}
+	path, workspace, err := t.resolve(args.FilePath)
+	if err != nil {
+		return "", err
+	}
+	displayPath := displayPathForWorkspace(path, workspace)
+
+	format := strings.ToLower(strings.TrimSpace(args.Format))
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".csv", ".tsv":
+			format = "csv"
+		case ".json":
+			format = "json"
+		}
+	}
+	if format != "csv" && format != "json" {
+		return "", NewToolError("VALIDATION_FAILED", "Format must be csv or json").
+			WithDetail("format", args.Format)
+	}
+
+	maxRows := args.MaxRows
+	if maxRows <= 0 {
+		maxRows = defaultStructuredMaxRows
+	}
+	if maxRows > maxStructuredMaxRows {
+		maxRows = maxStructuredMaxRows
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", NewToolError("FILE_NOT_FOUND", "File not found").
+				WithDetail("path", displayPath)
+		}
+		return "", NewToolError("READ_ERROR", "Failed to open file").
+			WithDetail("path", displayPath).
+			WithDetail("error", err.Error())
+	}
+	defer file.Close()
+
+	if format == "csv" {
+		return summarizeCSV(file, displayPath, maxRows, args.Columns)
+	}
+	return summarizeJSON(file, displayPath, maxRows, args.Columns)
+}
+
+func summarizeCSV(r io.Reader, displayPath string, maxRows int, columns []string) (string, error) {
+	br := bufio.NewReader(r)
+	if prefix, err := br.Peek(len(utf8BOM)); err == nil && bytes.Equal(prefix, utf8BOM) {
+		_, _ = br.Discard(len(utf8BOM))
+	}
+	firstLine, _ := br.Peek(br.Size())
+	if idx := bytes.IndexByte(firstLine, '\n'); idx >= 0 {
+		firstLine = firstLine[:idx]
+	}
+	delimiter, delimiterName := ',', "comma"
+	if bytes.Count(firstLine, []byte("\t")) > bytes.Count(firstLine, []byte(",")) {
+		delimiter, delimiterName = '\t', "tab"
+	}
+
+	reader := csv.NewReader(br)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Sprintf("File: %s (csv)\nThe file is empty.", displayPath), nil
+		}
+		return "", NewToolError("PARSE_ERROR", "Failed to parse CSV header").
+			WithDetail("error", err.Error())
+	}
+
+	indices := make([]int, len(header))
+	for i := range header {
+		indices[i] = i
+	}
+	if len(columns) > 0 {
+		indices = indices[:0]
+		for _, want := range columns {
+			found := -1
+			for i, name := range header {
+				if strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(want)) {
+					found = i
+					break
+				}
+			}
+			if found < 0 {
+				return "", NewToolError("VALIDATION_FAILED", "Unknown column").
+					WithDetail("column", want).
+					WithDetail("available", strings.Join(header, ", "))
+			}
+			indices = append(indices, found)
+		}
+	}
+
+	var rows [][]string
+	total := 0
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", NewToolError("PARSE_ERROR", "Failed to parse CSV").
+				WithDetail("row", total+2).
+				WithDetail("error", err.Error())
+		}
+		total++
+		if len(rows) < maxRows {
+			row := make([]string, len(indices))
+			for i, idx := range indices {
+				if idx < len(record) {
+					row[i] = markdownCell(record[idx], maxStructuredValueChars)
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	selected := make([]string, len(indices))
+	for i, idx := range indices {
+		selected[i] = markdownCell(header[idx], maxStructuredValueChars)
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("File: %s (csv, %s-delimited)\n", displayPath, delimiterName))
+	b.WriteString(fmt.Sprintf("Columns (%d): %s\n", len(header), strings.Join(header, ", ")))
+	if total > len(rows) {
+		b.WriteString(fmt.Sprintf("Rows: %d (showing first %d)\n\n", total, len(rows)))
+	} else {
+		b.WriteString(fmt.Sprintf("Rows: %d\n\n", total))
+	}
+	b.WriteString("| " + strings.Join(selected, " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat("---|", len(selected)) + "\n")
+	for _, row := range rows {
+		b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+// jsonField is one key/value pair of a JSON object, kept in file order.
+type jsonField struct {
+	key   string
+	value interface{}
+}
+
+type jsonObject []jsonField
+
+func summarizeJSON(r io.Reader, displayPath string, maxItems int, keys []string) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxStructuredJSONBytes+1))
+	if err != nil {
+		return "", NewToolError("READ_ERROR", "Failed to read file").
+			WithDetail("error", err.Error())
+	}
+	if len(data) > maxStructuredJSONBytes {
+		return "", NewToolError("FILE_TOO_LARGE", fmt.Sprintf("JSON files larger than %d bytes are not supported", maxStructuredJSONBytes)).
+			WithDetail("path", displayPath)
+	}
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	value, err := decodeOrderedJSON(dec)
+	if err != nil {
+		return "", NewToolError("PARSE_ERROR", "Failed to parse JSON").
+			WithDetail("error", err.Error())
+	}
+	if len(keys) > 0 {
+		value = filterJSONKeys(value, keys)
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("File: %s (json)\n", displayPath))
+	switch v := value.(type) {
+	case jsonObject:
+		b.WriteString(fmt.Sprintf("Top level: object with %d keys\n\n", len(v)))
+	case []interface{}:
+		b.WriteString(fmt.Sprintf("Top level: array of %d items\n\n", len(v)))
+	default:
+		b.WriteString("Top level: scalar\n\n")
+	}
+	writeJSONSummary(&b, value, 0, maxItems)
+	return b.String(), nil
+}
+
+// decodeOrderedJSON decodes the next JSON value, preserving object key order.
+func decodeOrderedJSON(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+
+	switch delim {
+	case '{':
+		obj := jsonObject{}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := keyTok.(string)
+			value, err := decodeOrderedJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, jsonField{key: key, value: value})
+		}
+		_, err := dec.Token()
+		return obj, err
+	case '[':
+		arr := []interface{}{}
+		for dec.More() {
+			value, err := decodeOrderedJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		_, err := dec.Token()
+		return arr, err
+	}
+	return nil, fmt.Errorf("unexpected delimiter %v", delim)
+}
+
+// filterJSONKeys keeps only the listed keys of a top-level object or of
+// each object in a top-level array.
+func filterJSONKeys(value interface{}, keys []string) interface{} {
+	keep := func(obj jsonObject) jsonObject {
+		filtered := jsonObject{}
+		for _, field := range obj {
+			for _, key := range keys {
+				if strings.EqualFold(field.key, strings.TrimSpace(key)) {
+					filtered = append(filtered, field)
+					break
+				}
+			}
+		}
+		return filtered
+	}
+
+	switch v := value.(type) {
+	case jsonObject:
+		return keep(v)
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			if obj, ok := item.(jsonObject); ok {
+				out[i] = keep(obj)
+			} else {
+				out[i] = item
+			}
+		}
+		return out
+	}
+	return value
+}
+
+func writeJSONSummary(b *strings.Builder, value interface{}, depth, maxItems int) {
+	indent := strings.Repeat("  ", depth)
+	inner := indent + "  "
+
+	switch v := value.(type) {
+	case jsonObject:
+		if len(v) == 0 {
+			b.WriteString("{}")
+			return
+		}
+		if depth >= maxStructuredJSONDepth {
+			b.WriteString(fmt.Sprintf("{… %d keys}", len(v)))
+			return
+		}
+		b.WriteString("{\n")
+		for i, field := range v {
+			key, _ := json.Marshal(field.key)
+			b.WriteString(inner + string(key) + ": ")
+			writeJSONSummary(b, field.value, depth+1, maxItems)
+			if i < len(v)-1 {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(indent + "}")
+	case []interface{}:
+		if len(v) == 0 {
+			b.WriteString("[]")
+			return
+		}
+		if depth >= maxStructuredJSONDepth {
+			b.WriteString(fmt.Sprintf("[… %d items]", len(v)))
+			return
+		}
+		shown := len(v)
+		if shown > maxItems {
+			shown = maxItems
+		}
+		b.WriteString("[\n")
+		for i := 0; i < shown; i++ {
+			b.WriteString(inner)
+			writeJSONSummary(b, v[i], depth+1, maxItems)
+			if i < len(v)-1 {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		if shown < len(v) {
+			b.WriteString(fmt.Sprintf("%s… %d more items (%d total)\n", inner, len(v)-shown, len(v)))
+		}
+		b.WriteString(indent + "]")
+	case string:
+		encoded, _ := json.Marshal(truncateRunes(v, maxStructuredValueChars))
+		b.Write(encoded)
+	case nil:
+		b.WriteString("null")
+	default:
+		b.WriteString(truncateRunes(fmt.Sprint(v), maxStructuredValueChars))
+	}
+}
+
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max]) + "..."
+}
+
+// markdownCell truncates s and escapes it for use inside a markdown table row.
+func markdownCell(s string, max int) string {
+	s = truncateRunes(s, max)
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\r\n", " ")
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeStructuredFixture(t *testing.T, name, content string) {
+	t.Helper()
+	dir := t.TempDir()
+	withWorkingDir(t, dir)
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+}
+
+func TestStructuredFileTool_CSVWithBOMAndTabs(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("\ufeffname\temail\tage\n")
+	for i := 1; i <= 30; i++ {
+		b.WriteString(fmt.Sprintf("user%d\tuser%d@example.com\t%d\n", i, i, 20+i))
+	}
+	writeStructuredFixture(t, "people.tsv", b.String())
+
+	out, err := NewStructuredFileTool().Execute(context.Background(), json.RawMessage(`{"file_path":"people.tsv","columns":["name","AGE"]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"tab-delimited", "Rows: 30 (showing first 20)", "| name | age |", "| user1 | 21 |", "| user20 | 40 |"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "user21 |") || strings.Contains(out, "@example.com |") {
+		t.Fatalf("expected row limit and column filter to apply:\n%s", out)
+	}
+
+	_, err = NewStructuredFileTool().Execute(context.Background(), json.RawMessage(`{"file_path":"people.tsv","format":"csv","columns":["missing"]}`))
+	if err == nil || !strings.Contains(err.Error(), "Unknown column") {
+		t.Fatalf("expected unknown column error, got %v", err)
+	}
+}
+
+func TestStructuredFileTool_CSVCommaQuoted(t *testing.T) {
+	writeStructuredFixture(t, "data.csv", "id,note\n1,\"hello, world\"\n2,a|b\n")
+
+	out, err := NewStructuredFileTool().Execute(context.Background(), json.RawMessage(`{"file_path":"data.csv","max_rows":5}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"File: data.csv (csv, comma-delimited)", "Rows: 2\n", "| 1 | hello, world |", "| 2 | a\\|b |"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestStructuredFileTool_JSONSummarizesStructure(t *testing.T) {
+	items := make([]string, 25)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"id":%d,"secret":"s%d"}`, i, i)
+	}
+	content := fmt.Sprintf(`{"name":"report","description":%q,"items":[%s],"empty":[]}`, strings.Repeat("d", 150), strings.Join(items, ","))
+	writeStructuredFixture(t, "report.json", content)
+
+	out, err := NewStructuredFileTool().Execute(context.Background(), json.RawMessage(`{"file_path":"report.json","max_rows":3}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Top level: object with 4 keys", `"name": "report"`, strings.Repeat("d", 100) + `..."`, `"id": 2`, "… 22 more items (25 total)", `"empty": []`} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, `"name"`) > strings.Index(out, `"items"`) {
+		t.Fatalf("expected keys to keep file order:\n%s", out)
+	}
+
+	writeStructuredFixture(t, "rows.json", "["+strings.Join(items[:2], ",")+"]")
+	out, err = NewStructuredFileTool().Execute(context.Background(), json.RawMessage(`{"file_path":"rows.json","columns":["id"]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "Top level: array of 2 items") || strings.Contains(out, "secret") {
+		t.Fatalf("expected keys filter on array objects:\n%s", out)
+	}
+}
@@ -0,0 +1,233 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/tools/base"
+)
+
+const (
+	gitCommandTimeout  = 30 * time.Second
+	defaultGitLogLimit = 10
+	maxGitLogLimit     = 100
+	gitLogFieldSep     = "\x1f"
+)
+
+type GitParams struct {
+	Operation string `json:"operation" schema:"required" description:"Git operation: log, diff, status, add, commit, checkout, or branch"`
+	Path      string `json:"path,omitempty" description:"File or directory path (add: required; log/diff: optional filter)"`
+	Message   string `json:"message,omitempty" description:"Commit message (commit: required)"`
+	Ref       string `json:"ref,omitempty" description:"Branch, tag, or commit (checkout: required; branch: name to create; log/diff: optional)"`
+	Limit     int    `json:"limit,omitempty" description:"Number of commits for log (optional, default 10)"`
+}
+
+// GitTool runs common git operations in the repository containing the working directory.
+type GitTool struct {
+	base.BaseTool
+	fileScope
+}
+
+// Parameters returns the parameters struct
+func (t *GitTool) Parameters() interface{} {
+	return &GitParams{}
+}
+
+// Execute runs the requested git operation.
+func (t *GitTool) Execute(ctx context.Context, params json.RawMessage) (string, error) {
+	var args GitParams
+	if err := json.Unmarshal(params, &args); err != nil {
+		return "", NewToolError("INVALID_PARAMS", "Failed to parse parameters").
+			WithDetail("error", err.Error())
+	}
+
+	workspace, err := t.workspace()
+	if err != nil {
+		return "", err
+	}
+	repoRoot, ok := findGitRoot(workspace)
+	if !ok {
+		return "", NewToolError("NOT_A_REPOSITORY", "No git repository found in the working directory or its parents").
+			WithDetail("workspace", workspace)
+	}
+
+	var pathArg string
+	if strings.TrimSpace(args.Path) != "" {
+		resolved, _, err := t.resolve(args.Path)
+		if err != nil {
+			return "", err
+		}
+		pathArg = resolved
+	}
+	ref := strings.TrimSpace(args.Ref)
+	if strings.HasPrefix(ref, "-") {
+		return "", NewToolError("VALIDATION_FAILED", "Ref cannot start with '-'").
+			WithDetail("ref", ref)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(args.Operation)) {
+	case "status":
+		return t.run(ctx, repoRoot, "status", "--short", "--branch")
+
+	case "log":
+		limit := args.Limit
+		if limit <= 0 {
+			limit = defaultGitLogLimit
+		}
+		if limit > maxGitLogLimit {
+			limit = maxGitLogLimit
+		}
+		gitArgs := []string{"log", fmt.Sprintf("-n%d", limit), "--date=short",
+			"--pretty=format:%h" + gitLogFieldSep + "%an" + gitLogFieldSep + "%ad" + gitLogFieldSep + "%s"}
+		if ref != "" {
+			gitArgs = append(gitArgs, ref)
+		}
+		if pathArg != "" {
+			gitArgs = append(gitArgs, "--", pathArg)
+		}
+		out, err := t.run(ctx, repoRoot, gitArgs...)
+		if err != nil {
+			return "", err
+		}
+		return formatGitLog(out), nil
+
+	case "diff":
+		gitArgs := []string{"diff"}
+		if ref != "" {
+			gitArgs = append(gitArgs, ref)
+		}
+		if pathArg != "" {
+			gitArgs = append(gitArgs, "--", pathArg)
+		}
+		out, err := t.run(ctx, repoRoot, gitArgs...)
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(out) == "" {
+			return "No changes.", nil
+		}
+		return "```diff\n" + strings.TrimRight(out, "\n") + "\n```", nil
+
+	case "add":
+		if pathArg == "" {
+			return "", NewToolError("VALIDATION_FAILED", "Path is required for add")
+		}
+		if _, err := t.run(ctx, repoRoot, "add", "--", pathArg); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Staged %s", displayPathForWorkspace(pathArg, workspace)), nil
+
+	case "commit":
+		message := strings.TrimSpace(args.Message)
+		if message == "" {
+			return "", NewToolError("VALIDATION_FAILED", "Message is required for commit")
+		}
+		return t.run(ctx, repoRoot, "commit", "-m", message)
+
+	case "checkout":
+		if ref == "" {
+			return "", NewToolError("VALIDATION_FAILED", "Ref is required for checkout")
+		}
+		out, err := t.run(ctx, repoRoot, "checkout", ref)
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(out) == "" {
+			out = fmt.Sprintf("Checked out %s", ref)
+		}
+		return out, nil
+
+	case "branch":
+		if ref == "" {
+			return t.run(ctx, repoRoot, "branch", "--list")
+		}
+		if _, err := t.run(ctx, repoRoot, "branch", ref); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Created branch %s", ref), nil
+
+	default:
+		return "", NewToolError("VALIDATION_FAILED", "Unsupported git operation").
+			WithDetail("operation", args.Operation).
+			WithDetail("allowed", "log, diff, status, add, commit, checkout, branch")
+	}
+}
+
+// run executes git with a fixed timeout and returns stdout.
+func (t *GitTool) run(ctx context.Context, dir string, args ...string) (string, error) {
+	cmdCtx, cancel := context.WithTimeout(ctx, gitCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(cmdCtx, "git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		command := "git " + strings.Join(args, " ")
+		if cmdCtx.Err() == context.DeadlineExceeded {
+			return "", NewToolError("EXECUTION_TIMEOUT", fmt.Sprintf("git timed out after %v", gitCommandTimeout)).
+				WithDetail("command", command)
+		}
+		if cmdCtx.Err() == context.Canceled {
+			return "", NewToolError("EXECUTION_CANCELLED", "git command was cancelled").
+				WithDetail("command", command)
+		}
+		output := strings.TrimSpace(stderr.String())
+		if output == "" {
+			output = strings.TrimSpace(stdout.String())
+		}
+		return "", NewToolError("GIT_ERROR", "git command failed").
+			WithDetail("command", command).
+			WithDetail("error", err.Error()).
+			WithDetail("output", output)
+	}
+
+	return stdout.String(), nil
+}
+
+// findGitRoot walks up from start looking for a .git directory or file.
+func findGitRoot(start string) (string, bool) {
+	dir := filepath.Clean(start)
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// formatGitLog renders separator-delimited log lines as a markdown table.
+func formatGitLog(out string) string {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) == 0 || lines[0] == "" {
+		return "No commits found."
+	}
+
+	var b strings.Builder
+	b.WriteString("| Commit | Author | Date | Subject |\n")
+	b.WriteString("|--------|--------|------|---------|\n")
+	for _, line := range lines {
+		fields := strings.SplitN(line, gitLogFieldSep, 4)
+		for len(fields) < 4 {
+			fields = append(fields, "")
+		}
+		for i := range fields {
+			fields[i] = strings.ReplaceAll(fields[i], "|", "\\|")
+		}
+		b.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", fields[0], fields[1], fields[2], fields[3]))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func initTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "Test Author")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test Author")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(t.TempDir(), "gitconfig"))
+
+	dir := t.TempDir()
+	cmd := exec.Command("git", "init", "-q", "-b", "main")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+	return dir
+}
+
+func runGitTool(t *testing.T, tool Tool, args string) string {
+	t.Helper()
+	out, err := tool.Execute(context.Background(), json.RawMessage(args))
+	if err != nil {
+		t.Fatalf("git tool %s: %v", args, err)
+	}
+	return out
+}
+
+func TestGitTool_Workflow(t *testing.T) {
+	repo := initTestRepo(t)
+	sub := filepath.Join(repo, "pkg")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	withWorkingDir(t, sub)
+
+	tool := NewGitTool()
+	if err := os.WriteFile("hello.txt", []byte("hello\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	if out := runGitTool(t, tool, `{"operation":"status"}`); !strings.Contains(out, "?? pkg/") {
+		t.Fatalf("expected untracked file in status, got %q", out)
+	}
+	if out := runGitTool(t, tool, `{"operation":"add","path":"hello.txt"}`); out != "Staged hello.txt" {
+		t.Fatalf("unexpected add output: %q", out)
+	}
+	runGitTool(t, tool, `{"operation":"commit","message":"Add hello | greeting"}`)
+
+	log := runGitTool(t, tool, `{"operation":"log"}`)
+	if !strings.HasPrefix(log, "| Commit | Author | Date | Subject |") {
+		t.Fatalf("expected markdown table header, got %q", log)
+	}
+	if !strings.Contains(log, "| Test Author |") || !strings.Contains(log, `Add hello \| greeting`) {
+		t.Fatalf("expected commit row with escaped subject, got %q", log)
+	}
+
+	if err := os.WriteFile("hello.txt", []byte("hello\nworld\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	diff := runGitTool(t, tool, `{"operation":"diff","path":"hello.txt"}`)
+	if !strings.HasPrefix(diff, "```diff\n") || !strings.HasSuffix(diff, "\n```") || !strings.Contains(diff, "+world") {
+		t.Fatalf("expected fenced diff block, got %q", diff)
+	}
+
+	runGitTool(t, tool, `{"operation":"branch","ref":"feature"}`)
+	if out := runGitTool(t, tool, `{"operation":"branch"}`); !strings.Contains(out, "feature") {
+		t.Fatalf("expected new branch in list, got %q", out)
+	}
+}
+
+func TestGitTool_Validation(t *testing.T) {
+	repo := initTestRepo(t)
+	withWorkingDir(t, repo)
+	tool := NewGitTool()
+
+	tests := []struct {
+		args string
+		code string
+	}{
+		{`{"operation":"commit"}`, "VALIDATION_FAILED"},
+		{`{"operation":"checkout"}`, "VALIDATION_FAILED"},
+		{`{"operation":"checkout","ref":"--orphan"}`, "VALIDATION_FAILED"},
+		{`{"operation":"push"}`, "VALIDATION_FAILED"},
+		{`{"operation":"add","path":"../outside"}`, PathEscape},
+	}
+	for _, tc := range tests {
+		_, err := tool.Execute(context.Background(), json.RawMessage(tc.args))
+		te, ok := err.(*ToolError)
+		if !ok || te.Code != tc.code {
+			t.Fatalf("%s: expected %s, got %v", tc.args, tc.code, err)
+		}
+	}
+}
+
+func TestFindGitRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatalf("mkdir .git: %v", err)
+	}
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("mkdir nested: %v", err)
+	}
+
+	got, ok := findGitRoot(nested)
+	if !ok || got != root {
+		t.Fatalf("expected root %s, got %s (ok=%v)", root, got, ok)
+	}
+}
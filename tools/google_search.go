@@ -30,6 +30,11 @@ func (t *GoogleSearchTool) Parameters() interface{} {
 	return &GoogleSearchParams{}
 }
 
+// Available reports whether GOOGLE_API_KEY and GOOGLE_CX are set
+func (t *GoogleSearchTool) Available() bool {
+	return t.apiKey != "" && t.searchEngineID != ""
+}
+
 // Execute performs a Google search and returns formatted results
 func (t *GoogleSearchTool) Execute(ctx context.Context, params json.RawMessage) (string, error) {
 	var args GoogleSearchParams
@@ -44,7 +49,7 @@ func (t *GoogleSearchTool) Execute(ctx context.Context, params json.RawMessage)
 	}
 
 	// Check if API credentials are configured
-	if t.apiKey == "" || t.searchEngineID == "" {
+	if !t.Available() {
 		return "", NewToolError("NOT_CONFIGURED", "Google Search API credentials not configured").
 			WithDetail("help", "Set GOOGLE_API_KEY and GOOGLE_CX environment variables")
 	}
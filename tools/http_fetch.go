@@ -0,0 +1,247 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/tools/base"
+)
+
+const (
+	defaultHTTPFetchTimeoutSecs = 15
+	maxHTTPFetchTimeoutSecs     = 120
+	defaultHTTPFetchMaxBytes    = 100 * 1024
+)
+
+type HTTPFetchParams struct {
+	URL      string `json:"url" schema:"required" description:"http(s) URL to fetch with GET"`
+	Timeout  int    `json:"timeout,omitempty" description:"Timeout in seconds (optional, default 15)"`
+	MaxBytes int    `json:"max_bytes,omitempty" description:"Maximum response bytes to read (optional, default 102400)"`
+}
+
+// HTTPFetchTool retrieves a URL and returns a readable version of the body.
+type HTTPFetchTool struct {
+	base.BaseTool
+	client       *http.Client
+	maxBytes     int
+	allowedHosts []string
+}
+
+// Parameters returns the parameters struct
+func (t *HTTPFetchTool) Parameters() interface{} {
+	return &HTTPFetchParams{}
+}
+
+// Execute fetches the URL and formats the response body by content type.
+func (t *HTTPFetchTool) Execute(ctx context.Context, params json.RawMessage) (string, error) {
+	var args HTTPFetchParams
+	if err := json.Unmarshal(params, &args); err != nil {
+		return "", NewToolError("INVALID_PARAMS", "Failed to parse parameters").
+			WithDetail("error", err.Error())
+	}
+
+	target, err := url.Parse(strings.TrimSpace(args.URL))
+	if err != nil || target.Host == "" || (target.Scheme != "http" && target.Scheme != "https") {
+		return "", NewToolError("VALIDATION_FAILED", "URL must be an absolute http or https URL").
+			WithDetail("url", args.URL)
+	}
+
+	timeout := args.Timeout
+	if timeout <= 0 || timeout > maxHTTPFetchTimeoutSecs {
+		timeout = defaultHTTPFetchTimeoutSecs
+	}
+	maxBytes := args.MaxBytes
+	if maxBytes <= 0 || maxBytes > t.maxBytes {
+		maxBytes = t.maxBytes
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, "GET", target.String(), nil)
+	if err != nil {
+		return "", NewToolError("REQUEST_ERROR", "Failed to create request").
+			WithDetail("error", err.Error())
+	}
+	req.Header.Set("User-Agent", "simple-agent-go/1.0")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", NewToolError("HTTP_ERROR", "Failed to fetch URL").
+			WithDetail("url", target.String()).
+			WithDetail("error", err.Error())
+	}
+	defer resp.Body.Close()
+
+	var bodyReader io.Reader = resp.Body
+	if _, ok := ProgressReporterFromContext(ctx); ok {
+		bodyReader = &progressReader{ctx: ctx, r: resp.Body, total: resp.ContentLength}
+	}
+	body, err := io.ReadAll(io.LimitReader(bodyReader, int64(maxBytes)+1))
+	if err != nil {
+		return "", NewToolError("READ_ERROR", "Failed to read response").
+			WithDetail("error", err.Error())
+	}
+	truncated := len(body) > maxBytes
+	if truncated {
+		body = body[:maxBytes]
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "" {
+		mediaType = http.DetectContentType(body)
+		mediaType, _, _ = mime.ParseMediaType(mediaType)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("URL: %s\n", resp.Request.URL.String()))
+	output.WriteString(fmt.Sprintf("Status: %s\n", resp.Status))
+	output.WriteString(fmt.Sprintf("Content-Type: %s\n\n", mediaType))
+
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		output.WriteString(htmlToText(string(body)))
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var pretty bytes.Buffer
+		if !truncated && json.Indent(&pretty, body, "", "  ") == nil {
+			output.Write(pretty.Bytes())
+		} else {
+			output.Write(body)
+		}
+	case strings.HasPrefix(mediaType, "text/") || mediaType == "application/xml" || strings.HasSuffix(mediaType, "+xml"):
+		output.Write(body)
+	default:
+		output.WriteString(fmt.Sprintf("[binary content (%s) not shown", mediaType))
+		if resp.ContentLength > 0 {
+			output.WriteString(fmt.Sprintf(", %d bytes", resp.ContentLength))
+		}
+		output.WriteString("]")
+		return output.String(), nil
+	}
+
+	if truncated {
+		output.WriteString(fmt.Sprintf("\n\n[response truncated to %d bytes]", maxBytes))
+	}
+
+	return output.String(), nil
+}
+
+// guardedHTTPClient returns the client the web tools share. Requests give up
+// after timeout, and connections to private, loopback, and link-local
+// addresses are refused unless allowed(host) reports true; the fetch tools
+// allow the hosts listed in SIMPLE_AGENT_FETCH_ALLOW_HOSTS (comma-separated
+// host names or IPs).
+func guardedHTTPClient(timeout time.Duration, allowed func(host string) bool) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         guardedDialContext(allowed),
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}
+}
+
+// guardedDialContext refuses connections to private, loopback, and
+// link-local addresses unless allowed(host) reports true. Checking at dial
+// time also covers redirects and hostnames that resolve to internal addresses.
+func guardedDialContext(allowed func(host string) bool) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if allowed(host) {
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		if len(ips) == 0 {
+			return nil, fmt.Errorf("no addresses found for host %s", host)
+		}
+		for _, ip := range ips {
+			if isInternalIP(ip.IP) {
+				return nil, fmt.Errorf("refusing to connect to internal address %s for host %s", ip.IP, host)
+			}
+		}
+		return dialer.DialContext(ctx, network, net.JoinHostPort(ips[0].IP.String(), port))
+	}
+}
+
+func hostAllowed(host string, allowed []string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, entry := range allowed {
+		if strings.EqualFold(strings.TrimSpace(entry), host) {
+			return true
+		}
+	}
+	return false
+}
+
+// Ranges the net.IP helpers don't cover
+var (
+	thisNetwork = mustParseCIDR("0.0.0.0/8")     // "This network"; 0.x.x.x reaches the local host on Linux
+	carrierNAT  = mustParseCIDR("100.64.0.0/10") // Shared address space (CGNAT), often internal cloud networks
+	nat64       = mustParseCIDR("64:ff9b::/96")  // NAT64; the last four bytes are an IPv4 address the gateway reaches
+)
+
+func mustParseCIDR(s string) *net.IPNet {
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// isInternalIP reports whether ip is loopback, private, link-local,
+// multicast, unspecified, in 0.0.0.0/8 or CGNAT space, or a NAT64 address,
+// which a NAT64 gateway would translate to any IPv4 address, internal ones
+// included.
+func isInternalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsMulticast() ||
+		thisNetwork.Contains(ip) || carrierNAT.Contains(ip) || nat64.Contains(ip)
+}
+
+var (
+	htmlDropBlockRe = regexp.MustCompile(`(?is)<(script|style|noscript|head|svg)\b.*?</(script|style|noscript|head|svg)>`)
+	htmlCommentRe   = regexp.MustCompile(`(?s)<!--.*?-->`)
+	htmlBreakRe     = regexp.MustCompile(`(?i)<(br|/p|/div|/li|/tr|/h[1-6]|/section|/article|/blockquote|/pre)\b[^>]*>`)
+	htmlTagRe       = regexp.MustCompile(`(?s)<[^>]*>`)
+	spaceRunRe      = regexp.MustCompile(`[ \t\r\f\v]+`)
+	blankLinesRe    = regexp.MustCompile(`\n\s*\n+`)
+)
+
+// htmlToText strips markup from an HTML document and collapses whitespace so
+// the model sees the readable text only.
+func htmlToText(doc string) string {
+	text := htmlDropBlockRe.ReplaceAllString(doc, " ")
+	text = htmlCommentRe.ReplaceAllString(text, " ")
+	text = htmlBreakRe.ReplaceAllString(text, "\n")
+	text = htmlTagRe.ReplaceAllString(text, " ")
+	text = html.UnescapeString(text)
+	text = spaceRunRe.ReplaceAllString(text, " ")
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	text = strings.Join(lines, "\n")
+	text = blankLinesRe.ReplaceAllString(text, "\n\n")
+	return strings.TrimSpace(text)
+}
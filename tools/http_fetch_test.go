@@ -0,0 +1,148 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestHTTPFetchTool(allowedHosts ...string) *HTTPFetchTool {
+	t := NewHTTPFetchTool().(*HTTPFetchTool)
+	t.allowedHosts = allowedHosts
+	return t
+}
+
+func TestHTTPFetchTool_StripsHTML(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><title>t</title><style>body{color:red}</style></head>
+<body><h1>Hello &amp; welcome</h1><script>alert("x")</script><p>First <b>para</b>.</p></body></html>`))
+	}))
+	defer srv.Close()
+
+	tool := newTestHTTPFetchTool("127.0.0.1")
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{"url":"`+srv.URL+`"}`))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	if !strings.Contains(out, "Content-Type: text/html") {
+		t.Fatalf("expected content type in output, got %q", out)
+	}
+	if !strings.Contains(out, "Hello & welcome") || !strings.Contains(out, "First para .") {
+		t.Fatalf("expected readable text, got %q", out)
+	}
+	for _, leaked := range []string{"<", "alert", "color:red"} {
+		if strings.Contains(out, leaked) {
+			t.Fatalf("expected %q to be stripped, got %q", leaked, out)
+		}
+	}
+}
+
+func TestHTTPFetchTool_TruncatesToMaxBytes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(strings.Repeat("z", 200*1024)))
+	}))
+	defer srv.Close()
+
+	tool := newTestHTTPFetchTool("127.0.0.1")
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{"url":"`+srv.URL+`"}`))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	if got := strings.Count(out, "z"); got != defaultHTTPFetchMaxBytes {
+		t.Fatalf("expected body capped at %d bytes, got %d", defaultHTTPFetchMaxBytes, got)
+	}
+	if !strings.Contains(out, "[response truncated to 102400 bytes]") {
+		t.Fatalf("expected truncation note, got tail %q", out[len(out)-80:])
+	}
+}
+
+func TestHTTPFetchTool_PrettyPrintsJSONAndNotesBinary(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/img" {
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte{0x89, 'P', 'N', 'G'})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"a":1,"b":[true]}`))
+	}))
+	defer srv.Close()
+
+	tool := newTestHTTPFetchTool("127.0.0.1")
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{"url":"`+srv.URL+`/data"}`))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !strings.Contains(out, "{\n  \"a\": 1,") {
+		t.Fatalf("expected pretty-printed JSON, got %q", out)
+	}
+
+	out, err = tool.Execute(context.Background(), json.RawMessage(`{"url":"`+srv.URL+`/img"}`))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !strings.Contains(out, "[binary content (image/png) not shown") {
+		t.Fatalf("expected binary note, got %q", out)
+	}
+}
+
+func TestHTTPFetchTool_BlocksLoopbackByDefault(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("secret"))
+	}))
+	defer srv.Close()
+
+	tool := newTestHTTPFetchTool()
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"url":"`+srv.URL+`"}`))
+	if err == nil {
+		t.Fatalf("expected loopback fetch to be blocked")
+	}
+	te, ok := err.(*ToolError)
+	if !ok || te.Code != "HTTP_ERROR" || !strings.Contains(te.Details["error"].(string), "internal address") {
+		t.Fatalf("expected internal address error, got %v", err)
+	}
+}
+
+func TestIsInternalIP(t *testing.T) {
+	cases := map[string]bool{
+		"127.0.0.1":            true,
+		"10.1.2.3":             true,
+		"172.16.0.1":           true,
+		"192.168.1.1":          true,
+		"169.254.169.254":      true, // cloud metadata
+		"100.64.0.1":           true, // CGNAT
+		"100.127.255.254":      true,
+		"0.0.0.0":              true,
+		"0.1.2.3":              true,
+		"224.0.0.1":            true,
+		"239.255.255.250":      true,
+		"::1":                  true,
+		"::":                   true,
+		"fc00::1":              true,
+		"fe80::1":              true,
+		"ff02::1":              true,
+		"::ffff:127.0.0.1":     true,
+		"::ffff:10.0.0.1":      true,
+		"64:ff9b::7f00:1":      true, // NAT64 of 127.0.0.1
+		"64:ff9b::a9fe:a9fe":   true, // NAT64 of 169.254.169.254
+		"64:ff9b::808:808":     true, // NAT64 of a public address still goes through the gateway
+		"8.8.8.8":              false,
+		"100.128.0.1":          false,
+		"1.1.1.1":              false,
+		"2606:4700:4700::1111": false,
+		"::ffff:8.8.8.8":       false,
+	}
+	for addr, want := range cases {
+		if got := isInternalIP(net.ParseIP(addr)); got != want {
+			t.Errorf("isInternalIP(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
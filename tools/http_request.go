@@ -0,0 +1,201 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/tools/base"
+)
+
+const (
+	defaultHTTPRequestTimeoutSecs = 30
+	maxHTTPRequestTimeoutSecs     = 120
+	maxHTTPRequestRedirects       = 5
+	maxHTTPRequestBodyBytes       = 50 * 1024
+)
+
+var allowedHTTPMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+type HTTPRequestParams struct {
+	Method      string            `json:"method,omitempty" description:"HTTP method (optional, default GET)"`
+	URL         string            `json:"url" schema:"required" description:"Absolute http(s) URL to request"`
+	Headers     map[string]string `json:"headers,omitempty" description:"Request headers as a JSON object (optional)"`
+	Body        string            `json:"body,omitempty" description:"Request body (optional)"`
+	TimeoutSecs int               `json:"timeout_secs,omitempty" description:"Timeout in seconds (optional, default 30)"`
+}
+
+// HTTPRequestTool performs arbitrary HTTP requests.
+type HTTPRequestTool struct {
+	base.BaseTool
+	client         *http.Client
+	allowedDomains []string
+	blockedDomains []string
+	allowAll       bool
+}
+
+// Parameters returns the parameters struct
+func (t *HTTPRequestTool) Parameters() interface{} {
+	return &HTTPRequestParams{}
+}
+
+// Execute performs the HTTP request and returns a structured summary of the response.
+func (t *HTTPRequestTool) Execute(ctx context.Context, params json.RawMessage) (string, error) {
+	var args HTTPRequestParams
+	if err := json.Unmarshal(params, &args); err != nil {
+		return "", NewToolError("INVALID_PARAMS", "Failed to parse parameters").
+			WithDetail("error", err.Error())
+	}
+
+	method := strings.ToUpper(strings.TrimSpace(args.Method))
+	if method == "" {
+		method = "GET"
+	}
+	if !containsString(allowedHTTPMethods, method) {
+		return "", NewToolError("VALIDATION_FAILED", "Unsupported HTTP method").
+			WithDetail("method", method).
+			WithDetail("allowed", strings.Join(allowedHTTPMethods, ", "))
+	}
+
+	target, err := url.Parse(strings.TrimSpace(args.URL))
+	if err != nil || target.Host == "" || (target.Scheme != "http" && target.Scheme != "https") {
+		return "", NewToolError("VALIDATION_FAILED", "URL must be an absolute http or https URL").
+			WithDetail("url", args.URL)
+	}
+	if err := t.checkDomain(target.Hostname()); err != nil {
+		return "", err
+	}
+
+	timeout := args.TimeoutSecs
+	if timeout <= 0 || timeout > maxHTTPRequestTimeoutSecs {
+		timeout = defaultHTTPRequestTimeoutSecs
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	var body io.Reader
+	if args.Body != "" {
+		body = strings.NewReader(args.Body)
+	}
+	req, err := http.NewRequestWithContext(reqCtx, method, target.String(), body)
+	if err != nil {
+		return "", NewToolError("REQUEST_ERROR", "Failed to create request").
+			WithDetail("error", err.Error())
+	}
+	req.Header.Set("User-Agent", "simple-agent-go/1.0")
+	for k, v := range args.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		var toolErr *ToolError
+		if errors.As(err, &toolErr) {
+			return "", toolErr
+		}
+		return "", NewToolError("HTTP_ERROR", "Request failed").
+			WithDetail("url", target.String()).
+			WithDetail("error", err.Error())
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPRequestBodyBytes+1))
+	if err != nil {
+		return "", NewToolError("READ_ERROR", "Failed to read response").
+			WithDetail("error", err.Error())
+	}
+	truncated := len(respBody) > maxHTTPRequestBodyBytes
+	if truncated {
+		respBody = respBody[:maxHTTPRequestBodyBytes]
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Final URL: %s\n", resp.Request.URL.String()))
+	output.WriteString(fmt.Sprintf("Status: %d %s\n", resp.StatusCode, http.StatusText(resp.StatusCode)))
+
+	output.WriteString("Headers:\n")
+	names := make([]string, 0, len(resp.Header))
+	for name := range resp.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		output.WriteString(fmt.Sprintf("  %s: %s\n", name, strings.Join(resp.Header.Values(name), ", ")))
+	}
+
+	output.WriteString("Body:\n")
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "text/html" || mediaType == "application/xhtml+xml" {
+		output.WriteString(htmlToText(string(respBody)))
+	} else {
+		output.Write(respBody)
+	}
+	if truncated {
+		output.WriteString(fmt.Sprintf("\n[body truncated to %d bytes]", maxHTTPRequestBodyBytes))
+	}
+
+	return output.String(), nil
+}
+
+// checkRedirect caps redirect chains and applies the domain policy to every hop.
+func (t *HTTPRequestTool) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxHTTPRequestRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxHTTPRequestRedirects)
+	}
+	return t.checkDomain(req.URL.Hostname())
+}
+
+// checkDomain enforces the blocklist and, unless allowAll is set, the
+// allowlist. Like BashTool's allowlist, an empty list allows nothing.
+func (t *HTTPRequestTool) checkDomain(host string) error {
+	if matchesDomain(host, t.blockedDomains) {
+		return NewToolError("DOMAIN_NOT_ALLOWED", "Domain is blocked").
+			WithDetail("host", host)
+	}
+	if t.allowAll {
+		return nil
+	}
+	if len(t.allowedDomains) == 0 {
+		return NewToolError("DOMAIN_NOT_ALLOWED", "No domains are allowed (set SIMPLE_AGENT_HTTP_ALLOW_DOMAINS, or start simple-agent with --yolo to allow any domain)").
+			WithDetail("host", host)
+	}
+	if !matchesDomain(host, t.allowedDomains) {
+		return NewToolError("DOMAIN_NOT_ALLOWED", "Domain is not in the allowed list (start simple-agent with --yolo to allow any domain)").
+			WithDetail("host", host).
+			WithDetail("allowed", strings.Join(t.allowedDomains, ", "))
+	}
+	return nil
+}
+
+// matchesDomain reports whether host equals or is a subdomain of any entry.
+func matchesDomain(host string, domains []string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "."))
+		if domain == "" {
+			continue
+		}
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
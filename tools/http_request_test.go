@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestHTTPRequestTool(allowAll bool, allowed, blocked []string) *HTTPRequestTool {
+	t := NewHTTPRequestTool().(*HTTPRequestTool)
+	t.allowAll = allowAll
+	t.allowedDomains = allowed
+	t.blockedDomains = blocked
+	return t
+}
+
+func TestHTTPRequestTool_StructuredResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/start" {
+			http.Redirect(w, r, "/echo", http.StatusFound)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("X-Method", r.Method)
+		_, _ = w.Write([]byte("<p>got <b>" + string(body) + "</b> " + r.Header.Get("X-Token") + "</p>"))
+	}))
+	defer srv.Close()
+
+	tool := newTestHTTPRequestTool(false, []string{"127.0.0.1"}, nil)
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{"method":"get","url":"`+srv.URL+`/echo","headers":{"X-Token":"abc"},"body":"ping"}`))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	for _, want := range []string{"Final URL: " + srv.URL + "/echo", "Status: 200 OK", "  X-Method: GET", "got ping abc"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got %q", want, out)
+		}
+	}
+	if strings.Contains(out, "<b>") {
+		t.Fatalf("expected HTML tags to be stripped, got %q", out)
+	}
+
+	out, err = tool.Execute(context.Background(), json.RawMessage(`{"url":"`+srv.URL+`/start"}`))
+	if err != nil {
+		t.Fatalf("Execute redirect: %v", err)
+	}
+	if !strings.Contains(out, "Final URL: "+srv.URL+"/echo") {
+		t.Fatalf("expected redirect to be followed, got %q", out)
+	}
+}
+
+func TestHTTPRequestTool_RedirectLimitAndTruncation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/loop" {
+			http.Redirect(w, r, "/loop", http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte(strings.Repeat("z", 2*maxHTTPRequestBodyBytes)))
+	}))
+	defer srv.Close()
+
+	tool := newTestHTTPRequestTool(true, nil, nil)
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{"url":"`+srv.URL+`/loop"}`)); err == nil || !strings.Contains(err.Error(), "HTTP_ERROR") {
+		t.Fatalf("expected redirect loop to fail, got %v", err)
+	}
+
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{"url":"`+srv.URL+`/big"}`))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if got := strings.Count(out, "z"); got != maxHTTPRequestBodyBytes {
+		t.Fatalf("expected body truncated to %d bytes, got %d", maxHTTPRequestBodyBytes, got)
+	}
+	if !strings.HasSuffix(out, "[body truncated to 51200 bytes]") {
+		t.Fatalf("expected truncation note, got tail %q", out[len(out)-60:])
+	}
+}
+
+func TestHTTPRequestTool_DomainPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		allowAll bool
+		allowed  []string
+		blocked  []string
+		url      string
+	}{
+		{name: "blocked subdomain", blocked: []string{"example.com"}, url: "https://api.example.com/x"},
+		{name: "not in allowlist", allowed: []string{"go.dev"}, url: "https://example.com"},
+		{name: "empty allowlist allows nothing", url: "https://example.com"},
+		{name: "blocklist wins over allowAll", allowAll: true, blocked: []string{"example.com"}, url: "https://example.com"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tool := newTestHTTPRequestTool(tc.allowAll, tc.allowed, tc.blocked)
+			_, err := tool.Execute(context.Background(), json.RawMessage(`{"url":"`+tc.url+`"}`))
+			te, ok := err.(*ToolError)
+			if !ok || te.Code != "DOMAIN_NOT_ALLOWED" {
+				t.Fatalf("expected DOMAIN_NOT_ALLOWED, got %v", err)
+			}
+		})
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	if _, err := newTestHTTPRequestTool(false, nil, nil).Execute(context.Background(), json.RawMessage(`{"url":"`+srv.URL+`"}`)); err == nil {
+		t.Fatalf("expected loopback request to be refused without allowAll or allowlist")
+	}
+	if _, err := newTestHTTPRequestTool(true, nil, nil).Execute(context.Background(), json.RawMessage(`{"url":"`+srv.URL+`"}`)); err != nil {
+		t.Fatalf("expected allowAll to permit loopback, got %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package tools
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// OutputLimitedTool is implemented by tools with their own output limit,
+// which every tool embedding base.BaseTool does. A limit of 0 defers to the
+// registry's.
+type OutputLimitedTool interface {
+	OutputLimit() int
+}
+
+// TruncateOutput shortens text to at most limit bytes, cutting on a UTF-8
+// boundary, and appends a note telling the model how much was left out. It
+// returns the text unchanged, and 0, when it fits or limit is not positive;
+// otherwise it also returns the number of bytes omitted.
+func TruncateOutput(text string, limit int) (string, int) {
+	if limit <= 0 || len(text) <= limit {
+		return text, 0
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	omitted := len(text) - cut
+	return text[:cut] + fmt.Sprintf("\n[Output truncated: %d bytes omitted. Use head/tail or filter to reduce output.]", omitted), omitted
+}
@@ -1,11 +1,64 @@
 package tools
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
+// PathEscape is the error code returned when a path resolves outside the
+// directory a tool is confined to.
+const PathEscape = "PATH_ESCAPE"
+
+// fileScope confines a file tool to a root directory. An empty root means
+// the current working directory at the time of the call.
+type fileScope struct {
+	root string
+}
+
+// FileToolOption configures the tools that take a path: read, write, edit,
+// directory_list, diff, file_structured, sqlite_query, git, scaffold, and
+// summarize.
+type FileToolOption func(*fileScope)
+
+// WithRoot confines the tool to root. Relative paths resolve against it, and
+// paths that lead outside it, directly or through a symlink, are rejected.
+func WithRoot(root string) FileToolOption {
+	return func(s *fileScope) {
+		s.root = strings.TrimSpace(root)
+	}
+}
+
+func newFileScope(opts []FileToolOption) fileScope {
+	var s fileScope
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s
+}
+
+// describe names the root in a tool description written for the default
+// scope of the current working directory.
+func (s fileScope) describe(desc string) string {
+	if s.root == "" {
+		return desc
+	}
+	return strings.Replace(desc, "the current working directory", s.root, 1)
+}
+
+func (s fileScope) resolve(path string) (string, string, error) {
+	return resolvePathInRoot(s.root, path)
+}
+
+// workspace returns the absolute root the tool is confined to.
+func (s fileScope) workspace() (string, error) {
+	if s.root == "" {
+		return currentWorkspaceRoot()
+	}
+	return filepath.Abs(filepath.Clean(s.root))
+}
+
 func currentWorkspaceRoot() (string, error) {
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -15,10 +68,19 @@ func currentWorkspaceRoot() (string, error) {
 	return filepath.Clean(cwd), nil
 }
 
-func resolveWorkspacePath(path string) (string, string, error) {
-	workspace, err := currentWorkspaceRoot()
-	if err != nil {
-		return "", "", err
+// resolvePathInRoot resolves path against root (the working directory when
+// empty) and returns the resolved path and the root. Paths outside the root,
+// including ones that only get there by following a symlink, yield a
+// PATH_ESCAPE error.
+func resolvePathInRoot(root, path string) (string, string, error) {
+	workspace := filepath.Clean(root)
+	if root == "" {
+		var err error
+		if workspace, err = currentWorkspaceRoot(); err != nil {
+			return "", "", err
+		}
+	} else if abs, err := filepath.Abs(workspace); err == nil {
+		workspace = abs
 	}
 
 	raw := strings.TrimSpace(path)
@@ -33,22 +95,132 @@ func resolveWorkspacePath(path string) (string, string, error) {
 	}
 	resolved = filepath.Clean(resolved)
 
-	rel, relErr := filepath.Rel(workspace, resolved)
-	if relErr != nil {
-		return "", "", NewToolError("PATH_RESOLUTION_FAILED", "Failed to resolve path relative to current working directory").
+	inside, err := withinRoot(workspace, resolved)
+	if err != nil {
+		return "", "", NewToolError("PATH_RESOLUTION_FAILED", "Failed to resolve path relative to the working directory").
 			WithDetail("path", raw).
 			WithDetail("workspace", workspace).
-			WithDetail("error", relErr.Error())
+			WithDetail("error", err.Error())
 	}
-	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
-		return "", "", NewToolError("PATH_OUTSIDE_WORKSPACE", "Path must stay within the current working directory").
-			WithDetail("path", raw).
-			WithDetail("workspace", workspace)
+	if !inside {
+		return "", "", pathEscapeError(raw, workspace)
+	}
+
+	// The path is lexically inside the root; make sure no symlink along it
+	// points back out, including links whose target does not exist yet.
+	realWorkspace, err := resolveSymlinks(workspace)
+	if err != nil {
+		realWorkspace = workspace
+	}
+	realPath, err := resolveSymlinks(resolved)
+	if err != nil {
+		return "", "", pathEscapeError(raw, workspace)
+	}
+	if inside, err := withinRoot(realWorkspace, realPath); err != nil || !inside {
+		return "", "", pathEscapeError(raw, workspace)
 	}
 
 	return resolved, workspace, nil
 }
 
+func pathEscapeError(path, workspace string) error {
+	return NewToolError(PathEscape, "Path must stay within the working directory").
+		WithDetail("path", path).
+		WithDetail("workspace", workspace)
+}
+
+func withinRoot(root, path string) (bool, error) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false, err
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)), nil
+}
+
+// maxSymlinks bounds how many links resolveSymlinks follows, as the kernel
+// does, so link cycles fail instead of looping
+const maxSymlinks = 255
+
+// resolveSymlinks follows every symlink along the absolute path, one
+// component at a time. Unlike filepath.EvalSymlinks it does not stop at the
+// first component that is missing: a dangling link is followed to where it
+// points, so a file about to be created through it is judged by its real
+// location. Components past the last existing one are joined as they are.
+func resolveSymlinks(path string) (string, error) {
+	volume := filepath.VolumeName(path)
+	resolved := volume + string(filepath.Separator)
+	pending := splitPath(path[len(volume):])
+	links := 0
+	for len(pending) > 0 {
+		part := pending[0]
+		pending = pending[1:]
+		if part == ".." {
+			resolved = filepath.Dir(resolved)
+			continue
+		}
+
+		next := filepath.Join(resolved, part)
+		info, err := os.Lstat(next)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				return "", err
+			}
+			resolved = next
+			continue
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+
+		links++
+		if links > maxSymlinks {
+			return "", &os.PathError{Op: "resolve", Path: path, Err: errors.New("too many levels of symbolic links")}
+		}
+		target, err := os.Readlink(next)
+		if err != nil {
+			return "", err
+		}
+		if filepath.IsAbs(target) {
+			volume = filepath.VolumeName(target)
+			resolved = volume + string(filepath.Separator)
+			target = target[len(volume):]
+		}
+		pending = append(splitPath(target), pending...)
+	}
+	return resolved, nil
+}
+
+func splitPath(path string) []string {
+	var parts []string
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		if part != "" && part != "." {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// writeFileNoFollow writes data to path like os.WriteFile, after resolving
+// the symlinks along it, and opens the file without following a symlink in
+// its place, so a link swapped in after the path was checked is not written
+// through.
+func writeFileNoFollow(path string, data []byte, perm os.FileMode) error {
+	realPath, err := resolveSymlinks(path)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(realPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|openNoFollow, perm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
 func displayPathForWorkspace(path, workspace string) string {
 	if path == "" || workspace == "" {
 		return path
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 )
@@ -29,14 +30,14 @@ func withWorkingDir(t *testing.T, dir string) {
 func expectOutsideWorkspaceError(t *testing.T, err error) {
 	t.Helper()
 	if err == nil {
-		t.Fatalf("expected PATH_OUTSIDE_WORKSPACE error, got nil")
+		t.Fatalf("expected PATH_ESCAPE error, got nil")
 	}
 	toolErr, ok := err.(*ToolError)
 	if !ok {
 		t.Fatalf("expected *ToolError, got %T (%v)", err, err)
 	}
-	if toolErr.Code != "PATH_OUTSIDE_WORKSPACE" {
-		t.Fatalf("expected PATH_OUTSIDE_WORKSPACE, got %q", toolErr.Code)
+	if toolErr.Code != PathEscape {
+		t.Fatalf("expected PATH_ESCAPE, got %q", toolErr.Code)
 	}
 }
 
@@ -105,3 +106,208 @@ func TestWriteTool_UsesWorkspaceRelativePath(t *testing.T) {
 		t.Fatalf("expected file in workspace: %v", err)
 	}
 }
+
+func TestFileTools_BlockDotDotTraversal(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "root")
+	if err := os.Mkdir(root, 0755); err != nil {
+		t.Fatalf("mkdir root: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(parent, "secret.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		tool   Tool
+		params string
+	}{
+		{"read", NewReadTool(WithRoot(root)), `{"path":"../secret.txt"}`},
+		{"read nested", NewReadTool(WithRoot(root)), `{"path":"sub/../../secret.txt"}`},
+		{"write", NewWriteTool(WithRoot(root)), `{"path":"../escaped.txt","content":"x"}`},
+		{"edit", NewEditTool(WithRoot(root)), `{"path":"../secret.txt","oldText":"secret","newText":"gone"}`},
+		{"directory_list", NewDirectoryListTool(WithRoot(root)), `{"input":"{\"path\":\"..\"}"}`},
+		{"diff", NewDiffTool(WithRoot(root)), `{"mode":"files","a":"../secret.txt","b":"../secret.txt"}`},
+		{"sqlite_query", NewSQLiteTool(WithRoot(root)), `{"database_path":"../secret.txt","query":"SELECT 1"}`},
+		{"file_structured", NewStructuredFileTool(WithRoot(root)), `{"file_path":"../secret.txt","format":"csv"}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.tool.Execute(context.Background(), json.RawMessage(tc.params))
+			expectOutsideWorkspaceError(t, err)
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(parent, "escaped.txt")); !os.IsNotExist(err) {
+		t.Fatalf("write escaped the root: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(parent, "secret.txt")); string(data) != "secret" {
+		t.Fatalf("edit escaped the root, file now %q", data)
+	}
+}
+
+func TestFileTools_BlockSymlinkEscapes(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "linkdir")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(root, "link.txt")); err != nil {
+		t.Fatalf("symlink file: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		tool   Tool
+		params string
+	}{
+		{"read file link", NewReadTool(WithRoot(root)), `{"path":"link.txt"}`},
+		{"read through dir link", NewReadTool(WithRoot(root)), `{"path":"linkdir/secret.txt"}`},
+		{"write new file through dir link", NewWriteTool(WithRoot(root)), `{"path":"linkdir/new/file.txt","content":"x"}`},
+		{"edit file link", NewEditTool(WithRoot(root)), `{"path":"link.txt","oldText":"secret","newText":"gone"}`},
+		{"directory_list dir link", NewDirectoryListTool(WithRoot(root)), `{"input":"{\"path\":\"linkdir\"}"}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.tool.Execute(context.Background(), json.RawMessage(tc.params))
+			expectOutsideWorkspaceError(t, err)
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(outside, "new")); !os.IsNotExist(err) {
+		t.Fatalf("write escaped through the symlink: %v", err)
+	}
+}
+
+func TestFileTools_BlockDanglingSymlinkEscapes(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	pwned := filepath.Join(outside, "pwned.txt")
+	if err := os.Symlink(pwned, filepath.Join(root, "link")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "newdir"), filepath.Join(root, "dirlink")); err != nil {
+		t.Fatalf("symlink dir: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		tool   Tool
+		params string
+	}{
+		{"write through dangling link", NewWriteTool(WithRoot(root)), `{"path":"link","content":"x"}`},
+		{"edit creates through dangling link", NewEditTool(WithRoot(root)), `{"path":"link","oldText":"","newText":"x"}`},
+		{"write under dangling dir link", NewWriteTool(WithRoot(root)), `{"path":"dirlink/file.txt","content":"x"}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.tool.Execute(context.Background(), json.RawMessage(tc.params))
+			expectOutsideWorkspaceError(t, err)
+		})
+	}
+
+	if _, err := os.Lstat(pwned); !os.IsNotExist(err) {
+		t.Fatalf("write escaped through the dangling symlink: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(outside, "newdir")); !os.IsNotExist(err) {
+		t.Fatalf("write escaped through the dangling dir symlink: %v", err)
+	}
+}
+
+func TestWriteFileNoFollowRefusesSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.txt")
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("O_NOFOLLOW is not available on Windows")
+	}
+
+	// A link swapped in after the path was resolved must not be followed
+	f, err := os.OpenFile(link, os.O_WRONLY|os.O_CREATE|openNoFollow, 0644)
+	if err == nil {
+		f.Close()
+		t.Fatal("opening a symlink with openNoFollow should fail")
+	}
+	if _, err := os.Lstat(target); !os.IsNotExist(err) {
+		t.Fatalf("open followed the symlink: %v", err)
+	}
+}
+
+func TestFileTools_AllowSymlinksWithinRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "real"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "real", "a.txt"), []byte("inside"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(root, "real"), filepath.Join(root, "alias")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	out, err := NewReadTool(WithRoot(root)).Execute(context.Background(), json.RawMessage(`{"path":"alias/a.txt"}`))
+	if err != nil {
+		t.Fatalf("read through in-root symlink: %v", err)
+	}
+	if !strings.Contains(out, "inside") {
+		t.Fatalf("unexpected output %q", out)
+	}
+	// Links within the root stay writable, dangling ones included
+	if err := os.Symlink(filepath.Join(root, "real", "b.txt"), filepath.Join(root, "b-link")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	write := NewWriteTool(WithRoot(root))
+	for _, path := range []string{"alias/a.txt", "b-link"} {
+		if _, err := write.Execute(context.Background(), json.RawMessage(`{"path":"`+path+`","content":"new"}`)); err != nil {
+			t.Fatalf("write through in-root symlink %s: %v", path, err)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "real", "b.txt")); string(data) != "new" {
+		t.Fatalf("write through the dangling in-root link should create its target, got %q", data)
+	}
+}
+
+func TestWithRoot_ResolvesRelativeToRootNotCwd(t *testing.T) {
+	root := t.TempDir()
+	withWorkingDir(t, t.TempDir())
+
+	tool := NewWriteTool(WithRoot(root))
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{"path":"note.txt","content":"hi"}`)); err != nil {
+		t.Fatalf("write tool error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "note.txt")); err != nil {
+		t.Fatalf("expected file under root: %v", err)
+	}
+	if !strings.Contains(tool.Description(), root) {
+		t.Fatalf("expected description to name the root, got %q", tool.Description())
+	}
+}
+
+func TestPathTools_ConfinedToRootNotWorkingDir(t *testing.T) {
+	// The working directory holds the files; the tools must still refuse
+	// them because they sit outside the configured root
+	cwd := t.TempDir()
+	withWorkingDir(t, cwd)
+	if err := os.WriteFile(filepath.Join(cwd, "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatalf("write a.txt: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(cwd, "app.db"), nil, 0644); err != nil {
+		t.Fatalf("write app.db: %v", err)
+	}
+	root := t.TempDir()
+	a := filepath.Join(cwd, "a.txt")
+	db := filepath.Join(cwd, "app.db")
+
+	diffParams, _ := json.Marshal(map[string]string{"mode": "files", "a": a, "b": a})
+	_, err := NewDiffTool(WithRoot(root)).Execute(context.Background(), diffParams)
+	expectOutsideWorkspaceError(t, err)
+	sqliteParams, _ := json.Marshal(map[string]string{"database_path": db, "query": "SELECT 1"})
+	_, err = NewSQLiteTool(WithRoot(root)).Execute(context.Background(), sqliteParams)
+	expectOutsideWorkspaceError(t, err)
+}
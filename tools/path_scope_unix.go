@@ -0,0 +1,8 @@
+//go:build !windows
+
+package tools
+
+import "syscall"
+
+// openNoFollow makes os.OpenFile fail on a symlink instead of following it
+const openNoFollow = syscall.O_NOFOLLOW
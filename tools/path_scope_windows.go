@@ -0,0 +1,7 @@
+//go:build windows
+
+package tools
+
+// openNoFollow is zero on Windows, which has no O_NOFOLLOW; paths are still
+// checked by resolvePathInRoot before they are opened
+const openNoFollow = 0
@@ -1,6 +1,12 @@
 package tools
 
-import "context"
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
 
 // ProgressReporter allows tools to report execution progress
 type ProgressReporter interface {
@@ -17,3 +23,108 @@ type ProgressableTool interface {
 	// ExecuteWithProgress executes the tool with progress reporting
 	ExecuteWithProgress(ctx context.Context, params string, reporter ProgressReporter) (string, error)
 }
+
+// ProgressFunc adapts a function to ProgressReporter. percent is negative
+// when the update carries no percentage.
+type ProgressFunc func(message string, percent float64)
+
+// ReportProgress reports a text progress update
+func (f ProgressFunc) ReportProgress(message string) {
+	f(message, -1)
+}
+
+// ReportProgressPercent reports progress with a percentage (0-1)
+func (f ProgressFunc) ReportProgressPercent(message string, percent float64) {
+	f(message, percent)
+}
+
+type progressReporterKey struct{}
+
+// WithProgressReporter returns a context that carries reporter for tools to use.
+func WithProgressReporter(ctx context.Context, reporter ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressReporterKey{}, reporter)
+}
+
+// ProgressReporterFromContext returns the reporter attached to ctx, if any.
+func ProgressReporterFromContext(ctx context.Context) (ProgressReporter, bool) {
+	reporter, ok := ctx.Value(progressReporterKey{}).(ProgressReporter)
+	return reporter, ok && reporter != nil
+}
+
+// ReportProgress sends a text update to the context's reporter. It is a no-op
+// when no reporter is attached.
+func ReportProgress(ctx context.Context, message string) {
+	if reporter, ok := ProgressReporterFromContext(ctx); ok {
+		reporter.ReportProgress(message)
+	}
+}
+
+// ReportProgressPercent sends a percentage update to the context's reporter.
+// It is a no-op when no reporter is attached.
+func ReportProgressPercent(ctx context.Context, message string, percent float64) {
+	if reporter, ok := ProgressReporterFromContext(ctx); ok {
+		reporter.ReportProgressPercent(message, percent)
+	}
+}
+
+// progressLineWriter copies output into buf and reports each complete line
+// as it is written.
+type progressLineWriter struct {
+	mu      sync.Mutex
+	buf     *bytes.Buffer
+	report  func(line string)
+	pending []byte
+}
+
+func (w *progressLineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	w.pending = append(w.pending, p...)
+	for {
+		idx := bytes.IndexByte(w.pending, '\n')
+		if idx < 0 {
+			break
+		}
+		w.report(string(bytes.TrimRight(w.pending[:idx], "\r")))
+		w.pending = w.pending[idx+1:]
+	}
+	return len(p), nil
+}
+
+// Flush reports any trailing output that did not end in a newline.
+func (w *progressLineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.pending) > 0 {
+		w.report(string(w.pending))
+		w.pending = nil
+	}
+}
+
+const progressReadInterval = 32 * 1024
+
+// progressReader reports the number of bytes read every progressReadInterval
+// bytes, with a percentage when the total size is known.
+type progressReader struct {
+	ctx        context.Context
+	r          io.Reader
+	total      int64
+	read       int64
+	lastReport int64
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.read += int64(n)
+	if r.read-r.lastReport >= progressReadInterval || (err == io.EOF && r.read > r.lastReport) {
+		r.lastReport = r.read
+		if r.total > 0 {
+			ReportProgressPercent(r.ctx, fmt.Sprintf("Downloaded %d of %d bytes", r.read, r.total), min(float64(r.read)/float64(r.total), 1))
+		} else {
+			ReportProgress(r.ctx, fmt.Sprintf("Downloaded %d bytes", r.read))
+		}
+	}
+	return n, err
+}
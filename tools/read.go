@@ -26,6 +26,7 @@ type ReadParams struct {
 // ReadTool reads file contents.
 type ReadTool struct {
 	base.BaseTool
+	fileScope
 }
 
 // Parameters returns the parameters struct
@@ -76,7 +77,7 @@ func (t *ReadTool) Execute(ctx context.Context, params json.RawMessage) (string,
 		return "", NewToolError("VALIDATION_FAILED", "Path cannot be empty")
 	}
 
-	resolvedPath, workspace, err := resolveWorkspacePath(args.Path)
+	resolvedPath, workspace, err := t.resolve(args.Path)
 	if err != nil {
 		return "", err
 	}
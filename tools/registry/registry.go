@@ -5,11 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"runtime"
 	"sync"
+	"time"
 
 	"github.com/nachoal/simple-agent-go/internal/schema"
 	"github.com/nachoal/simple-agent-go/internal/validator"
 	"github.com/nachoal/simple-agent-go/tools"
+	"github.com/nachoal/simple-agent-go/tools/base"
 )
 
 // ToolFactory is a function that creates a new tool instance
@@ -17,19 +20,47 @@ type ToolFactory func() tools.Tool
 
 // Registry manages tool registration and discovery
 type Registry struct {
-	mu        sync.RWMutex
-	tools     map[string]ToolFactory
-	generator *schema.Generator
-	validator *validator.Validator
+	mu             sync.RWMutex
+	tools          map[string]ToolFactory
+	generator      *schema.Generator
+	validator      *validator.Validator
+	maxConcurrency int
+	stats          *Stats
+	// showUnavailable keeps tools whose Available reports false in
+	// GetAllSchemas and Advertised
+	showUnavailable bool
+	// descriptions replaces tool descriptions by name; see SetDescription
+	descriptions map[string]string
+	// outputLimit caps tool output for tools without their own limit; 0
+	// means base.DefaultMaxOutputBytes and a negative value means no limit
+	outputLimit int
+}
+
+// RegistryOption configures a Registry created by New
+type RegistryOption func(*Registry)
+
+// WithGlobalOutputLimit caps the bytes of output any tool without its own
+// MaxOutputBytes returns to the model (default base.DefaultMaxOutputBytes).
+// A negative limit turns truncation off for those tools.
+func WithGlobalOutputLimit(bytes int) RegistryOption {
+	return func(r *Registry) {
+		r.outputLimit = bytes
+	}
 }
 
 // New creates a new tool registry
-func New() *Registry {
-	return &Registry{
-		tools:     make(map[string]ToolFactory),
-		generator: schema.NewGenerator(),
-		validator: validator.New(),
+func New(opts ...RegistryOption) *Registry {
+	r := &Registry{
+		tools:        make(map[string]ToolFactory),
+		generator:    schema.NewGenerator(),
+		validator:    validator.New(),
+		stats:        NewStats(),
+		descriptions: make(map[string]string),
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 // Register registers a tool factory with the given name
@@ -45,6 +76,93 @@ func (r *Registry) Register(name string, factory ToolFactory) error {
 	return nil
 }
 
+// SetMaxConcurrency limits how many tool calls ExecuteToolCalls runs at once.
+// A value <= 0 restores the default of runtime.GOMAXPROCS(0).
+func (r *Registry) SetMaxConcurrency(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maxConcurrency = n
+}
+
+// MaxConcurrency returns the effective tool call concurrency limit
+func (r *Registry) MaxConcurrency() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.maxConcurrency > 0 {
+		return r.maxConcurrency
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// SetOutputLimit changes the global output limit; see WithGlobalOutputLimit
+func (r *Registry) SetOutputLimit(bytes int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.outputLimit = bytes
+}
+
+// OutputLimit returns the most bytes of output the named tool may return to
+// the model, or 0 when its output is not limited
+func (r *Registry) OutputLimit(name string) int {
+	if tool, err := r.Get(name); err == nil {
+		if limited, ok := tool.(tools.OutputLimitedTool); ok && limited.OutputLimit() > 0 {
+			return limited.OutputLimit()
+		}
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	switch {
+	case r.outputLimit < 0:
+		return 0
+	case r.outputLimit > 0:
+		return r.outputLimit
+	default:
+		return base.DefaultMaxOutputBytes
+	}
+}
+
+// limitOutput truncates the model text of output to the tool's limit,
+// returning its original size when it had to and 0 otherwise
+func (r *Registry) limitOutput(name string, output *tools.ToolOutput) int {
+	size := len(output.ModelText)
+	text, omitted := tools.TruncateOutput(output.ModelText, r.OutputLimit(name))
+	if omitted == 0 {
+		return 0
+	}
+	output.ModelText = text
+	return size
+}
+
+// SetShowUnavailable controls whether tools that report themselves
+// unavailable (see tools.AvailableTool) are still advertised to the model.
+// They are hidden by default, since calling them only returns an error.
+func (r *Registry) SetShowUnavailable(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.showUnavailable = enabled
+}
+
+// Available reports whether the named tool is configured and able to run
+func (r *Registry) Available(name string) (bool, error) {
+	tool, err := r.Get(name)
+	if err != nil {
+		return false, err
+	}
+	return tools.IsAvailable(tool), nil
+}
+
+// Advertised reports whether the named tool should be offered to the model:
+// it is registered, and available unless SetShowUnavailable is on.
+func (r *Registry) Advertised(name string) bool {
+	available, err := r.Available(name)
+	if err != nil {
+		return false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return available || r.showUnavailable
+}
+
 // Get retrieves a tool by name
 func (r *Registry) Get(name string) (tools.Tool, error) {
 	r.mu.RLock()
@@ -70,6 +188,39 @@ func (r *Registry) List() []string {
 	return names
 }
 
+// SetDescription overrides the description sent to the model for the named
+// tool, e.g. to steer when it gets used. The tool's own Description is left
+// untouched; an empty desc removes the override. The tool does not need to
+// be registered yet.
+func (r *Registry) SetDescription(name, desc string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if desc == "" {
+		delete(r.descriptions, name)
+		return
+	}
+	r.descriptions[name] = desc
+}
+
+// Description returns the named tool's description, with any override from
+// SetDescription applied
+func (r *Registry) Description(name string) (string, error) {
+	tool, err := r.Get(name)
+	if err != nil {
+		return "", err
+	}
+	return r.describe(name, tool), nil
+}
+
+func (r *Registry) describe(name string, tool tools.Tool) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if desc, ok := r.descriptions[name]; ok {
+		return desc
+	}
+	return tool.Description()
+}
+
 // GetSchema returns the JSON schema for a tool
 func (r *Registry) GetSchema(name string) (map[string]interface{}, error) {
 	tool, err := r.Get(name)
@@ -79,19 +230,20 @@ func (r *Registry) GetSchema(name string) (map[string]interface{}, error) {
 
 	return r.generator.GenerateFunctionSchema(
 		tool.Name(),
-		tool.Description(),
+		r.describe(name, tool),
 		tool.Parameters(),
 	), nil
 }
 
-// GetAllSchemas returns schemas for all registered tools
+// GetAllSchemas returns schemas for all advertised tools
 func (r *Registry) GetAllSchemas() []map[string]interface{} {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+	names := r.List()
+	schemas := make([]map[string]interface{}, 0, len(names))
 
-	schemas := make([]map[string]interface{}, 0, len(r.tools))
-
-	for name := range r.tools {
+	for _, name := range names {
+		if !r.Advertised(name) {
+			continue
+		}
 		if schema, err := r.GetSchema(name); err == nil {
 			schemas = append(schemas, schema)
 		}
@@ -100,11 +252,20 @@ func (r *Registry) GetAllSchemas() []map[string]interface{} {
 	return schemas
 }
 
-// Execute executes a tool by name with the given parameters
+// Execute executes a tool by name with the given parameters and returns the
+// text meant for the model, truncated to the tool's output limit
 func (r *Registry) Execute(ctx context.Context, name string, params json.RawMessage) (string, error) {
+	output, err := r.execute(ctx, name, params)
+	if err == nil {
+		r.limitOutput(name, &output)
+	}
+	return output.ModelText, err
+}
+
+func (r *Registry) execute(ctx context.Context, name string, params json.RawMessage) (tools.ToolOutput, error) {
 	tool, err := r.Get(name)
 	if err != nil {
-		return "", err
+		return tools.ToolOutput{}, err
 	}
 
 	// Debug logging
@@ -132,48 +293,142 @@ func (r *Registry) Execute(ctx context.Context, name string, params json.RawMess
 	// Unmarshal parameters into the tool's parameter struct
 	paramStruct := tool.Parameters()
 	if err := json.Unmarshal(decodedParams, paramStruct); err != nil {
-		return "", tools.NewToolError("INVALID_PARAMS", "Failed to parse parameters").
+		return tools.ToolOutput{}, tools.NewToolError("INVALID_PARAMS", "Failed to parse parameters").
 			WithDetail("error", err.Error()).
 			WithDetail("raw_params", string(params))
 	}
 
 	// Validate parameters
 	if err := r.validator.Validate(paramStruct); err != nil {
-		return "", tools.NewToolError("VALIDATION_FAILED", "Parameter validation failed").
+		return tools.ToolOutput{}, tools.NewToolError("VALIDATION_FAILED", "Parameter validation failed").
 			WithDetail("error", err.Error())
 	}
 
 	// Execute the tool (use decoded params)
-	return tool.Execute(ctx, decodedParams)
+	if displayTool, ok := tool.(tools.DisplayTool); ok {
+		return displayTool.ExecuteWithDisplay(ctx, decodedParams)
+	}
+	var output string
+	if progressTool, ok := tool.(tools.ProgressableTool); ok {
+		if reporter, ok := tools.ProgressReporterFromContext(ctx); ok {
+			output, err = progressTool.ExecuteWithProgress(ctx, string(decodedParams), reporter)
+			return tools.ToolOutput{ModelText: output}, err
+		}
+	}
+	output, err = tool.Execute(ctx, decodedParams)
+	return tools.ToolOutput{ModelText: output}, err
 }
 
-// ExecuteToolCall executes a tool call
-func (r *Registry) ExecuteToolCall(ctx context.Context, call tools.ToolCall) tools.ToolResult {
-	result := tools.ToolResult{
+// ExecuteToolCall executes a tool call. A panic inside the tool is recovered
+// and reported as the result's error. When the tool asks for confirmation
+// and ctx carries a tools.Confirmer, the user is asked and an approved call
+// is run again with the approval attached. Every call is recorded in the
+// registry's stats. Tools implementing tools.DisplayTool also fill the
+// result's DisplayText. Result is truncated to the tool's output limit, with
+// the original size in OutputBytes; DisplayText is left whole.
+func (r *Registry) ExecuteToolCall(ctx context.Context, call tools.ToolCall) (result tools.ToolResult) {
+	result = tools.ToolResult{
 		ID:   call.ID,
 		Name: call.Name,
 	}
 
-	output, err := r.Execute(ctx, call.Name, call.Arguments)
+	start := time.Now()
+	defer func() {
+		if p := recover(); p != nil {
+			result.Result = ""
+			result.DisplayText = ""
+			result.Error = tools.NewToolError("TOOL_PANIC", fmt.Sprintf("Tool panicked: %v", p)).
+				WithDetail("tool", call.Name)
+		}
+		r.stats.Record(call.Name, time.Since(start), result.Error != nil, start)
+	}()
+
+	output, err := r.execute(ctx, call.Name, call.Arguments)
+	if request, token, ok := tools.ConfirmationRequest(err); ok {
+		// Ask the user when a confirmer is attached; otherwise the model
+		// sees the request as the tool's error.
+		if confirm, ok := tools.ConfirmerFromContext(ctx); ok {
+			if confirm(ctx, call, request) {
+				output, err = r.execute(tools.WithApproval(ctx, token), call.Name, call.Arguments)
+			} else {
+				err = tools.NewToolError("CONFIRMATION_DECLINED", "The user declined this action; it was not run").
+					WithDetail("request", request.Message)
+			}
+		}
+	}
 	if err != nil {
 		result.Error = err
 	} else {
-		result.Result = output
+		result.OutputBytes = r.limitOutput(call.Name, &output)
+		result.Result = output.ModelText
+		result.DisplayText = output.DisplayText
 	}
 
 	return result
 }
 
-// ExecuteToolCalls executes multiple tool calls concurrently
+// GetStats returns per-tool usage recorded since the registry was created or
+// last reset.
+func (r *Registry) GetStats() map[string]ToolStats {
+	return r.stats.Snapshot()
+}
+
+// ResetStats clears the recorded tool usage.
+func (r *Registry) ResetStats() {
+	r.stats.Reset()
+}
+
+// ExecuteToolCallWithProgress executes a tool call with reporter attached to
+// the context, so progress the tool reports reaches the caller while it runs.
+func (r *Registry) ExecuteToolCallWithProgress(ctx context.Context, call tools.ToolCall, reporter tools.ProgressReporter) tools.ToolResult {
+	if reporter != nil {
+		ctx = tools.WithProgressReporter(ctx, reporter)
+	}
+	return r.ExecuteToolCall(ctx, call)
+}
+
+// ExecuteToolCalls executes multiple tool calls concurrently, running at most
+// MaxConcurrency at a time. Results are returned in the order of calls. Once
+// ctx is cancelled, calls still waiting for a slot are not started and get a
+// CANCELLED error.
 func (r *Registry) ExecuteToolCalls(ctx context.Context, calls []tools.ToolCall) []tools.ToolResult {
+	return r.ExecuteToolCallsFunc(ctx, calls, func(tc tools.ToolCall) tools.ToolResult {
+		return r.ExecuteToolCall(ctx, tc)
+	})
+}
+
+// ExecuteToolCallsFunc runs exec for each call with the same bounds as
+// ExecuteToolCalls, for callers that do more around each call than execute
+// it, such as reporting its progress.
+func (r *Registry) ExecuteToolCallsFunc(ctx context.Context, calls []tools.ToolCall, exec func(tools.ToolCall) tools.ToolResult) []tools.ToolResult {
 	results := make([]tools.ToolResult, len(calls))
+	sem := make(chan struct{}, r.MaxConcurrency())
 	var wg sync.WaitGroup
 
 	for i, call := range calls {
+		if ctx.Err() == nil {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			for j := i; j < len(calls); j++ {
+				results[j] = tools.ToolResult{
+					ID:   calls[j].ID,
+					Name: calls[j].Name,
+					Error: tools.NewToolError("CANCELLED", "Not run: the request was cancelled").
+						WithDetail("error", err.Error()),
+				}
+			}
+			break
+		}
+
 		wg.Add(1)
 		go func(idx int, tc tools.ToolCall) {
 			defer wg.Done()
-			results[idx] = r.ExecuteToolCall(ctx, tc)
+			defer func() { <-sem }()
+			results[idx] = exec(tc)
 		}(i, call)
 	}
 
@@ -199,6 +454,33 @@ func List() []string {
 	return defaultRegistry.List()
 }
 
+// SetOutputLimit changes the default registry's global output limit
+func SetOutputLimit(bytes int) {
+	defaultRegistry.SetOutputLimit(bytes)
+}
+
+// SetShowUnavailable controls whether the default registry advertises
+// unavailable tools
+func SetShowUnavailable(enabled bool) {
+	defaultRegistry.SetShowUnavailable(enabled)
+}
+
+// Available reports whether the named tool in the default registry can run
+func Available(name string) (bool, error) {
+	return defaultRegistry.Available(name)
+}
+
+// SetDescription overrides a tool's description in the default registry
+func SetDescription(name, desc string) {
+	defaultRegistry.SetDescription(name, desc)
+}
+
+// Description returns a tool's description from the default registry,
+// including any override
+func Description(name string) (string, error) {
+	return defaultRegistry.Description(name)
+}
+
 // GetSchema returns the schema for a tool in the default registry
 func GetSchema(name string) (map[string]interface{}, error) {
 	return defaultRegistry.GetSchema(name)
@@ -219,11 +501,31 @@ func ExecuteToolCall(ctx context.Context, call tools.ToolCall) tools.ToolResult
 	return defaultRegistry.ExecuteToolCall(ctx, call)
 }
 
+// ExecuteToolCallWithProgress executes a tool call with progress reporting using the default registry
+func ExecuteToolCallWithProgress(ctx context.Context, call tools.ToolCall, reporter tools.ProgressReporter) tools.ToolResult {
+	return defaultRegistry.ExecuteToolCallWithProgress(ctx, call, reporter)
+}
+
 // ExecuteToolCalls executes multiple tool calls using the default registry
 func ExecuteToolCalls(ctx context.Context, calls []tools.ToolCall) []tools.ToolResult {
 	return defaultRegistry.ExecuteToolCalls(ctx, calls)
 }
 
+// SetMaxConcurrency limits concurrent tool calls on the default registry
+func SetMaxConcurrency(n int) {
+	defaultRegistry.SetMaxConcurrency(n)
+}
+
+// GetStats returns tool usage recorded by the default registry
+func GetStats() map[string]ToolStats {
+	return defaultRegistry.GetStats()
+}
+
+// ResetStats clears tool usage recorded by the default registry
+func ResetStats() {
+	defaultRegistry.ResetStats()
+}
+
 // Default returns the default registry instance
 func Default() *Registry {
 	return defaultRegistry
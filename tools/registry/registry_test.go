@@ -0,0 +1,431 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/tools"
+	"github.com/nachoal/simple-agent-go/tools/base"
+)
+
+type probeParams struct {
+	Index int `json:"index"`
+}
+
+type probeTool struct {
+	active    *int64
+	maxActive *int64
+}
+
+func (probeTool) Name() string            { return "probe" }
+func (probeTool) Description() string     { return "records concurrency" }
+func (probeTool) Parameters() interface{} { return &probeParams{} }
+
+func (p probeTool) Execute(ctx context.Context, params json.RawMessage) (string, error) {
+	var args probeParams
+	if err := json.Unmarshal(params, &args); err != nil {
+		return "", err
+	}
+
+	current := atomic.AddInt64(p.active, 1)
+	defer atomic.AddInt64(p.active, -1)
+	for {
+		seen := atomic.LoadInt64(p.maxActive)
+		if current <= seen || atomic.CompareAndSwapInt64(p.maxActive, seen, current) {
+			break
+		}
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	return fmt.Sprintf("result-%d", args.Index), nil
+}
+
+type panicTool struct{}
+
+func (panicTool) Name() string            { return "boom" }
+func (panicTool) Description() string     { return "always panics" }
+func (panicTool) Parameters() interface{} { return &probeParams{} }
+func (panicTool) Execute(context.Context, json.RawMessage) (string, error) {
+	panic("kaboom")
+}
+
+func TestExecuteToolCalls_BoundedAndOrdered(t *testing.T) {
+	var active, maxActive int64
+	r := New()
+	if err := r.Register("probe", func() tools.Tool {
+		return probeTool{active: &active, maxActive: &maxActive}
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	r.SetMaxConcurrency(3)
+
+	calls := make([]tools.ToolCall, 12)
+	for i := range calls {
+		calls[i] = tools.ToolCall{
+			ID:        fmt.Sprintf("call-%d", i),
+			Name:      "probe",
+			Arguments: json.RawMessage(fmt.Sprintf(`{"index":%d}`, i)),
+		}
+	}
+
+	results := r.ExecuteToolCalls(context.Background(), calls)
+
+	if got := atomic.LoadInt64(&maxActive); got > 3 {
+		t.Fatalf("expected at most 3 concurrent tool calls, observed %d", got)
+	}
+	if len(results) != len(calls) {
+		t.Fatalf("expected %d results, got %d", len(calls), len(results))
+	}
+	for i, result := range results {
+		if result.Error != nil {
+			t.Fatalf("result %d: unexpected error %v", i, result.Error)
+		}
+		if result.ID != calls[i].ID || result.Result != fmt.Sprintf("result-%d", i) {
+			t.Fatalf("result %d out of order: %+v", i, result)
+		}
+	}
+}
+
+func TestExecuteToolCalls_RecoversPanics(t *testing.T) {
+	var active, maxActive int64
+	r := New()
+	_ = r.Register("boom", func() tools.Tool { return panicTool{} })
+	_ = r.Register("probe", func() tools.Tool {
+		return probeTool{active: &active, maxActive: &maxActive}
+	})
+
+	results := r.ExecuteToolCalls(context.Background(), []tools.ToolCall{
+		{ID: "a", Name: "boom", Arguments: json.RawMessage(`{}`)},
+		{ID: "b", Name: "probe", Arguments: json.RawMessage(`{"index":1}`)},
+	})
+
+	toolErr, ok := results[0].Error.(*tools.ToolError)
+	if !ok || toolErr.Code != "TOOL_PANIC" {
+		t.Fatalf("expected TOOL_PANIC error, got %v", results[0].Error)
+	}
+	if results[1].Error != nil || results[1].Result != "result-1" {
+		t.Fatalf("expected sibling call to succeed, got %+v", results[1])
+	}
+}
+
+// blockingTool runs until its context is cancelled, counting the calls that
+// were started
+type blockingTool struct {
+	started *int64
+	running chan struct{}
+}
+
+func (blockingTool) Name() string            { return "block" }
+func (blockingTool) Description() string     { return "blocks until cancelled" }
+func (blockingTool) Parameters() interface{} { return &probeParams{} }
+func (b blockingTool) Execute(ctx context.Context, _ json.RawMessage) (string, error) {
+	atomic.AddInt64(b.started, 1)
+	b.running <- struct{}{}
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestExecuteToolCalls_SkipsQueuedCallsAfterCancel(t *testing.T) {
+	var started int64
+	running := make(chan struct{}, 4)
+	r := New()
+	_ = r.Register("block", func() tools.Tool {
+		return blockingTool{started: &started, running: running}
+	})
+	r.SetMaxConcurrency(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-running
+		cancel()
+	}()
+
+	done := make(chan []tools.ToolResult, 1)
+	go func() {
+		done <- r.ExecuteToolCalls(ctx, []tools.ToolCall{
+			{ID: "a", Name: "block", Arguments: json.RawMessage(`{}`)},
+			{ID: "b", Name: "block", Arguments: json.RawMessage(`{}`)},
+			{ID: "c", Name: "block", Arguments: json.RawMessage(`{}`)},
+		})
+	}()
+
+	var results []tools.ToolResult
+	select {
+	case results = <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("ExecuteToolCalls did not return after cancellation")
+	}
+	if got := atomic.LoadInt64(&started); got != 1 {
+		t.Fatalf("expected only the first call to start, got %d", got)
+	}
+	if results[0].Error == nil {
+		t.Fatalf("expected the running call to fail with the cancellation")
+	}
+	for _, result := range results[1:] {
+		toolErr, ok := result.Error.(*tools.ToolError)
+		if !ok || toolErr.Code != "CANCELLED" || result.ID == "" {
+			t.Fatalf("expected queued call to be CANCELLED, got %+v", result)
+		}
+	}
+}
+
+func TestMaxConcurrency_DefaultsToGOMAXPROCS(t *testing.T) {
+	r := New()
+	if r.MaxConcurrency() < 1 {
+		t.Fatalf("expected positive default concurrency, got %d", r.MaxConcurrency())
+	}
+	r.SetMaxConcurrency(2)
+	if r.MaxConcurrency() != 2 {
+		t.Fatalf("expected concurrency 2, got %d", r.MaxConcurrency())
+	}
+}
+
+// gatedTool asks for approval before doing anything.
+type gatedTool struct {
+	runs *int64
+}
+
+func (gatedTool) Name() string            { return "gated" }
+func (gatedTool) Description() string     { return "needs approval" }
+func (gatedTool) Parameters() interface{} { return &probeParams{} }
+func (g gatedTool) Execute(ctx context.Context, _ json.RawMessage) (string, error) {
+	if !tools.Approved(ctx, "token-1") {
+		return "", tools.NewToolError(tools.ConfirmationRequired, "needs approval").
+			WithDetail("approval_token", "token-1")
+	}
+	atomic.AddInt64(g.runs, 1)
+	return "done", nil
+}
+
+func TestExecuteToolCall_AsksConfirmerForApproval(t *testing.T) {
+	for _, approve := range []bool{true, false} {
+		var runs int64
+		r := New()
+		if err := r.Register("gated", func() tools.Tool { return gatedTool{runs: &runs} }); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+		var asked []string
+		ctx := tools.WithConfirmer(context.Background(), func(_ context.Context, call tools.ToolCall, request *tools.ToolError) bool {
+			asked = append(asked, call.Name+": "+request.Message)
+			return approve
+		})
+
+		result := r.ExecuteToolCall(ctx, tools.ToolCall{ID: "1", Name: "gated", Arguments: json.RawMessage(`{}`)})
+
+		if len(asked) != 1 || asked[0] != "gated: needs approval" {
+			t.Fatalf("approve=%v: expected one confirmation prompt, got %v", approve, asked)
+		}
+		if approve && (result.Error != nil || result.Result != "done" || runs != 1) {
+			t.Fatalf("expected approved call to run, got %+v (runs=%d)", result, runs)
+		}
+		if !approve {
+			te, ok := result.Error.(*tools.ToolError)
+			if !ok || te.Code != "CONFIRMATION_DECLINED" || runs != 0 {
+				t.Fatalf("expected declined call not to run, got %+v (runs=%d)", result, runs)
+			}
+		}
+	}
+}
+
+func TestExecuteToolCall_ReturnsConfirmationRequestWithoutConfirmer(t *testing.T) {
+	var runs int64
+	r := New()
+	if err := r.Register("gated", func() tools.Tool { return gatedTool{runs: &runs} }); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	result := r.ExecuteToolCall(context.Background(), tools.ToolCall{ID: "1", Name: "gated", Arguments: json.RawMessage(`{}`)})
+	if _, _, ok := tools.ConfirmationRequest(result.Error); !ok || runs != 0 {
+		t.Fatalf("expected the confirmation request to reach the caller, got %+v", result)
+	}
+}
+
+type displayTool struct{}
+
+func (displayTool) Name() string            { return "display" }
+func (displayTool) Description() string     { return "returns separate display text" }
+func (displayTool) Parameters() interface{} { return &probeParams{} }
+func (displayTool) Execute(context.Context, json.RawMessage) (string, error) {
+	return "short", nil
+}
+func (displayTool) ExecuteWithDisplay(context.Context, json.RawMessage) (tools.ToolOutput, error) {
+	return tools.ToolOutput{ModelText: "short", DisplayText: "long\nverbose\noutput"}, nil
+}
+
+func TestExecuteToolCall_SplitsModelAndDisplayText(t *testing.T) {
+	r := New()
+	if err := r.Register("display", func() tools.Tool { return displayTool{} }); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	result := r.ExecuteToolCall(context.Background(), tools.ToolCall{ID: "1", Name: "display", Arguments: json.RawMessage(`{}`)})
+	if result.Error != nil {
+		t.Fatalf("unexpected error: %v", result.Error)
+	}
+	if result.Result != "short" || result.DisplayText != "long\nverbose\noutput" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if result.Display() != "long\nverbose\noutput" {
+		t.Fatalf("expected Display to prefer DisplayText, got %q", result.Display())
+	}
+
+	output, err := r.Execute(context.Background(), "display", json.RawMessage(`{}`))
+	if err != nil || output != "short" {
+		t.Fatalf("expected Execute to return the model text, got %q, %v", output, err)
+	}
+}
+
+type credentialTool struct {
+	configured bool
+}
+
+func (credentialTool) Name() string            { return "needs_key" }
+func (credentialTool) Description() string     { return "needs an API key" }
+func (credentialTool) Parameters() interface{} { return &probeParams{} }
+func (c credentialTool) Available() bool       { return c.configured }
+func (credentialTool) Execute(context.Context, json.RawMessage) (string, error) {
+	return "", tools.NewToolError("NOT_CONFIGURED", "missing key")
+}
+
+func schemaNames(schemas []map[string]interface{}) map[string]bool {
+	names := make(map[string]bool)
+	for _, schema := range schemas {
+		if fn, ok := schema["function"].(map[string]interface{}); ok {
+			names[fn["name"].(string)] = true
+		}
+	}
+	return names
+}
+
+func TestGetAllSchemasHidesUnavailableTools(t *testing.T) {
+	r := New()
+	configured := false
+	_ = r.Register("needs_key", func() tools.Tool { return credentialTool{configured: configured} })
+	_ = r.Register("probe", func() tools.Tool { return probeTool{} })
+
+	names := schemaNames(r.GetAllSchemas())
+	if names["needs_key"] || !names["probe"] {
+		t.Fatalf("expected only probe to be advertised, got %v", names)
+	}
+	if r.Advertised("needs_key") {
+		t.Fatal("expected the unconfigured tool not to be advertised")
+	}
+	if available, err := r.Available("needs_key"); err != nil || available {
+		t.Fatalf("expected needs_key to be unavailable, got %v (%v)", available, err)
+	}
+
+	r.SetShowUnavailable(true)
+	if names := schemaNames(r.GetAllSchemas()); !names["needs_key"] {
+		t.Fatalf("expected the unconfigured tool with the filter off, got %v", names)
+	}
+
+	r.SetShowUnavailable(false)
+	configured = true
+	if names := schemaNames(r.GetAllSchemas()); !names["needs_key"] {
+		t.Fatalf("expected the tool once configured, got %v", names)
+	}
+}
+
+func TestSetDescriptionOverridesSchemaOnly(t *testing.T) {
+	r := New()
+	_ = r.Register("probe", func() tools.Tool { return probeTool{} })
+
+	r.SetDescription("probe", "Use only for load tests")
+	schema, err := r.GetSchema("probe")
+	if err != nil {
+		t.Fatalf("GetSchema: %v", err)
+	}
+	if desc := schema["function"].(map[string]interface{})["description"]; desc != "Use only for load tests" {
+		t.Fatalf("expected the override in the schema, got %v", desc)
+	}
+	all := r.GetAllSchemas()
+	if desc := all[0]["function"].(map[string]interface{})["description"]; desc != "Use only for load tests" {
+		t.Fatalf("expected the override in GetAllSchemas, got %v", desc)
+	}
+	if tool, _ := r.Get("probe"); tool.Description() != "records concurrency" {
+		t.Fatalf("the tool's own description changed: %q", tool.Description())
+	}
+
+	r.SetDescription("probe", "")
+	if desc, _ := r.Description("probe"); desc != "records concurrency" {
+		t.Fatalf("expected the override to be cleared, got %q", desc)
+	}
+	if _, err := r.Description("missing"); err == nil {
+		t.Fatal("expected an error for an unknown tool")
+	}
+}
+
+type floodTool struct {
+	base.BaseTool
+	size int
+}
+
+func (f *floodTool) Parameters() interface{} { return &probeParams{} }
+func (f *floodTool) Execute(context.Context, json.RawMessage) (string, error) {
+	return strings.Repeat("x", f.size), nil
+}
+
+func TestExecuteToolCall_TruncatesLargeOutput(t *testing.T) {
+	const size = 100 * 1024
+	newFlood := func(limit int) ToolFactory {
+		return func() tools.Tool {
+			return &floodTool{BaseTool: base.BaseTool{ToolName: "flood", MaxOutputBytes: limit}, size: size}
+		}
+	}
+	call := tools.ToolCall{ID: "1", Name: "flood", Arguments: json.RawMessage(`{}`)}
+
+	r := New()
+	r.Register("flood", newFlood(0))
+	result := r.ExecuteToolCall(context.Background(), call)
+	if result.Error != nil {
+		t.Fatalf("unexpected error: %v", result.Error)
+	}
+	if result.OutputBytes != size {
+		t.Fatalf("expected OutputBytes %d, got %d", size, result.OutputBytes)
+	}
+	note := fmt.Sprintf("\n[Output truncated: %d bytes omitted. Use head/tail or filter to reduce output.]", size-base.DefaultMaxOutputBytes)
+	if !strings.HasSuffix(result.Result, note) || len(result.Result) != base.DefaultMaxOutputBytes+len(note) {
+		t.Fatalf("expected %d bytes plus the truncation note, got %d bytes ending %q", base.DefaultMaxOutputBytes, len(result.Result), result.Result[len(result.Result)-100:])
+	}
+	output, err := r.Execute(context.Background(), "flood", json.RawMessage(`{}`))
+	if err != nil || output != result.Result {
+		t.Fatalf("expected Execute to truncate the same way, got %d bytes, %v", len(output), err)
+	}
+
+	r = New(WithGlobalOutputLimit(1024))
+	r.Register("flood", newFlood(0))
+	if got := r.OutputLimit("flood"); got != 1024 {
+		t.Fatalf("expected the global limit, got %d", got)
+	}
+	if result := r.ExecuteToolCall(context.Background(), call); !strings.HasPrefix(result.Result, strings.Repeat("x", 1024)+"\n[Output truncated: 101376 bytes omitted.") {
+		t.Fatalf("expected output cut at the global limit, got %d bytes", len(result.Result))
+	}
+
+	r = New(WithGlobalOutputLimit(1024))
+	r.Register("flood", newFlood(2048))
+	if got := r.OutputLimit("flood"); got != 2048 {
+		t.Fatalf("expected the tool's own limit to win, got %d", got)
+	}
+
+	r = New(WithGlobalOutputLimit(-1))
+	r.Register("flood", newFlood(0))
+	if result := r.ExecuteToolCall(context.Background(), call); len(result.Result) != size || result.OutputBytes != 0 {
+		t.Fatalf("expected a negative limit to disable truncation, got %d bytes, OutputBytes %d", len(result.Result), result.OutputBytes)
+	}
+}
+
+func TestTruncateOutputKeepsRunesWhole(t *testing.T) {
+	text, omitted := tools.TruncateOutput("héllo", 2)
+	if omitted != 5 || !strings.HasPrefix(text, "h\n[Output truncated: 5 bytes omitted.") {
+		t.Fatalf("expected the cut before the two-byte rune, got %q, %d", text, omitted)
+	}
+	if text, omitted := tools.TruncateOutput("short", 10); text != "short" || omitted != 0 {
+		t.Fatalf("expected short output unchanged, got %q, %d", text, omitted)
+	}
+}
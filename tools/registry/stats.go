@@ -0,0 +1,222 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// ToolStats summarizes the calls made to one tool.
+type ToolStats struct {
+	Calls         int           `json:"calls"`
+	Errors        int           `json:"errors"`
+	TotalDuration time.Duration `json:"total_duration_ns"`
+	LastCalled    time.Time     `json:"last_called"`
+}
+
+// AverageDuration returns the mean execution time per call.
+func (s ToolStats) AverageDuration() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.TotalDuration / time.Duration(s.Calls)
+}
+
+// Stats records per-tool usage. It is safe for concurrent use.
+type Stats struct {
+	mu     sync.Mutex
+	byTool map[string]ToolStats
+}
+
+// NewStats creates an empty stats recorder
+func NewStats() *Stats {
+	return &Stats{byTool: make(map[string]ToolStats)}
+}
+
+// Record adds one call of the named tool.
+func (s *Stats) Record(name string, duration time.Duration, failed bool, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.byTool[name]
+	stats.Calls++
+	stats.TotalDuration += duration
+	if failed {
+		stats.Errors++
+	}
+	if at.After(stats.LastCalled) {
+		stats.LastCalled = at
+	}
+	s.byTool[name] = stats
+}
+
+// Snapshot returns a copy of the recorded stats keyed by tool name.
+func (s *Stats) Snapshot() map[string]ToolStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := make(map[string]ToolStats, len(s.byTool))
+	for name, stats := range s.byTool {
+		snapshot[name] = stats
+	}
+	return snapshot
+}
+
+// Reset clears all recorded stats.
+func (s *Stats) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byTool = make(map[string]ToolStats)
+}
+
+// MergeStats returns base with the counts from add folded in.
+func MergeStats(base, add map[string]ToolStats) map[string]ToolStats {
+	merged := make(map[string]ToolStats, len(base)+len(add))
+	for name, stats := range base {
+		merged[name] = stats
+	}
+	for name, stats := range add {
+		current := merged[name]
+		current.Calls += stats.Calls
+		current.Errors += stats.Errors
+		current.TotalDuration += stats.TotalDuration
+		if stats.LastCalled.After(current.LastCalled) {
+			current.LastCalled = stats.LastCalled
+		}
+		merged[name] = current
+	}
+	return merged
+}
+
+// LoadStatsFile reads stats saved by SaveStatsFile. A missing file yields
+// empty stats.
+func LoadStatsFile(path string) (map[string]ToolStats, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]ToolStats{}, nil
+		}
+		return nil, fmt.Errorf("failed to read tool stats: %w", err)
+	}
+
+	stats := map[string]ToolStats{}
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, fmt.Errorf("failed to parse tool stats %s: %w", path, err)
+	}
+	return stats, nil
+}
+
+// UpdateStatsFile folds add into the stats saved at path. The read, merge,
+// and write happen under a lock file, so sessions finishing at the same time
+// do not lose each other's counts.
+func UpdateStatsFile(path string, add map[string]ToolStats) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create tool stats directory: %w", err)
+	}
+	unlock, err := lockStatsFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	saved, err := LoadStatsFile(path)
+	if err != nil {
+		return err
+	}
+	return SaveStatsFile(path, MergeStats(saved, add))
+}
+
+const (
+	statsLockWait  = 5 * time.Second
+	statsLockStale = 30 * time.Second // Older locks were left by a crashed process
+)
+
+// lockStatsFile takes an exclusive lock on path by creating path.lock, waiting
+// up to statsLockWait for another process to release it. The returned func
+// releases the lock.
+func lockStatsFile(path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(statsLockWait)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock tool stats: %w", err)
+		}
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > statsLockStale {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("failed to lock tool stats: %s is held by another session", lockPath)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// SaveStatsFile writes stats as JSON, replacing the file atomically.
+func SaveStatsFile(path string, stats map[string]ToolStats) error {
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create tool stats directory: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write tool stats: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write tool stats: %w", err)
+	}
+	return nil
+}
+
+// WriteStatsTable writes stats as an aligned table, most-used tools first.
+func WriteStatsTable(w io.Writer, stats map[string]ToolStats) error {
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if stats[names[i]].Calls != stats[names[j]].Calls {
+			return stats[names[i]].Calls > stats[names[j]].Calls
+		}
+		return names[i] < names[j]
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TOOL\tCALLS\tERRORS\tAVG\tLAST CALLED")
+	for _, name := range names {
+		s := stats[name]
+		lastCalled := "-"
+		if !s.LastCalled.IsZero() {
+			lastCalled = s.LastCalled.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\n", name, s.Calls, s.Errors, formatStatsDuration(s.AverageDuration()), lastCalled)
+	}
+	return tw.Flush()
+}
+
+func formatStatsDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(100 * time.Microsecond).String()
+	default:
+		return d.Round(time.Microsecond).String()
+	}
+}
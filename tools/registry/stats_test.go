@@ -0,0 +1,117 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/tools"
+)
+
+func TestExecuteToolCall_RecordsStats(t *testing.T) {
+	var active, maxActive int64
+	r := New()
+	_ = r.Register("boom", func() tools.Tool { return panicTool{} })
+	_ = r.Register("probe", func() tools.Tool {
+		return probeTool{active: &active, maxActive: &maxActive}
+	})
+
+	before := time.Now()
+	r.ExecuteToolCalls(context.Background(), []tools.ToolCall{
+		{ID: "a", Name: "probe", Arguments: json.RawMessage(`{"index":1}`)},
+		{ID: "b", Name: "probe", Arguments: json.RawMessage(`{"index":2}`)},
+		{ID: "c", Name: "boom", Arguments: json.RawMessage(`{}`)},
+	})
+
+	stats := r.GetStats()
+	probe := stats["probe"]
+	if probe.Calls != 2 || probe.Errors != 0 {
+		t.Fatalf("unexpected probe stats: %+v", probe)
+	}
+	if probe.AverageDuration() < 10*time.Millisecond || probe.LastCalled.Before(before) {
+		t.Fatalf("expected duration and timestamp to be recorded: %+v", probe)
+	}
+	if boom := stats["boom"]; boom.Calls != 1 || boom.Errors != 1 {
+		t.Fatalf("expected panic to count as an error: %+v", boom)
+	}
+
+	r.ResetStats()
+	if len(r.GetStats()) != 0 {
+		t.Fatalf("expected stats cleared, got %+v", r.GetStats())
+	}
+}
+
+func TestStatsFileRoundTripAndMerge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "tool_stats.json")
+	if stats, err := LoadStatsFile(path); err != nil || len(stats) != 0 {
+		t.Fatalf("expected empty stats for missing file, got %+v (err %v)", stats, err)
+	}
+
+	older := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	saved := map[string]ToolStats{
+		"read": {Calls: 3, Errors: 1, TotalDuration: 30 * time.Millisecond, LastCalled: newer},
+	}
+	if err := SaveStatsFile(path, saved); err != nil {
+		t.Fatalf("SaveStatsFile: %v", err)
+	}
+	loaded, err := LoadStatsFile(path)
+	if err != nil {
+		t.Fatalf("LoadStatsFile: %v", err)
+	}
+
+	merged := MergeStats(loaded, map[string]ToolStats{
+		"read": {Calls: 1, TotalDuration: 10 * time.Millisecond, LastCalled: older},
+		"bash": {Calls: 1, TotalDuration: 2 * time.Second, LastCalled: older},
+	})
+	read := merged["read"]
+	if read.Calls != 4 || read.Errors != 1 || read.AverageDuration() != 10*time.Millisecond || !read.LastCalled.Equal(newer) {
+		t.Fatalf("unexpected merged read stats: %+v", read)
+	}
+
+	var table strings.Builder
+	if err := WriteStatsTable(&table, merged); err != nil {
+		t.Fatalf("WriteStatsTable: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "read ") || !strings.HasPrefix(lines[2], "bash ") {
+		t.Fatalf("expected most-used tool first:\n%s", table.String())
+	}
+	if !strings.Contains(lines[2], "2s") {
+		t.Fatalf("expected average duration in table:\n%s", table.String())
+	}
+}
+
+func TestUpdateStatsFileKeepsConcurrentUpdates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tool_stats.json")
+
+	const sessions = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, sessions)
+	for i := 0; i < sessions; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- UpdateStatsFile(path, map[string]ToolStats{"read": {Calls: 1, TotalDuration: time.Millisecond}})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("UpdateStatsFile: %v", err)
+		}
+	}
+
+	saved, err := LoadStatsFile(path)
+	if err != nil {
+		t.Fatalf("LoadStatsFile: %v", err)
+	}
+	if got := saved["read"]; got.Calls != sessions || got.TotalDuration != sessions*time.Millisecond {
+		t.Fatalf("expected every session's call to be kept, got %+v", got)
+	}
+}
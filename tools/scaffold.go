@@ -0,0 +1,181 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nachoal/simple-agent-go/internal/prompttemplate"
+	"github.com/nachoal/simple-agent-go/internal/userpaths"
+	"github.com/nachoal/simple-agent-go/tools/base"
+)
+
+type ScaffoldParams struct {
+	Template string            `json:"template" schema:"required" description:"Name of a template in ~/.simple-agent/templates, with or without the .tmpl extension"`
+	Dest     string            `json:"dest" schema:"required" description:"Path of the file to create (relative or absolute)"`
+	Vars     map[string]string `json:"vars,omitempty" description:"Values for the variables the template uses, as a JSON object (optional)"`
+	Force    bool              `json:"force,omitempty" description:"Overwrite dest if it already exists (default: false)"`
+}
+
+// ScaffoldTool creates files from the Go text/template files the user keeps
+// in ~/.simple-agent/templates, the same templates query --template and
+// /template use.
+type ScaffoldTool struct {
+	base.BaseTool
+	fileScope
+
+	// templatesDir is a field so tests can use their own directory.
+	templatesDir func() (string, error)
+}
+
+// NewScaffoldTool creates a scaffold tool. Like the other file tools, it
+// writes within the current working directory unless WithRoot says otherwise.
+func NewScaffoldTool(opts ...FileToolOption) Tool {
+	scope := newFileScope(opts)
+	return &ScaffoldTool{
+		BaseTool: base.BaseTool{
+			ToolName: "scaffold",
+			ToolDesc: scope.describe("Create a file within the current working directory from a named template in ~/.simple-agent/templates, filling in its variables. Refuses to overwrite an existing file unless force is true. Example: {\"template\":\"go-handler\",\"dest\":\"api/users.go\",\"vars\":{\"name\":\"Users\"}}"),
+		},
+		fileScope:    scope,
+		templatesDir: userpaths.TemplatesDir,
+	}
+}
+
+// Parameters returns the parameters struct
+func (t *ScaffoldTool) Parameters() interface{} {
+	return &ScaffoldParams{}
+}
+
+// Execute renders the template and writes it to dest
+func (t *ScaffoldTool) Execute(ctx context.Context, params json.RawMessage) (string, error) {
+	var args ScaffoldParams
+	if err := json.Unmarshal(params, &args); err != nil {
+		return "", NewToolError("INVALID_PARAMS", "Failed to parse parameters").
+			WithDetail("error", err.Error())
+	}
+	if strings.TrimSpace(args.Template) == "" {
+		return "", NewToolError("VALIDATION_FAILED", "Template cannot be empty")
+	}
+	if strings.TrimSpace(args.Dest) == "" {
+		return "", NewToolError("VALIDATION_FAILED", "Dest cannot be empty")
+	}
+
+	dir, err := t.templatesDir()
+	if err != nil {
+		return "", NewToolError("NOT_AVAILABLE", "Templates directory unavailable").
+			WithDetail("error", err.Error())
+	}
+	text, err := prompttemplate.LoadNamed(dir, args.Template)
+	if err != nil {
+		toolErr := NewToolError("NOT_FOUND", err.Error())
+		if names, _ := prompttemplate.List(dir); len(names) > 0 {
+			toolErr = toolErr.WithDetail("available", strings.Join(names, ", "))
+		}
+		return "", toolErr
+	}
+	rendered, err := prompttemplate.RenderFile(args.Template, text, args.Vars)
+	if err != nil {
+		return "", NewToolError("VALIDATION_FAILED", err.Error())
+	}
+
+	resolvedPath, workspace, err := t.resolve(args.Dest)
+	if err != nil {
+		return "", err
+	}
+	displayPath := displayPathForWorkspace(resolvedPath, workspace)
+
+	existed := false
+	if info, err := os.Stat(resolvedPath); err == nil {
+		if info.IsDir() {
+			return "", NewToolError("IS_DIRECTORY", "Dest is a directory").
+				WithDetail("path", displayPath)
+		}
+		if !args.Force {
+			return "", NewToolError("FILE_EXISTS", "Dest already exists").
+				WithDetail("path", displayPath).
+				WithDetail("help", "Pass \"force\": true to overwrite it")
+		}
+		existed = true
+	}
+
+	if err := os.MkdirAll(filepath.Dir(resolvedPath), 0755); err != nil {
+		return "", NewToolError("MKDIR_ERROR", "Failed to create parent directories").
+			WithDetail("error", err.Error()).
+			WithDetail("path", displayPath)
+	}
+	if err := writeFileAtomic(resolvedPath, []byte(rendered), args.Force); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			// Created by something else since the check above
+			return "", NewToolError("FILE_EXISTS", "Dest already exists").
+				WithDetail("path", displayPath).
+				WithDetail("help", "Pass \"force\": true to overwrite it")
+		}
+		return "", NewToolError("WRITE_ERROR", "Failed to write file").
+			WithDetail("error", err.Error()).
+			WithDetail("path", displayPath)
+	}
+
+	verb := "Created"
+	if existed {
+		verb = "Overwrote"
+	}
+	return fmt.Sprintf("%s %s from template %s (%d bytes)", verb, displayPath, args.Template, len(rendered)), nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and moves it
+// into place, so readers never see a partly written file. With overwrite, an
+// existing file is replaced and keeps its permissions; without it, the file
+// is only created if nothing is at path by then, and an error wrapping
+// fs.ErrExist is returned otherwise.
+func writeFileAtomic(path string, data []byte, overwrite bool) error {
+	perm := os.FileMode(0644)
+	if overwrite {
+		if info, err := os.Stat(path); err == nil {
+			perm = info.Mode().Perm()
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if overwrite {
+		return os.Rename(tmp.Name(), path)
+	}
+
+	// A hard link, unlike a rename, fails if path exists
+	err = os.Link(tmp.Name(), path)
+	if err == nil || errors.Is(err, fs.ErrExist) {
+		return err
+	}
+	// The filesystem has no hard links; create the file exclusively instead
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL|openNoFollow, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// newTestScaffoldTool confines a scaffold tool to a temp workspace and gives
+// it a templates directory holding a handler template
+func newTestScaffoldTool(t *testing.T) (*ScaffoldTool, string) {
+	t.Helper()
+	templates := t.TempDir()
+	handler := "package {{.pkg}}\n\n// {{.name}}Handler serves /{{.name}}\nfunc {{.name}}Handler() {}\n"
+	if err := os.WriteFile(filepath.Join(templates, "handler.tmpl"), []byte(handler), 0644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	workspace := t.TempDir()
+	tool := NewScaffoldTool(WithRoot(workspace)).(*ScaffoldTool)
+	tool.templatesDir = func() (string, error) { return templates, nil }
+	return tool, workspace
+}
+
+func TestScaffoldTool_RendersTemplateIntoWorkspace(t *testing.T) {
+	tool, workspace := newTestScaffoldTool(t)
+
+	out, err := tool.Execute(context.Background(), []byte(`{"template":"handler","dest":"api/users.go","vars":{"pkg":"api","name":"Users"}}`))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !strings.HasPrefix(out, "Created api/users.go") {
+		t.Fatalf("unexpected result %q", out)
+	}
+
+	data, err := os.ReadFile(filepath.Join(workspace, "api", "users.go"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	want := "package api\n\n// UsersHandler serves /Users\nfunc UsersHandler() {}\n"
+	if string(data) != want {
+		t.Fatalf("expected %q, got %q", want, data)
+	}
+	entries, _ := os.ReadDir(filepath.Join(workspace, "api"))
+	if len(entries) != 1 {
+		t.Fatalf("expected no temporary files left behind, got %v", entries)
+	}
+}
+
+func TestScaffoldTool_RefusesOverwriteWithoutForce(t *testing.T) {
+	tool, workspace := newTestScaffoldTool(t)
+	dest := filepath.Join(workspace, "users.go")
+	if err := os.WriteFile(dest, []byte("keep me"), 0644); err != nil {
+		t.Fatalf("write existing file: %v", err)
+	}
+
+	_, err := tool.Execute(context.Background(), []byte(`{"template":"handler.tmpl","dest":"users.go","vars":{"pkg":"api","name":"Users"}}`))
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != "FILE_EXISTS" {
+		t.Fatalf("expected FILE_EXISTS, got %v", err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "keep me" {
+		t.Fatalf("expected the existing file untouched, got %q", data)
+	}
+
+	out, err := tool.Execute(context.Background(), []byte(`{"template":"handler","dest":"users.go","vars":{"pkg":"api","name":"Users"},"force":true}`))
+	if err != nil || !strings.HasPrefix(out, "Overwrote users.go") {
+		t.Fatalf("expected force to overwrite, got %q, %v", out, err)
+	}
+}
+
+func TestScaffoldTool_ForceKeepsFileMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows has no Unix permission bits")
+	}
+	tool, workspace := newTestScaffoldTool(t)
+	dest := filepath.Join(workspace, "run.sh")
+	if err := os.WriteFile(dest, []byte("old"), 0750); err != nil {
+		t.Fatalf("write existing file: %v", err)
+	}
+	if err := os.Chmod(dest, 0750); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+
+	if _, err := tool.Execute(context.Background(), []byte(`{"template":"handler","dest":"run.sh","vars":{"pkg":"api","name":"Users"},"force":true}`)); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	info, err := os.Stat(dest)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode().Perm() != 0750 {
+		t.Fatalf("expected the file to keep mode 0750, got %v", info.Mode().Perm())
+	}
+}
+
+func TestWriteFileAtomic_DoesNotClobberFileCreatedAfterCheck(t *testing.T) {
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "users.go")
+	// Stands in for a file created between the tool's existence check and
+	// its write
+	if err := os.WriteFile(dest, []byte("keep me"), 0644); err != nil {
+		t.Fatalf("write existing file: %v", err)
+	}
+
+	if err := writeFileAtomic(dest, []byte("new"), false); !errors.Is(err, fs.ErrExist) {
+		t.Fatalf("expected fs.ErrExist, got %v", err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "keep me" {
+		t.Fatalf("expected the existing file untouched, got %q", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("expected no temporary files left behind, got %v", entries)
+	}
+
+	fresh := filepath.Join(dir, "fresh.go")
+	if err := writeFileAtomic(fresh, []byte("new"), false); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if data, _ := os.ReadFile(fresh); string(data) != "new" {
+		t.Fatalf("unexpected content %q", data)
+	}
+}
+
+func TestScaffoldTool_RejectsUnsafeTemplatesAndDestinations(t *testing.T) {
+	tool, _ := newTestScaffoldTool(t)
+	cases := map[string]string{
+		"template path":     `{"template":"../handler","dest":"a.go","vars":{"pkg":"a","name":"A"}}`,
+		"unknown template":  `{"template":"missing","dest":"a.go"}`,
+		"missing variable":  `{"template":"handler","dest":"a.go","vars":{"pkg":"a"}}`,
+		"dest outside root": `{"template":"handler","dest":"../escape.go","vars":{"pkg":"a","name":"A"}}`,
+	}
+	for name, params := range cases {
+		if _, err := tool.Execute(context.Background(), []byte(params)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nachoal/simple-agent-go/tools/base"
+	_ "modernc.org/sqlite"
+)
+
+const (
+	defaultSQLiteMaxRows = 100
+	maxSQLiteMaxRows     = 1000
+	maxSQLiteCellChars   = 200
+)
+
+// sqliteReadKeywords are the leading keywords of statements that only read data.
+var sqliteReadKeywords = []string{"SELECT", "WITH", "EXPLAIN", "VALUES", "PRAGMA"}
+
+type SQLiteParams struct {
+	DatabasePath string `json:"database_path" schema:"required" description:"Path to the SQLite database file"`
+	Query        string `json:"query" schema:"required" description:"SQL statement to execute"`
+	MaxRows      int    `json:"max_rows,omitempty" description:"Maximum rows to return (optional, default 100)"`
+	WriteMode    bool   `json:"write_mode,omitempty" description:"Set to true to allow INSERT/UPDATE/DELETE and other modifying statements"`
+}
+
+// SQLiteTool queries SQLite databases within the working directory.
+type SQLiteTool struct {
+	base.BaseTool
+	fileScope
+}
+
+// Parameters returns the parameters struct
+func (t *SQLiteTool) Parameters() interface{} {
+	return &SQLiteParams{}
+}
+
+// Execute runs the query and returns rows as a markdown table.
+func (t *SQLiteTool) Execute(ctx context.Context, params json.RawMessage) (string, error) {
+	var args SQLiteParams
+	if err := json.Unmarshal(params, &args); err != nil {
+		return "", NewToolError("INVALID_PARAMS", "Failed to parse parameters").
+			WithDetail("error", err.Error())
+	}
+
+	query := strings.TrimSpace(args.Query)
+	if query == "" {
+		return "", NewToolError("VALIDATION_FAILED", "Query is required")
+	}
+	if strings.TrimSpace(args.DatabasePath) == "" {
+		return "", NewToolError("VALIDATION_FAILED", "Database path is required")
+	}
+
+	dbPath, workspace, err := t.resolve(args.DatabasePath)
+	if err != nil {
+		return "", err
+	}
+	displayPath := displayPathForWorkspace(dbPath, workspace)
+
+	readOnly := isSQLiteReadStatement(query)
+	if !readOnly && !args.WriteMode {
+		return "", NewToolError("WRITE_NOT_ALLOWED", "Statement modifies the database; set write_mode to true to run it").
+			WithDetail("query", query)
+	}
+	if _, err := os.Stat(dbPath); err != nil && (readOnly || !errors.Is(err, os.ErrNotExist)) {
+		return "", NewToolError("FILE_NOT_FOUND", "Database file not found").
+			WithDetail("path", displayPath)
+	}
+
+	maxRows := args.MaxRows
+	if maxRows <= 0 {
+		maxRows = defaultSQLiteMaxRows
+	}
+	if maxRows > maxSQLiteMaxRows {
+		maxRows = maxSQLiteMaxRows
+	}
+
+	db, err := sql.Open("sqlite", sqliteDSN(dbPath, readOnly))
+	if err != nil {
+		return "", NewToolError("SQL_ERROR", err.Error())
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: readOnly})
+	if err != nil {
+		return "", NewToolError("SQL_ERROR", err.Error())
+	}
+	defer tx.Rollback()
+
+	if readOnly {
+		return querySQLiteRows(ctx, tx, query, maxRows)
+	}
+
+	result, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		return "", NewToolError("SQL_ERROR", err.Error())
+	}
+	if err := tx.Commit(); err != nil {
+		return "", NewToolError("SQL_ERROR", err.Error())
+	}
+	affected, _ := result.RowsAffected()
+	return fmt.Sprintf("Statement executed. Rows affected: %d", affected), nil
+}
+
+// sqliteDSN builds a file URI; read-only connections also set query_only so
+// statements hidden behind a leading WITH cannot modify data.
+func sqliteDSN(path string, readOnly bool) string {
+	values := url.Values{}
+	values.Add("_pragma", "busy_timeout(5000)")
+	if readOnly {
+		values.Set("mode", "ro")
+		values.Add("_pragma", "query_only(1)")
+	}
+	return "file:" + (&url.URL{Path: path}).EscapedPath() + "?" + values.Encode()
+}
+
+func querySQLiteRows(ctx context.Context, tx *sql.Tx, query string, maxRows int) (string, error) {
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return "", NewToolError("SQL_ERROR", err.Error())
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", NewToolError("SQL_ERROR", err.Error())
+	}
+	if len(columns) == 0 {
+		return "Statement executed. No rows returned.", nil
+	}
+
+	var table [][]string
+	truncated := false
+	for rows.Next() {
+		if len(table) == maxRows {
+			truncated = true
+			break
+		}
+		values := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return "", NewToolError("SQL_ERROR", err.Error())
+		}
+		row := make([]string, len(columns))
+		for i, v := range values {
+			row[i] = formatSQLiteCell(v)
+		}
+		table = append(table, row)
+	}
+	if err := rows.Err(); err != nil {
+		return "", NewToolError("SQL_ERROR", err.Error())
+	}
+
+	return formatSQLiteTable(columns, table, truncated, maxRows), nil
+}
+
+// isSQLiteReadStatement reports whether the query starts with a keyword that
+// only reads data, ignoring leading comments.
+func isSQLiteReadStatement(query string) bool {
+	q := strings.TrimSpace(query)
+	for {
+		switch {
+		case strings.HasPrefix(q, "--"):
+			if idx := strings.Index(q, "\n"); idx >= 0 {
+				q = strings.TrimSpace(q[idx+1:])
+				continue
+			}
+			return false
+		case strings.HasPrefix(q, "/*"):
+			if idx := strings.Index(q, "*/"); idx >= 0 {
+				q = strings.TrimSpace(q[idx+2:])
+				continue
+			}
+			return false
+		}
+		break
+	}
+
+	keyword := strings.ToUpper(strings.TrimLeft(q, "("))
+	if idx := strings.IndexFunc(keyword, func(r rune) bool {
+		return !(r >= 'A' && r <= 'Z')
+	}); idx >= 0 {
+		keyword = keyword[:idx]
+	}
+	return containsString(sqliteReadKeywords, keyword)
+}
+
+func formatSQLiteCell(v interface{}) string {
+	var s string
+	switch val := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		if utf8.Valid(val) {
+			s = string(val)
+		} else {
+			s = "x'" + hex.EncodeToString(val) + "'"
+		}
+	default:
+		s = fmt.Sprint(val)
+	}
+
+	return markdownCell(s, maxSQLiteCellChars)
+}
+
+func formatSQLiteTable(columns []string, rows [][]string, truncated bool, maxRows int) string {
+	var b strings.Builder
+	b.WriteString("| " + strings.Join(columns, " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat("---|", len(columns)) + "\n")
+	for _, row := range rows {
+		b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+	}
+
+	if len(rows) == 0 {
+		b.WriteString("\n(0 rows)")
+	} else if truncated {
+		b.WriteString(fmt.Sprintf("\n[showing first %d rows]", maxRows))
+	} else {
+		b.WriteString(fmt.Sprintf("\n(%d rows)", len(rows)))
+	}
+	return b.String()
+}
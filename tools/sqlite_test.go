@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newSQLiteFixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	withWorkingDir(t, dir)
+
+	db, err := sql.Open("sqlite", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatalf("open fixture: %v", err)
+	}
+	defer db.Close()
+	stmts := []string{
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, bio TEXT)`,
+		`INSERT INTO users (name, bio) VALUES ('ada', 'a|b'), ('grace', NULL), ('linus', '` + strings.Repeat("x", 300) + `')`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("fixture %q: %v", stmt, err)
+		}
+	}
+	return dir
+}
+
+func TestSQLiteTool_SelectFormatsMarkdownTable(t *testing.T) {
+	newSQLiteFixture(t)
+	tool := NewSQLiteTool()
+
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{"database_path":"app.db","query":"SELECT id, name, bio FROM users ORDER BY id"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"| id | name | bio |", "| 1 | ada | a\\|b |", "| 2 | grace | NULL |", strings.Repeat("x", 200) + "...", "(3 rows)"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, strings.Repeat("x", 201)) {
+		t.Fatalf("expected long cell to be truncated:\n%s", out)
+	}
+
+	out, err = tool.Execute(context.Background(), json.RawMessage(`{"database_path":"app.db","query":"SELECT name FROM users ORDER BY id","max_rows":2}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(out, "linus") || !strings.Contains(out, "[showing first 2 rows]") {
+		t.Fatalf("expected max_rows to limit output:\n%s", out)
+	}
+}
+
+func TestSQLiteTool_WritesRequireWriteMode(t *testing.T) {
+	newSQLiteFixture(t)
+	tool := NewSQLiteTool()
+
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"database_path":"app.db","query":"DELETE FROM users"}`))
+	if err == nil || !strings.Contains(err.Error(), "WRITE_NOT_ALLOWED") {
+		t.Fatalf("expected WRITE_NOT_ALLOWED, got %v", err)
+	}
+
+	// A CTE-prefixed write is classified as a read and must be stopped by the read-only connection.
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"database_path":"app.db","query":"WITH x AS (SELECT 1) DELETE FROM users"}`))
+	if err == nil || !strings.Contains(err.Error(), "SQL_ERROR") {
+		t.Fatalf("expected read-only connection to reject write, got %v", err)
+	}
+
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{"database_path":"app.db","query":"DELETE FROM users WHERE name = 'grace'","write_mode":true}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "Statement executed. Rows affected: 1" {
+		t.Fatalf("unexpected write output: %q", out)
+	}
+}
+
+func TestSQLiteTool_ReturnsSQLiteErrorVerbatim(t *testing.T) {
+	newSQLiteFixture(t)
+	tool := NewSQLiteTool()
+
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"database_path":"app.db","query":"SELECT nope FROM users"}`))
+	if err == nil || !strings.Contains(err.Error(), "no such column: nope") {
+		t.Fatalf("expected sqlite error message, got %v", err)
+	}
+}
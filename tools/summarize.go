@@ -0,0 +1,294 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/tools/base"
+	"golang.org/x/net/html"
+)
+
+const (
+	defaultSummarizeChunkChars = 8000
+	maxSummarizeChunks         = 20
+	maxSummarizeBytes          = 2 * 1024 * 1024
+	summarizeFetchTimeout      = 30 * time.Second
+)
+
+type SummarizeParams struct {
+	Source string `json:"source" schema:"required" description:"File path (within the working directory) or http(s) URL to summarize"`
+	Focus  string `json:"focus,omitempty" description:"What the summary should concentrate on (optional)"`
+}
+
+// SummarizeTool condenses a long file or web page by summarizing it in chunks
+// with the agent's own model and then combining the chunk summaries.
+type SummarizeTool struct {
+	base.BaseTool
+	fileScope
+	client       *http.Client
+	allowedHosts []string
+	chunkChars   int
+}
+
+// Parameters returns the parameters struct
+func (t *SummarizeTool) Parameters() interface{} {
+	return &SummarizeParams{}
+}
+
+// Execute loads the source, summarizes each chunk, and returns the combined
+// summary. Content that needs more than maxSummarizeChunks chunks is cut off
+// and the result says so.
+func (t *SummarizeTool) Execute(ctx context.Context, params json.RawMessage) (string, error) {
+	var args SummarizeParams
+	if err := json.Unmarshal(params, &args); err != nil {
+		return "", NewToolError("INVALID_PARAMS", "Failed to parse parameters").
+			WithDetail("error", err.Error())
+	}
+	source := strings.TrimSpace(args.Source)
+	if source == "" {
+		return "", NewToolError("VALIDATION_FAILED", "Source cannot be empty")
+	}
+
+	chat, ok := ChatClientFromContext(ctx)
+	if !ok {
+		return "", NewToolError("NO_CLIENT", "summarize needs a model client and can only run inside an agent")
+	}
+
+	text, name, err := t.load(ctx, source)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(text) == "" {
+		return "", NewToolError("EMPTY_SOURCE", "Source has no text to summarize").
+			WithDetail("source", name)
+	}
+
+	chunkChars := t.chunkChars
+	if chunkChars <= 0 {
+		chunkChars = defaultSummarizeChunkChars
+	}
+	chunks := chunkText(text, chunkChars)
+	truncated := len(chunks) > maxSummarizeChunks
+	if truncated {
+		chunks = chunks[:maxSummarizeChunks]
+	}
+
+	summaries := make([]string, 0, len(chunks))
+	for i, chunk := range chunks {
+		ReportProgressPercent(ctx, fmt.Sprintf("Summarizing chunk %d of %d", i+1, len(chunks)), float64(i)/float64(len(chunks)))
+		prompt := fmt.Sprintf("Summarize part %d of %d of %s.%s\n\n%s", i+1, len(chunks), name, focusInstruction(args.Focus), chunk)
+		summary, err := summarizeWith(ctx, chat, prompt)
+		if err != nil {
+			return "", NewToolError("SUMMARIZE_FAILED", fmt.Sprintf("Failed to summarize chunk %d of %d", i+1, len(chunks))).
+				WithDetail("error", err.Error())
+		}
+		summaries = append(summaries, summary)
+	}
+
+	final := summaries[0]
+	if len(summaries) > 1 {
+		ReportProgressPercent(ctx, "Combining chunk summaries", 1)
+		var b strings.Builder
+		b.WriteString(fmt.Sprintf("These are summaries of consecutive parts of %s. Combine them into one coherent summary without repeating points.%s", name, focusInstruction(args.Focus)))
+		for i, summary := range summaries {
+			b.WriteString(fmt.Sprintf("\n\nPart %d:\n%s", i+1, summary))
+		}
+		final, err = summarizeWith(ctx, chat, b.String())
+		if err != nil {
+			return "", NewToolError("SUMMARIZE_FAILED", "Failed to combine chunk summaries").
+				WithDetail("error", err.Error())
+		}
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Summary of %s (%d chunk", name, len(chunks)))
+	if len(chunks) != 1 {
+		output.WriteString("s")
+	}
+	output.WriteString("):\n\n")
+	output.WriteString(final)
+	if truncated {
+		output.WriteString(fmt.Sprintf("\n\n[only the first %d chunks were summarized]", maxSummarizeChunks))
+	}
+	return output.String(), nil
+}
+
+// load returns the source's text and a name for it in prompts: the URL for
+// web pages, the workspace-relative path for files.
+func (t *SummarizeTool) load(ctx context.Context, source string) (string, string, error) {
+	if target, err := url.Parse(source); err == nil && (target.Scheme == "http" || target.Scheme == "https") {
+		if target.Host == "" {
+			return "", "", NewToolError("VALIDATION_FAILED", "URL must be an absolute http or https URL").
+				WithDetail("url", source)
+		}
+		text, err := t.fetch(ctx, target)
+		return text, target.String(), err
+	}
+
+	resolvedPath, workspace, err := t.resolve(source)
+	if err != nil {
+		return "", "", err
+	}
+	displayPath := displayPathForWorkspace(resolvedPath, workspace)
+	info, err := os.Stat(resolvedPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", "", NewToolError("FILE_NOT_FOUND", "File does not exist").
+				WithDetail("path", displayPath)
+		}
+		return "", "", NewToolError("ACCESS_ERROR", "Cannot access file").
+			WithDetail("path", displayPath).
+			WithDetail("error", err.Error())
+	}
+	if info.IsDir() {
+		return "", "", NewToolError("IS_DIRECTORY", "Path points to a directory, not a file").
+			WithDetail("path", displayPath)
+	}
+	if info.Size() > maxSummarizeBytes {
+		return "", "", NewToolError("FILE_TOO_LARGE", fmt.Sprintf("File is larger than %d bytes", maxSummarizeBytes)).
+			WithDetail("path", displayPath)
+	}
+	content, err := os.ReadFile(resolvedPath)
+	if err != nil {
+		return "", "", NewToolError("READ_ERROR", "Error reading file").
+			WithDetail("path", displayPath).
+			WithDetail("error", err.Error())
+	}
+	if !utf8.Valid(content) {
+		return "", "", NewToolError("UNSUPPORTED_CONTENT", "File is not UTF-8 text").
+			WithDetail("path", displayPath)
+	}
+	return strings.ReplaceAll(string(content), "\r\n", "\n"), displayPath, nil
+}
+
+// fetch downloads a page and returns its readable text, extracted the same
+// way as web_fetch but without the character cap.
+func (t *SummarizeTool) fetch(ctx context.Context, target *url.URL) (string, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, summarizeFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, "GET", target.String(), nil)
+	if err != nil {
+		return "", NewToolError("REQUEST_ERROR", "Failed to create request").
+			WithDetail("error", err.Error())
+	}
+	req.Header.Set("User-Agent", webFetchUserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,text/*;q=0.8")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", NewToolError("HTTP_ERROR", "Failed to fetch URL").
+			WithDetail("url", target.String()).
+			WithDetail("error", err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return "", NewToolError("HTTP_ERROR", fmt.Sprintf("Server returned %s", resp.Status)).
+			WithDetail("url", resp.Request.URL.String())
+	}
+
+	body := io.LimitReader(resp.Body, maxSummarizeBytes)
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "" || mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		doc, err := html.Parse(body)
+		if err != nil {
+			return "", NewToolError("PARSE_ERROR", "Failed to parse HTML").
+				WithDetail("error", err.Error())
+		}
+		return extractReadableText(doc), nil
+	case strings.HasPrefix(mediaType, "text/") || mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return "", NewToolError("READ_ERROR", "Failed to read response").
+				WithDetail("error", err.Error())
+		}
+		return string(data), nil
+	default:
+		return "", NewToolError("UNSUPPORTED_CONTENT", "URL is not a text document").
+			WithDetail("url", resp.Request.URL.String()).
+			WithDetail("content_type", mediaType)
+	}
+}
+
+// chunkText splits text into pieces of at most size characters, breaking
+// between paragraphs where it can and between lines or mid-line otherwise.
+func chunkText(text string, size int) []string {
+	var chunks []string
+	var current strings.Builder
+	currentLen := 0
+	flush := func() {
+		if chunk := strings.TrimSpace(current.String()); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		current.Reset()
+		currentLen = 0
+	}
+	add := func(piece, sep string) {
+		n := utf8.RuneCountInString(piece)
+		if currentLen > 0 && currentLen+len(sep)+n > size {
+			flush()
+		}
+		if currentLen > 0 {
+			current.WriteString(sep)
+			currentLen += len(sep)
+		}
+		current.WriteString(piece)
+		currentLen += n
+	}
+
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		if utf8.RuneCountInString(paragraph) <= size {
+			add(paragraph, "\n\n")
+			continue
+		}
+		for _, line := range strings.Split(paragraph, "\n") {
+			runes := []rune(line)
+			for len(runes) > size {
+				add(string(runes[:size]), "\n")
+				runes = runes[size:]
+			}
+			add(string(runes), "\n")
+		}
+	}
+	flush()
+	return chunks
+}
+
+func focusInstruction(focus string) string {
+	if focus = strings.TrimSpace(focus); focus == "" {
+		return ""
+	}
+	return " Focus on: " + focus + "."
+}
+
+func summarizeWith(ctx context.Context, chat ChatClient, prompt string) (string, error) {
+	resp, err := chat.Client.Chat(ctx, &llm.ChatRequest{
+		Model: chat.Model,
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: llm.StringPtr("You write concise, faithful summaries. Keep names, numbers, and conclusions; never add information that is not in the text.")},
+			{Role: llm.RoleUser, Content: llm.StringPtr(prompt)},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("model returned no choices")
+	}
+	summary := strings.TrimSpace(llm.GetStringValue(resp.Choices[0].Message.Content))
+	if summary == "" {
+		return "", fmt.Errorf("model returned an empty summary")
+	}
+	return summary, nil
+}
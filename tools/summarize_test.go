@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// summarizeStubClient answers chunk prompts with "summary N" and records the
+// prompt that combines them.
+type summarizeStubClient struct {
+	mu       sync.Mutex
+	prompts  []string
+	combined string
+}
+
+func (c *summarizeStubClient) Chat(ctx context.Context, req *llm.ChatRequest) (*llm.ChatResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prompt := llm.GetStringValue(req.Messages[len(req.Messages)-1].Content)
+	c.prompts = append(c.prompts, prompt)
+
+	reply := fmt.Sprintf("summary %d", len(c.prompts))
+	if strings.HasPrefix(prompt, "These are summaries") {
+		c.combined = prompt
+		reply = "combined summary"
+	}
+	return &llm.ChatResponse{Choices: []llm.Choice{{Message: llm.Message{Role: llm.RoleAssistant, Content: llm.StringPtr(reply)}}}}, nil
+}
+
+func (c *summarizeStubClient) ChatStream(ctx context.Context, req *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (c *summarizeStubClient) ListModels(ctx context.Context) ([]llm.Model, error) { return nil, nil }
+
+func (c *summarizeStubClient) GetModel(ctx context.Context, modelID string) (*llm.Model, error) {
+	return nil, nil
+}
+
+func (c *summarizeStubClient) Close() error { return nil }
+
+func TestSummarizeCombinesChunkSummaries(t *testing.T) {
+	root := t.TempDir()
+	var doc strings.Builder
+	for i := 0; i < 40; i++ {
+		fmt.Fprintf(&doc, "Paragraph %d talks about topic %d in some detail.\n\n", i, i)
+	}
+	if err := os.WriteFile(filepath.Join(root, "long.txt"), []byte(doc.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tool := NewSummarizeTool(WithRoot(root)).(*SummarizeTool)
+	tool.chunkChars = 500
+	client := &summarizeStubClient{}
+	ctx := WithChatClient(context.Background(), client, "stub-model")
+
+	params, _ := json.Marshal(SummarizeParams{Source: "long.txt", Focus: "topics"})
+	out, err := tool.Execute(ctx, params)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	chunks := len(client.prompts) - 1
+	if chunks < 2 {
+		t.Fatalf("expected the file to be split into several chunks, got %d prompts", len(client.prompts))
+	}
+	for i := 1; i <= chunks; i++ {
+		if !strings.Contains(client.combined, fmt.Sprintf("Part %d:\nsummary %d", i, i)) {
+			t.Fatalf("combine prompt is missing chunk %d's summary:\n%s", i, client.combined)
+		}
+	}
+	if !strings.Contains(client.prompts[0], "Focus on: topics.") || !strings.Contains(client.prompts[0], "Paragraph 0 talks") {
+		t.Fatalf("unexpected first chunk prompt:\n%s", client.prompts[0])
+	}
+	want := fmt.Sprintf("Summary of long.txt (%d chunks):\n\ncombined summary", chunks)
+	if out != want {
+		t.Fatalf("got %q, want %q", out, want)
+	}
+}
+
+func TestSummarizeRequiresChatClient(t *testing.T) {
+	params, _ := json.Marshal(SummarizeParams{Source: "anything.txt"})
+	_, err := NewSummarizeTool(WithRoot(t.TempDir())).Execute(context.Background(), params)
+	if err == nil || !strings.Contains(err.Error(), "NO_CLIENT") {
+		t.Fatalf("expected NO_CLIENT error, got %v", err)
+	}
+}
+
+func TestChunkTextSplitsLongLines(t *testing.T) {
+	chunks := chunkText(strings.Repeat("x", 25), 10)
+	if len(chunks) != 3 || chunks[0] != strings.Repeat("x", 10) || chunks[2] != "xxxxx" {
+		t.Fatalf("unexpected chunks %q", chunks)
+	}
+}
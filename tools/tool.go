@@ -21,6 +21,39 @@ type Tool interface {
 	Parameters() interface{}
 }
 
+// ToolOutput is a tool result with separate text for the model and the user.
+// ModelText is what the LLM sees; DisplayText, when set, is shown in the UI
+// instead, so verbose output such as full build logs can reach the user
+// without filling the model's context.
+type ToolOutput struct {
+	ModelText   string
+	DisplayText string
+}
+
+// DisplayTool is an optional interface for tools that return separate model
+// and display text
+type DisplayTool interface {
+	Tool
+	// ExecuteWithDisplay executes the tool and returns both texts
+	ExecuteWithDisplay(ctx context.Context, params json.RawMessage) (ToolOutput, error)
+}
+
+// AvailableTool is an optional interface for tools that depend on credentials
+// or other setup. Available reports whether the tool can run; tools that do
+// not implement it are always available.
+type AvailableTool interface {
+	Tool
+	Available() bool
+}
+
+// IsAvailable reports whether t can run, honoring AvailableTool
+func IsAvailable(t Tool) bool {
+	if at, ok := t.(AvailableTool); ok {
+		return at.Available()
+	}
+	return true
+}
+
 // ToolError represents a structured error from a tool
 type ToolError struct {
 	Code    string                 `json:"code"`
@@ -59,8 +92,21 @@ type ToolCall struct {
 
 // ToolResult represents the result of a tool execution
 type ToolResult struct {
-	ID     string `json:"id"`
-	Name   string `json:"name"`
-	Result string `json:"result"`
-	Error  error  `json:"error,omitempty"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Result      string `json:"result"`                 // Text sent to the model
+	DisplayText string `json:"display_text,omitempty"` // Text shown to the user, if different
+	Error       error  `json:"error,omitempty"`
+	// OutputBytes is the size of the tool's output when it was truncated to
+	// fit the output limit, and 0 otherwise
+	OutputBytes int `json:"output_bytes,omitempty"`
+}
+
+// Display returns the text to show the user: DisplayText when the tool set
+// one, otherwise Result.
+func (r ToolResult) Display() string {
+	if r.DisplayText != "" {
+		return r.DisplayText
+	}
+	return r.Result
 }
@@ -0,0 +1,577 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nachoal/simple-agent-go/tools/base"
+	"golang.org/x/net/html"
+)
+
+const (
+	webFetchTimeout      = 20 * time.Second
+	webFetchRobotsAgent  = "simple-agent"
+	maxWebFetchChars     = 8000
+	maxWebFetchBodyBytes = 2 * 1024 * 1024
+	maxRobotsBodyBytes   = 512 * 1024
+	webFetchUserAgent    = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36"
+)
+
+// webFetchSkipTags are elements whose contents never count as readable text.
+var webFetchSkipTags = map[string]bool{
+	"head": true, "script": true, "style": true, "nav": true, "noscript": true, "template": true, "svg": true,
+}
+
+// webFetchBlockTags are elements that start a new line in the extracted text.
+var webFetchBlockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true, "dd": true, "div": true,
+	"dl": true, "dt": true, "figcaption": true, "figure": true, "footer": true, "form": true, "h1": true,
+	"h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "header": true, "hr": true, "li": true,
+	"main": true, "ol": true, "p": true, "pre": true, "section": true, "table": true, "td": true, "th": true,
+	"tr": true, "ul": true,
+}
+
+type WebFetchParams struct {
+	URL      string `json:"url" schema:"required" description:"http(s) URL of the page to read"`
+	Selector string `json:"selector,omitempty" description:"CSS selector limiting extraction (optional; supports tag, #id, .class, [attr], [attr=value], descendant and > combinators)"`
+}
+
+// WebFetchTool downloads a web page and extracts its readable text.
+type WebFetchTool struct {
+	base.BaseTool
+	client       *http.Client
+	allowedHosts []string
+}
+
+// Parameters returns the parameters struct
+func (t *WebFetchTool) Parameters() interface{} {
+	return &WebFetchParams{}
+}
+
+// Execute fetches the page, honoring robots.txt, and returns its title,
+// canonical URL, and extracted text.
+func (t *WebFetchTool) Execute(ctx context.Context, params json.RawMessage) (string, error) {
+	var args WebFetchParams
+	if err := json.Unmarshal(params, &args); err != nil {
+		return "", NewToolError("INVALID_PARAMS", "Failed to parse parameters").
+			WithDetail("error", err.Error())
+	}
+
+	target, err := url.Parse(strings.TrimSpace(args.URL))
+	if err != nil || target.Host == "" || (target.Scheme != "http" && target.Scheme != "https") {
+		return "", NewToolError("VALIDATION_FAILED", "URL must be an absolute http or https URL").
+			WithDetail("url", args.URL)
+	}
+
+	var selectors []cssSelector
+	if strings.TrimSpace(args.Selector) != "" {
+		selectors, err = parseCSSSelector(args.Selector)
+		if err != nil {
+			return "", NewToolError("VALIDATION_FAILED", "Invalid CSS selector").
+				WithDetail("selector", args.Selector).
+				WithDetail("error", err.Error())
+		}
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, webFetchTimeout)
+	defer cancel()
+
+	if !t.robotsAllowed(reqCtx, target) {
+		return "", NewToolError("ROBOTS_DISALLOWED", "robots.txt disallows fetching this URL").
+			WithDetail("url", target.String())
+	}
+	if strings.HasSuffix(strings.ToLower(target.Path), ".pdf") {
+		return webFetchPDFNote(target), nil
+	}
+
+	req, err := http.NewRequestWithContext(reqCtx, "GET", target.String(), nil)
+	if err != nil {
+		return "", NewToolError("REQUEST_ERROR", "Failed to create request").
+			WithDetail("error", err.Error())
+	}
+	req.Header.Set("User-Agent", webFetchUserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.8")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", NewToolError("HTTP_ERROR", "Failed to fetch URL").
+			WithDetail("url", target.String()).
+			WithDetail("error", err.Error())
+	}
+	defer resp.Body.Close()
+
+	finalURL := resp.Request.URL
+	if resp.StatusCode >= 400 {
+		return "", NewToolError("HTTP_ERROR", fmt.Sprintf("Server returned %s", resp.Status)).
+			WithDetail("url", finalURL.String())
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "application/pdf" {
+		return webFetchPDFNote(finalURL), nil
+	}
+	if mediaType != "" && mediaType != "text/html" && mediaType != "application/xhtml+xml" && !strings.HasPrefix(mediaType, "text/") {
+		return "", NewToolError("UNSUPPORTED_CONTENT", "URL is not a web page").
+			WithDetail("url", finalURL.String()).
+			WithDetail("content_type", mediaType)
+	}
+
+	doc, err := html.Parse(io.LimitReader(resp.Body, maxWebFetchBodyBytes))
+	if err != nil {
+		return "", NewToolError("PARSE_ERROR", "Failed to parse HTML").
+			WithDetail("error", err.Error())
+	}
+
+	title := strings.Join(strings.Fields(nodeText(findElement(doc, "title"))), " ")
+	canonical := finalURL.String()
+	if href := canonicalHref(doc); href != "" {
+		if ref, err := finalURL.Parse(href); err == nil {
+			canonical = ref.String()
+		}
+	}
+
+	roots := []*html.Node{doc}
+	if len(selectors) > 0 {
+		roots = selectNodes(doc, selectors)
+		if len(roots) == 0 {
+			return "", NewToolError("SELECTOR_NO_MATCH", "CSS selector matched no elements").
+				WithDetail("selector", args.Selector).
+				WithDetail("url", canonical)
+		}
+	}
+
+	parts := make([]string, 0, len(roots))
+	for _, root := range roots {
+		if text := extractReadableText(root); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	text := strings.Join(parts, "\n\n")
+	if runes := []rune(text); len(runes) > maxWebFetchChars {
+		text = string(runes[:maxWebFetchChars]) + fmt.Sprintf("\n\n[content truncated to %d characters]", maxWebFetchChars)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Title: %s\n", title))
+	output.WriteString(fmt.Sprintf("URL: %s\n\n", canonical))
+	output.WriteString(text)
+	return output.String(), nil
+}
+
+func webFetchPDFNote(target *url.URL) string {
+	return fmt.Sprintf("URL: %s\n\nThis URL is a PDF document. PDF reading is not yet supported by web_fetch.", target.String())
+}
+
+// robotsAllowed fetches robots.txt for the target host and reports whether
+// the agent may fetch the target path. A missing or unreadable robots.txt
+// allows everything.
+func (t *WebFetchTool) robotsAllowed(ctx context.Context, target *url.URL) bool {
+	robotsURL := &url.URL{Scheme: target.Scheme, Host: target.Host, Path: "/robots.txt"}
+	req, err := http.NewRequestWithContext(ctx, "GET", robotsURL.String(), nil)
+	if err != nil {
+		return true
+	}
+	req.Header.Set("User-Agent", webFetchUserAgent)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return true
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return true
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsBodyBytes))
+	if err != nil {
+		return true
+	}
+
+	path := target.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if target.RawQuery != "" {
+		path += "?" + target.RawQuery
+	}
+	return robotsAllows(string(body), webFetchRobotsAgent, path)
+}
+
+type robotsRule struct {
+	allow bool
+	path  string
+}
+
+type robotsGroup struct {
+	agents []string
+	rules  []robotsRule
+}
+
+// robotsAllows applies the rules of the group matching agent (or the "*"
+// group) to path. The longest matching rule wins; Allow wins ties.
+func robotsAllows(robotsTxt, agent, path string) bool {
+	var groups []*robotsGroup
+	var current *robotsGroup
+	for _, line := range strings.Split(robotsTxt, "\n") {
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if current == nil || len(current.rules) > 0 {
+				current = &robotsGroup{}
+				groups = append(groups, current)
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			if current == nil || value == "" {
+				continue
+			}
+			current.rules = append(current.rules, robotsRule{allow: key == "allow", path: value})
+		}
+	}
+
+	agent = strings.ToLower(agent)
+	var matched, wildcard *robotsGroup
+	for _, group := range groups {
+		for _, name := range group.agents {
+			if name == "*" {
+				if wildcard == nil {
+					wildcard = group
+				}
+			} else if strings.Contains(agent, name) && matched == nil {
+				matched = group
+			}
+		}
+	}
+	if matched == nil {
+		matched = wildcard
+	}
+	if matched == nil {
+		return true
+	}
+
+	allowed, longest := true, -1
+	for _, rule := range matched.rules {
+		if !robotsPathMatches(rule.path, path) {
+			continue
+		}
+		if len(rule.path) > longest || (len(rule.path) == longest && rule.allow) {
+			allowed, longest = rule.allow, len(rule.path)
+		}
+	}
+	return allowed
+}
+
+// robotsPathMatches matches a robots.txt path pattern, supporting the "*"
+// wildcard and a trailing "$" anchor.
+func robotsPathMatches(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	segments := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, segments[0]) {
+		return false
+	}
+	rest := path[len(segments[0]):]
+	for i, segment := range segments[1:] {
+		if i == len(segments)-2 && anchored {
+			return strings.HasSuffix(rest, segment)
+		}
+		idx := strings.Index(rest, segment)
+		if idx < 0 {
+			return false
+		}
+		rest = rest[idx+len(segment):]
+	}
+	return !anchored || rest == ""
+}
+
+func findElement(n *html.Node, tag string) *html.Node {
+	if n.Type == html.ElementNode && n.Data == tag {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func canonicalHref(n *html.Node) string {
+	if n.Type == html.ElementNode && n.Data == "link" {
+		for _, rel := range strings.Fields(strings.ToLower(attrValue(n, "rel"))) {
+			if rel == "canonical" {
+				return strings.TrimSpace(attrValue(n, "href"))
+			}
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if href := canonicalHref(c); href != "" {
+			return href
+		}
+	}
+	return ""
+}
+
+func attrValue(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// nodeText returns the raw text content of n.
+func nodeText(n *html.Node) string {
+	if n == nil {
+		return ""
+	}
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// extractReadableText renders the visible text under n, dropping scripts,
+// styles, and navigation and collapsing whitespace.
+func extractReadableText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if webFetchSkipTags[n.Data] {
+				return
+			}
+			if webFetchBlockTags[n.Data] {
+				b.WriteString("\n")
+				defer b.WriteString("\n")
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+
+	text := spaceRunRe.ReplaceAllString(b.String(), " ")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	text = strings.Join(lines, "\n")
+	text = blankLinesRe.ReplaceAllString(text, "\n\n")
+	return strings.TrimSpace(text)
+}
+
+// cssCompound is a simple selector sequence such as div.note#main[lang=en].
+type cssCompound struct {
+	tag     string
+	id      string
+	classes []string
+	attrs   []cssAttr
+}
+
+// cssAttr is an [attr] or [attr=value] condition.
+type cssAttr struct {
+	key      string
+	value    string
+	hasValue bool
+}
+
+type cssStep struct {
+	compound cssCompound
+	child    bool // combinator to the previous step is ">"
+}
+
+type cssSelector []cssStep
+
+// parseCSSSelector parses a comma-separated list of selectors built from
+// tag, #id, .class, [attr], and [attr=value] with descendant and child
+// combinators.
+func parseCSSSelector(selector string) ([]cssSelector, error) {
+	var groups []cssSelector
+	for _, part := range strings.Split(selector, ",") {
+		part = strings.ReplaceAll(part, ">", " > ")
+		var sel cssSelector
+		child := false
+		for _, token := range strings.Fields(part) {
+			if token == ">" {
+				if len(sel) == 0 || child {
+					return nil, fmt.Errorf("unexpected '>' in %q", strings.TrimSpace(part))
+				}
+				child = true
+				continue
+			}
+			compound, err := parseCSSCompound(token)
+			if err != nil {
+				return nil, err
+			}
+			sel = append(sel, cssStep{compound: compound, child: child})
+			child = false
+		}
+		if len(sel) == 0 || child {
+			return nil, fmt.Errorf("incomplete selector %q", strings.TrimSpace(part))
+		}
+		groups = append(groups, sel)
+	}
+	return groups, nil
+}
+
+func parseCSSCompound(token string) (cssCompound, error) {
+	var c cssCompound
+	readIdent := func(s string) (string, string) {
+		end := 0
+		for end < len(s) {
+			ch := s[end]
+			if ch == '-' || ch == '_' || (ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') {
+				end++
+				continue
+			}
+			break
+		}
+		return s[:end], s[end:]
+	}
+
+	rest := token
+	if strings.HasPrefix(rest, "*") {
+		rest = rest[1:]
+	} else {
+		c.tag, rest = readIdent(rest)
+		c.tag = strings.ToLower(c.tag)
+	}
+	for rest != "" {
+		var ident string
+		switch rest[0] {
+		case '#':
+			ident, rest = readIdent(rest[1:])
+			if ident == "" {
+				return c, fmt.Errorf("empty id in %q", token)
+			}
+			c.id = ident
+		case '.':
+			ident, rest = readIdent(rest[1:])
+			if ident == "" {
+				return c, fmt.Errorf("empty class in %q", token)
+			}
+			c.classes = append(c.classes, ident)
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return c, fmt.Errorf("unterminated attribute in %q", token)
+			}
+			key, value, hasValue := strings.Cut(rest[1:end], "=")
+			key = strings.ToLower(strings.TrimSpace(key))
+			if key == "" {
+				return c, fmt.Errorf("empty attribute in %q", token)
+			}
+			c.attrs = append(c.attrs, cssAttr{key: key, value: strings.Trim(strings.TrimSpace(value), `"'`), hasValue: hasValue})
+			rest = rest[end+1:]
+		default:
+			return c, fmt.Errorf("unsupported selector syntax %q", token)
+		}
+	}
+	return c, nil
+}
+
+func (c cssCompound) matches(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	if c.tag != "" && n.Data != c.tag {
+		return false
+	}
+	if c.id != "" && attrValue(n, "id") != c.id {
+		return false
+	}
+	if len(c.classes) > 0 {
+		classes := strings.Fields(attrValue(n, "class"))
+		for _, want := range c.classes {
+			if !containsString(classes, want) {
+				return false
+			}
+		}
+	}
+	for _, attr := range c.attrs {
+		found := false
+		for _, a := range n.Attr {
+			if a.Key == attr.key && (!attr.hasValue || a.Val == attr.value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (s cssSelector) matches(n *html.Node) bool {
+	return s.matchAt(n, len(s)-1)
+}
+
+func (s cssSelector) matchAt(n *html.Node, i int) bool {
+	if !s[i].compound.matches(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	if s[i].child {
+		return n.Parent != nil && s.matchAt(n.Parent, i-1)
+	}
+	for p := n.Parent; p != nil; p = p.Parent {
+		if s.matchAt(p, i-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectNodes returns the outermost elements matching any selector, in
+// document order.
+func selectNodes(doc *html.Node, selectors []cssSelector) []*html.Node {
+	var matches []*html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for _, sel := range selectors {
+			if sel.matches(n) {
+				matches = append(matches, n)
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return matches
+}
@@ -0,0 +1,144 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestWebFetchTool(allowedHosts ...string) *WebFetchTool {
+	t := NewWebFetchTool().(*WebFetchTool)
+	t.allowedHosts = allowedHosts
+	return t
+}
+
+const webFetchTestPage = `<html><head><title> Example  Article </title>
+<link rel="canonical" href="/articles/1"><style>p{color:red}</style></head>
+<body><nav><a href="/">Home</a> <a href="/about">About</a></nav>
+<article class="post main"><h1>Hello &amp; welcome</h1><p>First   paragraph.</p><script>track()</script></article>
+<aside id="related"><p>Related links</p></aside></body></html>`
+
+func webFetchTestServer(t *testing.T, robots string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			if robots == "" {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(robots))
+		case "/old":
+			http.Redirect(w, r, "/page", http.StatusMovedPermanently)
+		case "/page":
+			if !strings.Contains(r.UserAgent(), "Mozilla/5.0") {
+				t.Errorf("expected browser User-Agent, got %q", r.UserAgent())
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(webFetchTestPage))
+		case "/report":
+			w.Header().Set("Content-Type", "application/pdf")
+			_, _ = w.Write([]byte("%PDF-1.4"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWebFetchTool_ExtractsReadableText(t *testing.T) {
+	srv := webFetchTestServer(t, "")
+	tool := newTestWebFetchTool("127.0.0.1")
+
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{"url":"`+srv.URL+`/old"}`))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	for _, want := range []string{"Title: Example Article", "URL: " + srv.URL + "/articles/1", "Hello & welcome", "First paragraph.", "Related links"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got %q", want, out)
+		}
+	}
+	for _, leaked := range []string{"Home", "About", "track()", "color:red", "<"} {
+		if strings.Contains(out, leaked) {
+			t.Fatalf("expected %q to be stripped, got %q", leaked, out)
+		}
+	}
+}
+
+func TestWebFetchTool_AppliesSelector(t *testing.T) {
+	srv := webFetchTestServer(t, "")
+	tool := newTestWebFetchTool("127.0.0.1")
+
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{"url":"`+srv.URL+`/page","selector":"body > article.post h1, #related"}`))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !strings.Contains(out, "Hello & welcome") || !strings.Contains(out, "Related links") || strings.Contains(out, "First paragraph") {
+		t.Fatalf("expected only selected elements, got %q", out)
+	}
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"url":"`+srv.URL+`/page","selector":"table.missing"}`))
+	if err == nil || !strings.Contains(err.Error(), "SELECTOR_NO_MATCH") {
+		t.Fatalf("expected SELECTOR_NO_MATCH, got %v", err)
+	}
+}
+
+func TestWebFetchTool_HonorsRobotsTxt(t *testing.T) {
+	srv := webFetchTestServer(t, "User-agent: *\nDisallow: /page\n")
+	tool := newTestWebFetchTool("127.0.0.1")
+
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"url":"`+srv.URL+`/page"}`))
+	if err == nil || !strings.Contains(err.Error(), "ROBOTS_DISALLOWED") {
+		t.Fatalf("expected ROBOTS_DISALLOWED, got %v", err)
+	}
+}
+
+func TestWebFetchTool_PDFNotSupported(t *testing.T) {
+	srv := webFetchTestServer(t, "")
+	tool := newTestWebFetchTool("127.0.0.1")
+
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{"url":"`+srv.URL+`/report"}`))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !strings.Contains(out, srv.URL+"/report") || !strings.Contains(out, "PDF reading is not yet supported") {
+		t.Fatalf("expected PDF note, got %q", out)
+	}
+}
+
+func TestRobotsAllows(t *testing.T) {
+	robots := `# comment
+User-agent: otherbot
+Disallow: /
+
+User-agent: simple-agent
+Disallow: /private
+Allow: /private/public
+Disallow: /*.json$
+
+User-agent: *
+Disallow: /
+`
+	cases := map[string]bool{
+		"/":                    true,
+		"/private":             false,
+		"/private/x":           false,
+		"/private/public/page": true,
+		"/data.json":           false,
+		"/data.json?x=1":       true,
+	}
+	for path, want := range cases {
+		if got := robotsAllows(robots, webFetchRobotsAgent, path); got != want {
+			t.Errorf("robotsAllows(%q) = %v, want %v", path, got, want)
+		}
+	}
+	if robotsAllows(robots, "unknown-bot", "/anything") {
+		t.Errorf("expected wildcard group to apply to other agents")
+	}
+}
@@ -18,6 +18,7 @@ type WriteParams struct {
 // WriteTool writes content to files.
 type WriteTool struct {
 	base.BaseTool
+	fileScope
 }
 
 // Parameters returns the parameters struct
@@ -39,7 +40,7 @@ func (t *WriteTool) Execute(ctx context.Context, params json.RawMessage) (string
 		return "", NewToolError("VALIDATION_FAILED", "Path cannot be empty")
 	}
 
-	resolvedPath, workspace, err := resolveWorkspacePath(args.Path)
+	resolvedPath, workspace, err := t.resolve(args.Path)
 	if err != nil {
 		return "", err
 	}
@@ -54,7 +55,7 @@ func (t *WriteTool) Execute(ctx context.Context, params json.RawMessage) (string
 	}
 
 	// Always overwrite.
-	if err := os.WriteFile(resolvedPath, []byte(args.Content), 0644); err != nil {
+	if err := writeFileNoFollow(resolvedPath, []byte(args.Content), 0644); err != nil {
 		return "", NewToolError("WRITE_ERROR", "Failed to write file").
 			WithDetail("error", err.Error()).
 			WithDetail("path", displayPath)
@@ -1,14 +1,15 @@
 package tui
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"image"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
-	"runtime"
 	"sort"
 	"strconv"
 	"strings"
@@ -26,9 +27,14 @@ import (
 	"github.com/nachoal/simple-agent-go/config"
 	"github.com/nachoal/simple-agent-go/history"
 	"github.com/nachoal/simple-agent-go/internal/improve"
+	"github.com/nachoal/simple-agent-go/internal/prompttemplate"
 	"github.com/nachoal/simple-agent-go/internal/runlog"
+	"github.com/nachoal/simple-agent-go/internal/userpaths"
 	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/llm/cost"
+	"github.com/nachoal/simple-agent-go/tools"
 	"github.com/nachoal/simple-agent-go/tools/registry"
+	"github.com/nachoal/simple-agent-go/tui/render"
 )
 
 const assistantMessageWrapWidth = 74
@@ -52,6 +58,7 @@ const (
 type transcriptEntry struct {
 	kind    transcriptEntryKind
 	content string
+	model   string // Model of a resumed assistant turn, set where a session switched models
 }
 
 // BorderedTUI is a minimal TUI that matches the Python bordered_interface.py
@@ -67,8 +74,17 @@ type BorderedTUI struct {
 	width            int
 	height           int
 	isThinking       bool
-	streamingMessage *llm.Message // Live assistant message during streaming
-	typedStreamMode  bool         // True when message_start/message_update events are in use
+	streamingMessage *llm.Message        // Live assistant message during streaming
+	typedStreamMode  bool                // True when message_start/message_update events are in use
+	messageQueue     *agent.MessageQueue // Input sent during a streamed run, delivered at its next step
+	lastUsage        *llm.Usage          // Token usage reported by the current or last streamed run
+	contextTokens    int                 // Estimated tokens in the agent's memory
+	contextLimit     int                 // Context window of the current model
+	contextTokensKey string              // Provider, model, and history length contextTokens was estimated for
+	costTracker      *cost.CostEstimator // Session spending shown by /status; nil when not tracked
+	maxCost          float64             // Session budget in USD; 0 means no limit
+	stats            sessionStats        // Totals shown by /status and the stats bar
+	runStarted       time.Time           // When the active run began
 	err              error
 	initialized      bool // Track if we've received the first WindowSizeMsg
 	yoloEnabled      bool
@@ -78,6 +94,8 @@ type BorderedTUI struct {
 	configManager   *config.Manager
 	clientFactory   providerClientFactory
 	configuredTools []string
+	// unavailable maps providers without a client to the reason why
+	unavailable map[string]string
 
 	// Runtime resource/model refresh hooks.
 	systemPromptBuilder systemPromptBuilder
@@ -87,6 +105,7 @@ type BorderedTUI struct {
 	// Glamour renderer
 	renderer      *glamour.TermRenderer
 	rendererWidth int
+	markdownStyle string // Overrides the theme's glamour style when set
 
 	// Spinner for thinking state
 	spinner spinner.Model
@@ -100,6 +119,8 @@ type BorderedTUI struct {
 	renderPending        bool
 	toolEventChan        chan agent.StreamEvent
 	toolsUsedInLastQuery map[string]time.Duration
+	confirmer            *commandConfirmer // Relays tool approval prompts during a streamed run
+	pendingConfirm       *confirmRequest   // Approval prompt awaiting y/n
 
 	// Border style for input
 	borderStyle lipgloss.Style
@@ -114,6 +135,7 @@ type BorderedTUI struct {
 	dataURLSeen       map[string]struct{}
 	tokenRe           *regexp.Regexp
 	prevInput         string
+	imageMaxDimension int // Longest side images are downscaled to before sending; 0 keeps them as-is
 	supportsVision    bool
 	thinkingEnabled   bool
 	baseRequestParams agent.RequestParams
@@ -136,6 +158,21 @@ type BorderedTUI struct {
 	// Transient notice displayed above prompt bar
 	transientNotice   string
 	transientNoticeID int
+
+	// Short confirmation, such as "Copied!", shown at the start of the model
+	// info line
+	infoNotice   string
+	infoNoticeID int
+
+	// editMode is set while the input holds a message loaded by /edit
+	editMode bool
+
+	// scratchSession holds /checkpoint branches when there is no saved session
+	scratchSession *history.Session
+
+	// Transcript search: the last /find query and the line it matched
+	findQuery string
+	findLine  int
 }
 
 // ActiveTool represents a currently executing tool
@@ -244,25 +281,15 @@ func NewBorderedTUI(llmClient llm.Client, agentInstance agent.Agent, provider, m
 	ta.SetWidth(74) // Default width minus borders/padding
 
 	// Simple glamour renderer
-	renderer, _ := glamour.NewTermRenderer(
-		// Use non-colored markdown output so assistant text remains visible across terminal themes.
-		glamour.WithStandardStyle("notty"),
-		glamour.WithWordWrap(assistantMessageWrapWidth),
-	)
+	renderer, _ := newMarkdownRenderer(resolveMarkdownStyle(os.Stdout, ""), assistantMessageWrapWidth)
 
 	// Initialize spinner
 	s := spinner.New()
 	s.Spinner = spinner.Dot
-	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("75")) // Same color as model
+	s.Style = themeColor(activeTheme.Primary) // Same color as model
 
 	// Border style for input
-	borderColor := lipgloss.Color("15")
-	if yoloEnabled {
-		borderColor = lipgloss.Color("196") // Red indicator for unsafe bash mode
-	}
-	borderStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(borderColor)
+	borderStyle := inputBorderStyle(yoloEnabled)
 
 	tokenRe := regexp.MustCompile(`\[Image\s+#(\d+)\]`)
 	transcriptView := viewport.New(80, 12)
@@ -286,6 +313,7 @@ func NewBorderedTUI(llmClient llm.Client, agentInstance agent.Agent, provider, m
 		completedTools:       []CompletedTool{},
 		toolErrors:           []ToolError{},
 		lastRender:           time.Now(),
+		stats:                sessionStats{started: time.Now()},
 		toolsUsedInLastQuery: make(map[string]time.Duration),
 		borderStyle:          borderStyle,
 		yoloEnabled:          yoloEnabled,
@@ -316,15 +344,29 @@ func NewBorderedTUI(llmClient llm.Client, agentInstance agent.Agent, provider, m
 		{name: "/model", desc: "Change model interactively"},
 		{name: "/reload", desc: "Reload context/resources/models"},
 		{name: "/improve", desc: "Run guarded self-improve cycle (opt-in)"},
-		{name: "/status", desc: "Show current model and provider"},
+		{name: "/status", desc: "Show current model, provider, and tool usage"},
 		{name: "/system", desc: "Show system prompt"},
+		{name: "/tokens", desc: "Show estimated context tokens"},
 		{name: "/thinking", desc: "Toggle model thinking (if supported)"},
 		{name: "/verbose", desc: "Toggle verbose/debug mode"},
 		{name: "/trace", desc: "Show current trace log path"},
+		{name: "/retry", desc: "Regenerate the last response"},
+		{name: "/resend", desc: "Send the last message again as a new turn"},
+		{name: "/save", desc: "Save the conversation as Markdown"},
+		{name: "/export", desc: "Export the conversation as Markdown, HTML, or JSON"},
+		{name: "/theme", desc: "List or switch color themes"},
+		{name: "/find", desc: "Scroll to the next transcript line containing text"},
+		{name: "/copy", desc: "Copy the last response, message n, or its first code block"},
+		{name: "/edit", desc: "Edit and re-send a past message, or the last tool call"},
+		{name: "/checkpoint", desc: "Save the conversation as a named branch"},
+		{name: "/branch", desc: "List checkpoints or restore one"},
+		{name: "/tag", desc: "Tag this session, or list its tags"},
+		{name: "/untag", desc: "Remove a tag from this session"},
 		{name: "/clear", desc: "Clear chat history"},
 		{name: "/attachments", desc: "List attached images"},
-		{name: "/attach", desc: "Attach an image by path"},
-		{name: "/paste-image", desc: "Attach clipboard image (macOS)"},
+		{name: "/attach", desc: "Attach images by path, glob, or directory"},
+		{name: "/paste-image", desc: "Attach clipboard image"},
+		{name: "/template", desc: "Load a prompt template into the input"},
 	}
 
 	tui.supportsVision = tui.computeVisionSupport()
@@ -361,6 +403,11 @@ func NewBorderedTUIWithHistory(llmClient llm.Client, historyAgent *agent.History
 				fmt.Fprintf(os.Stderr, "[TUI] Found %d messages in session %s\n", len(session.Messages), session.ID)
 			}
 
+			// Sessions that used more than one model name the model on each
+			// assistant turn where it changed.
+			switchedModels := len(session.ModelsUsed()) > 1
+			lastModel := ""
+
 			for _, msg := range session.Messages {
 				// Skip system messages
 				if msg.Role == "system" {
@@ -388,7 +435,12 @@ func NewBorderedTUIWithHistory(llmClient llm.Client, historyAgent *agent.History
 				case "user":
 					tui.transcript = append(tui.transcript, transcriptEntry{kind: transcriptUser, content: content})
 				case "assistant":
-					tui.transcript = append(tui.transcript, transcriptEntry{kind: transcriptAssistant, content: content})
+					entry := transcriptEntry{kind: transcriptAssistant, content: content}
+					if name := msg.ModelName(); switchedModels && name != "" && name != lastModel {
+						entry.model = name
+						lastModel = name
+					}
+					tui.transcript = append(tui.transcript, entry)
 				}
 			}
 		}
@@ -418,6 +470,26 @@ func (m *BorderedTUI) SetStaticModelsLoader(loader func() map[string][]llm.Model
 	m.staticModelsLoader = loader
 }
 
+// SetCostTracker sets the estimator whose session cost /status reports, and
+// the budget it is held to (0 for none).
+func (m *BorderedTUI) SetCostTracker(tracker *cost.CostEstimator, maxCost float64) {
+	m.costTracker = tracker
+	m.maxCost = maxCost
+}
+
+// SetImageMaxDimension tells /attachments the limit the LLM client downscales
+// images to (see llm.WithImageMaxDimension), so it can report the sent size.
+func (m *BorderedTUI) SetImageMaxDimension(px int) {
+	m.imageMaxDimension = px
+}
+
+// SetUnavailableProviders lists providers whose client could not be created,
+// with the reason, for the model selector. The map is read each time the
+// selector opens, so a runtime reloader may update it in place.
+func (m *BorderedTUI) SetUnavailableProviders(unavailable map[string]string) {
+	m.unavailable = unavailable
+}
+
 // SetConfiguredTools provides the enabled tool set for the in-app header.
 func (m *BorderedTUI) SetConfiguredTools(configuredTools []string) {
 	if configuredTools == nil {
@@ -457,6 +529,7 @@ func (m *BorderedTUI) syncLayout(pinBottom bool) {
 	m.transcriptView.Width = m.transcriptWrapWidth()
 	m.transcriptView.Height = m.transcriptHeight()
 	m.refreshTranscriptView(pinBottom)
+	m.refreshContextTokens()
 	m.initialized = true
 }
 
@@ -465,10 +538,7 @@ func (m *BorderedTUI) ensureRenderer() {
 	if m.renderer != nil && m.rendererWidth == wrapWidth {
 		return
 	}
-	renderer, err := glamour.NewTermRenderer(
-		glamour.WithStandardStyle("notty"),
-		glamour.WithWordWrap(wrapWidth),
-	)
+	renderer, err := newMarkdownRenderer(resolveMarkdownStyle(os.Stdout, m.markdownStyle), wrapWidth)
 	if err == nil {
 		m.renderer = renderer
 		m.rendererWidth = wrapWidth
@@ -515,7 +585,7 @@ func (m BorderedTUI) suggestionLineCount() int {
 
 func (m BorderedTUI) transcriptHeight() int {
 	headerLines := 3 // two header lines plus one spacer line
-	metaLines := 1
+	metaLines := 2   // model info line plus the stats bar
 	if m.transientNotice != "" {
 		metaLines++
 	}
@@ -560,35 +630,79 @@ func (m BorderedTUI) renderTranscriptContent() string {
 	sections := make([]string, 0, len(m.transcript)+2)
 	wrapWidth := m.transcriptWrapWidth()
 	for _, entry := range m.transcript {
-		rendered := renderTranscriptEntry(entry, m.renderer, wrapWidth)
+		rendered := renderTranscriptEntry(entry, m.renderer, m.agentName(), wrapWidth)
 		if strings.TrimSpace(rendered) != "" {
 			sections = append(sections, rendered)
 		}
 	}
 
+	// Tool-call-only turns stream no text; keep showing the thinking status
+	// and tool activity instead of an empty assistant bubble.
+	streamingText := false
 	if m.streamingMessage != nil {
 		streamContent := streamMessageToContent(m.streamingMessage)
 		if strings.TrimSpace(streamContent) != "" {
-			sections = append(sections, renderAssistantMessage(m.renderer, streamContent, wrapWidth))
+			streamingText = true
+			sections = append(sections, renderAssistantMessage(m.renderer, m.agentName(), streamContent, wrapWidth))
 		}
 	}
 
-	if m.isThinking && m.streamingMessage == nil {
+	if m.isThinking && !streamingText {
 		status := renderToolMessage(fmt.Sprintf("%s Thinking...", m.spinner.View()), wrapWidth)
 		if strings.TrimSpace(status) != "" {
 			sections = append(sections, status)
 		}
 	}
+	if m.isThinking {
+		if progress := formatActiveToolProgress(m.activeTools); progress != "" {
+			sections = append(sections, renderToolMessage(progress, wrapWidth))
+		}
+	}
 
 	return strings.Join(sections, "\n\n")
 }
 
-func renderTranscriptEntry(entry transcriptEntry, renderer *glamour.TermRenderer, wrapWidth int) string {
+// formatActiveToolProgress lists the latest progress update of each running
+// tool, oldest first.
+func formatActiveToolProgress(active map[string]*ActiveTool) string {
+	running := make([]*ActiveTool, 0, len(active))
+	for _, tool := range active {
+		if tool != nil && strings.TrimSpace(tool.LastProgressText) != "" {
+			running = append(running, tool)
+		}
+	}
+	if len(running) == 0 {
+		return ""
+	}
+	sort.Slice(running, func(i, j int) bool {
+		return running[i].StartTime.Before(running[j].StartTime)
+	})
+
+	lines := make([]string, 0, len(running))
+	for _, tool := range running {
+		line := fmt.Sprintf("⏳ %s: %s", tool.Name, truncateToWidth(strings.TrimSpace(tool.LastProgressText), 120))
+		if tool.Progress > 0 {
+			line += fmt.Sprintf(" (%d%%)", int(tool.Progress*100))
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// agentName returns the configured agent name, if any.
+func (m BorderedTUI) agentName() string {
+	if m.agent == nil {
+		return ""
+	}
+	return m.agent.Name()
+}
+
+func renderTranscriptEntry(entry transcriptEntry, renderer *glamour.TermRenderer, agentName string, wrapWidth int) string {
 	switch entry.kind {
 	case transcriptUser:
 		return renderUserMessage(entry.content, wrapWidth)
 	case transcriptAssistant:
-		return renderAssistantMessage(renderer, entry.content, wrapWidth)
+		return renderAssistantTurn(renderer, agentName, entry.model, entry.content, wrapWidth)
 	case transcriptError:
 		return renderErrorMessage(entry.content, wrapWidth)
 	case transcriptTool:
@@ -601,33 +715,38 @@ func renderTranscriptEntry(entry transcriptEntry, renderer *glamour.TermRenderer
 }
 
 func renderUserMessage(content string, wrapWidth int) string {
-	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("15")).Bold(true)
-	bodyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("15"))
+	labelStyle := themeColor(activeTheme.Text).Bold(true)
+	bodyStyle := themeColor(activeTheme.Text)
 	return fmt.Sprintf("%s\n%s", labelStyle.Render("👤 You:"), styleWrappedText(bodyStyle, content, wrapWidth))
 }
 
-func renderAssistantMessage(renderer *glamour.TermRenderer, content string, wrapWidth int) string {
-	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("15")).Bold(true)
-	thinkingTrace, finalContent := splitThinkingTrace(content)
-	sections := []string{labelStyle.Render("🤖 Assistant:")}
+// assistantLabel returns the transcript label for assistant messages.
+func assistantLabel(agentName string) string {
+	if agentName == "" {
+		agentName = "Assistant"
+	}
+	return fmt.Sprintf("🤖 %s:", agentName)
+}
 
-	if thinkingTrace != "" {
-		tagStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Bold(true)
-		traceStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
-		wrappedTrace := wrapThinkingTrace(thinkingTrace, wrapWidth)
+func renderAssistantMessage(renderer *glamour.TermRenderer, agentName, content string, wrapWidth int) string {
+	return renderAssistantTurn(renderer, agentName, "", content, wrapWidth)
+}
 
-		traceBlock := fmt.Sprintf("%s\n%s\n%s",
-			tagStyle.Render("<thinking traces>"),
-			styleMultiline(traceStyle, wrappedTrace),
-			tagStyle.Render("</thinking traces>"),
-		)
-		sections = append(sections, traceBlock)
+// renderAssistantTurn renders an assistant message, naming the model that
+// wrote it in dim text after the label when model is set.
+func renderAssistantTurn(renderer *glamour.TermRenderer, agentName, model, content string, wrapWidth int) string {
+	labelStyle := themeColor(activeTheme.Text).Bold(true)
+	body, reasoning := render.RenderThinking(content)
+	label := labelStyle.Render(assistantLabel(agentName))
+	if model != "" {
+		label += " " + themeColor(activeTheme.TextDim).Render(model)
 	}
+	sections := []string{label}
 
-	body := content
-	if thinkingTrace != "" {
-		body = finalContent
+	if reasoning != "" {
+		sections = append(sections, renderReasoning(reasoning, wrapWidth))
 	}
+
 	body = strings.TrimSpace(body)
 	if body != "" {
 		if renderer != nil {
@@ -635,16 +754,30 @@ func renderAssistantMessage(renderer *glamour.TermRenderer, content string, wrap
 			if err == nil {
 				sections = append(sections, strings.TrimRight(rendered, "\n"))
 			} else {
-				sections = append(sections, styleWrappedText(lipgloss.NewStyle().Foreground(lipgloss.Color("15")), body, wrapWidth))
+				sections = append(sections, styleWrappedText(themeColor(activeTheme.Text), body, wrapWidth))
 			}
 		} else {
-			sections = append(sections, styleWrappedText(lipgloss.NewStyle().Foreground(lipgloss.Color("15")), body, wrapWidth))
+			sections = append(sections, styleWrappedText(themeColor(activeTheme.Text), body, wrapWidth))
 		}
 	}
 
 	return strings.Join(sections, "\n")
 }
 
+// renderReasoning shows a model's <think> reasoning as a section that
+// /verbose expands; otherwise only its header and length are shown.
+func renderReasoning(reasoning string, wrapWidth int) string {
+	headerStyle := themeColor(activeTheme.Trace).Bold(true)
+	if os.Getenv("SIMPLE_AGENT_DEBUG") != "true" {
+		return headerStyle.Render(fmt.Sprintf("▸ 🧠 Reasoning (%d words, /verbose to expand)", len(strings.Fields(reasoning))))
+	}
+	traceStyle := themeColor(activeTheme.Trace)
+	return fmt.Sprintf("%s\n%s",
+		headerStyle.Render("▾ 🧠 Reasoning"),
+		styleMultiline(traceStyle, wrapThinkingTrace(reasoning, wrapWidth)),
+	)
+}
+
 func cloneMessageForDisplay(msg *llm.Message) *llm.Message {
 	if msg == nil {
 		return nil
@@ -698,33 +831,6 @@ func streamMessageToContent(msg *llm.Message) string {
 	return fmt.Sprintf("<think>\n%s\n</think>\n\n%s", reasoning, content)
 }
 
-var thinkTraceRe = regexp.MustCompile(`(?is)<think>\s*(.*?)\s*</think>`)
-
-func splitThinkingTrace(content string) (thinkingTrace string, finalContent string) {
-	if strings.TrimSpace(content) == "" {
-		return "", ""
-	}
-
-	matches := thinkTraceRe.FindAllStringSubmatch(content, -1)
-	if len(matches) == 0 {
-		return "", content
-	}
-
-	traces := make([]string, 0, len(matches))
-	for _, m := range matches {
-		if len(m) < 2 {
-			continue
-		}
-		trace := strings.TrimSpace(m[1])
-		if trace != "" {
-			traces = append(traces, trace)
-		}
-	}
-
-	remaining := strings.TrimSpace(thinkTraceRe.ReplaceAllString(content, ""))
-	return strings.Join(traces, "\n\n"), remaining
-}
-
 func wrapThinkingTrace(trace string, wrapWidth int) string {
 	if strings.TrimSpace(trace) == "" {
 		return ""
@@ -794,17 +900,17 @@ func styleWrappedText(style lipgloss.Style, content string, wrapWidth int) strin
 }
 
 func renderCommandMessage(content string, wrapWidth int) string {
-	style := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	style := themeColor(activeTheme.TextDim)
 	return styleWrappedText(style, content, wrapWidth)
 }
 
 func renderErrorMessage(content string, wrapWidth int) string {
-	style := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	style := themeColor(activeTheme.Error)
 	return styleWrappedText(style, fmt.Sprintf("❌ %s", content), wrapWidth)
 }
 
 func renderToolMessage(content string, wrapWidth int) string {
-	style := lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Italic(true)
+	style := themeColor(activeTheme.TextDim).Italic(true)
 	return styleWrappedText(style, content, wrapWidth)
 }
 
@@ -923,6 +1029,7 @@ func (m *BorderedTUI) beginRun(mode, prompt string) (context.Context, string) {
 	ctx = runlog.WithMetadata(ctx, meta)
 	m.activeRunCancel = cancel
 	m.activeRunID = runID
+	m.runStarted = time.Now()
 	m.tracef("run_start id=%s mode=%s prompt=%q", runID, mode, truncateForTrace(prompt, 512))
 	runlog.EventFromContext(ctx, "run_start", map[string]interface{}{"ui_mode": "tui"})
 	return ctx, runID
@@ -946,6 +1053,7 @@ func (m *BorderedTUI) cancelActiveRun(reason string) bool {
 }
 
 func (m *BorderedTUI) clearActiveRun() {
+	m.recordRunEnd()
 	m.activeRunCancel = nil
 	m.activeRunID = ""
 }
@@ -957,21 +1065,20 @@ func isYoloEnabled() bool {
 
 // PrintHeader prints the TUI header to stdout before the TUI starts
 func PrintHeader(provider, model string, configuredTools []string) {
-	// Colors matching Python version
-	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("15")).Bold(true)
-	modelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("75")) // #5B9BD5
-	toolsStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("80")) // #4ECDC4
-	cmdStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))  // #6B7280
+	headerStyle := themeColor(activeTheme.Text).Bold(true)
+	modelStyle := themeColor(activeTheme.Primary)
+	toolsStyle := themeColor(activeTheme.Info)
+	cmdStyle := themeColor(activeTheme.TextDim)
 
 	verboseIndicator := ""
 	if os.Getenv("SIMPLE_AGENT_DEBUG") == "true" {
-		verboseStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true) // Red
+		verboseStyle := themeColor(activeTheme.Error).Bold(true)
 		verboseIndicator = " | " + verboseStyle.Render("[VERBOSE]")
 	}
 
 	yoloIndicator := ""
 	if isYoloEnabled() {
-		yoloStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true) // Red
+		yoloStyle := themeColor(activeTheme.Error).Bold(true)
 		yoloIndicator = " | " + yoloStyle.Render("[YOLO]")
 	}
 
@@ -1017,11 +1124,16 @@ func replayHistory(session *history.Session, renderer *glamour.TermRenderer) tea
 				content = *msg.Content
 			}
 
+			if strings.TrimSpace(content) == "" {
+				// Tool-call-only assistant turns have nothing to show.
+				continue
+			}
+
 			switch msg.Role {
 			case "user":
 				tea.Println(renderUserMessage(content, assistantMessageWrapWidth))
 			case "assistant":
-				tea.Println(renderAssistantMessage(renderer, content, assistantMessageWrapWidth))
+				tea.Println(renderAssistantMessage(renderer, "", content, assistantMessageWrapWidth))
 			}
 			tea.Println() // Empty line between messages
 		}
@@ -1075,6 +1187,12 @@ func (m BorderedTUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return syncAndReturn(m, nil, false)
 
+	case clearInfoNoticeMsg:
+		if msg.id == m.infoNoticeID {
+			m.infoNotice = ""
+		}
+		return syncAndReturn(m, nil, false)
+
 	case modelSelectedMsg:
 		if err := m.switchModel(msg.provider, msg.model); err != nil {
 			m.textarea.Focus()
@@ -1102,7 +1220,21 @@ func (m BorderedTUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.transcriptView, cmd = m.transcriptView.Update(msg)
 		return syncAndReturn(m, cmd, false)
 
+	case confirmRequestMsg:
+		m.pendingConfirm = &msg.req
+		m.appendTranscript(transcriptTool, confirmPrompt(msg.req))
+		return syncAndReturn(m, nil, true)
+
 	case tea.KeyMsg:
+		if m.pendingConfirm != nil && msg.Type == tea.KeyRunes {
+			switch string(msg.Runes) {
+			case "y", "Y":
+				return syncAndReturn(m, m.answerConfirmation(true), true)
+			case "n", "N":
+				return syncAndReturn(m, m.answerConfirmation(false), true)
+			}
+			return syncAndReturn(m, nil, false)
+		}
 		switch msg.Type {
 		case tea.KeyCtrlC, tea.KeyCtrlQ:
 			m.tracef("app_quit key=%s", msg.Type.String())
@@ -1118,9 +1250,11 @@ func (m BorderedTUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.streamingMessage = nil
 					m.typedStreamMode = false
 					m.toolEventChan = nil
+					m.pendingConfirm = nil
 					m.resetToolTrackingForNextQuery()
 					m.clearActiveRun()
 					m.textarea.Focus()
+					m.restoreQueuedInput()
 					return syncAndReturn(m, m.showTransientNotice("Tool interrupted, what would you like Simple Agent to do instead?"), true)
 				}
 				return syncAndReturn(m, nil, false)
@@ -1197,8 +1331,16 @@ func (m BorderedTUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				cmds = append(cmds, func() tea.Msg { return resp })
 				return syncAndReturn(m, tea.Batch(cmds...), false)
 			}
+			if m.isThinking && m.messageQueue != nil && trimmed != "" && !strings.HasPrefix(trimmed, "/") {
+				m.messageQueue.Push(value)
+				m.textarea.Reset()
+				m.textarea.SetHeight(1)
+				m.tracef("message_queued run=%s len=%d", m.activeRunID, len(value))
+				return syncAndReturn(m, m.showTransientNotice("Queued: your message will be added after the current step."), false)
+			}
 			if !m.isThinking {
 				if trimmed != "" {
+					m.editMode = false
 					// If suggestions are visible for a slash command, Enter executes the selected
 					// command only when the input is just a single token (no arguments yet).
 					if m.suggestVisible && len(m.suggestItems) > 0 && strings.HasPrefix(trimmed, "/") &&
@@ -1246,24 +1388,7 @@ func (m BorderedTUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.textarea.Reset()
 					m.textarea.SetHeight(1)
 
-					// Send to agent or multimodal helper depending on attachments
-					m.isThinking = true
-					m.showingTools = false
-					m.streamingMessage = nil
-					m.typedStreamMode = false
-
-					if len(m.attachments) > 0 && m.supportsVision {
-						runCtx, runID := m.beginRun("multimodal", value)
-						cmds = append(cmds, m.sendMultimodal(runCtx, runID, value))
-						cmds = append(cmds, m.spinner.Tick)
-					} else {
-						// Create event channel and store it
-						m.toolEventChan = make(chan agent.StreamEvent, 100)
-						runCtx, runID := m.beginRun("query", value)
-						cmds = append(cmds, m.sendMessage(runCtx, runID, value))
-						cmds = append(cmds, m.spinner.Tick)
-						cmds = append(cmds, m.listenForToolEvents())
-					}
+					cmds = append(cmds, m.startQuery(value)...)
 				}
 			}
 			return syncAndReturn(m, tea.Batch(cmds...), true)
@@ -1312,6 +1437,20 @@ func (m BorderedTUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			m.streamingMessage = nil
 
+		case agent.EventTypeUserMessage:
+			content := msg.event.Content
+			m.historyForAgent = append(m.historyForAgent, llm.Message{
+				Role:    llm.RoleUser,
+				Content: &content,
+			})
+			m.appendTranscript(transcriptUser, content)
+
+		case agent.EventTypeUsage:
+			if msg.event.Usage != nil {
+				usage := *msg.event.Usage
+				m.lastUsage = &usage
+			}
+
 		case agent.EventTypeMessage:
 			// Legacy chunk event fallback for older stream producers.
 			if !m.typedStreamMode && msg.event.Content != "" {
@@ -1324,7 +1463,7 @@ func (m BorderedTUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.streamingMessage.Content = &updated
 			}
 
-		case agent.EventTypeComplete:
+		case agent.EventTypeComplete, agent.EventTypeMaxIterations:
 			terminal = true
 
 			finalContent := streamMessageToContent(m.streamingMessage)
@@ -1336,14 +1475,28 @@ func (m BorderedTUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.appendTranscript(transcriptAssistant, finalContent)
 			}
 
+			status := "completed"
+			if msg.event.Type == agent.EventTypeMaxIterations {
+				// The partial answer was already rendered when its message ended.
+				status = "max_iterations"
+				m.appendTranscript(transcriptTool, fmt.Sprintf("(stopped after %d tool iterations)", msg.event.Iterations))
+			}
+			if msg.event.FinishReason == "length" {
+				m.appendTranscript(transcriptTool, "(response cut off at the token limit: ask to continue, or raise --max-tokens)")
+			}
+
 			m.tracef("run_end id=%s status=ok mode=stream response_len=%d", runID, len(finalContent))
 			if m.runLogger != nil {
-				m.runLogger.Event("run_end", map[string]interface{}{
+				fields := map[string]interface{}{
 					"run_id":       runID,
 					"mode":         "stream",
-					"status":       "completed",
+					"status":       status,
 					"response_len": len(finalContent),
-				})
+				}
+				if m.lastUsage != nil {
+					fields["total_tokens"] = m.lastUsage.TotalTokens
+				}
+				m.runLogger.Event("run_end", fields)
 			}
 			m.isThinking = false
 			m.showingTools = false
@@ -1353,6 +1506,17 @@ func (m BorderedTUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.streamingMessage = nil
 			m.typedStreamMode = false
 			m.textarea.Focus()
+			m.syncHistoryFromAgent()
+
+			// Input queued after the agent's last step starts a follow-up run.
+			if queued := m.takeQueuedMessages(); queued != "" {
+				m.appendTranscript(transcriptUser, queued)
+				m.historyForAgent = append(m.historyForAgent, llm.Message{
+					Role:    llm.RoleUser,
+					Content: &queued,
+				})
+				cmds = append(cmds, m.startQuery(queued)...)
+			}
 
 		case agent.EventTypeError:
 			terminal = true
@@ -1377,6 +1541,7 @@ func (m BorderedTUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.streamingMessage = nil
 			m.typedStreamMode = false
 			m.textarea.Focus()
+			m.restoreQueuedInput()
 			if strings.TrimSpace(partial) != "" {
 				m.historyForAgent = append(m.historyForAgent, llm.Message{
 					Role:    llm.RoleAssistant,
@@ -1395,6 +1560,11 @@ func (m BorderedTUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
+		case agent.EventTypeToolCallProposed:
+			for _, call := range msg.event.ToolCalls {
+				m.tracef("tool_proposed run=%s tool_id=%s tool=%s", m.activeRunID, call.ID, call.Function.Name)
+			}
+
 		case agent.EventTypeToolStart:
 			if msg.event.Tool != nil {
 				m.tracef("tool_start run=%s tool_id=%s tool=%s args=%q", m.activeRunID, msg.event.Tool.ID, msg.event.Tool.Name, truncateForTrace(msg.event.Tool.ArgsRaw, 400))
@@ -1421,6 +1591,13 @@ func (m BorderedTUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.appendTranscript(transcriptTool, toolStartMsg)
 			}
 
+		case agent.EventTypeToolBatchComplete:
+			// A lone failure already has its own line; summarize only batches.
+			if batch := msg.event.ToolBatch; batch != nil && batch.Failed > 0 && batch.Succeeded+batch.Failed > 1 {
+				m.tracef("tool_batch run=%s succeeded=%d failed=%d", m.activeRunID, batch.Succeeded, batch.Failed)
+				m.appendTranscript(transcriptTool, fmt.Sprintf("⚠️ %d of %d tool calls failed (%s)", batch.Failed, batch.Succeeded+batch.Failed, strings.Join(batch.FailedTools, ", ")))
+			}
+
 		case agent.EventTypeToolProgress:
 			if msg.event.Tool != nil && m.activeTools[msg.event.Tool.ID] != nil {
 				tool := m.activeTools[msg.event.Tool.ID]
@@ -1441,9 +1618,10 @@ func (m BorderedTUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						Name:         activeTool.Name,
 						CompletedAt:  time.Now(),
 						Success:      msg.event.Tool.Error == nil,
-						OutputSample: msg.event.Tool.Result,
+						OutputSample: msg.event.Tool.Display,
 					}
 					m.completedTools = append(m.completedTools, completedTool)
+					m.stats.toolCalls++
 
 					// Update duration in tracking
 					duration := time.Since(activeTool.StartTime)
@@ -1473,7 +1651,15 @@ func (m BorderedTUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.tracef("tool_end run=%s tool_id=%s tool=%s status=ok duration_ms=%d", m.activeRunID, msg.event.Tool.ID, activeTool.Name, duration.Milliseconds())
 						// Print success message with duration
 						successMsg := fmt.Sprintf("✅ Tool %s completed in %v", activeTool.Name, duration.Round(time.Millisecond))
+						if size := msg.event.Tool.OutputBytes; size > 0 {
+							successMsg += fmt.Sprintf(" (output %s, truncated to %s for the model)", formatByteSize(size), formatByteSize(len(msg.event.Tool.Result)))
+						}
 						m.appendTranscript(transcriptTool, successMsg)
+						// Output the tool meant only for the user is shown here,
+						// since it never reaches the model's reply.
+						if display := msg.event.Tool.Display; display != "" && display != msg.event.Tool.Result {
+							m.appendTranscript(transcriptTool, display)
+						}
 					}
 				}
 			}
@@ -1519,6 +1705,19 @@ func (m BorderedTUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return syncAndReturn(m, tea.ClearScreen, true)
 		}
 
+		if msg.retryInput != "" {
+			return syncAndReturn(m, tea.Batch(m.startQuery(msg.retryInput)...), true)
+		}
+
+		if msg.resendInput != "" {
+			m.appendTranscript(transcriptUser, msg.resendInput)
+			m.historyForAgent = append(m.historyForAgent, llm.Message{
+				Role:    llm.RoleUser,
+				Content: &msg.resendInput,
+			})
+			return syncAndReturn(m, tea.Batch(m.startQuery(msg.resendInput)...), true)
+		}
+
 		if msg.isModelSelect {
 			// Show in-app model selector modal
 			configuredModels := map[string][]llm.Model{}
@@ -1526,17 +1725,25 @@ func (m BorderedTUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				configuredModels = m.staticModelsLoader()
 			}
 			m.selector = NewModelSelector(m.providers, configuredModels, nil)
-			// Initialize selector size to match current TUI
-			if m.selector != nil {
-				m.selector.width = m.width
-				m.selector.height = m.height
-				m.selector.list.SetSize(m.width, m.height)
+			if m.configManager != nil {
+				m.selector.SetRecentModels(m.configManager.RecentModels())
 			}
+			m.selector.SetUnavailableProviders(m.unavailable)
+			// Initialize selector size to match current TUI
+			m.selector.SetSize(m.width, m.height)
 			m.showModelSelector = true
 			m.textarea.Blur()
 			// Enter alt screen and trigger selector Init to load models
 			return syncAndReturn(m, tea.Batch(tea.EnterAltScreen, m.selector.Init()), false)
 		}
+		if msg.notice != "" {
+			m.textarea.Focus()
+			return syncAndReturn(m, m.showTransientNotice(msg.notice), false)
+		}
+		if msg.infoNotice != "" {
+			m.textarea.Focus()
+			return syncAndReturn(m, m.showInfoNotice(msg.infoNotice, copyNoticeDuration), false)
+		}
 		// Handle normal messages
 		if msg.err != nil {
 			if errors.Is(msg.err, context.Canceled) {
@@ -1548,7 +1755,7 @@ func (m BorderedTUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			m.appendTranscript(transcriptError, fmt.Sprintf("Error: %v", msg.err))
 			return syncAndReturn(m, nil, true)
-		} else if msg.content != "" {
+		} else if strings.TrimSpace(msg.content) != "" {
 			if msg.isCommand {
 				m.textarea.Focus()
 				m.appendTranscript(transcriptCommand, msg.content)
@@ -1559,6 +1766,7 @@ func (m BorderedTUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					Role:    llm.RoleAssistant,
 					Content: &content,
 				})
+				m.syncHistoryFromAgent()
 				m.textarea.Focus()
 				m.appendTranscript(transcriptAssistant, msg.content)
 				return syncAndReturn(m, nil, true)
@@ -1582,6 +1790,11 @@ func (m BorderedTUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.appendTranscript(transcriptError, fmt.Sprintf("Failed to switch model: %v", err))
 			return syncAndReturn(m, tea.ExitAltScreen, true)
 		}
+		if m.configManager != nil {
+			if err := m.configManager.AddRecentModel(msg.provider, msg.model); err != nil {
+				m.err = fmt.Errorf("failed to save config: %w", err)
+			}
+		}
 		m.supportsVision = m.computeVisionSupport()
 		m.applyModelDefaults()
 		if !m.supportsVision && len(m.attachments) > 0 {
@@ -1633,7 +1846,7 @@ func (m BorderedTUI) View() string {
 	b.WriteString("\n")
 
 	// Create model info string that will appear above the input box.
-	grayStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	grayStyle := themeColor(activeTheme.TextDim)
 	visionState := "Off"
 	if m.supportsVision {
 		visionState = "On"
@@ -1653,6 +1866,11 @@ func (m BorderedTUI) View() string {
 	if len(m.attachments) > 0 {
 		modelParts = append(modelParts, fmt.Sprintf("Attached: %d", len(m.attachments)))
 	}
+	tokenStatus, tokenStyle := m.contextTokenStatus()
+	modelParts = append(modelParts, tokenStatus)
+	if m.lastUsage != nil {
+		modelParts = append(modelParts, fmt.Sprintf("Last run: %d in / %d out", m.lastUsage.PromptTokens, m.lastUsage.CompletionTokens))
+	}
 	if m.yoloEnabled {
 		modelParts = append(modelParts, "Bash: YOLO")
 	}
@@ -1661,15 +1879,30 @@ func (m BorderedTUI) View() string {
 	// Keep live lines strictly within terminal width; wrapped live lines can
 	// break Bubble Tea's redraw bookkeeping when resizing.
 	boxWidth := m.inputOuterWidth()
-	modelInfo = truncateToWidth(modelInfo, boxWidth-1)
+	noticePrefix := ""
+	if m.infoNotice != "" {
+		noticePrefix = m.infoNotice + " | "
+	}
+	modelInfo = truncateToWidth(noticePrefix+modelInfo, boxWidth-1)
+	if noticePrefix != "" {
+		if rest, ok := strings.CutPrefix(modelInfo, noticePrefix); ok {
+			b.WriteString(themeColor(activeTheme.Success).Bold(true).Render(m.infoNotice) + grayStyle.Render(" | "))
+			modelInfo = rest
+		}
+	}
 
-	// Add the model info line above the input box
-	b.WriteString(grayStyle.Render(modelInfo))
+	// Add the model info line above the input box, with the token count
+	// colored by how full the context is
+	if before, after, ok := strings.Cut(modelInfo, tokenStatus); ok {
+		b.WriteString(grayStyle.Render(before) + tokenStyle.Render(tokenStatus) + grayStyle.Render(after))
+	} else {
+		b.WriteString(grayStyle.Render(modelInfo))
+	}
 	b.WriteString("\n")
 
 	// Optional transient notice line above prompt bar
 	if m.transientNotice != "" {
-		noticeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+		noticeStyle := themeColor(activeTheme.Warning).Bold(true)
 		notice := truncateToWidth(m.transientNotice, boxWidth-1)
 		b.WriteString(noticeStyle.Render(notice))
 		b.WriteString("\n")
@@ -1678,8 +1911,12 @@ func (m BorderedTUI) View() string {
 	// Input area with border and prompt
 	inputContent := m.textarea.View()
 
-	// Style the input box with border
-	styledInput := m.borderStyle.
+	// Style the input box with border, yellow while editing a past message
+	inputStyle := m.borderStyle
+	if m.editMode {
+		inputStyle = inputStyle.BorderForeground(activeTheme.Warning)
+	}
+	styledInput := inputStyle.
 		PaddingLeft(1).
 		PaddingRight(1).
 		Render(inputContent)
@@ -1693,9 +1930,9 @@ func (m BorderedTUI) View() string {
 			max = 8
 		}
 		// Simple styles
-		nameStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("75"))
-		descStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
-		selStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("230")).Background(lipgloss.Color("62"))
+		nameStyle := themeColor(activeTheme.Primary)
+		descStyle := themeColor(activeTheme.TextDim)
+		selStyle := themeColor(activeTheme.SelectionText).Background(activeTheme.SelectionBackground)
 		for i := 0; i < max; i++ {
 			item := m.suggestItems[i]
 			line := fmt.Sprintf(" %s  %s", nameStyle.Render(item.name), descStyle.Render(item.desc))
@@ -1711,13 +1948,16 @@ func (m BorderedTUI) View() string {
 		}
 	}
 
+	// Session totals stay on the last line, below any suggestions
+	b.WriteString(grayStyle.Render(truncateToWidth(m.statsBar(), boxWidth-1)))
+
 	return b.String()
 }
 
 func (m BorderedTUI) renderHeaderBlock() string {
-	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("15")).Bold(true)
-	toolsStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("80"))
-	alertStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+	headerStyle := themeColor(activeTheme.Text).Bold(true)
+	toolsStyle := themeColor(activeTheme.Info)
+	alertStyle := themeColor(activeTheme.Error).Bold(true)
 
 	line1 := fmt.Sprintf("Simple Agent Go | Model: %s | Provider: %s", m.model, m.provider)
 	if os.Getenv("SIMPLE_AGENT_DEBUG") == "true" {
@@ -1758,6 +1998,17 @@ func (m *BorderedTUI) showTransientNotice(text string) tea.Cmd {
 	})
 }
 
+// showInfoNotice puts text on the model info line for d
+func (m *BorderedTUI) showInfoNotice(text string, d time.Duration) tea.Cmd {
+	m.infoNotice = strings.TrimSpace(text)
+	m.infoNoticeID++
+	currentID := m.infoNoticeID
+
+	return tea.Tick(d, func(time.Time) tea.Msg {
+		return clearInfoNoticeMsg{id: currentID}
+	})
+}
+
 func (m *BorderedTUI) resetToolTrackingForNextQuery() {
 	m.toolsUsedInLastQuery = make(map[string]time.Duration)
 	m.activeTools = make(map[string]*ActiveTool)
@@ -1796,6 +2047,7 @@ func (m *BorderedTUI) switchModel(provider, model string) error {
 
 	currentMemory := m.agent.GetMemory()
 	replacement := agent.New(newClient,
+		agent.WithName(m.agentName()),
 		agent.WithModel(model),
 		agent.WithSystemPrompt(systemPrompt),
 		agent.WithMaxIterations(1000),
@@ -1809,6 +2061,9 @@ func (m *BorderedTUI) switchModel(provider, model string) error {
 
 	if historyAgent, ok := m.agent.(*agent.HistoryAgent); ok {
 		historyAgent.ReplaceAgent(replacement)
+		if historyAgent.TitleGenerator() != nil {
+			historyAgent.SetTitleGenerator(history.NewLLMTitleGenerator(newClient, model))
+		}
 		if session := historyAgent.GetSession(); session != nil {
 			session.Provider = provider
 			session.Model = model
@@ -1826,8 +2081,129 @@ func (m *BorderedTUI) switchModel(provider, model string) error {
 	return nil
 }
 
+// startQuery marks the TUI as thinking and starts a run for value, using the
+// multimodal helper when images are attached.
+func (m *BorderedTUI) startQuery(value string) []tea.Cmd {
+	m.isThinking = true
+	m.showingTools = false
+	m.streamingMessage = nil
+	m.typedStreamMode = false
+	m.lastUsage = nil
+
+	if len(m.attachments) > 0 && m.supportsVision {
+		m.messageQueue = nil
+		m.confirmer = nil
+		runCtx, runID := m.beginRun("multimodal", value)
+		return []tea.Cmd{m.sendMultimodal(runCtx, runID, value), m.spinner.Tick}
+	}
+
+	// Create event channel and store it
+	m.toolEventChan = make(chan agent.StreamEvent, 100)
+	runCtx, runID := m.beginRun("query", value)
+	m.messageQueue = agent.NewMessageQueue()
+	runCtx = agent.WithMessageQueue(runCtx, m.messageQueue)
+	m.confirmer = newCommandConfirmer()
+	m.pendingConfirm = nil
+	runCtx = tools.WithConfirmer(runCtx, m.confirmer.confirm)
+	return []tea.Cmd{m.sendMessage(runCtx, runID, value), m.spinner.Tick, m.listenForToolEvents(), m.confirmer.listen()}
+}
+
+// takeQueuedMessages returns input queued during the finished run that the
+// agent never picked up, joined into a single message.
+func (m *BorderedTUI) takeQueuedMessages() string {
+	if m.messageQueue == nil {
+		return ""
+	}
+	pending := m.messageQueue.Drain()
+	m.messageQueue = nil
+	return strings.Join(pending, "\n\n")
+}
+
+// restoreQueuedInput puts undelivered queued input back into an empty
+// textarea so it is not lost when a run stops early.
+func (m *BorderedTUI) restoreQueuedInput() {
+	if queued := m.takeQueuedMessages(); queued != "" && strings.TrimSpace(m.textarea.Value()) == "" {
+		m.textarea.SetValue(queued)
+		m.adjustTextareaHeight()
+	}
+}
+
+// syncHistoryFromAgent replaces the UI history with the agent's memory once a
+// run finishes, so /save and /retry see assistant tool calls and tool results
+// rather than only the text that was displayed.
+func (m *BorderedTUI) syncHistoryFromAgent() {
+	if m.agent == nil {
+		return
+	}
+	memory := m.agent.GetMemory()
+	history := make([]llm.Message, 0, len(memory))
+	for _, msg := range memory {
+		if msg.Role != llm.RoleSystem {
+			history = append(history, msg)
+		}
+	}
+	if len(history) == 0 {
+		return
+	}
+	m.historyForAgent = history
+}
+
+// handleRetryCommand drops the last answer from the transcript, the UI
+// history, and the agent's memory, then asks for the previous user message
+// to be sent again.
+func (m *BorderedTUI) handleRetryCommand() borderedResponseMsg {
+	lastUser := -1
+	for i := len(m.historyForAgent) - 1; i >= 0; i-- {
+		if m.historyForAgent[i].Role == llm.RoleUser {
+			lastUser = i
+			break
+		}
+	}
+	if lastUser < 0 {
+		return borderedResponseMsg{content: "Nothing to retry yet: send a message first.", isCommand: true}
+	}
+	input := llm.GetStringValue(m.historyForAgent[lastUser].Content)
+	m.historyForAgent = m.historyForAgent[:lastUser+1]
+
+	if m.agent != nil {
+		memory := m.agent.GetMemory()
+		for i := len(memory) - 1; i >= 0; i-- {
+			if memory[i].Role == llm.RoleUser {
+				m.agent.SetMemory(memory[:i])
+				break
+			}
+		}
+	}
+
+	for i := len(m.transcript) - 1; i >= 0; i-- {
+		if m.transcript[i].kind == transcriptUser {
+			m.transcript = m.transcript[:i+1]
+			break
+		}
+	}
+	m.refreshTranscriptView(true)
+
+	return borderedResponseMsg{retryInput: input}
+}
+
+// handleResendCommand asks for the last user message to be sent again as a
+// new turn. Unlike /retry, nothing is removed from the conversation.
+func (m *BorderedTUI) handleResendCommand() borderedResponseMsg {
+	for i := len(m.historyForAgent) - 1; i >= 0; i-- {
+		if m.historyForAgent[i].Role == llm.RoleUser {
+			return borderedResponseMsg{resendInput: llm.GetStringValue(m.historyForAgent[i].Content)}
+		}
+	}
+	return borderedResponseMsg{content: "Nothing to resend yet: send a message first.", isCommand: true}
+}
+
 func (m *BorderedTUI) sendMessage(runCtx context.Context, runID, input string) tea.Cmd {
 	return func() tea.Msg {
+		// Stop listening for approval prompts once the run is over
+		if confirmer := m.confirmer; confirmer != nil {
+			defer close(confirmer.done)
+		}
+
 		// Handle commands (trim leading whitespace)
 		trimmed := strings.TrimSpace(input)
 		if strings.HasPrefix(trimmed, "/") {
@@ -1960,6 +2336,39 @@ func (m *BorderedTUI) handleCommand(cmd string) borderedResponseMsg {
 	if strings.HasPrefix(lower, "/improve") {
 		return m.handleImproveCommand(trimmed)
 	}
+	if lower == "/template" || strings.HasPrefix(lower, "/template ") {
+		return m.handleTemplateCommand(trimmed)
+	}
+	if lower == "/save" || strings.HasPrefix(lower, "/save ") {
+		return m.handleSaveCommand(trimmed)
+	}
+	if lower == "/export" || strings.HasPrefix(lower, "/export ") {
+		return m.handleExportCommand(trimmed)
+	}
+	if lower == "/theme" || strings.HasPrefix(lower, "/theme ") {
+		return m.handleThemeCommand(trimmed)
+	}
+	if lower == "/find" || strings.HasPrefix(lower, "/find ") {
+		return m.handleFindCommand(trimmed)
+	}
+	if lower == "/copy" || strings.HasPrefix(lower, "/copy ") {
+		return m.handleCopyCommand(trimmed)
+	}
+	if lower == "/edit" || strings.HasPrefix(lower, "/edit ") {
+		return m.handleEditCommand(trimmed)
+	}
+	if lower == "/checkpoint" || strings.HasPrefix(lower, "/checkpoint ") {
+		return m.handleCheckpointCommand(trimmed)
+	}
+	if lower == "/branch" || strings.HasPrefix(lower, "/branch ") {
+		return m.handleBranchCommand(trimmed)
+	}
+	if lower == "/tag" || strings.HasPrefix(lower, "/tag ") {
+		return m.handleTagCommand(trimmed)
+	}
+	if lower == "/untag" || strings.HasPrefix(lower, "/untag ") {
+		return m.handleUntagCommand(trimmed)
+	}
 	switch lower {
 	case "/exit", "/quit":
 		// Return a special message type that will trigger quit
@@ -1969,6 +2378,10 @@ func (m *BorderedTUI) handleCommand(cmd string) borderedResponseMsg {
 			return borderedResponseMsg{content: "No active run to cancel.", isCommand: true}
 		}
 		return borderedResponseMsg{content: "Cancellation requested.", isCommand: true}
+	case "/retry":
+		return m.handleRetryCommand()
+	case "/resend":
+		return m.handleResendCommand()
 	case "/clear":
 		// Return a special message type that will trigger clear
 		return borderedResponseMsg{content: "", isClear: true}
@@ -1980,21 +2393,36 @@ func (m *BorderedTUI) handleCommand(cmd string) borderedResponseMsg {
   /model   - Change model interactively
   /reload  - Reload context/resources/models
   /improve <goal> - Run guarded self-improve cycle (requires SIMPLE_AGENT_ENABLE_IMPROVE=1)
-  /status  - Show current model and provider
+  /status  - Show current model, provider, and tool usage
   /system  - Show system prompt
+  /tokens  - Show estimated tokens for the system prompt and the conversation
   /thinking [on|off] - Toggle model thinking (if supported)
   /verbose - Toggle verbose/debug mode
   /trace   - Show active trace log path
+  /retry   - Regenerate the last response
+  /resend  - Send the last message again as a new turn, keeping history
+  /save [path] - Save the conversation as Markdown
+  /export [markdown|html|json] [path] - Export the conversation (default: ~/Downloads)
+  /theme [name] - List color themes or switch to one (saved for next time)
+  /find [text] - Scroll to the next transcript line containing text
+  /copy [n|code] - Copy the last response, the nth most recent message, or the response's first code block
+  /edit [n|last-tool] - Load the nth most recent message (default: last) to edit and re-send from that point, or the last tool call's arguments
+  /checkpoint [name] - Save the conversation so far as a named branch
+  /branch [name] - List checkpoints, or restore one and discard the messages after it
+  /tag [name] - Tag this session (filter by tag with [t] in the --resume picker), or list its tags
+  /untag <name> - Remove a tag from this session
   /clear   - Clear chat history
   /attachments - List attached images
-  /attach <path> - Attach an image by path
+  /attach <path|glob|dir> - Attach an image, or up to 10 matching a glob or in a directory
   /clear images - Remove all image attachments from the input
+  /template <name> [key=value ...] - Load a prompt template into the input
   /exit    - Exit application
 
 Keyboard shortcuts:
   Esc    - Interrupt active run (when model/tools are running)
   Ctrl+C - Quit
   Ctrl+L - Clear chat
+  PgUp/PgDn - Scroll the transcript
   Enter  - Send message`
 		return borderedResponseMsg{content: help, isCommand: true}
 	case "/tools":
@@ -2009,7 +2437,11 @@ Keyboard shortcuts:
 				continue
 			}
 			// Format: tool_name - description
-			toolsBuilder.WriteString(fmt.Sprintf("  %-15s - %s\n", name, tool.Description()))
+			desc, _ := registry.Description(name)
+			if !tools.IsAvailable(tool) {
+				desc = "(not configured) " + desc
+			}
+			toolsBuilder.WriteString(fmt.Sprintf("  %-15s - %s\n", name, desc))
 		}
 
 		return borderedResponseMsg{content: strings.TrimRight(toolsBuilder.String(), "\n"), isCommand: true}
@@ -2040,9 +2472,17 @@ Keyboard shortcuts:
 			}
 			statusMsg = fmt.Sprintf("%s\n  Thinking: %s", statusMsg, thinkingState)
 		}
+		statusMsg = fmt.Sprintf("%s\n\n📈 Session stats:\n%s", statusMsg, m.sessionStatsTable())
+		if stats := registry.GetStats(); len(stats) > 0 {
+			var table strings.Builder
+			_ = registry.WriteStatsTable(&table, stats)
+			statusMsg = fmt.Sprintf("%s\n\n🔧 Tool usage this session:\n%s", statusMsg, strings.TrimRight(table.String(), "\n"))
+		}
 		return borderedResponseMsg{content: statusMsg, isCommand: true}
 	case "/reload":
 		return m.handleReloadCommand()
+	case "/tokens":
+		return m.handleTokensCommand()
 	case "/system":
 		// Show the current system prompt with tools
 		messages := m.agent.GetMemory()
@@ -2101,7 +2541,7 @@ Keyboard shortcuts:
 			} else {
 				ref = filepath.Base(ref)
 			}
-			fmt.Fprintf(&b, "  [%d] %s\n", i+1, ref)
+			fmt.Fprintf(&b, "  [%d] %s%s\n", i+1, ref, m.attachmentSize(a))
 		}
 		return borderedResponseMsg{content: strings.TrimRight(b.String(), "\n"), isCommand: true}
 	case "/clear images":
@@ -2111,17 +2551,15 @@ Keyboard shortcuts:
 		m.textarea.SetValue(strings.TrimSpace(stripped))
 		return borderedResponseMsg{content: "Cleared all image attachments", isCommand: true, clearAttachments: true}
 	case "/paste-image", "/paste image":
-		// macOS-only: capture clipboard image via pngpaste
+		// Capture the clipboard image with the platform's clipboard tool
 		if !m.supportsVision {
 			return borderedResponseMsg{content: "This model does not support vision.", isCommand: true}
 		}
-		if runtime.GOOS != "darwin" {
-			return borderedResponseMsg{content: "Clipboard image paste is only wired for macOS.", isCommand: true}
-		}
-		if _, err := exec.LookPath("pngpaste"); err != nil {
-			return borderedResponseMsg{content: "pngpaste not found. Install with: brew install pngpaste", isCommand: true}
-		}
 		path, err := saveClipboardPNG()
+		var toolErr *clipboardToolError
+		if errors.As(err, &toolErr) {
+			return borderedResponseMsg{content: toolErr.Error(), isCommand: true}
+		}
 		if err != nil {
 			return borderedResponseMsg{content: fmt.Sprintf("Clipboard does not contain an image (%v)", err), isCommand: true}
 		}
@@ -2132,15 +2570,18 @@ Keyboard shortcuts:
 		}
 		return borderedResponseMsg{content: "Failed to attach clipboard image", isCommand: true}
 	default:
-		// Handle /attach <path>
+		// Handle /attach <path|glob|directory>
 		if strings.HasPrefix(strings.ToLower(cmd), "/attach ") {
 			path := strings.TrimSpace(cmd[len("/attach "):])
 			if path == "" {
-				return borderedResponseMsg{content: "Usage: /attach <image-path>", isCommand: true}
+				return borderedResponseMsg{content: "Usage: /attach <image-path|glob|directory>", isCommand: true}
 			}
 			if !m.supportsVision {
 				return borderedResponseMsg{content: "This model does not support vision.", isCommand: true}
 			}
+			if isAttachPattern(path) {
+				return m.attachMany(path)
+			}
 			if m.tryAttachPath(path) {
 				// Insert token at end
 				placeholder := fmt.Sprintf(" [Image #%d]", len(m.attachments))
@@ -2153,6 +2594,186 @@ Keyboard shortcuts:
 	}
 }
 
+// handleTemplateCommand renders a template from ~/.simple-agent/templates into
+// the input box so it can be reviewed before sending. Without a name it lists
+// the available templates.
+func (m *BorderedTUI) handleTemplateCommand(cmd string) borderedResponseMsg {
+	fields := strings.Fields(cmd)
+	if len(fields) < 2 {
+		dir, err := userpaths.TemplatesDir()
+		if err != nil {
+			return borderedResponseMsg{content: fmt.Sprintf("Templates unavailable: %v", err), isCommand: true}
+		}
+		names, err := prompttemplate.List(dir)
+		if err != nil {
+			return borderedResponseMsg{content: fmt.Sprintf("Failed to list templates: %v", err), isCommand: true}
+		}
+		if len(names) == 0 {
+			return borderedResponseMsg{content: fmt.Sprintf("No templates found in %s\nUsage: /template <name> [key=value ...]", dir), isCommand: true}
+		}
+		return borderedResponseMsg{content: "Templates:\n  " + strings.Join(names, "\n  ") + "\nUsage: /template <name> [key=value ...]", isCommand: true}
+	}
+
+	name := fields[1]
+	vars, err := prompttemplate.ParseVars(fields[2:])
+	if err != nil {
+		return borderedResponseMsg{content: err.Error(), isCommand: true}
+	}
+	text, err := prompttemplate.Load(name)
+	if err != nil {
+		return borderedResponseMsg{content: err.Error(), isCommand: true}
+	}
+	rendered, err := prompttemplate.Render(name, text, vars)
+	if err != nil {
+		return borderedResponseMsg{content: err.Error(), isCommand: true}
+	}
+
+	m.textarea.SetValue(rendered)
+	return borderedResponseMsg{content: fmt.Sprintf("Loaded template %s. Press Enter to send.", name), isCommand: true}
+}
+
+// handleSaveCommand writes the conversation to a Markdown file. Without a
+// path it saves to a timestamped file in the current directory.
+func (m *BorderedTUI) handleSaveCommand(cmd string) borderedResponseMsg {
+	path := strings.TrimSpace(cmd[len("/save"):])
+	if len(m.historyForAgent) == 0 {
+		return borderedResponseMsg{content: "Nothing to save yet: send a message first.", isCommand: true}
+	}
+	if path == "" {
+		path = fmt.Sprintf("conversation-%s.md", time.Now().Format("20060102-150405"))
+	}
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return borderedResponseMsg{content: fmt.Sprintf("Failed to save conversation: %v", err), isCommand: true}
+	}
+	if err := os.WriteFile(path, []byte(conversationMarkdown(m.historyForAgent, m.agentName())), 0644); err != nil {
+		return borderedResponseMsg{content: fmt.Sprintf("Failed to save conversation: %v", err), isCommand: true}
+	}
+	return borderedResponseMsg{content: fmt.Sprintf("Conversation saved to %s", path), isCommand: true}
+}
+
+// handleExportCommand writes the conversation as Markdown (the default),
+// HTML, or JSON. A lone path argument picks the format from its extension
+// when it names one; without a path the file goes to history.DefaultExportPath.
+func (m *BorderedTUI) handleExportCommand(cmd string) borderedResponseMsg {
+	args := strings.Fields(strings.TrimSpace(cmd[len("/export"):]))
+	if len(m.historyForAgent) == 0 {
+		return borderedResponseMsg{content: "Nothing to export yet: send a message first.", isCommand: true}
+	}
+
+	var formatName, path string
+	switch len(args) {
+	case 0:
+	case 1:
+		if _, err := history.ParseExportFormat(args[0]); err == nil {
+			formatName = args[0]
+		} else {
+			path = args[0]
+			if ext := strings.TrimPrefix(filepath.Ext(path), "."); ext != "" {
+				if _, err := history.ParseExportFormat(ext); err == nil {
+					formatName = ext
+				}
+			}
+		}
+	case 2:
+		formatName, path = args[0], args[1]
+	default:
+		return borderedResponseMsg{content: "Usage: /export [markdown|html|json] [path]", isCommand: true}
+	}
+	format, err := history.ParseExportFormat(formatName)
+	if err != nil {
+		return borderedResponseMsg{content: err.Error(), isCommand: true}
+	}
+
+	session := m.exportSession()
+	if path == "" {
+		path = history.DefaultExportPath(session.ID, format)
+	}
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	var buf bytes.Buffer
+	if err := history.Export(&buf, session, format); err != nil {
+		return borderedResponseMsg{content: fmt.Sprintf("Failed to export conversation: %v", err), isCommand: true}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return borderedResponseMsg{content: fmt.Sprintf("Failed to export conversation: %v", err), isCommand: true}
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return borderedResponseMsg{content: fmt.Sprintf("Failed to export conversation: %v", err), isCommand: true}
+	}
+	return borderedResponseMsg{content: fmt.Sprintf("Conversation exported to %s", path), isCommand: true}
+}
+
+// exportSession returns the saved session, whose messages carry timestamps,
+// when history is enabled and in step with the TUI; otherwise it builds one
+// from the in-memory conversation.
+func (m *BorderedTUI) exportSession() *history.Session {
+	if historyAgent, ok := m.agent.(*agent.HistoryAgent); ok {
+		if session := historyAgent.GetSession(); session != nil && len(session.Messages) > 0 {
+			return session
+		}
+	}
+	return &history.Session{
+		Provider: m.provider,
+		Model:    m.model,
+		Messages: history.FromLLMMessages(m.historyForAgent),
+	}
+}
+
+// conversationMarkdown renders messages as plain Markdown with a header per
+// message. Content is written verbatim so fenced code blocks survive.
+func conversationMarkdown(messages []llm.Message, agentName string) string {
+	if agentName == "" {
+		agentName = "Assistant"
+	}
+
+	var b strings.Builder
+	b.WriteString("# Conversation\n")
+	for _, msg := range messages {
+		content := strings.TrimSpace(llm.GetStringValue(msg.Content))
+		var header string
+		switch msg.Role {
+		case llm.RoleUser:
+			header = "You"
+		case llm.RoleAssistant:
+			header = agentName
+			content, _ = render.RenderThinking(content)
+			for _, call := range msg.ToolCalls {
+				line := fmt.Sprintf("Called `%s` with `%s`", call.Function.Name, strings.TrimSpace(string(call.Function.Arguments)))
+				content = strings.TrimSpace(content + "\n\n" + line)
+			}
+		case llm.RoleTool:
+			header = "Tool result"
+			if msg.Name != "" {
+				header = fmt.Sprintf("Tool result (%s)", msg.Name)
+			}
+		default:
+			continue
+		}
+		if content == "" {
+			continue
+		}
+		b.WriteString("\n## " + header + "\n\n")
+		b.WriteString(content + "\n")
+	}
+	return b.String()
+}
+
 func (m *BorderedTUI) handleThinkingCommand(cmd string) borderedResponseMsg {
 	if !supportsThinkingToggle(m.provider, m.model) {
 		return borderedResponseMsg{content: "Thinking toggle is not available for this model.", isCommand: true}
@@ -2181,6 +2802,62 @@ func (m *BorderedTUI) handleThinkingCommand(cmd string) borderedResponseMsg {
 	return borderedResponseMsg{content: "Thinking: OFF", isCommand: true}
 }
 
+// handleTokensCommand reports the estimated size of the agent's memory. The
+// system prompt is listed apart from the conversation: it is sent with every
+// request and never trimmed, so folding it in would overstate what the
+// conversation itself costs.
+func (m *BorderedTUI) handleTokensCommand() borderedResponseMsg {
+	memory := m.agent.GetMemory()
+	breakdown := agent.EstimateMemoryTokens(memory)
+
+	var b strings.Builder
+	b.WriteString("Estimated context tokens:\n")
+	if len(memory) > 0 {
+		b.WriteString(m.tokensByMessage(memory))
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "  System prompt: %d\n", breakdown.System)
+	fmt.Fprintf(&b, "  Conversation:  %d (%d messages)\n", breakdown.Conversation, breakdown.Messages)
+	fmt.Fprintf(&b, "  Total:         %d", breakdown.Total())
+	if m.lastUsage != nil {
+		fmt.Fprintf(&b, "\n\nLast run (reported by provider): %d in / %d out", m.lastUsage.PromptTokens, m.lastUsage.CompletionTokens)
+	}
+	return borderedResponseMsg{content: b.String(), isCommand: true}
+}
+
+// tokensByMessage lists each message in memory with its role, estimated
+// tokens, and as much of its text as fits on one line
+func (m BorderedTUI) tokensByMessage(memory []llm.Message) string {
+	width := m.transcriptWrapWidth()
+	if m.width <= 0 {
+		width = 80
+	}
+	indexWidth := len(strconv.Itoa(len(memory)))
+
+	var b strings.Builder
+	for i, msg := range memory {
+		row := fmt.Sprintf("  %*d  %-9s %6d  ", indexWidth, i+1, msg.Role, agent.EstimateMessageTokens(msg))
+		b.WriteString(row)
+		b.WriteString(truncateToWidth(messagePreview(msg), width-len(row)))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// messagePreview flattens a message to one line, naming the tools called by
+// an assistant message without text
+func messagePreview(msg llm.Message) string {
+	preview := strings.Join(strings.Fields(llm.GetStringValue(msg.Content)), " ")
+	if preview == "" && len(msg.ToolCalls) > 0 {
+		names := make([]string, len(msg.ToolCalls))
+		for i, call := range msg.ToolCalls {
+			names[i] = call.Function.Name
+		}
+		preview = "[tool calls: " + strings.Join(names, ", ") + "]"
+	}
+	return preview
+}
+
 func (m *BorderedTUI) handleReloadCommand() borderedResponseMsg {
 	if m.runtimeReloader != nil {
 		if err := m.runtimeReloader(); err != nil {
@@ -2275,9 +2952,13 @@ type borderedResponseMsg struct {
 	err              error
 	isQuit           bool
 	isClear          bool
-	isCommand        bool // Flag to indicate this is a command response
-	isModelSelect    bool // Flag to trigger model selection
-	clearAttachments bool // Clear image attachments on success
+	isCommand        bool   // Flag to indicate this is a command response
+	isModelSelect    bool   // Flag to trigger model selection
+	clearAttachments bool   // Clear image attachments on success
+	retryInput       string // Re-send this user message as a new run
+	resendInput      string // Send this user message again as an additional turn
+	notice           string // Show as a transient notice instead of a transcript entry
+	infoNotice       string // Show briefly on the model info line instead of a transcript entry
 }
 
 // modelSelectedMsg is sent when a model is selected
@@ -2295,6 +2976,10 @@ type clearTransientNoticeMsg struct {
 	id int
 }
 
+type clearInfoNoticeMsg struct {
+	id int
+}
+
 // adjustTextareaHeight dynamically adjusts the textarea height based on content
 func (m *BorderedTUI) adjustTextareaHeight() {
 	content := m.textarea.Value()
@@ -2424,16 +3109,79 @@ func (m *BorderedTUI) computeVisionSupport() bool {
 	model := strings.ToLower(m.model)
 	// Heuristics per provider
 	switch p {
-	case "ollama":
-		return strings.Contains(model, "llava") || strings.Contains(model, "bakllava") || strings.Contains(model, "moondream") || strings.Contains(model, "-vision") || strings.Contains(model, ":vision")
-	case "lmstudio", "lm-studio":
-		return strings.Contains(model, "gemma-3") || strings.Contains(model, "pixtral") || strings.Contains(model, "llava") || strings.Contains(model, "bakllava") || strings.Contains(model, "moondream") || strings.Contains(model, "-vision")
+	case "ollama", "lmstudio", "lm-studio":
+		return isLocalVisionModel(model)
+	case "anthropic":
+		// Every Claude model since Claude 3 accepts images.
+		return true
+	case "openai":
+		// Contains rather than prefix matching also covers "chatgpt-4o-latest"
+		// and provider-qualified IDs such as "openai/gpt-4o".
+		return strings.Contains(model, "gpt-4o") || strings.Contains(model, "gpt-4-turbo") || strings.Contains(model, "vision")
 	default:
 		// Other providers: conservatively false for now
 		return false
 	}
 }
 
+// localVisionModels are name fragments of the vision models Ollama and LM
+// Studio serve; both name them after the same upstream releases.
+var localVisionModels = []string{
+	"llava", "bakllava", "moondream", "pixtral", "minicpm-v",
+	"gemma-3", "gemma3", "qwen2.5vl", "qwen2.5-vl", "qwen2-vl",
+	"-vision", ":vision",
+}
+
+// isLocalVisionModel reports whether a local model's name (lowercased) looks
+// like a vision model
+func isLocalVisionModel(model string) bool {
+	for _, fragment := range localVisionModels {
+		if strings.Contains(model, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// attachmentSize describes how many bytes an attachment will add to the
+// request, noting the original size when it is downscaled first.
+func (m BorderedTUI) attachmentSize(a Attachment) string {
+	data, _, err := llm.PrepareImage(a.Ref, m.imageMaxDimension)
+	if err != nil {
+		return fmt.Sprintf(" (unreadable: %v)", err)
+	}
+	sent := formatByteSize(len(data))
+	if m.imageMaxDimension <= 0 {
+		return fmt.Sprintf(" (%s)", sent)
+	}
+
+	var original []byte
+	if a.IsDataURL {
+		if _, payload, ok := strings.Cut(a.Ref, ","); ok {
+			original, _ = base64.StdEncoding.DecodeString(payload)
+		}
+	} else {
+		original, _ = os.ReadFile(a.Ref)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(original))
+	if err != nil || max(cfg.Width, cfg.Height) <= m.imageMaxDimension {
+		return fmt.Sprintf(" (%s)", sent)
+	}
+	return fmt.Sprintf(" (%s, resized from %s at %dx%d)", sent, formatByteSize(len(original)), cfg.Width, cfg.Height)
+}
+
+// formatByteSize renders a byte count as B, KB, or MB.
+func formatByteSize(n int) string {
+	switch {
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	case n >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
 // normalizeInputAndAttachments detects pasted image refs and normalizes tokens <-> attachments
 func (m *BorderedTUI) normalizeInputAndAttachments() {
 	if !m.supportsVision {
@@ -2646,6 +3394,94 @@ func (m *BorderedTUI) tryAttachPath(path string) bool {
 	return true
 }
 
+// maxAttachPerCommand caps how many images one /attach glob or directory adds
+const maxAttachPerCommand = 10
+
+// isAttachPattern reports whether an /attach argument names several files:
+// a glob pattern or a directory.
+func isAttachPattern(arg string) bool {
+	if strings.ContainsAny(arg, "*?[") {
+		return true
+	}
+	st, err := os.Stat(expandPath(arg))
+	return err == nil && st.IsDir()
+}
+
+// attachCandidates lists the image files a glob matches or a directory
+// contains, in name order. Other file types and subdirectories are skipped.
+func attachCandidates(arg string) ([]string, error) {
+	p := expandPath(arg)
+	var matches []string
+	if st, err := os.Stat(p); err == nil && st.IsDir() {
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			matches = append(matches, filepath.Join(p, entry.Name()))
+		}
+	} else {
+		globbed, err := filepath.Glob(p)
+		if err != nil {
+			return nil, err
+		}
+		matches = globbed
+	}
+
+	images := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if looksLikeImagePath(match) && fileExists(match) {
+			images = append(images, match)
+		}
+	}
+	return images, nil
+}
+
+// attachMany handles /attach with a glob or directory, adding one
+// [Image #N] token per newly attached file up to maxAttachPerCommand.
+func (m *BorderedTUI) attachMany(arg string) borderedResponseMsg {
+	candidates, err := attachCandidates(arg)
+	if err != nil {
+		return borderedResponseMsg{content: fmt.Sprintf("Failed to attach images: %v", err), isCommand: true}
+	}
+	if len(candidates) == 0 {
+		return borderedResponseMsg{content: fmt.Sprintf("No images found matching %s", arg), isCommand: true}
+	}
+
+	var attached []string
+	duplicates, overCap := 0, 0
+	value := m.textarea.Value()
+	for _, path := range candidates {
+		if _, ok := m.pathSeen[path]; ok {
+			duplicates++
+			continue
+		}
+		if len(attached) == maxAttachPerCommand {
+			overCap++
+			continue
+		}
+		if m.tryAttachPath(path) {
+			value += fmt.Sprintf(" [Image #%d]", len(m.attachments))
+			attached = append(attached, filepath.Base(path))
+		}
+	}
+	m.textarea.SetValue(value)
+
+	var b strings.Builder
+	if len(attached) == 0 {
+		b.WriteString("No new images attached")
+	} else {
+		fmt.Fprintf(&b, "Attached %d image(s): %s", len(attached), strings.Join(attached, ", "))
+	}
+	if duplicates > 0 {
+		fmt.Fprintf(&b, " (%d already attached)", duplicates)
+	}
+	if overCap > 0 {
+		fmt.Fprintf(&b, "\nWarning: only %d images can be attached per command; %d more were skipped", maxAttachPerCommand, overCap)
+	}
+	return borderedResponseMsg{content: b.String(), isCommand: true}
+}
+
 // detectsImageRef returns true if text appears to contain an image path or data URL
 func detectsImageRef(text string) bool {
 	if strings.Contains(strings.ToLower(text), "data:image/") {
@@ -2660,29 +3496,6 @@ func detectsImageRef(text string) bool {
 	return false
 }
 
-// saveClipboardPNG runs `pngpaste` to save the clipboard image to a temporary PNG file
-func saveClipboardPNG() (string, error) {
-	f, err := os.CreateTemp("", "simple-agent-clipboard-*.png")
-	if err != nil {
-		return "", err
-	}
-	path := f.Name()
-	_ = f.Close()
-	cmd := exec.Command("pngpaste", path)
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		_ = os.Remove(path)
-		if len(out) > 0 {
-			return "", errors.New(string(out))
-		}
-		return "", err
-	}
-	if !fileExists(path) {
-		return "", fmt.Errorf("pngpaste produced no file")
-	}
-	return path, nil
-}
-
 // updateSuggestions updates the slash-command suggestions based on current input
 func (m *BorderedTUI) updateSuggestions() {
 	cur := strings.TrimSpace(m.textarea.Value())
@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textarea"
+)
+
+func newAttachTestTUI() *BorderedTUI {
+	return &BorderedTUI{
+		textarea:       textarea.New(),
+		supportsVision: true,
+		pathSeen:       map[string]struct{}{},
+		dataURLSeen:    map[string]struct{}{},
+	}
+}
+
+func writeAttachFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+}
+
+func TestAttach_DirectoryAttachesOnlyImages(t *testing.T) {
+	dir := t.TempDir()
+	writeAttachFiles(t, dir, "a.png", "b.JPG", "notes.txt", "data.json", "c.webp")
+	if err := os.Mkdir(filepath.Join(dir, "nested.png"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	m := newAttachTestTUI()
+	resp := m.handleCommand("/attach " + dir)
+	if len(m.attachments) != 3 {
+		t.Fatalf("expected 3 image attachments, got %+v (%s)", m.attachments, resp.content)
+	}
+	for i, want := range []string{"a.png", "b.JPG", "c.webp"} {
+		if filepath.Base(m.attachments[i].Ref) != want {
+			t.Fatalf("attachment %d: expected %s, got %s", i+1, want, m.attachments[i].Ref)
+		}
+	}
+	if got := m.textarea.Value(); got != " [Image #1] [Image #2] [Image #3]" {
+		t.Fatalf("expected one token per image, got %q", got)
+	}
+
+	resp = m.handleCommand("/attach " + filepath.Join(dir, "*.png"))
+	if len(m.attachments) != 3 || !strings.Contains(resp.content, "1 already attached") {
+		t.Fatalf("expected the glob to skip the attached image, got %d attachments (%s)", len(m.attachments), resp.content)
+	}
+}
+
+func TestAttach_GlobCapsImagesPerCommand(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < maxAttachPerCommand+2; i++ {
+		writeAttachFiles(t, dir, fmt.Sprintf("shot%02d.png", i))
+	}
+	writeAttachFiles(t, dir, "readme.md")
+
+	m := newAttachTestTUI()
+	resp := m.handleCommand("/attach " + filepath.Join(dir, "*"))
+	if len(m.attachments) != maxAttachPerCommand {
+		t.Fatalf("expected %d attachments, got %d", maxAttachPerCommand, len(m.attachments))
+	}
+	if !strings.Contains(resp.content, "Warning:") || !strings.Contains(resp.content, "2 more were skipped") {
+		t.Fatalf("expected a cap warning, got %q", resp.content)
+	}
+
+	if resp := m.handleCommand("/attach " + filepath.Join(dir, "*.gif")); !strings.Contains(resp.content, "No images found") {
+		t.Fatalf("expected no matches, got %q", resp.content)
+	}
+}
+
+func TestAttach_SingleFileUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	writeAttachFiles(t, dir, "photo.png", "notes.txt")
+
+	m := newAttachTestTUI()
+	if resp := m.handleCommand("/attach " + filepath.Join(dir, "photo.png")); resp.content != "Attached photo.png" {
+		t.Fatalf("unexpected response %q", resp.content)
+	}
+	if resp := m.handleCommand("/attach " + filepath.Join(dir, "notes.txt")); !strings.Contains(resp.content, "Failed to attach image") {
+		t.Fatalf("expected a non-image to be rejected, got %q", resp.content)
+	}
+	if len(m.attachments) != 1 || m.textarea.Value() != " [Image #1]" {
+		t.Fatalf("unexpected state: %+v %q", m.attachments, m.textarea.Value())
+	}
+}
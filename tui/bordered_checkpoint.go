@@ -0,0 +1,157 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nachoal/simple-agent-go/agent"
+	"github.com/nachoal/simple-agent-go/history"
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// handleCheckpointCommand saves the conversation so far as a named branch of
+// the session that /branch can return to. Without a name the checkpoint is
+// numbered.
+func (m *BorderedTUI) handleCheckpointCommand(cmd string) borderedResponseMsg {
+	if m.isThinking {
+		return borderedResponseMsg{content: "Wait for the current run to finish (or press Esc) before saving a checkpoint.", isCommand: true}
+	}
+	session := m.checkpointSession()
+	name := strings.TrimSpace(cmd[len("/checkpoint"):])
+	if name == "" {
+		for n := len(session.Branches) + 1; ; n++ {
+			if _, taken := session.Branches[fmt.Sprintf("checkpoint-%d", n)]; !taken {
+				name = fmt.Sprintf("checkpoint-%d", n)
+				break
+			}
+		}
+	}
+
+	messages := m.checkpointMessages()
+	_, replaced := session.Branches[name]
+	session.SetCheckpoint(name, messages)
+	if err := m.saveSession(); err != nil {
+		return borderedResponseMsg{content: fmt.Sprintf("Checkpoint %q saved for this run only: %v", name, err), isCommand: true}
+	}
+	m.tracef("checkpoint_save name=%q messages=%d", name, len(messages))
+
+	verb := "Saved"
+	if replaced {
+		verb = "Replaced"
+	}
+	return borderedResponseMsg{
+		content:   fmt.Sprintf("%s checkpoint %q (%d messages). Use /branch %s to return to it.", verb, name, conversationLength(messages), name),
+		isCommand: true,
+	}
+}
+
+// handleBranchCommand restores the conversation saved by /checkpoint name,
+// discarding the messages that came after it. Without a name it lists the
+// session's checkpoints.
+func (m *BorderedTUI) handleBranchCommand(cmd string) borderedResponseMsg {
+	session := m.checkpointSession()
+	name := strings.TrimSpace(cmd[len("/branch"):])
+	if name == "" {
+		names := session.CheckpointNames()
+		if len(names) == 0 {
+			return borderedResponseMsg{content: "No checkpoints yet. Use /checkpoint [name] to save one.", isCommand: true}
+		}
+		var b strings.Builder
+		b.WriteString("Checkpoints:\n")
+		for _, name := range names {
+			messages, _ := session.Checkpoint(name)
+			fmt.Fprintf(&b, "  %s (%d messages)\n", name, conversationLength(messages))
+		}
+		b.WriteString("\nUse /branch <name> to restore one.")
+		return borderedResponseMsg{content: b.String(), isCommand: true}
+	}
+	if m.isThinking {
+		return borderedResponseMsg{content: "Wait for the current run to finish (or press Esc) before switching branches.", isCommand: true}
+	}
+
+	messages, ok := session.Checkpoint(name)
+	if !ok {
+		return borderedResponseMsg{content: fmt.Sprintf("No checkpoint named %q. Use /branch to list them.", name), isCommand: true}
+	}
+	dropped := conversationLength(m.checkpointMessages()) - conversationLength(messages)
+	if dropped < 0 {
+		dropped = 0
+	}
+	m.restoreCheckpoint(session, messages)
+	if err := m.saveSession(); err != nil {
+		m.tracef("checkpoint_restore_save error=%q", err.Error())
+	}
+	m.tracef("checkpoint_restore name=%q messages=%d", name, len(messages))
+
+	return borderedResponseMsg{
+		content:   fmt.Sprintf("Restored checkpoint %q (%d messages); %d later messages were discarded.", name, conversationLength(messages), dropped),
+		isCommand: true,
+	}
+}
+
+// checkpointMessages returns what a checkpoint saves: the agent's memory,
+// which includes tool calls and results, or the UI history without an agent.
+func (m BorderedTUI) checkpointMessages() []llm.Message {
+	if m.agent != nil {
+		return m.agent.GetMemory()
+	}
+	return m.historyForAgent
+}
+
+// conversationLength counts the messages other than the system prompt
+func conversationLength(messages []llm.Message) int {
+	n := 0
+	for _, msg := range messages {
+		if msg.Role != llm.RoleSystem {
+			n++
+		}
+	}
+	return n
+}
+
+// restoreCheckpoint replaces the agent's memory, the UI history, the
+// transcript, and the saved session's messages with the checkpoint's.
+func (m *BorderedTUI) restoreCheckpoint(session *history.Session, messages []llm.Message) {
+	if m.agent != nil {
+		m.agent.SetMemory(messages)
+	}
+	m.historyForAgent = make([]llm.Message, 0, len(messages))
+	m.transcript = nil
+	m.streamingMessage = nil
+	for _, msg := range messages {
+		if msg.Role == llm.RoleSystem {
+			continue
+		}
+		m.historyForAgent = append(m.historyForAgent, msg)
+
+		content := llm.GetStringValue(msg.Content)
+		switch {
+		case msg.Role == llm.RoleUser:
+			m.transcript = append(m.transcript, transcriptEntry{kind: transcriptUser, content: content})
+		case msg.Role == llm.RoleAssistant && strings.TrimSpace(content) != "":
+			m.transcript = append(m.transcript, transcriptEntry{kind: transcriptAssistant, content: content})
+		}
+	}
+	session.SetMessages(history.FromLLMMessages(messages))
+	m.refreshTranscriptView(true)
+}
+
+// checkpointSession returns the saved session checkpoints belong to. Without
+// one, checkpoints are kept in memory for the life of the TUI.
+func (m *BorderedTUI) checkpointSession() *history.Session {
+	if session := m.savedSession(); session != nil {
+		return session
+	}
+	if m.scratchSession == nil {
+		m.scratchSession = &history.Session{}
+	}
+	return m.scratchSession
+}
+
+// saveSession writes the session, with its checkpoints and tags, to disk
+func (m *BorderedTUI) saveSession() error {
+	if historyAgent, ok := m.agent.(*agent.HistoryAgent); ok {
+		return historyAgent.SaveSessionMetadata()
+	}
+	return nil
+}
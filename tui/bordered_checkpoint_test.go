@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+func TestCheckpointCommandSavesConversation(t *testing.T) {
+	m, _ := editTestTUI()
+
+	resp := m.handleCommand("/checkpoint before-read")
+	if !resp.isCommand || !strings.Contains(resp.content, `Saved checkpoint "before-read" (6 messages)`) {
+		t.Fatalf("unexpected confirmation %+v", resp)
+	}
+	saved, ok := m.checkpointSession().Checkpoint("before-read")
+	if !ok || len(saved) != 7 {
+		t.Fatalf("expected the agent's 7 memory messages saved, got %v %d", ok, len(saved))
+	}
+
+	resp = m.handleCommand("/checkpoint")
+	if !strings.Contains(resp.content, `"checkpoint-2"`) {
+		t.Fatalf("expected an unnamed checkpoint to be numbered, got %q", resp.content)
+	}
+	list := m.handleCommand("/branch").content
+	if !strings.Contains(list, "before-read (6 messages)") || !strings.Contains(list, "checkpoint-2") {
+		t.Fatalf("expected both checkpoints listed, got %q", list)
+	}
+}
+
+func TestBranchCommandRestoresCheckpoint(t *testing.T) {
+	m, stub := editTestTUI()
+	m.handleCommand("/checkpoint start")
+
+	// Continue the conversation past the checkpoint.
+	for _, msg := range []llm.Message{textMessage("user", "now delete it"), textMessage("assistant", "Deleted")} {
+		stub.memory = append(stub.memory, msg)
+		m.historyForAgent = append(m.historyForAgent, msg)
+	}
+	m.transcript = append(m.transcript,
+		transcriptEntry{kind: transcriptUser, content: "now delete it"},
+		transcriptEntry{kind: transcriptAssistant, content: "Deleted"})
+
+	resp := m.handleCommand("/branch start")
+	if !resp.isCommand || !strings.Contains(resp.content, `Restored checkpoint "start"`) || !strings.Contains(resp.content, "2 later messages were discarded") {
+		t.Fatalf("unexpected confirmation %+v", resp)
+	}
+	if len(stub.memory) != 7 || llm.GetStringValue(stub.memory[len(stub.memory)-1].Content) != "It prints hello" {
+		t.Fatalf("expected memory restored to the checkpoint, got %+v", stub.memory)
+	}
+	if len(m.historyForAgent) != 6 {
+		t.Fatalf("expected the history without the system prompt, got %d messages", len(m.historyForAgent))
+	}
+	for _, entry := range m.transcript {
+		if entry.content == "now delete it" || entry.content == "Deleted" {
+			t.Fatalf("expected messages after the checkpoint dropped from the transcript, got %+v", m.transcript)
+		}
+	}
+	if last := m.transcript[len(m.transcript)-1]; last.kind != transcriptAssistant || last.content != "It prints hello" {
+		t.Fatalf("expected the transcript to end at the checkpoint, got %+v", last)
+	}
+}
+
+func TestBranchCommandRejectsUnknownCheckpoint(t *testing.T) {
+	m, stub := editTestTUI()
+
+	resp := m.handleCommand("/branch nope")
+	if !strings.Contains(resp.content, `No checkpoint named "nope"`) {
+		t.Fatalf("unexpected response %q", resp.content)
+	}
+	if len(stub.memory) != 7 || len(m.historyForAgent) != 4 {
+		t.Fatal("expected the conversation left untouched")
+	}
+}
@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nachoal/simple-agent-go/tools"
+)
+
+// commandConfirmer relays tool confirmation requests (such as the bash
+// tool's confirm mode) from the agent goroutine to the UI for one run.
+type commandConfirmer struct {
+	requests chan confirmRequest
+	done     chan struct{} // closed when the run's stream ends
+}
+
+// confirmRequest is a tool call waiting for the user's y/n
+type confirmRequest struct {
+	call    tools.ToolCall
+	request *tools.ToolError
+	reply   chan bool
+}
+
+// confirmRequestMsg asks the UI to prompt for a pending confirmation
+type confirmRequestMsg struct {
+	req confirmRequest
+}
+
+func newCommandConfirmer() *commandConfirmer {
+	return &commandConfirmer{
+		requests: make(chan confirmRequest),
+		done:     make(chan struct{}),
+	}
+}
+
+// confirm implements tools.Confirmer. It blocks until the user answers; a
+// cancelled run counts as declined.
+func (c *commandConfirmer) confirm(ctx context.Context, call tools.ToolCall, request *tools.ToolError) bool {
+	reply := make(chan bool, 1)
+	select {
+	case c.requests <- confirmRequest{call: call, request: request, reply: reply}:
+	case <-ctx.Done():
+		return false
+	}
+	select {
+	case approved := <-reply:
+		return approved
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// listen waits for the next confirmation request of the run
+func (c *commandConfirmer) listen() tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case req := <-c.requests:
+			return confirmRequestMsg{req: req}
+		case <-c.done:
+			return nil
+		}
+	}
+}
+
+// confirmPrompt describes a confirmation request for the transcript
+func confirmPrompt(req confirmRequest) string {
+	subject := req.call.Name
+	if command, ok := req.request.Details["command"].(string); ok && command != "" {
+		subject = fmt.Sprintf("%s: %s", req.call.Name, command)
+	}
+	return fmt.Sprintf("⚠️  Approve %s ? Press y to run it or n to decline.", subject)
+}
+
+// answerConfirmation replies to the pending confirmation and resumes
+// listening for the next one.
+func (m *BorderedTUI) answerConfirmation(approved bool) tea.Cmd {
+	req := m.pendingConfirm
+	m.pendingConfirm = nil
+	req.reply <- approved
+	if approved {
+		m.appendTranscript(transcriptTool, "✅ Approved")
+	} else {
+		m.appendTranscript(transcriptTool, "🚫 Declined")
+	}
+	if m.confirmer == nil {
+		return nil
+	}
+	return m.confirmer.listen()
+}
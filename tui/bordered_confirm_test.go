@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nachoal/simple-agent-go/tools"
+)
+
+func TestConfirmationPromptApprovesOnY(t *testing.T) {
+	ta := textarea.New()
+	ta.Focus()
+	confirmer := newCommandConfirmer()
+	defer close(confirmer.done)
+
+	var m tea.Model = BorderedTUI{
+		textarea:             ta,
+		borderStyle:          lipgloss.NewStyle().Border(lipgloss.RoundedBorder()),
+		activeTools:          map[string]*ActiveTool{},
+		toolsUsedInLastQuery: map[string]time.Duration{},
+		isThinking:           true,
+		confirmer:            confirmer,
+	}
+
+	answers := make(chan bool, 1)
+	go func() {
+		request := tools.NewToolError(tools.ConfirmationRequired, "needs approval").WithDetail("command", "rm -rf build")
+		answers <- confirmer.confirm(context.Background(), tools.ToolCall{Name: "bash"}, request)
+	}()
+
+	m, _ = m.Update(confirmer.listen()())
+	tui := m.(BorderedTUI)
+	if tui.pendingConfirm == nil {
+		t.Fatalf("expected a pending confirmation")
+	}
+	if last := tui.transcript[len(tui.transcript)-1]; !strings.Contains(last.content, "bash: rm -rf build") {
+		t.Fatalf("expected the command in the prompt, got %q", last.content)
+	}
+
+	// Other keys are swallowed rather than typed into the input
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	tui = m.(BorderedTUI)
+
+	select {
+	case approved := <-answers:
+		if !approved {
+			t.Fatalf("expected y to approve")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("confirmer never got an answer")
+	}
+	if tui.pendingConfirm != nil || tui.textarea.Value() != "" {
+		t.Fatalf("expected the prompt to be cleared without typing, textarea=%q", tui.textarea.Value())
+	}
+	if cmd == nil {
+		t.Fatalf("expected to keep listening for further prompts")
+	}
+}
+
+func TestConfirmerDeclinesWhenRunIsCancelled(t *testing.T) {
+	confirmer := newCommandConfirmer()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if confirmer.confirm(ctx, tools.ToolCall{Name: "bash"}, tools.NewToolError(tools.ConfirmationRequired, "x")) {
+		t.Fatalf("expected a cancelled run to decline")
+	}
+}
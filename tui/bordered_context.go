@@ -0,0 +1,131 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nachoal/simple-agent-go/agent"
+)
+
+// defaultContextWindow is assumed for models missing from models.json and
+// modelContextWindows
+const defaultContextWindow = 32768
+
+// The status line's token count turns amber, then red, as the estimated
+// context passes these fractions of the model's window.
+const (
+	contextWarnRatio  = 0.75
+	contextAlertRatio = 0.9
+)
+
+// modelContextWindows maps model ID prefixes to context windows in tokens.
+// The longest matching prefix wins, so "gpt-4o" is not read as "gpt-4".
+var modelContextWindows = map[string]int{
+	"gpt-4.1":          1047576,
+	"gpt-4o":           128000,
+	"gpt-4-turbo":      128000,
+	"gpt-4":            8192,
+	"gpt-3.5-turbo":    16385,
+	"o1":               200000,
+	"o3":               200000,
+	"o4":               200000,
+	"claude":           200000,
+	"minimax":          1000000,
+	"moonshot-v1-8k":   8192,
+	"moonshot-v1-32k":  32768,
+	"moonshot-v1-128k": 128000,
+	"kimi":             128000,
+	"deepseek":         64000,
+	"mistral-large":    128000,
+	"codestral":        256000,
+	"command-r":        128000,
+	"llama-3.1":        128000,
+	"llama-3.3":        128000,
+	"mixtral-8x7b":     32768,
+	"gemini":           1000000,
+	"qwen":             32768,
+	"llama2":           4096,
+	"llama3":           8192,
+	"llama-3.1-sonar":  127072,
+}
+
+// contextWindow returns the current model's context window: its
+// contextWindow in models.json, then modelContextWindows, then the default.
+func (m BorderedTUI) contextWindow() int {
+	if m.staticModelsLoader != nil {
+		for _, model := range m.staticModelsLoader()[m.provider] {
+			if model.ID == m.model && model.ContextWindow > 0 {
+				return model.ContextWindow
+			}
+		}
+	}
+	return lookupContextWindow(m.model)
+}
+
+func lookupContextWindow(model string) int {
+	id := strings.ToLower(model)
+	// Provider-qualified IDs such as "meta-llama/llama-3.1-8b" match on the
+	// model name.
+	if i := strings.LastIndex(id, "/"); i >= 0 {
+		id = id[i+1:]
+	}
+	best, window := "", defaultContextWindow
+	for prefix, tokens := range modelContextWindows {
+		if strings.HasPrefix(id, prefix) && len(prefix) > len(best) {
+			best, window = prefix, tokens
+		}
+	}
+	return window
+}
+
+// refreshContextTokens re-estimates the context size when the conversation
+// or model has changed since the last estimate.
+func (m *BorderedTUI) refreshContextTokens() {
+	key := fmt.Sprintf("%s/%s/%d", m.provider, m.model, len(m.historyForAgent))
+	if key == m.contextTokensKey {
+		return
+	}
+	m.contextTokensKey = key
+
+	memory := m.historyForAgent
+	if m.agent != nil {
+		memory = m.agent.GetMemory()
+	}
+	m.contextTokens = agent.EstimateMemoryTokens(memory).Total()
+	m.contextLimit = m.contextWindow()
+}
+
+// contextTokenStatus returns the status line's "Tokens: ~N/MAX" part and the
+// style to draw it in.
+func (m BorderedTUI) contextTokenStatus() (string, lipgloss.Style) {
+	limit := m.contextLimit
+	if limit <= 0 {
+		limit = defaultContextWindow
+	}
+	text := fmt.Sprintf("Tokens: ~%s/%s", formatTokenCount(m.contextTokens), formatTokenCount(limit))
+
+	ratio := float64(m.contextTokens) / float64(limit)
+	switch {
+	case ratio >= contextAlertRatio:
+		return text, themeColor(activeTheme.Error).Bold(true)
+	case ratio >= contextWarnRatio:
+		return text, themeColor(activeTheme.Warning)
+	default:
+		return text, themeColor(activeTheme.TextDim)
+	}
+}
+
+// formatTokenCount shortens large counts: 950, 12.3k, 128k, 1.0M
+func formatTokenCount(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 100_000:
+		return fmt.Sprintf("%dk", n/1000)
+	case n >= 1000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
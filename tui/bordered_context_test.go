@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+func TestStatusLineShowsContextTokenEstimate(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	t.Cleanup(func() { lipgloss.SetColorProfile(profile) })
+
+	m := BorderedTUI{
+		textarea:       textarea.New(),
+		model:          "llama2", // 4096-token window
+		provider:       "ollama",
+		borderStyle:    lipgloss.NewStyle().Border(lipgloss.RoundedBorder()),
+		transcriptView: viewport.New(120, 10),
+		width:          160,
+		height:         24,
+		historyForAgent: []llm.Message{
+			textMessage("user", strings.Repeat("u", 400)), // 100 + 4
+		},
+	}
+	m.syncLayout(true)
+
+	view := m.View()
+	if !strings.Contains(stripANSI(view), "Tokens: ~104/4.1k") {
+		t.Fatalf("expected the token estimate in the status line, got:\n%s", stripANSI(view))
+	}
+	if _, style := m.contextTokenStatus(); style.GetForeground() != activeTheme.TextDim {
+		t.Fatalf("expected the dim color below the threshold, got %v", style.GetForeground())
+	}
+
+	// Past 90% of the window the count turns red
+	m.historyForAgent = append(m.historyForAgent, textMessage("assistant", strings.Repeat("a", 15000)))
+	m.syncLayout(true)
+	text, style := m.contextTokenStatus()
+	if text != "Tokens: ~3.9k/4.1k" || style.GetForeground() != activeTheme.Error {
+		t.Fatalf("expected a red estimate near the limit, got %q in %v", text, style.GetForeground())
+	}
+	if !strings.Contains(m.View(), style.Render(text)) {
+		t.Fatal("expected the status line to draw the token count in the alert color")
+	}
+}
+
+func TestLookupContextWindowUsesLongestPrefix(t *testing.T) {
+	for model, want := range map[string]int{
+		"gpt-4o-mini":                128000,
+		"gpt-4-0613":                 8192,
+		"claude-3-5-sonnet-20241022": 200000,
+		"meta-llama/llama-3.1-8b":    128000,
+		"something-new":              defaultContextWindow,
+	} {
+		if got := lookupContextWindow(model); got != want {
+			t.Errorf("%s: expected %d, got %d", model, want, got)
+		}
+	}
+}
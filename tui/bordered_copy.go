@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// copyNoticeDuration is how long "Copied!" stays on the model info line
+const copyNoticeDuration = 2 * time.Second
+
+// clipboardWrite is a variable so tests can stand in for the system
+// clipboard. It uses pbcopy on macOS, xclip, xsel or wl-copy on Linux, and
+// the clipboard API on Windows.
+var clipboardWrite = clipboard.WriteAll
+
+var fencedCodeRe = regexp.MustCompile("(?s)```[^\n]*\n(.*?)```")
+
+// handleCopyCommand copies a message to the system clipboard: the last
+// response by default, the nth most recent message with "/copy n", or the
+// last response's first fenced code block with "/copy code". Without a
+// usable clipboard the content is printed for the user to select instead.
+func (m *BorderedTUI) handleCopyCommand(cmd string) borderedResponseMsg {
+	arg := strings.ToLower(strings.TrimSpace(cmd[len("/copy"):]))
+
+	var content string
+	switch arg {
+	case "", "code":
+		content = m.lastAssistantContent()
+		if content == "" {
+			return borderedResponseMsg{content: "No response to copy yet.", isCommand: true}
+		}
+		if arg == "code" {
+			match := fencedCodeRe.FindStringSubmatch(content)
+			if match == nil {
+				return borderedResponseMsg{content: "The last response has no code block.", isCommand: true}
+			}
+			content = strings.TrimSuffix(match[1], "\n")
+		}
+	default:
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 {
+			return borderedResponseMsg{content: "Usage: /copy [n|code]", isCommand: true}
+		}
+		messages := m.copyableMessages()
+		if n > len(messages) {
+			return borderedResponseMsg{content: fmt.Sprintf("Only %d messages to copy.", len(messages)), isCommand: true}
+		}
+		content = messages[len(messages)-n]
+	}
+
+	if err := clipboardWrite(content); err != nil {
+		m.tracef("copy_fallback error=%q", err.Error())
+		return borderedResponseMsg{content: "Copy this:\n\n" + content, isCommand: true}
+	}
+	return borderedResponseMsg{infoNotice: "Copied!"}
+}
+
+// copyableMessages returns the text of the conversation's user and
+// assistant messages, oldest first
+func (m BorderedTUI) copyableMessages() []string {
+	var messages []string
+	for _, msg := range m.historyForAgent {
+		if msg.Role != llm.RoleUser && msg.Role != llm.RoleAssistant {
+			continue
+		}
+		if content := strings.TrimSpace(llm.GetStringValue(msg.Content)); content != "" {
+			messages = append(messages, content)
+		}
+	}
+	return messages
+}
+
+func (m BorderedTUI) lastAssistantContent() string {
+	for i := len(m.historyForAgent) - 1; i >= 0; i-- {
+		msg := m.historyForAgent[i]
+		if msg.Role != llm.RoleAssistant {
+			continue
+		}
+		if content := strings.TrimSpace(llm.GetStringValue(msg.Content)); content != "" {
+			return content
+		}
+	}
+	return ""
+}
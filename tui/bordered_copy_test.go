@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+func stubClipboard(t *testing.T, err error) *string {
+	t.Helper()
+	var copied string
+	original := clipboardWrite
+	clipboardWrite = func(text string) error {
+		copied = text
+		return err
+	}
+	t.Cleanup(func() { clipboardWrite = original })
+	return &copied
+}
+
+func copyTestTUI() *BorderedTUI {
+	return &BorderedTUI{
+		agent: &memoryStubAgent{},
+		historyForAgent: []llm.Message{
+			{Role: llm.RoleSystem, Content: llm.StringPtr("system")},
+			{Role: llm.RoleUser, Content: llm.StringPtr("write hello world")},
+			{Role: llm.RoleAssistant, Content: llm.StringPtr("Here it is:\n\n```go\nfmt.Println(\"hi\")\n```\n\nand ```sh\necho two\n```")},
+			{Role: llm.RoleTool, Content: llm.StringPtr("tool output")},
+		},
+	}
+}
+
+func TestCopyCommandCopiesLastResponse(t *testing.T) {
+	copied := stubClipboard(t, nil)
+	m := copyTestTUI()
+
+	resp := m.handleCopyCommand("/copy")
+	if resp.infoNotice != "Copied!" || !strings.HasPrefix(*copied, "Here it is:") {
+		t.Fatalf("expected the last response to be copied, got notice %q and %q", resp.infoNotice, *copied)
+	}
+
+	m.handleCopyCommand("/copy 2")
+	if *copied != "write hello world" {
+		t.Fatalf("expected the second most recent message, got %q", *copied)
+	}
+
+	m.handleCopyCommand("/copy code")
+	if *copied != "fmt.Println(\"hi\")" {
+		t.Fatalf("expected only the first code block, got %q", *copied)
+	}
+
+	if resp := m.handleCopyCommand("/copy 3"); !strings.Contains(resp.content, "Only 2 messages") {
+		t.Fatalf("expected an out-of-range message, got %q", resp.content)
+	}
+}
+
+func TestCopyCommandFallsBackToPrinting(t *testing.T) {
+	stubClipboard(t, errors.New("no clipboard utilities available"))
+	m := copyTestTUI()
+
+	resp := m.handleCopyCommand("/copy code")
+	if !resp.isCommand || resp.content != "Copy this:\n\nfmt.Println(\"hi\")" {
+		t.Fatalf("expected the content printed for manual copying, got %+v", resp)
+	}
+}
+
+func TestCopiedNoticeShowsOnModelInfoLineThenClears(t *testing.T) {
+	stubClipboard(t, nil)
+	m := copyTestTUI()
+	m.model = "test-model"
+	m.provider = "openai"
+	m.width, m.height = 100, 20
+	m.textarea = textarea.New()
+	m.borderStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder())
+
+	updated, cmd := m.Update(m.handleCopyCommand("/copy"))
+	tui := updated.(BorderedTUI)
+	if cmd == nil || !strings.Contains(stripANSI(tui.View()), "Copied! | Model: test-model") {
+		t.Fatalf("expected Copied! on the model info line:\n%s", stripANSI(tui.View()))
+	}
+
+	updated, _ = tui.Update(clearInfoNoticeMsg{id: tui.infoNoticeID})
+	if strings.Contains(stripANSI(updated.(BorderedTUI).View()), "Copied!") {
+		t.Fatal("expected the notice to clear")
+	}
+}
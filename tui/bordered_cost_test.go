@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/llm/cost"
+)
+
+func TestStatusCommandShowsSessionCost(t *testing.T) {
+	tracker := cost.NewCostEstimator(cost.PriceTable{"test-model": {InputPerMTok: 1, OutputPerMTok: 2}})
+	tracker.RecordUsage("test-model", &llm.Usage{PromptTokens: 1_000_000, CompletionTokens: 250_000})
+	tracker.RecordUsage("local-model", &llm.Usage{PromptTokens: 10})
+
+	m := BorderedTUI{agent: &memoryStubAgent{}, provider: "openai", model: "test-model"}
+	m.SetCostTracker(tracker, 5)
+
+	resp := m.handleCommand("/status")
+	for _, want := range []string{
+		"Estimated cost       │ $1.5000 of $5.00",
+		"1 requests to unpriced models are not in the cost",
+	} {
+		if !strings.Contains(resp.content, want) {
+			t.Fatalf("expected %q in:\n%s", want, resp.content)
+		}
+	}
+}
+
+func TestStatusCommandShowsLastQueryCost(t *testing.T) {
+	tracker := cost.NewCostEstimator(cost.PriceTable{"test-model": {InputPerMTok: 1, OutputPerMTok: 2}})
+	m := BorderedTUI{agent: &memoryStubAgent{}, provider: "openai", model: "test-model"}
+	m.SetCostTracker(tracker, 0)
+	m.lastUsage = &llm.Usage{PromptTokens: 2000, CompletionTokens: 500}
+
+	if resp := m.handleCommand("/status"); !strings.Contains(resp.content, "Last query cost      │ $0.0030") {
+		t.Fatalf("expected the last query's cost in:\n%s", resp.content)
+	}
+
+	m.model = "local-model"
+	if resp := m.handleCommand("/status"); !strings.Contains(resp.content, "Last query cost      │ unavailable") {
+		t.Fatalf("expected an unknown model's cost to be unavailable in:\n%s", resp.content)
+	}
+}
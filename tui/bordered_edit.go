@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// handleEditCommand loads a past user message into the input for editing.
+// "/edit n" picks the nth most recent one (default: the last). The
+// conversation is rewound to just before that message, so sending the
+// edited text starts a new branch from there. "/edit last-tool" instead
+// loads the arguments of the last tool call, leaving the conversation as is.
+func (m *BorderedTUI) handleEditCommand(cmd string) borderedResponseMsg {
+	if m.isThinking {
+		return borderedResponseMsg{content: "Wait for the current run to finish (or press Esc) before editing.", isCommand: true}
+	}
+	arg := strings.ToLower(strings.TrimSpace(cmd[len("/edit"):]))
+	if arg == "last-tool" {
+		return m.editLastToolCall()
+	}
+
+	n := 1
+	if arg != "" {
+		var err error
+		if n, err = strconv.Atoi(arg); err != nil || n < 1 {
+			return borderedResponseMsg{content: "Usage: /edit [n|last-tool]", isCommand: true}
+		}
+	}
+
+	index, seen := -1, 0
+	for i := len(m.historyForAgent) - 1; i >= 0; i-- {
+		if m.historyForAgent[i].Role == llm.RoleUser {
+			if seen++; seen == n {
+				index = i
+				break
+			}
+		}
+	}
+	if index < 0 {
+		if seen == 0 {
+			return borderedResponseMsg{content: "Nothing to edit yet: send a message first.", isCommand: true}
+		}
+		return borderedResponseMsg{content: fmt.Sprintf("Only %d messages to edit.", seen), isCommand: true}
+	}
+	content := llm.GetStringValue(m.historyForAgent[index].Content)
+	m.rewindToUserMessage(index, n)
+
+	m.textarea.SetValue(content)
+	m.editMode = true
+	return borderedResponseMsg{notice: "Editing: the conversation was rewound to this message. Press Enter to send."}
+}
+
+// rewindToUserMessage drops historyForAgent[index], the nth most recent user
+// message, and everything after it from the history, the agent's memory, and
+// the transcript. Memory can hold user messages the TUI never showed (the
+// agent's own nudges), so the message is found there by its content,
+// skipping later user messages with the same text.
+func (m *BorderedTUI) rewindToUserMessage(index, n int) {
+	content := llm.GetStringValue(m.historyForAgent[index].Content)
+	later := 0
+	for _, msg := range m.historyForAgent[index+1:] {
+		if msg.Role == llm.RoleUser && llm.GetStringValue(msg.Content) == content {
+			later++
+		}
+	}
+	m.historyForAgent = m.historyForAgent[:index]
+
+	if m.agent != nil {
+		memory := m.agent.GetMemory()
+		for i := len(memory) - 1; i >= 0; i-- {
+			if memory[i].Role != llm.RoleUser || llm.GetStringValue(memory[i].Content) != content {
+				continue
+			}
+			if later > 0 {
+				later--
+				continue
+			}
+			m.agent.SetMemory(memory[:i])
+			break
+		}
+	}
+
+	seen := 0
+	for i := len(m.transcript) - 1; i >= 0; i-- {
+		if m.transcript[i].kind == transcriptUser {
+			if seen++; seen == n {
+				m.transcript = m.transcript[:i]
+				break
+			}
+		}
+	}
+	m.refreshTranscriptView(true)
+}
+
+// editLastToolCall loads the most recent tool call into the input as a
+// request to run it again with arguments the user can adjust
+func (m *BorderedTUI) editLastToolCall() borderedResponseMsg {
+	var memory []llm.Message
+	if m.agent != nil {
+		memory = m.agent.GetMemory()
+	}
+	for i := len(memory) - 1; i >= 0; i-- {
+		calls := memory[i].ToolCalls
+		if memory[i].Role != llm.RoleAssistant || len(calls) == 0 {
+			continue
+		}
+		call := calls[len(calls)-1]
+		args := string(call.Function.Arguments)
+		var pretty bytes.Buffer
+		if json.Indent(&pretty, call.Function.Arguments, "", "  ") == nil {
+			args = pretty.String()
+		}
+		m.textarea.SetValue(fmt.Sprintf("Call %s again with these arguments:\n%s", call.Function.Name, args))
+		m.editMode = true
+		return borderedResponseMsg{notice: fmt.Sprintf("Editing the last %s call. Press Enter to send.", call.Function.Name)}
+	}
+	return borderedResponseMsg{content: "No tool calls to edit yet.", isCommand: true}
+}
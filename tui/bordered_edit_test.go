@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+func editTestTUI() (*BorderedTUI, *memoryStubAgent) {
+	stub := &memoryStubAgent{memory: []llm.Message{
+		textMessage("system", "sys"),
+		textMessage("user", "list the files"),
+		{Role: llm.RoleAssistant, ToolCalls: []llm.ToolCall{{ID: "1", Type: "function", Function: llm.FunctionCall{Name: "directory_list", Arguments: []byte(`{"path":"src"}`)}}}},
+		textMessage("tool", "main.go"),
+		textMessage("assistant", "There is main.go"),
+		textMessage("user", "read it"),
+		textMessage("assistant", "It prints hello"),
+	}}
+	m := &BorderedTUI{
+		agent:       stub,
+		textarea:    textarea.New(),
+		borderStyle: lipgloss.NewStyle().Border(lipgloss.RoundedBorder()),
+		historyForAgent: []llm.Message{
+			textMessage("user", "list the files"),
+			textMessage("assistant", "There is main.go"),
+			textMessage("user", "read it"),
+			textMessage("assistant", "It prints hello"),
+		},
+		transcript: []transcriptEntry{
+			{kind: transcriptUser, content: "list the files"},
+			{kind: transcriptTool, content: "🔧 Calling tool: directory_list"},
+			{kind: transcriptAssistant, content: "There is main.go"},
+			{kind: transcriptUser, content: "read it"},
+			{kind: transcriptAssistant, content: "It prints hello"},
+		},
+	}
+	return m, stub
+}
+
+func TestEditCommandRewindsToTheEditedMessage(t *testing.T) {
+	m, stub := editTestTUI()
+
+	resp := m.handleCommand("/edit 2")
+	if resp.notice == "" || !m.editMode {
+		t.Fatalf("expected edit mode with a notice, got %+v", resp)
+	}
+	if got := m.textarea.Value(); got != "list the files" {
+		t.Fatalf("expected the second most recent message in the input, got %q", got)
+	}
+	if len(m.historyForAgent) != 0 || len(m.transcript) != 0 {
+		t.Fatalf("expected history and transcript rewound to the start, got %+v / %+v", m.historyForAgent, m.transcript)
+	}
+	if len(stub.memory) != 1 || stub.memory[0].Role != llm.RoleSystem {
+		t.Fatalf("expected only the system prompt left in memory, got %+v", stub.memory)
+	}
+}
+
+func TestEditCommandDefaultsToLastMessage(t *testing.T) {
+	m, stub := editTestTUI()
+
+	m.handleCommand("/edit")
+	if got := m.textarea.Value(); got != "read it" {
+		t.Fatalf("expected the last user message in the input, got %q", got)
+	}
+	if len(m.historyForAgent) != 2 || len(m.transcript) != 3 || len(stub.memory) != 5 {
+		t.Fatalf("expected the last exchange dropped, got %d history, %d transcript, %d memory",
+			len(m.historyForAgent), len(m.transcript), len(stub.memory))
+	}
+
+	if resp := m.handleCommand("/edit 5"); !strings.Contains(resp.content, "Only 1 messages") {
+		t.Fatalf("expected an out-of-range message, got %+v", resp)
+	}
+}
+
+func TestEditLastToolLoadsArguments(t *testing.T) {
+	m, stub := editTestTUI()
+
+	m.handleCommand("/edit last-tool")
+	want := "Call directory_list again with these arguments:\n{\n  \"path\": \"src\"\n}"
+	if got := m.textarea.Value(); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if len(stub.memory) != 7 || len(m.historyForAgent) != 4 {
+		t.Fatal("expected /edit last-tool to leave the conversation alone")
+	}
+}
+
+func TestEditModeEndsWhenTheMessageIsSent(t *testing.T) {
+	m, _ := editTestTUI()
+	m.width, m.height = 80, 20
+
+	m.handleCommand("/edit")
+	m.textarea.SetValue("read it and explain")
+	m.textarea.Focus()
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	updated := updatedModel.(BorderedTUI)
+	defer updated.cancelActiveRun("test")
+
+	if updated.editMode {
+		t.Fatal("expected edit mode to end once the message is sent")
+	}
+	last := updated.historyForAgent[len(updated.historyForAgent)-1]
+	if len(updated.historyForAgent) != 3 || llm.GetStringValue(last.Content) != "read it and explain" {
+		t.Fatalf("expected the edited message to replace the original, got %+v", updated.historyForAgent)
+	}
+}
@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+func TestExportCommand_FormatsAndPaths(t *testing.T) {
+	m := &BorderedTUI{
+		agent: &memoryStubAgent{},
+		historyForAgent: []llm.Message{
+			textMessage("user", "What is 6*7?"),
+			textMessage("assistant", "It is 42."),
+		},
+	}
+	dir := t.TempDir()
+
+	mdPath := filepath.Join(dir, "chat.md")
+	if resp := m.handleCommand("/export " + mdPath); !strings.Contains(resp.content, mdPath) {
+		t.Fatalf("unexpected response %q", resp.content)
+	}
+	data, err := os.ReadFile(mdPath)
+	if err != nil || !strings.Contains(string(data), "## User\n\n") || !strings.Contains(string(data), "## Assistant\n\n") {
+		t.Fatalf("unexpected Markdown export (%v):\n%s", err, data)
+	}
+
+	jsonPath := filepath.Join(dir, "chat.out")
+	m.handleCommand("/export json " + jsonPath)
+	data, _ = os.ReadFile(jsonPath)
+	var messages []llm.Message
+	if err := json.Unmarshal(data, &messages); err != nil || len(messages) != 2 {
+		t.Fatalf("unexpected JSON export (%v):\n%s", err, data)
+	}
+
+	if resp := m.handleCommand("/export pdf " + jsonPath); !strings.Contains(resp.content, "unsupported export format") {
+		t.Fatalf("expected an unsupported format error, got %q", resp.content)
+	}
+}
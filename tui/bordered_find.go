@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// handleFindCommand scrolls the transcript to the next line containing the
+// query, ignoring case. Repeating the same query, or "/find" on its own,
+// moves on to the following match and wraps around at the end.
+func (m *BorderedTUI) handleFindCommand(cmd string) borderedResponseMsg {
+	query := strings.TrimSpace(cmd[len("/find"):])
+	if query == "" {
+		query = m.findQuery
+	}
+	if query == "" {
+		return borderedResponseMsg{content: "Usage: /find <text> (repeat to jump to the next match)", isCommand: true}
+	}
+	if !strings.EqualFold(query, m.findQuery) {
+		m.findQuery = query
+		m.findLine = -1
+	}
+
+	matches := transcriptMatchLines(m.renderTranscriptContent(), query)
+	if len(matches) == 0 {
+		m.findLine = -1
+		return borderedResponseMsg{notice: fmt.Sprintf("No matches for %q", query)}
+	}
+
+	index := 0
+	for i, line := range matches {
+		if line > m.findLine {
+			index = i
+			break
+		}
+	}
+	m.findLine = matches[index]
+	m.transcriptView.SetYOffset(m.findLine)
+	return borderedResponseMsg{notice: fmt.Sprintf("Match %d of %d for %q (PgUp/PgDn to scroll)", index+1, len(matches), query)}
+}
+
+// transcriptMatchLines returns the indexes of the rendered transcript lines
+// that contain query, ignoring case and styling.
+func transcriptMatchLines(content, query string) []int {
+	needle := strings.ToLower(query)
+	var lines []int
+	for i, line := range strings.Split(ansi.Strip(content), "\n") {
+		if strings.Contains(strings.ToLower(line), needle) {
+			lines = append(lines, i)
+		}
+	}
+	return lines
+}
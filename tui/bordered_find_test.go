@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func newFindTestTUI() *BorderedTUI {
+	m := &BorderedTUI{
+		textarea:       textarea.New(),
+		borderStyle:    lipgloss.NewStyle().Border(lipgloss.RoundedBorder()),
+		transcriptView: viewport.New(60, 10),
+		width:          60,
+		height:         14,
+	}
+	m.syncLayout(true)
+	return m
+}
+
+func TestTranscriptViewportFollowsNewMessages(t *testing.T) {
+	m := newFindTestTUI()
+	for i := 1; i <= 20; i++ {
+		m.appendTranscript(transcriptCommand, fmt.Sprintf("message %d", i))
+	}
+
+	if !m.transcriptView.AtBottom() {
+		t.Fatal("expected the viewport to follow new messages")
+	}
+	view := stripANSI(m.transcriptView.View())
+	if !strings.Contains(view, "message 20") || strings.Contains(view, "message 1\n") {
+		t.Fatalf("expected only the latest messages in view, got:\n%s", view)
+	}
+
+	m.transcriptView.HalfPageUp()
+	m.refreshTranscriptView(false)
+	if m.transcriptView.AtBottom() {
+		t.Fatal("expected scrolling up to stay put on refresh")
+	}
+
+	m.appendTranscript(transcriptCommand, "message 21")
+	if !m.transcriptView.AtBottom() || !strings.Contains(stripANSI(m.transcriptView.View()), "message 21") {
+		t.Fatal("expected a new message to scroll back to the bottom")
+	}
+}
+
+func TestFindCommandScrollsToMatches(t *testing.T) {
+	m := newFindTestTUI()
+	for i := 1; i <= 20; i++ {
+		text := fmt.Sprintf("message %d", i)
+		if i == 3 || i == 12 {
+			text += " needle"
+		}
+		m.appendTranscript(transcriptCommand, text)
+	}
+
+	resp := m.handleCommand("/find NEEDLE")
+	if !strings.Contains(resp.notice, "Match 1 of 2") {
+		t.Fatalf("unexpected notice %q", resp.notice)
+	}
+	if first := strings.Split(stripANSI(m.transcriptView.View()), "\n")[0]; !strings.Contains(first, "message 3 needle") {
+		t.Fatalf("expected the first match at the top of the view, got %q", first)
+	}
+
+	resp = m.handleCommand("/find")
+	if !strings.Contains(resp.notice, "Match 2 of 2") {
+		t.Fatalf("expected /find to repeat the last query, got %q", resp.notice)
+	}
+	if first := strings.Split(stripANSI(m.transcriptView.View()), "\n")[0]; !strings.Contains(first, "message 12 needle") {
+		t.Fatalf("expected the second match at the top of the view, got %q", first)
+	}
+
+	if resp = m.handleCommand("/find needle"); !strings.Contains(resp.notice, "Match 1 of 2") {
+		t.Fatalf("expected the search to wrap around, got %q", resp.notice)
+	}
+	if resp = m.handleCommand("/find haystack"); !strings.Contains(resp.notice, "No matches") {
+		t.Fatalf("expected no matches, got %q", resp.notice)
+	}
+}
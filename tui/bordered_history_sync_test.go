@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nachoal/simple-agent-go/agent"
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// memoryAgent reports a fixed memory, as the agent would after a tool run.
+type memoryAgent struct {
+	blockingStreamAgent
+	memory []llm.Message
+}
+
+func (a memoryAgent) GetMemory() []llm.Message { return append([]llm.Message(nil), a.memory...) }
+
+func TestCompletedRunSyncsHistoryWithToolCalls(t *testing.T) {
+	toolCall := llm.ToolCall{
+		ID:       "call_1",
+		Type:     "function",
+		Function: llm.FunctionCall{Name: "calculate", Arguments: json.RawMessage(`{"expression":"6*7"}`)},
+	}
+	memory := []llm.Message{
+		textMessage("system", "You are helpful."),
+		textMessage("user", "What is 6*7?"),
+		{Role: llm.RoleAssistant, Content: llm.StringPtr(""), ToolCalls: []llm.ToolCall{toolCall}},
+		{Role: llm.RoleTool, Content: llm.StringPtr("42"), ToolCallID: "call_1"},
+		textMessage("assistant", "It is 42."),
+	}
+
+	var m tea.Model = BorderedTUI{
+		agent:                memoryAgent{memory: memory},
+		textarea:             textarea.New(),
+		borderStyle:          lipgloss.NewStyle().Border(lipgloss.RoundedBorder()),
+		activeTools:          map[string]*ActiveTool{},
+		toolsUsedInLastQuery: map[string]time.Duration{},
+		isThinking:           true,
+		historyForAgent:      []llm.Message{textMessage("user", "What is 6*7?")},
+	}
+	final := "It is 42."
+	m, _ = m.Update(toolEventMsg{event: agent.StreamEvent{
+		Type:    agent.EventTypeMessageEnd,
+		Message: &llm.Message{Role: llm.RoleAssistant, Content: &final},
+	}})
+	m, _ = m.Update(toolEventMsg{event: agent.StreamEvent{Type: agent.EventTypeComplete}})
+	tui := m.(BorderedTUI)
+
+	if len(tui.historyForAgent) != 4 {
+		t.Fatalf("expected history synced from agent memory without the system prompt, got %+v", tui.historyForAgent)
+	}
+	if calls := tui.historyForAgent[1].ToolCalls; len(calls) != 1 || calls[0].Function.Name != "calculate" {
+		t.Fatalf("expected assistant tool call in history, got %+v", tui.historyForAgent[1])
+	}
+	if result := tui.historyForAgent[2]; result.Role != llm.RoleTool || result.ToolCallID != "call_1" {
+		t.Fatalf("expected tool result in history, got %+v", result)
+	}
+
+	path := filepath.Join(t.TempDir(), "chat.md")
+	tui.handleCommand("/save " + path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read export: %v", err)
+	}
+	if !strings.Contains(string(data), "Called `calculate` with `{\"expression\":\"6*7\"}`") || !strings.Contains(string(data), "## Tool result\n\n42") {
+		t.Fatalf("expected tool call and result in export:\n%s", data)
+	}
+}
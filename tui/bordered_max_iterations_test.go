@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nachoal/simple-agent-go/agent"
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+func TestMaxIterationsKeepsPartialAnswerWithNote(t *testing.T) {
+	var m tea.Model = BorderedTUI{
+		textarea:             textarea.New(),
+		borderStyle:          lipgloss.NewStyle().Border(lipgloss.RoundedBorder()),
+		activeTools:          map[string]*ActiveTool{},
+		toolsUsedInLastQuery: map[string]time.Duration{},
+		isThinking:           true,
+	}
+	partial := "Found 2 of 3 files so far."
+	m, _ = m.Update(toolEventMsg{event: agent.StreamEvent{
+		Type:    agent.EventTypeMessageEnd,
+		Message: &llm.Message{Role: llm.RoleAssistant, Content: &partial},
+	}})
+	m, _ = m.Update(toolEventMsg{event: agent.StreamEvent{
+		Type:       agent.EventTypeMaxIterations,
+		Content:    partial,
+		Iterations: 10,
+	}})
+	tui := m.(BorderedTUI)
+
+	if tui.isThinking {
+		t.Fatal("expected the run to end")
+	}
+	if len(tui.transcript) != 2 {
+		t.Fatalf("expected partial answer and note, got %+v", tui.transcript)
+	}
+	if got := tui.transcript[0]; got.kind != transcriptAssistant || got.content != partial {
+		t.Fatalf("expected partial answer to stay in the transcript, got %+v", got)
+	}
+	if got := tui.transcript[1]; got.kind != transcriptTool || got.content != "(stopped after 10 tool iterations)" {
+		t.Fatalf("expected stop note, got %+v", got)
+	}
+}
+
+func TestLengthFinishWarnsResponseWasCutOff(t *testing.T) {
+	var m tea.Model = BorderedTUI{
+		textarea:             textarea.New(),
+		borderStyle:          lipgloss.NewStyle().Border(lipgloss.RoundedBorder()),
+		activeTools:          map[string]*ActiveTool{},
+		toolsUsedInLastQuery: map[string]time.Duration{},
+		isThinking:           true,
+	}
+	partial := "The first half of"
+	m, _ = m.Update(toolEventMsg{event: agent.StreamEvent{
+		Type:    agent.EventTypeMessageEnd,
+		Message: &llm.Message{Role: llm.RoleAssistant, Content: &partial},
+	}})
+	m, _ = m.Update(toolEventMsg{event: agent.StreamEvent{
+		Type:         agent.EventTypeComplete,
+		FinishReason: "length",
+	}})
+	tui := m.(BorderedTUI)
+
+	last := tui.transcript[len(tui.transcript)-1]
+	if last.kind != transcriptTool || !strings.Contains(last.content, "cut off at the token limit") {
+		t.Fatalf("expected a cut-off warning, got %+v", tui.transcript)
+	}
+}
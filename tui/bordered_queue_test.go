@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nachoal/simple-agent-go/agent"
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+func TestEnterWhileThinkingQueuesMessageForRun(t *testing.T) {
+	ta := textarea.New()
+	ta.Focus()
+	ta.SetValue("review the code")
+
+	var m tea.Model = BorderedTUI{
+		textarea:             ta,
+		borderStyle:          lipgloss.NewStyle().Border(lipgloss.RoundedBorder()),
+		activeTools:          map[string]*ActiveTool{},
+		toolsUsedInLastQuery: map[string]time.Duration{},
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.(BorderedTUI).isThinking || m.(BorderedTUI).messageQueue == nil {
+		t.Fatalf("expected a running query with a message queue")
+	}
+
+	for _, r := range "focus on auth" {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	tui := m.(BorderedTUI)
+	if tui.textarea.Value() != "" || tui.messageQueue.Len() != 1 {
+		t.Fatalf("expected input to be queued, textarea=%q queued=%d", tui.textarea.Value(), tui.messageQueue.Len())
+	}
+
+	// The agent picks the message up at its next step.
+	delivered := tui.messageQueue.Drain()
+	m, _ = m.Update(toolEventMsg{event: agent.StreamEvent{Type: agent.EventTypeUserMessage, Content: delivered[0]}})
+	tui = m.(BorderedTUI)
+	last := tui.transcript[len(tui.transcript)-1]
+	if last.kind != transcriptUser || last.content != "focus on auth" {
+		t.Fatalf("expected delivered message in transcript, got %+v", last)
+	}
+	if got := tui.historyForAgent[len(tui.historyForAgent)-1]; got.Role != llm.RoleUser || llm.GetStringValue(got.Content) != "focus on auth" {
+		t.Fatalf("expected delivered message in agent history, got %+v", got)
+	}
+}
+
+func TestQueuedMessageAfterFinalStepStartsFollowUp(t *testing.T) {
+	queue := agent.NewMessageQueue()
+	queue.Push("now write the tests")
+
+	var m tea.Model = BorderedTUI{
+		agent:                blockingStreamAgent{},
+		textarea:             textarea.New(),
+		borderStyle:          lipgloss.NewStyle().Border(lipgloss.RoundedBorder()),
+		activeTools:          map[string]*ActiveTool{},
+		toolsUsedInLastQuery: map[string]time.Duration{},
+		isThinking:           true,
+		messageQueue:         queue,
+	}
+	m, _ = m.Update(toolEventMsg{event: agent.StreamEvent{Type: agent.EventTypeComplete}})
+	tui := m.(BorderedTUI)
+
+	if !tui.isThinking || tui.activeRunID == "" {
+		t.Fatalf("expected a follow-up run to start")
+	}
+	last := tui.transcript[len(tui.transcript)-1]
+	if last.kind != transcriptUser || last.content != "now write the tests" {
+		t.Fatalf("expected queued message as the next user turn, got %+v", last)
+	}
+	if queue.Len() != 0 || tui.messageQueue == queue {
+		t.Fatalf("expected the old queue to be drained and replaced")
+	}
+}
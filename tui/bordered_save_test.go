@@ -0,0 +1,42 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+func TestSaveCommandWritesMarkdown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chat.md")
+	m := BorderedTUI{
+		historyForAgent: []llm.Message{
+			textMessage("user", "How do I print in Go?"),
+			textMessage("assistant", "Use fmt:\n\n```go\nfmt.Println(\"hi\")\n```"),
+		},
+	}
+
+	resp := m.handleCommand("/save " + path)
+	if !resp.isCommand || !strings.Contains(resp.content, path) {
+		t.Fatalf("expected saved path in command message, got %+v", resp)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read export: %v", err)
+	}
+	want := "# Conversation\n\n## You\n\nHow do I print in Go?\n\n## Assistant\n\nUse fmt:\n\n```go\nfmt.Println(\"hi\")\n```\n"
+	if string(data) != want {
+		t.Fatalf("unexpected export:\n%s", data)
+	}
+}
+
+func TestSaveCommandWithoutHistory(t *testing.T) {
+	m := BorderedTUI{}
+	resp := m.handleCommand("/save")
+	if !strings.Contains(resp.content, "Nothing to save") {
+		t.Fatalf("expected empty-history message, got %+v", resp)
+	}
+}
@@ -0,0 +1,137 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// sessionStats accumulates what /status and the stats bar report about the
+// current TUI session.
+type sessionStats struct {
+	started   time.Time
+	usage     llm.Usage     // Provider-reported tokens, summed over finished runs
+	runs      int           // Finished runs, cancelled and failed ones included
+	latency   time.Duration // Total wall time of the finished runs
+	toolCalls int
+}
+
+// finishRun records a run that started at started and reported usage, which
+// may be nil.
+func (s *sessionStats) finishRun(started time.Time, usage *llm.Usage) {
+	if !started.IsZero() {
+		s.runs++
+		s.latency += time.Since(started)
+	}
+	if usage != nil {
+		s.usage.PromptTokens += usage.PromptTokens
+		s.usage.CompletionTokens += usage.CompletionTokens
+		s.usage.TotalTokens += usage.PromptTokens + usage.CompletionTokens
+	}
+}
+
+func (s sessionStats) averageLatency() time.Duration {
+	if s.runs == 0 {
+		return 0
+	}
+	return s.latency / time.Duration(s.runs)
+}
+
+// recordRunEnd adds the active run to the session stats. Runs can end more
+// than once (a stream's Complete event, then its response message), so only
+// the first call for a run counts.
+func (m *BorderedTUI) recordRunEnd() {
+	if m.activeRunID == "" {
+		return
+	}
+	m.stats.finishRun(m.runStarted, m.lastUsage)
+	m.runStarted = time.Time{}
+}
+
+// sessionStatsTable renders the /status breakdown as a plain-bordered table;
+// command output is restyled as a whole, so the cells carry no colors.
+func (m BorderedTUI) sessionStatsTable() string {
+	costText, unpriced := "n/a", 0
+	if m.costTracker != nil {
+		session := m.costTracker.Session()
+		costText, unpriced = formatCostBrief(session.USD, m.maxCost), session.Unpriced
+	}
+	lastCost := "n/a"
+	if m.lastUsage != nil && m.costTracker != nil {
+		lastCost = "unavailable"
+		if price, ok := m.costTracker.Prices().Lookup(m.model); ok {
+			lastCost = fmt.Sprintf("$%.4f", price.Cost(m.lastUsage.PromptTokens, m.lastUsage.CompletionTokens))
+		}
+	}
+	latency := "n/a"
+	if m.stats.runs > 0 {
+		latency = formatStatsDuration(m.stats.averageLatency())
+	}
+	duration := time.Duration(0)
+	if !m.stats.started.IsZero() {
+		duration = time.Since(m.stats.started)
+	}
+
+	t := table.New().
+		Border(lipgloss.RoundedBorder()).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			return lipgloss.NewStyle().Padding(0, 1)
+		}).
+		Rows(
+			[]string{"Messages", fmt.Sprintf("%d", len(m.historyForAgent))},
+			[]string{"Prompt tokens", fmt.Sprintf("%d", m.stats.usage.PromptTokens)},
+			[]string{"Completion tokens", fmt.Sprintf("%d", m.stats.usage.CompletionTokens)},
+			[]string{"Estimated cost", costText},
+			[]string{"Last query cost", lastCost},
+			[]string{"Tool calls", fmt.Sprintf("%d", m.stats.toolCalls)},
+			[]string{"Avg response latency", latency},
+			[]string{"Session duration", formatStatsDuration(duration)},
+		)
+	out := t.Render()
+	if unpriced > 0 {
+		out += fmt.Sprintf("\n%d requests to unpriced models are not in the cost", unpriced)
+	}
+	return out
+}
+
+// statsBar returns the one-line session summary drawn under the input box
+func (m BorderedTUI) statsBar() string {
+	usage := m.stats.usage
+	parts := []string{fmt.Sprintf("Session: %s tokens (%s in / %s out)",
+		formatTokenCount(usage.PromptTokens+usage.CompletionTokens),
+		formatTokenCount(usage.PromptTokens),
+		formatTokenCount(usage.CompletionTokens))}
+	if m.costTracker != nil {
+		parts = append(parts, "Cost: "+formatCostBrief(m.costTracker.Session().USD, m.maxCost))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// formatCostBrief renders spending so far, against the budget when one is set
+func formatCostBrief(usd, maxCost float64) string {
+	if maxCost > 0 {
+		return fmt.Sprintf("$%.4f of $%.2f", usd, maxCost)
+	}
+	return fmt.Sprintf("$%.4f", usd)
+}
+
+// formatStatsDuration rounds to what a person reads at a glance: 850ms, 4.2s,
+// 3m12s, 1h05m.
+func formatStatsDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	case d < time.Hour:
+		d = d.Round(time.Second)
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		d = d.Round(time.Minute)
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/llm/cost"
+)
+
+func TestSessionStatsCountEachRunOnce(t *testing.T) {
+	m := BorderedTUI{agent: &memoryStubAgent{}, provider: "openai", model: "test-model"}
+	m.activeRunID = "run-1"
+	m.runStarted = time.Now().Add(-2 * time.Second)
+	m.lastUsage = &llm.Usage{PromptTokens: 1200, CompletionTokens: 300}
+
+	// A streamed run is cleared by its Complete event and again by the
+	// response message that follows.
+	m.clearActiveRun()
+	m.clearActiveRun()
+
+	if m.stats.runs != 1 || m.stats.usage.PromptTokens != 1200 || m.stats.usage.CompletionTokens != 300 {
+		t.Fatalf("unexpected stats after one run: %+v", m.stats)
+	}
+	if avg := m.stats.averageLatency(); avg < 2*time.Second {
+		t.Fatalf("expected average latency of at least 2s, got %s", avg)
+	}
+}
+
+func TestStatusCommandShowsSessionStatsTable(t *testing.T) {
+	m := BorderedTUI{agent: &memoryStubAgent{}, provider: "openai", model: "test-model"}
+	m.historyForAgent = []llm.Message{{Role: llm.RoleUser}, {Role: llm.RoleAssistant}}
+	m.stats = sessionStats{
+		started:   time.Now().Add(-90 * time.Second),
+		usage:     llm.Usage{PromptTokens: 4000, CompletionTokens: 500},
+		runs:      2,
+		latency:   3 * time.Second,
+		toolCalls: 5,
+	}
+
+	resp := m.handleCommand("/status")
+	for _, want := range []string{
+		"│ Messages             │ 2",
+		"│ Prompt tokens        │ 4000",
+		"│ Completion tokens    │ 500",
+		"│ Estimated cost       │ n/a",
+		"│ Tool calls           │ 5",
+		"│ Avg response latency │ 1.5s",
+		"│ Session duration     │ 1m30s",
+	} {
+		if !strings.Contains(resp.content, want) {
+			t.Fatalf("expected %q in:\n%s", want, resp.content)
+		}
+	}
+}
+
+func TestViewEndsWithStatsBar(t *testing.T) {
+	m := BorderedTUI{
+		agent:       &memoryStubAgent{},
+		textarea:    textarea.New(),
+		model:       "test-model",
+		provider:    "openai",
+		borderStyle: lipgloss.NewStyle().Border(lipgloss.RoundedBorder()),
+		width:       100,
+		height:      20,
+	}
+	m.SetCostTracker(cost.NewCostEstimator(cost.PriceTable{"test-model": {InputPerMTok: 1, OutputPerMTok: 2}}), 0)
+	m.costTracker.RecordUsage("test-model", &llm.Usage{PromptTokens: 12_000, CompletionTokens: 1500})
+	m.stats.usage = llm.Usage{PromptTokens: 12_000, CompletionTokens: 1500}
+
+	updated, _ := m.Update(borderedResponseMsg{content: "done"})
+	lines := strings.Split(stripANSI(updated.(BorderedTUI).View()), "\n")
+	last := lines[len(lines)-1]
+	want := "Session: 13.5k tokens (12.0k in / 1.5k out) | Cost: $0.0150"
+	if last != want {
+		t.Fatalf("expected stats bar %q on the last line, got %q", want, last)
+	}
+	if len(lines) != 20 {
+		t.Fatalf("expected the view to fill 20 lines, got %d", len(lines))
+	}
+}
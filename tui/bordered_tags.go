@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nachoal/simple-agent-go/agent"
+	"github.com/nachoal/simple-agent-go/history"
+)
+
+// handleTagCommand tags the saved session with "/tag <name>", or lists its
+// tags with "/tag". Tags are lowercased and can filter the --resume picker.
+func (m *BorderedTUI) handleTagCommand(cmd string) borderedResponseMsg {
+	session := m.savedSession()
+	if session == nil {
+		return borderedResponseMsg{content: "This conversation is not being saved, so it cannot be tagged.", isCommand: true}
+	}
+	tag := history.NormalizeTag(cmd[len("/tag"):])
+	if tag == "" {
+		if len(session.Metadata.Tags) == 0 {
+			return borderedResponseMsg{content: "No tags yet. Use /tag <name> to add one.", isCommand: true}
+		}
+		return borderedResponseMsg{content: "Tags: " + strings.Join(session.Metadata.Tags, ", "), isCommand: true}
+	}
+	if strings.ContainsAny(tag, " \t") {
+		return borderedResponseMsg{content: "Tags cannot contain spaces.", isCommand: true}
+	}
+	if !session.AddTag(tag) {
+		return borderedResponseMsg{content: fmt.Sprintf("Already tagged %q.", tag), isCommand: true}
+	}
+	if err := m.saveSession(); err != nil {
+		return borderedResponseMsg{content: fmt.Sprintf("Tagged %q for this run only: %v", tag, err), isCommand: true}
+	}
+	m.tracef("session_tag add=%q", tag)
+	return borderedResponseMsg{infoNotice: fmt.Sprintf("Tagged %q", tag)}
+}
+
+// handleUntagCommand removes a tag from the saved session
+func (m *BorderedTUI) handleUntagCommand(cmd string) borderedResponseMsg {
+	session := m.savedSession()
+	if session == nil {
+		return borderedResponseMsg{content: "This conversation is not being saved, so it has no tags.", isCommand: true}
+	}
+	tag := history.NormalizeTag(cmd[len("/untag"):])
+	if tag == "" {
+		return borderedResponseMsg{content: "Usage: /untag <name>", isCommand: true}
+	}
+	if !session.RemoveTag(tag) {
+		return borderedResponseMsg{content: fmt.Sprintf("Not tagged %q.", tag), isCommand: true}
+	}
+	if err := m.saveSession(); err != nil {
+		return borderedResponseMsg{content: fmt.Sprintf("Removed %q for this run only: %v", tag, err), isCommand: true}
+	}
+	m.tracef("session_tag remove=%q", tag)
+	return borderedResponseMsg{infoNotice: fmt.Sprintf("Removed tag %q", tag)}
+}
+
+// savedSession returns the session being saved to disk, or nil when the
+// conversation is not saved
+func (m *BorderedTUI) savedSession() *history.Session {
+	if historyAgent, ok := m.agent.(*agent.HistoryAgent); ok {
+		return historyAgent.GetSession()
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nachoal/simple-agent-go/agent"
+	"github.com/nachoal/simple-agent-go/history"
+)
+
+func TestTagCommandsPersistTags(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	mgr, err := history.NewManager()
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	session, err := mgr.StartSession("/tmp/project", "openai", "gpt-4o")
+	if err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+	m := &BorderedTUI{agent: agent.NewHistoryAgent(agent.New(noopLLMClient{}), mgr, session)}
+
+	for _, cmd := range []string{"/tag Release", "/tag bugs", "/untag BUGS"} {
+		if resp := m.handleCommand(cmd); resp.infoNotice == "" {
+			t.Fatalf("%s: expected a confirmation, got %q", cmd, resp.content)
+		}
+	}
+	if resp := m.handleCommand("/untag bugs"); !strings.Contains(resp.content, "Not tagged") {
+		t.Fatalf("expected removing a missing tag to say so, got %q", resp.content)
+	}
+	if resp := m.handleCommand("/tag"); resp.content != "Tags: release" {
+		t.Fatalf("expected the tag list, got %q", resp.content)
+	}
+
+	loaded, err := mgr.LoadSession(session.ID)
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if strings.Join(loaded.Metadata.Tags, ",") != "release" {
+		t.Fatalf("expected the release tag saved, got %q", loaded.Metadata.Tags)
+	}
+}
+
+func TestTagCommandWithoutSavedSession(t *testing.T) {
+	m := &BorderedTUI{}
+	if resp := m.handleCommand("/tag work"); !strings.Contains(resp.content, "not being saved") {
+		t.Fatalf("expected tagging to need a saved session, got %q", resp.content)
+	}
+}
+
+func TestSessionPickerCyclesTagFilter(t *testing.T) {
+	picker := NewSessionPicker([]history.SessionInfo{
+		{ID: "a", Title: "API work", Tags: []string{"work"}},
+		{ID: "b", Title: "Groceries"},
+		{ID: "c", Title: "Site redesign", Tags: []string{"design", "work"}},
+	})
+	press := func() {
+		picker.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	}
+	ids := func() string {
+		var ids []string
+		for _, session := range picker.sessions {
+			ids = append(ids, session.ID)
+		}
+		return strings.Join(ids, ",")
+	}
+
+	press()
+	if picker.tagFilter != "design" || ids() != "c" {
+		t.Fatalf("expected the first tag's sessions, got %q: %s", picker.tagFilter, ids())
+	}
+	if !strings.Contains(picker.View(), "tagged #design") {
+		t.Fatalf("expected the filter in the title, got %q", picker.View())
+	}
+	press()
+	if picker.tagFilter != "work" || ids() != "a,c" {
+		t.Fatalf("expected the work sessions, got %q: %s", picker.tagFilter, ids())
+	}
+	press()
+	if picker.tagFilter != "" || ids() != "a,b,c" {
+		t.Fatalf("expected every session after the last tag, got %q: %s", picker.tagFilter, ids())
+	}
+
+	picker.Update(tea.KeyMsg{Type: tea.KeyDown})
+	picker.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if picker.SelectedSessionID != "b" {
+		t.Fatalf("expected the highlighted session selected, got %q", picker.SelectedSessionID)
+	}
+}
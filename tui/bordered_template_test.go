@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textarea"
+)
+
+func TestTemplateCommandLoadsRenderedPromptIntoInput(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, ".simple-agent", "templates")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "review.tmpl"), []byte("Review {{.file}} carefully."), 0644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+
+	m := &BorderedTUI{textarea: textarea.New()}
+
+	resp := m.handleCommand("/template review file=main.go")
+	if !resp.isCommand || !strings.Contains(resp.content, "Loaded template review") {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if got := m.textarea.Value(); got != "Review main.go carefully." {
+		t.Fatalf("expected rendered template in input, got %q", got)
+	}
+
+	m.textarea.Reset()
+	resp = m.handleCommand("/template review")
+	if !strings.Contains(resp.content, `requires variable "file"`) {
+		t.Fatalf("expected missing variable error, got %q", resp.content)
+	}
+	if m.textarea.Value() != "" {
+		t.Fatalf("input should be untouched on error, got %q", m.textarea.Value())
+	}
+}
@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	glamourstyles "github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nachoal/simple-agent-go/tui/styles"
+	"golang.org/x/term"
+)
+
+// activeTheme colors the bordered TUI. The transcript render helpers are plain
+// functions, so they read it rather than taking a theme argument.
+var activeTheme = styles.DefaultTheme
+
+// themeColor returns a style with the foreground set to color
+func themeColor(color lipgloss.TerminalColor) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(color)
+}
+
+// inputBorderStyle frames the input box, in the error color while --yolo
+// lets bash run anything.
+func inputBorderStyle(yolo bool) lipgloss.Style {
+	color := activeTheme.Border
+	if yolo {
+		color = activeTheme.Error
+	}
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(color)
+}
+
+// SetTheme switches the TUI to the named built-in theme and re-renders the
+// transcript in its colors. It does not persist the choice; /theme does.
+func (m *BorderedTUI) SetTheme(name string) error {
+	theme, ok := styles.LookupTheme(name)
+	if !ok {
+		return fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(styles.ThemeNames(), ", "))
+	}
+	activeTheme = theme
+	m.spinner.Style = themeColor(theme.Primary)
+	m.borderStyle = inputBorderStyle(m.yoloEnabled)
+	m.renderer = nil
+	m.ensureRenderer()
+	m.refreshTranscriptView(false)
+	return nil
+}
+
+// SetMarkdownStyle renders responses with the named glamour style instead of
+// the theme's, even when stdout is not a terminal. An empty name restores
+// the default.
+func (m *BorderedTUI) SetMarkdownStyle(name string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if _, ok := glamourstyles.DefaultStyles[name]; name != "" && name != glamourstyles.AutoStyle && !ok {
+		names := make([]string, 0, len(glamourstyles.DefaultStyles)+1)
+		names = append(names, glamourstyles.AutoStyle)
+		for styleName := range glamourstyles.DefaultStyles {
+			names = append(names, styleName)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown markdown style %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	m.markdownStyle = name
+	m.renderer = nil
+	m.ensureRenderer()
+	m.refreshTranscriptView(false)
+	return nil
+}
+
+// resolveMarkdownStyle picks the glamour style for output written to out:
+// the configured style when there is one, "notty" when out is not a
+// terminal so piped output carries no escape codes, and otherwise the
+// theme's style.
+func resolveMarkdownStyle(out io.Writer, configured string) string {
+	if configured != "" {
+		return configured
+	}
+	if f, ok := out.(*os.File); !ok || !term.IsTerminal(int(f.Fd())) {
+		return glamourstyles.NoTTYStyle
+	}
+	return activeTheme.GlamourStyle
+}
+
+func newMarkdownRenderer(style string, wrapWidth int) (*glamour.TermRenderer, error) {
+	return glamour.NewTermRenderer(
+		glamour.WithStandardStyle(style),
+		glamour.WithWordWrap(wrapWidth),
+	)
+}
+
+// handleThemeCommand lists the themes or switches to one and saves it as the
+// default for future sessions.
+func (m *BorderedTUI) handleThemeCommand(cmd string) borderedResponseMsg {
+	name := strings.TrimSpace(cmd[len("/theme"):])
+	if name == "" {
+		var b strings.Builder
+		b.WriteString("Themes:\n")
+		for _, themeName := range styles.ThemeNames() {
+			marker := "  "
+			if themeName == activeTheme.Name {
+				marker = "* "
+			}
+			b.WriteString(fmt.Sprintf("  %s%s\n", marker, themeName))
+		}
+		b.WriteString("\nUse /theme <name> to switch.")
+		return borderedResponseMsg{content: b.String(), isCommand: true}
+	}
+
+	if err := m.SetTheme(name); err != nil {
+		return borderedResponseMsg{content: err.Error(), isCommand: true}
+	}
+	if m.configManager != nil {
+		if err := m.configManager.SetTheme(activeTheme.Name); err != nil {
+			return borderedResponseMsg{content: fmt.Sprintf("Switched to the %s theme, but could not save it: %v", activeTheme.Name, err), isCommand: true}
+		}
+	}
+	return borderedResponseMsg{content: fmt.Sprintf("Switched to the %s theme", activeTheme.Name), isCommand: true}
+}
@@ -0,0 +1,148 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/nachoal/simple-agent-go/config"
+	"github.com/nachoal/simple-agent-go/tui/styles"
+)
+
+// useANSI256 makes lipgloss emit 256-color codes and restores the theme and
+// color profile afterwards.
+func useANSI256(t *testing.T) {
+	t.Helper()
+	profile := lipgloss.ColorProfile()
+	theme := activeTheme
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	t.Cleanup(func() {
+		lipgloss.SetColorProfile(profile)
+		activeTheme = theme
+	})
+}
+
+func TestSetThemeChangesRenderedColors(t *testing.T) {
+	useANSI256(t)
+	m := &BorderedTUI{}
+
+	if err := m.SetTheme("default"); err != nil {
+		t.Fatal(err)
+	}
+	// Colors 0-15 render as the basic ANSI codes: 15 (bright white) is 97
+	if got := renderUserMessage("hi", 40); !strings.Contains(got, "\x1b[97mhi") {
+		t.Fatalf("expected the default text color 15, got %q", got)
+	}
+	if got := renderErrorMessage("boom", 40); !strings.Contains(got, "38;5;196m") {
+		t.Fatalf("expected the default error color 196, got %q", got)
+	}
+
+	if err := m.SetTheme("light"); err != nil {
+		t.Fatal(err)
+	}
+	if got := renderUserMessage("hi", 40); !strings.Contains(got, "\x1b[38;5;235mhi") || strings.Contains(got, "97m") {
+		t.Fatalf("expected the light text color 235, got %q", got)
+	}
+	if got := renderToolMessage("running", 40); !strings.Contains(got, "38;5;243m") {
+		t.Fatalf("expected the light dim color 243, got %q", got)
+	}
+
+	if err := m.SetTheme("High-Contrast"); err != nil {
+		t.Fatal(err)
+	}
+	if got := renderErrorMessage("boom", 40); !strings.Contains(got, "\x1b[91m") {
+		t.Fatalf("expected the high-contrast error color 9, got %q", got)
+	}
+	if activeTheme.GlamourStyle != "dark" {
+		t.Fatalf("expected the high-contrast glamour style, got %q", activeTheme.GlamourStyle)
+	}
+}
+
+func TestThemeCommandSwitchesAndPersists(t *testing.T) {
+	useANSI256(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	configManager, err := config.NewManager()
+	if err != nil {
+		t.Fatalf("config.NewManager: %v", err)
+	}
+	m := &BorderedTUI{configManager: configManager}
+
+	resp := m.handleCommand("/theme light")
+	if !strings.Contains(resp.content, "Switched to the light theme") {
+		t.Fatalf("unexpected response %q", resp.content)
+	}
+	if activeTheme.Name != styles.LightTheme.Name {
+		t.Fatalf("expected the light theme to be active, got %q", activeTheme.Name)
+	}
+	reloaded, err := config.NewManager()
+	if err != nil {
+		t.Fatalf("config.NewManager: %v", err)
+	}
+	if got := reloaded.GetTheme(); got != "light" {
+		t.Fatalf("expected the theme to be saved, got %q", got)
+	}
+
+	list := m.handleCommand("/theme")
+	if !strings.Contains(list.content, "* light") || !strings.Contains(list.content, "high-contrast") {
+		t.Fatalf("expected the theme list with light marked, got %q", list.content)
+	}
+
+	unknown := m.handleCommand("/theme neon")
+	if !strings.Contains(unknown.content, `unknown theme "neon"`) || activeTheme.Name != "light" {
+		t.Fatalf("expected an unknown theme error and no change, got %q (active %s)", unknown.content, activeTheme.Name)
+	}
+}
+
+func TestMarkdownRendererForNonTTYHasNoANSI(t *testing.T) {
+	useANSI256(t)
+	m := &BorderedTUI{}
+	if err := m.SetTheme("dracula"); err != nil {
+		t.Fatalf("SetTheme: %v", err)
+	}
+	markdown := "# Title\n\nSome **bold** text and `code`.\n\n- item"
+
+	themed, err := newMarkdownRenderer(activeTheme.GlamourStyle, 60)
+	if err != nil {
+		t.Fatalf("newMarkdownRenderer: %v", err)
+	}
+	if out, _ := themed.Render(markdown); !strings.Contains(out, "\x1b[") {
+		t.Fatalf("expected the theme's style to emit escape codes, got %q", out)
+	}
+
+	var piped strings.Builder
+	style := resolveMarkdownStyle(&piped, "")
+	if style != "notty" {
+		t.Fatalf("expected notty for a non-TTY writer, got %q", style)
+	}
+	renderer, err := newMarkdownRenderer(style, 60)
+	if err != nil {
+		t.Fatalf("newMarkdownRenderer: %v", err)
+	}
+	out, err := renderer.Render(markdown)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if strings.Contains(out, "\x1b[") {
+		t.Fatalf("expected no ANSI escapes for a non-TTY writer, got %q", out)
+	}
+	if !strings.Contains(out, "bold") || !strings.Contains(out, "code") {
+		t.Fatalf("expected the text to survive, got %q", out)
+	}
+}
+
+func TestConfiguredMarkdownStyleWins(t *testing.T) {
+	if got := resolveMarkdownStyle(&strings.Builder{}, "light"); got != "light" {
+		t.Fatalf("expected the configured style, got %q", got)
+	}
+
+	m := &BorderedTUI{}
+	if err := m.SetMarkdownStyle("Dracula"); err != nil || m.markdownStyle != "dracula" {
+		t.Fatalf("expected dracula to be accepted, got %q, %v", m.markdownStyle, err)
+	}
+	if err := m.SetMarkdownStyle("neon"); err == nil || !strings.Contains(err.Error(), "notty") {
+		t.Fatalf("expected an error listing the styles, got %v", err)
+	}
+}
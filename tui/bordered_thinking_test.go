@@ -5,33 +5,33 @@ import (
 	"testing"
 )
 
-func TestSplitThinkingTrace(t *testing.T) {
-	content := "<think>\ninternal reasoning\n</think>\n\nFinal answer."
-	trace, final := splitThinkingTrace(content)
+func TestRenderAssistantMessageCollapsesReasoning(t *testing.T) {
+	t.Setenv("SIMPLE_AGENT_DEBUG", "")
+	content := "<think>weigh the two options</think>\nDone."
+	rendered := renderAssistantMessage(nil, "", content, 40)
 
-	if trace != "internal reasoning" {
-		t.Fatalf("unexpected trace: %q", trace)
+	if !strings.Contains(rendered, "🧠 Reasoning (4 words, /verbose to expand)") {
+		t.Fatalf("expected a collapsed reasoning header, got: %q", rendered)
 	}
-	if final != "Final answer." {
-		t.Fatalf("unexpected final content: %q", final)
+	if strings.Contains(rendered, "weigh") || strings.Contains(rendered, "<think>") {
+		t.Fatalf("expected the reasoning hidden outside verbose mode, got: %q", rendered)
+	}
+	if !strings.Contains(rendered, "Done.") {
+		t.Fatalf("expected final content in output, got: %q", rendered)
 	}
 }
 
-func TestRenderAssistantMessageWithThinkingTrace(t *testing.T) {
+func TestRenderAssistantMessageExpandsReasoningWhenVerbose(t *testing.T) {
+	t.Setenv("SIMPLE_AGENT_DEBUG", "true")
 	content := "<think>plan</think>\nDone."
-	rendered := renderAssistantMessage(nil, content, 40)
+	rendered := renderAssistantMessage(nil, "", content, 40)
 
-	if !strings.Contains(rendered, "<thinking traces>") {
-		t.Fatalf("expected thinking trace start tag, got: %q", rendered)
-	}
-	if !strings.Contains(rendered, "</thinking traces>") {
-		t.Fatalf("expected thinking trace end tag, got: %q", rendered)
+	header := strings.Index(rendered, "🧠 Reasoning")
+	if header < 0 || strings.Contains(rendered, "/verbose to expand") {
+		t.Fatalf("expected an expanded reasoning section, got: %q", rendered)
 	}
-	if !strings.Contains(rendered, "plan") {
-		t.Fatalf("expected thinking content in output, got: %q", rendered)
-	}
-	if !strings.Contains(rendered, "Done.") {
-		t.Fatalf("expected final content in output, got: %q", rendered)
+	if plan, done := strings.Index(rendered, "plan"), strings.Index(rendered, "Done."); plan < header || done < plan {
+		t.Fatalf("expected the reasoning between its header and the response, got: %q", rendered)
 	}
 }
 
@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/agent"
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+func TestTokensCommandSeparatesSystemPrompt(t *testing.T) {
+	stub := &memoryStubAgent{memory: []llm.Message{
+		textMessage("system", strings.Repeat("s", 400)),   // 100 + 4
+		textMessage("user", strings.Repeat("u", 40)),      // 10 + 4
+		textMessage("assistant", strings.Repeat("a", 20)), // 5 + 4
+	}}
+	m := BorderedTUI{agent: stub, lastUsage: &llm.Usage{PromptTokens: 130, CompletionTokens: 7}}
+
+	resp := m.handleCommand("/tokens")
+	if !resp.isCommand {
+		t.Fatalf("expected a command response, got %+v", resp)
+	}
+	for _, want := range []string{
+		"System prompt: 104",
+		"Conversation:  23 (2 messages)",
+		"Total:         127",
+		"130 in / 7 out",
+	} {
+		if !strings.Contains(resp.content, want) {
+			t.Fatalf("expected %q in:\n%s", want, resp.content)
+		}
+	}
+}
+
+func TestTokensCommandListsEachMessage(t *testing.T) {
+	stub := &memoryStubAgent{memory: []llm.Message{
+		textMessage("system", strings.Repeat("s", 400)),                            // 100 + 4
+		textMessage("user", "why is the build\nfailing "+strings.Repeat("x", 200)), // 57 + 4
+		{Role: llm.RoleAssistant, ToolCalls: []llm.ToolCall{{Function: llm.FunctionCall{Name: "bash", Arguments: []byte("{}")}}}},
+	}}
+	m := BorderedTUI{agent: stub, width: 60}
+
+	resp := m.handleCommand("/tokens")
+	lines := strings.Split(resp.content, "\n")
+	for _, want := range []string{
+		"  1  system       104  ssss",
+		"  2  user          61  why is the build failing xxx",
+		"  3  assistant",
+		"[tool calls: bash]",
+		"Total:         ",
+	} {
+		if !strings.Contains(resp.content, want) {
+			t.Fatalf("expected %q in:\n%s", want, resp.content)
+		}
+	}
+	for _, line := range lines {
+		if len([]rune(line)) > m.transcriptWrapWidth() {
+			t.Fatalf("line wider than the transcript: %q", line)
+		}
+	}
+	total := agent.EstimateMemoryTokens(stub.memory).Total()
+	if !strings.Contains(resp.content, fmt.Sprintf("Total:         %d", total)) {
+		t.Fatalf("expected the grand total %d in:\n%s", total, resp.content)
+	}
+}
@@ -0,0 +1,103 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nachoal/simple-agent-go/agent"
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+// toolOnlyStreamClient answers the first turn with a bare tool call and the
+// second with text.
+type toolOnlyStreamClient struct {
+	noopLLMClient
+	calls int
+}
+
+func (c *toolOnlyStreamClient) ChatStream(context.Context, *llm.ChatRequest) (<-chan llm.StreamEvent, error) {
+	c.calls++
+	delta := llm.Message{Content: llm.StringPtr("It is sunny.")}
+	if c.calls == 1 {
+		delta = llm.Message{ToolCalls: []llm.ToolCall{{
+			ID:       "call_1",
+			Type:     "function",
+			Function: llm.FunctionCall{Name: "weather_lookup", Arguments: json.RawMessage(`{"city":"Paris"}`)},
+		}}}
+	}
+	ch := make(chan llm.StreamEvent, 1)
+	ch <- llm.StreamEvent{Choices: []llm.Choice{{Delta: &delta}}}
+	close(ch)
+	return ch, nil
+}
+
+func TestToolOnlyAssistantTurnRendersNoEmptyBubble(t *testing.T) {
+	ag := agent.New(&toolOnlyStreamClient{}, agent.WithSystemPrompt(""))
+	stream, err := ag.QueryStream(context.Background(), "weather in Paris?")
+	if err != nil {
+		t.Fatalf("QueryStream: %v", err)
+	}
+
+	var m tea.Model = BorderedTUI{
+		agent:                ag,
+		textarea:             textarea.New(),
+		borderStyle:          lipgloss.NewStyle().Border(lipgloss.RoundedBorder()),
+		activeTools:          map[string]*ActiveTool{},
+		toolsUsedInLastQuery: map[string]time.Duration{},
+		isThinking:           true,
+	}
+	sawFinal := false
+	for event := range stream {
+		updated, _ := m.Update(toolEventMsg{event: event})
+		m = updated
+		tui := m.(BorderedTUI)
+
+		if event.Type == agent.EventTypeMessageUpdate && len(event.Message.ToolCalls) > 0 {
+			if view := stripANSI(tui.renderTranscriptContent()); !strings.Contains(view, "Thinking...") {
+				t.Fatalf("expected the thinking status while a tool-only turn streams, got:\n%s", view)
+			}
+		}
+		if event.Type == agent.EventTypeMessageEnd && len(event.Message.ToolCalls) > 0 {
+			for _, entry := range tui.transcript {
+				if entry.kind == transcriptAssistant {
+					t.Fatalf("expected no assistant entry for a tool-only turn, got %q", entry.content)
+				}
+			}
+			if view := stripANSI(tui.renderTranscriptContent()); strings.Contains(view, assistantLabel("")) {
+				t.Fatalf("expected no empty assistant bubble, got:\n%s", view)
+			}
+		}
+		if event.Type == agent.EventTypeMessageEnd && llm.GetStringValue(event.Message.Content) == "It is sunny." {
+			sawFinal = true
+		}
+	}
+	if !sawFinal {
+		t.Fatalf("expected the text turn to stream after the tool call")
+	}
+
+	var assistantEntries []string
+	for _, entry := range m.(BorderedTUI).transcript {
+		if entry.kind == transcriptAssistant {
+			assistantEntries = append(assistantEntries, entry.content)
+		}
+	}
+	if len(assistantEntries) != 1 || assistantEntries[0] != "It is sunny." {
+		t.Fatalf("expected only the text turn in the transcript, got %q", assistantEntries)
+	}
+
+	var toolTurn *llm.Message
+	for i, msg := range ag.GetMemory() {
+		if msg.Role == llm.RoleAssistant && len(msg.ToolCalls) > 0 {
+			toolTurn = &ag.GetMemory()[i]
+		}
+	}
+	if toolTurn == nil || strings.TrimSpace(llm.GetStringValue(toolTurn.Content)) != "" || toolTurn.ToolCalls[0].Function.Name != "weather_lookup" {
+		t.Fatalf("expected the tool-call-only turn in agent memory, got %+v", ag.GetMemory())
+	}
+}
@@ -0,0 +1,32 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/tools"
+	"github.com/nachoal/simple-agent-go/tools/registry"
+)
+
+type describedTool struct{}
+
+func (describedTool) Name() string            { return "tui_described" }
+func (describedTool) Description() string     { return "original description" }
+func (describedTool) Parameters() interface{} { return &struct{}{} }
+func (describedTool) Execute(context.Context, json.RawMessage) (string, error) {
+	return "", nil
+}
+
+func TestToolsCommandShowsDescriptionOverride(t *testing.T) {
+	_ = registry.Register("tui_described", func() tools.Tool { return describedTool{} })
+	registry.SetDescription("tui_described", "steered description")
+	t.Cleanup(func() { registry.SetDescription("tui_described", "") })
+
+	m := &BorderedTUI{}
+	resp := m.handleCommand("/tools")
+	if !strings.Contains(resp.content, "steered description") || strings.Contains(resp.content, "original description") {
+		t.Fatalf("expected the overridden description in /tools, got:\n%s", resp.content)
+	}
+}
@@ -30,6 +30,10 @@ func (blockingStreamAgent) SetSystemPrompt(string)                {}
 func (blockingStreamAgent) SetMemory([]llm.Message)               {}
 func (blockingStreamAgent) SetRequestParams(agent.RequestParams)  {}
 func (blockingStreamAgent) GetRequestParams() agent.RequestParams { return agent.RequestParams{} }
+func (blockingStreamAgent) Name() string                          { return "" }
+func (blockingStreamAgent) QueryJSON(context.Context, string, interface{}) (*agent.Response, error) {
+	return nil, nil
+}
 
 func (noopLLMClient) Chat(context.Context, *llm.ChatRequest) (*llm.ChatResponse, error) {
 	return nil, nil
@@ -90,6 +94,7 @@ func TestTruncateToWidth(t *testing.T) {
 }
 
 func TestUpdateStreamingMessageDoesNotPanicOnModelCopy(t *testing.T) {
+	t.Setenv("SIMPLE_AGENT_DEBUG", "")
 	ta := textarea.New()
 	seed := "<think>\nThe user"
 	m := BorderedTUI{
@@ -125,8 +130,10 @@ func TestUpdateStreamingMessageDoesNotPanicOnModelCopy(t *testing.T) {
 		t.Fatalf("unexpected streamed content: %q", got)
 	}
 
+	// Reasoning is collapsed outside verbose mode; its word count follows
+	// the stream.
 	view := stripANSI(updated.View())
-	if !strings.Contains(view, "The user wants a greeting.") {
+	if !strings.Contains(view, "🧠 Reasoning (5 words") {
 		t.Fatalf("expected view to include live streamed content, got: %q", view)
 	}
 }
@@ -308,6 +315,48 @@ func TestResizedHistoryViewDoesNotDuplicateAssistantText(t *testing.T) {
 	}
 }
 
+func TestResumedSessionNamesModelWhereItSwitched(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	historyMgr, err := history.NewManager()
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	session, err := historyMgr.StartSession("/tmp/project", "anthropic", "claude-sonnet-4-20250514")
+	if err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+	text := func(s string) *string { return &s }
+	session.Messages = []history.Message{
+		{Role: "user", Content: text("one")},
+		{Role: "assistant", Content: text("First."), Provider: "openai", Model: "gpt-4o"},
+		{Role: "user", Content: text("two")},
+		{Role: "assistant", Content: text("Second."), Provider: "openai", Model: "gpt-4o"},
+		{Role: "user", Content: text("three")},
+		{Role: "assistant", Content: text("Third."), Provider: "anthropic", Model: "claude-sonnet-4-20250514"},
+	}
+
+	historyAgent := agent.NewHistoryAgent(agent.New(noopLLMClient{}), historyMgr, session)
+	tuiModel := NewBorderedTUIWithHistory(noopLLMClient{}, historyAgent, session.Provider, session.Model, map[string]llm.Client{}, nil)
+
+	var models []string
+	for _, entry := range tuiModel.transcript {
+		if entry.kind == transcriptAssistant {
+			models = append(models, entry.model)
+		}
+	}
+	want := []string{"openai/gpt-4o", "", "anthropic/claude-sonnet-4-20250514"}
+	if strings.Join(models, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected the model named where it changed, got %q", models)
+	}
+	rendered := stripANSI(renderTranscriptEntry(tuiModel.transcript[1], nil, "", 60))
+	if !strings.Contains(rendered, "🤖 Assistant: openai/gpt-4o") {
+		t.Fatalf("expected the model after the label, got %q", rendered)
+	}
+}
+
 func TestStreamingCompletionDoesNotDuplicateAssistantTextAfterResize(t *testing.T) {
 	ta := textarea.New()
 	m := BorderedTUI{
@@ -356,3 +405,15 @@ func TestStreamingCompletionDoesNotDuplicateAssistantTextAfterResize(t *testing.
 		t.Fatalf("expected final assistant text once after resize, got %d in view: %q", count, view)
 	}
 }
+
+func TestRenderAssistantMessageUsesAgentName(t *testing.T) {
+	named := renderAssistantMessage(nil, "Researcher", "Found it.", 40)
+	if !strings.Contains(named, "🤖 Researcher:") {
+		t.Fatalf("expected named assistant label, got %q", named)
+	}
+
+	unnamed := renderAssistantMessage(nil, "", "Found it.", 40)
+	if !strings.Contains(unnamed, "🤖 Assistant:") {
+		t.Fatalf("expected default assistant label, got %q", unnamed)
+	}
+}
@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/nachoal/simple-agent-go/llm/anthropic"
+	"github.com/nachoal/simple-agent-go/llm/ollama"
+	"github.com/nachoal/simple-agent-go/llm/openai"
+)
+
+func TestComputeVisionSupport_OpenAIVisionModels(t *testing.T) {
+	client, err := openai.NewClient(llm.WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	cases := map[string]bool{
+		"gpt-4o":               true,
+		"gpt-4o-mini":          true,
+		"gpt-4-turbo":          true,
+		"gpt-4-vision-preview": true,
+		"chatgpt-4o-latest":    true,
+		"openai/gpt-4o-mini":   true,
+		"gpt-4":                false,
+		"gpt-3.5-turbo":        false,
+	}
+	for model, want := range cases {
+		m := BorderedTUI{llmClient: client, provider: "openai", model: model}
+		if got := m.computeVisionSupport(); got != want {
+			t.Errorf("computeVisionSupport(%q) = %v, want %v", model, got, want)
+		}
+	}
+
+	m := BorderedTUI{llmClient: noopLLMClient{}, provider: "openai", model: "gpt-4o"}
+	if m.computeVisionSupport() {
+		t.Errorf("expected no vision support from a client without image helpers")
+	}
+}
+
+func TestComputeVisionSupport_LocalVisionModels(t *testing.T) {
+	// NewClient needs a running server; only the client's type matters here.
+	client := &ollama.Client{}
+	cases := map[string]bool{
+		"llava:13b":           true,
+		"moondream:latest":    true,
+		"llama3.2-vision:11b": true,
+		"gemma3:4b":           true,
+		"qwen2.5vl:7b":        true,
+		"minicpm-v:8b":        true,
+		"llama3.2:latest":     false,
+		"qwen2.5-coder:7b":    false,
+		"mistral-small:24b":   false,
+	}
+	for _, provider := range []string{"ollama", "lmstudio"} {
+		for model, want := range cases {
+			m := BorderedTUI{llmClient: client, provider: provider, model: model}
+			if got := m.computeVisionSupport(); got != want {
+				t.Errorf("computeVisionSupport(%s, %q) = %v, want %v", provider, model, got, want)
+			}
+		}
+	}
+}
+
+func TestComputeVisionSupport_AnthropicModels(t *testing.T) {
+	client, err := anthropic.NewClient(llm.WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	for _, model := range []string{"claude-3-haiku-20240307", "claude-sonnet-4-20250514"} {
+		m := BorderedTUI{llmClient: client, provider: "anthropic", model: model}
+		if !m.computeVisionSupport() {
+			t.Errorf("expected %s to support vision", model)
+		}
+	}
+}
+
+func TestAttachmentsReportsDownscaledSize(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1600, 800))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 7)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode fixture: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "photo.png")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	m := &BorderedTUI{attachments: []Attachment{{ID: 1, Ref: path}}}
+	resp := m.handleCommand("/attachments")
+	if !strings.Contains(resp.content, "photo.png (") || strings.Contains(resp.content, "resized") {
+		t.Fatalf("expected the plain size without a limit, got %q", resp.content)
+	}
+
+	m.SetImageMaxDimension(400)
+	resp = m.handleCommand("/attachments")
+	if !strings.Contains(resp.content, "resized from") || !strings.Contains(resp.content, "1600x800") {
+		t.Fatalf("expected /attachments to report the downscaled size, got %q", resp.content)
+	}
+}
@@ -0,0 +1,121 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// These are variables so tests can simulate other platforms and tools.
+var (
+	clipboardGOOS     = runtime.GOOS
+	clipboardLookPath = exec.LookPath
+	clipboardGetenv   = os.Getenv
+	clipboardRun      = func(name string, args ...string) ([]byte, []byte, error) {
+		cmd := exec.Command(name, args...)
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		return out, []byte(stderr.String()), err
+	}
+)
+
+// clipboardToolError reports that the clipboard helper for this platform is
+// not installed; its message says how to install it.
+type clipboardToolError struct {
+	msg string
+}
+
+func (e *clipboardToolError) Error() string { return e.msg }
+
+// clipboardCommand is how one platform saves the clipboard image: either the
+// command writes the file at path itself, or the PNG arrives on stdout.
+type clipboardCommand struct {
+	name   string
+	args   []string
+	stdout bool
+}
+
+// clipboardImageCommand picks the command that saves the clipboard image to
+// path on goos. On Linux, Wayland sessions use wl-paste and X11 sessions use
+// xclip, falling back to the other tool when only that one is installed.
+func clipboardImageCommand(goos, path string) (clipboardCommand, error) {
+	switch goos {
+	case "darwin":
+		if _, err := clipboardLookPath("pngpaste"); err != nil {
+			return clipboardCommand{}, &clipboardToolError{"pngpaste not found. Install with: brew install pngpaste"}
+		}
+		return clipboardCommand{name: "pngpaste", args: []string{path}}, nil
+	case "linux":
+		wlPaste := clipboardCommand{name: "wl-paste", args: []string{"--no-newline", "--type", "image/png"}, stdout: true}
+		xclip := clipboardCommand{name: "xclip", args: []string{"-selection", "clipboard", "-target", "image/png", "-out"}, stdout: true}
+		preferred, other, install := xclip, wlPaste, "xclip not found. Install it with your package manager (e.g. sudo apt install xclip)"
+		if clipboardGetenv("WAYLAND_DISPLAY") != "" || strings.EqualFold(clipboardGetenv("XDG_SESSION_TYPE"), "wayland") {
+			preferred, other, install = wlPaste, xclip, "wl-paste not found. Install wl-clipboard with your package manager (e.g. sudo apt install wl-clipboard)"
+		}
+		for _, candidate := range []clipboardCommand{preferred, other} {
+			if _, err := clipboardLookPath(candidate.name); err == nil {
+				return candidate, nil
+			}
+		}
+		return clipboardCommand{}, &clipboardToolError{install}
+	case "windows":
+		for _, shell := range []string{"powershell", "pwsh"} {
+			if _, err := clipboardLookPath(shell); err != nil {
+				continue
+			}
+			script := "Add-Type -AssemblyName System.Drawing; " +
+				"$img = Get-Clipboard -Format Image; " +
+				"if ($null -eq $img) { Write-Error 'no image on the clipboard'; exit 1 }; " +
+				"$img.Save('" + strings.ReplaceAll(path, "'", "''") + "', [System.Drawing.Imaging.ImageFormat]::Png)"
+			return clipboardCommand{name: shell, args: []string{"-NoProfile", "-NonInteractive", "-Command", script}}, nil
+		}
+		return clipboardCommand{}, &clipboardToolError{"PowerShell not found. Install Windows PowerShell or PowerShell 7 to paste images"}
+	default:
+		return clipboardCommand{}, &clipboardToolError{fmt.Sprintf("Clipboard image paste is not supported on %s", goos)}
+	}
+}
+
+// saveClipboardPNG saves the clipboard image to a temporary PNG file using
+// the platform's clipboard tool. A missing tool is reported as a
+// *clipboardToolError.
+func saveClipboardPNG() (string, error) {
+	f, err := os.CreateTemp("", "simple-agent-clipboard-*.png")
+	if err != nil {
+		return "", err
+	}
+	path := f.Name()
+	_ = f.Close()
+
+	cmd, err := clipboardImageCommand(clipboardGOOS, path)
+	if err != nil {
+		_ = os.Remove(path)
+		return "", err
+	}
+	out, stderr, err := clipboardRun(cmd.name, cmd.args...)
+	if err != nil {
+		_ = os.Remove(path)
+		if msg := strings.TrimSpace(string(stderr)); msg != "" {
+			return "", errors.New(msg)
+		}
+		return "", err
+	}
+	if cmd.stdout {
+		if len(out) == 0 {
+			_ = os.Remove(path)
+			return "", fmt.Errorf("%s returned no image", cmd.name)
+		}
+		if err := os.WriteFile(path, out, 0600); err != nil {
+			_ = os.Remove(path)
+			return "", err
+		}
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+		_ = os.Remove(path)
+		return "", fmt.Errorf("%s produced no file", cmd.name)
+	}
+	return path, nil
+}
@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// stubClipboardEnv makes clipboardImageCommand see goos, the given installed
+// tools, and the given environment.
+func stubClipboardEnv(t *testing.T, goos string, installed []string, env map[string]string) {
+	t.Helper()
+	origGOOS, origLookPath, origGetenv := clipboardGOOS, clipboardLookPath, clipboardGetenv
+	t.Cleanup(func() {
+		clipboardGOOS, clipboardLookPath, clipboardGetenv = origGOOS, origLookPath, origGetenv
+	})
+	clipboardGOOS = goos
+	clipboardLookPath = func(name string) (string, error) {
+		for _, tool := range installed {
+			if tool == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", exec.ErrNotFound
+	}
+	clipboardGetenv = func(key string) string { return env[key] }
+}
+
+func TestClipboardImageCommandPerPlatform(t *testing.T) {
+	tests := []struct {
+		name      string
+		goos      string
+		installed []string
+		env       map[string]string
+		want      string
+		stdout    bool
+	}{
+		{name: "macOS", goos: "darwin", installed: []string{"pngpaste"}, want: "pngpaste"},
+		{name: "Wayland", goos: "linux", installed: []string{"wl-paste", "xclip"}, env: map[string]string{"WAYLAND_DISPLAY": "wayland-0"}, want: "wl-paste", stdout: true},
+		{name: "X11", goos: "linux", installed: []string{"wl-paste", "xclip"}, env: map[string]string{"XDG_SESSION_TYPE": "x11"}, want: "xclip", stdout: true},
+		{name: "X11 without xclip", goos: "linux", installed: []string{"wl-paste"}, want: "wl-paste", stdout: true},
+		{name: "Windows PowerShell", goos: "windows", installed: []string{"powershell", "pwsh"}, want: "powershell"},
+		{name: "PowerShell 7", goos: "windows", installed: []string{"pwsh"}, want: "pwsh"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubClipboardEnv(t, tt.goos, tt.installed, tt.env)
+			cmd, err := clipboardImageCommand(tt.goos, "/tmp/clip.png")
+			if err != nil {
+				t.Fatalf("clipboardImageCommand: %v", err)
+			}
+			if cmd.name != tt.want || cmd.stdout != tt.stdout {
+				t.Fatalf("got %s (stdout %v), want %s (stdout %v)", cmd.name, cmd.stdout, tt.want, tt.stdout)
+			}
+		})
+	}
+}
+
+func TestClipboardImageCommandWindowsUsesGetClipboard(t *testing.T) {
+	stubClipboardEnv(t, "windows", []string{"powershell"}, nil)
+	cmd, err := clipboardImageCommand("windows", `C:\Temp\it's.png`)
+	if err != nil {
+		t.Fatalf("clipboardImageCommand: %v", err)
+	}
+	script := cmd.args[len(cmd.args)-1]
+	if !strings.Contains(script, "Get-Clipboard -Format Image") || !strings.Contains(script, `'C:\Temp\it''s.png'`) {
+		t.Fatalf("unexpected script %q", script)
+	}
+}
+
+func TestClipboardImageCommandMissingToolSaysWhatToInstall(t *testing.T) {
+	tests := []struct {
+		goos string
+		env  map[string]string
+		want string
+	}{
+		{goos: "darwin", want: "brew install pngpaste"},
+		{goos: "linux", env: map[string]string{"XDG_SESSION_TYPE": "wayland"}, want: "wl-clipboard"},
+		{goos: "linux", want: "apt install xclip"},
+		{goos: "windows", want: "PowerShell not found"},
+		{goos: "plan9", want: "not supported on plan9"},
+	}
+	for _, tt := range tests {
+		stubClipboardEnv(t, tt.goos, nil, tt.env)
+		_, err := clipboardImageCommand(tt.goos, "/tmp/clip.png")
+		var toolErr *clipboardToolError
+		if !errors.As(err, &toolErr) || !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("%s: expected a tool error mentioning %q, got %v", tt.goos, tt.want, err)
+		}
+	}
+}
+
+func TestSaveClipboardPNGWritesStdoutImage(t *testing.T) {
+	stubClipboardEnv(t, "linux", []string{"xclip"}, nil)
+	origRun := clipboardRun
+	t.Cleanup(func() { clipboardRun = origRun })
+	var ran string
+	clipboardRun = func(name string, args ...string) ([]byte, []byte, error) {
+		ran = name + " " + strings.Join(args, " ")
+		return []byte("\x89PNG fake"), nil, nil
+	}
+
+	path, err := saveClipboardPNG()
+	if err != nil {
+		t.Fatalf("saveClipboardPNG: %v", err)
+	}
+	defer os.Remove(path)
+	if ran != "xclip -selection clipboard -target image/png -out" {
+		t.Fatalf("unexpected command %q", ran)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "\x89PNG fake" {
+		t.Fatalf("expected the clipboard bytes in %s, got %q (%v)", path, data, err)
+	}
+}
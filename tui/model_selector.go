@@ -6,26 +6,55 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/nachoal/simple-agent-go/config"
 	"github.com/nachoal/simple-agent-go/llm"
+	"github.com/sahilm/fuzzy"
 )
 
+// Groups that are not providers: the "Recently Used" section and the
+// section listing providers that could not be set up
+const (
+	recentGroup      = ""
+	unavailableGroup = "\x00unavailable"
+)
+
+// selectorChromeLines is the height of the title, filter box, scroll
+// indicator, and help around the model rows
+const selectorChromeLines = 9
+
 // ModelItem represents a model in the list
 type ModelItem struct {
 	Provider    string
 	Model       llm.Model
 	DisplayName string
+	Recent      bool
 }
 
 func (i ModelItem) Title() string       { return i.DisplayName }
 func (i ModelItem) Description() string { return i.Model.Description }
-func (i ModelItem) FilterValue() string { return i.DisplayName }
 
-// ModelSelector is a component for selecting models
+// FilterValue is the text the filter fuzzy-matches: the provider and model ID
+func (i ModelItem) FilterValue() string { return i.Provider + " " + i.Model.ID }
+
+// selectorRow is one line of the selector: a group header or a model
+type selectorRow struct {
+	header bool
+	group  string // provider name, or recentGroup
+	count  int    // models in the group, on headers
+	item   ModelItem
+	// reason is set on the rows of unavailable providers, which cannot be
+	// selected
+	reason string
+}
+
+// ModelSelector is a component for selecting models. Models are grouped
+// under one header per provider, after a "Recently Used" group; typing in
+// the filter box ranks them by how well they fuzzy-match what was typed.
 type ModelSelector struct {
-	list      list.Model
 	providers map[string]llm.Client
 	// staticModels are optional models sourced from config (e.g., models.json).
 	staticModels map[string][]llm.Model
@@ -35,6 +64,17 @@ type ModelSelector struct {
 	width        int
 	height       int
 	onSelect     func(provider, model string) tea.Cmd
+	// recents are listed first, newest first, when they are still available
+	recents []config.RecentModel
+	// unavailable maps providers without a client to the reason, listed last
+	unavailable map[string]string
+
+	filter    textinput.Model
+	models    []ModelItem // every loaded model, by provider then ID
+	rows      []selectorRow
+	cursor    int
+	offset    int // first visible row
+	collapsed map[string]bool
 }
 
 // Messages emitted by the model selector when used as an in-app modal
@@ -48,48 +88,51 @@ type (
 
 // NewModelSelector creates a new model selector
 func NewModelSelector(providers map[string]llm.Client, staticModels map[string][]llm.Model, onSelect func(provider, model string) tea.Cmd) *ModelSelector {
-	// Create list with custom styles
-	delegate := list.NewDefaultDelegate()
-	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
-		Foreground(lipgloss.Color("170")).
-		BorderLeftForeground(lipgloss.Color("170"))
-	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
-		Foreground(lipgloss.Color("170")).
-		BorderLeftForeground(lipgloss.Color("170"))
-
-	l := list.New([]list.Item{}, delegate, 80, 20) // Default size
-	l.Title = "Select a Model (type to filter)"
-	l.SetShowStatusBar(true)
-	l.SetFilteringEnabled(true)
-	l.SetShowHelp(true)
-	l.FilterInput.Placeholder = "Type to filter models..."
-	l.DisableQuitKeybindings() // We handle quit ourselves
-	l.Styles.Title = lipgloss.NewStyle().
-		Background(lipgloss.Color("62")).
-		Foreground(lipgloss.Color("230")).
-		Padding(0, 1)
+	filter := textinput.New()
+	filter.Prompt = "Filter: "
+	filter.Placeholder = "type or paste a model name"
+	filter.Focus()
 
 	return &ModelSelector{
-		list:         l,
 		providers:    providers,
 		staticModels: staticModels,
 		loading:      true,
 		onSelect:     onSelect,
 		width:        80, // Default width
 		height:       20, // Default height
+		filter:       filter,
+		collapsed:    make(map[string]bool),
 	}
 }
 
+// SetRecentModels pins recently used models to the top of the list
+func (m *ModelSelector) SetRecentModels(recents []config.RecentModel) {
+	m.recents = recents
+}
+
+// SetUnavailableProviders lists providers that could not be set up, with the
+// reason (such as a missing API key), in a greyed-out section at the end
+func (m *ModelSelector) SetUnavailableProviders(unavailable map[string]string) {
+	m.unavailable = unavailable
+}
+
+// SetSize fits the selector to the terminal
+func (m *ModelSelector) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	// Leave room for the box border, padding, and prompt
+	m.filter.Width = max(10, width-8-len(m.filter.Prompt))
+	m.scrollToCursor()
+}
+
 func (m *ModelSelector) Init() tea.Cmd {
-	return m.loadModels()
+	return tea.Batch(m.loadModels(), textinput.Blink)
 }
 
 func (m *ModelSelector) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
-		m.list.SetSize(msg.Width, msg.Height)
+		m.SetSize(msg.Width, msg.Height)
 		return m, nil
 
 	case tea.KeyMsg:
@@ -98,61 +141,86 @@ func (m *ModelSelector) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Notify parent to close selector without quitting the whole app
 			return m, func() tea.Msg { return selectorCancelMsg{} }
 		case "enter":
-			if i, ok := m.list.SelectedItem().(ModelItem); ok {
-				m.selected = i
-				// Notify parent about selection; parent decides how to handle
-				return m, func() tea.Msg { return selectorConfirmMsg{provider: i.Provider, model: i.Model.ID} }
+			row, ok := m.current()
+			if !ok {
+				return m, nil
+			}
+			if row.header {
+				m.toggleGroup(row.group)
+				return m, nil
+			}
+			if row.reason != "" {
+				return m, nil
+			}
+			m.selected = row.item
+			// Notify parent about selection; parent decides how to handle
+			return m, func() tea.Msg { return selectorConfirmMsg{provider: row.item.Provider, model: row.item.Model.ID} }
+		case "up", "ctrl+p":
+			m.moveCursor(-1)
+			return m, nil
+		case "down", "ctrl+n":
+			m.moveCursor(1)
+			return m, nil
+		case "pgup":
+			m.moveCursor(-m.visibleRows())
+			return m, nil
+		case "pgdown":
+			m.moveCursor(m.visibleRows())
+			return m, nil
+		case " ":
+			// Space collapses the group under the cursor, unless it is part
+			// of a filter being typed.
+			if m.filter.Value() == "" {
+				if row, ok := m.current(); ok {
+					m.toggleGroup(row.group)
+				}
+				return m, nil
 			}
 		}
 
-	case modelsLoadedMsg:
-		items := make([]list.Item, 0)
+		before := m.filter.Value()
+		var cmd tea.Cmd
+		m.filter, cmd = m.filter.Update(msg)
+		if m.filter.Value() != before {
+			m.rebuildRows()
+			m.cursor = m.firstModelRow()
+			m.scrollToCursor()
+		}
+		return m, cmd
 
-		// Group models by provider
-		providerModels := make(map[string][]llm.Model)
+	case modelsLoadedMsg:
+		// Sort providers, and models within each, for consistent display
+		providers := make([]string, 0, len(msg.models))
 		for provider, models := range msg.models {
 			if len(models) > 0 {
-				providerModels[provider] = models
+				providers = append(providers, provider)
 			}
 		}
-
-		// Sort providers for consistent display
-		providers := make([]string, 0, len(providerModels))
-		for p := range providerModels {
-			providers = append(providers, p)
-		}
 		sort.Strings(providers)
 
-		// Add models grouped by provider
+		m.models = m.models[:0]
 		for _, provider := range providers {
-			models := providerModels[provider]
-			// Sort models by ID for consistency
+			models := msg.models[provider]
 			sort.Slice(models, func(i, j int) bool {
 				return models[i].ID < models[j].ID
 			})
-
 			for _, model := range models {
-				displayName := fmt.Sprintf("[%s] %s", provider, model.ID)
-				if model.SupportsVision {
-					displayName += "  👁️"
-				}
-				items = append(items, ModelItem{
-					Provider:    provider,
-					Model:       model,
-					DisplayName: displayName,
-				})
+				m.models = append(m.models, newModelItem(provider, model))
 			}
 		}
 
-		// If no models were found, show an error
-		if len(items) == 0 {
+		// If no models were found, show an error unless the unavailable
+		// providers explain why
+		if len(m.models) == 0 && len(m.unavailable) == 0 {
 			m.err = fmt.Errorf("no models found - check your API keys")
 			m.loading = false
 			return m, nil
 		}
 
-		m.list.SetItems(items)
 		m.loading = false
+		m.rebuildRows()
+		m.cursor = m.firstModelRow()
+		m.scrollToCursor()
 		return m, nil
 
 	case errMsg:
@@ -161,9 +229,9 @@ func (m *ModelSelector) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	// Update the list
+	// Keep the filter's cursor blinking
 	var cmd tea.Cmd
-	m.list, cmd = m.list.Update(msg)
+	m.filter, cmd = m.filter.Update(msg)
 	return m, cmd
 }
 
@@ -185,7 +253,284 @@ func (m *ModelSelector) View() string {
 			Render(fmt.Sprintf("Error loading models: %v", m.err))
 	}
 
-	return m.list.View()
+	titleStyle := lipgloss.NewStyle().
+		Background(lipgloss.Color("62")).
+		Foreground(lipgloss.Color("230")).
+		Padding(0, 1)
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(0, 1).
+		Width(max(20, m.width-4))
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("75"))
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("170")).
+		Bold(true)
+	dimStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240"))
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Select a Model"))
+	b.WriteString("\n\n")
+	b.WriteString(boxStyle.Render(m.filter.View()))
+	b.WriteString("\n")
+
+	if len(m.rows) == 0 {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("No models match %q", m.filter.Value())))
+		b.WriteString("\n")
+	}
+
+	end := min(len(m.rows), m.offset+m.visibleRows())
+	for i := m.offset; i < end; i++ {
+		row := m.rows[i]
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "▸ "
+		}
+
+		if row.header {
+			marker := "▾"
+			if m.collapsed[row.group] && m.filter.Value() == "" {
+				marker = "▸"
+			}
+			style := headerStyle
+			if row.group == unavailableGroup {
+				style = dimStyle.Bold(true)
+			}
+			if i == m.cursor {
+				style = selectedStyle
+			}
+			line := fmt.Sprintf("%s%s %s (%d)", cursor, marker, groupTitle(row.group), row.count)
+			b.WriteString(style.Render(ansi.Truncate(line, m.width, "…")))
+			b.WriteString("\n")
+			continue
+		}
+
+		if row.reason != "" {
+			line := fmt.Sprintf("%s  %s — %s", cursor, row.item.Provider, row.reason)
+			b.WriteString(dimStyle.Render(ansi.Truncate(line, m.width, "…")))
+			b.WriteString("\n")
+			continue
+		}
+
+		name := row.item.DisplayName
+		if row.group != recentGroup {
+			name = strings.TrimPrefix(name, "["+row.item.Provider+"] ")
+		}
+		line := cursor + "  " + name
+		if i == m.cursor {
+			line = selectedStyle.Render(line)
+		}
+		if desc := row.item.Model.Description; desc != "" {
+			line += dimStyle.Render("  " + desc)
+		}
+		b.WriteString(ansi.Truncate(line, m.width, "…"))
+		b.WriteString("\n")
+	}
+
+	if m.offset > 0 || end < len(m.rows) {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("[%d-%d of %d]", m.offset+1, end, len(m.rows))))
+		b.WriteString("\n")
+	}
+	b.WriteString(dimStyle.Render("[↑/↓] Navigate  [Enter] Select  [Space] Collapse group  [Esc] Cancel"))
+
+	return b.String()
+}
+
+func groupTitle(group string) string {
+	switch group {
+	case recentGroup:
+		return "Recently Used"
+	case unavailableGroup:
+		return "Unavailable"
+	default:
+		return group
+	}
+}
+
+// rebuildRows lays out the rows for the current filter. Without one, models
+// are grouped by provider after the recently used ones; with one, only the
+// matches are shown, best first, and groups are ordered by their best match.
+// Unavailable providers come last either way.
+func (m *ModelSelector) rebuildRows() {
+	m.rows = m.rows[:0]
+	query := strings.TrimSpace(m.filter.Value())
+	defer m.addUnavailable(query)
+
+	if query == "" {
+		var recent []ModelItem
+		for _, r := range m.recents {
+			for _, item := range m.models {
+				if item.Provider == r.Provider && item.Model.ID == r.Model {
+					item.Recent = true
+					recent = append(recent, item)
+					break
+				}
+			}
+		}
+		m.addGroup(recentGroup, recent, true)
+
+		for start := 0; start < len(m.models); {
+			end := start
+			for end < len(m.models) && m.models[end].Provider == m.models[start].Provider {
+				end++
+			}
+			m.addGroup(m.models[start].Provider, m.models[start:end], true)
+			start = end
+		}
+		return
+	}
+
+	var order []string
+	groups := make(map[string][]ModelItem)
+	for _, item := range rankModels(query, m.models) {
+		if _, ok := groups[item.Provider]; !ok {
+			order = append(order, item.Provider)
+		}
+		groups[item.Provider] = append(groups[item.Provider], item)
+	}
+	for _, provider := range order {
+		m.addGroup(provider, groups[provider], false)
+	}
+}
+
+// addGroup adds a header and, unless the group is collapsed, its models
+func (m *ModelSelector) addGroup(group string, items []ModelItem, collapsible bool) {
+	if len(items) == 0 {
+		return
+	}
+	m.rows = append(m.rows, selectorRow{header: true, group: group, count: len(items)})
+	if collapsible && m.collapsed[group] {
+		return
+	}
+	for _, item := range items {
+		m.rows = append(m.rows, selectorRow{group: group, item: item})
+	}
+}
+
+// addUnavailable lists the providers that could not be set up, or with a
+// filter those whose name contains it, so users can see what to configure
+func (m *ModelSelector) addUnavailable(query string) {
+	names := make([]string, 0, len(m.unavailable))
+	for name := range m.unavailable {
+		if _, ok := m.providers[name]; ok {
+			continue
+		}
+		if strings.Contains(name, strings.ToLower(query)) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	sort.Strings(names)
+
+	m.rows = append(m.rows, selectorRow{header: true, group: unavailableGroup, count: len(names)})
+	if query == "" && m.collapsed[unavailableGroup] {
+		return
+	}
+	for _, name := range names {
+		m.rows = append(m.rows, selectorRow{
+			group:  unavailableGroup,
+			item:   ModelItem{Provider: name},
+			reason: m.unavailable[name],
+		})
+	}
+}
+
+// toggleGroup collapses or expands a group, leaving the cursor on its header
+func (m *ModelSelector) toggleGroup(group string) {
+	if m.filter.Value() != "" {
+		return
+	}
+	m.collapsed[group] = !m.collapsed[group]
+	m.rebuildRows()
+	for i, row := range m.rows {
+		if row.header && row.group == group {
+			m.cursor = i
+			break
+		}
+	}
+	m.scrollToCursor()
+}
+
+// rankModels returns the models matching query, best first: fuzzy matches
+// of the provider and model ID by score, then models whose description
+// contains query.
+func rankModels(query string, models []ModelItem) []ModelItem {
+	targets := make([]string, len(models))
+	for i, item := range models {
+		targets[i] = item.FilterValue()
+	}
+	matches := fuzzy.FindNoSort(query, targets)
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+
+	ranked := make([]ModelItem, 0, len(matches))
+	matched := make(map[int]bool, len(matches))
+	for _, match := range matches {
+		ranked = append(ranked, models[match.Index])
+		matched[match.Index] = true
+	}
+	lower := strings.ToLower(query)
+	for i, item := range models {
+		if !matched[i] && strings.Contains(strings.ToLower(item.Model.Description), lower) {
+			ranked = append(ranked, item)
+		}
+	}
+	return ranked
+}
+
+func (m *ModelSelector) current() (selectorRow, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return selectorRow{}, false
+	}
+	return m.rows[m.cursor], true
+}
+
+func (m *ModelSelector) firstModelRow() int {
+	for i, row := range m.rows {
+		if !row.header && row.reason == "" {
+			return i
+		}
+	}
+	return 0
+}
+
+func (m *ModelSelector) moveCursor(delta int) {
+	m.cursor = max(0, min(len(m.rows)-1, m.cursor+delta))
+	m.scrollToCursor()
+}
+
+func (m *ModelSelector) visibleRows() int {
+	return max(1, m.height-selectorChromeLines)
+}
+
+// scrollToCursor keeps the cursor's row on screen
+func (m *ModelSelector) scrollToCursor() {
+	visible := m.visibleRows()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+visible {
+		m.offset = m.cursor - visible + 1
+	}
+	m.offset = max(0, min(m.offset, len(m.rows)-visible))
+}
+
+func newModelItem(provider string, model llm.Model) ModelItem {
+	displayName := fmt.Sprintf("[%s] %s", provider, model.ID)
+	if model.SupportsVision {
+		displayName += "  👁️"
+	}
+	return ModelItem{
+		Provider:    provider,
+		Model:       model,
+		DisplayName: displayName,
+	}
 }
 
 // loadModels fetches models from all providers concurrently
@@ -193,6 +538,9 @@ func (m *ModelSelector) loadModels() tea.Cmd {
 	return func() tea.Msg {
 		// Check if we have any providers
 		if len(m.providers) == 0 && len(m.staticModels) == 0 {
+			if len(m.unavailable) > 0 {
+				return modelsLoadedMsg{}
+			}
 			return errMsg{err: fmt.Errorf("no providers available")}
 		}
 
@@ -247,6 +595,12 @@ func (m *ModelSelector) loadModels() tea.Cmd {
 					if model.SupportsVision {
 						current.SupportsVision = true
 					}
+					if current.ContextWindow == 0 {
+						current.ContextWindow = model.ContextWindow
+					}
+					if current.MaxOutputTokens == 0 {
+						current.MaxOutputTokens = model.MaxOutputTokens
+					}
 					index[model.ID] = current
 				} else {
 					index[model.ID] = model
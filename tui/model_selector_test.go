@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nachoal/simple-agent-go/config"
+	"github.com/nachoal/simple-agent-go/llm"
+)
+
+func newLoadedSelector(t *testing.T, recents []config.RecentModel) *ModelSelector {
+	t.Helper()
+	s := NewModelSelector(nil, nil, nil)
+	s.SetSize(100, 40)
+	s.SetRecentModels(recents)
+	s.Update(modelsLoadedMsg{models: map[string][]llm.Model{
+		"anthropic": {{ID: "claude-3-haiku"}, {ID: "claude-3-5-sonnet"}},
+		"openai": {
+			{ID: "gpt-4o", Description: "Flagship multimodal model"},
+			{ID: "gpt-4-turbo-preview"},
+			{ID: "gpt-3.5-turbo"},
+		},
+	}})
+	return s
+}
+
+// selectorRows describes the rows as "# group (count)" for headers and
+// "provider/model" for models
+func selectorRows(s *ModelSelector) []string {
+	var rows []string
+	for _, row := range s.rows {
+		if row.header {
+			rows = append(rows, "# "+groupTitle(row.group))
+			continue
+		}
+		rows = append(rows, row.item.Provider+"/"+row.item.Model.ID)
+	}
+	return rows
+}
+
+func typeIntoSelector(s *ModelSelector, text string) {
+	for _, r := range text {
+		s.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+func TestModelSelectorGroupsByProviderWithRecentsFirst(t *testing.T) {
+	s := newLoadedSelector(t, []config.RecentModel{
+		{Provider: "openai", Model: "gpt-4o"},
+		{Provider: "anthropic", Model: "claude-3-5-sonnet"},
+		{Provider: "openai", Model: "gone"},
+	})
+
+	want := []string{
+		"# Recently Used",
+		"openai/gpt-4o",
+		"anthropic/claude-3-5-sonnet",
+		"# anthropic",
+		"anthropic/claude-3-5-sonnet",
+		"anthropic/claude-3-haiku",
+		"# openai",
+		"openai/gpt-3.5-turbo",
+		"openai/gpt-4-turbo-preview",
+		"openai/gpt-4o",
+	}
+	if got := selectorRows(s); !slices.Equal(got, want) {
+		t.Fatalf("expected rows %v, got %v", want, got)
+	}
+	if row, _ := s.current(); row.item.Model.ID != "gpt-4o" {
+		t.Fatalf("expected the cursor on the most recent model, got %+v", row)
+	}
+}
+
+func TestModelSelectorSpaceCollapsesGroup(t *testing.T) {
+	s := newLoadedSelector(t, nil)
+	s.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+
+	want := []string{"# anthropic", "# openai", "openai/gpt-3.5-turbo", "openai/gpt-4-turbo-preview", "openai/gpt-4o"}
+	if got := selectorRows(s); !slices.Equal(got, want) {
+		t.Fatalf("expected anthropic collapsed, got %v", got)
+	}
+	if row, _ := s.current(); !row.header || row.group != "anthropic" {
+		t.Fatalf("expected the cursor on the collapsed header, got %+v", row)
+	}
+	if !strings.Contains(s.View(), "▸ ▸ anthropic (2)") {
+		t.Fatalf("expected a collapsed marker in the view:\n%s", s.View())
+	}
+
+	s.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := selectorRows(s); len(got) != 7 {
+		t.Fatalf("expected enter on the header to expand it, got %v", got)
+	}
+}
+
+func TestModelSelectorFuzzyFilterRanksMatches(t *testing.T) {
+	s := newLoadedSelector(t, []config.RecentModel{{Provider: "anthropic", Model: "claude-3-haiku"}})
+	typeIntoSelector(s, "gpt4")
+
+	got := selectorRows(s)
+	if len(got) < 2 || got[0] != "# openai" || !strings.HasPrefix(got[1], "openai/gpt-4") {
+		t.Fatalf("expected gpt-4 models first, got %v", got)
+	}
+	if slices.Contains(got, "openai/gpt-3.5-turbo") || slices.Contains(got, "# Recently Used") {
+		t.Fatalf("expected only matches, got %v", got)
+	}
+
+	// The description is searched too.
+	s.filter.SetValue("")
+	typeIntoSelector(s, "multimodal")
+	if got := selectorRows(s); !slices.Equal(got, []string{"# openai", "openai/gpt-4o"}) {
+		t.Fatalf("expected a description match, got %v", got)
+	}
+}
+
+func TestModelSelectorAcceptsPastedModelName(t *testing.T) {
+	s := newLoadedSelector(t, nil)
+	s.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("claude-3-haiku"), Paste: true})
+
+	if s.filter.Value() != "claude-3-haiku" {
+		t.Fatalf("expected the paste in the filter box, got %q", s.filter.Value())
+	}
+	_, cmd := s.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected enter to confirm the best match")
+	}
+	if msg, ok := cmd().(selectorConfirmMsg); !ok || msg.provider != "anthropic" || msg.model != "claude-3-haiku" {
+		t.Fatalf("expected anthropic/claude-3-haiku to be confirmed, got %#v", cmd())
+	}
+}
+
+func TestModelSelectorListsUnavailableProviders(t *testing.T) {
+	s := NewModelSelector(nil, nil, nil)
+	s.SetSize(100, 40)
+	s.SetUnavailableProviders(map[string]string{
+		"openai": "OPENAI_API_KEY not set",
+		"groq":   "GROQ_API_KEY not set",
+	})
+	s.Update(modelsLoadedMsg{models: map[string][]llm.Model{
+		"anthropic": {{ID: "claude-3-haiku"}},
+	}})
+
+	want := []string{"# anthropic", "anthropic/claude-3-haiku", "# Unavailable", "groq/", "openai/"}
+	if got := selectorRows(s); !slices.Equal(got, want) {
+		t.Fatalf("expected rows %v, got %v", want, got)
+	}
+	view := stripANSI(s.View())
+	if !strings.Contains(view, "openai — OPENAI_API_KEY not set") || !strings.Contains(view, "▾ Unavailable (2)") {
+		t.Fatalf("expected unavailable providers with reasons in the view:\n%s", view)
+	}
+
+	// Unavailable providers cannot be selected.
+	s.cursor = len(s.rows) - 1
+	if _, cmd := s.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Fatalf("expected enter on an unavailable provider to do nothing, got %#v", cmd())
+	}
+}
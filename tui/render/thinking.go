@@ -0,0 +1,99 @@
+// Package render turns raw model output into the text the TUI displays.
+package render
+
+import "strings"
+
+const (
+	thinkOpen  = "<think>"
+	thinkClose = "</think>"
+)
+
+// RenderThinking splits content into the text to display and the reasoning
+// that local models such as DeepSeek-R1 and Qwen3 wrap in <think>...</think>.
+// Tags are matched case-insensitively and nested tags belong to the outermost
+// block. A block left open, as when a stream is cut mid-block, is reasoning
+// up to the end, and a closing tag with no opening one (some chat templates
+// put <think> in the prompt) ends reasoning that began with the content.
+// A partial tag at the very end is dropped so it does not flash on screen
+// while streaming. Content without tags is returned unchanged.
+func RenderThinking(content string) (display string, reasoning string) {
+	lower := strings.ToLower(content)
+	if !strings.Contains(lower, "<think") && !strings.Contains(lower, "</think") && !partialTagSuffix(lower, thinkOpen) {
+		return content, ""
+	}
+
+	var out, block strings.Builder
+	var blocks []string
+	endBlock := func() {
+		if text := strings.TrimSpace(block.String()); text != "" {
+			blocks = append(blocks, text)
+		}
+		block.Reset()
+	}
+
+	// Reasoning the prompt's template already opened runs to the first
+	// closing tag, provided no opening tag comes before it.
+	pos, depth := 0, 0
+	if end := strings.Index(lower, thinkClose); end >= 0 {
+		if open := strings.Index(lower, thinkOpen); open < 0 || end < open {
+			block.WriteString(content[:end])
+			endBlock()
+			pos = end + len(thinkClose)
+		}
+	}
+
+	for pos < len(content) {
+		rest := lower[pos:]
+		open := strings.Index(rest, thinkOpen)
+		if depth == 0 {
+			if open < 0 {
+				out.WriteString(trimPartialTag(content[pos:], thinkOpen))
+				break
+			}
+			out.WriteString(content[pos : pos+open])
+			pos += open + len(thinkOpen)
+			depth = 1
+			continue
+		}
+
+		end := strings.Index(rest, thinkClose)
+		if end < 0 {
+			// Still thinking: everything left is reasoning.
+			block.WriteString(trimPartialTag(content[pos:], thinkOpen, thinkClose))
+			break
+		}
+		if open >= 0 && open < end {
+			block.WriteString(content[pos : pos+open])
+			pos += open + len(thinkOpen)
+			depth++
+			continue
+		}
+		block.WriteString(content[pos : pos+end])
+		pos += end + len(thinkClose)
+		if depth--; depth == 0 {
+			endBlock()
+		}
+	}
+	endBlock()
+
+	return strings.TrimSpace(out.String()), strings.Join(blocks, "\n\n")
+}
+
+// trimPartialTag drops the start of any of tags from the end of s. A lone
+// "<" is kept: it is more often text than the start of a tag.
+func trimPartialTag(s string, tags ...string) string {
+	lower := strings.ToLower(s)
+	for _, tag := range tags {
+		for n := len(tag) - 1; n > 1; n-- {
+			if strings.HasSuffix(lower, tag[:n]) {
+				return s[:len(s)-n]
+			}
+		}
+	}
+	return s
+}
+
+// partialTagSuffix reports whether s ends with the start of tag
+func partialTagSuffix(s, tag string) bool {
+	return trimPartialTag(s, tag) != s
+}
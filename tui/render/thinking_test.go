@@ -0,0 +1,85 @@
+package render
+
+import "testing"
+
+func TestRenderThinking(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		display   string
+		reasoning string
+	}{
+		{
+			name:    "no tags",
+			content: "Plain answer with a < b\n",
+			display: "Plain answer with a < b\n",
+		},
+		{
+			name:      "single block",
+			content:   "<think>\ninternal reasoning\n</think>\n\nFinal answer.",
+			display:   "Final answer.",
+			reasoning: "internal reasoning",
+		},
+		{
+			name:      "tags in any case",
+			content:   "<THINK>plan</Think>Done.",
+			display:   "Done.",
+			reasoning: "plan",
+		},
+		{
+			name:      "several blocks",
+			content:   "<think>first</think>Step one.\n<think>second</think>Step two.",
+			display:   "Step one.\nStep two.",
+			reasoning: "first\n\nsecond",
+		},
+		{
+			name:      "nested tags belong to the outer block",
+			content:   "<think>outer <think>inner</think> again</think>Answer.",
+			display:   "Answer.",
+			reasoning: "outer inner again",
+		},
+		{
+			name:      "stream cut mid-block",
+			content:   "<think>still working it out",
+			reasoning: "still working it out",
+		},
+		{
+			name:      "stream cut inside the closing tag",
+			content:   "<think>almost done</thi",
+			reasoning: "almost done",
+		},
+		{
+			name:    "stream cut inside the opening tag",
+			content: "Intro <thi",
+			display: "Intro",
+		},
+		{
+			name:      "nested block left open",
+			content:   "<think>outer <think>inner</think> more",
+			reasoning: "outer inner more",
+		},
+		{
+			name:      "opening tag supplied by the prompt",
+			content:   "reasoning first</think>\n\nThe answer.",
+			display:   "The answer.",
+			reasoning: "reasoning first",
+		},
+		{
+			name:    "empty block",
+			content: "<think>\n\n</think>Answer.",
+			display: "Answer.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			display, reasoning := RenderThinking(tt.content)
+			if display != tt.display {
+				t.Errorf("display = %q, want %q", display, tt.display)
+			}
+			if reasoning != tt.reasoning {
+				t.Errorf("reasoning = %q, want %q", reasoning, tt.reasoning)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -11,7 +12,10 @@ import (
 
 // SessionPicker is a TUI component for selecting a conversation session
 type SessionPicker struct {
-	sessions          []history.SessionInfo
+	sessions          []history.SessionInfo // The sessions shown: all of them, or those with tagFilter
+	all               []history.SessionInfo
+	tags              []string // Every tag in all, sorted; [t] cycles tagFilter through them
+	tagFilter         string
 	selected          int
 	done              bool
 	width             int
@@ -26,14 +30,56 @@ type SelectedSessionMsg struct {
 
 // NewSessionPicker creates a new session picker
 func NewSessionPicker(sessions []history.SessionInfo) *SessionPicker {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, session := range sessions {
+		for _, tag := range session.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
 	return &SessionPicker{
 		sessions: sessions,
+		all:      sessions,
+		tags:     tags,
 		selected: 0,
 		width:    80,
 		height:   24,
 	}
 }
 
+// SetTagFilter shows only the sessions tagged tag, or every session when tag
+// is empty
+func (p *SessionPicker) SetTagFilter(tag string) {
+	p.tagFilter = history.NormalizeTag(tag)
+	p.sessions = p.all
+	if p.tagFilter != "" {
+		p.sessions = history.FilterSessionsByTag(p.all, p.tagFilter)
+	}
+	p.selected = 0
+}
+
+// nextTagFilter advances the filter from all sessions through each tag and
+// back to all sessions
+func (p *SessionPicker) nextTagFilter() {
+	next := ""
+	if len(p.tags) > 0 {
+		i := sort.SearchStrings(p.tags, p.tagFilter)
+		switch {
+		case p.tagFilter == "":
+			next = p.tags[0]
+		case i < len(p.tags) && p.tags[i] == p.tagFilter:
+			if i+1 < len(p.tags) {
+				next = p.tags[i+1]
+			}
+		}
+	}
+	p.SetTagFilter(next)
+}
+
 func (p SessionPicker) Init() tea.Cmd {
 	return nil
 }
@@ -55,6 +101,8 @@ func (p *SessionPicker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if p.selected < len(p.sessions)-1 {
 				p.selected++
 			}
+		case "t":
+			p.nextTagFilter()
 		case "enter":
 			if len(p.sessions) > 0 {
 				p.SelectedSessionID = p.sessions[p.selected].ID
@@ -68,7 +116,7 @@ func (p *SessionPicker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (p SessionPicker) View() string {
-	if len(p.sessions) == 0 {
+	if len(p.all) == 0 {
 		return "\nNo saved conversations found.\n\nPress [Esc] to start a new conversation."
 	}
 
@@ -92,8 +140,16 @@ func (p SessionPicker) View() string {
 	var b strings.Builder
 
 	// Title
-	b.WriteString(titleStyle.Render("Select a conversation to resume:"))
+	title := "Select a conversation to resume:"
+	if p.tagFilter != "" {
+		title = fmt.Sprintf("Select a conversation to resume (tagged #%s):", p.tagFilter)
+	}
+	b.WriteString(titleStyle.Render(title))
 	b.WriteString("\n\n")
+	if len(p.sessions) == 0 {
+		b.WriteString(normalStyle.Render("No conversations with this tag."))
+		b.WriteString("\n")
+	}
 
 	// Calculate visible sessions based on height
 	visibleHeight := p.height - 6 // Account for title, help, and margins
@@ -139,6 +195,9 @@ func (p SessionPicker) View() string {
 			session.Provider,
 			session.Model,
 			status)
+		for _, tag := range session.Tags {
+			line += " #" + tag
+		}
 
 		b.WriteString(style.Render(line))
 		b.WriteString("\n")
@@ -152,6 +211,9 @@ func (p SessionPicker) View() string {
 
 	// Help
 	help := "\n[↑/↓/j/k] Navigate  [Enter] Select  [Esc/q] Cancel"
+	if len(p.tags) > 0 {
+		help = "\n[↑/↓/j/k] Navigate  [t] Filter by tag  [Enter] Select  [Esc/q] Cancel"
+	}
 	b.WriteString(helpStyle.Render(help))
 
 	return b.String()
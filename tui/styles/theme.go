@@ -1,41 +1,101 @@
 package styles
 
 import (
+	"strings"
+
 	"github.com/charmbracelet/lipgloss"
 )
 
-// Theme represents a color theme
+// Theme represents a color theme. Colors are lipgloss terminal colors, so a
+// theme can use ANSI codes, hex values, or adaptive light/dark pairs.
 type Theme struct {
 	Name           string
-	Primary        lipgloss.AdaptiveColor
-	Secondary      lipgloss.AdaptiveColor
-	Background     lipgloss.AdaptiveColor
-	Surface        lipgloss.AdaptiveColor
-	Text           lipgloss.AdaptiveColor
-	TextDim        lipgloss.AdaptiveColor
-	Border         lipgloss.AdaptiveColor
-	Success        lipgloss.AdaptiveColor
-	Warning        lipgloss.AdaptiveColor
-	Error          lipgloss.AdaptiveColor
-	Info           lipgloss.AdaptiveColor
-	CodeBackground lipgloss.AdaptiveColor
+	Primary        lipgloss.TerminalColor
+	Secondary      lipgloss.TerminalColor
+	Background     lipgloss.TerminalColor
+	Surface        lipgloss.TerminalColor
+	Text           lipgloss.TerminalColor
+	TextDim        lipgloss.TerminalColor
+	Border         lipgloss.TerminalColor
+	Success        lipgloss.TerminalColor
+	Warning        lipgloss.TerminalColor
+	Error          lipgloss.TerminalColor
+	Info           lipgloss.TerminalColor
+	CodeBackground lipgloss.TerminalColor
+
+	// Trace colors thinking traces; Selection* highlight the chosen
+	// autocomplete entry.
+	Trace               lipgloss.TerminalColor
+	SelectionText       lipgloss.TerminalColor
+	SelectionBackground lipgloss.TerminalColor
+
+	// GlamourStyle is the glamour standard style used to render markdown
+	GlamourStyle string
 }
 
-// Default theme
+// Default theme, using the 256-color palette the bordered TUI has always
+// used. Markdown renders without colors so it stays readable on any
+// terminal background.
 var DefaultTheme = Theme{
-	Name:           "default",
-	Primary:        lipgloss.AdaptiveColor{Light: "#5A56E0", Dark: "#7B68EE"},
-	Secondary:      lipgloss.AdaptiveColor{Light: "#6C6CFF", Dark: "#9370DB"},
-	Background:     lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#1E1E1E"},
-	Surface:        lipgloss.AdaptiveColor{Light: "#F5F5F5", Dark: "#2D2D2D"},
-	Text:           lipgloss.AdaptiveColor{Light: "#1E1E1E", Dark: "#E0E0E0"},
-	TextDim:        lipgloss.AdaptiveColor{Light: "#666666", Dark: "#888888"},
-	Border:         lipgloss.AdaptiveColor{Light: "#E0E0E0", Dark: "#404040"},
-	Success:        lipgloss.AdaptiveColor{Light: "#4CAF50", Dark: "#66BB6A"},
-	Warning:        lipgloss.AdaptiveColor{Light: "#FF9800", Dark: "#FFA726"},
-	Error:          lipgloss.AdaptiveColor{Light: "#F44336", Dark: "#EF5350"},
-	Info:           lipgloss.AdaptiveColor{Light: "#2196F3", Dark: "#42A5F5"},
-	CodeBackground: lipgloss.AdaptiveColor{Light: "#F5F5F5", Dark: "#1E1E1E"},
+	Name:                "default",
+	Primary:             lipgloss.Color("75"),
+	Secondary:           lipgloss.Color("62"),
+	Background:          lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#1E1E1E"},
+	Surface:             lipgloss.AdaptiveColor{Light: "#F5F5F5", Dark: "#2D2D2D"},
+	Text:                lipgloss.Color("15"),
+	TextDim:             lipgloss.Color("245"),
+	Border:              lipgloss.Color("15"),
+	Success:             lipgloss.Color("42"),
+	Warning:             lipgloss.Color("214"),
+	Error:               lipgloss.Color("196"),
+	Info:                lipgloss.Color("80"),
+	CodeBackground:      lipgloss.AdaptiveColor{Light: "#F5F5F5", Dark: "#1E1E1E"},
+	Trace:               lipgloss.Color("244"),
+	SelectionText:       lipgloss.Color("230"),
+	SelectionBackground: lipgloss.Color("62"),
+	GlamourStyle:        "notty",
+}
+
+// Light theme, for terminals with a light background
+var LightTheme = Theme{
+	Name:                "light",
+	Primary:             lipgloss.Color("25"),
+	Secondary:           lipgloss.Color("61"),
+	Background:          lipgloss.Color("231"),
+	Surface:             lipgloss.Color("255"),
+	Text:                lipgloss.Color("235"),
+	TextDim:             lipgloss.Color("243"),
+	Border:              lipgloss.Color("240"),
+	Success:             lipgloss.Color("28"),
+	Warning:             lipgloss.Color("130"),
+	Error:               lipgloss.Color("160"),
+	Info:                lipgloss.Color("30"),
+	CodeBackground:      lipgloss.Color("255"),
+	Trace:               lipgloss.Color("246"),
+	SelectionText:       lipgloss.Color("231"),
+	SelectionBackground: lipgloss.Color("25"),
+	GlamourStyle:        "light",
+}
+
+// High-contrast theme: bright base colors only, on a dark background
+var HighContrastTheme = Theme{
+	Name:                "high-contrast",
+	Primary:             lipgloss.Color("14"),
+	Secondary:           lipgloss.Color("13"),
+	Background:          lipgloss.Color("0"),
+	Surface:             lipgloss.Color("0"),
+	Text:                lipgloss.Color("15"),
+	TextDim:             lipgloss.Color("7"),
+	Border:              lipgloss.Color("15"),
+	Success:             lipgloss.Color("10"),
+	Warning:             lipgloss.Color("11"),
+	Error:               lipgloss.Color("9"),
+	Info:                lipgloss.Color("11"),
+	CodeBackground:      lipgloss.Color("0"),
+	Trace:               lipgloss.Color("7"),
+	SelectionText:       lipgloss.Color("0"),
+	SelectionBackground: lipgloss.Color("14"),
+	GlamourStyle:        "dark",
 }
 
 // Dracula theme
@@ -53,6 +113,11 @@ var DraculaTheme = Theme{
 	Error:          lipgloss.AdaptiveColor{Light: "#FF5555", Dark: "#FF5555"},
 	Info:           lipgloss.AdaptiveColor{Light: "#8BE9FD", Dark: "#8BE9FD"},
 	CodeBackground: lipgloss.AdaptiveColor{Light: "#44475A", Dark: "#44475A"},
+
+	Trace:               lipgloss.AdaptiveColor{Light: "#6272A4", Dark: "#6272A4"},
+	SelectionText:       lipgloss.AdaptiveColor{Light: "#F8F8F2", Dark: "#F8F8F2"},
+	SelectionBackground: lipgloss.AdaptiveColor{Light: "#44475A", Dark: "#44475A"},
+	GlamourStyle:        "dracula",
 }
 
 // Nord theme
@@ -70,16 +135,39 @@ var NordTheme = Theme{
 	Error:          lipgloss.AdaptiveColor{Light: "#BF616A", Dark: "#BF616A"},
 	Info:           lipgloss.AdaptiveColor{Light: "#5E81AC", Dark: "#81A1C1"},
 	CodeBackground: lipgloss.AdaptiveColor{Light: "#3B4252", Dark: "#3B4252"},
+
+	Trace:               lipgloss.AdaptiveColor{Light: "#4C566A", Dark: "#4C566A"},
+	SelectionText:       lipgloss.AdaptiveColor{Light: "#ECEFF4", Dark: "#ECEFF4"},
+	SelectionBackground: lipgloss.AdaptiveColor{Light: "#5E81AC", Dark: "#5E81AC"},
+	GlamourStyle:        "dark",
+}
+
+// themes lists the built-in themes in the order they are offered
+var themes = []Theme{DefaultTheme, LightTheme, HighContrastTheme, DraculaTheme, NordTheme}
+
+// ThemeNames returns the names of the built-in themes
+func ThemeNames() []string {
+	names := make([]string, len(themes))
+	for i, theme := range themes {
+		names[i] = theme.Name
+	}
+	return names
+}
+
+// LookupTheme returns the built-in theme with the given name, ignoring case
+func LookupTheme(name string) (Theme, bool) {
+	for _, theme := range themes {
+		if strings.EqualFold(theme.Name, strings.TrimSpace(name)) {
+			return theme, true
+		}
+	}
+	return Theme{}, false
 }
 
-// GetTheme returns a theme by name
+// GetTheme returns a theme by name, or the default theme for unknown names
 func GetTheme(name string) Theme {
-	switch name {
-	case "dracula":
-		return DraculaTheme
-	case "nord":
-		return NordTheme
-	default:
-		return DefaultTheme
+	if theme, ok := LookupTheme(name); ok {
+		return theme
 	}
+	return DefaultTheme
 }